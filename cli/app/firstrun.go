@@ -26,6 +26,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/kardianos/osext"
 
+	"github.com/akamai/cli/pkg/commands"
 	"github.com/akamai/cli/pkg/config"
 
 	"github.com/akamai/cli/pkg/stats"
@@ -41,7 +42,7 @@ func firstRun(ctx context.Context) error {
 	term := terminal.Get(ctx)
 	cfg := config.Get(ctx)
 	if !term.IsTTY() {
-		return nil
+		return commands.FirstRunFromEnv(ctx)
 	}
 
 	bannerShown, err := firstRunCheckInPath(ctx)
@@ -53,6 +54,11 @@ func firstRun(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+
+	bannerShown, err = commands.FirstRunCheckLaunchers(ctx, bannerShown)
+	if err != nil {
+		return err
+	}
 	stats.FirstRunCheckStats(ctx, bannerShown)
 
 	return nil
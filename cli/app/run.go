@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -17,11 +19,15 @@ import (
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
+
+	"github.com/urfave/cli/v2"
 )
 
 // Run ...
 func Run() int {
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer cancelOnInterrupt(cancel)()
 	term := terminal.Color()
 
 	var pathErr *os.PathError
@@ -57,6 +63,9 @@ func Run() int {
 	}
 
 	ctx = terminal.Context(ctx, term)
+	if val, ok := cfg.GetValue("cli", terminal.AccessibilityKey); ok && strings.TrimSpace(val) == "true" {
+		terminal.SetAccessible(true)
+	}
 
 	cfg.SetValue("cli", "cache-path", cachePath)
 	if err := cfg.Save(ctx); err != nil {
@@ -69,24 +78,171 @@ func Run() int {
 	cli := app.CreateApp(ctx)
 	ctx = log.SetupContext(ctx, cli.Writer)
 
+	// the version, completion scripts and help of built-in commands are requested constantly by shells and scripts,
+	// so they are answered without reading installed packages or making network requests
+	if builtins := commands.BuiltinCommands(); builtinInvocation(os.Args[1:], builtins) {
+		cli.Commands = builtins
+		return runApp(ctx, cli)
+	}
+
 	cmds := commands.CommandLocator(ctx)
+	commands.RankCommandsByUsage(ctx, cmds)
 	cli.Commands = cmds
 
-	if err := firstRun(ctx); err != nil {
-		return 5
+	// completions are generated on every key press and must neither prompt nor wait for the network
+	if !completing(os.Args[1:]) {
+		name := commandArg(cli, os.Args[1:])
+		if code := runChecks(ctx, name); code != 0 {
+			return code
+		}
+		if name != "" && cli.Command(name) == nil {
+			installed, err := commands.InstallMissingCommand(ctx, cli, name)
+			if err != nil {
+				term.WriteError(err.Error())
+				return 6
+			}
+			if !installed {
+				if suggestions := commands.SuggestCommands(ctx, cli, name); len(suggestions) > 0 {
+					term.WriteErrorf("Command \"%s\" not found. Did you mean: %s?", name, strings.Join(suggestions, ", "))
+				}
+			}
+		}
+	}
+
+	return runApp(ctx, cli)
+}
+
+// runChecks runs the first-run setup and the periodic checks for updates and statistics, which may prompt the user
+// or make network requests; cmd is the name of the command run, empty if there is none
+func runChecks(ctx context.Context, cmd string) int {
+	term := terminal.Get(ctx)
+	// bootstrap performs the first-run setup itself, without prompting
+	if cmd != "bootstrap" {
+		if err := firstRun(ctx); err != nil {
+			return 5
+		}
+		if !updateCheckDisabled() {
+			checkUpgrade(ctx, cmd)
+			if cmd != "update" && cmd != "upgrade" && cmd != "outdated" {
+				commands.CheckUpdateDigest(ctx)
+				commands.CheckOutdatedPackages(ctx)
+				commands.CheckAutoUpdates(ctx)
+			}
+		}
 	}
-	checkUpgrade(ctx)
 	if err := stats.CheckPing(ctx); err != nil {
 		term.WriteError(err.Error())
 	}
+	return 0
+}
 
-	if err := cli.RunContext(ctx, os.Args); err != nil {
+func runApp(ctx context.Context, cliApp *cli.App) int {
+	if err := cliApp.RunContext(ctx, os.Args); err != nil {
+		var name string
+		for _, arg := range os.Args[1:] {
+			if !strings.HasPrefix(arg, "-") && cliApp.Command(arg) != nil {
+				name = arg
+				break
+			}
+		}
+		commands.RecordCommandError(ctx, name, err)
 		return 6
 	}
-
 	return 0
 }
 
+// builtinInvocation returns true if args, without the executable, only ask for the version, a completion script,
+// a cached one included, or the help and completions of a built-in command, so that neither installed commands nor
+// update checks are needed
+func builtinInvocation(args []string, builtins []*cli.Command) bool {
+	isBuiltin := func(name string) bool {
+		for _, cmd := range builtins {
+			if cmd.HasName(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var flags []string
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		flags = append(flags, strings.TrimLeft(args[0], "-"))
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		for _, flag := range flags {
+			if flag == "version" || flag == "bash" || flag == "zsh" {
+				return true
+			}
+		}
+		return false
+	}
+	if args[0] == "help" {
+		return len(args) == 2 && isBuiltin(args[1])
+	}
+	if args[0] == "completion" {
+		return len(args) == 2 && commands.CompletionCached(args[1])
+	}
+	if !isBuiltin(args[0]) {
+		return false
+	}
+	for _, arg := range args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "--help" || arg == "-help" || arg == "--generate-auto-complete" {
+			return true
+		}
+	}
+	return false
+}
+
+// commandArg returns the name of the command given in args, without the executable, skipping the global flags
+// which precede it along with their values, e.g. "akamai --proxy http://proxy:3128 bootstrap"
+// An empty name is returned if args do not have a command.
+func commandArg(cliApp *cli.App, args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+		name := strings.TrimLeft(arg, "-")
+		if !strings.Contains(name, "=") && flagTakesValue(cliApp, name) {
+			i++
+		}
+	}
+	return ""
+}
+
+// flagTakesValue returns true if the global flag with given name is followed by its value
+func flagTakesValue(cliApp *cli.App, name string) bool {
+	for _, flag := range cliApp.Flags {
+		for _, flagName := range flag.Names() {
+			if flagName == name {
+				_, isBool := flag.(*cli.BoolFlag)
+				return !isBool
+			}
+		}
+	}
+	return false
+}
+
+// completing returns true if args request shell completions rather than running a command
+func completing(args []string) bool {
+	for _, arg := range args {
+		if arg == "--generate-auto-complete" {
+			return true
+		}
+	}
+	return false
+}
+
 func cleanupUpgrade() error {
 	oldFilename := os.Args[0]
 	if strings.HasSuffix(strings.ToLower(oldFilename), ".exe") {
@@ -97,8 +253,25 @@ func cleanupUpgrade() error {
 	return os.Remove(oldFilename)
 }
 
-func checkUpgrade(ctx context.Context) {
-	if len(os.Args) > 1 && os.Args[1] == "upgrade" {
+// updateCheckDisabled returns true if update checks were disabled with the global --no-update-check flag,
+// which precedes the command, or its environment variable
+func updateCheckDisabled() bool {
+	if disabled, err := strconv.ParseBool(os.Getenv(app.NoUpdateCheckEnv)); err == nil && disabled {
+		return true
+	}
+	for _, arg := range os.Args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return false
+		}
+		if arg == "--no-update-check" || arg == "-no-update-check" {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUpgrade(ctx context.Context, cmd string) {
+	if cmd == "upgrade" {
 		return
 	}
 	if latestVersion := commands.CheckUpgradeVersion(ctx, false); latestVersion != "" && latestVersion != version.Version {
@@ -109,3 +282,24 @@ func checkUpgrade(ctx context.Context) {
 		stats.TrackEvent(ctx, "upgrade.auto", "failed", "to: "+latestVersion+" from: "+version.Version)
 	}
 }
+
+// cancelOnInterrupt calls cancel on the first interrupt or termination signal, so that pending operations,
+// such as fetching a package, stop and clean up. Following signals terminate the process as usual.
+// The returned function stops listening for signals.
+func cancelOnInterrupt(cancel context.CancelFunc) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			cancel()
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCommandArg(t *testing.T) {
+	cliApp := &cli.App{
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "bash"},
+			&cli.StringFlag{Name: "proxy"},
+			&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+		},
+	}
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"command only": {
+			args:     []string{"bootstrap", "--install", "cli-dns"},
+			expected: "bootstrap",
+		},
+		"boolean flags before the command": {
+			args:     []string{"--bash", "-v", "bootstrap"},
+			expected: "bootstrap",
+		},
+		"flag with a separate value": {
+			args:     []string{"--proxy", "http://proxy:3128", "bootstrap"},
+			expected: "bootstrap",
+		},
+		"flag with an inline value": {
+			args:     []string{"--proxy=http://proxy:3128", "update"},
+			expected: "update",
+		},
+		"end of flags": {
+			args:     []string{"-v", "--", "upgrade"},
+			expected: "upgrade",
+		},
+		"no command": {
+			args: []string{"--bash"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, commandArg(cliApp, test.args))
+		})
+	}
+}
@@ -3,10 +3,13 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/akamai/cli/pkg/version"
@@ -18,6 +21,12 @@ import (
 
 const sleepTime24Hours = time.Hour * 24
 
+// JSONOutputEnv is the environment variable which, when set to "json", has the same effect as the global --json flag
+const JSONOutputEnv = "AKAMAI_CLI_OUTPUT"
+
+// NoUpdateCheckEnv is the environment variable which, when set to "true", has the same effect as the global --no-update-check flag
+const NoUpdateCheckEnv = "AKAMAI_CLI_NO_UPDATE_CHECK"
+
 // CreateApp creates and sets up *cli.App
 func CreateApp(ctx context.Context) *cli.App {
 	term := terminal.Get(ctx)
@@ -61,6 +70,34 @@ func CreateApp(ctx context.Context) *cli.App {
 			Name:  "proxy",
 			Usage: "Set a proxy to use",
 		},
+		&cli.StringFlag{
+			Name:    "output",
+			Usage:   fmt.Sprintf("Output format passed to installed commands (%s)", strings.Join(plugin.OutputFormats, ", ")),
+			EnvVars: []string{plugin.OutputFormatEnv, "AKAMAI_CLI_OUTPUT_FORMAT"},
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: fmt.Sprintf("Print results of built-in commands as JSON, without spinners or colors, also enabled with %s=json", JSONOutputEnv),
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "Hide spinners and status messages",
+		},
+		&cli.BoolFlag{
+			Name:    "verbose",
+			Aliases: []string{"v"},
+			Usage:   "Stream the output of git and package managers instead of showing a spinner, use -vv to also show debug logs",
+		},
+		&cli.BoolFlag{
+			Name:   "vv",
+			Hidden: true,
+		},
+		&cli.BoolFlag{
+			Name:    "no-update-check",
+			Usage:   "Do not check for updates of Akamai CLI and installed packages",
+			EnvVars: []string{NoUpdateCheckEnv},
+		},
 		&cli.BoolFlag{
 			Name:    "daemon",
 			Usage:   "Keep Akamai CLI running in the background, particularly useful for Docker containers",
@@ -87,6 +124,30 @@ func CreateApp(ctx context.Context) *cli.App {
 			}
 		}
 
+		if !c.Bool("json") && strings.EqualFold(strings.TrimSpace(os.Getenv(JSONOutputEnv)), plugin.FormatJSON) {
+			if err := c.Set("json", "true"); err != nil {
+				return err
+			}
+		}
+		format := strings.ToLower(c.String("output"))
+		if c.Bool("json") {
+			// status messages are written to stderr, so results are the only output and have to stay free of color codes
+			format = plugin.FormatJSON
+			color.NoColor = true
+		}
+		if format != "" {
+			if !plugin.IsOutputFormat(format) {
+				return cli.Exit(color.RedString("Unsupported output format: %s, expected one of: %s", format, strings.Join(plugin.OutputFormats, ", ")), 1)
+			}
+			if err := os.Setenv(plugin.OutputFormatEnv, format); err != nil {
+				return err
+			}
+		}
+
+		if err := setVerbosity(c); err != nil {
+			return err
+		}
+
 		if c.IsSet("daemon") {
 			for {
 				time.Sleep(sleepTime24Hours)
@@ -98,6 +159,30 @@ func CreateApp(ctx context.Context) *cli.App {
 	return app
 }
 
+// setVerbosity applies the verbosity level chosen with -q, -v or -vv flags to the terminal and the logger
+func setVerbosity(c *cli.Context) error {
+	verbosity := terminal.VerbosityNormal
+	switch {
+	case c.Bool("quiet") && (c.Bool("verbose") || c.Bool("vv")):
+		return cli.Exit(color.RedString("--quiet and --verbose cannot be used together"), 1)
+	case c.Bool("vv"):
+		verbosity = terminal.VerbosityDebug
+	case c.Bool("verbose"):
+		verbosity = terminal.VerbosityVerbose
+	case c.Bool("quiet"), c.Bool("json"):
+		verbosity = terminal.VerbosityQuiet
+	}
+	if verbosity == terminal.VerbosityNormal {
+		return nil
+	}
+	c.Context = terminal.WithVerbosity(c.Context, verbosity)
+	log.SetLevel(c.Context, verbosity.LogLevel())
+	if term, ok := terminal.Get(c.Context).(interface{ SetVerbosity(terminal.Verbosity) }); ok {
+		term.SetVerbosity(verbosity)
+	}
+	return nil
+}
+
 // DefaultAutoComplete ...
 func DefaultAutoComplete(ctx *cli.Context) {
 	term := terminal.Get(ctx.Context)
@@ -167,6 +252,14 @@ func DefaultAutoComplete(ctx *cli.Context) {
 
 // SetHelpTemplates sets up custom help outputs for app, commands and subcommands
 func SetHelpTemplates() {
+	cli.HelpPrinter = func(w io.Writer, templ string, data interface{}) {
+		cli.HelpPrinterCustom(w, templ, data, map[string]interface{}{
+			"wrap": func(text string, indent int) string {
+				return terminal.Wrap(text, terminal.Width(), indent)
+			},
+		})
+	}
+
 	cli.AppHelpTemplate = "" +
 		color.YellowString("Usage: \n") +
 		color.BlueString("	{{if .UsageText}}"+
@@ -179,7 +272,7 @@ func SetHelpTemplates() {
 			"\n\n{{end}}") +
 		"{{if .Description}}\n\n" +
 		color.YellowString("Description:\n") +
-		"   {{.Description}}" +
+		"   {{wrap .Description 3}}" +
 		"\n\n{{end}}" +
 		"{{if .VisibleCommands}}" +
 		color.YellowString("Built-In Commands:\n") +
@@ -219,7 +312,7 @@ func SetHelpTemplates() {
 		"   {{.Category}}\n\n{{end}}" +
 		"{{if .Description}}" +
 		color.YellowString("Description: \n") +
-		"   {{.Description}}\n\n{{end}}" +
+		"   {{wrap .Description 3}}\n\n{{end}}" +
 		"{{if .VisibleFlags}}" +
 		color.YellowString("Flags: \n") +
 		"{{range .VisibleFlags}}   {{.}}\n{{end}}{{end}}" +
@@ -6,6 +6,8 @@ import (
 	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/version"
+	apexlog "github.com/apex/log"
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -26,7 +28,11 @@ func TestCreateApp(t *testing.T) {
 	assert.True(t, hasFlag(app, "bash"))
 	assert.True(t, hasFlag(app, "zsh"))
 	assert.True(t, hasFlag(app, "proxy"))
+	assert.True(t, hasFlag(app, "output"))
+	assert.True(t, hasFlag(app, "json"))
 	assert.True(t, hasFlag(app, "daemon"))
+	assert.True(t, hasFlag(app, "quiet"))
+	assert.True(t, hasFlag(app, "verbose"))
 	assert.NotNil(t, app.Before)
 }
 
@@ -85,6 +91,167 @@ func TestCreateAppProxy(t *testing.T) {
 	}
 }
 
+func TestCreateAppOutputFormat(t *testing.T) {
+	tests := map[string]struct {
+		output      string
+		expectedEnv string
+		withError   string
+	}{
+		"no output format": {},
+		"json output format": {
+			output:      "json",
+			expectedEnv: "json",
+		},
+		"upper case yaml output format": {
+			output:      "YAML",
+			expectedEnv: "yaml",
+		},
+		"unsupported output format": {
+			output:    "xml",
+			withError: "Unsupported output format: xml",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Unsetenv("AKAMAI_OUTPUT_FORMAT"))
+			term := terminal.Color()
+			ctx := terminal.Context(context.Background(), term)
+			app := CreateApp(ctx)
+			set := flag.NewFlagSet("test", 0)
+			set.String("output", "", "")
+			cliCtx := cli.NewContext(app, set, nil)
+			if test.output != "" {
+				require.NoError(t, cliCtx.Set("output", test.output))
+			}
+			err := app.Before(cliCtx)
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_OUTPUT_FORMAT"))
+			}()
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedEnv, os.Getenv("AKAMAI_OUTPUT_FORMAT"))
+		})
+	}
+}
+
+func TestCreateAppVerbosity(t *testing.T) {
+	tests := map[string]struct {
+		flags             []string
+		expectedVerbosity terminal.Verbosity
+		expectedLevel     apexlog.Level
+		withError         string
+	}{
+		"default": {
+			expectedVerbosity: terminal.VerbosityNormal,
+			expectedLevel:     apexlog.ErrorLevel,
+		},
+		"quiet": {
+			flags:             []string{"quiet"},
+			expectedVerbosity: terminal.VerbosityQuiet,
+			expectedLevel:     apexlog.ErrorLevel,
+		},
+		"verbose": {
+			flags:             []string{"verbose"},
+			expectedVerbosity: terminal.VerbosityVerbose,
+			expectedLevel:     apexlog.InfoLevel,
+		},
+		"debug": {
+			flags:             []string{"vv"},
+			expectedVerbosity: terminal.VerbosityDebug,
+			expectedLevel:     apexlog.DebugLevel,
+		},
+		"quiet and verbose": {
+			flags:     []string{"quiet", "verbose"},
+			withError: "--quiet and --verbose cannot be used together",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Unsetenv("AKAMAI_LOG"))
+			term := terminal.Color()
+			ctx := log.SetupContext(terminal.Context(context.Background(), term), os.Stderr)
+			app := CreateApp(ctx)
+			set := flag.NewFlagSet("test", 0)
+			for _, name := range []string{"quiet", "verbose", "vv"} {
+				set.Bool(name, false, "")
+			}
+			cliCtx := cli.NewContext(app, set, nil)
+			cliCtx.Context = ctx
+			for _, name := range test.flags {
+				require.NoError(t, cliCtx.Set(name, "true"))
+			}
+			err := app.Before(cliCtx)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedVerbosity, terminal.GetVerbosity(cliCtx.Context))
+			assert.Equal(t, test.expectedLevel, apexlog.FromContext(cliCtx.Context).(*apexlog.Logger).Level)
+		})
+	}
+}
+
+func TestCreateAppJSON(t *testing.T) {
+	tests := map[string]struct {
+		flag, env string
+		output    string
+		expected  bool
+	}{
+		"json flag":             {flag: "json", expected: true},
+		"json overrides output": {flag: "json", output: "yaml", expected: true},
+		"json env":              {env: "JSON", expected: true},
+		"other env value":       {env: "table"},
+		"neither flag nor env":  {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			noColor := color.NoColor
+			defer func() {
+				color.NoColor = noColor
+				require.NoError(t, os.Unsetenv("AKAMAI_OUTPUT_FORMAT"))
+				require.NoError(t, os.Unsetenv(JSONOutputEnv))
+			}()
+			require.NoError(t, os.Unsetenv("AKAMAI_OUTPUT_FORMAT"))
+			require.NoError(t, os.Setenv(JSONOutputEnv, test.env))
+			term := terminal.Color()
+			ctx := log.SetupContext(terminal.Context(context.Background(), term), os.Stderr)
+			app := CreateApp(ctx)
+			set := flag.NewFlagSet("test", 0)
+			for _, name := range []string{"json", "quiet", "verbose", "vv"} {
+				set.Bool(name, false, "")
+			}
+			set.String("output", "", "")
+			cliCtx := cli.NewContext(app, set, nil)
+			cliCtx.Context = ctx
+			if test.flag != "" {
+				require.NoError(t, cliCtx.Set(test.flag, "true"))
+			}
+			if test.output != "" {
+				require.NoError(t, cliCtx.Set("output", test.output))
+			}
+			color.NoColor = false
+
+			require.NoError(t, app.Before(cliCtx))
+			assert.Equal(t, test.expected, cliCtx.Bool("json"))
+			if !test.expected {
+				assert.Equal(t, terminal.VerbosityNormal, terminal.GetVerbosity(cliCtx.Context))
+				assert.False(t, color.NoColor)
+				assert.Empty(t, os.Getenv("AKAMAI_OUTPUT_FORMAT"))
+				return
+			}
+			assert.Equal(t, terminal.VerbosityQuiet, terminal.GetVerbosity(cliCtx.Context))
+			assert.True(t, color.NoColor)
+			assert.Equal(t, "json", os.Getenv("AKAMAI_OUTPUT_FORMAT"))
+		})
+	}
+}
+
 func hasFlag(app *cli.App, name string) bool {
 	for _, f := range app.Flags {
 		if f.Names()[0] == name {
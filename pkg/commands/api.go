@@ -0,0 +1,53 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// The functions below are used by the manager package to expose package operations to other Go programs.
+// Context passed to each of them has to contain a terminal, a config and a logger.
+
+// InstallPackage clones and installs the package from given repository and returns its directory
+func InstallPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo string, forceBinary bool) (string, error) {
+	repo = tools.Githubize(repo)
+	if _, err := installPackage(ctx, gitRepo, langManager, repo, "", forceBinary); err != nil {
+		return "", err
+	}
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(srcPath, strings.TrimSuffix(filepath.Base(repo), ".git")), nil
+}
+
+// UpdatePackage updates the package containing given command
+func UpdatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, cmd string, forceBinary bool) error {
+	_, err := updatePackage(ctx, gitRepo, langManager, log.FromContext(ctx), cmd, "", forceBinary, false)
+	return err
+}
+
+// FindExec returns the executable, along with its interpreter if required, for given command
+func FindExec(ctx context.Context, langManager packages.LangManager, cmd string) ([]string, error) {
+	return findExec(ctx, langManager, cmd)
+}
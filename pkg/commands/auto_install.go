@@ -0,0 +1,121 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// autoInstallKey is the config key controlling installation of missing commands found in the package registry:
+// "true" installs them without asking, "false" disables the lookup and any other value prompts the user
+const autoInstallKey = "auto-install"
+
+// commandNamePattern matches the names commands of registry packages can have, other input is never looked up
+var commandNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// InstallMissingCommand looks up a command which is not installed in the package registry and installs the package providing it,
+// so that the command can be run right away. Depending on cli.auto-install setting, the user is asked for confirmation first.
+// Names close to an installed command are most likely mistyped, so they are only looked up if cli.auto-install is "true".
+// It returns true if the package was installed and its commands were added to the app.
+func InstallMissingCommand(ctx context.Context, app *cli.App, name string) (bool, error) {
+	return installMissingCommand(ctx, git.NewRepository(), packages.NewLangManager(), app, name)
+}
+
+func installMissingCommand(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, app *cli.App, name string) (bool, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	if !commandNamePattern.MatchString(name) {
+		return false, nil
+	}
+
+	autoInstall, _ := config.Get(ctx).GetValue("cli", autoInstallKey)
+	autoInstall = strings.TrimSpace(autoInstall)
+	if autoInstall == "false" || (autoInstall != "true" && !term.IsTTY()) {
+		return false, nil
+	}
+	if autoInstall != "true" && len(SuggestCommands(ctx, app, name)) > 0 {
+		logger.Debugf("Command %s is close to an installed command, not looking it up in the package registry", name)
+		return false, nil
+	}
+
+	list, err := fetchCachedPackageList(ctx)
+	if err != nil {
+		logger.Debugf("Unable to fetch package list: %s", err)
+		return false, nil
+	}
+	pkg := findCommandPackage(list, name)
+	if pkg == nil {
+		return false, nil
+	}
+
+	if autoInstall != "true" {
+		answer, err := term.Confirm(fmt.Sprintf("Command \"%s\" is not installed, but is available in package \"%s\". Would you like to install it", name, pkg.Name), true)
+		if err != nil || !answer {
+			return false, nil
+		}
+	}
+
+	repo := pkg.Name
+	if pkg.URL != "" {
+		repo = pkg.URL
+	}
+	repo = tools.Githubize(repo)
+	subCmd, err := installPackage(ctx, gitRepo, langManager, repo, "", false)
+	if err != nil {
+		if isPublicRepo(repo) {
+			stats.TrackEvent(ctx, "package.auto-install", "failed", repo)
+		}
+		return false, err
+	}
+	if isPublicRepo(repo) {
+		stats.TrackEvent(ctx, "package.auto-install", "success", repo)
+	}
+	term.Writeln(color.GreenString("Package \"%s\" installed, running \"%s\"...", pkg.Name, name))
+
+	app.Commands = append(app.Commands, subcommandToCliCommands(*subCmd, gitRepo, langManager)...)
+	sortCommands(app.Commands)
+	return true, nil
+}
+
+// findCommandPackage returns the registry package providing a command with given name or alias, or nil if there is none
+func findCommandPackage(list *packageList, name string) *packageListPackage {
+	for i, pkg := range list.Packages {
+		for _, cmd := range pkg.Commands {
+			if strings.EqualFold(cmd.Name, name) {
+				return &list.Packages[i]
+			}
+			for _, alias := range cmd.Aliases {
+				if strings.EqualFold(alias, name) {
+					return &list.Packages[i]
+				}
+			}
+		}
+	}
+	return nil
+}
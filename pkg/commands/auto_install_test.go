@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestInstallMissingCommand(t *testing.T) {
+	packageListResponse := `{"packages": [{"name":"test-cmd","commands": [{"name":"app-1-cmd-1","aliases":["ac1"]}]}]}`
+	tests := map[string]struct {
+		command   string
+		commands  []*cli.Command
+		init      func(*testing.T, *mocked)
+		teardown  func(*testing.T)
+		installed bool
+	}{
+		"auto install disabled": {
+			command: "app-1-cmd-1",
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("false", true).Once()
+			},
+		},
+		"not a terminal and auto install not enabled": {
+			command: "app-1-cmd-1",
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("", false).Once()
+				m.term.On("IsTTY").Return(false).Once()
+			},
+		},
+		"flag-like input": {
+			command: "-verbose",
+			init:    func(t *testing.T, m *mocked) {},
+		},
+		"path-like input": {
+			command: "./app-1-cmd-1",
+			init:    func(t *testing.T, m *mocked) {},
+		},
+		"mistyped installed command": {
+			command:  "lsit",
+			commands: []*cli.Command{{Name: "list"}},
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("", false).Once()
+				m.term.On("IsTTY").Return(true).Once()
+			},
+		},
+		"mistyped installed command looked up when auto install enabled": {
+			command:  "lsit",
+			commands: []*cli.Command{{Name: "list"}},
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("true", true).Once()
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+		},
+		"command not found in registry": {
+			command: "abc",
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("true", true).Once()
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+		},
+		"user declines installation": {
+			command: "ac1",
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("", false).Once()
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Confirm", `Command "ac1" is not installed, but is available in package "test-cmd". Would you like to install it`, true).Return(false, nil).Once()
+			},
+		},
+		"install package without asking": {
+			command: "app-1-cmd-1",
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "auto-install").Return("true", true).Once()
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.term.On("Writeln", []interface{}{color.GreenString(`Package "%s" installed, running "%s"...`, "test-cmd", "app-1-cmd-1")}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+			installed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/cli/package-list.json", r.URL.String())
+				_, err := w.Write([]byte(packageListResponse))
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", download.ProxyKey).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", buildContainerKey).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", eventLogKey).Return("false", true).Maybe()
+			m.cfg.On("GetValue", "cli", minFreeSpaceKey).Return("0", true).Maybe()
+			m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Maybe()
+			ctx := terminal.Context(context.Background(), m.term)
+			ctx = config.Context(ctx, m.cfg)
+			app := cli.NewApp()
+			app.Commands = test.commands
+
+			test.init(t, m)
+			installed, err := installMissingCommand(ctx, m.gitRepo, m.langManager, app, test.command)
+			if test.teardown != nil {
+				test.teardown(t)
+			}
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			require.NoError(t, err)
+			assert.Equal(t, test.installed, installed)
+			if test.installed {
+				assert.NotNil(t, app.Command(test.command))
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// autoUpdateKey is the config key enabling automatic updates of packages: "daily", "weekly" or an interval such as 72h
+	autoUpdateKey = "auto-update-packages"
+	// lastAutoUpdateKey holds the time automatic updates were last started
+	lastAutoUpdateKey = "last-auto-update"
+	// autoUpdateWindowKey limits automatic updates to a maintenance window in the format of cli.upgrade-window, e.g. "mon-fri 01:00-05:00"
+	autoUpdateWindowKey = "auto-update-window"
+	// autoUpdateCacheFile is the file in the cache directory holding the automatic updates not reported yet
+	autoUpdateCacheFile = "auto-updates.json"
+
+	// updateTriggerAuto is the trigger of events recorded by automatic updates
+	updateTriggerAuto = "auto-update"
+	// logEventAutoUpdateSkipped is recorded for outdated packages left alone by automatic updates
+	logEventAutoUpdateSkipped = "auto-update-skipped"
+)
+
+// autoUpdate is a package updated automatically, or failed to, reported on the next run in a terminal
+type autoUpdate struct {
+	Package    string    `json:"package"`
+	Command    string    `json:"command"`
+	OldVersion string    `json:"oldVersion"`
+	NewVersion string    `json:"newVersion"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// startAutoUpdate runs "update --auto" in a background process which outlives the current one.
+// It is a variable so that tests do not start processes.
+var startAutoUpdate = func() error {
+	bin, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(bin, "--no-update-check", "--quiet", "update", "--auto")
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+type updateTriggerContextType string
+
+var updateTriggerContext updateTriggerContextType = "update-trigger"
+
+// withUpdateTrigger marks the events recorded with ctx as caused by trigger
+func withUpdateTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, updateTriggerContext, trigger)
+}
+
+func updateTrigger(ctx context.Context) string {
+	trigger, _ := ctx.Value(updateTriggerContext).(string)
+	return trigger
+}
+
+type updateTagContextType string
+
+var updateTagContext updateTagContextType = "update-tag"
+
+// withUpdateTag makes package updates with ctx check out given tag instead of the latest one of their channel, without pinning it
+func withUpdateTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, updateTagContext, tag)
+}
+
+func updateTag(ctx context.Context) string {
+	tag, _ := ctx.Value(updateTagContext).(string)
+	return tag
+}
+
+// CheckAutoUpdates prints a notice of the packages updated automatically since the last run in a terminal,
+// and starts automatic updates in the background once the interval set in cli.auto-update-packages has elapsed
+// since the last ones, as soon as the maintenance window set in cli.auto-update-window, if any, is open.
+func CheckAutoUpdates(ctx context.Context) {
+	term := terminal.Get(ctx)
+	if term.IsTTY() {
+		reportAutoUpdates(ctx, term)
+	}
+	interval, ok := settingInterval(ctx, autoUpdateKey)
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	cfg := config.Get(ctx)
+	if val, ok := cfg.GetValue("cli", lastAutoUpdateKey); ok {
+		if last, err := time.Parse(time.RFC3339, strings.TrimSpace(val)); err == nil && time.Since(last) < interval {
+			return
+		}
+	}
+	if window, _ := cfg.GetValue("cli", autoUpdateWindowKey); strings.TrimSpace(window) != "" {
+		// like an invalid upgrade window, an invalid window does not let updates through
+		w, err := parseMaintenanceWindow(window)
+		if err != nil {
+			logger.Warnf("Invalid value of cli.%s: %s", autoUpdateWindowKey, err)
+			return
+		}
+		if !w.allows(time.Now()) {
+			logger.Debugf("Automatic updates wait for the maintenance window %s", window)
+			return
+		}
+	}
+	cfg.SetValue("cli", lastAutoUpdateKey, time.Now().UTC().Format(time.RFC3339))
+	if err := cfg.Save(ctx); err != nil {
+		logger.Debugf("Unable to save the time of automatic updates: %s", err)
+	}
+	if err := startAutoUpdate(); err != nil {
+		logger.Debugf("Unable to start automatic updates: %s", err)
+	}
+}
+
+// autoUpdateTarget returns the tag an outdated package is updated to automatically, or why it is not updated.
+// Only packages following release tags are updated, and only to the latest tag of the installed major version,
+// which is not the latest tag of the package once a later major version is released.
+func autoUpdateTarget(res outdatedPackage) (string, string) {
+	if res.Channel == channelBranch {
+		return "", "follows a branch, only packages following release tags are updated automatically"
+	}
+	if res.Current == shortHash(res.Commit) {
+		return "", fmt.Sprintf("installed commit %s is not a release tag", res.Current)
+	}
+	current, err := semver.NewVersion(res.Current)
+	if err != nil {
+		return "", fmt.Sprintf("installed tag %s is not a semantic version", res.Current)
+	}
+	tags := res.tags
+	if len(tags) == 0 {
+		tags = []string{res.Latest}
+	}
+	var target string
+	var targetVersion *semver.Version
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil || v.Major() != current.Major() || !v.GreaterThan(current) ||
+			(res.Channel != channelPrerelease && v.Prerelease() != "") {
+			continue
+		}
+		if targetVersion == nil || v.GreaterThan(targetVersion) {
+			target, targetVersion = tag, v
+		}
+	}
+	if target != "" {
+		return target, ""
+	}
+	latest, err := semver.NewVersion(res.Latest)
+	switch {
+	case err != nil:
+		return "", fmt.Sprintf("latest tag %s is not a semantic version", res.Latest)
+	case latest.Major() != current.Major():
+		return "", fmt.Sprintf("%s is a major update", res.Latest)
+	}
+	return "", fmt.Sprintf("%s is not later than %s", res.Latest, res.Current)
+}
+
+// autoUpdatePackages updates the outdated packages eligible for automatic updates, run by "update --auto".
+// Pinned, locked and skipped packages are recorded in the event log, the updates are also kept for the notice
+// printed on the next run in a terminal.
+func autoUpdatePackages(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager) error {
+	ctx := withUpdateTrigger(c.Context, updateTriggerAuto)
+	logger := log.FromContext(ctx)
+	channels := packageUpdateChannels()
+	updates := make([]autoUpdate, 0)
+	for _, dir := range getPackagePaths() {
+		if !fileExists(filepath.Join(dir, "cli.json")) {
+			continue
+		}
+		res := checkOutdatedPackage(ctx, gitRepo, channels[filepath.Base(dir)], dir)
+		target, reason := "", res.Message
+		switch res.Status {
+		case outdatedStatusOutdated:
+			target, reason = autoUpdateTarget(res)
+		case outdatedStatusPinned, outdatedStatusLocked:
+			// the message tells what the package is pinned or locked to
+		default:
+			continue
+		}
+		if reason != "" {
+			logger.Debugf("Package %s is not updated automatically: %s", res.Package, reason)
+			recordEvent(ctx, logEvent{Event: logEventAutoUpdateSkipped, Package: res.Package, OldVersion: res.Current, NewVersion: res.Latest, Reason: reason})
+			continue
+		}
+		pkg, err := readPackage(dir)
+		if err != nil || len(pkg.Commands) == 0 {
+			logger.Debugf("Unable to read commands of package %s: %v", res.Package, err)
+			continue
+		}
+		update := autoUpdate{Package: res.Package, Command: pkg.Commands[0].Name, OldVersion: res.Current, NewVersion: target, Time: time.Now().UTC()}
+		cmdExec, _ := findExec(ctx, langManager, update.Command)
+		result, err := updatePackageExec(withUpdateTag(ctx, target), gitRepo, langManager, logger, update.Command, cmdExec, "", false, false)
+		if err != nil {
+			update.Error = strings.TrimSpace(ansiEscape.ReplaceAllString(err.Error(), ""))
+			recordEvent(ctx, logEvent{Event: logEventError, Package: res.Package, Command: "update", Error: update.Error})
+		} else if result.Status != updateStatusUpdated {
+			continue
+		}
+		updates = append(updates, update)
+	}
+	saveAutoUpdates(ctx, append(loadAutoUpdates(ctx), updates...))
+	return nil
+}
+
+func loadAutoUpdates(ctx context.Context) []autoUpdate {
+	dir, err := cacheDir(ctx)
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, autoUpdateCacheFile))
+	if err != nil {
+		return nil
+	}
+	var updates []autoUpdate
+	if err := json.Unmarshal(data, &updates); err != nil {
+		log.FromContext(ctx).Debugf("Invalid automatic updates cache: %s", err)
+		return nil
+	}
+	return updates
+}
+
+func saveAutoUpdates(ctx context.Context, updates []autoUpdate) {
+	logger := log.FromContext(ctx)
+	dir, err := cacheDir(ctx)
+	if err != nil {
+		logger.Debugf("Unable to find cache directory: %s", err)
+		return
+	}
+	if len(updates) == 0 {
+		if err := os.Remove(filepath.Join(dir, autoUpdateCacheFile)); err != nil && !os.IsNotExist(err) {
+			logger.Debugf("Unable to remove automatic updates cache: %s", err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(updates, "", "  ")
+	if err != nil {
+		logger.Debugf("Unable to encode automatic updates: %s", err)
+		return
+	}
+	if err := writeCache(dir, autoUpdateCacheFile, data); err != nil {
+		logger.Debugf("Unable to cache automatic updates: %s", err)
+	}
+}
+
+// reportAutoUpdates prints the automatic updates not reported yet to stderr, with a hint on how to roll them back
+func reportAutoUpdates(ctx context.Context, term terminal.Terminal) {
+	updates := loadAutoUpdates(ctx)
+	if len(updates) == 0 {
+		return
+	}
+	out := term.Error()
+	fmt.Fprintln(out, color.YellowString("Packages updated automatically:"))
+	for _, update := range updates {
+		if update.Error != "" {
+			fmt.Fprintf(out, "  %s %s -> %s %s\n", update.Package, update.OldVersion, update.NewVersion, color.RedString("failed: %s", update.Error))
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s -> %s\n", update.Package, update.OldVersion, color.GreenString(update.NewVersion))
+	}
+	fmt.Fprintf(out, "Run \"%s rollback <command>\" to restore a package to the version before its update.\n", tools.Self())
+	saveAutoUpdates(ctx, nil)
+}
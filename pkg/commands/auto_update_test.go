@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/warnings"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoUpdateTarget(t *testing.T) {
+	commit := "1111111111111111111111111111111111111111"
+	tests := map[string]struct {
+		res            outdatedPackage
+		expectedTag    string
+		expectedReason string
+	}{
+		"patch update":    {res: outdatedPackage{Channel: channelStable, Commit: commit, Current: "v1.0.0", Latest: "v1.0.1"}, expectedTag: "v1.0.1"},
+		"minor update":    {res: outdatedPackage{Channel: channelStable, Commit: commit, Current: "1.0.0", Latest: "1.4.0"}, expectedTag: "1.4.0"},
+		"prerelease":      {res: outdatedPackage{Channel: channelPrerelease, Commit: commit, Current: "v1.0.0", Latest: "v1.1.0-beta.1"}, expectedTag: "v1.1.0-beta.1"},
+		"major update":    {res: outdatedPackage{Channel: channelStable, Commit: commit, Current: "v1.2.0", Latest: "v2.0.0"}, expectedReason: "v2.0.0 is a major update"},
+		"branch":          {res: outdatedPackage{Channel: channelBranch, Commit: commit, Current: "master@1111111", Latest: "master@2222222"}, expectedReason: "follows a branch, only packages following release tags are updated automatically"},
+		"untagged commit": {res: outdatedPackage{Channel: channelStable, Commit: commit, Current: "1111111", Latest: "v1.1.0"}, expectedReason: "installed commit 1111111 is not a release tag"},
+		"not semver":      {res: outdatedPackage{Channel: channelStable, Commit: commit, Current: "release-a", Latest: "v1.1.0"}, expectedReason: "installed tag release-a is not a semantic version"},
+		"latest tag of the installed major version": {
+			res:         outdatedPackage{Channel: channelStable, Commit: commit, Current: "v1.2.0", Latest: "v2.1.0", tags: []string{"v1.2.0", "v1.3.0", "v1.3.1", "v1.4.0-beta.1", "v2.0.0", "v2.1.0"}},
+			expectedTag: "v1.3.1",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, reason := autoUpdateTarget(test.res)
+			assert.Equal(t, test.expectedTag, tag)
+			assert.Equal(t, test.expectedReason, reason)
+		})
+	}
+}
+
+func TestCheckAutoUpdates(t *testing.T) {
+	defer func(start func() error) {
+		startAutoUpdate = start
+	}(startAutoUpdate)
+	now := time.Now()
+	tests := map[string]struct {
+		interval string
+		last     string
+		window   string
+		updates  []autoUpdate
+		expected string
+		started  bool
+	}{
+		"first run": {
+			interval: "daily",
+			started:  true,
+		},
+		"updates reported": {
+			interval: "weekly",
+			last:     now.Add(-time.Hour).UTC().Format(time.RFC3339),
+			updates: []autoUpdate{
+				{Package: "cli-dns", Command: "dns", OldVersion: "v1.0.0", NewVersion: "v1.1.0"},
+				{Package: "cli-echo", Command: "echo", OldVersion: "v2.0.0", NewVersion: "v2.0.1", Error: "pre-update hook failed"},
+			},
+			expected: color.YellowString("Packages updated automatically:") + "\n" +
+				"  cli-dns v1.0.0 -> " + color.GreenString("v1.1.0") + "\n" +
+				"  cli-echo v2.0.0 -> v2.0.1 " + color.RedString("failed: pre-update hook failed") + "\n" +
+				"Run \"commands.test rollback <command>\" to restore a package to the version before its update.\n",
+		},
+		"updates reported when disabled": {
+			updates: []autoUpdate{{Package: "cli-dns", Command: "dns", OldVersion: "v1.0.0", NewVersion: "v1.1.0"}},
+			expected: color.YellowString("Packages updated automatically:") + "\n" +
+				"  cli-dns v1.0.0 -> " + color.GreenString("v1.1.0") + "\n" +
+				"Run \"commands.test rollback <command>\" to restore a package to the version before its update.\n",
+		},
+		"interval elapsed": {
+			interval: "72h",
+			last:     now.Add(-73 * time.Hour).UTC().Format(time.RFC3339),
+			started:  true,
+		},
+		"within the window": {
+			interval: "daily",
+			window:   "daily " + now.Add(-time.Hour).Format("15:04") + "-" + now.Add(time.Hour).Format("15:04"),
+			started:  true,
+		},
+		"outside of the window": {
+			interval: "daily",
+			window:   "daily " + now.Add(time.Hour).Format("15:04") + "-" + now.Add(2*time.Hour).Format("15:04"),
+		},
+		"invalid window": {
+			interval: "daily",
+			window:   "nightly",
+		},
+		"disabled": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			started := false
+			startAutoUpdate = func() error {
+				started = true
+				return nil
+			}
+			cachePath := filepath.Join(cliHome, "cache")
+			if test.updates != nil {
+				data, err := json.Marshal(test.updates)
+				require.NoError(t, err)
+				require.NoError(t, writeCache(cachePath, autoUpdateCacheFile, data))
+			}
+
+			cfg, term := &config.Mock{}, &terminal.Mock{}
+			cfg.On("GetValue", "cli", autoUpdateKey).Return(test.interval, test.interval != "")
+			cfg.On("GetValue", "cli", lastAutoUpdateKey).Return(test.last, test.last != "").Maybe()
+			cfg.On("GetValue", "cli", autoUpdateWindowKey).Return(test.window, test.window != "").Maybe()
+			cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			cfg.On("GetValue", "cli", warnings.IntervalKey).Return("0", true).Maybe()
+			if test.started {
+				cfg.On("SetValue", "cli", lastAutoUpdateKey, mock.Anything).Return().Once()
+				cfg.On("Save", mock.Anything).Return(nil).Once()
+			}
+			term.On("IsTTY").Return(true).Maybe()
+			out := &bytes.Buffer{}
+			term.On("Error").Return(out).Maybe()
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+			CheckAutoUpdates(ctx)
+			cfg.AssertExpectations(t)
+			assert.Equal(t, test.expected, out.String())
+			assert.Equal(t, test.started, started)
+			assert.False(t, fileExists(filepath.Join(cachePath, autoUpdateCacheFile)), "updates are reported once")
+		})
+	}
+}
+
+func TestAutoUpdatePackagesSkipped(t *testing.T) {
+	installed := plumbing.NewHash("1111111111111111111111111111111111111111")
+	latest := plumbing.NewHash("2222222222222222222222222222222222222222")
+	detached := plumbing.NewHashReference(plumbing.HEAD, installed)
+
+	tests := map[string]struct {
+		meta     packageMetadata
+		init     func(*mocked)
+		expected logEvent
+	}{
+		"major update": {
+			meta: packageMetadata{Channel: channelStable},
+			init: func(m *mocked) {
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v2.0.0"}, nil).Once()
+				m.gitRepo.On("TagCommit", "v1.0.0").Return(installed, nil)
+				m.gitRepo.On("TagCommit", "v2.0.0").Return(latest, nil)
+			},
+			expected: logEvent{Event: logEventAutoUpdateSkipped, Package: "cli-dns", OldVersion: "v1.0.0", NewVersion: "v2.0.0", Reason: "v2.0.0 is a major update", Trigger: updateTriggerAuto},
+		},
+		"pinned package": {
+			meta: packageMetadata{Channel: channelStable, Pinned: "v1.0.0"},
+			init: func(m *mocked) {
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0"}, nil).Once()
+				m.gitRepo.On("TagCommit", "v1.0.0").Return(installed, nil)
+				m.gitRepo.On("TagCommit", "v1.1.0").Return(latest, nil)
+			},
+			expected: logEvent{Event: logEventAutoUpdateSkipped, Package: "cli-dns", OldVersion: "v1.0.0", NewVersion: "v1.1.0", Reason: "pinned to v1.0.0", Trigger: updateTriggerAuto},
+		},
+		"branch": {
+			meta: packageMetadata{Branch: "master"},
+			init: func(m *mocked) {
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(latest, nil).Once()
+			},
+			expected: logEvent{Event: logEventAutoUpdateSkipped, Package: "cli-dns", OldVersion: "master@1111111", NewVersion: "master@2222222", Reason: "follows a branch, only packages following release tags are updated automatically", Trigger: updateTriggerAuto},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			store.migrateInstalled(context.Background(), "")
+			store.Packages["cli-dns"].Channel = test.meta.Channel
+			store.Packages["cli-dns"].Branch = test.meta.Branch
+			store.Packages["cli-dns"].Pinned = test.meta.Pinned
+			require.NoError(t, store.save())
+			cachePath := filepath.Join(cliHome, "cache")
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.gitRepo.On("Open", dir).Return(nil)
+			m.gitRepo.On("Head").Return(detached, nil)
+			m.gitRepo.On("Changes", installed, latest).Return(&git.Changes{Commits: 1}, nil).Maybe()
+			test.init(m)
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "update",
+				Action: cmdUpdate(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "auto"}},
+			}, m)
+
+			require.NoError(t, app.RunContext(ctx, []string{os.Args[0], "update", "--auto"}))
+			m.gitRepo.AssertExpectations(t)
+			path, err := eventLogPath()
+			require.NoError(t, err)
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			require.Len(t, lines, 1)
+			var event logEvent
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+			event.Schema, event.Time, event.CLIVersion, event.PID = 0, time.Time{}, "", 0
+			assert.Equal(t, test.expected, event)
+			assert.False(t, fileExists(filepath.Join(cachePath, autoUpdateCacheFile)))
+		})
+	}
+}
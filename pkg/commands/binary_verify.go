@@ -0,0 +1,213 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// binaryRecordFile is the file in package directory listing the command binaries downloaded instead of built
+const binaryRecordFile = ".akamai-cli-binaries.json"
+
+type (
+	// binarySignature describes the detached signatures published next to the binaries of a command in cli.json
+	binarySignature struct {
+		// Type is download.SignatureGPG or download.SignatureCosign
+		Type string `json:"type"`
+		// URL is a template of the signature URL like bin, the binary URL followed by .asc or .sig if empty
+		URL string `json:"url,omitempty"`
+		// Key is the armored or PEM-encoded public key, or the path of the file holding it in the package
+		Key string `json:"key"`
+	}
+
+	// downloadedBinary describes a command binary downloaded by install or update
+	downloadedBinary struct {
+		Path      string `json:"path"`
+		URL       string `json:"url"`
+		Platform  string `json:"platform"`
+		SHA256    string `json:"sha256"`
+		Verified  bool   `json:"verified"`
+		Signature string `json:"signature,omitempty"`
+	}
+
+	skipVerifyContextType string
+)
+
+var skipVerifyContext skipVerifyContextType = "skip-verify"
+
+// withSkipVerify disables the verification of downloaded binaries if skip is set
+func withSkipVerify(ctx context.Context, skip bool) context.Context {
+	if !skip {
+		return ctx
+	}
+	return context.WithValue(ctx, skipVerifyContext, true)
+}
+
+func skipVerify(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipVerifyContext).(bool)
+	return skip
+}
+
+// binaryPlatform returns the key of the checksum of binaries for the platform of cmd, e.g. linux-amd64 or mac-arm64
+func binaryPlatform(cmd command) string {
+	return cmd.OS + "-" + cmd.Arch
+}
+
+// binaryChecksum returns the SHA-256 sum of the binary of cmd for given platform published in cli.json,
+// nil if the package does not publish checksums
+func binaryChecksum(cmd command, platform string) ([]byte, error) {
+	if len(cmd.Checksums) == 0 {
+		return nil, nil
+	}
+	val, ok := cmd.Checksums[platform]
+	if !ok {
+		return nil, fmt.Errorf("no checksum of the %s binary is published for %s", cmd.Name, platform)
+	}
+	sum, err := hex.DecodeString(strings.TrimSpace(val))
+	if err != nil || len(sum) != 32 {
+		return nil, fmt.Errorf("invalid checksum of the %s binary for %s: expected a hex-encoded SHA-256 sum", cmd.Name, platform)
+	}
+	return sum, nil
+}
+
+// signatureURL returns the URL of the signature of the binary downloaded from binURL
+func signatureURL(sig binarySignature, cmd command, binURL string) (string, error) {
+	if sig.URL == "" {
+		if sig.Type == download.SignatureGPG {
+			return binURL + ".asc", nil
+		}
+		return binURL + ".sig", nil
+	}
+	t, err := template.New("signature").Parse(sig.URL)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, cmd); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// signatureKey returns the public key of the signature, read from the package directory if it is not inline
+func signatureKey(sig binarySignature, packageDir string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(sig.Key), "-----BEGIN") {
+		return []byte(sig.Key), nil
+	}
+	if sig.Key == "" {
+		return nil, fmt.Errorf("signature key is not set")
+	}
+	path := filepath.Join(packageDir, filepath.FromSlash(sig.Key))
+	if rel, err := filepath.Rel(packageDir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("signature key %s is outside of the package", sig.Key)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// verifyBinarySignature downloads the signature of the binary at path and checks it with the key of the package
+func verifyBinarySignature(ctx context.Context, packageDir string, sig binarySignature, cmd command, binURL, path string) error {
+	key, err := signatureKey(sig, packageDir)
+	if err != nil {
+		return fmt.Errorf("unable to read signature key: %w", err)
+	}
+	url, err := signatureURL(sig, cmd, binURL)
+	if err != nil {
+		return fmt.Errorf("unable to create signature URL: %w", err)
+	}
+	log.FromContext(ctx).Debugf("Fetching signature from %s", url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Transport: download.LimitTransport(ctx, nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to fetch signature: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.FromContext(ctx).Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to fetch signature: %s", resp.Status)
+	}
+	signature, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to fetch signature: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return download.VerifySignature(sig.Type, key, signature, f)
+}
+
+// readBinaryRecord returns the binaries downloaded into the package in given directory by command name
+func readBinaryRecord(dir string) (map[string]downloadedBinary, error) {
+	binaries := make(map[string]downloadedBinary)
+	data, err := ioutil.ReadFile(filepath.Join(dir, binaryRecordFile))
+	if os.IsNotExist(err) {
+		return binaries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &binaries); err != nil {
+		return nil, err
+	}
+	return binaries, nil
+}
+
+// recordDownloadedBinary adds the binary of cmd to the binary record of the package, failures are only logged
+func recordDownloadedBinary(ctx context.Context, dir, cmd string, binary downloadedBinary) {
+	logger := log.FromContext(ctx)
+	binaries, err := readBinaryRecord(dir)
+	if err != nil {
+		logger.Warnf("Unable to record downloaded binary: %s", err)
+		binaries = make(map[string]downloadedBinary)
+	}
+	binaries[cmd] = binary
+	data, err := json.MarshalIndent(binaries, "", "  ")
+	if err != nil {
+		logger.Warnf("Unable to record downloaded binary: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, binaryRecordFile), data, 0644); err != nil {
+		logger.Warnf("Unable to record downloaded binary: %s", err)
+	}
+}
+
+// removeBinaryRecord forgets the downloaded binaries of a package after its commands were built from source
+func removeBinaryRecord(ctx context.Context, dir string) {
+	if err := os.Remove(filepath.Join(dir, binaryRecordFile)); err != nil && !os.IsNotExist(err) {
+		log.FromContext(ctx).Warnf("Unable to remove binary record: %s", err)
+	}
+}
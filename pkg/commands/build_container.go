@@ -0,0 +1,50 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/packages"
+)
+
+// config keys choosing the container engine in which packages are built, "true" meaning docker,
+// and the builder image used for packages in given language, e.g. cli.build-image-go
+const (
+	buildContainerKey   = "build-container"
+	buildImageKeyPrefix = "build-image-"
+)
+
+// withBuildContainer makes dependency installation and build steps run in a container if cli.build-container is set
+func withBuildContainer(ctx context.Context) context.Context {
+	cfg := config.Get(ctx)
+	engine, _ := cfg.GetValue("cli", buildContainerKey)
+	engine = strings.TrimSpace(engine)
+	switch strings.ToLower(engine) {
+	case "", "false":
+		return ctx
+	case "true":
+		engine = "docker"
+	}
+	images := make(map[string]string)
+	for _, lang := range []string{packages.Go, packages.Javascript} {
+		if image, ok := cfg.GetValue("cli", buildImageKeyPrefix+lang); ok && strings.TrimSpace(image) != "" {
+			images[lang] = strings.TrimSpace(image)
+		}
+	}
+	return packages.WithBuildContainer(ctx, packages.BuildContainer{Engine: engine, Images: images})
+}
@@ -0,0 +1,67 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// cachePathKey is the config key holding the directory of cached files
+const cachePathKey = "cache-path"
+
+// cacheDir returns the directory of cached files: cli.cache-path if set, otherwise the cache directory in CLI home
+func cacheDir(ctx context.Context) (string, error) {
+	if dir, ok := config.Get(ctx).GetValue("cli", cachePathKey); ok && strings.TrimSpace(dir) != "" {
+		return strings.TrimSpace(dir), nil
+	}
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, "cache"), nil
+}
+
+// readCache returns the contents of a file in given cache directory, if it was written less than maxAge ago
+func readCache(ctx context.Context, dir, name string, maxAge time.Duration) ([]byte, bool) {
+	path := filepath.Join(dir, name)
+	stat, err := os.Stat(path)
+	if err != nil || stat.ModTime().Add(maxAge).Before(time.Now()) {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	log.FromContext(ctx).Debugf("Using cached file: %s", path)
+	return data, true
+}
+
+// writeCache stores a file in given cache directory, so that readCache returns it until it expires
+func writeCache(dir, name string, data []byte) error {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,91 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+const (
+	// maxCaptureKey is the setting of the maximum size of the output of hooks and self-tests kept in memory
+	maxCaptureKey     = "max-capture-size"
+	defaultMaxCapture = 1 << 20
+)
+
+// captureBuffer keeps the last bytes written to it, up to a maximum size, so that commands printing large reports
+// cannot exhaust the memory while their output is captured. Older output is dropped as new output arrives.
+type captureBuffer struct {
+	buf []byte
+	max int
+	// next is the position in buf of the oldest byte, which the next write overwrites once buf is full
+	next    int
+	dropped int64
+}
+
+func newCaptureBuffer(ctx context.Context) *captureBuffer {
+	return &captureBuffer{max: maxCaptureSize(ctx)}
+}
+
+// Write stores p, dropping as many of the oldest bytes as needed to stay within the maximum size
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if free := b.max - len(b.buf); free > 0 {
+		if free > len(p) {
+			free = len(p)
+		}
+		b.buf = append(b.buf, p[:free]...)
+		p = p[free:]
+	}
+	if len(p) > b.max {
+		b.dropped += int64(len(p) - b.max)
+		p = p[len(p)-b.max:]
+	}
+	for len(p) > 0 {
+		copied := copy(b.buf[b.next:], p)
+		b.dropped += int64(copied)
+		b.next = (b.next + copied) % b.max
+		p = p[copied:]
+	}
+	return n, nil
+}
+
+// String returns the kept output, oldest first
+func (b *captureBuffer) String() string {
+	return string(b.buf[b.next:]) + string(b.buf[:b.next])
+}
+
+// Dropped returns the number of bytes of output which did not fit in the buffer
+func (b *captureBuffer) Dropped() int64 {
+	return b.dropped
+}
+
+// maxCaptureSize returns the maximum size of captured output set in cli.max-capture-size, 1M by default
+func maxCaptureSize(ctx context.Context) int {
+	val, ok := config.Get(ctx).GetValue("cli", maxCaptureKey)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return defaultMaxCapture
+	}
+	size, err := parseSize(val)
+	if err != nil || size > 1<<30 {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s, expected a size up to 1G such as 512k or 4M", maxCaptureKey, val)
+		return defaultMaxCapture
+	}
+	return int(size)
+}
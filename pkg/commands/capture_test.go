@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestCaptureBuffer(t *testing.T) {
+	tests := map[string]struct {
+		writes   []string
+		expected string
+		dropped  int64
+	}{
+		"within the limit":     {writes: []string{"abc", "de"}, expected: "abcde"},
+		"exactly the limit":    {writes: []string{"abcdefgh"}, expected: "abcdefgh"},
+		"oldest bytes dropped": {writes: []string{"abcdef", "ghij"}, expected: "cdefghij", dropped: 2},
+		"wrapping many times":  {writes: []string{"abc", "defgh", "ijk", "lmnopq", "r"}, expected: "klmnopqr", dropped: 10},
+		"write over the limit": {writes: []string{"ab", "cdefghijklmn"}, expected: "ghijklmn", dropped: 6},
+		"nothing written":      {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := &captureBuffer{max: 8}
+			for _, w := range test.writes {
+				n, err := b.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+			assert.Equal(t, test.expected, b.String())
+			assert.Equal(t, test.dropped, b.Dropped())
+		})
+	}
+}
+
+func TestMaxCaptureSize(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected int
+	}{
+		"default":   {expected: defaultMaxCapture},
+		"kilobytes": {value: "512k", expected: 512 << 10},
+		"megabytes": {value: "4M", expected: 4 << 20},
+		"too large": {value: "2G", expected: defaultMaxCapture},
+		"invalid":   {value: "a lot", expected: defaultMaxCapture},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", maxCaptureKey).Return(test.value, test.value != "").Once()
+			b := newCaptureBuffer(config.Context(context.Background(), cfg))
+			assert.Equal(t, test.expected, b.max)
+			_, err := b.Write([]byte(strings.Repeat("a", test.expected+1)))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), b.Dropped())
+			cfg.AssertExpectations(t)
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -43,6 +44,16 @@ type command struct {
 	Arguments    string   `json:"arguments"`
 	Bin          string   `json:"bin"`
 	AutoComplete bool     `json:"auto-complete"`
+	// CompletionFlags are the flags of the command offered by "akamai completion" scripts, besides those of all packages
+	CompletionFlags []string `json:"flags,omitempty"`
+
+	// Checksums are the SHA-256 sums of the binaries by platform, e.g. linux-amd64, verified after download
+	Checksums map[string]string `json:"checksums,omitempty"`
+	Signature *binarySignature  `json:"signature,omitempty"`
+
+	ExitCodes map[string]string `json:"exit-codes,omitempty"`
+	// Selftest are the arguments the command is run with after install and update to check that it works, e.g. ["--version"]
+	Selftest []string `json:"selftest,omitempty"`
 
 	Flags       []cli.Flag     `json:"-"`
 	Docs        string         `json:"-"`
@@ -67,6 +78,9 @@ func getBuiltinCommands(c *cli.Context) []subcommands {
 func getCommands(c *cli.Context) []subcommands {
 	commands := make([]subcommands, 0)
 	for _, cmd := range c.App.Commands {
+		if cmd.Hidden {
+			continue
+		}
 		commands = append(commands, cliCommandToSubcommand(cmd))
 	}
 	return commands
@@ -104,8 +118,11 @@ func subcommandToCliCommands(from subcommands, gitRepo git.Repository, langManag
 			Category:        color.YellowString("Installed Commands:"),
 			SkipFlagParsing: true,
 			BashComplete: func(c *cli.Context) {
+				if completeEdgercSection(c) {
+					return
+				}
 				if command.AutoComplete {
-					executable, err := findExec(c.Context, langManager, c.Command.Name)
+					executable, err := findCommandExec(c.Context, langManager, c.Command.Name)
 					if err != nil {
 						return
 					}
@@ -132,6 +149,13 @@ func CommandLocator(ctx context.Context) []*cli.Command {
 	return commands
 }
 
+// BuiltinCommands returns the built-in commands sorted by name, without reading the metadata of installed packages
+func BuiltinCommands() []*cli.Command {
+	commands := createBuiltinCommands()
+	sortCommands(commands)
+	return commands
+}
+
 func sortCommands(commands []*cli.Command) {
 	sort.Slice(commands, func(i, j int) bool {
 		cmp := strings.Compare(commands[i].Name, commands[j].Name)
@@ -143,31 +167,246 @@ func createBuiltinCommands() []*cli.Command {
 	gitRepo := git.NewRepository()
 	langManager := packages.NewLangManager()
 	commands := []*cli.Command{
+		{
+			Name:        "adopt",
+			ArgsUsage:   "<directory>",
+			Description: "Register a package repository cloned without \"install\" as an installed package: validate its cli.json, install its dependencies and record it, so that it can be listed, updated and uninstalled",
+			Action:      cmdAdopt(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai adopt ~/src/cli-dns\n   akamai adopt --link ~/src/cli-dns",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "link",
+					Usage: "Link the directory to the packages directory instead of moving it there, uninstall then only removes the link",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "api",
+			ArgsUsage:   "[method] <path>",
+			Description: "Send a request signed with EdgeGrid credentials to an Akamai API and print the response",
+			UsageText:   "Examples:\n\n   akamai api /papi/v1/contracts\n   akamai api --section papi --body @cpcode.json POST /papi/v1/cpcodes?contractId=ctr_1",
+			Action:      cmdAPI(&http.Client{}),
+			Flags:       apiFlags(),
+			BashComplete: func(c *cli.Context) {
+				completeEdgercSection(c)
+			},
+		},
+		{
+			Name:        "auth",
+			ArgsUsage:   "<service>",
+			Description: "Manage credentials Akamai CLI uses for other services",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "github",
+					Description: "Store a GitHub token in the system keychain, used to clone and update packages, download releases and avoid API rate limits. The token is read from the terminal, or from the input when it is not a terminal",
+					UsageText:   "Examples:\n\n   akamai auth github\n   echo \"$GITHUB_TOKEN\" | akamai auth github --repo acme/cli-internal\n   akamai auth github status\n   akamai auth github logout",
+					Action:      cmdAuthGitHub,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "force",
+							Usage: "Store a classic token with scopes beyond reading repositories and packages",
+						},
+						&cli.StringSliceFlag{
+							Name:  "repo",
+							Usage: "Check that the token can read the repository, given as owner/name, can be specified multiple times",
+						},
+					},
+					Subcommands: []*cli.Command{
+						{
+							Name:        "status",
+							Description: "Show the user, scopes and expiry of the stored token",
+							Action:      cmdAuthGitHubStatus,
+						},
+						{
+							Name:        "logout",
+							Description: "Remove the stored token from the keychain",
+							Action:      cmdAuthGitHubLogout,
+						},
+					},
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "bootstrap",
+			Description: "Perform first-run setup without prompts and install given packages",
+			Action:      cmdBootstrap(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai bootstrap --stats=off --install dns,property\n   akamai bootstrap --install dns --add-to-path",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "stats",
+					Usage: "Send anonymous usage statistics: on or off (default: off)",
+				},
+				&cli.StringFlag{
+					Name:  "upgrade-check",
+					Usage: "Check for Akamai CLI upgrades daily: on or off (default: off)",
+				},
+				&cli.StringSliceFlag{
+					Name:  "install",
+					Usage: "Packages to install, separated by commas or specified multiple times",
+				},
+				&cli.BoolFlag{
+					Name:  "add-to-path",
+					Usage: "Add the launchers of installed commands, e.g. akamai-dns, to PATH",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:         "completion",
+			ArgsUsage:    "<bash|zsh|fish|powershell>",
+			Description:  "Generate a shell completion script for built-in and installed commands, kept up-to-date as packages are installed, updated or uninstalled",
+			UsageText:    "Examples:\n\n   source <(akamai completion bash)\n   akamai completion powershell | Out-String | Invoke-Expression",
+			Action:       cmdCompletion,
+			BashComplete: completeWith(func(*cli.Context) []string { return completionShellNames() }),
+		},
 		{
 			Name:        "config",
 			ArgsUsage:   "<action> <setting> [value]",
 			Description: "Manage configuration",
 			Subcommands: []*cli.Command{
 				{
-					Name:      "get",
-					ArgsUsage: "<setting>",
-					Action:    cmdConfigGet,
+					Name:         "get",
+					ArgsUsage:    "<setting>",
+					Action:       cmdConfigGet,
+					BashComplete: completeWith(configSettingNames),
+				},
+				{
+					Name:         "set",
+					ArgsUsage:    "<setting> [value]",
+					Action:       cmdConfigSet(gitRepo),
+					BashComplete: completeWith(configSettingNames),
 				},
 				{
-					Name:      "set",
-					ArgsUsage: "<setting> <value>",
-					Action:    cmdConfigSet,
+					Name:         "list",
+					ArgsUsage:    "[section]",
+					Action:       cmdConfigList,
+					Flags:        []cli.Flag{outputFlag()},
+					BashComplete: completeWith(configSectionNames),
 				},
 				{
-					Name:      "list",
-					ArgsUsage: "[section]",
-					Action:    cmdConfigList,
+					Name:         "unset",
+					Aliases:      []string{"rm"},
+					ArgsUsage:    "<setting>",
+					Action:       cmdConfigUnset,
+					BashComplete: completeWith(configSettingNames),
 				},
 				{
-					Name:      "unset",
-					Aliases:   []string{"rm"},
-					ArgsUsage: "<setting>",
-					Action:    cmdConfigUnset,
+					Name:   "edit",
+					Action: cmdConfigEdit,
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:         "disable",
+			ArgsUsage:    "<package or command>...",
+			Description:  "Disable packages without uninstalling them, their commands are hidden until the packages are enabled again",
+			Action:       cmdEnable(langManager, false),
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:         "enable",
+			ArgsUsage:    "<package or command>...",
+			Description:  "Enable packages disabled with \"disable\"",
+			Action:       cmdEnable(langManager, true),
+			HideHelp:     true,
+			BashComplete: completeWith(disabledPackageNames),
+		},
+		{
+			Name:        "doctor",
+			ArgsUsage:   "[<package or command>...]",
+			Description: "Check that installed packages can run: their runtime, dependencies, executables and repository. If no package is specified, all packages are checked",
+			Action:      cmdDoctor(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai doctor\n   akamai doctor property --fix",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "fix",
+					Usage: "Reinstall dependencies or re-clone packages to repair the problems found",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "docs",
+			ArgsUsage:   "<package or command>",
+			Description: "Display the README of an installed package",
+			Action:      cmdDocs(langManager),
+			UsageText:   "Examples:\n\n   akamai docs dns\n   akamai docs --web cli-property",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "web",
+					Usage: "Open the repository of the package in the web browser",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "events",
+			Description: "Print the event log of installs, updates, uninstalls, command runs and errors, one JSON object per line",
+			UsageText:   "Examples:\n\n   akamai events --since 24h\n   akamai events --event install --event update\n   akamai events tail --follow",
+			Action:      cmdEvents,
+			Flags:       eventFlags(),
+			Subcommands: []*cli.Command{
+				{
+					Name:        "tail",
+					Description: "Print the last events, and with --follow keep printing new ones as they are recorded",
+					Action:      cmdEventsTail,
+					Flags: append(eventFlags(),
+						&cli.IntFlag{
+							Name:    "lines",
+							Aliases: []string{"n"},
+							Usage:   "Number of events to print",
+							Value:   10,
+						},
+						&cli.BoolFlag{
+							Name:    "follow",
+							Aliases: []string{"f"},
+							Usage:   "Keep printing new events until interrupted",
+						},
+					),
+				},
+			},
+			HideHelp: true,
+		},
+		{
+			Name:        "explain",
+			ArgsUsage:   "<command> [arguments...]",
+			Description: "Show how an installed command would be executed, without running it",
+			Action:      cmdExplain(langManager),
+			UsageText:   "Examples:\n\n   akamai explain property-manager list-groups\n   akamai explain --chdir ./project --output json purge",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "chdir",
+					Usage: "Working directory of the command",
+				},
+				outputFlag(),
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "export",
+			ArgsUsage:   "[file]",
+			Description: "Write a Dockerfile or devcontainer.json reproducing this CLI version, the installed packages at their commits and the runtimes they require",
+			Action:      cmdExport(langManager),
+			UsageText:   "Examples:\n\n   akamai export > Dockerfile\n   akamai export --devcontainer .devcontainer/devcontainer.json",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "dockerfile",
+					Usage: "Write a Dockerfile (default)",
+				},
+				&cli.BoolFlag{
+					Name:  "devcontainer",
+					Usage: "Write a devcontainer.json instead of a Dockerfile",
 				},
 			},
 			HideHelp:     true,
@@ -184,120 +423,456 @@ func createBuiltinCommands() []*cli.Command {
 		{
 			Name:        "install",
 			Aliases:     []string{"get"},
-			ArgsUsage:   "<package name or repository URL>...",
+			ArgsUsage:   "<package name or repository URL>[@<tag, version range or commit>]...",
 			Description: "Fetch and install packages from a Git repository.",
 			Action:      cmdInstall(gitRepo, langManager),
-			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v",
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v\n   %v\n   %v\n   %v\n   %v",
 				"akamai install property purge",
 				"akamai install akamai/cli-property",
 				"akamai install git@github.com:akamai/cli-property.git",
-				"akamai install https://github.com/akamai/cli-property.git"),
+				"akamai install https://github.com/akamai/cli-property.git",
+				"akamai install property@v1.2.0",
+				"akamai install property --branch beta",
+				"akamai install --from-lock akamai-cli.lock",
+				"akamai install --file cli-property.tar.gz"),
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "branch",
+					Usage: "Check out and follow this branch instead of the default branch of the repository",
+				},
 				&cli.BoolFlag{
 					Name:  "force",
 					Usage: "Force binary installation if available when source installation fails",
 				},
+				&cli.StringFlag{
+					Name:  "file",
+					Usage: "Install the package from an archive written by \"akamai package export\", without network access",
+				},
+				&cli.StringFlag{
+					Name:  "from-lock",
+					Usage: "Install the packages of a lockfile written by \"akamai lock\" at the exact commits it lists",
+				},
+				&cli.StringSliceFlag{
+					Name:  "mirror",
+					Usage: "Secondary repository used by update when the primary repository is unavailable, can be specified multiple times",
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run the post-install hooks of the packages",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-verify",
+					Usage: "Install downloaded binaries without verifying their checksums and signatures",
+				},
 			},
 			HideHelp:     true,
-			BashComplete: app.DefaultAutoComplete,
+			BashComplete: completeWith(registryPackageNames),
 		},
 		{
 			Name:        "list",
 			Description: "Displays available commands",
-			Action:      cmdList,
+			Action:      cmdList(gitRepo),
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
 					Name:  "remote",
 					Usage: "Display all available packages",
 				},
+				&cli.BoolFlag{
+					Name:  "upgradable",
+					Usage: "Display only installed commands with pending updates",
+				},
+				&cli.BoolFlag{
+					Name:  "graph",
+					Usage: "Display dependencies between installed packages",
+				},
+				&cli.BoolFlag{
+					Name:  "commands",
+					Usage: "Display the name of every built-in and installed command, one per line",
+				},
+				&cli.BoolFlag{
+					Name:  "with-package",
+					Usage: "With --commands, display the package providing each command",
+				},
+				&cli.BoolFlag{
+					Name:  "no-trunc",
+					Usage: "Do not truncate descriptions to the terminal width",
+				},
+				plainFlag(),
+				outputFlag(),
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "lock",
+			ArgsUsage:   "[file]",
+			Description: "Write a lockfile of the installed packages, their commits and binary checksums, and lock the packages to these commits",
+			Action:      cmdLock,
+			UsageText:   fmt.Sprintf("Examples:\n\n   %v\n   %v", "akamai lock", "akamai lock - > akamai-cli.lock"),
+			HideHelp:    true,
+		},
+		{
+			Name:        "outdated",
+			ArgsUsage:   "[<package or command>...]",
+			Description: "Fetch the update channels of packages, without updating them, and compare the installed revisions with the latest ones. If no package is specified, all packages are checked",
+			Action:      cmdOutdated(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai outdated\n   akamai outdated property --output json",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:   "background",
+					Hidden: true,
+				},
+				plainFlag(),
+				outputFlag(),
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "package",
+			ArgsUsage:   "<action> <package or command>",
+			Description: "Inspect installed packages",
+			Subcommands: []*cli.Command{
+				{
+					Name:         "status",
+					ArgsUsage:    "<package or command>",
+					Description:  "Compare the package commit, executables and dependency lockfiles to the state recorded at install or last update",
+					Action:       cmdPackageStatus(gitRepo, langManager),
+					Flags:        []cli.Flag{outputFlag()},
+					BashComplete: completeWith(installedCommandNames),
+				},
+				{
+					Name:         "info",
+					ArgsUsage:    "<package or command>",
+					Description:  "Show the source, version, language, binaries and install history of a package, including uninstalled ones",
+					Action:       cmdPackageInfo(langManager),
+					Flags:        []cli.Flag{plainFlag(), outputFlag()},
+					BashComplete: completeWith(installedCommandNames),
+				},
+				{
+					Name:        "set-remote",
+					ArgsUsage:   "<package or command> <repository URL>",
+					Description: "Point an installed package to another repository, such as a fork, used by following updates. The previous remote is kept as \"upstream\"",
+					Action:      cmdPackageSetRemote(gitRepo, langManager),
+					UsageText:   "Examples:\n\n   akamai package set-remote dns https://github.com/me/cli-dns\n   akamai package set-remote --branch fix-records dns me/cli-dns",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "branch",
+							Usage: "Check out given branch of the repository, its dependencies are installed again",
+						},
+					},
+					BashComplete: completeWith(installedCommandNames),
+				},
+				{
+					Name:        "diff",
+					ArgsUsage:   "<package or command>",
+					Description: "Summarize the commits, changed files and dependency changes between the installed commit of a package and the latest one of its update channel, or given tag, without changing anything",
+					Action:      cmdPackageDiff(gitRepo, langManager),
+					UsageText:   "Examples:\n\n   akamai package diff dns\n   akamai package diff --to v1.2.0 dns",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "to",
+							Usage: "Compare with given tag, branch or full commit hash instead of the latest revision of the update channel",
+						},
+						outputFlag(),
+					},
+					BashComplete: completeWith(installedCommandNames),
+				},
+				{
+					Name:         "channel",
+					ArgsUsage:    "<package or command> [branch|stable|prerelease]",
+					Description:  "Show or choose whether updates of a package follow the tip of its branch, the latest stable release tag or the latest pre-release tag",
+					Action:       cmdPackageChannel(gitRepo, langManager),
+					UsageText:    "Examples:\n\n   akamai package channel dns stable\n   akamai package channel dns branch",
+					BashComplete: completeWith(installedCommandNames),
+				},
+				{
+					Name:        "export",
+					ArgsUsage:   "<package or command>",
+					Description: "Bundle an installed package, with its repository, binaries and dependencies, into an archive which \"install --file\" installs without network access",
+					Action:      cmdPackageExport(langManager),
+					UsageText:   "Examples:\n\n   akamai package export dns\n   akamai package export -o cli-dns.tar.gz dns",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "out",
+							Aliases: []string{"o"},
+							Usage:   "Path of the archive, <package>.tar.gz by default",
+						},
+					},
+					BashComplete: completeWith(installedCommandNames),
+				},
 			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
-			Name:         "search",
-			ArgsUsage:    "<keyword>...",
-			Description:  "Search for packages in the official Akamai CLI package repository",
-			Action:       cmdSearch,
-			UsageText:    "Examples:\n\n   akamai search property",
+			Name:        "purge-cache-creds",
+			Description: "Remove cached responses, package logs, statistics history and identity, and credentials stored in Akamai CLI config",
+			Action:      cmdPurgeCacheCreds,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Remove the data without asking for confirmation",
+				},
+			},
+		},
+		{
+			Name:        "rollback",
+			ArgsUsage:   "<package or command>",
+			Description: "Restore a package to the commit installed before its last update",
+			Action:      cmdRollback(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai rollback property\n   akamai rollback cli-property",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Force binary installation if available when source installation fails",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "run",
+			ArgsUsage:   "<command> [arguments...]",
+			Description: "Run an installed command in a given working directory and with environment variables loaded from files",
+			Action:      cmdRun(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai run --chdir ./project --env-file .env property-manager list-groups",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "chdir",
+					Usage: "Working directory of the command",
+				},
+				&cli.StringSliceFlag{
+					Name:  "env-file",
+					Usage: "File with KEY=VALUE lines to set in the command environment, can be specified multiple times",
+				},
+				&cli.BoolFlag{
+					Name:    "no-workspace",
+					Usage:   "Ignore the credentials declared by the workspace the command runs in",
+					EnvVars: []string{noWorkspaceEnv},
+				},
+			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
 		{
-			Name:         "uninstall",
-			ArgsUsage:    "<command>...",
-			Description:  "Uninstall package containing <command>",
-			Action:       cmdUninstall(langManager),
+			Name:        "search",
+			ArgsUsage:   "<keyword>...",
+			Description: "Search for packages in the official Akamai CLI package repository",
+			Action:      cmdSearch(gitRepo, langManager),
+			UsageText:   "Examples:\n\n   akamai search property\n   akamai search --install property",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "install",
+					Usage: "Choose packages from search results and install them",
+				},
+				&cli.BoolFlag{
+					Name:  "no-trunc",
+					Usage: "Do not truncate descriptions to the terminal width",
+				},
+				plainFlag(),
+				outputFlag(),
+			},
 			HideHelp:     true,
 			BashComplete: app.DefaultAutoComplete,
 		},
+		{
+			Name:         "selftest",
+			ArgsUsage:    "[<package or command>...]",
+			Description:  "Run the self-tests declared by packages to check that their commands work. If no package is specified, all packages are tested",
+			Action:       cmdSelftest(langManager),
+			UsageText:    "Examples:\n\n   akamai selftest property\n   akamai selftest",
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:        "stats",
+			Description: "View, reset or migrate the anonymous client identifier and the decision whether to send usage statistics",
+			UsageText:   "Examples:\n\n   akamai stats export > stats.json\n   akamai stats import stats.json\n   akamai stats reset --consent\n   akamai stats preview --last 3",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "show",
+					Action: cmdStatsShow,
+				},
+				{
+					Name:   "reset",
+					Action: cmdStatsReset,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "consent",
+							Usage: "Also remove the decision whether to send statistics, so that it is asked for again",
+						},
+					},
+				},
+				{
+					Name:   "export",
+					Action: cmdStatsExport,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "no-client-id",
+							Usage: "Export only the decision whether to send statistics",
+						},
+					},
+				},
+				{
+					Name:   "preview",
+					Action: cmdStatsPreview,
+					Flags: []cli.Flag{
+						&cli.IntFlag{
+							Name:  "last",
+							Usage: "Number of most recent commands to preview",
+							Value: 10,
+						},
+						outputFlag(),
+					},
+				},
+				{
+					Name:      "import",
+					ArgsUsage: "<file>",
+					Action:    cmdStatsImport,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "no-client-id",
+							Usage: "Import only the decision whether to send statistics",
+						},
+					},
+				},
+			},
+			HideHelp:     true,
+			BashComplete: app.DefaultAutoComplete,
+		},
+		{
+			Name:        "uninstall",
+			ArgsUsage:   "<command>...",
+			Description: "Uninstall package containing <command>",
+			Action:      cmdUninstall(langManager),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Uninstall all installed packages",
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run the pre-uninstall hooks of the packages",
+				},
+				&cli.BoolFlag{
+					Name:  "wait",
+					Usage: "Wait for running commands of the packages to finish instead of refusing to uninstall them",
+				},
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Uninstall several packages without asking for confirmation",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
+		},
 		{
 			Name:        "update",
 			ArgsUsage:   "[<command>...]",
-			Description: "Update one or more commands. If no command is specified, all commands are updated",
+			Description: "Update one or more commands. If no command is specified, all commands are updated. Packages are updated in parallel and a failure of one package does not stop the others",
 			Action:      cmdUpdate(gitRepo, langManager),
 			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:   "auto",
+					Hidden: true,
+				},
 				&cli.BoolFlag{
 					Name:  "force",
 					Usage: "Force binary installation if available when source installation fails",
 				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print the update summary in JSON format",
+				},
+				&cli.IntFlag{
+					Name:  "concurrency",
+					Usage: "Maximum number of packages updated at the same time",
+					Value: 4,
+				},
+				// failures no longer stop the update of the remaining packages, the flag is kept for existing scripts
+				&cli.BoolFlag{
+					Name:   "continue-on-error",
+					Usage:  "Continue with the remaining packages if updating a package fails",
+					Hidden: true,
+				},
+				&cli.BoolFlag{
+					Name:  "no-hooks",
+					Usage: "Do not run the pre-update and post-update hooks of the packages",
+				},
+				&cli.BoolFlag{
+					Name:  "reset",
+					Usage: "Reset packages to the remote branch without asking when their history was rewritten or has local commits",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-verify",
+					Usage: "Install downloaded binaries without verifying their checksums and signatures",
+				},
+				&cli.BoolFlag{
+					Name:  "unlock",
+					Usage: "Update packages locked to the commits of a lockfile, removing their lock",
+				},
+				&cli.StringFlag{
+					Name:  "version",
+					Usage: "Pin the command to a tag, semantic version range or commit, \"latest\" removes the pin",
+				},
+				&cli.BoolFlag{
+					Name:  "wait",
+					Usage: "Wait for running commands of the packages to finish instead of refusing to update them",
+				},
 			},
 			HideHelp:     true,
-			BashComplete: app.DefaultAutoComplete,
+			BashComplete: completeWith(installedCommandNames),
+		},
+		{
+			Name:         "verify",
+			ArgsUsage:    "[<package or command>...]",
+			Description:  "Check downloaded binaries of packages against the checksums and signatures they publish. If no package is specified, all packages are checked",
+			Action:       cmdVerify(langManager),
+			UsageText:    "Examples:\n\n   akamai verify property\n   akamai verify",
+			HideHelp:     true,
+			BashComplete: completeWith(installedCommandNames),
 		},
 	}
 	upgradeCommand := getUpgradeCommand()
 	if upgradeCommand != nil {
 		commands = append(commands, upgradeCommand)
 	}
+	applyDeprecations(commands, deprecations)
 	return commands
 }
 
 func createInstalledCommands(_ context.Context, gitRepo git.Repository, langManager packages.LangManager) []*cli.Command {
 	commands := make([]*cli.Command, 0)
 	packagePaths := getPackagePaths()
+	names := make(map[string]bool)
 	for _, dir := range packagePaths {
 		pkg, err := readPackage(dir)
 		if err == nil {
-			commands = append(commands, subcommandToCliCommands(pkg, gitRepo, langManager)...)
+			cmds, _ := packageCliCommands(names, filepath.Base(dir), pkg, gitRepo, langManager)
+			commands = append(commands, cmds...)
 		}
 	}
 	return commands
 }
 
 func findExec(ctx context.Context, langManager packages.LangManager, cmd string) ([]string, error) {
+	return findExecInPaths(ctx, langManager, cmd, getPackageBinPaths())
+}
+
+// findExecInPaths looks for the executable of cmd in given list of package directories
+func findExecInPaths(ctx context.Context, langManager packages.LangManager, cmd, packagePaths string) ([]string, error) {
 	// "command" becomes: akamai-command, and akamaiCommand
 	// "command-name" becomes: akamai-command-name, and akamaiCommandName
-	cmdName := "akamai"
-	cmdNameTitle := "akamai"
-	for _, cmdPart := range strings.Split(cmd, "-") {
-		cmdName += "-" + strings.ToLower(cmdPart)
-		cmdNameTitle += strings.Title(strings.ToLower(cmdPart))
-	}
+	cmdName, cmdNameTitle := executableNames(cmd)
 
-	systemPath := os.Getenv("PATH")
-	packagePaths := getPackageBinPaths()
-	if err := os.Setenv("PATH", packagePaths); err != nil {
-		return nil, err
-	}
-
-	// Quick look for executables on the path
-	var path string
-	path, err := exec.LookPath(cmdName)
-	if err != nil {
-		path, _ = exec.LookPath(cmdNameTitle)
-	}
-
-	if path != "" {
-		if err := os.Setenv("PATH", systemPath); err != nil {
-			return nil, err
-		}
+	// Quick look for executables in the package directories
+	if path := lookPathIn(packagePaths, cmdName, cmdNameTitle); path != "" {
 		return []string{path}, nil
 	}
 
-	if err := os.Setenv("PATH", systemPath); err != nil {
-		return nil, err
-	}
 	if packagePaths == "" {
 		return nil, errors.New("no executables found")
 	}
@@ -345,15 +920,33 @@ func findExec(ctx context.Context, langManager packages.LangManager, cmd string)
 	return nil, errors.New("no executables found")
 }
 
+// lookPathIn returns the first executable with one of given names in the list of directories, as exec.LookPath does for PATH
+// The directories are searched without replacing PATH, which other goroutines may use at the same time.
+func lookPathIn(paths string, names ...string) string {
+	for _, name := range names {
+		for _, dir := range filepath.SplitList(paths) {
+			if dir == "" {
+				continue
+			}
+			if path, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
 func getPackageBinPaths() string {
 	path := ""
 	akamaiCliPath, err := tools.GetAkamaiCliSrcPath()
 	if err == nil && akamaiCliPath != "" {
 		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
+		paths = enabledPackagePaths(paths)
 		if len(paths) > 0 {
 			path += strings.Join(paths, string(os.PathListSeparator))
 		}
 		paths, _ = filepath.Glob(filepath.Join(akamaiCliPath, "*", "bin"))
+		paths = enabledPackagePaths(paths)
 		if len(paths) > 0 {
 			path += string(os.PathListSeparator) + strings.Join(paths, string(os.PathListSeparator))
 		}
@@ -363,12 +956,20 @@ func getPackageBinPaths() string {
 }
 
 func passthruCommand(executable []string) error {
+	return exitCodeError(passthruCmd(executable).Run())
+}
+
+// passthruCmd returns a command running given executable with standard input and outputs of the CLI
+func passthruCmd(executable []string) *exec.Cmd {
 	subCmd := exec.Command(executable[0], executable[1:]...)
 	subCmd.Stdin = os.Stdin
 	subCmd.Stderr = os.Stderr
 	subCmd.Stdout = os.Stdout
-	err := subCmd.Run()
+	return subCmd
+}
 
+// exitCodeError converts error returned by an executed command into an exit error carrying its exit code
+func exitCodeError(err error) error {
 	exitCode := 1
 	if exitError, ok := err.(*exec.ExitError); ok {
 		if waitStatus, ok := exitError.Sys().(syscall.WaitStatus); ok {
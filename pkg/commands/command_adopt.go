@@ -0,0 +1,154 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdAdopt(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		start := time.Now()
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("ADOPT START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("ADOPT FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("ADOPT ERROR: %v", e.Error())
+			}
+		}()
+		defer refreshCompletions(c)
+		defer refreshLaunchers(c)
+		if c.NArg() != 1 {
+			return cli.Exit(color.RedString("You must specify the directory of a package checkout"), 1)
+		}
+
+		oldCmds := getCommands(c)
+		packageDir, subCmd, err := adoptPackage(c.Context, gitRepo, langManager, c.Args().First(), c.Bool("link"))
+		if err != nil {
+			return err
+		}
+		registered := make(map[string]bool)
+		for _, cmd := range c.App.Commands {
+			registered[cmd.Name] = true
+		}
+		cmds, _ := packageCliCommands(registered, filepath.Base(packageDir), *subCmd, gitRepo, langManager)
+		c.App.Commands = append(c.App.Commands, cmds...)
+		sortCommands(c.App.Commands)
+		packageListDiff(c, oldCmds)
+		terminal.Get(c.Context).Writeln(fmt.Sprintf("Package %s is managed by Akamai CLI, update it with \"%s update %s\"", filepath.Base(packageDir), tools.Self(), filepath.Base(packageDir)))
+		return nil
+	}
+}
+
+// adoptPackage registers the git checkout in dir as an installed package, as if it was installed with "akamai install"
+// from its origin remote: the package is moved to the packages directory, or linked there if link is set.
+// Its cli.json is validated, its dependencies are installed and it is recorded in the package store.
+// The checkout is left where it was if any of these steps fails.
+func adoptPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string, link bool) (string, *subcommands, error) {
+	logger := log.FromContext(ctx)
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", nil, cli.Exit(color.RedString("Directory %s not found", dir), 1)
+	}
+	if _, err := readPackage(dir); err != nil {
+		return "", nil, cli.Exit(color.RedString("%s is not a valid package: %s", dir, err), 1)
+	}
+	if err := gitRepo.Open(dir); err != nil {
+		return "", nil, cli.Exit(color.RedString("%s is not a git repository: %s", dir, err), 1)
+	}
+	repo, err := gitRepo.RemoteURL(git.DefaultRemoteName)
+	if err != nil {
+		return "", nil, cli.Exit(color.RedString("%s has no %s remote to update the package from: %s", dir, git.DefaultRemoteName, err), 1)
+	}
+	if err := requireVerified(ctx, repo); err != nil {
+		return "", nil, err
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return "", nil, err
+	}
+	name := packageDirName(repo)
+	packageDir := filepath.Join(srcPath, name)
+	// a checkout cloned directly to the packages directory is registered where it is
+	inPlace := packageDir == dir
+	if !inPlace {
+		if _, err := os.Lstat(packageDir); err == nil {
+			return "", nil, newFailure(failurePackageExists, failureData{"Dir": packageDir, "Package": name}, nil)
+		}
+		if err := checkPreflight(ctx, srcPath); err != nil {
+			return "", nil, err
+		}
+		if link {
+			err = os.Symlink(dir, packageDir)
+		} else {
+			err = os.Rename(dir, packageDir)
+		}
+		if err != nil {
+			return "", nil, cli.Exit(color.RedString("Unable to add %s to the packages directory: %s", dir, err), 1)
+		}
+	}
+	restore := func() {
+		var err error
+		switch {
+		case inPlace:
+			return
+		case link:
+			err = os.Remove(packageDir)
+		default:
+			err = os.Rename(packageDir, dir)
+		}
+		if err != nil {
+			logger.Errorf("Unable to restore %s: %s", dir, err)
+		}
+	}
+
+	subCmd, err := installPackageDependencies(ctx, langManager, packageDir, hookPostInstall, false, logger)
+	if err != nil {
+		restore()
+		return "", nil, err
+	}
+	seedPackageConfig(ctx, *subCmd)
+
+	var revision string
+	if err := gitRepo.Open(packageDir); err == nil {
+		if ref, err := gitRepo.Head(); err == nil {
+			revision = ref.Hash().String()
+		}
+	}
+	saveInstallRecord(ctx, packageDir, revision)
+	recordPackageEvent(ctx, packageEventInstall, packageDir)
+	notifyPackageEvent(ctx, packageEventInstall, packageDir, "", packageVersion(*subCmd))
+	return packageDir, subCmd, nil
+}
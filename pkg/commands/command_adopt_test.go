@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdAdopt(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		noManifest  bool
+		installed   bool
+		installErr  error
+		withError   string
+		expectMoved bool
+		expectLink  bool
+	}{
+		"move checkout":           {expectMoved: true},
+		"link checkout":           {args: []string{"--link"}, expectLink: true},
+		"not a package":           {noManifest: true, withError: "is not a valid package"},
+		"package already exists":  {installed: true, withError: "[package-exists]"},
+		"dependencies not found":  {installErr: packages.ErrPackageManagerNotFound, withError: "[package-manager-not-found]"},
+		"dependencies with links": {args: []string{"--link"}, installErr: packages.ErrPackageManagerNotFound, withError: "[package-manager-not-found]"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			workDir, err := ioutil.TempDir("", "cli-checkout")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.RemoveAll(workDir))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			checkout := filepath.Join(workDir, "dns")
+			require.NoError(t, os.MkdirAll(checkout, 0755))
+			if !test.noManifest {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(checkout, "cli.json"), []byte(`{"requirements": {"node": "12.0.0"}, "commands": [{"name": "dns", "version": "1.0.0"}]}`), 0644))
+			}
+			packageDir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-dns")
+			if test.installed {
+				writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns"}]}`)
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "adopt",
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "link"}},
+				Action: cmdAdopt(m.gitRepo, m.langManager),
+			}
+			app, ctx := setupTestApp(command, m)
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli-dns", mock.Anything).Return("", false).Maybe()
+			m.term.On("Spinner").Return(m.term).Maybe()
+			m.term.On("Start", mock.Anything, mock.Anything).Return().Maybe()
+			m.term.On("OK").Return().Maybe()
+			m.term.On("Stop", mock.Anything).Return().Maybe()
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Maybe()
+			m.term.On("Printf", mock.Anything, mock.Anything).Return().Maybe()
+			m.gitRepo.On("Open", mock.Anything).Return(nil).Maybe()
+			m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/akamai/cli-dns.git", nil).Maybe()
+			m.gitRepo.On("Head").Return(plumbing.NewHashReference("refs/heads/master", plumbing.NewHash("0a1b2c3d4e5f60718293a4b5c6d7e8f901234567")), nil).Maybe()
+			m.langManager.On("Install", packageDir, packages.LanguageRequirements{Node: "12.0.0"}, []string{"dns"}).Return(test.installErr).Maybe()
+
+			err = app.RunContext(ctx, append(append([]string{os.Args[0], "adopt"}, test.args...), checkout))
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.DirExists(t, checkout, "the checkout is left in place")
+				if !test.installed {
+					_, statErr := os.Lstat(packageDir)
+					assert.True(t, os.IsNotExist(statErr))
+				}
+				return
+			}
+			require.NoError(t, err)
+			info, err := os.Lstat(packageDir)
+			require.NoError(t, err)
+			if test.expectLink {
+				assert.NotZero(t, info.Mode()&os.ModeSymlink)
+				assert.FileExists(t, filepath.Join(checkout, "cli.json"))
+			}
+			if test.expectMoved {
+				assert.True(t, info.IsDir())
+				_, err := os.Stat(checkout)
+				assert.True(t, os.IsNotExist(err))
+			}
+			store, err := loadPackageStore(ctx)
+			require.NoError(t, err)
+			require.Contains(t, store.Packages, "cli-dns")
+			assert.True(t, store.Packages["cli-dns"].Installed)
+			assert.Equal(t, "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", store.Packages["cli-dns"].Commit)
+		})
+	}
+}
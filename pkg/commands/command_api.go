@@ -0,0 +1,389 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/edgegrid"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// apiFlags returns the credentials flags of installed packages, used by the api command to sign requests
+func apiFlags() []cli.Flag {
+	var flags []cli.Flag
+	for _, flag := range plugin.Flags() {
+		if flag.Names()[0] != "json" {
+			flags = append(flags, flag)
+		}
+	}
+	return append(flags,
+		&cli.StringSliceFlag{
+			Name:    "header",
+			Aliases: []string{"H"},
+			Usage:   "Request header in \"Name: value\" format, can be specified multiple times",
+		},
+		&cli.StringFlag{
+			Name:    "body",
+			Aliases: []string{"d"},
+			Usage:   "Request body, @file reads it from a file and @- from standard input",
+		},
+		&cli.BoolFlag{
+			Name:    "include",
+			Aliases: []string{"i"},
+			Usage:   "Print the response status and headers",
+		},
+		&cli.DurationFlag{
+			Name:  "cache",
+			Usage: "Reuse successful GET responses received within given time, e.g. 10m",
+		},
+		&cli.BoolFlag{
+			Name:  "paginate",
+			Usage: "Follow links to the next pages of the response and print all of them",
+		},
+	)
+}
+
+func cmdAPI(client *http.Client) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("API START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("API FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("API ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		method, path := http.MethodGet, c.Args().First()
+		switch c.Args().Len() {
+		case 1:
+		case 2:
+			method, path = strings.ToUpper(c.Args().Get(0)), c.Args().Get(1)
+		default:
+			return cli.Exit(color.RedString("You must specify the request method and path, for example: GET /papi/v1/contracts"), plugin.ExitUsage)
+		}
+
+		edgercPath, section, err := plugin.Edgerc(c)
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), plugin.ExitAuth)
+		}
+		creds, err := edgegrid.Load(edgercPath, section)
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to read credentials: %s", err)), plugin.ExitAuth)
+		}
+		req, err := apiRequest(c, creds, method, path)
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Invalid request: %s", err)), plugin.ExitUsage)
+		}
+
+		visited := map[string]bool{req.URL.String(): true}
+		for {
+			resp, err := sendAPIRequest(c.Context, client, creds, req, c.Duration("cache"))
+			if err != nil {
+				return cli.Exit(color.RedString(fmt.Sprintf("Request failed: %s", err)), plugin.ExitNetwork)
+			}
+			if c.Bool("include") {
+				term.Writeln(fmt.Sprintf("%s %s", resp.Proto, resp.Status))
+				names := make([]string, 0, len(resp.Header))
+				for name := range resp.Header {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					term.Writeln(fmt.Sprintf("%s: %s", name, strings.Join(resp.Header[name], ", ")))
+				}
+				term.Writeln("")
+			}
+			writeAPIResponse(term, resp.Header.Get("Content-Type"), resp.Body)
+
+			switch {
+			case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+				return cli.Exit(color.RedString(fmt.Sprintf("Request failed: %s", resp.Status)), plugin.ExitAuth)
+			case resp.StatusCode < 200 || resp.StatusCode > 299:
+				return cli.Exit(color.RedString(fmt.Sprintf("Request failed: %s", resp.Status)), 1)
+			}
+			if !c.Bool("paginate") {
+				return nil
+			}
+			next, err := nextPageURL(req.URL, resp)
+			if err != nil {
+				return cli.Exit(color.RedString(fmt.Sprintf("Unable to fetch next page: %s", err)), 1)
+			}
+			if next == nil || visited[next.String()] {
+				return nil
+			}
+			visited[next.String()] = true
+			if req, err = nextPageRequest(req, next); err != nil {
+				return cli.Exit(color.RedString(fmt.Sprintf("Unable to fetch next page: %s", err)), 1)
+			}
+		}
+	}
+}
+
+// apiResponse is the part of an API response printed by the api command, it is stored in the cache as JSON
+type apiResponse struct {
+	Proto      string      `json:"proto"`
+	Status     string      `json:"status"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// sendAPIRequest signs and sends the request, successful GET responses are cached for given time if it is not 0
+func sendAPIRequest(ctx context.Context, client *http.Client, creds *edgegrid.Credentials, req *http.Request, cacheFor time.Duration) (*apiResponse, error) {
+	logger := log.FromContext(ctx)
+	var cachePath string
+	if cacheFor > 0 && req.Method == http.MethodGet {
+		dir, err := cacheDir(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cachePath = dir
+	}
+	cacheName := apiCacheName(creds, req)
+	if cachePath != "" {
+		if data, ok := readCache(ctx, cachePath, cacheName, cacheFor); ok {
+			cached := &apiResponse{}
+			if err := json.Unmarshal(data, cached); err == nil {
+				return cached, nil
+			}
+		}
+	}
+
+	if err := creds.Sign(req); err != nil {
+		return nil, fmt.Errorf("unable to sign request: %w", err)
+	}
+	logger.Debugf("Sending %s %s", req.Method, req.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %w", err)
+	}
+	result := &apiResponse{Proto: resp.Proto, Status: resp.Status, StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+
+	if cachePath != "" && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		if data, err := json.Marshal(result); err == nil {
+			if err := writeCache(cachePath, cacheName, data); err != nil {
+				logger.Debugf("Unable to cache API response: %s", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// apiCacheName returns the name of the cached response to a request, it depends on the credentials,
+// so that responses are not shared between accounts, as well as the URL and headers of the request
+func apiCacheName(creds *edgegrid.Credentials, req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if name != "Authorization" && name != "User-Agent" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	key := []string{creds.Host, creds.ClientToken, creds.AccessToken, req.Method, req.URL.String()}
+	for _, name := range names {
+		key = append(key, name+": "+strings.Join(req.Header[name], ", "))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(key, "\n")))
+	return filepath.Join("api", hex.EncodeToString(sum[:])+".json")
+}
+
+// nextPageURL returns the URL of the next page of a paginated response, or nil if it is the last page.
+// The next page is found in the Link header with rel="next", or in the links of a JSON response
+// in either {"links": [{"rel": "next", "href": ...}]} or {"_links": {"next": {"href": ...}}} form
+func nextPageURL(current *url.URL, resp *apiResponse) (*url.URL, error) {
+	href := linkHeaderNext(resp.Header.Values("Link"))
+	if href == "" && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		var doc struct {
+			Links []struct {
+				Rel  string `json:"rel"`
+				Href string `json:"href"`
+			} `json:"links"`
+			HAL struct {
+				Next struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"_links"`
+		}
+		if json.Unmarshal(resp.Body, &doc) == nil {
+			href = doc.HAL.Next.Href
+			for _, link := range doc.Links {
+				if link.Rel == "next" {
+					href = link.Href
+				}
+			}
+		}
+	}
+	if href == "" {
+		return nil, nil
+	}
+	next, err := current.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	// credentials must not be used to sign requests to other hosts
+	if next.Host != current.Host {
+		return nil, fmt.Errorf("next page is on another host: %s", next)
+	}
+	if key := current.Query().Get(edgegrid.AccountSwitchKeyParam); key != "" && next.Query().Get(edgegrid.AccountSwitchKeyParam) == "" {
+		query := next.Query()
+		query.Set(edgegrid.AccountSwitchKeyParam, key)
+		next.RawQuery = query.Encode()
+	}
+	return next, nil
+}
+
+// linkHeaderNext returns the target of the rel="next" link of Link headers, as defined in RFC 8288
+func linkHeaderNext(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(name) != 2 || !strings.EqualFold(name[0], "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(name[1], `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// nextPageRequest returns a GET request for the next page, with the same headers as the previous one
+func nextPageRequest(prev *http.Request, next *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(prev.Context(), http.MethodGet, next.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = prev.Header.Clone()
+	req.Header.Del("Authorization")
+	req.Header.Del("Content-Type")
+	return req, nil
+}
+
+// apiRequest builds the request to the host of given credentials, path may include a query string
+func apiRequest(c *cli.Context, creds *edgegrid.Credentials, method, path string) (*http.Request, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path has to start with /: %s", path)
+	}
+	u, err := url.Parse("https://" + creds.Host + path)
+	if err != nil {
+		return nil, err
+	}
+	if key := c.String("accountkey"); key != "" {
+		query := u.Query()
+		query.Set(edgegrid.AccountSwitchKeyParam, key)
+		u.RawQuery = query.Encode()
+	}
+
+	var body io.Reader
+	data, err := apiBody(c.String("body"))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(c.Context, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range c.StringSlice("header") {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("header has to be provided in \"Name: value\" format: %s", header)
+		}
+		req.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	if data != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "akamai-cli/"+version.Version)
+	return req, nil
+}
+
+// apiBody returns the request body given with --body, reading it from a file or standard input if it starts with @
+func apiBody(value string) ([]byte, error) {
+	switch {
+	case value == "":
+		return nil, nil
+	case value == "@-":
+		return ioutil.ReadAll(os.Stdin)
+	case strings.HasPrefix(value, "@"):
+		data, err := ioutil.ReadFile(strings.TrimPrefix(value, "@"))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("body file not found: %s", strings.TrimPrefix(value, "@"))
+		}
+		return data, err
+	}
+	return []byte(value), nil
+}
+
+// writeAPIResponse prints the response body, indenting JSON documents
+func writeAPIResponse(term terminal.Terminal, contentType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	var out bytes.Buffer
+	if strings.Contains(contentType, "json") && json.Indent(&out, body, "", "  ") == nil {
+		term.Writeln(out.String())
+		return
+	}
+	term.Writeln(strings.TrimSuffix(string(body), "\n"))
+}
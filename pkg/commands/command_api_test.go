@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdAPI(t *testing.T) {
+	requests := make(map[string]int)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "EG1-HMAC-SHA256 client_token=test-client-token;access_token=test-access-token;") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/papi/v1/contracts":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"accountId":%q,"method":%q}`, r.URL.Query().Get("accountSwitchKey"), r.Method)
+		case "/papi/v1/cpcodes":
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, "%s %s %s\n", r.Method, r.Header.Get("Content-Type"), body)
+		case "/users":
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("page") {
+			case "":
+				w.Header().Set("Link", `</users?page=2>; rel="next", </users>; rel="first"`)
+				_, _ = fmt.Fprintf(w, `{"page":1}`)
+			case "2":
+				_, _ = fmt.Fprintf(w, `{"page":2,"account":%q,"links":[{"rel":"next","href":"/users?page=3"}]}`, r.URL.Query().Get("accountSwitchKey"))
+			case "3":
+				_, _ = fmt.Fprintf(w, `{"page":3,"_links":{"next":{"href":"https://example.com/users?page=4"}}}`)
+			}
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/counter/cached", "/counter/uncached":
+			requests[r.URL.Path]++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"requests":%d}`, requests[r.URL.Path])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cli-api")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	edgerc := filepath.Join(dir, ".edgerc")
+	require.NoError(t, ioutil.WriteFile(edgerc, []byte(fmt.Sprintf(`[default]
+host = %s
+client_token = test-client-token
+client_secret = test-secret
+access_token = test-access-token
+`, strings.TrimPrefix(srv.URL, "https://"))), 0600))
+	bodyFile := filepath.Join(dir, "cpcode.json")
+	require.NoError(t, ioutil.WriteFile(bodyFile, []byte(`{"cpcodeName":"example"}`), 0644))
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		runs      int
+		expected  string
+		withError string
+		exitCode  int
+	}{
+		"GET by default": {
+			args:     []string{"/papi/v1/contracts"},
+			expected: "{\n  \"accountId\": \"\",\n  \"method\": \"GET\"\n}",
+		},
+		"account switch key": {
+			args:     []string{"--accountkey", "1-ABC", "get", "/papi/v1/contracts"},
+			expected: "{\n  \"accountId\": \"1-ABC\",\n  \"method\": \"GET\"\n}",
+		},
+		"POST body from file": {
+			args:     []string{"--body", "@" + bodyFile, "POST", "/papi/v1/cpcodes"},
+			expected: `POST application/json {"cpcodeName":"example"}`,
+		},
+		"include response headers": {
+			args:     []string{"-i", "-H", "Content-Type: text/plain", "-d", "abc", "PUT", "/papi/v1/cpcodes"},
+			expected: "HTTP/1.1 201 Created\nContent-Length: 19\nContent-Type: text/plain\nDate: *\n\nPUT text/plain abc",
+		},
+		"error status": {
+			args:      []string{"/missing"},
+			expected:  "not found",
+			withError: "Request failed: 404 Not Found",
+		},
+		"forbidden": {
+			args:      []string{"/forbidden"},
+			withError: "Request failed: 403 Forbidden",
+			exitCode:  3,
+		},
+		"missing section": {
+			args:      []string{"--section", "ccu", "/papi/v1/contracts"},
+			withError: "Unable to read credentials: section not found: ccu",
+			exitCode:  3,
+		},
+		"relative path": {
+			args:      []string{"papi/v1/contracts"},
+			withError: "Invalid request: path has to start with /",
+			exitCode:  2,
+		},
+		"invalid header": {
+			args:      []string{"-H", "nothing", "/papi/v1/contracts"},
+			withError: `Invalid request: header has to be provided in "Name: value" format: nothing`,
+		},
+		"paginate": {
+			args:      []string{"--paginate", "--accountkey", "1-ABC", "/users"},
+			expected:  "{\n  \"page\": 1\n}\n{\n  \"page\": 2,\n  \"account\": \"1-ABC\",\n  \"links\": [\n    {\n      \"rel\": \"next\",\n      \"href\": \"/users?page=3\"\n    }\n  ]\n}\n{\n  \"page\": 3,\n  \"_links\": {\n    \"next\": {\n      \"href\": \"https://example.com/users?page=4\"\n    }\n  }\n}",
+			withError: "Unable to fetch next page: next page is on another host: https://example.com/users?page=4",
+		},
+		"cache": {
+			args:     []string{"--cache", "10m", "/counter/cached"},
+			init:     func(m *mocked) { m.cfg.On("GetValue", "cli", "cache-path").Return(dir, true) },
+			runs:     2,
+			expected: "{\n  \"requests\": 1\n}\n{\n  \"requests\": 1\n}",
+		},
+		"no cache": {
+			args:     []string{"/counter/uncached"},
+			runs:     2,
+			expected: "{\n  \"requests\": 1\n}\n{\n  \"requests\": 2\n}",
+		},
+		"no path": {
+			args:      []string{},
+			withError: "You must specify the request method and path",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			var output []string
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Run(func(args mock.Arguments) {
+				output = append(output, fmt.Sprint(args.Get(0).([]interface{})...))
+			})
+			command := &cli.Command{
+				Name:   "api",
+				Action: cmdAPI(srv.Client()),
+				Flags:  apiFlags(),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "api", "--edgerc", edgerc)
+			args = append(args, test.args...)
+
+			if test.init != nil {
+				test.init(m)
+			}
+			err := app.RunContext(ctx, args)
+			for i := 1; i < test.runs; i++ {
+				require.NoError(t, err)
+				err = app.RunContext(ctx, args)
+			}
+
+			m.cfg.AssertExpectations(t)
+			actual := strings.Join(output, "\n")
+			if strings.Contains(test.expected, "Date: *") {
+				lines := strings.Split(actual, "\n")
+				for i, line := range lines {
+					if strings.HasPrefix(line, "Date: ") {
+						lines[i] = "Date: *"
+					}
+				}
+				actual = strings.Join(lines, "\n")
+			}
+			assert.Equal(t, test.expected, actual)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				if test.exitCode != 0 {
+					assert.Equal(t, test.exitCode, err.(cli.ExitCoder).ExitCode())
+				}
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/keychain"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/warnings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	githubKeychainService = "akamai-cli"
+	githubKeychainAccount = "github.com"
+
+	// githubUserKey is the config key holding the GitHub user of the token stored in the keychain, set while a token is stored
+	githubUserKey = "github-user"
+	// githubExpiresKey is the config key holding the expiry of the stored token in RFC 3339 format, empty if it does not expire
+	githubExpiresKey = "github-expires"
+
+	// githubExpiryWarning is how long before its expiry a warning is printed whenever the token is used
+	githubExpiryWarning = 7 * 24 * time.Hour
+	// githubAppTokenLifetime is the lifetime of GitHub App installation tokens, which GitHub does not report
+	githubAppTokenLifetime = time.Hour
+)
+
+// githubAPI is the base URL of the GitHub REST API
+var githubAPI = "https://api.github.com"
+
+// githubHosts are the hosts requests and git remotes of which are authenticated with the stored token
+var githubHosts = []string{"github.com", "api.github.com"}
+
+// githubReadScopes are the scopes of classic tokens allowing to read repositories and packages, Akamai CLI needs no other
+var githubReadScopes = map[string]bool{"repo": true, "public_repo": true, "read:packages": true, "read:org": true}
+
+// githubToken describes a GitHub token as reported by the GitHub API
+type githubToken struct {
+	Kind string
+	User string
+	// Scopes are the scopes of classic and OAuth tokens, nil for tokens which have permissions instead
+	Scopes    []string
+	Expires   time.Time
+	RateLimit int
+}
+
+func cmdAuthGitHub(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("AUTH GITHUB START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("AUTH GITHUB FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("AUTH GITHUB ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	if c.Args().Present() {
+		return cli.Exit(color.RedString("Unknown action %q, expected \"status\" or \"logout\"", c.Args().First()), 1)
+	}
+
+	token, err := term.Ask(terminal.Question{Message: "GitHub token:", Masked: true, Validate: terminal.Required})
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read the token: %s", err), 1)
+	}
+	token = strings.TrimSpace(token)
+
+	term.Spinner().Start("Validating the token with GitHub...")
+	info, err := inspectGitHubToken(c.Context, token)
+	if err != nil {
+		term.Spinner().Fail()
+		return cli.Exit(color.RedString("Unable to validate the token: %s", err), 1)
+	}
+	for _, repo := range c.StringSlice("repo") {
+		if err := checkGitHubRepoAccess(c.Context, token, repo); err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to validate the token: %s", err), 1)
+		}
+	}
+	term.Spinner().OK()
+
+	if broad := info.broadScopes(); len(broad) > 0 && !c.Bool("force") {
+		return cli.Exit(color.RedString("The token has scopes Akamai CLI does not need: %s. Create a fine-grained token with read-only access to repository contents, or use --force to store it anyway", strings.Join(broad, ", ")), 1)
+	}
+	for _, warning := range info.warnings(time.Now()) {
+		term.Writeln(color.YellowString("Warning: %s", warning))
+	}
+
+	if err := keychain.Get(c.Context).Set(githubKeychainService, githubKeychainAccount, token); err != nil {
+		msg := fmt.Sprintf("Unable to store the token in the keychain: %s", err)
+		if errors.Is(err, keychain.ErrUnsupported) {
+			msg += fmt.Sprintf(". Set %s instead", git.TokenEnv)
+		}
+		return cli.Exit(color.RedString(msg), 1)
+	}
+	cfg := config.Get(c.Context)
+	cfg.SetValue("cli", githubUserKey, info.account())
+	if info.Expires.IsZero() {
+		cfg.UnsetValue("cli", githubExpiresKey)
+	} else {
+		cfg.SetValue("cli", githubExpiresKey, info.Expires.UTC().Format(time.RFC3339))
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		return cli.Exit(color.RedString("Unable to save config: %s", err), 1)
+	}
+	term.Printf("Stored the %s token of %s in the keychain, it is used for GitHub repositories and downloads.\n", info.Kind, color.BlueString(info.account()))
+	return nil
+}
+
+func cmdAuthGitHubStatus(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("AUTH GITHUB STATUS START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("AUTH GITHUB STATUS FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("AUTH GITHUB STATUS ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	if git.EnvToken(githubHosts[0]) != "" {
+		term.Writeln(color.CyanString("%s is set, it is used instead of the stored token.", git.TokenEnv))
+	}
+	if _, ok := config.Get(c.Context).GetValue("cli", githubUserKey); !ok {
+		return cli.Exit(color.RedString("No GitHub token is stored. Run \"%s auth github\" to store one.", tools.Self()), 1)
+	}
+	token, err := keychain.Get(c.Context).Get(githubKeychainService, githubKeychainAccount)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read the token from the keychain: %s. Run \"%s auth github\" to store it again.", err, tools.Self()), 1)
+	}
+	info, err := inspectGitHubToken(c.Context, token)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to validate the token: %s. Run \"%s auth github\" to replace it.", err, tools.Self()), 1)
+	}
+
+	term.Printf("User: %s\n", info.account())
+	term.Printf("Token: %s\n", info.Kind)
+	if info.Scopes != nil {
+		scopes := strings.Join(info.Scopes, ", ")
+		if scopes == "" {
+			scopes = "none"
+		}
+		term.Printf("Scopes: %s\n", scopes)
+	}
+	if info.Expires.IsZero() {
+		term.Printf("Expires: never\n")
+	} else {
+		term.Printf("Expires: %s\n", info.Expires.Local().Format("2006-01-02 15:04 MST"))
+	}
+	term.Printf("Rate limit: %d requests per hour\n", info.RateLimit)
+	for _, warning := range info.warnings(time.Now()) {
+		term.Writeln(color.YellowString("Warning: %s", warning))
+	}
+	return nil
+}
+
+func cmdAuthGitHubLogout(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("AUTH GITHUB LOGOUT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("AUTH GITHUB LOGOUT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("AUTH GITHUB LOGOUT ERROR: %v", e.Error())
+		}
+	}()
+
+	if err := removeGitHubToken(c.Context); err != nil {
+		return cli.Exit(color.RedString("Unable to remove the token: %s", err), 1)
+	}
+	if err := config.Get(c.Context).Save(c.Context); err != nil {
+		return cli.Exit(color.RedString("Unable to save config: %s", err), 1)
+	}
+	terminal.Get(c.Context).Writeln("Removed the GitHub token from the keychain.")
+	return nil
+}
+
+// removeGitHubToken deletes the stored token from the keychain and its details from the config, without saving it
+func removeGitHubToken(ctx context.Context) error {
+	if err := keychain.Get(ctx).Delete(githubKeychainService, githubKeychainAccount); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+		return err
+	}
+	cfg := config.Get(ctx)
+	cfg.UnsetValue("cli", githubUserKey)
+	cfg.UnsetValue("cli", githubExpiresKey)
+	return nil
+}
+
+// withGitHubToken returns a context in which GitHub repositories and downloads are authenticated with the token stored
+// by "auth github", warning when it is about to expire. Expired tokens are not used, nor is the stored token when
+// the token of git.TokenEnv is set for GitHub.
+func withGitHubToken(ctx context.Context) context.Context {
+	user, ok := config.Get(ctx).GetValue("cli", githubUserKey)
+	if !ok || git.EnvToken(githubHosts[0]) != "" {
+		return ctx
+	}
+	logger := log.FromContext(ctx)
+	if val, ok := config.Get(ctx).GetValue("cli", githubExpiresKey); ok && val != "" {
+		expires, err := time.Parse(time.RFC3339, val)
+		switch {
+		case err != nil:
+			logger.Warnf("Invalid value of cli.%s: %s", githubExpiresKey, val)
+		case !time.Now().Before(expires):
+			warnMsg := fmt.Sprintf("Warning: the GitHub token of %s expired on %s and is not used. Run \"%s auth github\" to replace it.", user, expires.Local().Format("2006-01-02"), tools.Self())
+			warnings.Warn(ctx, "github-token-expiry", warnMsg)
+			return ctx
+		case time.Until(expires) < githubExpiryWarning:
+			warnMsg := fmt.Sprintf("Warning: the GitHub token of %s expires on %s. Run \"%s auth github\" to replace it.", user, expires.Local().Format("2006-01-02 15:04"), tools.Self())
+			warnings.Warn(ctx, "github-token-expiry", warnMsg)
+		}
+	}
+	token, err := keychain.Get(ctx).Get(githubKeychainService, githubKeychainAccount)
+	if err != nil {
+		logger.Warnf("Unable to read the GitHub token from the keychain: %s", err)
+		return ctx
+	}
+	for _, host := range githubHosts {
+		ctx = download.WithHostToken(ctx, host, token)
+	}
+	return ctx
+}
+
+// inspectGitHubToken checks that GitHub accepts the token and reads its scopes, expiry and rate limit
+func inspectGitHubToken(ctx context.Context, token string) (*githubToken, error) {
+	info := &githubToken{Kind: githubTokenKind(token)}
+	resp, err := githubGet(ctx, token, "/rate_limit")
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(ctx, resp)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errors.New("GitHub rejected the token, it is invalid, expired or revoked")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from GitHub: %s", resp.Status)
+	}
+	var limits struct {
+		Rate struct {
+			Limit int `json:"limit"`
+		} `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&limits); err != nil {
+		return nil, fmt.Errorf("invalid response from GitHub: %w", err)
+	}
+	info.RateLimit = limits.Rate.Limit
+	if _, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		info.Scopes = make([]string, 0)
+		for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+		sort.Strings(info.Scopes)
+	}
+	if val := resp.Header.Get("GitHub-Authentication-Token-Expiration"); val != "" {
+		for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+			if expires, err := time.Parse(layout, val); err == nil {
+				info.Expires = expires
+				break
+			}
+		}
+	}
+	if info.Expires.IsZero() && strings.HasPrefix(token, "ghs_") {
+		info.Expires = time.Now().Add(githubAppTokenLifetime)
+	}
+
+	// installation tokens of GitHub Apps do not belong to a user
+	if userResp, err := githubGet(ctx, token, "/user"); err == nil {
+		defer closeBody(ctx, userResp)
+		var user struct {
+			Login string `json:"login"`
+		}
+		if userResp.StatusCode == http.StatusOK && json.NewDecoder(userResp.Body).Decode(&user) == nil {
+			info.User = user.Login
+		}
+	}
+	return info, nil
+}
+
+// checkGitHubRepoAccess verifies that the token can read the repository, given as owner/name
+func checkGitHubRepoAccess(ctx context.Context, token, repo string) error {
+	if strings.Count(repo, "/") != 1 {
+		return fmt.Errorf("invalid repository %q, expected owner/name", repo)
+	}
+	resp, err := githubGet(ctx, token, "/repos/"+repo)
+	if err != nil {
+		return err
+	}
+	defer closeBody(ctx, resp)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the token cannot read repository %s (%s)", repo, resp.Status)
+	}
+	return nil
+}
+
+func githubGet(ctx context.Context, token, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPI+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	client := &http.Client{Transport: download.LimitTransport(ctx, nil), Timeout: 30 * time.Second}
+	return client.Do(req)
+}
+
+func closeBody(ctx context.Context, resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.FromContext(ctx).Debugf("Unable to close response: %s", err)
+	}
+}
+
+// githubTokenKind names the type of the token from its prefix
+func githubTokenKind(token string) string {
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return "fine-grained"
+	case strings.HasPrefix(token, "ghp_"):
+		return "classic"
+	case strings.HasPrefix(token, "ghs_"):
+		return "GitHub App installation"
+	case strings.HasPrefix(token, "ghu_"):
+		return "GitHub App user"
+	case strings.HasPrefix(token, "gho_"):
+		return "OAuth"
+	}
+	return "GitHub"
+}
+
+// account returns the user of the token, or its type for tokens which do not belong to a user
+func (t githubToken) account() string {
+	if t.User != "" {
+		return t.User
+	}
+	return t.Kind
+}
+
+// broadScopes returns the scopes of the token beyond reading repositories and packages
+func (t githubToken) broadScopes() []string {
+	var broad []string
+	for _, scope := range t.Scopes {
+		if !githubReadScopes[scope] {
+			broad = append(broad, scope)
+		}
+	}
+	return broad
+}
+
+// warnings lists what limits the use of the token at given time
+func (t githubToken) warnings(now time.Time) []string {
+	var warnings []string
+	if t.Scopes != nil && !containsFold(t.Scopes, "repo") {
+		warnings = append(warnings, "the token has no \"repo\" scope, packages in private repositories cannot be installed")
+	}
+	if !t.Expires.IsZero() && t.Expires.Sub(now) < githubExpiryWarning {
+		warnings = append(warnings, fmt.Sprintf("the token expires on %s", t.Expires.Local().Format("2006-01-02 15:04")))
+	}
+	return warnings
+}
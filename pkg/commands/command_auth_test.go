@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/keychain"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/warnings"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdAuthGitHub(t *testing.T) {
+	expires := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch token {
+		case "github_pat_good":
+			w.Header().Set("GitHub-Authentication-Token-Expiration", expires.Format("2006-01-02 15:04:05 MST"))
+		case "ghp_broad":
+			w.Header().Set("X-OAuth-Scopes", "repo, delete_repo")
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/rate_limit":
+			_, _ = w.Write([]byte(`{"rate": {"limit": 5000}}`))
+		case "/user":
+			_, _ = w.Write([]byte(`{"login": "jdoe"}`))
+		case "/repos/acme/cli-internal":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	githubAPI = srv.URL
+	defer func() {
+		githubAPI = "https://api.github.com"
+	}()
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked, *keychain.Mock)
+		withError string
+	}{
+		"store fine-grained token": {
+			args: []string{"auth", "github", "--repo", "acme/cli-internal"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return(" github_pat_good\n", nil).Once()
+				kc.On("Set", githubKeychainService, githubKeychainAccount, "github_pat_good").Return(nil).Once()
+				m.cfg.On("SetValue", "cli", githubUserKey, "jdoe").Return().Once()
+				m.cfg.On("SetValue", "cli", githubExpiresKey, expires.Format(time.RFC3339)).Return().Once()
+				m.cfg.On("Save", mock.Anything).Return(nil).Once()
+				m.term.On("Printf", "Stored the %s token of %s in the keychain, it is used for GitHub repositories and downloads.\n", []interface{}{"fine-grained", color.BlueString("jdoe")}).Return().Once()
+			},
+		},
+		"broad scopes refused": {
+			args: []string{"auth", "github"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return("ghp_broad", nil).Once()
+			},
+			withError: "The token has scopes Akamai CLI does not need: delete_repo.",
+		},
+		"broad scopes with force": {
+			args: []string{"auth", "github", "--force"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return("ghp_broad", nil).Once()
+				kc.On("Set", githubKeychainService, githubKeychainAccount, "ghp_broad").Return(nil).Once()
+				m.cfg.On("SetValue", "cli", githubUserKey, "jdoe").Return().Once()
+				m.cfg.On("UnsetValue", "cli", githubExpiresKey).Return().Once()
+				m.cfg.On("Save", mock.Anything).Return(nil).Once()
+				m.term.On("Printf", mock.Anything, []interface{}{"classic", color.BlueString("jdoe")}).Return().Once()
+			},
+		},
+		"invalid token": {
+			args: []string{"auth", "github"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return("ghp_revoked", nil).Once()
+			},
+			withError: "Unable to validate the token: GitHub rejected the token, it is invalid, expired or revoked",
+		},
+		"repository cannot be read": {
+			args: []string{"auth", "github", "--repo", "acme/other"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return("github_pat_good", nil).Once()
+			},
+			withError: "the token cannot read repository acme/other (404 Not Found)",
+		},
+		"keychain not supported": {
+			args: []string{"auth", "github"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.term.On("Ask", mock.AnythingOfType("terminal.Question")).Return("github_pat_good", nil).Once()
+				kc.On("Set", githubKeychainService, githubKeychainAccount, "github_pat_good").Return(keychain.ErrUnsupported).Once()
+			},
+			withError: "Set " + git.TokenEnv + " instead",
+		},
+		"status": {
+			args: []string{"auth", "github", "status"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.cfg.On("GetValue", "cli", githubUserKey).Return("jdoe", true).Once()
+				kc.On("Get", githubKeychainService, githubKeychainAccount).Return("github_pat_good", nil).Once()
+				m.term.On("Printf", "User: %s\n", []interface{}{"jdoe"}).Return().Once()
+				m.term.On("Printf", "Token: %s\n", []interface{}{"fine-grained"}).Return().Once()
+				m.term.On("Printf", "Expires: %s\n", []interface{}{expires.Local().Format("2006-01-02 15:04 MST")}).Return().Once()
+				m.term.On("Printf", "Rate limit: %d requests per hour\n", []interface{}{5000}).Return().Once()
+			},
+		},
+		"status without token": {
+			args: []string{"auth", "github", "status"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				m.cfg.On("GetValue", "cli", githubUserKey).Return("", false).Once()
+			},
+			withError: `No GitHub token is stored. Run "commands.test auth github" to store one.`,
+		},
+		"logout": {
+			args: []string{"auth", "github", "logout"},
+			init: func(m *mocked, kc *keychain.Mock) {
+				kc.On("Delete", githubKeychainService, githubKeychainAccount).Return(keychain.ErrNotFound).Once()
+				m.cfg.On("UnsetValue", "cli", githubUserKey).Return().Once()
+				m.cfg.On("UnsetValue", "cli", githubExpiresKey).Return().Once()
+				m.cfg.On("Save", mock.Anything).Return(nil).Once()
+				m.term.On("Writeln", []interface{}{"Removed the GitHub token from the keychain."}).Return(0, nil).Once()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Unsetenv(git.TokenEnv))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			kc := &keychain.Mock{}
+			test.init(m, kc)
+			m.term.On("Spinner").Return(m.term).Maybe()
+			m.term.On("Start", mock.Anything, mock.Anything).Return().Maybe()
+			m.term.On("OK").Return().Maybe()
+			m.term.On("Fail").Return().Maybe()
+			command := &cli.Command{
+				Name: "auth",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "github",
+						Action: cmdAuthGitHub,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{Name: "force"},
+							&cli.StringSliceFlag{Name: "repo"},
+						},
+						Subcommands: []*cli.Command{
+							{Name: "status", Action: cmdAuthGitHubStatus},
+							{Name: "logout", Action: cmdAuthGitHubLogout},
+						},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+
+			err := app.RunContext(keychain.Context(ctx, kc), append(os.Args[0:1], test.args...))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			kc.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithGitHubToken(t *testing.T) {
+	tests := map[string]struct {
+		user     string
+		expires  time.Time
+		envToken string
+		keychain error
+		warning  string
+		expected string
+	}{
+		"no token stored": {},
+		"valid token": {
+			user:     "jdoe",
+			expires:  time.Now().Add(30 * 24 * time.Hour),
+			expected: "github_pat_good",
+		},
+		"token without expiry": {
+			user:     "jdoe",
+			expected: "github_pat_good",
+		},
+		"token expiring soon": {
+			user:     "jdoe",
+			expires:  time.Now().Add(48 * time.Hour),
+			warning:  "Warning: the GitHub token of jdoe expires on",
+			expected: "github_pat_good",
+		},
+		"expired token": {
+			user:    "jdoe",
+			expires: time.Now().Add(-time.Hour),
+			warning: "Warning: the GitHub token of jdoe expired on",
+		},
+		"token of the environment": {
+			user:     "jdoe",
+			envToken: "abc",
+		},
+		"keychain error": {
+			user:     "jdoe",
+			keychain: errors.New("locked"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv(git.TokenEnv, test.envToken))
+			defer func() {
+				require.NoError(t, os.Unsetenv(git.TokenEnv))
+			}()
+			term, cfg, kc := &terminal.Mock{}, &config.Mock{}, &keychain.Mock{}
+			cfg.On("GetValue", "cli", githubUserKey).Return(test.user, test.user != "")
+			if test.expires.IsZero() {
+				cfg.On("GetValue", "cli", githubExpiresKey).Return("", false).Maybe()
+			} else {
+				cfg.On("GetValue", "cli", githubExpiresKey).Return(test.expires.UTC().Format(time.RFC3339), true).Maybe()
+			}
+			kc.On("Get", githubKeychainService, githubKeychainAccount).Return("github_pat_good", test.keychain).Maybe()
+			cfg.On("GetValue", "cli", warnings.IntervalKey).Return("0", true).Maybe()
+			out := &bytes.Buffer{}
+			term.On("Error").Return(out).Maybe()
+			ctx := keychain.Context(terminal.Context(config.Context(context.Background(), cfg), term), kc)
+
+			ctx = withGitHubToken(ctx)
+			assert.Equal(t, test.expected, download.HostToken(ctx, "github.com"))
+			assert.Equal(t, test.expected, download.HostToken(ctx, "api.github.com"))
+			if test.warning != "" {
+				assert.Contains(t, out.String(), test.warning)
+				assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// environment variables holding first-run answers when Akamai CLI is not run in a terminal
+const (
+	statsEnv        = "AKAMAI_CLI_STATS"
+	upgradeCheckEnv = "AKAMAI_CLI_UPGRADE_CHECK"
+)
+
+// firstRunSettings contains answers to first-run prompts, empty values leave the setting unchanged
+type firstRunSettings struct {
+	stats        string
+	upgradeCheck string
+}
+
+func cmdBootstrap(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("BOOTSTRAP START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("BOOTSTRAP FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("BOOTSTRAP ERROR: %v", e.Error())
+			}
+		}()
+		c.Context = withGitHubToken(c.Context)
+		term := terminal.Get(c.Context)
+		cfg := config.Get(c.Context)
+
+		settings := firstRunSettings{stats: "off", upgradeCheck: "off"}
+		if c.IsSet("stats") {
+			settings.stats = c.String("stats")
+		} else if _, ok := cfg.GetValue("cli", "enable-cli-statistics"); ok {
+			settings.stats = ""
+		}
+		if c.IsSet("upgrade-check") {
+			settings.upgradeCheck = c.String("upgrade-check")
+		} else if _, ok := cfg.GetValue("cli", "last-upgrade-check"); ok {
+			settings.upgradeCheck = ""
+		}
+		if err := applyFirstRunSettings(c.Context, settings); err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		if _, ok := cfg.GetValue("cli", "install-in-path"); !ok {
+			cfg.SetValue("cli", "install-in-path", "no")
+			if err := cfg.Save(c.Context); err != nil {
+				return cli.Exit(color.RedString(err.Error()), 1)
+			}
+		}
+
+		toInstall, err := packagesToInstall(c.Context, c.StringSlice("install"))
+		if err != nil {
+			return err
+		}
+		if len(toInstall) > 0 {
+			if _, err := installPackages(c, gitRepo, langManager, toInstall); err != nil {
+				return err
+			}
+		}
+
+		if c.Bool("add-to-path") {
+			if err := installLaunchers(c.Context); err != nil {
+				return cli.Exit(color.RedString("Unable to add launchers of installed commands to PATH: %s", err), 1)
+			}
+			cfg.SetValue("cli", launchersInPathKey, "yes")
+			if err := cfg.Save(c.Context); err != nil {
+				return cli.Exit(color.RedString(err.Error()), 1)
+			}
+		}
+
+		term.Writeln(color.GreenString("Akamai CLI is ready to use."))
+		return nil
+	}
+}
+
+// FirstRunFromEnv configures settings normally asked for on first run using environment variables:
+// AKAMAI_CLI_STATS and AKAMAI_CLI_UPGRADE_CHECK, both accepting "on" or "off".
+// It is used instead of the prompts when Akamai CLI is not run in a terminal; settings which are already configured are left unchanged.
+func FirstRunFromEnv(ctx context.Context) error {
+	cfg := config.Get(ctx)
+	settings := firstRunSettings{
+		stats:        strings.TrimSpace(os.Getenv(statsEnv)),
+		upgradeCheck: strings.TrimSpace(os.Getenv(upgradeCheckEnv)),
+	}
+	if settings.stats != "" {
+		if _, ok := cfg.GetValue("cli", "enable-cli-statistics"); ok {
+			settings.stats = ""
+		}
+	}
+	if settings.upgradeCheck != "" {
+		if _, ok := cfg.GetValue("cli", "last-upgrade-check"); ok {
+			settings.upgradeCheck = ""
+		}
+	}
+	return applyFirstRunSettings(ctx, settings)
+}
+
+func applyFirstRunSettings(ctx context.Context, settings firstRunSettings) error {
+	cfg := config.Get(ctx)
+	if settings.upgradeCheck != "" {
+		enabled, err := tools.ParseSwitch(settings.upgradeCheck)
+		if err != nil {
+			return fmt.Errorf("upgrade check: %s", err)
+		}
+		lastUpgradeCheck := "ignore"
+		if enabled {
+			lastUpgradeCheck = "never"
+		}
+		cfg.SetValue("cli", "last-upgrade-check", lastUpgradeCheck)
+		if err := cfg.Save(ctx); err != nil {
+			return err
+		}
+	}
+	if settings.stats != "" {
+		enabled, err := tools.ParseSwitch(settings.stats)
+		if err != nil {
+			return fmt.Errorf("statistics: %s", err)
+		}
+		if err := stats.ConfigureStats(ctx, enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packagesToInstall returns the packages which are not installed yet, each of given values can list several packages separated by commas
+func packagesToInstall(ctx context.Context, values []string) ([]string, error) {
+	term := terminal.Get(ctx)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []string
+	for _, value := range values {
+		for _, pkg := range strings.Split(value, ",") {
+			if pkg = strings.TrimSpace(pkg); pkg != "" {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+	toInstall := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		dirName := strings.TrimSuffix(filepath.Base(tools.Githubize(pkg)), ".git")
+		if _, err := os.Stat(filepath.Join(srcPath, dirName)); err == nil {
+			term.Writeln(color.CyanString("Package \"%s\" is already installed, skipping", pkg))
+			continue
+		}
+		toInstall = append(toInstall, pkg)
+	}
+	return toInstall, nil
+}
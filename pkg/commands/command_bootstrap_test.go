@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdBootstrap(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"apply defaults": {
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("", false).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", "ignore").Return().Once()
+				m.cfg.On("Save").Return(nil).Times(3)
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+				m.cfg.On("SetValue", "cli", "enable-cli-statistics", "false").Return().Once()
+				m.cfg.On("GetValue", "cli", "install-in-path").Return("", false).Once()
+				m.cfg.On("SetValue", "cli", "install-in-path", "no").Return().Once()
+				m.term.On("Writeln", []interface{}{color.GreenString("Akamai CLI is ready to use.")}).Return(0, nil).Once()
+			},
+		},
+		"already configured, skip installed packages": {
+			args: []string{"--install", "cli-echo"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("ignore", true).Once()
+				m.cfg.On("GetValue", "cli", "install-in-path").Return("no", true).Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("Package \"%s\" is already installed, skipping", "cli-echo")}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{color.GreenString("Akamai CLI is ready to use.")}).Return(0, nil).Once()
+			},
+		},
+		"install packages separated by commas": {
+			args: []string{"--install", "cli-echo, cli-installed,", "--install", "cli-stale"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("ignore", true).Once()
+				m.cfg.On("GetValue", "cli", "install-in-path").Return("no", true).Once()
+				for _, pkg := range []string{"cli-echo", "cli-installed", "cli-stale"} {
+					m.term.On("Writeln", []interface{}{color.CyanString("Package \"%s\" is already installed, skipping", pkg)}).Return(0, nil).Once()
+				}
+				m.term.On("Writeln", []interface{}{color.GreenString("Akamai CLI is ready to use.")}).Return(0, nil).Once()
+			},
+		},
+		"explicit upgrade check overrides config": {
+			args: []string{"--upgrade-check", "on"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", "never").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.cfg.On("GetValue", "cli", "install-in-path").Return("no", true).Once()
+				m.term.On("Writeln", []interface{}{color.GreenString("Akamai CLI is ready to use.")}).Return(0, nil).Once()
+			},
+		},
+		"invalid stats value": {
+			args: []string{"--stats", "maybe"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("ignore", true).Once()
+			},
+			withError: `statistics: invalid value "maybe"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name:   "bootstrap",
+				Action: cmdBootstrap(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "stats"},
+					&cli.StringFlag{Name: "upgrade-check"},
+					&cli.StringSliceFlag{Name: "install"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "bootstrap")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,515 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// completionShells are the shells completion scripts are generated for, with the extension of their cached script
+var completionShells = map[string]string{"bash": "bash", "zsh": "zsh", "fish": "fish", "powershell": "ps1"}
+
+// completionSafe matches the words which can be written in completion scripts without quoting issues in any shell
+var completionSafe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/+-]*$|^--?[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// completionEntry lists the words completed after the command at path, given as the command words separated by
+// spaces, empty for Akamai CLI itself
+type completionEntry struct {
+	path  string
+	words []completionWord
+	// dynamic commands complete their arguments themselves when run with --generate-auto-complete
+	dynamic bool
+}
+
+type completionWord struct {
+	value       string
+	description string
+}
+
+func cmdCompletion(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("COMPLETION START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("COMPLETION FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("COMPLETION ERROR: %v", e.Error())
+		}
+	}()
+	shell := c.Args().First()
+	if _, ok := completionShells[shell]; !ok || c.NArg() > 1 {
+		return cli.Exit(color.RedString("Specify the shell to generate completions for: %s", strings.Join(completionShellNames(), ", ")), 1)
+	}
+
+	if script, ok := cachedCompletionScript(shell); ok {
+		terminal.Get(c.Context).Printf("%s", script)
+		return nil
+	}
+	path, err := completionScriptPath(shell)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to locate Akamai CLI home: %s", err), 1)
+	}
+	script := completionScript(shell, path, completionEntries(c))
+	// the cached script is regenerated whenever packages change, so that shells loading it stay up-to-date
+	if err := writeCompletionScript(path, script); err != nil {
+		logger.Warnf("Unable to cache completion script: %s", err)
+	}
+	terminal.Get(c.Context).Printf("%s", script)
+	return nil
+}
+
+// refreshCompletions regenerates the cached completion scripts after packages are installed, updated or uninstalled.
+// Only scripts of shells completions were generated for with "akamai completion" are written.
+func refreshCompletions(c *cli.Context) {
+	logger := log.FromContext(c.Context)
+	var entries []completionEntry
+	for _, shell := range completionShellNames() {
+		path, err := completionScriptPath(shell)
+		if err != nil {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if entries == nil {
+			entries = completionEntries(c)
+		}
+		if err := writeCompletionScript(path, completionScript(shell, path, entries)); err != nil {
+			logger.Warnf("Unable to update %s completion script: %s", shell, err)
+			continue
+		}
+		logger.Debugf("Updated %s completion script %s", shell, path)
+	}
+}
+
+// CompletionCached returns true if the cached completion script of shell is current, so that "completion" prints it
+// without reading the cli.json of installed packages
+func CompletionCached(shell string) bool {
+	_, ok := cachedCompletionScript(shell)
+	return ok
+}
+
+// cachedCompletionScript returns the cached completion script of shell, if it was generated by this version of
+// Akamai CLI after packages were last added, removed, enabled or disabled
+func cachedCompletionScript(shell string) (string, bool) {
+	if _, ok := completionShells[shell]; !ok {
+		return "", false
+	}
+	path, err := completionScriptPath(shell)
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return "", false
+	}
+	storePath, err := packageStorePath()
+	if err != nil {
+		return "", false
+	}
+	for _, changed := range []string{srcPath, storePath} {
+		if changedInfo, err := os.Stat(changed); err == nil && changedInfo.ModTime().After(info.ModTime()) {
+			return "", false
+		}
+	}
+	script, err := ioutil.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(script), completionTitle(shell)) {
+		return "", false
+	}
+	return string(script), true
+}
+
+func completionShellNames() []string {
+	names := make([]string, 0, len(completionShells))
+	for shell := range completionShells {
+		names = append(names, shell)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completionScriptPath returns the location of the cached completion script of given shell
+func completionScriptPath(shell string) (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, "completion", "akamai."+completionShells[shell]), nil
+}
+
+func writeCompletionScript(path, script string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(script), 0644)
+}
+
+// completionEntries enumerates the built-in and installed commands with their subcommands and flags. Installed
+// commands are read from the cli.json of the packages, so that commands installed or uninstalled since Akamai CLI
+// started are accounted for.
+func completionEntries(c *cli.Context) []completionEntry {
+	installed, order := installedCompletionCommands()
+	root := completionEntry{}
+	entries := []completionEntry{{}}
+	seen := make(map[string]bool)
+	for _, cmd := range c.App.Commands {
+		if cmd.Hidden {
+			continue
+		}
+		if cmd.Category == color.YellowString("Installed Commands:") {
+			pkgCmd, ok := installed[cmd.Name]
+			if !ok || seen[cmd.Name] {
+				continue
+			}
+			seen[cmd.Name] = true
+			root.words, entries = appendInstalledCompletion(root.words, entries, pkgCmd)
+			continue
+		}
+		for _, name := range cmd.Names() {
+			root.words = append(root.words, completionWord{name, firstSentence(cmd.Description)})
+		}
+		entries = appendCommandCompletion(entries, "", cmd)
+	}
+	for _, name := range order {
+		if !seen[name] {
+			root.words, entries = appendInstalledCompletion(root.words, entries, installed[name])
+		}
+	}
+	root.words = append(root.words, flagCompletionWords(c.App.VisibleFlags())...)
+	entries[0] = root
+	return entries
+}
+
+// installedCompletionCommands reads the commands of installed packages by name, in the order of the packages
+func installedCompletionCommands() (map[string]command, []string) {
+	commands := make(map[string]command)
+	var order []string
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			name := strings.ToLower(cmd.Name)
+			if _, ok := commands[name]; ok {
+				continue
+			}
+			cmd.Name = name
+			commands[name] = cmd
+			order = append(order, name)
+		}
+	}
+	return commands, order
+}
+
+func appendCommandCompletion(entries []completionEntry, parent string, cmd *cli.Command) []completionEntry {
+	var words []completionWord
+	for _, sub := range cmd.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+		for _, name := range sub.Names() {
+			words = append(words, completionWord{name, firstSentence(sub.Description)})
+		}
+	}
+	flags := cmd.VisibleFlags()
+	if !cmd.HideHelp {
+		flags = append(flags, cli.HelpFlag)
+	}
+	words = append(words, flagCompletionWords(flags)...)
+	for _, name := range cmd.Names() {
+		path := strings.TrimSpace(parent + " " + name)
+		entries = append(entries, completionEntry{path: path, words: words})
+		for _, sub := range cmd.Subcommands {
+			if !sub.Hidden {
+				entries = appendCommandCompletion(entries, path, sub)
+			}
+		}
+	}
+	return entries
+}
+
+// appendInstalledCompletion adds the completion of an installed command, which supports the flags of all packages
+// and those listed in its cli.json
+func appendInstalledCompletion(rootWords []completionWord, entries []completionEntry, cmd command) ([]completionWord, []completionEntry) {
+	words := flagCompletionWords(append(plugin.Flags(), cli.HelpFlag))
+	for _, flag := range cmd.CompletionFlags {
+		flag = strings.TrimLeft(flag, "-")
+		if len(flag) == 1 {
+			flag = "-" + flag
+		} else {
+			flag = "--" + flag
+		}
+		words = append(words, completionWord{value: flag})
+	}
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		rootWords = append(rootWords, completionWord{name, firstSentence(cmd.Description)})
+		entries = append(entries, completionEntry{path: name, words: words, dynamic: cmd.AutoComplete})
+	}
+	return rootWords, entries
+}
+
+func flagCompletionWords(flags []cli.Flag) []completionWord {
+	var words []completionWord
+	for _, flag := range flags {
+		for _, name := range flag.Names() {
+			if name == cli.BashCompletionFlag.Names()[0] {
+				continue
+			}
+			if len(name) == 1 {
+				name = "-" + name
+			} else {
+				name = "--" + name
+			}
+			words = append(words, completionWord{value: name})
+		}
+	}
+	return words
+}
+
+// firstSentence shortens the description of a command to be shown next to completions
+func firstSentence(description string) string {
+	if i := strings.IndexAny(description, ".\n"); i >= 0 {
+		description = description[:i]
+	}
+	if runes := []rune(description); len(runes) > 60 {
+		description = string(runes[:57]) + "..."
+	}
+	return strings.TrimSpace(description)
+}
+
+// completionValues returns the words of the entry which can be safely written in scripts, without duplicates
+func completionValues(entry completionEntry) []completionWord {
+	words := make([]completionWord, 0, len(entry.words))
+	seen := make(map[string]bool)
+	for _, word := range entry.words {
+		if seen[word.value] || !completionSafe.MatchString(word.value) {
+			continue
+		}
+		seen[word.value] = true
+		words = append(words, word)
+	}
+	return words
+}
+
+// completionScript generates the script completing the commands of entries in given shell
+func completionScript(shell, path string, entries []completionEntry) string {
+	var valid []completionEntry
+	for _, entry := range entries {
+		if entry.path == "" || completionSafe.MatchString(strings.ReplaceAll(entry.path, " ", "-")) {
+			valid = append(valid, entry)
+		}
+	}
+	switch shell {
+	case "zsh":
+		return zshCompletion(path, valid)
+	case "fish":
+		return fishCompletion(path, valid)
+	case "powershell":
+		return powershellCompletion(path, valid)
+	default:
+		return bashCompletion(path, valid)
+	}
+}
+
+func completionHeader(shell, load string) string {
+	return fmt.Sprintf("%s, generated with \"%s completion %s\"\n"+
+		"# %s\n"+
+		"# The script is regenerated when packages are installed, updated or uninstalled.\n\n", completionTitle(shell), tools.Self(), shell, load)
+}
+
+// completionTitle starts the completion scripts, the version tells whether a cached script is current
+func completionTitle(shell string) string {
+	return fmt.Sprintf("# Akamai CLI %s completion for %s", version.Version, shell)
+}
+
+// shellWordsFunction writes the function printing the words completed after a command path, shared by bash and zsh
+func shellWordsFunction(entries []completionEntry) string {
+	b := &strings.Builder{}
+	b.WriteString("_akamai_cli_words() {\n    case \"$1\" in\n")
+	for _, entry := range entries {
+		values := make([]string, 0, len(entry.words))
+		for _, word := range completionValues(entry) {
+			values = append(values, word.value)
+		}
+		fmt.Fprintf(b, "    %q) echo \"%s\" ;;\n", entry.path, strings.Join(values, " "))
+	}
+	b.WriteString("    *) return 1 ;;\n    esac\n}\n\n")
+
+	b.WriteString("_akamai_cli_dynamic() {\n")
+	var dynamic []string
+	for _, entry := range entries {
+		if entry.dynamic {
+			dynamic = append(dynamic, fmt.Sprintf("%q", entry.path))
+		}
+	}
+	if len(dynamic) > 0 {
+		fmt.Fprintf(b, "    case \"$1\" in\n    %s) return 0 ;;\n    esac\n", strings.Join(dynamic, "|"))
+	}
+	b.WriteString("    return 1\n}\n\n")
+	return b.String()
+}
+
+func bashCompletion(path string, entries []completionEntry) string {
+	return completionHeader("bash", fmt.Sprintf("Load it in ~/.bashrc with: source %q", path)) +
+		shellWordsFunction(entries) +
+		`_akamai_cli_complete() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" cmdpath="" word i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        word="${COMP_WORDS[i]}"
+        if [[ "$word" != -* ]] && _akamai_cli_words "${cmdpath:+$cmdpath }$word" >/dev/null; then
+            cmdpath="${cmdpath:+$cmdpath }$word"
+        fi
+    done
+    if _akamai_cli_dynamic "$cmdpath"; then
+        COMPREPLY=( $(compgen -W "$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-auto-complete 2>/dev/null)" -- "$cur") )
+        return 0
+    fi
+    COMPREPLY=( $(compgen -W "$(_akamai_cli_words "$cmdpath")" -- "$cur") )
+}
+
+complete -F _akamai_cli_complete ` + tools.Self() + "\n"
+}
+
+func zshCompletion(path string, entries []completionEntry) string {
+	return completionHeader("zsh", fmt.Sprintf("Load it in ~/.zshrc with: source %q", path)) +
+		shellWordsFunction(entries) +
+		`_akamai_cli_complete() {
+    local cmdpath="" word
+    local -a values
+    for word in "${(@)words[2,CURRENT-1]}"; do
+        if [[ "$word" != -* ]] && _akamai_cli_words "${cmdpath:+$cmdpath }$word" >/dev/null; then
+            cmdpath="${cmdpath:+$cmdpath }$word"
+        fi
+    done
+    if _akamai_cli_dynamic "$cmdpath"; then
+        values=( ${(f)"$("${(@)words[1,CURRENT-1]}" --generate-auto-complete 2>/dev/null)"} )
+    else
+        values=( ${=$(_akamai_cli_words "$cmdpath")} )
+    fi
+    compadd -a values
+}
+
+(( $+functions[compdef] )) || { autoload -U compinit && compinit }
+compdef _akamai_cli_complete ` + tools.Self() + "\n"
+}
+
+func fishCompletion(path string, entries []completionEntry) string {
+	quote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	b := &strings.Builder{}
+	b.WriteString(completionHeader("fish", fmt.Sprintf("Load it in ~/.config/fish/config.fish with: source %s", quote(path))))
+	b.WriteString("function __akamai_cli_words\n    switch \"$argv\"\n")
+	var dynamic []string
+	for _, entry := range entries {
+		fmt.Fprintf(b, "        case %s\n", quote(entry.path))
+		words := completionValues(entry)
+		if len(words) == 0 {
+			b.WriteString("            return 0\n")
+		} else {
+			b.WriteString("            printf '%s\\t%s\\n'")
+			for _, word := range words {
+				fmt.Fprintf(b, " %s %s", quote(word.value), quote(word.description))
+			}
+			b.WriteString("\n")
+		}
+		if entry.dynamic {
+			dynamic = append(dynamic, quote(entry.path))
+		}
+	}
+	b.WriteString("        case '*'\n            return 1\n    end\nend\n\n")
+	b.WriteString(`function __akamai_cli_complete
+    set -l tokens (commandline -opc)
+    set -l cmdpath
+    for word in $tokens[2..-1]
+        if not string match -q -- '-*' $word; and __akamai_cli_words $cmdpath $word >/dev/null
+            set cmdpath $cmdpath $word
+        end
+    end
+    if contains -- "$cmdpath" ` + strings.Join(dynamic, " ") + `
+        $tokens --generate-auto-complete 2>/dev/null
+        return
+    end
+    __akamai_cli_words $cmdpath
+end
+
+complete -c ` + tools.Self() + " -f -a '(__akamai_cli_complete)'\n")
+	return b.String()
+}
+
+func powershellCompletion(path string, entries []completionEntry) string {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	b := &strings.Builder{}
+	b.WriteString(completionHeader("powershell", fmt.Sprintf("Load it in your $PROFILE with: . %s", quote(path))))
+	fmt.Fprintf(b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", quote(tools.Self()))
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n    $completions = @{\n")
+	var dynamic []string
+	for _, entry := range entries {
+		values := make([]string, 0, len(entry.words))
+		for _, word := range completionValues(entry) {
+			values = append(values, quote(word.value))
+		}
+		fmt.Fprintf(b, "        %s = @(%s)\n", quote(entry.path), strings.Join(values, ", "))
+		if entry.dynamic {
+			dynamic = append(dynamic, quote(entry.path))
+		}
+	}
+	fmt.Fprintf(b, "    }\n    $dynamic = @(%s)\n", strings.Join(dynamic, ", "))
+	b.WriteString(`    $elements = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+    $cmdpath = ''
+    foreach ($word in ($elements | Select-Object -Skip 1)) {
+        $candidate = "$cmdpath $word".Trim()
+        if (-not $word.StartsWith('-') -and $completions.ContainsKey($candidate)) {
+            $cmdpath = $candidate
+        }
+    }
+    if ($dynamic -contains $cmdpath) {
+        $arguments = @($elements | Select-Object -Skip 1)
+        $words = & $elements[0] @arguments --generate-auto-complete 2>$null
+    } else {
+        $words = $completions[$cmdpath]
+    }
+    $words | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)
+	return b.String()
+}
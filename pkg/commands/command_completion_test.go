@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func completionTestCommands() []*cli.Command {
+	return []*cli.Command{
+		{Name: "completion", Action: cmdCompletion},
+		{
+			Name:        "config",
+			Description: "Manage configuration",
+			Subcommands: []*cli.Command{
+				{Name: "get", Description: "Get a setting"},
+				{Name: "set", Description: "Set a setting", Flags: []cli.Flag{&cli.BoolFlag{Name: "force"}}},
+				{Name: "internal", Hidden: true},
+			},
+		},
+		{Name: "list", Aliases: []string{"ls"}, Flags: []cli.Flag{&cli.BoolFlag{Name: "remote"}, &cli.BoolFlag{Name: "secret", Hidden: true}}},
+		{Name: "dns", Category: color.YellowString("Installed Commands:")},
+		{Name: "removed", Category: color.YellowString("Installed Commands:")},
+	}
+}
+
+func TestCmdCompletion(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		expected  []string
+		withError string
+	}{
+		"bash": {
+			args: []string{"bash"},
+			expected: []string{
+				`    "") echo "completion config list ls dns help h property --help -h" ;;`,
+				`    "config") echo "get set --help -h" ;;`,
+				`    "config set") echo "--force --help -h" ;;`,
+				`    "list") echo "--remote --help -h" ;;`,
+				`    "ls") echo "--remote --help -h" ;;`,
+				`    "dns") echo "--edgerc --section --accountkey --json --help -h --zone -y" ;;`,
+				`    "property") return 0 ;;`,
+				"complete -F _akamai_cli_complete commands.test",
+			},
+		},
+		"zsh": {
+			args: []string{"zsh"},
+			expected: []string{
+				`    "config") echo "get set --help -h" ;;`,
+				"compdef _akamai_cli_complete commands.test",
+			},
+		},
+		"fish": {
+			args: []string{"fish"},
+			expected: []string{
+				`        case 'config'`,
+				`            printf '%s\t%s\n' 'get' 'Get a setting' 'set' 'Set a setting' '--help' '' '-h' ''`,
+				`    if contains -- "$cmdpath" 'property'`,
+				"complete -c commands.test -f -a '(__akamai_cli_complete)'",
+			},
+		},
+		"powershell": {
+			args: []string{"powershell"},
+			expected: []string{
+				`        'config' = @('get', 'set', '--help', '-h')`,
+				`    $dynamic = @('property')`,
+				"Register-ArgumentCompleter -Native -CommandName 'commands.test' -ScriptBlock {",
+			},
+		},
+		"no shell": {
+			withError: "Specify the shell to generate completions for: bash, fish, powershell, zsh",
+		},
+		"unknown shell": {
+			args:      []string{"tcsh"},
+			withError: "Specify the shell to generate completions for: bash, fish, powershell, zsh",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+			writeStorePackage(t, srcDir, "cli-dns", `{"commands": [{"name": "dns", "description": "Manage DNS zones. Long description", "flags": ["zone", "-y"]}]}`)
+			writeStorePackage(t, srcDir, "cli-property", `{"commands": [{"name": "property", "auto-complete": true}]}`)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			var output string
+			m.term.On("Printf", "%s", mock.Anything).Return().Run(func(args mock.Arguments) {
+				output += fmt.Sprint(args.Get(1).([]interface{})...)
+			}).Maybe()
+			app, ctx := setupTestApp(completionTestCommands()[0], m)
+			app.Commands = completionTestCommands()
+
+			err := app.RunContext(ctx, append([]string{os.Args[0], "completion"}, test.args...))
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			for _, line := range test.expected {
+				assert.Contains(t, output, line+"\n")
+			}
+			assert.NotContains(t, output, "removed")
+			assert.NotContains(t, output, "internal")
+			assert.NotContains(t, output, "secret")
+			cached, err := ioutil.ReadFile(filepath.Join(cliHome, ".akamai-cli", "completion", "akamai."+completionShells[test.args[0]]))
+			require.NoError(t, err)
+			assert.Equal(t, output, string(cached))
+		})
+	}
+}
+
+func TestRefreshCompletions(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	completionDir := filepath.Join(cliHome, ".akamai-cli", "completion")
+	require.NoError(t, os.MkdirAll(completionDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(completionDir, "akamai.bash"), []byte("outdated"), 0644))
+	writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns"}]}`)
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+	app, ctx := setupTestApp(&cli.Command{Name: "install", Action: func(c *cli.Context) error {
+		refreshCompletions(c)
+		return nil
+	}}, m)
+	require.NoError(t, app.RunContext(ctx, []string{os.Args[0], "install"}))
+
+	script, err := ioutil.ReadFile(filepath.Join(completionDir, "akamai.bash"))
+	require.NoError(t, err)
+	assert.Contains(t, string(script), `    "") echo "install help h dns --help -h" ;;`)
+	files, err := ioutil.ReadDir(completionDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "scripts of other shells are not generated")
+}
+
+func TestCachedCompletionScript(t *testing.T) {
+	current := completionTitle("bash") + ", cached\n"
+	tests := map[string]struct {
+		script   string
+		modified time.Duration
+		cached   bool
+	}{
+		"current":                    {script: current, modified: time.Hour, cached: true},
+		"packages changed since":     {script: current, modified: -time.Hour},
+		"another version of the cli": {script: "# Akamai CLI 0.0.1 completion for bash, cached\n", modified: time.Hour},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns"}]}`)
+			path := filepath.Join(cliHome, ".akamai-cli", "completion", "akamai.bash")
+			require.NoError(t, writeCompletionScript(path, test.script))
+			modified := time.Now().Add(test.modified)
+			require.NoError(t, os.Chtimes(path, modified, modified))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			var output string
+			m.term.On("Printf", "%s", mock.Anything).Return().Run(func(args mock.Arguments) {
+				output += fmt.Sprint(args.Get(1).([]interface{})...)
+			})
+			app, ctx := setupTestApp(completionTestCommands()[0], m)
+			app.Commands = completionTestCommands()
+
+			assert.Equal(t, test.cached, CompletionCached("bash"))
+			require.NoError(t, app.RunContext(ctx, []string{os.Args[0], "completion", "bash"}))
+			if test.cached {
+				assert.Equal(t, test.script, output)
+				return
+			}
+			assert.True(t, strings.HasPrefix(output, completionTitle("bash")+", generated with"))
+			assert.Contains(t, output, `    "dns") echo`)
+			assert.True(t, CompletionCached("bash"), "the regenerated script is current")
+		})
+	}
+}
+
+func TestBashCompletionScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires bash")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("requires bash")
+	}
+	dir, err := ioutil.TempDir("", "cli-completion")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	// the dynamic completion of commands runs Akamai CLI itself
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "commands.test"), []byte("#!/bin/sh\necho \"--contract --group\"\n"), 0755))
+	entries := []completionEntry{
+		{words: []completionWord{{value: "config"}, {value: "property"}, {value: "--help"}}},
+		{path: "config", words: []completionWord{{value: "get"}, {value: "set"}}},
+		{path: "config set", words: []completionWord{{value: "--force"}}},
+		{path: "property", dynamic: true},
+	}
+	script := filepath.Join(dir, "akamai.bash")
+	require.NoError(t, ioutil.WriteFile(script, []byte(completionScript("bash", script, entries)), 0644))
+
+	tests := map[string]struct {
+		words    []string
+		expected string
+	}{
+		"commands":              {words: []string{""}, expected: "config property --help"},
+		"prefix":                {words: []string{"con"}, expected: "config"},
+		"subcommands":           {words: []string{"config", ""}, expected: "get set"},
+		"flags of a subcommand": {words: []string{"config", "set", "-"}, expected: "--force"},
+		"after flags":           {words: []string{"--help", "config", ""}, expected: "get set"},
+		"unknown argument":      {words: []string{"config", "set", "cli.proxy", "--f"}, expected: "--force"},
+		"dynamic command":       {words: []string{"property", "--c"}, expected: "--contract"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			words := append([]string{"commands.test"}, test.words...)
+			for i, word := range words {
+				words[i] = fmt.Sprintf("%q", word)
+			}
+			cmd := exec.Command("bash", "-c", fmt.Sprintf(`source %q; COMP_WORDS=(%s); COMP_CWORD=%d; _akamai_cli_complete; echo "${COMPREPLY[*]}"`,
+				script, strings.Join(words, " "), len(words)-1))
+			cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			assert.Equal(t, test.expected, strings.TrimSpace(string(out)))
+		})
+	}
+}
@@ -15,41 +15,72 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
-	"github.com/akamai/cli/pkg/log"
-	"github.com/fatih/color"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/akamai/cli/pkg/log"
+	"github.com/fatih/color"
+
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/stats"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/warnings"
 
 	"github.com/urfave/cli/v2"
 )
 
-func cmdConfigSet(c *cli.Context) (e error) {
-	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-	logger := log.WithCommand(c.Context, c.Command.Name)
-	start := time.Now()
-	logger.Debug("CONFIG SET START")
-	defer func() {
-		if e == nil {
-			logger.Debugf("CONFIG SET FINISH: %v", time.Now().Sub(start))
-		} else {
-			logger.Errorf("CONFIG SET ERROR: %v", e.Error())
+func cmdConfigSet(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		start := time.Now()
+		logger.Debug("CONFIG SET START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("CONFIG SET FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("CONFIG SET ERROR: %v", e.Error())
+			}
+		}()
+		cfg := config.Get(c.Context)
+		section, key, err := parseConfigPath(c)
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
 		}
-	}()
-	cfg := config.Get(c.Context)
-	section, key, err := parseConfigPath(c)
-	if err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
-	}
-	value := strings.Join(c.Args().Tail(), " ")
-	cfg.SetValue(section, key, value)
-	if err := cfg.Save(c.Context); err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		if isConfigPattern(section) || isConfigPattern(key) {
+			return cli.Exit(color.RedString("Unable to set config value: wildcards are not supported, provide the exact setting name"), 1)
+		}
+		value := strings.Join(c.Args().Tail(), " ")
+		if c.Args().Len() < 2 {
+			current, _ := cfg.GetValue(section, key)
+			if value, err = terminal.Get(c.Context).Ask(settingQuestion(section, key, current)); err != nil {
+				return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+			}
+		} else if err := validateSetting(section, key, value); err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		}
+		// the channel of a package is kept in the package store, next to the channel chosen with "package channel"
+		if key == channelKey {
+			if ok, err := setChannelSetting(c, gitRepo, section, value); ok {
+				return err
+			}
+		}
+		cfg.SetValue(section, key, value)
+		if err := cfg.Save(c.Context); err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
+		}
+		return nil
 	}
-	return nil
 }
 
 func cmdConfigGet(c *cli.Context) (e error) {
@@ -69,6 +100,17 @@ func cmdConfigGet(c *cli.Context) (e error) {
 	if err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Unable to get config value: %s", err)), 1)
 	}
+	if isConfigPattern(section) || isConfigPattern(key) {
+		matches, err := matchConfigKeys(cfg.Values(), section, key)
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to get config value: %s", err)), 1)
+		}
+		term := terminal.Get(c.Context)
+		for _, match := range matches {
+			term.Printf("%s.%s = %s\n", match.section, match.key, match.value)
+		}
+		return nil
+	}
 	val, _ := cfg.GetValue(section, key)
 	terminal.Get(c.Context).Writeln(val)
 	logger.Debug(val)
@@ -93,7 +135,20 @@ func cmdConfigUnset(c *cli.Context) (e error) {
 		return cli.Exit(color.RedString(fmt.Sprintf("Unable to unset config value: %s", err)), 1)
 	}
 
-	cfg.UnsetValue(section, key)
+	if isConfigPattern(section) || isConfigPattern(key) {
+		matches, err := matchConfigKeys(cfg.Values(), section, key)
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to unset config value: %s", err)), 1)
+		}
+		if len(matches) == 0 {
+			return nil
+		}
+		for _, match := range matches {
+			cfg.UnsetValue(match.section, match.key)
+		}
+	} else {
+		cfg.UnsetValue(section, key)
+	}
 	if err := cfg.Save(c.Context); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Unable to set config value: %s", err)), 1)
 	}
@@ -115,7 +170,33 @@ func cmdConfigList(c *cli.Context) (e error) {
 	cfg := config.Get(c.Context)
 	term := terminal.Get(c.Context)
 
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+
 	allValues := cfg.Values()
+	if format != plugin.FormatTable {
+		if c.NArg() > 0 {
+			sectionName := c.Args().First()
+			section, ok := allValues[sectionName]
+			if !ok {
+				section = map[string]string{}
+			}
+			allValues = map[string]map[string]string{sectionName: section}
+		}
+		rows := make([][]string, 0)
+		for sectionName, section := range allValues {
+			for key, value := range section {
+				rows = append(rows, []string{sectionName, key, value})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i][0] < rows[j][0] || rows[i][0] == rows[j][0] && rows[i][1] < rows[j][1]
+		})
+		return render(c, format, renderedOutput{Value: allValues, Columns: []string{"section", "key", "value"}, Rows: rows})
+	}
+
 	if c.NArg() > 0 {
 		sectionName := c.Args().First()
 		section, ok := allValues[sectionName]
@@ -146,3 +227,239 @@ func parseConfigPath(c *cli.Context) (string, string, error) {
 	key := strings.Join(path[1:], "-")
 	return section, key, nil
 }
+
+func cmdConfigEdit(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("CONFIG EDIT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("CONFIG EDIT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("CONFIG EDIT ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	configPath, err := config.FilePath()
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to edit config: %s", err)), 1)
+	}
+	original, err := ioutil.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to edit config: %s", err)), 1)
+	}
+
+	// changes are made on a copy, so that the config is left untouched until it passes validation
+	tmpFile, err := ioutil.TempFile("", "akamai-cli-config-*.ini")
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to edit config: %s", err)), 1)
+	}
+	defer func() {
+		if err := os.Remove(tmpFile.Name()); err != nil {
+			logger.Warnf("Unable to remove temporary file %s: %s", tmpFile.Name(), err)
+		}
+	}()
+	_, err = tmpFile.Write(original)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to edit config: %s", err)), 1)
+	}
+
+	for {
+		if err := runEditor(tmpFile.Name()); err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to run editor: %s", err)), 1)
+		}
+		edited, err := ioutil.ReadFile(tmpFile.Name())
+		if err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to edit config: %s", err)), 1)
+		}
+		if err := validateConfig(edited); err != nil {
+			term.WriteErrorf("Invalid config: %s", err.Error())
+			retry, err := term.Confirm("Would you like to edit it again", true)
+			if err != nil || !retry {
+				return cli.Exit(color.RedString("Config was not changed"), 1)
+			}
+			continue
+		}
+		if bytes.Equal(edited, original) {
+			term.Writeln("No changes made.")
+			return nil
+		}
+		if err := config.WriteFile(configPath, edited); err != nil {
+			return cli.Exit(color.RedString(fmt.Sprintf("Unable to save config: %s", err)), 1)
+		}
+		logger.Debugf("Config saved to %s", configPath)
+		return nil
+	}
+}
+
+// runEditor opens given file in the editor set in VISUAL or EDITOR environment variable and waits for it to exit
+func runEditor(file string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// durationSettings are cli settings holding durations such as "10m"
+var durationSettings = []string{cloneTimeoutKey, credentialsTTLKey, warnings.IntervalKey, installTimeoutKey, buildTimeoutKey, selftestTimeoutKey, hookTimeoutKey, heartbeatKey, git.NetworkTimeoutKey}
+
+// intervalSettings are cli settings holding intervals of periodic checks, such as "weekly" or "72h"
+var intervalSettings = []string{updateDigestKey, updateCheckKey, autoUpdateKey}
+
+// sizeSettings are cli settings holding sizes such as "500M", where "0" disables the limit
+var sizeSettings = []string{minFreeSpaceKey}
+
+// booleanSettings are cli settings which are either "true" or "false"
+var booleanSettings = []string{terminal.AccessibilityKey, allowHTTPTarballsKey, autoRepairKey, eventLogKey, "git-fallback", launchersKey, packageLogsKey, selftestKey, stats.DryRunKey, verifiedOnlyKey}
+
+// validateConfig verifies that config file contents can be parsed and settings with a known format have valid values
+func validateConfig(data []byte) error {
+	values, err := config.ParseValues(data)
+	if err != nil {
+		return err
+	}
+	for _, key := range append(append(append(durationSettings, intervalSettings...), sizeSettings...), deprecationsKey, networkPolicyKey, autoUpdateWindowKey) {
+		if err := validateSetting("cli", key, values["cli"][key]); err != nil {
+			return err
+		}
+	}
+	for key, val := range values[resourceLimitsSection] {
+		if err := validateSetting(resourceLimitsSection, key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSetting verifies the value of a setting with a known format, empty values are always valid
+func validateSetting(section, key, value string) error {
+	val := strings.TrimSpace(value)
+	if section == resourceLimitsSection && val != "" {
+		if _, err := parseResourceLimits(val); err != nil {
+			return fmt.Errorf("%s.%s: %w", section, key, err)
+		}
+		return nil
+	}
+	if section != "cli" || val == "" {
+		return nil
+	}
+	if containsFold(durationSettings, key) {
+		if timeout, err := time.ParseDuration(val); err != nil || timeout < 0 || timeout > 0 && timeout < minStepTimeout {
+			return fmt.Errorf("cli.%s: %q is not a valid duration, expected a value of at least %s such as 10m", key, val, minStepTimeout)
+		}
+	}
+	if containsFold(intervalSettings, key) {
+		if _, err := parseInterval(strings.ToLower(key), val); err != nil {
+			return err
+		}
+	}
+	if strings.EqualFold(key, deprecationsKey) && !containsFold([]string{deprecationsWarn, deprecationsError, deprecationsOff}, val) {
+		return fmt.Errorf("cli.%s: %q is not valid, expected %s, %s or %s", key, val, deprecationsWarn, deprecationsError, deprecationsOff)
+	}
+	if strings.EqualFold(key, networkPolicyKey) && !containsFold([]string{networkPolicyOff, networkPolicyAudit, networkPolicyEnforce}, val) {
+		return fmt.Errorf("cli.%s: %q is not valid, expected %s, %s or %s", key, val, networkPolicyOff, networkPolicyAudit, networkPolicyEnforce)
+	}
+	if strings.EqualFold(key, autoUpdateWindowKey) {
+		if _, err := parseMaintenanceWindow(val); err != nil {
+			return fmt.Errorf("cli.%s: %w", key, err)
+		}
+	}
+	if containsFold(sizeSettings, key) && val != "0" {
+		if _, err := parseSize(val); err != nil {
+			return fmt.Errorf("cli.%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// settingQuestion asks for the value of a setting, offering a choice for boolean settings and hiding secrets
+func settingQuestion(section, key, current string) terminal.Question {
+	q := terminal.Question{
+		Message: fmt.Sprintf("Value of %s.%s:", section, key),
+		Default: current,
+		Validate: func(answer string) error {
+			return validateSetting(section, key, answer)
+		},
+	}
+	if section == "cli" && containsFold(durationSettings, key) {
+		q.Help = "A duration such as 90s or 10m, leave empty to remove the limit"
+	}
+	if section == "cli" && containsFold(sizeSettings, key) {
+		q.Help = "A size such as 500M or 2G, 0 to disable the check"
+	}
+	if section == "cli" && containsFold(intervalSettings, key) {
+		q.Options = []string{"weekly", "daily", "off"}
+	}
+	if section == "cli" && strings.EqualFold(key, autoUpdateWindowKey) {
+		q.Help = "Days and hours in local time such as sat-sun or mon-fri 01:00-05:00, leave empty to update at any time"
+	}
+	if section == "cli" && strings.EqualFold(key, deprecationsKey) {
+		q.Options = []string{deprecationsWarn, deprecationsError, deprecationsOff}
+	}
+	if section == "cli" && strings.EqualFold(key, networkPolicyKey) {
+		q.Options = []string{networkPolicyOff, networkPolicyAudit, networkPolicyEnforce}
+	}
+	if section == resourceLimitsSection {
+		q.Help = "Limits such as memory=512M cpu=5m files=256, leave empty to remove them"
+	}
+	if section == "cli" && containsFold(booleanSettings, key) {
+		q.Options = []string{"true", "false"}
+	}
+	if maskEnvValue(key, "value") == maskedValue {
+		q.Masked = true
+	}
+	return q
+}
+
+type configEntry struct {
+	section, key, value string
+}
+
+// isConfigPattern returns true if given section or key name contains glob wildcards
+func isConfigPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// matchConfigKeys returns all settings with section and key matching given glob patterns, sorted by name
+func matchConfigKeys(values map[string]map[string]string, sectionPattern, keyPattern string) ([]configEntry, error) {
+	for _, pattern := range []string{sectionPattern, keyPattern} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %s", pattern)
+		}
+	}
+	matches := make([]configEntry, 0)
+	for section, keys := range values {
+		if ok, _ := path.Match(sectionPattern, section); !ok {
+			continue
+		}
+		for key, value := range keys {
+			if ok, _ := path.Match(keyPattern, key); ok {
+				matches = append(matches, configEntry{section: section, key: key, value: value})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].section != matches[j].section {
+			return matches[i].section < matches[j].section
+		}
+		return matches[i].key < matches[j].key
+	})
+	return matches, nil
+}
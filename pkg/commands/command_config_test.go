@@ -5,38 +5,97 @@ import (
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestCmdConfigSet(t *testing.T) {
 	tests := map[string]struct {
 		args      []string
-		init      func(*config.Mock)
+		init      func(*mocked)
 		withError string
 	}{
 		"set config no error": {
 			args: []string{"cli.testKey", "testValue"},
-			init: func(m *config.Mock) {
-				m.On("SetValue", "cli", "testKey", "testValue").Return().Once()
-				m.On("Save").Return(nil).Once()
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", "cli", "testKey", "testValue").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
 			},
 		},
 		"key format error": {
 			args:      []string{"cli", "testKey", "testValue"},
-			init:      func(m *config.Mock) {},
+			init:      func(m *mocked) {},
 			withError: "Unable to set config value: section key has to be provided in <section>.<key> format",
 		},
+		"wildcard in key": {
+			args:      []string{"cli.test*", "testValue"},
+			init:      func(m *mocked) {},
+			withError: "Unable to set config value: wildcards are not supported",
+		},
 		"error on save": {
 			args: []string{"cli.testKey", "testValue"},
-			init: func(m *config.Mock) {
-				m.On("SetValue", "cli", "testKey", "testValue").Return().Once()
-				m.On("Save").Return(fmt.Errorf("save error")).Once()
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", "cli", "testKey", "testValue").Return().Once()
+				m.cfg.On("Save").Return(fmt.Errorf("save error")).Once()
 			},
 			withError: "save error",
 		},
+		"invalid duration": {
+			args:      []string{"cli.clone-timeout", "soon"},
+			init:      func(m *mocked) {},
+			withError: `cli.clone-timeout: "soon" is not a valid duration`,
+		},
+		"duration below minimum": {
+			args:      []string{"cli.heartbeat-interval", "1ns"},
+			init:      func(m *mocked) {},
+			withError: `cli.heartbeat-interval: "1ns" is not a valid duration, expected a value of at least 1ms`,
+		},
+		"prompt for value": {
+			args: []string{"cli.clone-timeout"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("5m", true).Once()
+				m.term.On("Ask", mock.MatchedBy(func(q terminal.Question) bool {
+					return q.Default == "5m" && !q.Masked && q.Validate("soon") != nil && q.Validate("10m") == nil
+				})).Return("10m", nil).Once()
+				m.cfg.On("SetValue", "cli", "clone-timeout", "10m").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"prompt for boolean value": {
+			args: []string{"cli.verified-only"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Ask", mock.MatchedBy(func(q terminal.Question) bool {
+					return len(q.Options) == 2 && q.Options[0] == "true"
+				})).Return("true", nil).Once()
+				m.cfg.On("SetValue", "cli", "verified-only", "true").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"prompt for secret value": {
+			args: []string{"broker.api-token"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "broker", "api-token").Return("", false).Once()
+				m.term.On("Ask", mock.MatchedBy(func(q terminal.Question) bool {
+					return q.Masked
+				})).Return("abc", nil).Once()
+				m.cfg.On("SetValue", "broker", "api-token", "abc").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"prompt without terminal": {
+			args: []string{"cli.testKey"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "testKey").Return("", false).Once()
+				m.term.On("Ask", mock.Anything).Return("", terminal.ErrNonInteractive).Once()
+			},
+			withError: "Unable to set config value",
+		},
 	}
 
 	for name, test := range tests {
@@ -47,7 +106,7 @@ func TestCmdConfigSet(t *testing.T) {
 				Subcommands: []*cli.Command{
 					{
 						Name:   "set",
-						Action: cmdConfigSet,
+						Action: cmdConfigSet(nil),
 					},
 				},
 			}
@@ -56,10 +115,11 @@ func TestCmdConfigSet(t *testing.T) {
 			args = append(args, "config", "set")
 			args = append(args, test.args...)
 
-			test.init(m.cfg)
+			test.init(m)
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
@@ -89,6 +149,24 @@ func TestCmdConfigGet(t *testing.T) {
 			init:      func(m *mocked) {},
 			withError: "Unable to get config value: section key has to be provided in <section>.<key> format",
 		},
+		"get values matching pattern": {
+			args: []string{"cli.*-timeout"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"clone-timeout": "1m", "build-timeout": "5m", "proxy": "http://proxy"},
+					"test": {"install-timeout": "10m"},
+				}).Once()
+				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"cli", "build-timeout", "5m"}).Return().Once()
+				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"cli", "clone-timeout", "1m"}).Return().Once()
+			},
+		},
+		"invalid pattern": {
+			args: []string{"cli.[timeout"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+			},
+			withError: "Unable to get config value: invalid pattern: [timeout",
+		},
 	}
 
 	for name, test := range tests {
@@ -141,6 +219,26 @@ func TestCmdConfigUnset(t *testing.T) {
 			init:      func(m *config.Mock) {},
 			withError: "Unable to unset config value: section key has to be provided in <section>.<key> format",
 		},
+		"unset values matching pattern": {
+			args: []string{"*.test*"},
+			init: func(m *config.Mock) {
+				m.On("Values").Return(map[string]map[string]string{
+					"cli":   {"testKey": "val1", "other": "val2"},
+					"stats": {"testKey2": "val3"},
+				}).Once()
+				m.On("UnsetValue", "cli", "testKey").Return().Once()
+				m.On("UnsetValue", "stats", "testKey2").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"no values matching pattern": {
+			args: []string{"cli.abc*"},
+			init: func(m *config.Mock) {
+				m.On("Values").Return(map[string]map[string]string{
+					"cli": {"testKey": "val1"},
+				}).Once()
+			},
+		},
 		"error on save": {
 			args: []string{"cli.testKey", "testValue"},
 			init: func(m *config.Mock) {
@@ -219,6 +317,31 @@ func TestCmdConfigList(t *testing.T) {
 				}).Once()
 			},
 		},
+		"list specific section as json": {
+			args: []string{"--output", "json", "test"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1", "key2": "val2"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.term.On("Writeln", []interface{}{"{\n  \"test\": {\n    \"key3\": \"val3\"\n  }\n}"}).Return(0, nil).Once()
+			},
+		},
+		"list full config as yaml": {
+			args: []string{"--output", "yaml"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1", "key2": "val2"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.term.On("Printf", "%s", []interface{}{"cli:\n    key1: val1\n    key2: val2\ntest:\n    key3: val3\n"}).Return().Once()
+			},
+		},
+		"unsupported output format": {
+			args:      []string{"--output", "xml"},
+			init:      func(m *mocked) {},
+			withError: "Unsupported output format: xml",
+		},
 	}
 
 	for name, test := range tests {
@@ -230,6 +353,7 @@ func TestCmdConfigList(t *testing.T) {
 					{
 						Name:   "list",
 						Action: cmdConfigList,
+						Flags:  []cli.Flag{outputFlag()},
 					},
 				},
 			}
@@ -242,6 +366,103 @@ func TestCmdConfigList(t *testing.T) {
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigEdit(t *testing.T) {
+	initialConfig := "[cli]\nconfig-version = 1.1\n"
+	tests := map[string]struct {
+		editorScript   string
+		init           func(*mocked)
+		expectedConfig string
+		withError      string
+	}{
+		"save valid changes": {
+			editorScript:   "printf '[cli]\\nconfig-version = 1.1\\nbuild-timeout = 5m\\n' > \"$1\"",
+			init:           func(m *mocked) {},
+			expectedConfig: "[cli]\nconfig-version = 1.1\nbuild-timeout = 5m\n",
+		},
+		"no changes": {
+			editorScript: "exit 0",
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{"No changes made."}).Return(0, nil).Once()
+			},
+			expectedConfig: initialConfig,
+		},
+		"invalid syntax, do not edit again": {
+			editorScript: "printf '[cli\\n' > \"$1\"",
+			init: func(m *mocked) {
+				m.term.On("WriteErrorf", "Invalid config: %s", mock.Anything).Return().Once()
+				m.term.On("Confirm", "Would you like to edit it again", true).Return(false, nil).Once()
+			},
+			expectedConfig: initialConfig,
+			withError:      "Config was not changed",
+		},
+		"invalid timeout, do not edit again": {
+			editorScript: "printf '[cli]\\nclone-timeout = abc\\n' > \"$1\"",
+			init: func(m *mocked) {
+				m.term.On("WriteErrorf", "Invalid config: %s", []interface{}{`cli.clone-timeout: "abc" is not a valid duration, expected a value of at least 1ms such as 10m`}).Return().Once()
+				m.term.On("Confirm", "Would you like to edit it again", true).Return(false, nil).Once()
+			},
+			expectedConfig: initialConfig,
+			withError:      "Config was not changed",
+		},
+		"editor fails": {
+			editorScript:   "exit 1",
+			init:           func(m *mocked) {},
+			expectedConfig: initialConfig,
+			withError:      "Unable to run editor",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome, err := ioutil.TempDir("", "cli-home")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+			}()
+			configPath := filepath.Join(cliHome, ".akamai-cli", "config")
+			require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+			require.NoError(t, ioutil.WriteFile(configPath, []byte(initialConfig), 0644))
+			editor := filepath.Join(cliHome, "editor.sh")
+			require.NoError(t, ioutil.WriteFile(editor, []byte("#!/bin/sh\n"+test.editorScript+"\n"), 0755))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			require.NoError(t, os.Setenv("EDITOR", editor))
+			require.NoError(t, os.Unsetenv("VISUAL"))
+			defer func() {
+				require.NoError(t, os.Unsetenv("EDITOR"))
+			}()
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "edit",
+						Action: cmdConfigEdit,
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "config", "edit")
+
+			test.init(m)
+			err = app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			contents, readErr := ioutil.ReadFile(configPath)
+			require.NoError(t, readErr)
+			assert.Equal(t, test.expectedConfig, string(contents))
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
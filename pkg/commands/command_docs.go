@@ -0,0 +1,153 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// readmeNames are the file names looked up for the documentation of a package, in order of preference
+var readmeNames = []string{"README.md", "readme.md", "Readme.md", "README.markdown", "README"}
+
+// openBrowser opens given URL in the default web browser, it is a variable so that tests do not start a browser
+var openBrowser = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func cmdDocs(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("DOCS START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("DOCS FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("DOCS ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		term := terminal.Get(c.Context)
+
+		name := c.Args().First()
+		dir := installedPackageDir(c, langManager, name)
+		if dir == "" {
+			return packageNotFound(name)
+		}
+
+		if c.Bool("web") {
+			source, err := git.OriginURL(dir)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to find repository of package %s: %s", filepath.Base(dir), err), 1)
+			}
+			url := repositoryWebURL(source)
+			term.Writeln(fmt.Sprintf("Opening %s", url))
+			if err := openBrowser(url); err != nil {
+				return cli.Exit(color.RedString("Unable to open web browser: %s", err), 1)
+			}
+			return nil
+		}
+
+		readme := packageReadme(dir)
+		if readme == "" {
+			return cli.Exit(color.RedString("Package %s does not contain a README file, use --web to open its repository", filepath.Base(dir)), 1)
+		}
+		data, err := ioutil.ReadFile(readme)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read %s: %s", readme, err), 1)
+		}
+		text := string(data)
+		if filepath.Ext(readme) != "" {
+			text = terminal.RenderMarkdown(data)
+		}
+
+		if !term.IsTTY() {
+			term.Printf("%s", text)
+			return nil
+		}
+		if err := runPager(text); err != nil {
+			logger.Debugf("Unable to run pager: %s", err)
+			term.Printf("%s", text)
+		}
+		return nil
+	}
+}
+
+// packageReadme returns the path of the README file of a package, empty if there is none
+func packageReadme(dir string) string {
+	for _, name := range readmeNames {
+		path := filepath.Join(dir, name)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// repositoryWebURL returns the web page of a repository given its clone URL, e.g. git@github.com:akamai/cli-dns.git
+// becomes https://github.com/akamai/cli-dns. The README of GitHub repositories is linked directly.
+func repositoryWebURL(source string) string {
+	url := strings.TrimSuffix(source, ".git")
+	if strings.HasPrefix(url, "git@") {
+		url = "https://" + strings.Replace(strings.TrimPrefix(url, "git@"), ":", "/", 1)
+	}
+	if strings.HasPrefix(url, "https://github.com/") {
+		url += "#readme"
+	}
+	return url
+}
+
+// runPager displays text in the pager set in PAGER environment variable, less by default or if PAGER is blank
+func runPager(text string) error {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less", "-FRX"}
+		if runtime.GOOS == "windows" {
+			args = []string{"more"}
+		}
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
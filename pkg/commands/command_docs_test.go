@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCmdDocs(t *testing.T) {
+	const packageDir = "./testdata/.akamai-cli/src/cli-docs"
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked)
+		opened    string
+		withError string
+	}{
+		"render readme": {
+			args: []string{"cli-docs"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile(packageDir+"/README.md", []byte("# Docs\n\nUse `akamai docs`.\n"), 0644))
+				m.term.On("IsTTY").Return(false).Once()
+				m.term.On("Printf", "%s", []interface{}{terminal.RenderMarkdown([]byte("# Docs\n\nUse `akamai docs`.\n"))}).Return().Once()
+			},
+		},
+		"plain text readme": {
+			args: []string{"cli-docs"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile(packageDir+"/README", []byte("# not markdown\n"), 0644))
+				m.term.On("IsTTY").Return(false).Once()
+				m.term.On("Printf", "%s", []interface{}{"# not markdown\n"}).Return().Once()
+			},
+		},
+		"open repository": {
+			args: []string{"--web", "cli-docs"},
+			init: func(t *testing.T, m *mocked) {
+				repo, err := gogit.PlainInit(packageDir, false)
+				require.NoError(t, err)
+				_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:akamai/cli-docs.git"}})
+				require.NoError(t, err)
+				m.term.On("Writeln", []interface{}{"Opening https://github.com/akamai/cli-docs#readme"}).Return(0, nil).Once()
+			},
+			opened: "https://github.com/akamai/cli-docs#readme",
+		},
+		"no readme": {
+			args:      []string{"cli-docs"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "Package cli-docs does not contain a README file, use --web to open its repository",
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: fmt.Sprintf("Package \"cli-missing\" not found. [package-not-found]\nTry: run \"%s list\"", tools.Self()),
+		},
+	}
+
+	browser := openBrowser
+	defer func() { openBrowser = browser }()
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			require.NoError(t, os.MkdirAll(packageDir, 0755))
+			require.NoError(t, ioutil.WriteFile(packageDir+"/cli.json", []byte(`{"commands":[{"name":"docs-test"}]}`), 0644))
+			defer func() {
+				require.NoError(t, os.RemoveAll(packageDir))
+			}()
+			var opened string
+			openBrowser = func(url string) error {
+				opened = url
+				return nil
+			}
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", commandOwnerSection, "cli-missing").Return("", false).Maybe()
+			command := &cli.Command{
+				Name:   "docs",
+				Action: cmdDocs(m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "web"}},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "docs")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			assert.Equal(t, test.opened, opened)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRepositoryWebURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/akamai/cli-dns#readme", repositoryWebURL("https://github.com/akamai/cli-dns.git"))
+	assert.Equal(t, "https://github.com/akamai/cli-dns#readme", repositoryWebURL("git@github.com:akamai/cli-dns.git"))
+	assert.Equal(t, "https://git.example.com/tools/cli-dns", repositoryWebURL("https://git.example.com/tools/cli-dns"))
+}
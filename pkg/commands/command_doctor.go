@@ -0,0 +1,221 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+func cmdDoctor(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("DOCTOR START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("DOCTOR FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("DOCTOR ERROR: %v", e.Error())
+			}
+		}()
+		asJSON := jsonOutput(c)
+		printResults := jsonResults(c)
+
+		dirs := make([]string, 0)
+		if c.Args().Present() {
+			for _, name := range c.Args().Slice() {
+				dir := installedPackageDir(c, langManager, name)
+				if dir == "" {
+					return packageNotFound(name)
+				}
+				dirs = append(dirs, dir)
+			}
+		} else {
+			// directories without cli.json are reported too, they are left behind by broken installs
+			for _, dir := range getPackagePaths() {
+				if info, err := os.Stat(dir); err == nil && info.IsDir() && !strings.HasPrefix(filepath.Base(dir), ".") {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+
+		results := make([]diagnostic, 0)
+		for _, dir := range dirs {
+			diagnostics := diagnosePackage(c.Context, gitRepo, langManager, dir)
+			if c.Bool("fix") && repairable(diagnostics) {
+				if err := repairPackage(c.Context, gitRepo, langManager, dir, diagnostics); err != nil {
+					logger.Errorf("Unable to repair package %s: %s", filepath.Base(dir), err)
+					terminal.Get(c.Context).Writeln(color.RedString("Unable to repair package %s: %s", filepath.Base(dir), err))
+				}
+				diagnostics = diagnosePackage(c.Context, gitRepo, langManager, dir)
+			}
+			results = append(results, diagnostics...)
+		}
+
+		var err error
+		if asJSON {
+			err = printResults(results)
+		} else if len(results) == 0 {
+			terminal.Get(c.Context).Writeln("No packages installed.")
+		} else {
+			err = printDoctorSummary(terminal.Get(c.Context), results)
+		}
+		if err != nil {
+			return err
+		}
+
+		var failed []string
+		for _, res := range results {
+			if res.Status == diagnosticProblem && (len(failed) == 0 || failed[len(failed)-1] != res.Package) {
+				failed = append(failed, res.Package)
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		if !c.Bool("fix") && repairable(results) {
+			return cli.Exit(color.RedString("Problems found in: %s. Run \"%s doctor --fix\" to repair them.", strings.Join(failed, ", "), tools.Self()), 1)
+		}
+		return cli.Exit(color.RedString("Problems found in: %s", strings.Join(failed, ", ")), 1)
+	}
+}
+
+// repairable returns true if any problem found can be repaired by "akamai doctor --fix"
+func repairable(diagnostics []diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Status == diagnosticProblem && d.Repair != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// repairPackage applies the repairs of the problems found in the package in dir. Re-cloning the package replaces
+// its whole directory, so it is the only repair applied when a problem requires it.
+func repairPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string, diagnostics []diagnostic) error {
+	logger := log.FromContext(ctx)
+	repairs := make(map[string][]diagnostic)
+	for _, d := range diagnostics {
+		if d.Status == diagnosticProblem && d.Repair != "" {
+			repairs[d.Repair] = append(repairs[d.Repair], d)
+		}
+	}
+	if _, ok := repairs[repairReclone]; ok {
+		return reclonePackage(ctx, gitRepo, langManager, dir)
+	}
+	for _, d := range repairs[repairPermissions] {
+		info, err := os.Stat(d.path)
+		if err != nil {
+			return err
+		}
+		logger.Debugf("Adding execute permission to %s", d.path)
+		if err := os.Chmod(d.path, info.Mode()|0111); err != nil {
+			return err
+		}
+	}
+	if _, ok := repairs[repairReinstall]; ok {
+		if _, err := installPackageDependencies(ctx, langManager, dir, "", false, logger); err != nil {
+			return fmt.Errorf("unable to install dependencies: %w", err)
+		}
+	}
+	return nil
+}
+
+// reclonePackage installs the package in dir again from the repository it was installed from, on the branch, version or
+// commit it follows, and installs its dependencies. The package is restored if it cannot be installed.
+func reclonePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string) error {
+	name := filepath.Base(dir)
+	metadata := packageUpdateChannels()[name]
+	repo, err := git.OriginURL(dir)
+	if err != nil {
+		if record, err := readSourceRecord(dir); err == nil {
+			repo = record.URL
+		} else if metadata != nil {
+			repo = metadata.Source
+		}
+	}
+	if repo == "" {
+		return errors.New("the repository it was installed from is unknown, uninstall and install it again")
+	}
+	if packageDirName(repo) != name {
+		return fmt.Errorf("repository %s is installed as %s", repo, packageDirName(repo))
+	}
+
+	var version, branch string
+	if metadata != nil {
+		version, branch = metadata.Pinned, metadata.Branch
+		if metadata.Locked != "" {
+			version = metadata.Locked
+		}
+		if version != "" || metadata.channel() != channelBranch {
+			branch = ""
+		}
+	}
+	backup := filepath.Join(filepath.Dir(dir), "."+name+"-doctor")
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, backup); err != nil {
+		return err
+	}
+	if _, err := installPackage(withInstallBranch(ctx, branch), gitRepo, langManager, repo, version, false); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if err := os.Rename(backup, dir); err != nil {
+			return err
+		}
+		return err
+	}
+	return os.RemoveAll(backup)
+}
+
+func printDoctorSummary(term terminal.Terminal, results []diagnostic) error {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tCHECK\tSTATUS\tDETAILS")
+	for _, res := range results {
+		details := res.Message
+		if res.Status == diagnosticProblem && res.Repair != "" {
+			details = fmt.Sprintf("%s (fix: %s)", res.Message, res.Repair)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", res.Package, res.Check, res.Status, details)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("\nDoctor Summary:\n"))
+	term.Printf("%s", buf.String())
+	return nil
+}
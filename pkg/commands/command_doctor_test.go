@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdDoctor(t *testing.T) {
+	nodePackage := `{"requirements": {"node": "7.0.0"}, "commands": [{"name": "dns", "version": "1.0.0"}]}`
+	tests := map[string]struct {
+		cliJSON      string
+		files        map[string]os.FileMode
+		noRepository bool
+		args         []string
+		init         func(*mocked, string)
+		expected     []string
+		withError    string
+	}{
+		"healthy package": {
+			files:    map[string]os.FileMode{"bin/akamai-dns": 0755},
+			init:     func(m *mocked, dir string) {},
+			expected: []string{"cli-dns manifest ok 1 commands", "cli-dns runtime ok no runtime required", "cli-dns executables ok bin/akamai-dns"},
+		},
+		"dependencies not installed": {
+			cliJSON: nodePackage,
+			files:   map[string]os.FileMode{"package.json": 0644, "akamai-dns.js": 0644},
+			init: func(m *mocked, dir string) {
+				m.langManager.On("CheckRuntime", packages.LanguageRequirements{Node: "7.0.0"}).Return("14.15.0", nil).Once()
+			},
+			expected:  []string{"cli-dns runtime ok javascript 14.15.0", "cli-dns dependencies problem dependencies are not installed (fix: reinstall dependencies)"},
+			withError: "Problems found in: cli-dns. Run",
+		},
+		"dependencies reinstalled": {
+			cliJSON: nodePackage,
+			files:   map[string]os.FileMode{"package.json": 0644, "akamai-dns.js": 0644},
+			args:    []string{"--fix"},
+			init: func(m *mocked, dir string) {
+				m.langManager.On("CheckRuntime", packages.LanguageRequirements{Node: "7.0.0"}).Return("14.15.0", nil).Twice()
+				m.langManager.On("Install", dir, packages.LanguageRequirements{Node: "7.0.0"}, []string{"dns"}).Return(nil).Run(func(mock.Arguments) {
+					require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0755))
+				}).Once()
+			},
+			expected: []string{"cli-dns dependencies ok installed"},
+		},
+		"runtime too old": {
+			cliJSON: nodePackage,
+			files:   map[string]os.FileMode{"package.json": 0644, "node_modules/dep.js": 0644, "akamai-dns.js": 0644},
+			init: func(m *mocked, dir string) {
+				m.langManager.On("CheckRuntime", packages.LanguageRequirements{Node: "7.0.0"}).Return("6.0.0", fmt.Errorf("%w: required: Node.js:7.0.0, have: 6.0.0", packages.ErrRuntimeMinimumVersionRequired)).Once()
+			},
+			expected:  []string{"cli-dns runtime problem higher version is required to install this command: required: Node.js:7.0.0, have: 6.0.0"},
+			withError: "Problems found in: cli-dns",
+		},
+		"executable made executable": {
+			files:    map[string]os.FileMode{"bin/akamai-dns": 0644},
+			args:     []string{"--fix"},
+			init:     func(m *mocked, dir string) {},
+			expected: []string{"cli-dns executables ok bin/akamai-dns"},
+		},
+		"not a git repository": {
+			files:        map[string]os.FileMode{"bin/akamai-dns": 0755},
+			noRepository: true,
+			init:         func(m *mocked, dir string) {},
+			expected:     []string{"cli-dns repository problem not a valid git repository: repository does not exist (fix: re-clone)"},
+			withError:    "doctor --fix\" to repair them.",
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			init:      func(m *mocked, dir string) {},
+			withError: `Package "cli-missing" not found.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("requires file permissions")
+			}
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			cliJSON := test.cliJSON
+			if cliJSON == "" {
+				cliJSON = `{"commands": [{"name": "dns", "version": "1.0.0"}]}`
+			}
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", cliJSON)
+			for file, mode := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("test"), mode))
+			}
+			if !test.noRepository {
+				repo, err := gogit.PlainInit(dir, false)
+				require.NoError(t, err)
+				worktree, err := repo.Worktree()
+				require.NoError(t, err)
+				_, err = worktree.Add("cli.json")
+				require.NoError(t, err)
+				_, err = worktree.Commit("init", &gogit.CommitOptions{
+					Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+				})
+				require.NoError(t, err)
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, &packages.Mock{}}
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			m.term.On("Spinner").Return(m.term).Maybe()
+			m.term.On("Start", "Installing...", []interface{}(nil)).Return().Maybe()
+			m.term.On("OK").Return().Maybe()
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Maybe()
+			var output string
+			m.term.On("Printf", "%s", mock.Anything).Return().Run(func(args mock.Arguments) {
+				output = args.Get(1).([]interface{})[0].(string)
+			}).Maybe()
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "doctor",
+				Action: cmdDoctor(git.NewRepository(), m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "fix"}},
+			}, m)
+			test.init(m, dir)
+
+			err := app.RunContext(ctx, append(append(os.Args[0:1], "doctor"), test.args...))
+			m.langManager.AssertExpectations(t)
+			// columns are aligned to the longest values, lines are compared with single spaces between them
+			output = strings.Join(strings.Fields(output), " ")
+			for _, line := range test.expected {
+				assert.Contains(t, output, line)
+			}
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
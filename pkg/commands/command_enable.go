@@ -0,0 +1,114 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// cmdEnable returns the action of "enable" if enable is true and of "disable" otherwise
+// Disabled packages stay on disk, but their commands are not dispatched nor listed until they are enabled again
+func cmdEnable(langManager packages.LangManager, enable bool) cli.ActionFunc {
+	event := packageEventDisable
+	if enable {
+		event = packageEventEnable
+	}
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debugf("%s START", strings.ToUpper(event))
+		defer func() {
+			if e == nil {
+				logger.Debugf("%s FINISH: %v", strings.ToUpper(event), time.Now().Sub(start))
+			} else {
+				logger.Errorf("%s ERROR: %v", strings.ToUpper(event), e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		defer refreshLaunchers(c)
+
+		srcPath, err := tools.GetAkamaiCliSrcPath()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+		}
+
+		err = updatePackageStore(c.Context, func(store *packageStore) error {
+			store.migrateInstalled(c.Context, "")
+			for _, name := range c.Args().Slice() {
+				pkgName, ok := installedPackageName(c, langManager, store, name)
+				if !ok {
+					return packageNotFound(name)
+				}
+				if store.Packages[pkgName].Disabled != enable {
+					term.Printf("Package %s is already %sd.\n", color.BlueString(pkgName), event)
+					continue
+				}
+				if err := store.record(event, filepath.Join(srcPath, pkgName), time.Now().UTC().Truncate(time.Second)); err != nil {
+					return cli.Exit(color.RedString("Unable to %s package %s: %s", event, pkgName, err), 1)
+				}
+				if enable {
+					term.Printf("Package %s enabled.\n", color.BlueString(pkgName))
+				} else {
+					term.Printf("Package %s disabled, its commands are hidden until \"%s\".\n", color.BlueString(pkgName), color.BlueString("%s enable %s", tools.Self(), pkgName))
+				}
+			}
+			return nil
+		})
+		var exitErr cli.ExitCoder
+		if err != nil && !errors.As(err, &exitErr) {
+			return cli.Exit(color.RedString("Unable to update package store: %s", err), 1)
+		}
+		return err
+	}
+}
+
+// installedPackageName returns the name of an installed, possibly disabled, package given its name or the name of one of its commands
+func installedPackageName(c *cli.Context, langManager packages.LangManager, store *packageStore, name string) (string, bool) {
+	if dir := installedPackageDir(c, langManager, name); dir != "" {
+		name = filepath.Base(dir)
+	}
+	// commands of disabled packages cannot be found on disk, so they are looked up in the store
+	pkgName, ok := store.packageByName(name)
+	if !ok || !store.Packages[pkgName].Installed {
+		return "", false
+	}
+	return pkgName, true
+}
+
+// disabledPackageNames returns the names of disabled packages, completed by "enable"
+func disabledPackageNames(_ *cli.Context) []string {
+	names := make([]string, 0)
+	for name := range disabledPackages() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCmdEnable(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		disabled  bool
+		init      func(*mocked)
+		expect    bool
+		withError string
+	}{
+		"disable by command name": {
+			args: []string{"disable", "dns"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "Package %s disabled, its commands are hidden until \"%s\".\n", mock.Anything).Return().Once()
+			},
+			expect: true,
+		},
+		"enable disabled package by command name": {
+			args:     []string{"enable", "dns"},
+			disabled: true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "Package %s enabled.\n", []interface{}{color.BlueString("cli-dns")}).Return().Once()
+			},
+			expect: false,
+		},
+		"already disabled": {
+			args:     []string{"disable", "cli-dns"},
+			disabled: true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "Package %s is already %sd.\n", []interface{}{color.BlueString("cli-dns"), packageEventDisable}).Return().Once()
+			},
+			expect: true,
+		},
+		"unknown package": {
+			args:      []string{"disable", "cli-missing"},
+			init:      func(m *mocked) {},
+			withError: `Package "cli-missing" not found`,
+		},
+		"no args": {
+			args:      []string{"enable"},
+			init:      func(m *mocked) {},
+			withError: "You must specify a package or command name",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "akamai-dns"), []byte("#!/bin/sh"), 0755))
+			if test.disabled {
+				store, err := loadPackageStore(context.Background())
+				require.NoError(t, err)
+				require.NoError(t, store.record(packageEventDisable, dir, time.Now()))
+				require.NoError(t, store.save())
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			app, ctx := setupTestApp(&cli.Command{Name: "disable", Action: cmdEnable(m.langManager, false)}, m)
+			app.Commands = append(app.Commands, &cli.Command{Name: "enable", Action: cmdEnable(m.langManager, true)})
+			test.init(m)
+
+			err := app.RunContext(ctx, append(os.Args[0:1], test.args...))
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, disabledPackages()["cli-dns"])
+			if test.expect {
+				assert.Empty(t, getPackagePaths())
+				_, err := findExec(context.Background(), m.langManager, "dns")
+				assert.Error(t, err)
+			} else {
+				assert.Equal(t, []string{dir}, getPackagePaths())
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const maskedValue = "********"
+
+// explainedEnv lists environment variables which are shown by explain in addition to the ones set by the CLI
+// and the AKAMAI_ ones, as they affect how commands reach the network or find their dependencies
+var explainedEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "PYTHONUSERBASE"}
+
+// secretEnvNames are parts of environment variable names whose values are masked by explain
+var secretEnvNames = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL"}
+
+func cmdExplain(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("EXPLAIN START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("EXPLAIN FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("EXPLAIN ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a command to explain"), 1)
+		}
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		dir := c.String("chdir")
+		d, err := resolvePackageCommand(c.Context, langManager, c.Args().First(), c.Args().Tail(), dir)
+		if err != nil {
+			return err
+		}
+
+		if d.Interpreter != "" {
+			if path, err := exec.LookPath(d.Interpreter); err == nil {
+				d.Interpreter = path
+			}
+		}
+		if d.Dir == "" {
+			if d.Dir, err = os.Getwd(); err != nil {
+				return err
+			}
+		} else if d.Dir, err = filepath.Abs(d.Dir); err != nil {
+			return err
+		}
+		d.Env = explainEnv(d.Env, os.Environ())
+
+		if format != plugin.FormatTable {
+			return render(c, format, renderedOutput{Value: d})
+		}
+		printDispatch(terminal.Get(c.Context), d, dispatchNetworkPolicy(c.Context, d))
+		return nil
+	}
+}
+
+// explainEnv returns the variables set by the CLI merged with the relevant ones from given environment, with secrets masked
+func explainEnv(injected map[string]string, environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range environ {
+		i := strings.Index(entry, "=")
+		if i <= 0 {
+			continue
+		}
+		key := entry[:i]
+		if strings.HasPrefix(strings.ToUpper(key), "AKAMAI_") || containsFold(explainedEnv, key) {
+			env[key] = entry[i+1:]
+		}
+	}
+	for key, val := range injected {
+		env[key] = val
+	}
+	for key, val := range env {
+		env[key] = maskEnvValue(key, val)
+	}
+	return env
+}
+
+// maskEnvValue hides values of variables which look like credentials, as well as passwords in proxy URLs
+func maskEnvValue(key, val string) string {
+	upper := strings.ToUpper(key)
+	for _, secret := range secretEnvNames {
+		if strings.Contains(upper, secret) && val != "" {
+			return maskedValue
+		}
+	}
+	if strings.HasSuffix(upper, "_PROXY") {
+		if u, err := url.Parse(val); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.User(u.User.Username())
+				return strings.Replace(u.String(), u.User.String()+"@", u.User.String()+":"+maskedValue+"@", 1)
+			}
+		}
+	}
+	return val
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func printDispatch(term terminal.Terminal, d *packageDispatch, policy string) {
+	bold := color.New(color.FgWhite, color.Bold)
+	term.Printf(bold.Sprint("Command:")+" %s\n", d.Command)
+	term.Printf(bold.Sprint("Package:")+" %s\n", d.Package)
+	if d.Version != "" {
+		term.Printf(bold.Sprint("Version:")+" %s\n", d.Version)
+	}
+	if d.Interpreter != "" {
+		term.Printf(bold.Sprint("Interpreter:")+" %s\n", d.Interpreter)
+	}
+	term.Printf(bold.Sprint("Directory:")+" %s\n", d.Dir)
+	if d.Workspace != "" {
+		term.Printf(bold.Sprint("Workspace:")+" %s\n", d.Workspace)
+	}
+	if policy != networkPolicyOff {
+		term.Printf(bold.Sprint("Allowed hosts:")+" %s (%s)\n", strings.Join(d.pkg.Hosts, ", "), policy)
+	}
+	term.Printf(bold.Sprint("Command line:")+" %s\n", quoteArgs(d.Args))
+
+	keys := make([]string, 0, len(d.Env))
+	for key := range d.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	term.Writeln(bold.Sprint("Environment:"))
+	for _, key := range keys {
+		term.Printf("  %s=%s\n", key, d.Env[key])
+	}
+}
+
+// quoteArgs joins a command line, quoting arguments which would otherwise be split or interpreted by a shell
+func quoteArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'$`|&;<>()*?") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,297 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// exportBaseImage is the image Dockerfiles written by "akamai export" are based on
+const exportBaseImage = "debian:bullseye-slim"
+
+// exportDevcontainerImage is the image of devcontainer.json files written by "akamai export", runtimes are added as features
+const exportDevcontainerImage = "mcr.microsoft.com/devcontainers/base:bullseye"
+
+// runtimeAptPackages are the Debian packages installing the runtime and package manager of each language
+var runtimeAptPackages = map[string][]string{
+	packages.Go:         {"golang"},
+	packages.Javascript: {"nodejs", "npm"},
+	packages.PHP:        {"php-cli", "composer"},
+	packages.Python:     {"python3", "python3-pip"},
+	packages.Ruby:       {"ruby-full"},
+}
+
+// runtimeFeatures are the dev container features installing the runtime of each language
+var runtimeFeatures = map[string]string{
+	packages.Go:         "ghcr.io/devcontainers/features/go:1",
+	packages.Javascript: "ghcr.io/devcontainers/features/node:1",
+	packages.PHP:        "ghcr.io/devcontainers/features/php:1",
+	packages.Python:     "ghcr.io/devcontainers/features/python:1",
+	packages.Ruby:       "ghcr.io/devcontainers/features/ruby:1",
+}
+
+type (
+	// exportedEnvironment is what "akamai export" reproduces: the version of Akamai CLI, the installed packages
+	// at their commits and the runtimes these packages require
+	exportedEnvironment struct {
+		cliVersion string
+		// packages are the arguments of "akamai install" installing each package
+		packages []string
+		runtimes []exportedRuntime
+		// skipped are the packages which cannot be reproduced, as no repository was recorded for them
+		skipped []string
+	}
+
+	// exportedRuntime is a runtime required by installed packages, with the highest version they require
+	// and the version found locally, if any
+	exportedRuntime struct {
+		language  string
+		required  string
+		installed string
+		packages  []string
+	}
+
+	devcontainer struct {
+		Name              string                            `json:"name"`
+		Image             string                            `json:"image"`
+		Features          map[string]map[string]interface{} `json:"features,omitempty"`
+		PostCreateCommand string                            `json:"postCreateCommand"`
+	}
+)
+
+func cmdExport(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("EXPORT START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("EXPORT FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("EXPORT ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if c.Bool("dockerfile") && c.Bool("devcontainer") {
+			return cli.Exit(color.RedString("--dockerfile and --devcontainer cannot be combined"), 1)
+		}
+
+		env, err := exportEnvironment(c.Context, langManager)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read installed packages: %s", err), 1)
+		}
+		if len(env.skipped) > 0 {
+			term.WriteError(color.YellowString("Warning: packages without a recorded repository are not exported: %s\n", strings.Join(env.skipped, ", ")))
+		}
+
+		var out string
+		if c.Bool("devcontainer") {
+			if out, err = env.devcontainer(); err != nil {
+				return err
+			}
+		} else {
+			out = env.dockerfile()
+		}
+		if !c.Args().Present() || c.Args().First() == "-" {
+			term.Printf("%s", out)
+			return nil
+		}
+		if err := ioutil.WriteFile(c.Args().First(), []byte(out), 0644); err != nil {
+			return cli.Exit(color.RedString("Unable to write %s: %s", c.Args().First(), err), 1)
+		}
+		term.Printf("Exported %d packages to %s.\n", len(env.packages), color.BlueString(c.Args().First()))
+		return nil
+	}
+}
+
+// exportEnvironment collects the installed packages, at the commits recorded in the package store, and the runtimes they require
+func exportEnvironment(ctx context.Context, langManager packages.LangManager) (*exportedEnvironment, error) {
+	store, err := loadPackageStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store.migrateInstalled(ctx, "")
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &exportedEnvironment{cliVersion: version.Version, packages: make([]string, 0)}
+	runtimes := make(map[string]*exportedRuntime)
+	requirements := make(map[string]packages.LanguageRequirements)
+	for _, name := range sortedPackageNames(store) {
+		pkg := store.Packages[name]
+		if !pkg.Installed {
+			continue
+		}
+		if pkg.Source == "" {
+			env.skipped = append(env.skipped, name)
+			continue
+		}
+		arg := pkg.Source
+		// packages fetched from other sources than git are installed from the same URL, which has no commits
+		if pkg.Commit != "" && findPackageSource(pkg.Source) == nil {
+			arg += "@" + pkg.Commit
+		}
+		env.packages = append(env.packages, arg)
+
+		sub, err := readPackage(filepath.Join(srcPath, name))
+		if err != nil {
+			continue
+		}
+		lang, required := sub.Requirements.Language()
+		if lang == packages.Undefined {
+			continue
+		}
+		rt, ok := runtimes[lang]
+		if !ok {
+			rt = &exportedRuntime{language: lang}
+			runtimes[lang] = rt
+			requirements[lang] = sub.Requirements
+		}
+		if required != "" && required != "*" && (rt.required == "" || version.Compare(rt.required, required) == 1) {
+			rt.required = required
+		}
+		rt.packages = append(rt.packages, name)
+	}
+
+	for lang, rt := range runtimes {
+		if installed, err := langManager.CheckRuntime(ctx, requirements[lang]); err == nil {
+			rt.installed = installed
+		}
+		env.runtimes = append(env.runtimes, *rt)
+	}
+	sort.Slice(env.runtimes, func(i, j int) bool {
+		return env.runtimes[i].language < env.runtimes[j].language
+	})
+	return env, nil
+}
+
+// installScript returns the shell commands downloading the release binary of Akamai CLI and installing the packages.
+// sudo is prepended to the commands writing to /usr/local/bin if set.
+func (env *exportedEnvironment) installScript(sudo string) []string {
+	binURL := fmt.Sprintf("https://github.com/akamai/cli/releases/download/%[1]s/akamai-%[1]s-linux$(dpkg --print-architecture)", env.cliVersion)
+	script := []string{
+		fmt.Sprintf("%scurl -fsSL -o /usr/local/bin/akamai %s", sudo, binURL),
+		fmt.Sprintf("%schmod +x /usr/local/bin/akamai", sudo),
+	}
+	bootstrap := "akamai bootstrap --stats=off --upgrade-check=off"
+	for _, pkg := range env.packages {
+		bootstrap += " --install " + pkg
+	}
+	return append(script, bootstrap)
+}
+
+// dockerfile renders a Dockerfile based on Debian, installing the runtimes with apt
+func (env *exportedEnvironment) dockerfile() string {
+	lines := []string{
+		fmt.Sprintf("# Generated by \"akamai export\" with Akamai CLI %s", env.cliVersion),
+		"FROM " + exportBaseImage,
+		"",
+	}
+	aptPackages := []string{"ca-certificates", "curl", "git"}
+	if len(env.runtimes) > 0 {
+		lines = append(lines, "# runtimes required by the installed packages")
+	}
+	for _, rt := range env.runtimes {
+		desc := rt.describe()
+		if pkgs, ok := runtimeAptPackages[rt.language]; ok {
+			aptPackages = append(aptPackages, pkgs...)
+		} else {
+			desc += ", has to be installed manually"
+		}
+		lines = append(lines, "#   "+desc)
+	}
+	lines = append(lines,
+		"RUN apt-get update \\",
+		" && apt-get install -y --no-install-recommends "+strings.Join(aptPackages, " ")+" \\",
+		" && rm -rf /var/lib/apt/lists/*",
+		"",
+	)
+	script := env.installScript("")
+	bootstrap := strings.Split(script[len(script)-1], " --install ")
+	lines = append(lines, "RUN "+script[0]+" \\")
+	for _, cmd := range script[1 : len(script)-1] {
+		lines = append(lines, " && "+cmd+" \\")
+	}
+	lines = append(lines, " && "+bootstrap[0])
+	for _, pkg := range bootstrap[1:] {
+		lines[len(lines)-1] += " \\"
+		lines = append(lines, "    --install "+pkg)
+	}
+	lines = append(lines, "", `ENTRYPOINT ["akamai"]`, "")
+	return strings.Join(lines, "\n")
+}
+
+// devcontainer renders a devcontainer.json installing the runtimes as features, in the versions found locally.
+// Runtimes of languages supported through installer helpers have no feature and are left out.
+func (env *exportedEnvironment) devcontainer() (string, error) {
+	spec := devcontainer{
+		Name:              "Akamai CLI",
+		Image:             exportDevcontainerImage,
+		PostCreateCommand: strings.Join(env.installScript("sudo "), " && "),
+	}
+	spec.Features = make(map[string]map[string]interface{}, len(env.runtimes))
+	for _, rt := range env.runtimes {
+		feature, ok := runtimeFeatures[rt.language]
+		if !ok {
+			continue
+		}
+		runtimeVersion := minorVersion(rt.installed)
+		if runtimeVersion == "" {
+			runtimeVersion = "latest"
+		}
+		spec.Features[feature] = map[string]interface{}{"version": runtimeVersion}
+	}
+	// the post create command is a shell command line, "&&" should not be escaped
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(spec); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (rt exportedRuntime) describe() string {
+	desc := rt.language
+	if rt.required != "" {
+		desc += " " + rt.required + " or later"
+	}
+	desc += ", required by " + strings.Join(rt.packages, ", ")
+	if rt.installed != "" {
+		desc += fmt.Sprintf(", version %s installed locally", rt.installed)
+	}
+	return desc
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdExport(t *testing.T) {
+	pythonReqs := packages.LanguageRequirements{Python: "3.6.0"}
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		contains  []string
+		withError string
+	}{
+		"dockerfile to standard output": {
+			init: func(m *mocked) {},
+			contains: []string{
+				"FROM " + exportBaseImage + "\n",
+				"#   python 3.6.0 or later, required by cli-dns, cli-purge, version 3.9.2 installed locally\n",
+				" && apt-get install -y --no-install-recommends ca-certificates curl git python3 python3-pip \\\n",
+				fmt.Sprintf("RUN curl -fsSL -o /usr/local/bin/akamai https://github.com/akamai/cli/releases/download/%[1]s/akamai-%[1]s-linux$(dpkg --print-architecture) \\\n", version.Version),
+				" && akamai bootstrap --stats=off --upgrade-check=off \\\n    --install https://github.com/akamai/cli-dns.git@" + lockedCommit + " \\\n    --install https://github.com/akamai/cli-purge.git@" + currentCommit + "\n",
+				`ENTRYPOINT ["akamai"]`,
+			},
+		},
+		"devcontainer to file": {
+			args: []string{"--devcontainer", "devcontainer.json"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "Exported %d packages to %s.\n", []interface{}{2, color.BlueString("devcontainer.json")}).Return().Once()
+			},
+			contains: []string{
+				`"image": "` + exportDevcontainerImage + `"`,
+				`"ghcr.io/devcontainers/features/python:1": {` + "\n" + `      "version": "3.9"`,
+				`sudo chmod +x /usr/local/bin/akamai && akamai bootstrap --stats=off --upgrade-check=off --install https://github.com/akamai/cli-dns.git@` + lockedCommit,
+			},
+		},
+		"both formats": {
+			args:      []string{"--dockerfile", "--devcontainer"},
+			init:      func(m *mocked) {},
+			withError: "--dockerfile and --devcontainer cannot be combined",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			wd, err := os.Getwd()
+			require.NoError(t, err)
+			require.NoError(t, os.Chdir(cliHome))
+			defer func() {
+				require.NoError(t, os.Chdir(wd))
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			for name, commit := range map[string]string{"cli-dns": lockedCommit, "cli-purge": currentCommit, "cli-local": ""} {
+				dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), name, `{"requirements": {"python": "3.6.0"}, "commands": [{"name": "`+name[4:]+`", "version": "1.0.0"}]}`)
+				if commit != "" {
+					repo, err := gogit.PlainInit(dir, false)
+					require.NoError(t, err)
+					_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{"https://github.com/akamai/" + name + ".git"}})
+					require.NoError(t, err)
+				}
+				saveInstallRecord(context.Background(), dir, commit)
+				recordPackageEvent(context.Background(), packageEventInstall, dir)
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, &packages.Mock{}}
+			m.langManager.On("CheckRuntime", pythonReqs).Return("3.9.2", nil).Maybe()
+			m.term.On("WriteError", color.YellowString("Warning: packages without a recorded repository are not exported: cli-local\n")).Return().Maybe()
+			var out string
+			m.term.On("Printf", "%s", mock.Anything).Run(func(args mock.Arguments) {
+				out = args.Get(1).([]interface{})[0].(string)
+			}).Return().Maybe()
+			test.init(m)
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "export",
+				Action: cmdExport(m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "dockerfile"}, &cli.BoolFlag{Name: "devcontainer"}},
+			}, m)
+
+			err = app.RunContext(ctx, append(append(os.Args[0:1], "export"), test.args...))
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if len(test.args) > 0 {
+				data, err := ioutil.ReadFile(test.args[len(test.args)-1])
+				require.NoError(t, err)
+				out = string(data)
+				var spec devcontainer
+				require.NoError(t, json.Unmarshal(data, &spec))
+			}
+			for _, expected := range test.contains {
+				assert.Contains(t, out, expected)
+			}
+		})
+	}
+}
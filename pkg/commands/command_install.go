@@ -34,6 +34,12 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 )
 
+// install statuses reported in JSON output
+const (
+	installStatusInstalled = "installed"
+	installStatusFailed    = "failed"
+)
+
 var (
 	thirdPartyDisclaimer = color.CyanString("Disclaimer: You are installing a third-party package, subject to its own terms and conditions. Akamai makes no warranty or representation with respect to the third-party package.")
 )
@@ -51,34 +57,177 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 				logger.Errorf("INSTALL ERROR: %v", e.Error())
 			}
 		}()
+		defer refreshCompletions(c)
+		defer refreshLaunchers(c)
+		c.Context = withNoHooks(withSkipVerify(c.Context, c.Bool("skip-verify")), c.Bool("no-hooks"))
+		c.Context = withGitHubToken(c.Context)
+		if c.IsSet("file") {
+			if c.Args().Present() || c.IsSet("mirror") || c.IsSet("from-lock") || c.IsSet("branch") {
+				return cli.Exit(color.RedString("--file cannot be combined with repositories, mirrors, --branch or --from-lock"), 1)
+			}
+			printResults := jsonResults(c)
+			results, err := installFromArchive(c, git, langManager, c.String("file"))
+			if printErr := printResults(results); printErr != nil && err == nil {
+				return printErr
+			}
+			return err
+		}
+		if c.IsSet("from-lock") {
+			if c.Args().Present() || c.IsSet("mirror") || c.IsSet("branch") {
+				return cli.Exit(color.RedString("--from-lock cannot be combined with repositories, mirrors or --branch"), 1)
+			}
+			printResults := jsonResults(c)
+			results, err := installFromLock(c, git, langManager, c.String("from-lock"))
+			if printErr := printResults(results); printErr != nil && err == nil {
+				return printErr
+			}
+			return err
+		}
 		if !c.Args().Present() {
 			return cli.Exit(color.RedString("You must specify a repository URL"), 1)
 		}
 
-		oldCmds := getCommands(c)
-
-		for _, repo := range c.Args().Slice() {
-			repo = tools.Githubize(repo)
-			subCmd, err := installPackage(c.Context, git, langManager, repo, c.Bool("force"))
-			if err != nil {
-				// Only track public github repos
-				if isPublicRepo(repo) {
-					stats.TrackEvent(c.Context, "package.install", "failed", repo)
-				}
-				return err
+		mirrors := c.StringSlice("mirror")
+		if len(mirrors) > 0 && c.NArg() > 1 {
+			return cli.Exit(color.RedString("Mirrors can only be specified when installing a single package"), 1)
+		}
+		if branch := c.String("branch"); c.IsSet("branch") {
+			if err := validateBranchName(branch); err != nil {
+				return cli.Exit(color.RedString("Unable to install: %s", err), 1)
 			}
-			c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, git, langManager)...)
-			sortCommands(c.App.Commands)
+			c.Context = withInstallBranch(c.Context, branch)
+		}
+
+		printResults := jsonResults(c)
+		results, err := installPackages(c, git, langManager, c.Args().Slice(), mirrors...)
+		if printErr := printResults(results); printErr != nil && err == nil {
+			return printErr
+		}
+		return err
+	}
+}
+
+// installResult is the outcome of installing a package, as reported with --json
+type installResult struct {
+	Package    string   `json:"package"`
+	Repository string   `json:"repository"`
+	Status     string   `json:"status"`
+	Version    string   `json:"version,omitempty"`
+	Commit     string   `json:"commit,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Commands   []string `json:"commands"`
+	RequiredBy string   `json:"requiredBy,omitempty"`
+	Selftest   string   `json:"selftest,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// installPackages installs given repositories, registers their commands in the app and lists the changes.
+// The returned results include the package which failed to install, if any.
+func installPackages(c *cli.Context, git git.Repository, langManager packages.LangManager, repos []string, mirrors ...string) ([]installResult, error) {
+	start := time.Now()
+	oldCmds := getCommands(c)
+	term := terminal.Get(c.Context)
+	logger := log.FromContext(c.Context)
+	registered := make(map[string]bool)
+	for _, cmd := range c.App.Commands {
+		registered[cmd.Name] = true
+	}
 
+	// dependencies declared by installed packages are appended to the queue, unless they are already installed
+	queue := make([]string, 0, len(repos))
+	requiredBy := make(map[string]string)
+	results := make([]installResult, 0, len(repos))
+	queued := make(map[string]bool)
+	versions := make(map[string]string)
+	for _, repo := range repos {
+		repo, version := splitPackageVersion(repo)
+		resolved, err := resolveRegistryRepo(c.Context, repo)
+		if err != nil {
+			res := installResult{Package: packageDirName(repo), Repository: repo, Status: installStatusFailed, Commands: []string{}, Error: err.Error()}
+			return append(results, res), cli.Exit(color.RedString(err.Error()), 1)
+		}
+		repo = packageRepo(resolved)
+		queue = append(queue, repo)
+		queued[packageDirName(repo)] = true
+		versions[repo] = version
+	}
+
+	for i := 0; i < len(queue); i++ {
+		repo := queue[i]
+		repoMirrors := mirrors
+		if dependent, ok := requiredBy[repo]; ok {
+			repoMirrors = nil
+			term.Writeln(color.CyanString("Installing %s required by %s", packageDirName(repo), dependent))
+		}
+		packageStart := time.Now()
+		pkgCtx := c.Context
+		if _, ok := requiredBy[repo]; ok {
+			// dependencies are installed from their default branch
+			pkgCtx = withInstallBranch(c.Context, "")
+		}
+		subCmd, err := installPackage(pkgCtx, git, langManager, repo, versions[repo], c.Bool("force"), repoMirrors...)
+		logger.Debugf("Installation of %s took %s", repo, formatDuration(since(packageStart)))
+		packageName := packageDirName(repo)
+		res := installResult{Package: packageName, Repository: repo, Status: installStatusFailed, Commands: []string{}, RequiredBy: requiredBy[repo]}
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			// Only track public github repos
 			if isPublicRepo(repo) {
-				stats.TrackEvent(c.Context, "package.install", "success", repo)
+				stats.TrackEvent(c.Context, "package.install", "failed", repo)
+			}
+			return results, err
+		}
+		res.Status = installStatusInstalled
+		res.Version = packageVersion(*subCmd)
+		for _, cmd := range subCmd.Commands {
+			res.Commands = append(res.Commands, cmd.Name)
+		}
+		if srcPath, err := tools.GetAkamaiCliSrcPath(); err == nil {
+			res.Path = filepath.Join(srcPath, packageName)
+			if record, err := readInstallRecord(res.Path); err == nil {
+				res.Commit = record.Commit
+			}
+			res.Selftest = runInstallSelftests(c.Context, langManager, res.Path, *subCmd)
+		}
+		results = append(results, res)
+		cmds, collisions := packageCliCommands(registered, packageName, *subCmd, git, langManager)
+		for _, name := range collisions {
+			term.Writeln(color.YellowString("Warning: command \"%s\" is already provided by another package. Run \"%s %s%s%s\" to use the one from %s, or make it the default using \"%s config set %s.%s %s\".",
+				name, tools.Self(), packageName, namespaceSeparator, name, packageName, tools.Self(), commandOwnerSection, name, packageName))
+		}
+		c.App.Commands = append(c.App.Commands, cmds...)
+		sortCommands(c.App.Commands)
+		if srcPath, err := tools.GetAkamaiCliSrcPath(); err == nil {
+			for _, warning := range commandConflicts(c.App.Commands, filepath.Join(srcPath, packageName), *subCmd) {
+				term.Writeln(color.YellowString(warning))
 			}
 		}
 
-		packageListDiff(c, oldCmds)
+		for _, dep := range subCmd.Dependencies {
+			resolved, err := resolveRegistryRepo(c.Context, dep)
+			if err != nil {
+				return results, cli.Exit(color.RedString("Unable to resolve dependency %s of %s: %s", dep, packageName, err.Error()), 1)
+			}
+			depRepo := packageRepo(resolved)
+			depName := packageDirName(depRepo)
+			if queued[depName] || packageInstalled(depName) {
+				continue
+			}
+			queued[depName] = true
+			requiredBy[depRepo] = packageName
+			queue = append(queue, depRepo)
+		}
 
-		return nil
+		if isPublicRepo(repo) {
+			stats.TrackEvent(c.Context, "package.install", "success", repo)
+		}
 	}
+
+	packageListDiff(c, oldCmds)
+	term.Writeln(fmt.Sprintf("Installation took %s", formatDuration(since(start))))
+
+	return results, nil
 }
 
 func packageListDiff(c *cli.Context, oldcmds []subcommands) {
@@ -129,15 +278,29 @@ func packageListDiff(c *cli.Context, oldcmds []subcommands) {
 		}
 	}
 
-	listInstalledCommands(c, added, removed)
+	listInstalledCommands(c, added, removed, nil)
 }
 
 func isPublicRepo(repo string) bool {
 	return !strings.Contains(repo, ":") || strings.HasPrefix(repo, "https://github.com/")
 }
 
-func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo string, forceBinary bool) (*subcommands, error) {
+// packageDirName returns the name of the directory a package is installed to from given repository
+func packageDirName(repo string) string {
+	if source := findPackageSource(repo); source != nil {
+		return source.packageName(repo)
+	}
+	return strings.TrimSuffix(filepath.Base(repo), ".git")
+}
+
+// installPackage clones the package from given repository, or fetches it from another package source, and installs its dependencies,
+// checking out given tag, semantic version range or commit first and pinning the package to it if version is not empty
+func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo, version string, forceBinary bool, mirrors ...string) (*subcommands, error) {
 	logger := log.FromContext(ctx)
+	if err := requireVerified(ctx, repo); err != nil {
+		return nil, err
+	}
+
 	srcPath, err := tools.GetAkamaiCliSrcPath()
 	if err != nil {
 		return nil, err
@@ -149,44 +312,172 @@ func installPackage(ctx context.Context, gitRepo git.Repository, langManager pac
 
 	spin.Start("Attempting to fetch command from %s...", repo)
 
-	dirName := strings.TrimSuffix(filepath.Base(repo), ".git")
-	packageDir := filepath.Join(srcPath, dirName)
+	packageDir := filepath.Join(srcPath, packageDirName(repo))
 	if _, err = os.Stat(packageDir); err == nil {
 		spin.Stop(terminal.SpinnerStatusFail)
-		errorMsg := fmt.Sprintf("Package directory already exists (%s)", packageDir)
-		logger.Error(errorMsg)
-		return nil, cli.Exit(color.RedString(errorMsg), 1)
+		f := newFailure(failurePackageExists, failureData{"Dir": packageDir, "Package": packageDirName(repo)}, nil)
+		logger.Error(f.Message())
+		return nil, f
+	}
+	if err := checkPreflight(ctx, srcPath); err != nil {
+		spin.Stop(terminal.SpinnerStatusFail)
+		logger.Error(err.Error())
+		return nil, err
 	}
 
-	err = gitRepo.Clone(ctx, packageDir, repo, false, spin)
+	source := findPackageSource(repo)
+	var branch, revision string
+	if source != nil {
+		revision, err = fetchPackage(ctx, source, repo, packageDir, version, spin, mirrors)
+	} else {
+		branch, err = clonePackage(ctx, gitRepo, repo, packageDir, version, spin, mirrors)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(repo, "https://github.com/akamai/cli-") && !strings.HasPrefix(repo, "git@github.com:akamai/cli-") {
+		term.Printf(color.CyanString(thirdPartyDisclaimer))
+	}
+
+	subCmd, err := installPackageDependencies(ctx, langManager, packageDir, hookPostInstall, forceBinary, logger)
 	if err != nil {
 		if err := os.RemoveAll(packageDir); err != nil {
 			return nil, err
 		}
+		return nil, cli.Exit("Unable to install selected package", 1)
+	}
+
+	seedPackageConfig(ctx, *subCmd)
+
+	if source == nil {
+		if ref, err := gitRepo.Head(); err == nil {
+			revision = ref.Hash().String()
+		}
+	}
+	saveInstallRecord(ctx, packageDir, revision)
+	recordPackageEvent(ctx, packageEventInstall, packageDir)
+	if version != "" || installBranch(ctx) != "" {
+		pinPackage(ctx, packageDir, version, branch)
+	}
+	notifyPackageEvent(ctx, packageEventInstall, packageDir, "", packageVersion(*subCmd))
+
+	return subCmd, nil
+}
+
+// clonePackage clones the package repository to packageDir, adding mirrors as secondary remotes,
+// and checks out the branch and version requested. It returns the branch to remember when the package is pinned to version.
+func clonePackage(ctx context.Context, gitRepo git.Repository, repo, packageDir, version string, spin terminal.Spinner, mirrors []string) (string, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	cloneCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	err := gitRepo.Clone(cloneCtx, packageDir, repo, false, spin)
+	if err != nil {
+		if err := os.RemoveAll(packageDir); err != nil {
+			return "", err
+		}
 		spin.Stop(terminal.SpinnerStatusFail)
 
-		errorMsg := "Unable to clone repository: " + err.Error()
-		logger.Error(errorMsg)
-		return nil, cli.Exit(color.RedString(errorMsg), 1)
+		logger.Errorf("Unable to clone repository: %s", err)
+		return "", fetchFailure(repo, err)
 	}
 	spin.OK()
 
-	if !strings.HasPrefix(repo, "https://github.com/akamai/cli-") && !strings.HasPrefix(repo, "git@github.com:akamai/cli-") {
-		term.Printf(color.CyanString(thirdPartyDisclaimer))
+	for i, mirror := range mirrors {
+		remoteName := fmt.Sprintf("%s%d", git.MirrorRemotePrefix, i+1)
+		if err := gitRepo.AddRemote(remoteName, tools.Githubize(mirror)); err != nil {
+			warnMsg := fmt.Sprintf("Unable to add mirror %s: %s", mirror, err)
+			term.Writeln(color.CyanString(warnMsg))
+			logger.Warn(warnMsg)
+		}
 	}
 
-	ok, subCmd := installPackageDependencies(ctx, langManager, packageDir, forceBinary, logger)
-	if !ok {
+	branch := installBranch(ctx)
+	if branch != "" {
+		spin.Start("Checking out branch %s...", branch)
+		if err := gitRepo.CheckoutBranch(cloneCtx, git.DefaultRemoteName, branch); err != nil {
+			if err := os.RemoveAll(packageDir); err != nil {
+				return "", err
+			}
+			spin.Stop(terminal.SpinnerStatusFail)
+			errorMsg := fmt.Sprintf("Unable to check out branch %s: %s", branch, err)
+			logger.Error(errorMsg)
+			return "", cli.Exit(color.RedString(errorMsg), 1)
+		}
+		spin.OK()
+	}
+	if version != "" {
+		if branch == "" {
+			branch = headBranch(gitRepo)
+		}
+		spin.Start("Checking out version %s...", version)
+		if err := checkoutPackageVersion(cloneCtx, gitRepo, version); err != nil {
+			if err := os.RemoveAll(packageDir); err != nil {
+				return "", err
+			}
+			spin.Stop(terminal.SpinnerStatusFail)
+			errorMsg := fmt.Sprintf("Unable to check out version %s: %s", version, err)
+			logger.Error(errorMsg)
+			return "", cli.Exit(color.RedString(errorMsg), 1)
+		}
+		spin.OK()
+	}
+	return branch, nil
+}
+
+// fetchPackage downloads the package from a source other than git to packageDir, returning the revision fetched
+func fetchPackage(ctx context.Context, source packageSource, repo, packageDir, version string, spin terminal.Spinner, mirrors []string) (string, error) {
+	logger := log.FromContext(ctx)
+	if version != "" || installBranch(ctx) != "" || len(mirrors) > 0 {
+		spin.Stop(terminal.SpinnerStatusFail)
+		errorMsg := fmt.Sprintf("Versions, branches and mirrors are not supported for packages of the %s source", source.name())
+		logger.Error(errorMsg)
+		return "", cli.Exit(color.RedString(errorMsg), 1)
+	}
+	fetchCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	revision, err := fetchFromSource(fetchCtx, source, repo, packageDir, "")
+	if err != nil {
 		if err := os.RemoveAll(packageDir); err != nil {
-			return nil, err
+			return "", err
 		}
-		return nil, cli.Exit("Unable to install selected package", 1)
+		spin.Stop(terminal.SpinnerStatusFail)
+		logger.Errorf("Unable to fetch package: %s", err)
+		return "", fetchFailure(repo, err)
 	}
+	spin.OK()
+	return revision, nil
+}
 
-	return subCmd, nil
+type installBranchContextType string
+
+var installBranchContext installBranchContextType = "install-branch"
+
+// withInstallBranch sets the branch which installed packages check out instead of the default branch of their repository
+func withInstallBranch(ctx context.Context, branch string) context.Context {
+	return context.WithValue(ctx, installBranchContext, branch)
 }
 
-func installPackageDependencies(ctx context.Context, langManager packages.LangManager, dir string, forceBinary bool, logger log.Logger) (bool, *subcommands) {
+func installBranch(ctx context.Context) string {
+	branch, _ := ctx.Value(installBranchContext).(string)
+	return branch
+}
+
+// installPackageDependencies installs the dependencies of the package in dir, then runs its hook for given event, if not empty
+func installPackageDependencies(ctx context.Context, langManager packages.LangManager, dir, hook string, forceBinary bool, logger log.Logger) (*subcommands, error) {
+	pkg, err := installDependencies(ctx, langManager, dir, forceBinary, logger)
+	if err != nil || hook == "" {
+		return pkg, err
+	}
+	if err := runPackageHook(ctx, dir, *pkg, hook); err != nil {
+		terminal.Get(ctx).Writeln(color.RedString(err.Error()))
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func installDependencies(ctx context.Context, langManager packages.LangManager, dir string, forceBinary bool, logger log.Logger) (*subcommands, error) {
 	cmdPackage, err := readPackage(dir)
 
 	term := terminal.Get(ctx)
@@ -196,7 +487,7 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 		term.Spinner().Stop(terminal.SpinnerStatusFail)
 		term.Writeln(err.Error())
 		logger.Error(err.Error())
-		return false, nil
+		return nil, err
 	}
 
 	var commands []string
@@ -204,18 +495,22 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 		commands = append(commands, cmd.Name)
 	}
 
-	err = langManager.Install(ctx, dir, cmdPackage.Requirements, commands)
+	err = langManager.Install(withBuildContainer(withInstallTimeouts(ctx)), dir, cmdPackage.Requirements, commands)
+	if err != nil && !errors.Is(err, packages.ErrUnknownLang) {
+		err = installFailure(cmdPackage, filepath.Base(dir), err)
+	}
 	if errors.Is(err, packages.ErrUnknownLang) {
 		term.Spinner().WarnOK()
 		warnMsg := "Package installed successfully, however package type is unknown, and may or may not function correctly."
 		term.Writeln(color.CyanString(warnMsg))
 		logger.Warn(warnMsg)
-		return true, &cmdPackage
+		return &cmdPackage, nil
 	}
 
 	if err == nil {
+		removeBinaryRecord(ctx, dir)
 		term.Spinner().OK()
-		return true, &cmdPackage
+		return &cmdPackage, nil
 	}
 
 	first := true
@@ -228,23 +523,23 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 				logger.Warn(err.Error())
 				if !forceBinary {
 					if !term.IsTTY() {
-						return false, nil
+						return nil, err
 					}
 
-					answer, err := term.Confirm("Binary command(s) found, would you like to download and install it?", true)
-					if err != nil {
-						term.WriteError(err.Error())
-						logger.Error(err.Error())
-						return false, nil
+					answer, confirmErr := term.Confirm("Binary command(s) found, would you like to download and install it?", true)
+					if confirmErr != nil {
+						term.WriteError(confirmErr.Error())
+						logger.Error(confirmErr.Error())
+						return nil, confirmErr
 					}
 
 					if !answer {
-						return false, nil
+						return nil, err
 					}
 				}
 
 				if err := os.MkdirAll(filepath.Join(dir, "bin"), 0700); err != nil {
-					return false, nil
+					return nil, err
 				}
 
 				term.Spinner().Start("Downloading binary...")
@@ -255,7 +550,7 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 				errorMsg := "Unable to download binary: " + err.Error()
 				term.Writeln(color.RedString(errorMsg))
 				logger.Error(errorMsg)
-				return false, nil
+				return nil, err
 			}
 		}
 
@@ -263,11 +558,11 @@ func installPackageDependencies(ctx context.Context, langManager packages.LangMa
 			term.Spinner().Stop(terminal.SpinnerStatusFail)
 			term.Writeln(color.RedString(err.Error()))
 			logger.Error(err.Error())
-			return false, nil
+			return nil, err
 		}
 	}
 
 	term.Spinner().Stop(terminal.SpinnerStatusOK)
 
-	return true, &cmdPackage
+	return &cmdPackage, nil
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
 	"github.com/akamai/cli/pkg/git"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -31,8 +33,10 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -42,8 +46,12 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
@@ -60,11 +68,316 @@ func TestCmdInstall(t *testing.T) {
 				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
 			},
 		},
+		"json output": {
+			args: []string{"--json", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Twice()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// the list of installed commands is a status message, only the results are written to stdout
+				m.term.On("WriteErrorf", mock.Anything, mock.Anything).Return()
+				m.term.On("Writeln", []interface{}{`[
+  {
+    "package": "cli-test-cmd",
+    "repository": "https://github.com/akamai/cli-test-cmd.git",
+    "status": "installed",
+    "version": "1.0.0",
+    "commit": "0100000000000000000000000000000000000000",
+    "path": "testdata/.akamai-cli/src/cli-test-cmd",
+    "commands": [
+      "app-1-cmd-1"
+    ]
+  }
+]`}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"json output of failed install": {
+			args: []string{"--json", "test-cmd@v9.0.0"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.term.On("Start", "Checking out version %s...", []interface{}{"v9.0.0"}).Return().Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0"}, nil).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, "v9.0.0").Return(fmt.Errorf("invalid commit hash")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				errMsg, err := json.Marshal(color.RedString("Unable to check out version v9.0.0: no tag or commit matches version v9.0.0: invalid commit hash"))
+				require.NoError(t, err)
+				m.term.On("Writeln", []interface{}{fmt.Sprintf(`[
+  {
+    "package": "cli-test-cmd",
+    "repository": "https://github.com/akamai/cli-test-cmd.git",
+    "status": "failed",
+    "commands": [],
+    "error": %s
+  }
+]`, errMsg)}).Return(0, nil).Once()
+			},
+			withError: "Unable to check out version v9.0.0",
+		},
+		"install pinned version": {
+			args: []string{"test-cmd@^1.0"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.term.On("Start", "Checking out version %s...", []interface{}{"^1.0"}).Return().Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0", "v2.0.0"}, nil).Once()
+				m.gitRepo.On("CheckoutTag", "v1.1.0").Return(nil).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			teardown: func(t *testing.T) {
+				pkg := packageUpdateChannels()["cli-test-cmd"]
+				require.NotNil(t, pkg)
+				assert.Equal(t, "^1.0", pkg.Pinned)
+				assert.Equal(t, "master", pkg.Branch)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"install branch": {
+			args: []string{"--branch", "beta", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.term.On("Start", "Checking out branch %s...", []interface{}{"beta"}).Return().Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "beta").Return(nil).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("beta"), plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+			},
+			teardown: func(t *testing.T) {
+				pkg := packageUpdateChannels()["cli-test-cmd"]
+				require.NotNil(t, pkg)
+				assert.Empty(t, pkg.Pinned)
+				assert.Equal(t, "beta", pkg.Branch)
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+				require.NoError(t, os.Remove("./testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"branch not found": {
+			args: []string{"--branch", "beta", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.term.On("Start", "Checking out branch %s...", []interface{}{"beta"}).Return().Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "beta").Return(fmt.Errorf("branch beta not found on remote origin")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			withError: "Unable to check out branch beta: branch beta not found on remote origin",
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		"invalid branch name": {
+			args:      []string{"--branch", "-beta", "test-cmd"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Unable to install: invalid branch name "-beta"`,
+		},
+		"version not found": {
+			args: []string{"test-cmd@v9.0.0"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.term.On("Start", "Checking out version %s...", []interface{}{"v9.0.0"}).Return().Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0"}, nil).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, "v9.0.0").Return(fmt.Errorf("invalid commit hash")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			withError: "Unable to check out version v9.0.0: no tag or commit matches version v9.0.0: invalid commit hash",
+			teardown: func(t *testing.T) {
+				_, err := os.Stat("./testdata/.akamai-cli/src/cli-test-cmd")
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		"install with mirror repository": {
+			args: []string{"--mirror", "https://example.com/mirror/cli-test-cmd.git", "test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("OK").Return().Once()
+				m.gitRepo.On("AddRemote", "mirror-1", "https://example.com/mirror/cli-test-cmd.git").Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// list all packages
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.Anything, []interface{}(nil)).Return().Times(10)
+				m.term.On("Printf", mock.Anything, []interface{}{"aliases"}).Return().Twice()
+				m.term.On("Printf", mock.Anything, []interface{}{"alias"}).Return().Once()
+				m.term.On("Printf", mock.Anything, []interface{}{"commands.test help [command]"}).Return().Once()
+				m.term.On("Printf", mock.Anything).Return().Twice()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+			},
+		},
+		"install package with dependencies": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("OK").Return()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Twice()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Twice()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_dependencies/cli.json", "./testdata/.akamai-cli/src/cli-test-cmd")
+					})
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Twice()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Twice()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Twice()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Twice()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Twice()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+
+				// cli-echo is already installed, only the missing dependency is fetched
+				m.term.On("Writeln", []interface{}{color.CyanString("Installing %s required by %s", "cli-dep-cmd", "cli-test-cmd")}).Return(0, nil).Once()
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-dep-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-dep-cmd",
+					"https://github.com/akamai/cli-dep-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(args mock.Arguments) {
+						copyFile(t, "./testdata/repo_dependency/cli.json", "./testdata/.akamai-cli/src/cli-dep-cmd")
+					})
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-dep-cmd",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"dep-cmd-1"}).Return(nil).Once()
+
+				// list all packages
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-test-cmd"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-dep-cmd"))
+			},
+		},
+		"mirror with multiple packages": {
+			args:      []string{"--mirror", "https://example.com/mirror/cli-test-cmd.git", "test-cmd", "other-cmd"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "Mirrors can only be specified when installing a single package",
+		},
 		"install from official akamai repository, download binary": {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -80,6 +393,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -92,6 +407,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Start", "Downloading binary...", []interface{}(nil)).Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 
 				// list all packages
@@ -108,6 +425,7 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"installed"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-installed.git"}).Return().Once()
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
@@ -123,8 +441,10 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(fmt.Errorf("oops")).Once().
 					Run(func(args mock.Arguments) {
@@ -133,13 +453,15 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			withError: "Unable to clone repository: oops",
+			withError: "Unable to fetch https://github.com/akamai/cli-test-cmd.git [fetch-failed]\nCause: oops",
 		},
 		"error reading downloaded package, invalid cli.json": {
 			args: []string{"test-invalid-json"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-invalid-json.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-invalid-json",
 					"https://github.com/akamai/cli-test-invalid-json.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -165,7 +487,9 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -177,8 +501,12 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
 				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(packages.ErrUnknownLang).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("WarnOK").Return().Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
@@ -195,7 +523,9 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -211,6 +541,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -234,7 +566,9 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -245,6 +579,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -272,7 +608,9 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -288,6 +626,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -315,7 +655,9 @@ func TestCmdInstall(t *testing.T) {
 			args: []string{"test-cmd"},
 			init: func(t *testing.T, m *mocked) {
 				m.term.On("Spinner").Return(m.term).Once()
+				m.cfg.On("GetValue", "cli", "verified-only").Return("", false).Once()
 				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Clone", "testdata/.akamai-cli/src/cli-test-cmd",
 					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
 					Run(func(args mock.Arguments) {
@@ -331,6 +673,8 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
 
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-test-cmd",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}).Return(fmt.Errorf("oops")).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -355,7 +699,8 @@ func TestCmdInstall(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				assert.Equal(t, "/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1", r.URL.String())
-				assert.Equal(t, http.MethodGet, r.Method)
+				// the downloader asks for the size of the binary before fetching it
+				assert.Contains(t, []string{http.MethodHead, http.MethodGet}, r.Method)
 				w.WriteHeader(test.binaryResponseStatus)
 				_, err := w.Write([]byte(`binary content`))
 				assert.NoError(t, err)
@@ -367,6 +712,17 @@ func TestCmdInstall(t *testing.T) {
 			command := &cli.Command{
 				Name:   "install",
 				Action: cmdInstall(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "branch",
+					},
+					&cli.StringSliceFlag{
+						Name: "mirror",
+					},
+					&cli.BoolFlag{
+						Name: "json",
+					},
+				},
 			}
 			app, ctx := setupTestApp(command, m)
 			args := os.Args[0:1]
@@ -380,6 +736,7 @@ func TestCmdInstall(t *testing.T) {
 			}
 
 			m.cfg.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
@@ -15,86 +15,382 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"github.com/akamai/cli/pkg/log"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/plugin"
 	"github.com/akamai/cli/pkg/terminal"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 
 	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
 )
 
-func cmdList(c *cli.Context) (e error) {
-	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-	start := time.Now()
-	logger := log.WithCommand(c.Context, c.Command.Name)
-	logger.Debug("LIST START")
-	defer func() {
-		if e == nil {
-			logger.Debugf("LIST FINISH: %v", time.Now().Sub(start))
-		} else {
-			logger.Errorf("LIST ERROR: %v", e.Error())
-		}
-	}()
-	term := terminal.Get(c.Context)
-	bold := color.New(color.FgWhite, color.Bold)
+func cmdList(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("LIST START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("LIST FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("LIST ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		bold := color.New(color.FgWhite, color.Bold)
+
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("upgradable") {
+			return listUpgradableCommands(c, format)
+		}
+
+		if c.Bool("graph") {
+			return listDependencyGraph(c, format)
+		}
+
+		if c.Bool("commands") {
+			return listCommandNames(c, format)
+		}
+
+		if format != plugin.FormatTable {
+			return writeCommandListing(c, gitRepo, format)
+		}
+
+		commands := listInstalledCommands(c, nil, nil, checkPackagesHealth(gitRepo))
+		if disabled := disabledPackageNames(c); len(disabled) > 0 {
+			term.Printf("\nDisabled packages: %s (enable using \"%s\").\n", strings.Join(disabled, ", "), color.BlueString("%s enable [package]", tools.Self()))
+		}
+
+		if c.IsSet("remote") {
+			packageList, err := fetchPackageList(c.Context)
+			if err != nil {
+				return cli.Exit("Unable to fetch remote package list", 1)
+			}
+
+			foundCommands := true
+			for _, cmd := range packageList.Packages {
+				for _, command := range cmd.Commands {
+					if _, ok := commands[command.Name]; !ok {
+						foundCommands = false
+						continue
+					}
+				}
+			}
+
+			if foundCommands {
+				return nil
+			}
+			headerMsg := "\nAvailable Commands:\n\n"
+			term.Writeln(color.YellowString(headerMsg))
+			logger.Debug(headerMsg)
+
+			for _, remotePackage := range packageList.Packages {
+				for _, command := range remotePackage.Commands {
+					if _, ok := commands[command.Name]; ok {
+						continue
+					}
+					commandName := bold.Sprintf("  %s", command.Name)
+					term.Printf(commandName)
+					packageName := fmt.Sprintf(" [package: %s]", color.BlueString(remotePackage.Name))
+					if remotePackage.Verified {
+						packageName += " " + verifiedBadge()
+					}
+					term.Writeln(packageName)
+					commandDescription := fmt.Sprintf("    %s\n", fitToTerminal(command.Description, 4, c.Bool("no-trunc")))
+					term.Printf(commandDescription)
+					logger.Debug(commandName)
+					logger.Debug(packageName)
+					logger.Debug(commandDescription)
+				}
+			}
+
+			term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
+		}
+
+		return nil
+	}
+}
 
-	commands := listInstalledCommands(c, nil, nil)
+// listedCommand describes a command in list output formats other than table
+type listedCommand struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Package     string   `json:"package,omitempty"`
+	Verified    bool     `json:"verified,omitempty"`
+	Channel     string   `json:"channel,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+type commandListing struct {
+	Installed []listedCommand `json:"installed"`
+	Available []listedCommand `json:"available,omitempty"`
+}
+
+type upgradableCommand struct {
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+}
+
+// writeCommandListing prints installed commands, and with --remote also the ones available for install, in given format
+func writeCommandListing(c *cli.Context, gitRepo git.Repository, format string) error {
+	health := checkPackagesHealth(gitRepo)
+	channels := commandChannels()
+	listing := commandListing{Installed: make([]listedCommand, 0)}
+	installed := make(map[string]bool)
+	for _, cmd := range getCommands(c) {
+		for _, command := range cmd.Commands {
+			installed[command.Name] = true
+			listing.Installed = append(listing.Installed, listedCommand{
+				Name:        command.Name,
+				Aliases:     command.Aliases,
+				Description: command.Description,
+				Channel:     channels[command.Name],
+				Warnings:    health[command.Name],
+			})
+		}
+	}
 
 	if c.IsSet("remote") {
 		packageList, err := fetchPackageList(c.Context)
 		if err != nil {
 			return cli.Exit("Unable to fetch remote package list", 1)
 		}
-
-		foundCommands := true
-		for _, cmd := range packageList.Packages {
-			for _, command := range cmd.Commands {
-				if _, ok := commands[command.Name]; !ok {
-					foundCommands = false
+		listing.Available = make([]listedCommand, 0)
+		for _, remotePackage := range packageList.Packages {
+			for _, command := range remotePackage.Commands {
+				if installed[command.Name] {
 					continue
 				}
+				listing.Available = append(listing.Available, listedCommand{
+					Name:        command.Name,
+					Aliases:     command.Aliases,
+					Description: command.Description,
+					Package:     remotePackage.Name,
+					Verified:    remotePackage.Verified,
+				})
 			}
 		}
+	}
+
+	return render(c, format, renderedOutput{
+		Value:   listing,
+		Columns: []string{"status", "name", "aliases", "channel", "package", "description"},
+		Rows:    listing.rows(),
+	})
+}
+
+// rows returns listed commands as rows of CSV and --plain output: status, name, aliases, channel, package and description
+func (l commandListing) rows() [][]string {
+	rows := make([][]string, 0, len(l.Installed)+len(l.Available))
+	for _, cmd := range l.Installed {
+		rows = append(rows, cmd.plainRow("installed"))
+	}
+	for _, cmd := range l.Available {
+		rows = append(rows, cmd.plainRow("available"))
+	}
+	return rows
+}
+
+func (cmd listedCommand) plainRow(status string) []string {
+	return []string{status, cmd.Name, strings.Join(cmd.Aliases, ","), cmd.Channel, cmd.Package, cmd.Description}
+}
 
-		if foundCommands {
+// findUpgradableCommands returns the installed commands for which the package registry lists a newer version
+func findUpgradableCommands(ctx context.Context) ([]upgradableCommand, error) {
+	packageList, err := fetchCachedPackageList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]string)
+	for _, remotePackage := range packageList.Packages {
+		for _, command := range remotePackage.Commands {
+			available[strings.ToLower(command.Name)] = command.Version
+		}
+	}
+
+	upgradable := make([]upgradableCommand, 0)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			latest, ok := available[strings.ToLower(cmd.Name)]
+			if !ok || version.Compare(cmd.Version, latest) != 1 {
+				continue
+			}
+			upgradable = append(upgradable, upgradableCommand{Name: cmd.Name, Current: cmd.Version, Latest: latest})
+		}
+	}
+	return upgradable, nil
+}
+
+func listUpgradableCommands(c *cli.Context, format string) error {
+	term := terminal.Get(c.Context)
+	bold := color.New(color.FgWhite, color.Bold)
+
+	upgradable, err := findUpgradableCommands(c.Context)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to fetch remote package list"), 1)
+	}
+
+	rows := make([][]string, 0, len(upgradable))
+	for _, cmd := range upgradable {
+		rows = append(rows, []string{cmd.Name, cmd.Current, cmd.Latest})
+	}
+	return render(c, format, renderedOutput{
+		Value:   upgradable,
+		Columns: []string{"name", "current", "latest"},
+		Rows:    rows,
+		Table: func() error {
+			if len(upgradable) == 0 {
+				term.Writeln("All installed commands are up-to-date.")
+				return nil
+			}
+
+			term.Writeln(color.YellowString("\nUpgradable Commands:\n"))
+			for _, cmd := range upgradable {
+				term.Printf(bold.Sprintf("  %s", cmd.Name))
+				term.Printf(" %s -> %s\n", cmd.Current, color.GreenString(cmd.Latest))
+			}
+			term.Printf("\nUpdate using \"%s\".\n", color.BlueString("%s update [command]", tools.Self()))
 			return nil
+		},
+	})
+}
+
+// listDependencyGraph prints installed packages as trees of their dependencies,
+// or, in other formats, a map of installed packages to the packages they depend on.
+// Plain output has a line for each installed package and dependency, packages without dependencies have "-" instead.
+func listDependencyGraph(c *cli.Context, format string) error {
+	term := terminal.Get(c.Context)
+	deps := installedDependencies()
+
+	rows := make([][]string, 0, len(deps))
+	for _, name := range deps.names() {
+		if len(deps[name]) == 0 {
+			rows = append(rows, []string{name, ""})
 		}
-		headerMsg := "\nAvailable Commands:\n\n"
-		term.Writeln(color.YellowString(headerMsg))
-		logger.Debug(headerMsg)
+		for _, dep := range deps[name] {
+			rows = append(rows, []string{name, dep})
+		}
+	}
+	return render(c, format, renderedOutput{
+		Value:   deps,
+		Columns: []string{"package", "dependency"},
+		Rows:    rows,
+		Table: func() error {
+			if len(deps) == 0 {
+				term.Writeln("No packages installed.")
+				return nil
+			}
 
-		for _, remotePackage := range packageList.Packages {
-			for _, command := range remotePackage.Commands {
-				if _, ok := commands[command.Name]; ok {
-					continue
+			term.Writeln(color.YellowString("\nPackage Dependencies:\n"))
+			for _, root := range deps.roots() {
+				for _, line := range deps.renderTree(root) {
+					term.Writeln(line)
 				}
-				commandName := bold.Sprintf("  %s", command.Name)
-				term.Printf(commandName)
-				packageName := fmt.Sprintf(" [package: %s]", color.BlueString(remotePackage.Name))
-				term.Writeln(packageName)
-				commandDescription := fmt.Sprintf("    %s\n", command.Description)
-				term.Printf(commandDescription)
-				logger.Debug(commandName)
-				logger.Debug(packageName)
-				logger.Debug(commandDescription)
 			}
+			return nil
+		},
+	})
+}
+
+// commandName is a name Akamai CLI dispatches, along with the package providing the command.
+// Package is empty for built-in commands.
+type commandName struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+}
+
+// dispatchableCommands returns the names and aliases of built-in and installed commands, which can be called as "akamai <name>".
+// Namespaced names are included for commands provided by more than one package, other namespaced names are only used in scripts.
+func dispatchableCommands(c *cli.Context) []commandName {
+	providers := make(map[string][]string)
+	for _, cmd := range c.App.Commands {
+		if pkg, name := splitCommandName(cmd.Name); pkg != "" {
+			providers[name] = append(providers[name], pkg)
 		}
+	}
 
-		term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
+	names := make([]commandName, 0)
+	for _, cmd := range c.App.Commands {
+		pkg, name := splitCommandName(cmd.Name)
+		if pkg != "" {
+			if len(providers[name]) > 1 {
+				names = append(names, commandName{Name: cmd.Name, Package: pkg})
+			}
+			continue
+		}
+		if cmd.Hidden {
+			continue
+		}
+		if owners := providers[name]; len(owners) > 0 {
+			pkg = owners[0]
+			if owner := commandOwner(c.Context, name); owner != "" {
+				pkg = owner
+			}
+		}
+		for _, alias := range append([]string{cmd.Name}, cmd.Aliases...) {
+			names = append(names, commandName{Name: alias, Package: pkg})
+		}
 	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].Name < names[j].Name
+	})
+	return names
+}
 
-	return nil
+// listCommandNames prints every command name Akamai CLI dispatches, one per line, for completion systems and wrapper scripts.
+// With --with-package, lines also have the package providing the command, "-" for built-in commands.
+func listCommandNames(c *cli.Context, format string) error {
+	names := dispatchableCommands(c)
+	columns := []string{"name"}
+	if c.Bool("with-package") {
+		columns = append(columns, "package")
+	}
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{name.Name, name.Package}[:len(columns)])
+	}
+	return render(c, format, renderedOutput{
+		Value:   names,
+		Columns: columns,
+		Rows:    rows,
+		Table: func() error {
+			writePlain(c.Context, rows)
+			return nil
+		},
+	})
 }
-func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[string]bool) map[string]bool {
+
+// listInstalledCommands prints commands available in the app, marking added and removed ones,
+// along with problems found in installed packages if health is provided
+func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[string]bool, health map[string][]string) map[string]bool {
 	bold := color.New(color.FgWhite, color.Bold)
 
 	term := terminal.Get(c.Context)
 
 	commands := make(map[string]bool)
+	channels := commandChannels()
+	unhealthy := false
 	installedCmds := color.YellowString("\nInstalled Commands:\n")
 	term.Writeln(installedCmds)
 	cmds := getCommands(c)
@@ -127,14 +423,38 @@ func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[st
 				}
 				term.Printf(")")
 			}
+			if channel, ok := channels[command.Name]; ok {
+				term.Printf(" [channel: %s]", color.CyanString(channel))
+			}
 
 			term.Writeln()
 			if len(command.Description) > 0 {
-				cmdDescription := fmt.Sprintf("    %s\n", command.Description)
+				cmdDescription := fmt.Sprintf("    %s\n", fitToTerminal(command.Description, 4, c.Bool("no-trunc")))
 				term.Printf(cmdDescription)
 			}
+			if issues, ok := health[command.Name]; ok {
+				unhealthy = true
+				term.Printf(color.YellowString("    Warning: %s\n", strings.Join(issues, "; ")))
+			}
 		}
 	}
+	if unhealthy {
+		term.Printf("\nTo repair packages with warnings, reinstall them using \"%s\" and \"%s\".\n",
+			color.BlueString("%s uninstall [command]", tools.Self()), color.BlueString("%s install [package]", tools.Self()))
+	}
 	term.Printf("\nSee \"%s\" for details.\n", color.BlueString("%s help [command]", tools.Self()))
 	return commands
 }
+
+// fitToTerminal truncates text to fit in the terminal width, or wraps it over multiple lines if noTrunc is set.
+// indent is the number of columns preceding the text on the first line, wrapped lines are indented the same way.
+func fitToTerminal(text string, indent int, noTrunc bool) string {
+	width := terminal.Width()
+	if noTrunc {
+		return terminal.Wrap(text, width, indent)
+	}
+	if width <= indent {
+		return text
+	}
+	return terminal.Truncate(text, width-indent)
+}
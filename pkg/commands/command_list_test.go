@@ -1,17 +1,22 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -52,6 +57,14 @@ func TestCmdListWithRemote(t *testing.T) {
 				m.term.On("Printf", "\nInstall using \"%s\".\n", []interface{}{color.BlueString("%s install [package]", tools.Self())}).Return().Once()
 			},
 		},
+		"list all commands as plain": {
+			args: []string{"--plain"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{"available\ttest-remote-command\t-\t-\tremote-package\tTest remote command"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"installed\thelp\th\t-\t-\t-"}).Return(0, nil).Once()
+				m.term.On("Writeln", []interface{}{"installed\tlist\tls,show\t-\t-\tDisplays available commands"}).Return(0, nil).Once()
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -65,21 +78,24 @@ func TestCmdListWithRemote(t *testing.T) {
 		require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
 		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 		t.Run(name, func(t *testing.T) {
-			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			m.gitRepo.On("Open", mock.Anything).Return(fmt.Errorf("repository does not exist"))
 			command := &cli.Command{
 				Name: "list",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name: "remote",
 					},
+					plainFlag(),
 				},
 				Description: "Displays available commands",
 				Aliases:     []string{"ls", "show"},
-				Action:      cmdList,
+				Action:      cmdList(m.gitRepo),
 			}
 			app, ctx := setupTestApp(command, m)
 			args := os.Args[0:1]
 			args = append(args, "list", "--remote")
+			args = append(args, test.args...)
 
 			test.init(m)
 			err := app.RunContext(ctx, args)
@@ -95,3 +111,207 @@ func TestCmdListWithRemote(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdListUpgradable(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		response  string
+		cachePath bool
+		packages  map[string]string
+		init      func(*mocked)
+		withError string
+	}{
+		"list upgradable commands": {
+			response: `{"packages": [{"name":"stale","commands": [{"name":"stale","version":"1.1.0"}]}]}`,
+			init: func(m *mocked) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpgradable Commands:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", bold.Sprintf("  stale"), []interface{}(nil)).Return().Once()
+				m.term.On("Printf", " %s -> %s\n", []interface{}{"1.0.0", color.GreenString("1.1.0")}).Return().Once()
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", []interface{}{color.BlueString("%s update [command]", tools.Self())}).Return().Once()
+			},
+		},
+		"mixed-case command name": {
+			args:     []string{"--plain"},
+			response: `{"packages": [{"name":"cli-edgeworkers","commands": [{"name":"edgeworkers","version":"1.1.0"}]}]}`,
+			packages: map[string]string{"cli-edgeworkers": `{"commands": [{"name": "EdgeWorkers", "version": "1.0.0"}]}`},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Writeln", []interface{}{"edgeworkers\t1.0.0\t1.1.0"}).Return(0, nil).Once()
+			},
+		},
+		"all commands up-to-date, package list cached": {
+			response:  `{"packages": [{"name":"stale","commands": [{"name":"stale","version":"1.0.0"}]}]}`,
+			cachePath: true,
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{"All installed commands are up-to-date."}).Return(0, nil).Once()
+			},
+		},
+		"list upgradable commands as yaml": {
+			args:     []string{"--output", "yaml"},
+			response: `{"packages": [{"name":"stale","commands": [{"name":"stale","version":"1.1.0"}]}]}`,
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Printf", "%s", []interface{}{"- current: 1.0.0\n  latest: 1.1.0\n  name: stale\n"}).Return().Once()
+			},
+		},
+		"list upgradable commands as plain": {
+			args:     []string{"--plain"},
+			response: `{"packages": [{"name":"stale","commands": [{"name":"stale","version":"1.1.0"}]}]}`,
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+				m.term.On("Writeln", []interface{}{"stale\t1.0.0\t1.1.0"}).Return(0, nil).Once()
+			},
+		},
+		"invalid package list": {
+			response: `abc`,
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			withError: "Unable to fetch remote package list",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := w.Write([]byte(test.response))
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			if test.packages != nil {
+				cliHome := packageStoreHome(t)
+				defer func() {
+					require.NoError(t, os.RemoveAll(cliHome))
+					require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+				}()
+				for name, cliJSON := range test.packages {
+					writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), name, cliJSON)
+				}
+			}
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			command := &cli.Command{
+				Name: "list",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "upgradable",
+					},
+					plainFlag(),
+					outputFlag(),
+				},
+				Action: cmdList(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "list", "--upgradable")
+			args = append(args, test.args...)
+
+			if test.cachePath {
+				cacheDir, err := ioutil.TempDir("", "cache")
+				require.NoError(t, err)
+				defer func() {
+					require.NoError(t, os.RemoveAll(cacheDir))
+				}()
+				m.cfg.On("GetValue", "cli", "cache-path").Return(cacheDir, true).Once()
+				defer func() {
+					_, err := os.Stat(filepath.Join(cacheDir, "package-list.json"))
+					assert.NoError(t, err)
+				}()
+			}
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdListGraph(t *testing.T) {
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+	command := &cli.Command{
+		Name: "list",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name: "graph",
+			},
+			outputFlag(),
+		},
+		Action: cmdList(m.gitRepo),
+	}
+	app, ctx := setupTestApp(command, m)
+	var out string
+	m.term.On("Writeln", mock.Anything).Return(0, nil).Once().Run(func(args mock.Arguments) {
+		out = args.Get(0).([]interface{})[0].(string)
+	})
+
+	err := app.RunContext(ctx, []string{os.Args[0], "list", "--graph", "--output", "json"})
+	require.NoError(t, err)
+	m.term.AssertExpectations(t)
+
+	var deps map[string][]string
+	require.NoError(t, json.Unmarshal([]byte(out), &deps))
+	assert.Contains(t, deps, "cli-echo")
+	assert.Empty(t, deps["cli-echo"])
+	assert.NotContains(t, deps, "cli-echo-invalid-json")
+}
+
+func TestCmdListCommands(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"names": {
+			args:     []string{"list", "--commands"},
+			expected: []string{"cli-a:purge", "cli-b:purge", "echo", "h", "help", "list", "ls", "purge"},
+		},
+		"names with packages": {
+			args:     []string{"list", "--commands", "--with-package"},
+			expected: []string{"cli-a:purge\tcli-a", "cli-b:purge\tcli-b", "echo\tcli-echo", "h\t-", "help\t-", "list\t-", "ls\t-", "purge\tcli-a"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, nil}
+			command := &cli.Command{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "commands"},
+					&cli.BoolFlag{Name: "with-package"},
+					plainFlag(),
+					outputFlag(),
+				},
+				Action: cmdList(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			installed := color.YellowString("Installed Commands:")
+			app.Commands = append(app.Commands,
+				&cli.Command{Name: "cli-echo:echo", Hidden: true, Category: installed},
+				&cli.Command{Name: "echo", Category: installed},
+				&cli.Command{Name: "cli-a:purge", Hidden: true, Category: installed},
+				&cli.Command{Name: "purge", Category: installed},
+				&cli.Command{Name: "cli-b:purge", Hidden: true, Category: installed},
+				&cli.Command{Name: "internal", Hidden: true},
+			)
+			for _, line := range test.expected {
+				m.term.On("Writeln", []interface{}{line}).Return(0, nil).Once()
+			}
+
+			err := app.RunContext(ctx, append([]string{os.Args[0]}, test.args...))
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+		})
+	}
+}
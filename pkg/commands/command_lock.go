@@ -0,0 +1,331 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// lockFileVersion is the schema version of lockfiles written by "akamai lock"
+	lockFileVersion = 1
+
+	// defaultLockFile is the file written by "akamai lock" if no other is given
+	defaultLockFile = "akamai-cli.lock"
+)
+
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+type (
+	// lockFile describes the installed packages exactly, so that the same state can be installed on other machines
+	lockFile struct {
+		Version  int             `json:"version"`
+		Packages []lockedPackage `json:"packages"`
+	}
+
+	// lockedPackage is a package of a lockfile
+	lockedPackage struct {
+		Name   string `json:"name"`
+		Source string `json:"source"`
+		Commit string `json:"commit"`
+		// Binaries are the downloaded binaries of the package by command
+		Binaries map[string]lockedBinary `json:"binaries,omitempty"`
+	}
+
+	// lockedBinary is the checksum of a command binary downloaded for a platform, e.g. linux-amd64
+	lockedBinary struct {
+		Platform string `json:"platform"`
+		SHA256   string `json:"sha256"`
+	}
+
+	unlockContextType string
+)
+
+var unlockContext unlockContextType = "unlock"
+
+// withUnlock allows update to move packages past the commits they are locked to if unlock is set
+func withUnlock(ctx context.Context, unlock bool) context.Context {
+	if !unlock {
+		return ctx
+	}
+	return context.WithValue(ctx, unlockContext, true)
+}
+
+func unlockRequested(ctx context.Context) bool {
+	unlock, _ := ctx.Value(unlockContext).(bool)
+	return unlock
+}
+
+func cmdLock(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	start := time.Now()
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	logger.Debug("LOCK START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("LOCK FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("LOCK ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	path := defaultLockFile
+	if c.Args().Present() {
+		path = c.Args().First()
+	}
+
+	store, err := loadPackageStore(c.Context)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+	}
+	store.migrateInstalled(c.Context, "")
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+	}
+
+	lock := lockFile{Version: lockFileVersion, Packages: make([]lockedPackage, 0)}
+	var unlockable []string
+	for _, name := range sortedPackageNames(store) {
+		pkg := store.Packages[name]
+		if !pkg.Installed {
+			continue
+		}
+		if pkg.Source == "" || pkg.Commit == "" {
+			unlockable = append(unlockable, name)
+			continue
+		}
+		locked := lockedPackage{Name: name, Source: pkg.Source, Commit: pkg.Commit}
+		if binaries, err := readBinaryRecord(filepath.Join(srcPath, name)); err == nil && len(binaries) > 0 {
+			locked.Binaries = make(map[string]lockedBinary, len(binaries))
+			for cmd, binary := range binaries {
+				locked.Binaries[cmd] = lockedBinary{Platform: binary.Platform, SHA256: binary.SHA256}
+			}
+		}
+		lock.Packages = append(lock.Packages, locked)
+	}
+	if len(unlockable) > 0 {
+		return cli.Exit(color.RedString("Unable to lock packages without a recorded repository and commit: %s", strings.Join(unlockable, ", ")), 1)
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		term.Printf("%s\n", data)
+	} else if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return cli.Exit(color.RedString("Unable to write lockfile: %s", err), 1)
+	}
+	if err := lockPackages(c.Context, lock.Packages, false); err != nil {
+		return cli.Exit(color.RedString("Unable to update package store: %s", err), 1)
+	}
+
+	if path != "-" {
+		term.Printf("Locked %d packages in %s.\n", len(lock.Packages), color.BlueString(path))
+	}
+	term.Printf("Install them on other machines with \"%s\", updates require --unlock.\n", color.BlueString("%s install --from-lock %s", tools.Self(), path))
+	return nil
+}
+
+func sortedPackageNames(store *packageStore) []string {
+	names := make([]string, 0, len(store.Packages))
+	for name := range store.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readLockFile reads and validates a lockfile written by "akamai lock"
+func readLockFile(path string) (*lockFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := &lockFile{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+	if lock.Version < 1 || lock.Version > lockFileVersion {
+		return nil, fmt.Errorf("lockfile %s has schema version %d, this version of Akamai CLI supports up to %d", path, lock.Version, lockFileVersion)
+	}
+	for _, pkg := range lock.Packages {
+		if pkg.Name == "" || pkg.Source == "" || packageDirName(pkg.Source) != pkg.Name {
+			return nil, fmt.Errorf("invalid lockfile %s: package %q does not match its repository %q", path, pkg.Name, pkg.Source)
+		}
+		if !commitHashPattern.MatchString(pkg.Commit) {
+			return nil, fmt.Errorf("invalid lockfile %s: package %s has invalid commit %q", path, pkg.Name, pkg.Commit)
+		}
+	}
+	return lock, nil
+}
+
+// installFromLock installs the packages of the lockfile at exactly the commits it lists. Packages which are installed
+// already are checked out at their locked commit. All packages are then locked, so that update does not move them.
+func installFromLock(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager, path string) ([]installResult, error) {
+	term := terminal.Get(c.Context)
+	logger := log.FromContext(c.Context)
+	results := make([]installResult, 0)
+	lock, err := readLockFile(path)
+	if err != nil {
+		return results, cli.Exit(color.RedString("Unable to read lockfile: %s", err), 1)
+	}
+	store, err := loadPackageStore(c.Context)
+	if err != nil {
+		return results, cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+	}
+	store.migrateInstalled(c.Context, "")
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return results, cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+	}
+
+	repos := make([]string, 0)
+	for _, locked := range lock.Packages {
+		pkg, ok := store.Packages[locked.Name]
+		switch {
+		case !ok || !pkg.Installed:
+			repos = append(repos, locked.Source+"@"+locked.Commit)
+		case pkg.Commit == locked.Commit:
+			term.Printf("Package %s is already installed at commit %s.\n", color.BlueString(locked.Name), shortHash(locked.Commit))
+		default:
+			res, err := checkoutLockedPackage(c.Context, gitRepo, langManager, filepath.Join(srcPath, locked.Name), locked, c.Bool("force"), logger)
+			results = append(results, res)
+			if err != nil {
+				return results, err
+			}
+		}
+	}
+	if len(repos) > 0 {
+		installed, err := installPackages(c, gitRepo, langManager, repos)
+		results = append(results, installed...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	platform := binaryPlatform(withPlatform(command{}))
+	var mismatched []string
+	for _, locked := range lock.Packages {
+		binaries, _ := readBinaryRecord(filepath.Join(srcPath, locked.Name))
+		for cmd, binary := range locked.Binaries {
+			if binary.Platform != platform {
+				continue
+			}
+			if downloaded, ok := binaries[cmd]; ok && downloaded.SHA256 != binary.SHA256 {
+				mismatched = append(mismatched, fmt.Sprintf("%s (%s)", cmd, locked.Name))
+			}
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return results, cli.Exit(color.RedString("Downloaded binaries do not match the lockfile: %s", strings.Join(mismatched, ", ")), 1)
+	}
+
+	if err := lockPackages(c.Context, lock.Packages, true); err != nil {
+		return results, cli.Exit(color.RedString("Unable to update package store: %s", err), 1)
+	}
+	term.Printf("Installed %d packages from %s.\n", len(lock.Packages), color.BlueString(path))
+	return results, nil
+}
+
+// checkoutLockedPackage checks out the locked commit of an installed package and installs its dependencies again
+func checkoutLockedPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string, locked lockedPackage, forceBinary bool, logger log.Logger) (installResult, error) {
+	term := terminal.Get(ctx)
+	res := installResult{Package: locked.Name, Repository: locked.Source, Status: installStatusFailed, Commands: []string{}}
+	term.Spinner().Start("Checking out commit %s of package \"%s\"...", shortHash(locked.Commit), locked.Name)
+	if err := gitRepo.Open(dir); err != nil {
+		term.Spinner().Fail()
+		res.Error = err.Error()
+		return res, cli.Exit(color.RedString("Unable to check out commit, there is an issue with the package repo: %s", err.Error()), 1)
+	}
+	branch := headBranch(gitRepo)
+	checkoutCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	if err := gitRepo.CheckoutCommit(checkoutCtx, git.DefaultRemoteName, locked.Commit); err != nil {
+		logger.Debugf("Checkout error: %s", err.Error())
+		term.Spinner().Fail()
+		res.Error = err.Error()
+		return res, cli.Exit(color.RedString("Unable to check out locked commit %s of package \"%s\" (%s)", locked.Commit, locked.Name, err.Error()), 1)
+	}
+	term.Spinner().OK()
+
+	pkg, err := installPackageDependencies(ctx, langManager, dir, hookPostUpdate, forceBinary, logger)
+	if err != nil {
+		res.Error = err.Error()
+		return res, cli.Exit(color.RedString("Unable to install dependencies of package \"%s\"", locked.Name), 1)
+	}
+	saveInstallRecord(ctx, dir, locked.Commit)
+	recordPackageEvent(ctx, packageEventUpdate, dir)
+	pinPackage(ctx, dir, locked.Commit, branch)
+
+	res.Status, res.Version, res.Commit, res.Path = installStatusInstalled, packageVersion(*pkg), locked.Commit, dir
+	for _, cmd := range pkg.Commands {
+		res.Commands = append(res.Commands, cmd.Name)
+	}
+	return res, nil
+}
+
+// lockPackages records the commits the packages are locked to, pinning them to these commits if pin is set
+func lockPackages(ctx context.Context, locked []lockedPackage, pin bool) error {
+	return updatePackageStore(ctx, func(store *packageStore) error {
+		store.migrateInstalled(ctx, "")
+		for _, l := range locked {
+			if pkg, ok := store.Packages[l.Name]; ok && pkg.Installed {
+				pkg.Locked = l.Commit
+				if pin {
+					pkg.Pinned = l.Commit
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// unlockPackage removes the lock of the package in given directory, along with the pin to the locked commit
+func unlockPackage(ctx context.Context, dir string) {
+	err := updatePackageStore(ctx, func(store *packageStore) error {
+		pkg, ok := store.Packages[filepath.Base(dir)]
+		if !ok {
+			return errPackageStoreUnchanged
+		}
+		if pkg.Pinned == pkg.Locked {
+			pkg.Pinned = ""
+		}
+		pkg.Locked = ""
+		return nil
+	})
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to update package store: %s", err)
+	}
+}
@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	lockedCommit  = "0100000000000000000000000000000000000000"
+	currentCommit = "0200000000000000000000000000000000000000"
+)
+
+// installLockablePackage installs a package with given origin and commit into the store of cliHome
+func installLockablePackage(t *testing.T, cliHome, name, origin, commit string) string {
+	dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), name, `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+	if origin != "" {
+		repo, err := gogit.PlainInit(dir, false)
+		require.NoError(t, err)
+		_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{origin}})
+		require.NoError(t, err)
+	}
+	saveInstallRecord(context.Background(), dir, commit)
+	recordPackageEvent(context.Background(), packageEventInstall, dir)
+	return dir
+}
+
+func TestCmdLock(t *testing.T) {
+	tests := map[string]struct {
+		origin    string
+		args      []string
+		init      func(*mocked)
+		expected  string
+		withError string
+	}{
+		"write lockfile": {
+			origin: "https://github.com/akamai/cli-dns.git",
+			init: func(m *mocked) {
+				m.term.On("Printf", "Locked %d packages in %s.\n", []interface{}{1, color.BlueString(defaultLockFile)}).Return().Once()
+				m.term.On("Printf", "Install them on other machines with \"%s\", updates require --unlock.\n", mock.Anything).Return().Once()
+			},
+			expected: defaultLockFile,
+		},
+		"write lockfile to standard output": {
+			origin: "https://github.com/akamai/cli-dns.git",
+			args:   []string{"-"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "Install them on other machines with \"%s\", updates require --unlock.\n", mock.Anything).Return().Once()
+			},
+		},
+		"package without repository": {
+			init:      func(m *mocked) {},
+			withError: "Unable to lock packages without a recorded repository and commit: cli-dns",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			wd, err := os.Getwd()
+			require.NoError(t, err)
+			require.NoError(t, os.Chdir(cliHome))
+			defer func() {
+				require.NoError(t, os.Chdir(wd))
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := installLockablePackage(t, cliHome, "cli-dns", test.origin, lockedCommit)
+			recordDownloadedBinary(context.Background(), dir, "dns", downloadedBinary{Path: "bin/akamai-dns", Platform: "linux-amd64", SHA256: "abc"})
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			test.init(m)
+			app, ctx := setupTestApp(&cli.Command{Name: "lock", Action: cmdLock}, m)
+
+			err = app.RunContext(ctx, append(append(os.Args[0:1], "lock"), test.args...))
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, lockedCommit, store.Packages["cli-dns"].Locked)
+			assert.Empty(t, store.Packages["cli-dns"].Pinned)
+			if test.expected == "" {
+				return
+			}
+			lock, err := readLockFile(test.expected)
+			require.NoError(t, err)
+			assert.Equal(t, &lockFile{Version: lockFileVersion, Packages: []lockedPackage{{
+				Name:     "cli-dns",
+				Source:   "https://github.com/akamai/cli-dns.git",
+				Commit:   lockedCommit,
+				Binaries: map[string]lockedBinary{"dns": {Platform: "linux-amd64", SHA256: "abc"}},
+			}}}, lock)
+		})
+	}
+}
+
+func TestInstallFromLock(t *testing.T) {
+	platform := binaryPlatform(withPlatform(command{}))
+	tests := map[string]struct {
+		installedCommit string
+		lock            interface{}
+		checksum        string
+		init            func(*mocked, string)
+		withError       string
+	}{
+		"package at locked commit": {
+			installedCommit: lockedCommit,
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s is already installed at commit %s.\n", []interface{}{color.BlueString("cli-dns"), "0100000"}).Return().Once()
+				m.term.On("Printf", "Installed %d packages from %s.\n", mock.Anything).Return().Once()
+			},
+		},
+		"check out locked commit": {
+			installedCommit: currentCommit,
+			init: func(m *mocked, dir string) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking out commit %s of package \"%s\"...", []interface{}{"0100000", "cli-dns"}).Return().Once()
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, lockedCommit).Return(nil).Once()
+				m.term.On("OK").Return().Twice()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", dir, packages.LanguageRequirements{}, []string{"dns"}).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Maybe()
+				m.term.On("Printf", "Installed %d packages from %s.\n", mock.Anything).Return().Once()
+			},
+		},
+		"locked commit unreachable": {
+			installedCommit: currentCommit,
+			init: func(m *mocked, dir string) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking out commit %s of package \"%s\"...", []interface{}{"0100000", "cli-dns"}).Return().Once()
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, lockedCommit).Return(plumbing.ErrObjectNotFound).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: fmt.Sprintf(`Unable to check out locked commit %s of package "cli-dns" (object not found)`, lockedCommit),
+		},
+		"binary does not match": {
+			installedCommit: lockedCommit,
+			checksum:        "other",
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s is already installed at commit %s.\n", mock.Anything).Return().Once()
+			},
+			withError: "Downloaded binaries do not match the lockfile: dns (cli-dns)",
+		},
+		"invalid commit": {
+			lock:      lockFile{Version: 1, Packages: []lockedPackage{{Name: "cli-dns", Source: "https://github.com/akamai/cli-dns.git", Commit: "v1.0.0"}}},
+			init:      func(m *mocked, dir string) {},
+			withError: `package cli-dns has invalid commit "v1.0.0"`,
+		},
+		"newer lockfile": {
+			lock:      lockFile{Version: lockFileVersion + 1},
+			init:      func(m *mocked, dir string) {},
+			withError: fmt.Sprintf("has schema version %d", lockFileVersion+1),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := installLockablePackage(t, cliHome, "cli-dns", "https://github.com/akamai/cli-dns.git", test.installedCommit)
+			recordDownloadedBinary(context.Background(), dir, "dns", downloadedBinary{Path: "bin/akamai-dns", Platform: platform, SHA256: "abc"})
+			lock := test.lock
+			if lock == nil {
+				checksum := test.checksum
+				if checksum == "" {
+					checksum = "abc"
+				}
+				lock = lockFile{Version: 1, Packages: []lockedPackage{{
+					Name:     "cli-dns",
+					Source:   "https://github.com/akamai/cli-dns.git",
+					Commit:   lockedCommit,
+					Binaries: map[string]lockedBinary{"dns": {Platform: platform, SHA256: checksum}},
+				}}}
+			}
+			data, err := json.Marshal(lock)
+			require.NoError(t, err)
+			lockPath := filepath.Join(cliHome, defaultLockFile)
+			require.NoError(t, ioutil.WriteFile(lockPath, data, 0644))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			test.init(m, dir)
+			command := &cli.Command{
+				Name:   "install",
+				Action: cmdInstall(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.StringFlag{Name: "from-lock"}, &cli.StringSliceFlag{Name: "mirror"}},
+			}
+			app, ctx := setupTestApp(command, m)
+
+			err = app.RunContext(ctx, append(os.Args[0:1], "install", "--from-lock", lockPath))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			pkg := store.Packages["cli-dns"]
+			assert.Equal(t, lockedCommit, pkg.Commit)
+			assert.Equal(t, lockedCommit, pkg.Locked)
+			assert.Equal(t, lockedCommit, pkg.Pinned)
+		})
+	}
+}
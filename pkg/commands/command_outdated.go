@@ -0,0 +1,396 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/warnings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+const (
+	// updateCheckKey is the config key enabling the background check of package updates: "daily", "weekly" or an interval such as 72h
+	updateCheckKey = "update-check"
+	// lastUpdateCheckKey holds the time the background check was last started
+	lastUpdateCheckKey = "last-update-check"
+	// outdatedCacheFile is the file in the cache directory holding the results of the last check
+	outdatedCacheFile = "outdated.json"
+)
+
+// statuses of packages reported by "outdated"
+const (
+	outdatedStatusUpToDate  = "up-to-date"
+	outdatedStatusOutdated  = "outdated"
+	outdatedStatusPinned    = "pinned"
+	outdatedStatusLocked    = "locked"
+	outdatedStatusUnchecked = "unchecked"
+	outdatedStatusFailed    = "failed"
+)
+
+type (
+	// outdatedPackage compares the installed revision of a package with the latest one of its update channel
+	outdatedPackage struct {
+		Package string `json:"package"`
+		Channel string `json:"channel"`
+		Current string `json:"current"`
+		Commit  string `json:"commit,omitempty"`
+		Latest  string `json:"latest,omitempty"`
+		Commits int    `json:"commits,omitempty"`
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+
+		// latestCommit is the commit Latest points to, it is not kept in the saved results
+		latestCommit plumbing.Hash
+		// tags are the release tags of the remote, also not kept
+		tags []string
+	}
+
+	// outdatedCheck holds the results of the last check of all packages, used for the notice of pending updates
+	outdatedCheck struct {
+		Time     time.Time         `json:"time"`
+		Packages []outdatedPackage `json:"packages"`
+	}
+)
+
+// startUpdateCheck runs "outdated" in a background process which outlives the current one.
+// It is a variable so that tests do not start processes.
+var startUpdateCheck = func() error {
+	bin, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(bin, "--no-update-check", "--quiet", "outdated", "--background")
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+func cmdOutdated(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("OUTDATED START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("OUTDATED FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("OUTDATED ERROR: %v", e.Error())
+			}
+		}()
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		dirs := make([]string, 0)
+		if c.Args().Present() {
+			store, err := loadPackageStore(c.Context)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+			}
+			store.migrateInstalled(c.Context, "")
+			srcPath, err := tools.GetAkamaiCliSrcPath()
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+			}
+			for _, name := range c.Args().Slice() {
+				pkgName, ok := installedPackageName(c, langManager, store, name)
+				if !ok {
+					return packageNotFound(name)
+				}
+				dirs = append(dirs, filepath.Join(srcPath, pkgName))
+			}
+		} else {
+			for _, dir := range getPackagePaths() {
+				if fileExists(filepath.Join(dir, "cli.json")) {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+
+		term := terminal.Get(c.Context)
+		showSpinner := format == plugin.FormatTable && !c.Bool("background")
+		if showSpinner {
+			term.Spinner().Start("Checking %d packages for updates...", len(dirs))
+		}
+		channels := packageUpdateChannels()
+		results := make([]outdatedPackage, 0, len(dirs))
+		for _, dir := range dirs {
+			results = append(results, checkOutdatedPackage(c.Context, gitRepo, channels[filepath.Base(dir)], dir))
+		}
+		if showSpinner {
+			term.Spinner().OK()
+		}
+		if !c.Args().Present() {
+			saveOutdatedCheck(c.Context, outdatedCheck{Time: time.Now().UTC(), Packages: results})
+		}
+		if c.Bool("background") {
+			return nil
+		}
+
+		rows := make([][]string, 0, len(results))
+		for _, res := range results {
+			rows = append(rows, []string{res.Package, res.Status, res.Current, res.Latest})
+		}
+		return render(c, format, renderedOutput{
+			Value:   results,
+			Columns: []string{"package", "status", "current", "latest"},
+			Rows:    rows,
+			Table: func() error {
+				return printOutdatedPackages(term, results)
+			},
+		})
+	}
+}
+
+// checkOutdatedPackage fetches the update channel of the package in dir, without merging anything,
+// and compares its latest revision with the installed one
+func checkOutdatedPackage(ctx context.Context, gitRepo git.Repository, meta *packageMetadata, dir string) (res outdatedPackage) {
+	logger := log.FromContext(ctx)
+	res = outdatedPackage{Package: filepath.Base(dir), Channel: channelBranch, Status: outdatedStatusFailed}
+	defer func() {
+		logger.Debugf("Package %s: %s %s -> %s", res.Package, res.Status, res.Current, res.Latest)
+	}()
+	if meta != nil {
+		res.Channel = meta.channel()
+		if meta.Archive != "" {
+			res.Status, res.Message = outdatedStatusUnchecked, fmt.Sprintf("installed from archive %s", meta.Archive)
+			return res
+		}
+	}
+	if record, err := readSourceRecord(dir); err == nil {
+		res.Current = shortHash(record.Revision)
+		res.Status, res.Message = outdatedStatusUnchecked, fmt.Sprintf("checked only by \"%s update\" for packages of the %s source", tools.Self(), record.Source)
+		return res
+	}
+	if err := gitRepo.Open(dir); err != nil {
+		res.Message = err.Error()
+		return res
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		res.Message = err.Error()
+		return res
+	}
+	res.Commit = head.Hash().String()
+	res.Current = shortHash(res.Commit)
+
+	fetchCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	var latest plumbing.Hash
+	if res.Channel != channelBranch {
+		tags, err := gitRepo.FetchTags(fetchCtx, git.DefaultRemoteName)
+		if err != nil {
+			res.Message = err.Error()
+			return res
+		}
+		tag, ok := latestChannelTag(tags, res.Channel)
+		if !ok {
+			res.Message = fmt.Sprintf("no %s release tags found", res.Channel)
+			return res
+		}
+		if latest, err = gitRepo.TagCommit(tag); err != nil {
+			res.Message = err.Error()
+			return res
+		}
+		res.Latest, res.tags = tag, tags
+		var current []string
+		for _, t := range tags {
+			if hash, err := gitRepo.TagCommit(t); err == nil && hash == head.Hash() {
+				current = append(current, t)
+			}
+		}
+		if t, ok := latestChannelTag(current, channelPrerelease); ok {
+			res.Current = t
+		}
+	} else {
+		branch := headBranch(gitRepo)
+		if branch == "" && meta != nil {
+			branch = meta.Branch
+		}
+		if branch == "" {
+			if branch, err = gitRepo.DefaultBranch(fetchCtx, git.DefaultRemoteName); err != nil {
+				res.Message = err.Error()
+				return res
+			}
+		}
+		if latest, err = gitRepo.FetchBranch(fetchCtx, git.DefaultRemoteName, branch); err != nil {
+			res.Message = err.Error()
+			return res
+		}
+		res.Current = fmt.Sprintf("%s@%s", branch, res.Current)
+		res.Latest = fmt.Sprintf("%s@%s", branch, shortHash(latest.String()))
+	}
+
+	res.latestCommit = latest
+	res.Status = outdatedStatusUpToDate
+	if latest == head.Hash() {
+		return res
+	}
+	res.Status = outdatedStatusOutdated
+	if changes, err := gitRepo.Changes(head.Hash(), latest); err == nil {
+		res.Commits = changes.Commits
+	}
+	switch {
+	case meta != nil && meta.Locked != "":
+		res.Status, res.Message = outdatedStatusLocked, fmt.Sprintf("locked to commit %s", shortHash(meta.Locked))
+	case meta != nil && meta.Pinned != "":
+		res.Status, res.Message = outdatedStatusPinned, fmt.Sprintf("pinned to %s", meta.Pinned)
+	}
+	return res
+}
+
+func printOutdatedPackages(term terminal.Terminal, results []outdatedPackage) error {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tCURRENT\tLATEST\tSTATUS")
+	outdated := 0
+	for _, res := range results {
+		status := res.Status
+		switch {
+		case res.Status == outdatedStatusOutdated && res.Commits > 0:
+			outdated++
+			status = fmt.Sprintf("%s, %d new commits", res.Status, res.Commits)
+		case res.Status == outdatedStatusOutdated:
+			outdated++
+		case res.Message != "":
+			status = fmt.Sprintf("%s: %s", res.Status, res.Message)
+		}
+		latest := res.Latest
+		if latest == "" {
+			latest = "-"
+		}
+		current := res.Current
+		if current == "" {
+			current = "-"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", res.Package, current, latest, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("\nOutdated Packages:\n"))
+	term.Printf("%s", buf.String())
+	if outdated > 0 {
+		term.Printf("\nUpdate using \"%s\".\n", color.BlueString("%s update [command]", tools.Self()))
+	}
+	return nil
+}
+
+// saveOutdatedCheck caches the results of a check of all packages for the notice of pending updates
+func saveOutdatedCheck(ctx context.Context, check outdatedCheck) {
+	logger := log.FromContext(ctx)
+	dir, err := cacheDir(ctx)
+	if err != nil {
+		logger.Debugf("Unable to find cache directory: %s", err)
+		return
+	}
+	data, err := json.MarshalIndent(check, "", "  ")
+	if err != nil {
+		logger.Debugf("Unable to encode update check: %s", err)
+		return
+	}
+	if err := writeCache(dir, outdatedCacheFile, data); err != nil {
+		logger.Debugf("Unable to cache update check: %s", err)
+	}
+}
+
+// CheckOutdatedPackages prints a notice of the packages with pending updates, found by the last background check,
+// and starts a new check once the interval set in cli.update-check has elapsed since the last one.
+// Packages updated since the last check are not counted.
+func CheckOutdatedPackages(ctx context.Context) {
+	interval, ok := settingInterval(ctx, updateCheckKey)
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	cfg := config.Get(ctx)
+	term := terminal.Get(ctx)
+	if term.IsTTY() {
+		if count := pendingPackageUpdates(ctx); count == 1 {
+			warnings.Warn(ctx, "package-updates", fmt.Sprintf("1 package has updates, run \"%s update\"", tools.Self()))
+		} else if count > 1 {
+			warnings.Warn(ctx, "package-updates", fmt.Sprintf("%d packages have updates, run \"%s update\"", count, tools.Self()))
+		}
+	}
+
+	if val, ok := cfg.GetValue("cli", lastUpdateCheckKey); ok {
+		if last, err := time.Parse(time.RFC3339, strings.TrimSpace(val)); err == nil && time.Since(last) < interval {
+			return
+		}
+	}
+	cfg.SetValue("cli", lastUpdateCheckKey, time.Now().UTC().Format(time.RFC3339))
+	if err := cfg.Save(ctx); err != nil {
+		logger.Debugf("Unable to save the time of the update check: %s", err)
+	}
+	if err := startUpdateCheck(); err != nil {
+		logger.Debugf("Unable to start the update check: %s", err)
+	}
+}
+
+// pendingPackageUpdates returns the number of outdated packages found by the last check, which are still installed
+// at the commit checked
+func pendingPackageUpdates(ctx context.Context) int {
+	dir, err := cacheDir(ctx)
+	if err != nil {
+		return 0
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, outdatedCacheFile))
+	if err != nil {
+		return 0
+	}
+	var check outdatedCheck
+	if err := json.Unmarshal(data, &check); err != nil {
+		log.FromContext(ctx).Debugf("Invalid update check cache: %s", err)
+		return 0
+	}
+	store, err := loadPackageStore(ctx)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, res := range check.Packages {
+		pkg, ok := store.Packages[res.Package]
+		if res.Status == outdatedStatusOutdated && ok && pkg.Installed && pkg.Commit == res.Commit {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/warnings"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCmdOutdated(t *testing.T) {
+	installed := plumbing.NewHash("1111111111111111111111111111111111111111")
+	latest := plumbing.NewHash("2222222222222222222222222222222222222222")
+	beta := plumbing.NewHash("3333333333333333333333333333333333333333")
+	master := plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), installed)
+	detached := plumbing.NewHashReference(plumbing.HEAD, installed)
+
+	tests := map[string]struct {
+		args      []string
+		meta      *packageMetadata
+		source    bool
+		init      func(*mocked)
+		expected  string
+		results   []outdatedPackage
+		withError string
+	}{
+		"branch with new commits": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(latest, nil).Once()
+				m.gitRepo.On("Changes", installed, latest).Return(&git.Changes{Commits: 3}, nil).Once()
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", []interface{}{color.BlueString("commands.test update [command]")}).Return().Once()
+			},
+			expected: "  PACKAGE  CURRENT         LATEST          STATUS\n  cli-dns  master@1111111  master@2222222  outdated, 3 new commits\n",
+			results:  []outdatedPackage{{Package: "cli-dns", Channel: channelBranch, Current: "master@1111111", Commit: installed.String(), Latest: "master@2222222", Commits: 3, Status: outdatedStatusOutdated}},
+		},
+		"branch up-to-date": {
+			args: []string{"dns"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(installed, nil).Once()
+			},
+			expected: "  PACKAGE  CURRENT         LATEST          STATUS\n  cli-dns  master@1111111  master@1111111  up-to-date\n",
+		},
+		"detached package returns to the default branch": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(detached, nil)
+				m.gitRepo.On("DefaultBranch", git.DefaultRemoteName).Return("main", nil).Once()
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "main").Return(installed, nil).Once()
+			},
+			expected: "  PACKAGE  CURRENT       LATEST        STATUS\n  cli-dns  main@1111111  main@1111111  up-to-date\n",
+			results:  []outdatedPackage{{Package: "cli-dns", Channel: channelBranch, Current: "main@1111111", Commit: installed.String(), Latest: "main@1111111", Status: outdatedStatusUpToDate}},
+		},
+		"stable channel": {
+			meta: &packageMetadata{Channel: channelStable},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(detached, nil)
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0", "v1.2.0-beta.1"}, nil).Once()
+				m.gitRepo.On("TagCommit", "v1.0.0").Return(installed, nil)
+				m.gitRepo.On("TagCommit", "v1.1.0").Return(latest, nil)
+				m.gitRepo.On("TagCommit", "v1.2.0-beta.1").Return(beta, nil)
+				m.gitRepo.On("Changes", installed, latest).Return(nil, errors.New("object not found")).Once()
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", []interface{}{color.BlueString("commands.test update [command]")}).Return().Once()
+			},
+			expected: "  PACKAGE  CURRENT  LATEST  STATUS\n  cli-dns  v1.0.0   v1.1.0  outdated\n",
+			results:  []outdatedPackage{{Package: "cli-dns", Channel: channelStable, Current: "v1.0.0", Commit: installed.String(), Latest: "v1.1.0", Status: outdatedStatusOutdated}},
+		},
+		"pinned package": {
+			meta: &packageMetadata{Pinned: "v1.0.0", Branch: "master"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(detached, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(latest, nil).Once()
+				m.gitRepo.On("Changes", installed, latest).Return(&git.Changes{Commits: 1}, nil).Once()
+			},
+			expected: "  PACKAGE  CURRENT         LATEST          STATUS\n  cli-dns  master@1111111  master@2222222  pinned: pinned to v1.0.0\n",
+		},
+		"fetch failure": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(plumbing.ZeroHash, errors.New("authentication required")).Once()
+			},
+			expected: "  PACKAGE  CURRENT  LATEST  STATUS\n  cli-dns  1111111  -       failed: authentication required\n",
+		},
+		"tarball package": {
+			source:   true,
+			expected: "  PACKAGE  CURRENT  LATEST  STATUS\n  cli-dns  abcdef0  -       unchecked: checked only by \"commands.test update\" for packages of the tarball source\n",
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			withError: `Package "cli-missing" not found.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			if test.source {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, sourceRecordFile), []byte(`{"source": "tarball", "url": "https://example.com/cli-dns.tar.gz", "revision": "abcdef0123"}`), 0644))
+			}
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			store.migrateInstalled(context.Background(), "")
+			if test.meta != nil {
+				store.Packages["cli-dns"].Channel = test.meta.Channel
+				store.Packages["cli-dns"].Branch = test.meta.Branch
+				store.Packages["cli-dns"].Pinned = test.meta.Pinned
+			}
+			require.NoError(t, store.save())
+			cachePath := filepath.Join(cliHome, "cache")
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.gitRepo.On("Open", dir).Return(nil).Maybe()
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			m.term.On("Spinner").Return(m.term).Maybe()
+			m.term.On("Start", mock.Anything, mock.Anything).Return().Maybe()
+			m.term.On("OK").Return().Maybe()
+			if test.init != nil {
+				test.init(m)
+			}
+			if test.expected != "" {
+				m.term.On("Writeln", []interface{}{color.YellowString("\nOutdated Packages:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", []interface{}{test.expected}).Return().Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "outdated",
+				Action: cmdOutdated(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "background"}, plainFlag(), outputFlag()},
+			}, m)
+
+			err = app.RunContext(ctx, append(append(os.Args[0:1], "outdated"), test.args...))
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if test.results != nil {
+				data, err := ioutil.ReadFile(filepath.Join(cachePath, outdatedCacheFile))
+				require.NoError(t, err)
+				var check outdatedCheck
+				require.NoError(t, json.Unmarshal(data, &check))
+				assert.Equal(t, test.results, check.Packages)
+			}
+		})
+	}
+}
+
+func TestCmdOutdatedOutput(t *testing.T) {
+	installed := plumbing.NewHash("1111111111111111111111111111111111111111")
+	latest := plumbing.NewHash("2222222222222222222222222222222222222222")
+	tests := map[string]struct {
+		args     []string
+		expected interface{}
+	}{
+		"json": {
+			args:     []string{"--output", "json"},
+			expected: []interface{}{"[\n  {\n    \"package\": \"cli-dns\",\n    \"channel\": \"branch\",\n    \"current\": \"master@1111111\",\n    \"commit\": \"1111111111111111111111111111111111111111\",\n    \"latest\": \"master@2222222\",\n    \"commits\": 2,\n    \"status\": \"outdated\"\n  }\n]"},
+		},
+		"plain": {
+			args:     []string{"--plain"},
+			expected: []interface{}{"cli-dns\toutdated\tmaster@1111111\tmaster@2222222"},
+		},
+		"background": {
+			args: []string{"--background"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			cachePath := filepath.Join(cliHome, "cache")
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.gitRepo.On("Open", dir).Return(nil).Once()
+			m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), installed), nil)
+			m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(latest, nil).Once()
+			m.gitRepo.On("Changes", installed, latest).Return(&git.Changes{Commits: 2}, nil).Once()
+			if test.expected != nil {
+				m.term.On("Writeln", test.expected).Return(0, nil).Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "outdated",
+				Action: cmdOutdated(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "background"}, plainFlag(), outputFlag()},
+			}, m)
+
+			require.NoError(t, app.RunContext(ctx, append(append(os.Args[0:1], "outdated"), test.args...)))
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			assert.FileExists(t, filepath.Join(cachePath, outdatedCacheFile))
+		})
+	}
+}
+
+func TestCheckOutdatedPackages(t *testing.T) {
+	defer func(start func() error) {
+		startUpdateCheck = start
+	}(startUpdateCheck)
+	installed := "1111111111111111111111111111111111111111"
+	tests := map[string]struct {
+		check    string
+		last     string
+		packages []outdatedPackage
+		expected string
+		started  bool
+	}{
+		"first check": {
+			check:   "daily",
+			started: true,
+		},
+		"packages with updates": {
+			check: "weekly",
+			last:  time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			packages: []outdatedPackage{
+				{Package: "cli-dns", Commit: installed, Status: outdatedStatusOutdated},
+				{Package: "cli-echo", Commit: installed, Status: outdatedStatusOutdated},
+				{Package: "cli-property", Commit: installed, Status: outdatedStatusPinned},
+			},
+			expected: color.YellowString("2 packages have updates, run \"commands.test update\"") + "\n",
+		},
+		"package updated since the check": {
+			check: "72h",
+			last:  time.Now().Add(-73 * time.Hour).UTC().Format(time.RFC3339),
+			packages: []outdatedPackage{
+				{Package: "cli-dns", Commit: installed, Status: outdatedStatusOutdated},
+				{Package: "cli-echo", Commit: "2222222222222222222222222222222222222222", Status: outdatedStatusOutdated},
+			},
+			expected: color.YellowString("1 package has updates, run \"commands.test update\"") + "\n",
+			started:  true,
+		},
+		"all up-to-date": {
+			check:    "daily",
+			last:     time.Now().UTC().Format(time.RFC3339),
+			packages: []outdatedPackage{{Package: "cli-dns", Commit: installed, Status: outdatedStatusUpToDate}},
+		},
+		"check disabled": {
+			packages: []outdatedPackage{{Package: "cli-dns", Commit: installed, Status: outdatedStatusOutdated}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			started := false
+			startUpdateCheck = func() error {
+				started = true
+				return nil
+			}
+			srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+			store := &packageStore{Version: packageStoreVersion, Packages: map[string]*packageMetadata{}}
+			for _, name := range []string{"cli-dns", "cli-echo"} {
+				writeStorePackage(t, srcDir, name, `{"commands": [{"name": "`+name[4:]+`", "version": "1.0.0"}]}`)
+				store.Packages[name] = &packageMetadata{Name: name, Installed: true, Commit: installed, Commands: []string{name[4:]}, Binaries: []string{}, History: []packageStoreEvent{{Event: packageEventInstall, Commit: installed, Time: time.Now()}}}
+			}
+			require.NoError(t, store.save())
+			cachePath := filepath.Join(cliHome, "cache")
+			if test.packages != nil {
+				data, err := json.Marshal(outdatedCheck{Time: time.Now(), Packages: test.packages})
+				require.NoError(t, err)
+				require.NoError(t, writeCache(cachePath, outdatedCacheFile, data))
+			}
+
+			cfg, term := &config.Mock{}, &terminal.Mock{}
+			cfg.On("GetValue", "cli", updateCheckKey).Return(test.check, test.check != "")
+			cfg.On("GetValue", "cli", lastUpdateCheckKey).Return(test.last, test.last != "").Maybe()
+			cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			cfg.On("GetValue", "cli", warnings.IntervalKey).Return("0", true).Maybe()
+			if test.started {
+				cfg.On("SetValue", "cli", lastUpdateCheckKey, mock.Anything).Return().Once()
+				cfg.On("Save", mock.Anything).Return(nil).Once()
+			}
+			term.On("IsTTY").Return(true).Maybe()
+			out := &bytes.Buffer{}
+			term.On("Error").Return(out).Maybe()
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+			CheckOutdatedPackages(ctx)
+			cfg.AssertExpectations(t)
+			assert.Equal(t, test.expected, out.String())
+			assert.Equal(t, test.started, started)
+		})
+	}
+}
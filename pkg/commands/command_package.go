@@ -0,0 +1,335 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// packageStatus describes drift of an installed package from the state recorded at install
+type packageStatus struct {
+	Package    string         `json:"package"`
+	RecordedAt time.Time      `json:"recordedAt"`
+	Commit     string         `json:"commit,omitempty"`
+	Drift      []packageDrift `json:"drift"`
+}
+
+func cmdPackageStatus(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE STATUS START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE STATUS FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE STATUS ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		name := c.Args().First()
+		dir := installedPackageDir(c, langManager, name)
+		if dir == "" {
+			return packageNotFound(name)
+		}
+
+		recorded, err := readInstallRecord(dir)
+		if err != nil {
+			return cli.Exit(color.RedString("No install record found for \"%s\", it is created when the package is installed or updated", name), 1)
+		}
+		var commit string
+		if err := gitRepo.Open(dir); err == nil {
+			if ref, err := gitRepo.Head(); err == nil {
+				commit = ref.Hash().String()
+			}
+		}
+		current, err := newInstallRecord(dir, commit)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package state: %s", err), 1)
+		}
+
+		status := packageStatus{
+			Package:    filepath.Base(dir),
+			RecordedAt: recorded.InstalledAt,
+			Commit:     recorded.Commit,
+			Drift:      compareInstallRecord(recorded, current),
+		}
+		if format != plugin.FormatTable {
+			if err := render(c, format, renderedOutput{Value: status}); err != nil {
+				return err
+			}
+		} else {
+			printPackageStatus(term, status)
+		}
+
+		if len(status.Drift) > 0 {
+			return cli.Exit(color.RedString("Package \"%s\" has changed since it was installed", status.Package), 1)
+		}
+		return nil
+	}
+}
+
+func printPackageStatus(term terminal.Terminal, status packageStatus) {
+	bold := color.New(color.FgWhite, color.Bold)
+	term.Printf(bold.Sprint("Package:")+" %s\n", status.Package)
+	term.Printf(bold.Sprint("Recorded:")+" %s\n", status.RecordedAt.Local().Format(time.RFC3339))
+	if status.Commit != "" {
+		term.Printf(bold.Sprint("Commit:")+" %s\n", shortHash(status.Commit))
+	}
+	if len(status.Drift) == 0 {
+		term.Writeln(color.GreenString("No changes since the package was installed."))
+		return
+	}
+	term.Writeln(color.YellowString("\nChanges since the package was installed:"))
+	for _, drift := range status.Drift {
+		if drift.Change == driftCommit {
+			term.Printf("  %s: %s -> %s\n", drift.Change, shortHash(drift.Recorded), shortHash(drift.Current))
+			continue
+		}
+		term.Printf("  %s: %s\n", drift.Path, drift.Change)
+	}
+}
+
+// installedPackageDir returns the directory of an installed package given its directory name or the name of one of its commands
+func installedPackageDir(c *cli.Context, langManager packages.LangManager, name string) string {
+	if srcPath, err := tools.GetAkamaiCliSrcPath(); err == nil {
+		dir := filepath.Join(srcPath, filepath.Base(name))
+		if _, err := os.Stat(filepath.Join(dir, "cli.json")); err == nil {
+			return dir
+		}
+	}
+	executable, err := findCommandExec(c.Context, langManager, name)
+	if err != nil || len(executable) == 0 {
+		return ""
+	}
+	return findPackageDir(filepath.Dir(executable[len(executable)-1]))
+}
+
+// upstreamRemoteName is the remote keeping the original URL of a package re-pointed to a fork
+const upstreamRemoteName = "upstream"
+
+func cmdPackageSetRemote(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE SET-REMOTE START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE SET-REMOTE FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE SET-REMOTE ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if c.NArg() != 2 {
+			return cli.Exit(color.RedString("You must specify a package or command name and a repository URL"), 1)
+		}
+
+		name := c.Args().First()
+		dir := installedPackageDir(c, langManager, name)
+		if dir == "" {
+			return packageNotFound(name)
+		}
+		if err := gitRepo.Open(dir); err != nil {
+			return cli.Exit(color.RedString("Package \"%s\" was not installed from a git repository", filepath.Base(dir)), 1)
+		}
+
+		repo := tools.Githubize(c.Args().Get(1))
+		if err := requireVerified(c.Context, repo); err != nil {
+			return err
+		}
+		branch := c.String("branch")
+		if branch != "" {
+			// checking out a branch over uncommitted changes would lose them, as update refuses to since its reset
+			worktree, err := gitRepo.Worktree()
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read the package worktree: %s", err), 1)
+			}
+			if dirty, err := worktreeDirty(worktree); err != nil || dirty {
+				return cli.Exit(color.RedString("Unable to check out branch %s, package %s has uncommitted changes in %s", branch, filepath.Base(dir), dir), 1)
+			}
+		}
+		oldRepo, err := gitRepo.RemoteURL(git.DefaultRemoteName)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read the package remote: %s", err), 1)
+		}
+		// the original remote is kept once, so that the package can be pointed back to it after several set-remote calls
+		if oldRepo != repo {
+			if _, err := gitRepo.RemoteURL(upstreamRemoteName); err != nil {
+				if err := gitRepo.AddRemote(upstreamRemoteName, oldRepo); err != nil {
+					logger.Warnf("Unable to keep the previous remote as %s: %s", upstreamRemoteName, err)
+				}
+			}
+		}
+		if err := gitRepo.SetRemoteURL(git.DefaultRemoteName, repo); err != nil {
+			return cli.Exit(color.RedString("Unable to set the package remote: %s", err), 1)
+		}
+		logger.Debugf("Remote %s changed from %s to %s", git.DefaultRemoteName, oldRepo, repo)
+
+		if branch == "" {
+			recordPackageEvent(c.Context, packageEventSetRemote, dir)
+			term.Printf("Package %s now updates from %s.\n", color.BlueString(filepath.Base(dir)), repo)
+			term.Printf("Run \"%s\" to fetch its changes.\n", color.BlueString("%s update %s", tools.Self(), name))
+			return nil
+		}
+
+		refBefore, err := gitRepo.Head()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read the package commit: %s", err), 1)
+		}
+		term.Spinner().Start("Checking out branch %s of %s ...", branch, repo)
+		checkoutCtx, cancel := cloneContext(c.Context)
+		defer cancel()
+		if err := gitRepo.CheckoutBranch(checkoutCtx, git.DefaultRemoteName, branch); err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to check out branch %s: %s", branch, err), 1)
+		}
+		ref, err := gitRepo.Head()
+		if err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to read the package commit: %s", err), 1)
+		}
+		term.Spinner().OK()
+
+		if ref.Hash() != refBefore.Hash() {
+			if _, err := installPackageDependencies(c.Context, langManager, dir, "", false, logger); err != nil {
+				return cli.Exit(color.RedString("Unable to install dependencies of branch %s", branch), 1)
+			}
+			saveInstallRecord(c.Context, dir, ref.Hash().String())
+		}
+		recordPackageEvent(c.Context, packageEventSetRemote, dir)
+		term.Printf("Package %s now updates from branch %s of %s.\n", color.BlueString(filepath.Base(dir)), branch, repo)
+		return nil
+	}
+}
+
+func cmdPackageInfo(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE INFO START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE INFO FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE INFO ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		var store *packageStore
+		err = updatePackageStore(c.Context, func(s *packageStore) error {
+			store = s
+			if !store.migrateInstalled(c.Context, "") {
+				return errPackageStoreUnchanged
+			}
+			return nil
+		})
+		if store == nil {
+			return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+		}
+		if err != nil {
+			logger.Warnf("Unable to update package store: %s", err)
+		}
+
+		// uninstalled packages are looked up by their name, as their commands are gone
+		name := c.Args().First()
+		if dir := installedPackageDir(c, langManager, name); dir != "" {
+			name = filepath.Base(dir)
+		}
+		pkgName, ok := store.packageByName(filepath.Base(name))
+		if !ok {
+			return packageNotFound(name)
+		}
+		pkg := store.Packages[pkgName]
+		return render(c, format, renderedOutput{
+			Value:   pkg,
+			Columns: []string{"package", "status", "source", "version", "language", "commit", "channel", "pinned", "commands"},
+			Rows:    [][]string{{pkg.Name, pkg.status(), pkg.Source, pkg.Version, pkg.Language, pkg.Commit, pkg.channel(), pkg.Pinned, strings.Join(pkg.Commands, ",")}},
+			Table: func() error {
+				printPackageInfo(terminal.Get(c.Context), pkg)
+				return nil
+			},
+		})
+	}
+}
+
+func printPackageInfo(term terminal.Terminal, pkg *packageMetadata) {
+	bold := color.New(color.FgWhite, color.Bold)
+	status := color.GreenString(pkg.status())
+	if pkg.status() != "installed" {
+		status = color.YellowString(pkg.status())
+	}
+	term.Printf(bold.Sprint("Package:")+" %s (%s)\n", pkg.Name, status)
+	for _, field := range []struct{ name, value string }{
+		{"Source:", pkg.Source},
+		{"Archive:", pkg.Archive},
+		{"Version:", pkg.Version},
+		{"Language:", pkg.Language},
+		{"Commit:", shortHash(pkg.Commit)},
+		{"Channel:", pkg.Channel},
+		{"Branch:", pkg.Branch},
+		{"Pinned:", pkg.Pinned},
+		{"Previous:", shortHash(pkg.Previous)},
+		{"Commands:", strings.Join(pkg.Commands, ", ")},
+		{"Binaries:", strings.Join(pkg.Binaries, ", ")},
+	} {
+		if field.value != "" {
+			term.Printf(bold.Sprint(field.name)+" %s\n", field.value)
+		}
+	}
+	term.Writeln(bold.Sprint("History:"))
+	for _, event := range pkg.History {
+		line := fmt.Sprintf("  %s  %-10s", event.Time.Local().Format(time.RFC3339), event.Event)
+		if event.Version != "" {
+			line += " " + event.Version
+		}
+		if event.Commit != "" {
+			line += fmt.Sprintf(" (%s)", shortHash(event.Commit))
+		}
+		term.Writeln(line)
+	}
+}
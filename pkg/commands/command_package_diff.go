@@ -0,0 +1,280 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type (
+	// packageDiff summarizes what updating a package from its installed commit to another one would bring
+	packageDiff struct {
+		Package            string              `json:"package"`
+		From               string              `json:"from"`
+		To                 string              `json:"to"`
+		FromCommit         string              `json:"fromCommit"`
+		ToCommit           string              `json:"toCommit"`
+		Commits            []git.CommitSummary `json:"commits"`
+		FilesChanged       []string            `json:"filesChanged"`
+		DependencyChanges  []string            `json:"dependencyChanges"`
+		CommandChanges     []versionChange     `json:"commandChanges,omitempty"`
+		RequirementChanges []versionChange     `json:"requirementChanges,omitempty"`
+	}
+
+	// versionChange is a command or language requirement of a package whose version differs between two commits.
+	// Old is empty for added ones and New for removed ones.
+	versionChange struct {
+		Name string `json:"name"`
+		Old  string `json:"old,omitempty"`
+		New  string `json:"new,omitempty"`
+	}
+)
+
+func cmdPackageDiff(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE DIFF START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE DIFF FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE DIFF ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+
+		store, err := loadPackageStore(c.Context)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+		}
+		store.migrateInstalled(c.Context, "")
+		name := c.Args().First()
+		pkgName, ok := installedPackageName(c, langManager, store, name)
+		if !ok {
+			return packageNotFound(name)
+		}
+		meta := store.Packages[pkgName]
+		srcPath, err := tools.GetAkamaiCliSrcPath()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+		}
+		dir := filepath.Join(srcPath, pkgName)
+
+		diff, from, to, err := resolvePackageDiff(c.Context, gitRepo, meta, dir, c.String("to"))
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to compare package \"%s\": %s", pkgName, err), 1)
+		}
+		changes, err := gitRepo.Changes(from, to)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to compare package \"%s\": %s", pkgName, err), 1)
+		}
+		diff.Commits = changes.Log
+		if diff.Commits == nil {
+			diff.Commits = make([]git.CommitSummary, 0)
+		}
+		diff.FilesChanged = changes.FilesChanged
+		diff.DependencyChanges = dependencyChanges(changes.FilesChanged)
+		if installed, err := readPackage(dir); err == nil {
+			if data, err := gitRepo.FileContents(to, "cli.json"); err != nil {
+				logger.Debugf("Unable to read cli.json of commit %s: %s", to, err)
+			} else {
+				var target subcommands
+				if err := json.Unmarshal(data, &target); err != nil {
+					logger.Debugf("Invalid cli.json in commit %s: %s", to, err)
+				} else {
+					diff.CommandChanges = commandVersionChanges(installed.Commands, target.Commands)
+					diff.RequirementChanges = requirementChanges(installed.Requirements, target.Requirements)
+				}
+			}
+		}
+
+		if format != plugin.FormatTable {
+			return render(c, format, renderedOutput{Value: diff})
+		}
+		printPackageDiff(terminal.Get(c.Context), diff)
+		return nil
+	}
+}
+
+// resolvePackageDiff returns the installed commit of the package in dir and the commit to compare it with:
+// target may be a tag, a branch or a full commit hash, and defaults to the latest revision of the update channel of the package.
+func resolvePackageDiff(ctx context.Context, gitRepo git.Repository, meta *packageMetadata, dir, target string) (packageDiff, plumbing.Hash, plumbing.Hash, error) {
+	diff := packageDiff{Package: filepath.Base(dir)}
+	if target == "" {
+		res := checkOutdatedPackage(ctx, gitRepo, meta, dir)
+		if res.Status == outdatedStatusFailed || res.Status == outdatedStatusUnchecked {
+			return diff, plumbing.ZeroHash, plumbing.ZeroHash, fmt.Errorf("%s", res.Message)
+		}
+		diff.From, diff.To = res.Current, res.Latest
+		diff.FromCommit, diff.ToCommit = res.Commit, res.latestCommit.String()
+		return diff, plumbing.NewHash(res.Commit), res.latestCommit, nil
+	}
+
+	if meta != nil && meta.Archive != "" {
+		return diff, plumbing.ZeroHash, plumbing.ZeroHash, fmt.Errorf("installed from archive %s", meta.Archive)
+	}
+	if record, err := readSourceRecord(dir); err == nil {
+		return diff, plumbing.ZeroHash, plumbing.ZeroHash, fmt.Errorf("packages of the %s source have no history to compare", record.Source)
+	}
+	if err := gitRepo.Open(dir); err != nil {
+		return diff, plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return diff, plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	diff.FromCommit, diff.From = head.Hash().String(), shortHash(head.Hash().String())
+
+	fetchCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	if _, err := gitRepo.FetchTags(fetchCtx, git.DefaultRemoteName); err != nil {
+		return diff, plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	to, err := gitRepo.TagCommit(target)
+	switch {
+	case err == nil:
+		diff.To = target
+	case isCommitHash(target):
+		to = plumbing.NewHash(target)
+		diff.To = shortHash(target)
+	default:
+		if to, err = gitRepo.FetchBranch(fetchCtx, git.DefaultRemoteName, target); err != nil {
+			return diff, plumbing.ZeroHash, plumbing.ZeroHash, fmt.Errorf("no tag, branch or commit \"%s\" found", target)
+		}
+		diff.To = fmt.Sprintf("%s@%s", target, shortHash(to.String()))
+	}
+	diff.ToCommit = to.String()
+	return diff, head.Hash(), to, nil
+}
+
+// isCommitHash returns true if s is a full, hexadecimal commit hash
+func isCommitHash(s string) bool {
+	return len(s) == 40 && plumbing.NewHash(s).String() == strings.ToLower(s)
+}
+
+// commandVersionChanges lists the commands added, removed or having another version in target
+func commandVersionChanges(installed, target []command) []versionChange {
+	changes := make([]versionChange, 0)
+	versions := make(map[string]string, len(installed))
+	for _, cmd := range installed {
+		versions[strings.ToLower(cmd.Name)] = cmd.Version
+	}
+	for _, cmd := range target {
+		name := strings.ToLower(cmd.Name)
+		old, ok := versions[name]
+		delete(versions, name)
+		if !ok || old != cmd.Version {
+			changes = append(changes, versionChange{Name: name, Old: old, New: cmd.Version})
+		}
+	}
+	for name, old := range versions {
+		changes = append(changes, versionChange{Name: name, Old: old})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// requirementChanges lists the language requirements which target adds, removes or changes
+func requirementChanges(installed, target packages.LanguageRequirements) []versionChange {
+	changes := make([]versionChange, 0)
+	for _, req := range []struct{ name, old, new string }{
+		{"go", installed.Go, target.Go},
+		{"node", installed.Node, target.Node},
+		{"php", installed.Php, target.Php},
+		{"python", installed.Python, target.Python},
+		{"ruby", installed.Ruby, target.Ruby},
+	} {
+		if req.old != req.new {
+			changes = append(changes, versionChange{Name: req.name, Old: req.old, New: req.new})
+		}
+	}
+	return changes
+}
+
+func printPackageDiff(term terminal.Terminal, diff packageDiff) {
+	if diff.FromCommit == diff.ToCommit {
+		term.Printf("Package %s is already at %s.\n", color.BlueString(diff.Package), color.CyanString(diff.To))
+		return
+	}
+	term.Printf("Package %s: %s -> %s, %d commit(s), %d file(s) changed\n", color.BlueString(diff.Package), diff.From, color.CyanString(diff.To), len(diff.Commits), len(diff.FilesChanged))
+	if len(diff.Commits) > 0 {
+		term.Writeln(color.YellowString("\nCommits:"))
+		for _, commit := range diff.Commits {
+			term.Printf("  %s %s (%s, %s)\n", shortHash(commit.Hash), commit.Subject, commit.Author, commit.Time.Format("2006-01-02"))
+		}
+	}
+	if len(diff.FilesChanged) > 0 {
+		term.Writeln(color.YellowString("\nChanged files:"))
+		for _, file := range diff.FilesChanged {
+			term.Printf("  %s\n", file)
+		}
+	}
+	if len(diff.DependencyChanges) > 0 {
+		term.Writeln(color.YellowString("\nDependency changes:"))
+		for _, file := range diff.DependencyChanges {
+			term.Printf("  %s\n", file)
+		}
+	}
+	for _, section := range []struct {
+		title   string
+		changes []versionChange
+	}{
+		{"Command versions", diff.CommandChanges},
+		{"Language requirements", diff.RequirementChanges},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		term.Writeln(color.YellowString("\n%s:", section.title))
+		for _, change := range section.changes {
+			switch {
+			case change.Old == "":
+				term.Printf("  %s: added %s\n", change.Name, change.New)
+			case change.New == "":
+				term.Printf("  %s: removed\n", change.Name)
+			default:
+				term.Printf("  %s: %s -> %s\n", change.Name, change.Old, change.New)
+			}
+		}
+	}
+	term.Printf("\nNothing was changed, run \"%s\" to apply the update.\n", color.BlueString("%s update %s", tools.Self(), diff.Package))
+}
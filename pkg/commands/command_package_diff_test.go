@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCmdPackageDiff(t *testing.T) {
+	installed := plumbing.NewHash("1111111111111111111111111111111111111111")
+	latest := plumbing.NewHash("2222222222222222222222222222222222222222")
+	master := plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), installed)
+	when := time.Date(2020, 3, 4, 10, 0, 0, 0, time.UTC)
+	changes := &git.Changes{
+		Commits:      2,
+		FilesChanged: []string{"README.md", "cli.json", "go.sum"},
+		Log: []git.CommitSummary{
+			{Hash: latest.String(), Subject: "Add zone command", Author: "Alice", Time: when},
+			{Hash: "3333333333333333333333333333333333333333", Subject: "Fix records", Author: "Bob", Time: when},
+		},
+	}
+	targetJSON := []byte(`{"requirements": {"go": "1.16"}, "commands": [{"name": "dns", "version": "1.1.0"}, {"name": "dns-zone", "version": "1.0.0"}]}`)
+
+	tests := map[string]struct {
+		args      []string
+		source    bool
+		init      func(*mocked)
+		expected  string
+		withError string
+	}{
+		"latest commit of the branch": {
+			args: []string{"dns"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(latest, nil).Once()
+				m.gitRepo.On("Changes", installed, latest).Return(changes, nil).Twice()
+				m.gitRepo.On("FileContents", latest, "cli.json").Return(targetJSON, nil).Once()
+			},
+			expected: fmt.Sprintf("Package %s: master@1111111 -> %s, 2 commit(s), 3 file(s) changed\n", color.BlueString("cli-dns"), color.CyanString("master@2222222")) +
+				color.YellowString("\nCommits:") + "\n" +
+				"  2222222 Add zone command (Alice, 2020-03-04)\n" +
+				"  3333333 Fix records (Bob, 2020-03-04)\n" +
+				color.YellowString("\nChanged files:") + "\n  README.md\n  cli.json\n  go.sum\n" +
+				color.YellowString("\nDependency changes:") + "\n  cli.json\n  go.sum\n" +
+				color.YellowString("\nCommand versions:") + "\n  dns: 1.0.0 -> 1.1.0\n  dns-zone: added 1.0.0\n" +
+				color.YellowString("\nLanguage requirements:") + "\n  go: 1.14 -> 1.16\n" +
+				fmt.Sprintf("\nNothing was changed, run \"%s\" to apply the update.\n", color.BlueString("commands.test update cli-dns")),
+		},
+		"given tag": {
+			args: []string{"--to", "v1.1.0", "--output", "json", "cli-dns"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0"}, nil).Once()
+				m.gitRepo.On("TagCommit", "v1.1.0").Return(latest, nil).Once()
+				m.gitRepo.On("Changes", installed, latest).Return(&git.Changes{Commits: 1, FilesChanged: []string{"main.go"}, Log: changes.Log[:1]}, nil).Once()
+				m.gitRepo.On("FileContents", latest, "cli.json").Return(nil, errors.New("file not found")).Once()
+			},
+			expected: `{
+  "package": "cli-dns",
+  "from": "1111111",
+  "to": "v1.1.0",
+  "fromCommit": "1111111111111111111111111111111111111111",
+  "toCommit": "2222222222222222222222222222222222222222",
+  "commits": [
+    {
+      "hash": "2222222222222222222222222222222222222222",
+      "subject": "Add zone command",
+      "author": "Alice",
+      "time": "2020-03-04T10:00:00Z"
+    }
+  ],
+  "filesChanged": [
+    "main.go"
+  ],
+  "dependencyChanges": []
+}
+`,
+		},
+		"up-to-date": {
+			args: []string{"dns"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "master").Return(installed, nil).Once()
+				m.gitRepo.On("Changes", installed, installed).Return(&git.Changes{FilesChanged: []string{}}, nil).Once()
+				m.gitRepo.On("FileContents", installed, "cli.json").Return([]byte(`{"requirements": {"go": "1.14"}, "commands": [{"name": "dns", "version": "1.0.0"}]}`), nil).Once()
+			},
+			expected: fmt.Sprintf("Package %s is already at %s.\n", color.BlueString("cli-dns"), color.CyanString("master@1111111")),
+		},
+		"unknown target": {
+			args: []string{"--to", "missing", "dns"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Head").Return(master, nil)
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0"}, nil).Once()
+				m.gitRepo.On("TagCommit", "missing").Return(plumbing.ZeroHash, errors.New("tag missing not found")).Once()
+				m.gitRepo.On("FetchBranch", git.DefaultRemoteName, "missing").Return(plumbing.ZeroHash, errors.New("branch missing not found")).Once()
+			},
+			withError: `Unable to compare package "cli-dns": no tag, branch or commit "missing" found`,
+		},
+		"tarball package": {
+			args:      []string{"dns"},
+			source:    true,
+			withError: `Unable to compare package "cli-dns": checked only by "commands.test update" for packages of the tarball source`,
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			withError: `Package "cli-missing" not found.`,
+		},
+		"no package": {
+			withError: "You must specify a package or command name",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"requirements": {"go": "1.14"}, "commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			if test.source {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, sourceRecordFile), []byte(`{"source": "tarball", "url": "https://example.com/cli-dns.tar.gz", "revision": "abcdef0123"}`), 0644))
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			m.gitRepo.On("Open", dir).Return(nil).Maybe()
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			out := &bytes.Buffer{}
+			m.term.On("Printf", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				fmt.Fprintf(out, args.String(0), args.Get(1).([]interface{})...)
+			}).Return().Maybe()
+			m.term.On("Writeln", mock.Anything).Run(func(args mock.Arguments) {
+				fmt.Fprintln(out, args.Get(0).([]interface{})...)
+			}).Return(0, nil).Maybe()
+			if test.init != nil {
+				test.init(m)
+			}
+			app, ctx := setupTestApp(&cli.Command{
+				Name:   "diff",
+				Action: cmdPackageDiff(m.gitRepo, m.langManager),
+				Flags:  []cli.Flag{&cli.StringFlag{Name: "to"}, outputFlag()},
+			}, m)
+
+			err := app.RunContext(ctx, append(append(os.Args[0:1], "diff"), test.args...))
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestIsCommitHash(t *testing.T) {
+	assert.True(t, isCommitHash("0123456789abcdef0123456789abcdef01234567"))
+	assert.True(t, isCommitHash("0123456789ABCDEF0123456789ABCDEF01234567"))
+	assert.False(t, isCommitHash("0123456"))
+	assert.False(t, isCommitHash("main"))
+	assert.False(t, isCommitHash("0123456789abcdef0123456789abcdef0123456z"))
+}
@@ -0,0 +1,518 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCmdPackageStatus(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked, string)
+		withError string
+	}{
+		"no changes": {
+			args: []string{"cli-status"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				saveInstallRecord(context.Background(), dir, plumbing.Hash{1}.String())
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+				m.term.On("Writeln", []interface{}{color.GreenString("No changes since the package was installed.")}).Return(0, nil).Once()
+			},
+		},
+		"binary modified and commit changed": {
+			args: []string{"cli-status"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				saveInstallRecord(context.Background(), dir, plumbing.Hash{1}.String())
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-status"), []byte("changed"), 0755))
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{2}), nil).Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("\nChanges since the package was installed:")}).Return(0, nil).Once()
+				m.term.On("Printf", "  %s: %s -> %s\n", []interface{}{driftCommit, shortHash(plumbing.Hash{1}.String()), shortHash(plumbing.Hash{2}.String())}).Return().Once()
+				m.term.On("Printf", "  %s: %s\n", []interface{}{"bin/akamai-status", driftModified}).Return().Once()
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+			},
+			withError: `Package "cli-status" has changed since it was installed`,
+		},
+		"json output": {
+			args: []string{"--output", "json", "cli-status"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				saveInstallRecord(context.Background(), dir, "")
+				m.gitRepo.On("Open", dir).Return(fmt.Errorf("not a repository")).Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && assert.Contains(t, args[0], `"package": "cli-status"`) && assert.Contains(t, args[0], `"drift": []`)
+				})).Return(0, nil).Once()
+			},
+		},
+		"no install record": {
+			args:      []string{"cli-status"},
+			init:      func(t *testing.T, m *mocked, dir string) {},
+			withError: `No install record found for "cli-status"`,
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			init:      func(t *testing.T, m *mocked, dir string) {},
+			withError: `Package "cli-missing" not found`,
+		},
+		"no args": {
+			init:      func(t *testing.T, m *mocked, dir string) {},
+			withError: "You must specify a package or command name",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome, err := ioutil.TempDir("", "cli-home")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-status")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(`{"commands": [{"name": "status"}]}`), 0644))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-status"), []byte("binary"), 0755))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name: "package",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "status",
+						Action: cmdPackageStatus(m.gitRepo, m.langManager),
+						Flags:  []cli.Flag{outputFlag()},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "package", "status")
+			args = append(args, test.args...)
+
+			test.init(t, m, dir)
+			err = app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdPackageSetRemote(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked, string)
+		withError string
+	}{
+		"point to a fork": {
+			args: []string{"cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/akamai/cli-dns.git", nil).Once()
+				m.gitRepo.On("RemoteURL", upstreamRemoteName).Return("", fmt.Errorf("remote not found")).Once()
+				m.gitRepo.On("AddRemote", upstreamRemoteName, "https://github.com/akamai/cli-dns.git").Return(nil).Once()
+				m.gitRepo.On("SetRemoteURL", git.DefaultRemoteName, "https://github.com/me/cli-dns.git").Return(nil).Once()
+				m.term.On("Printf", "Package %s now updates from %s.\n", []interface{}{color.BlueString("cli-dns"), "https://github.com/me/cli-dns.git"}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" to fetch its changes.\n", mock.Anything).Return().Once()
+			},
+		},
+		"upstream remote already kept": {
+			args: []string{"cli-dns", "https://github.com/other/cli-dns.git"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/me/cli-dns.git", nil).Once()
+				m.gitRepo.On("RemoteURL", upstreamRemoteName).Return("https://github.com/akamai/cli-dns.git", nil).Once()
+				m.gitRepo.On("SetRemoteURL", git.DefaultRemoteName, "https://github.com/other/cli-dns.git").Return(nil).Once()
+				m.term.On("Printf", mock.AnythingOfType("string"), mock.Anything).Return()
+			},
+		},
+		"check out a branch": {
+			args: []string{"--branch", "fix-records", "cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+				m.gitRepo.On("Worktree").Return(packageWorktree(t, dir, false), nil).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/me/cli-dns.git", nil).Once()
+				m.gitRepo.On("SetRemoteURL", git.DefaultRemoteName, "https://github.com/me/cli-dns.git").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Twice()
+				m.cfg.On("GetValue", "cli", cloneTimeoutKey).Return("", false).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "fix-records").Return(nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking out branch %s of %s ...", []interface{}{"fix-records", "https://github.com/me/cli-dns.git"}).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "Package %s now updates from branch %s of %s.\n", []interface{}{color.BlueString("cli-dns"), "fix-records", "https://github.com/me/cli-dns.git"}).Return().Once()
+			},
+		},
+		"branch not found": {
+			args: []string{"--branch", "missing", "cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+				m.gitRepo.On("Worktree").Return(packageWorktree(t, dir, false), nil).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/me/cli-dns.git", nil).Once()
+				m.gitRepo.On("SetRemoteURL", git.DefaultRemoteName, "https://github.com/me/cli-dns.git").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", cloneTimeoutKey).Return("", false).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "missing").Return(fmt.Errorf("branch missing not found on remote origin")).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking out branch %s of %s ...", []interface{}{"missing", "https://github.com/me/cli-dns.git"}).Return().Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "Unable to check out branch missing",
+		},
+		"remote not published by a verified publisher": {
+			args: []string{"cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("true", true).Once()
+				m.cfg.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			withError: "Package https://github.com/me/cli-dns.git is not published by a verified publisher",
+		},
+		"branch over uncommitted changes": {
+			args: []string{"--branch", "fix-records", "cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+				m.gitRepo.On("Worktree").Return(packageWorktree(t, dir, true), nil).Once()
+			},
+			withError: "Unable to check out branch fix-records, package cli-dns has uncommitted changes",
+		},
+		"not a git repository": {
+			args: []string{"cli-dns", "me/cli-dns"},
+			init: func(t *testing.T, m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(fmt.Errorf("repository does not exist")).Once()
+			},
+			withError: `Package "cli-dns" was not installed from a git repository`,
+		},
+		"missing url": {
+			args:      []string{"cli-dns"},
+			init:      func(t *testing.T, m *mocked, dir string) {},
+			withError: "You must specify a package or command name and a repository URL",
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"packages": [{"name": "cli-dns", "url": "https://github.com/akamai/cli-dns", "verified": true}]}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+	defer func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+	}()
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome, err := ioutil.TempDir("", "cli-home")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-dns")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(`{"commands": [{"name": "dns"}]}`), 0644))
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name: "package",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "set-remote",
+						Action: cmdPackageSetRemote(m.gitRepo, m.langManager),
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "branch"}},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			m.cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", download.ProxyKey).Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", buildContainerKey).Return("", false).Maybe()
+			args := os.Args[0:1]
+			args = append(args, "package", "set-remote")
+			args = append(args, test.args...)
+
+			test.init(t, m, dir)
+			err = app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// packageWorktree commits cli.json of the package in dir to a new repository, modifying it afterwards if dirty is set
+func packageWorktree(t *testing.T, dir string, dirty bool) *gogit.Worktree {
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("cli.json")
+	require.NoError(t, err)
+	_, err = worktree.Commit("init", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	if dirty {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(`{"commands": [{"name": "dns", "version": "2.0.0"}]}`), 0644))
+	}
+	return worktree
+}
+
+func TestCmdPackageChannel(t *testing.T) {
+	tests := map[string]struct {
+		args            []string
+		channel         string
+		init            func(*mocked, string)
+		expectedChannel string
+		expectedBranch  string
+		withError       string
+	}{
+		"show default channel": {
+			args: []string{"dns"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s follows the %s channel.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("branch")}).Return().Once()
+			},
+		},
+		"follow stable tags": {
+			args: []string{"dns", "stable"},
+			init: func(m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Printf", "Package %s now follows the %s channel.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("stable")}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" to apply it.\n", mock.Anything).Return().Once()
+			},
+			expectedChannel: "stable",
+			expectedBranch:  "main",
+		},
+		"back to branch": {
+			args:    []string{"cli-dns", "Branch"},
+			channel: "prerelease",
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s now follows the %s channel.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("branch")}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" to apply it.\n", mock.Anything).Return().Once()
+			},
+		},
+		"already on channel": {
+			args:    []string{"dns", "prerelease"},
+			channel: "prerelease",
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s already follows the %s channel.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("prerelease")}).Return().Once()
+			},
+			expectedChannel: "prerelease",
+		},
+		"unknown channel": {
+			args:      []string{"dns", "nightly"},
+			init:      func(m *mocked, dir string) {},
+			withError: `Unknown channel "nightly", use one of: branch, stable, prerelease`,
+		},
+		"unknown package": {
+			args:      []string{"cli-missing", "stable"},
+			init:      func(m *mocked, dir string) {},
+			withError: `Package "cli-missing" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "akamai-dns"), []byte("#!/bin/sh"), 0755))
+			if test.channel != "" {
+				store, err := loadPackageStore(context.Background())
+				require.NoError(t, err)
+				require.NoError(t, store.record(packageEventInstall, dir, time.Now()))
+				store.Packages["cli-dns"].Channel = test.channel
+				require.NoError(t, store.save())
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			app, ctx := setupTestApp(&cli.Command{Name: "channel", Action: cmdPackageChannel(m.gitRepo, m.langManager)}, m)
+			test.init(m, dir)
+
+			err := app.RunContext(ctx, append(os.Args[0:1], append([]string{"channel"}, test.args...)...))
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			pkg := packageUpdateChannels()["cli-dns"]
+			if test.expectedChannel == "" && test.expectedBranch == "" {
+				assert.Nil(t, pkg)
+				return
+			}
+			require.NotNil(t, pkg)
+			assert.Equal(t, test.expectedChannel, pkg.Channel)
+			assert.Equal(t, test.expectedBranch, pkg.Branch)
+		})
+	}
+}
+
+func TestChannelSetting(t *testing.T) {
+	tests := map[string]struct {
+		args            []string
+		init            func(*mocked, string)
+		expectedChannel string
+		expectedBranch  string
+		withError       string
+	}{
+		"follow a branch": {
+			args: []string{"dns.channel", "beta"},
+			init: func(m *mocked, dir string) {
+				m.term.On("Printf", "Package %s now follows branch %s.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("beta")}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" to apply it.\n", mock.Anything).Return().Once()
+			},
+			expectedBranch: "beta",
+		},
+		"follow stable tags": {
+			args: []string{"cli-dns.channel", "Stable"},
+			init: func(m *mocked, dir string) {
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{1}), nil).Once()
+				m.term.On("Printf", "Package %s now follows the %s channel.\n", []interface{}{color.BlueString("cli-dns"), color.CyanString("stable")}).Return().Once()
+				m.term.On("Printf", "Run \"%s\" to apply it.\n", mock.Anything).Return().Once()
+			},
+			expectedChannel: "stable",
+			expectedBranch:  "main",
+		},
+		"invalid branch name": {
+			args:      []string{"dns.channel", "beta..1"},
+			init:      func(m *mocked, dir string) {},
+			withError: `Unable to set config value: invalid branch name "beta..1"`,
+		},
+		"not a package": {
+			args: []string{"other.channel", "beta"},
+			init: func(m *mocked, dir string) {
+				m.cfg.On("SetValue", "other", "channel", "beta").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, store.record(packageEventInstall, dir, time.Now()))
+			require.NoError(t, store.save())
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:        "config",
+				Subcommands: []*cli.Command{{Name: "set", Action: cmdConfigSet(m.gitRepo)}},
+			}
+			app, ctx := setupTestApp(command, m)
+			test.init(m, dir)
+
+			err = app.RunContext(ctx, append(os.Args[0:1], append([]string{"config", "set"}, test.args...)...))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			pkg := packageUpdateChannels()["cli-dns"]
+			if test.expectedChannel == "" && test.expectedBranch == "" {
+				assert.Nil(t, pkg)
+				return
+			}
+			require.NotNil(t, pkg)
+			assert.Equal(t, test.expectedChannel, pkg.Channel)
+			assert.Equal(t, test.expectedBranch, pkg.Branch)
+		})
+	}
+}
+
+func TestLatestChannelTag(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.2.0", "v1.10.0-beta.1", "1.3.0", "latest", "v0.9.0"}
+	tests := map[string]struct {
+		tags     []string
+		channel  string
+		expected string
+		ok       bool
+	}{
+		"stable": {
+			tags:     tags,
+			channel:  channelStable,
+			expected: "1.3.0",
+			ok:       true,
+		},
+		"prerelease": {
+			tags:     tags,
+			channel:  channelPrerelease,
+			expected: "v1.10.0-beta.1",
+			ok:       true,
+		},
+		"only pre-releases": {
+			tags:    []string{"v2.0.0-rc.1"},
+			channel: channelStable,
+		},
+		"no tags": {
+			channel: channelPrerelease,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, ok := latestChannelTag(test.tags, test.channel)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, tag)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// purgeItem is a piece of cached data or credentials found by purge-cache-creds
+type purgeItem struct {
+	description string
+	remove      func() error
+}
+
+func cmdPurgeCacheCreds(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("PURGE START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("PURGE FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("PURGE ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	items, err := findPurgeItems(c.Context)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to find data to remove: %s", err), 1)
+	}
+	if len(items) == 0 {
+		term.Writeln("Nothing to remove.")
+		term.Writeln("Akamai CLI stores no other credentials in the system keychain, .edgerc files are left untouched.")
+		return nil
+	}
+
+	term.Writeln("The following will be removed:")
+	for _, item := range items {
+		term.Printf("  - %s\n", item.description)
+	}
+	if !c.Bool("yes") {
+		if !term.IsTTY() {
+			return cli.Exit(color.RedString("Confirmation required to remove cached data and credentials, use --yes to remove them without asking"), 1)
+		}
+		answer, err := term.Confirm("Remove them?", false)
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		if !answer {
+			term.Writeln("Purge cancelled")
+			return nil
+		}
+	}
+
+	var failed []string
+	for _, item := range items {
+		if err := item.remove(); err != nil {
+			logger.Errorf("Unable to remove %s: %s", item.description, err)
+			failed = append(failed, fmt.Sprintf("%s (%s)", item.description, err))
+			continue
+		}
+		term.Printf("Removed %s\n", item.description)
+	}
+	if err := config.Get(c.Context).Save(c.Context); err != nil {
+		failed = append(failed, fmt.Sprintf("config changes (%s)", err))
+	}
+	term.Writeln("Akamai CLI stores no other credentials in the system keychain, .edgerc files are left untouched.")
+	if len(failed) > 0 {
+		return cli.Exit(color.RedString("Unable to remove: %s", strings.Join(failed, ", ")), 1)
+	}
+	return nil
+}
+
+// findPurgeItems lists the cache and log directories, the statistics history and identity, and the config settings
+// holding credentials, the GitHub token stored by "auth github", in the order they are removed
+func findPurgeItems(ctx context.Context) ([]purgeItem, error) {
+	cfg := config.Get(ctx)
+	var items []purgeItem
+
+	cache, err := cacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return nil, err
+	}
+	history, err := stats.HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range []struct{ description, path string }{
+		{"response cache", cache},
+		{"package logs", filepath.Join(cliPath, "logs")},
+		{"statistics history", history},
+	} {
+		path := path
+		if _, err := os.Stat(path.path); err != nil {
+			continue
+		}
+		items = append(items, purgeItem{
+			description: fmt.Sprintf("%s at %s", path.description, path.path),
+			remove:      func() error { return os.RemoveAll(path.path) },
+		})
+	}
+
+	values := cfg.Values()
+	sections := make([]string, 0, len(values))
+	for section := range values {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		keys := make([]string, 0, len(values[section]))
+		for key := range values[section] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			val := values[section][key]
+			envName := strings.ToUpper(strings.ReplaceAll(section+"_"+key, "-", "_"))
+			if val == "" || maskEnvValue(envName, val) == val {
+				continue
+			}
+			section, key := section, key
+			items = append(items, purgeItem{
+				description: fmt.Sprintf("setting %s.%s", section, key),
+				remove: func() error {
+					cfg.UnsetValue(section, key)
+					return nil
+				},
+			})
+		}
+	}
+
+	if user := values["cli"][githubUserKey]; user != "" {
+		items = append(items, purgeItem{
+			description: fmt.Sprintf("GitHub token of %s in the system keychain", user),
+			remove:      func() error { return removeGitHubToken(ctx) },
+		})
+	}
+
+	if id, ok := cfg.GetValue("cli", "client-id"); ok && id != "" {
+		items = append(items, purgeItem{
+			description: "statistics client ID and consent",
+			remove:      func() error { return stats.ResetIdentity(ctx, true) },
+		})
+	}
+	return items, nil
+}
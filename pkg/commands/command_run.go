@@ -0,0 +1,107 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdRun(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("RUN START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("RUN FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("RUN ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a command to run"), 1)
+		}
+
+		dir := c.String("chdir")
+		if dir != "" {
+			stat, err := os.Stat(dir)
+			if err != nil || !stat.IsDir() {
+				return cli.Exit(color.RedString("Working directory does not exist: %s", dir), 1)
+			}
+		}
+
+		if c.Bool("no-workspace") {
+			if err := os.Setenv(noWorkspaceEnv, "true"); err != nil {
+				return err
+			}
+		}
+
+		for _, envFile := range c.StringSlice("env-file") {
+			env, err := readEnvFile(envFile)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read env file: %s", err), 1)
+			}
+			for _, entry := range env {
+				if err := os.Setenv(entry[0], entry[1]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return runPackageCommand(c, gitRepo, langManager, c.Args().First(), c.Args().Tail(), dir)
+	}
+}
+
+// readEnvFile parses a file with KEY=VALUE lines, returning key and value pairs in the order they appear.
+// Empty lines and lines starting with # are skipped, "export " prefix and quotes around values are removed.
+func readEnvFile(path string) ([][2]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := make([][2]string, 0)
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNum)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, [2]string{key, value})
+	}
+	return env, scanner.Err()
+}
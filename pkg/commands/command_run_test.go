@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCmdRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires executable scripts")
+	}
+	cliHome, err := ioutil.TempDir("", "cli-home")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+	}()
+	binPath := filepath.Join(cliHome, ".akamai-cli", "src", "cli-pwd", "bin")
+	require.NoError(t, os.MkdirAll(binPath, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binPath, "..", "cli.json"), []byte(`{"commands": [{"name": "pwd"}]}`), 0644))
+	outFile := filepath.Join(cliHome, "out")
+	script := "#!/bin/sh\necho \"$(pwd) $RUN_TEST_VAR $*\" > " + outFile + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binPath, "akamai-pwd"), []byte(script), 0755))
+	workDir := filepath.Join(cliHome, "project")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	envFile := filepath.Join(cliHome, ".env")
+	require.NoError(t, ioutil.WriteFile(envFile, []byte("# test\nexport RUN_TEST_VAR=\"from file\"\n"), 0644))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+		require.NoError(t, os.Unsetenv("RUN_TEST_VAR"))
+	}()
+
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		expected  string
+		withLog   bool
+		withError string
+	}{
+		"run command in directory with env file": {
+			args: []string{"--chdir", workDir, "--env-file", envFile, "pwd", "abc"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+			},
+			expected: workDir + " from file abc",
+		},
+		"run command with package logs": {
+			args: []string{"--chdir", workDir, "--env-file", envFile, "pwd"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("true", true)
+			},
+			expected: workDir + " from file",
+			withLog:  true,
+		},
+		"no command": {
+			args:      []string{"--chdir", workDir},
+			init:      func(m *mocked) {},
+			withError: "You must specify a command to run",
+		},
+		"directory does not exist": {
+			args:      []string{"--chdir", filepath.Join(cliHome, "missing"), "pwd"},
+			init:      func(m *mocked) {},
+			withError: "Working directory does not exist",
+		},
+		"env file does not exist": {
+			args:      []string{"--env-file", filepath.Join(cliHome, "missing"), "pwd"},
+			init:      func(m *mocked) {},
+			withError: "Unable to read env file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			command := &cli.Command{
+				Name:   "run",
+				Action: cmdRun(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "chdir"},
+					&cli.StringSliceFlag{Name: "env-file"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "run")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			out, err := ioutil.ReadFile(outFile)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(string(out)))
+			if test.withLog {
+				logs, err := ioutil.ReadFile(filepath.Join(cliHome, ".akamai-cli", "logs", "cli-pwd", "cli.log"))
+				require.NoError(t, err)
+				assert.Contains(t, string(logs), "Dispatching command")
+				assert.Contains(t, string(logs), "dir="+workDir)
+				assert.Contains(t, string(logs), "exit-code=0")
+			}
+		})
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	tests := map[string]struct {
+		contents  string
+		expected  [][2]string
+		withError string
+	}{
+		"variables with comments, quotes and export": {
+			contents: "# comment\n\nA=1\nexport B = \"two words\"\nC='x=y'\nD=\n",
+			expected: [][2]string{{"A", "1"}, {"B", "two words"}, {"C", "x=y"}, {"D", ""}},
+		},
+		"invalid line": {
+			contents:  "A=1\nINVALID\n",
+			withError: ":2: expected KEY=VALUE",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "env")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.Remove(f.Name()))
+			}()
+			_, err = f.WriteString(test.contents)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			env, err := readEnvFile(f.Name())
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, env)
+		})
+	}
+}
@@ -26,6 +26,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
 	"github.com/akamai/cli/pkg/terminal"
 
 	"github.com/fatih/color"
@@ -45,6 +50,7 @@ type packageListPackage struct {
 	Version      string    `json:"version"`
 	URL          string    `json:"url"`
 	Issues       string    `json:"issues"`
+	Verified     bool      `json:"verified"`
 	Commands     []command `json:"commands"`
 	Requirements struct {
 		Go     string `json:"go"`
@@ -55,47 +61,165 @@ type packageListPackage struct {
 	} `json:"requirements"`
 }
 
-func cmdSearch(c *cli.Context) (e error) {
-	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-	start := time.Now()
-	logger := log.WithCommand(c.Context, c.Command.Name)
-	logger.Debug("SEARCH START")
-	defer func() {
-		if e == nil {
-			logger.Debugf("SEARCH FINISHED: %v", time.Now().Sub(start))
-		} else {
-			logger.Errorf("SEARCH ERROR: %v", e.Error())
+func cmdSearch(git git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("SEARCH START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("SEARCH FINISHED: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("SEARCH ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify one or more keywords"), 1)
 		}
-	}()
-	if !c.Args().Present() {
-		return cli.Exit(color.RedString("You must specify one or more keywords"), 1)
+
+		format, err := outputFormat(c)
+		if err != nil {
+			return err
+		}
+		if format == formatPlain && c.Bool("install") {
+			return cli.Exit(color.RedString("--install cannot be combined with --plain"), 1)
+		}
+		if format != plugin.FormatTable && c.Bool("install") {
+			return cli.Exit(color.RedString("--install cannot be combined with --output %s", format), 1)
+		}
+
+		packageList, err := fetchSearchIndex(c.Context)
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+
+		if format != plugin.FormatTable {
+			found := matchPackages(c.Args().Slice(), packageList)
+			return render(c, format, renderedOutput{
+				Value:   found,
+				Columns: []string{"package", "command", "aliases", "version", "verified", "description"},
+				Rows:    searchRows(found),
+			})
+		}
+
+		found, err := searchPackages(c.Context, c.Args().Slice(), packageList, c.Bool("no-trunc"))
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+
+		if c.Bool("install") && len(found) > 0 {
+			return installSearchResults(c, git, langManager, found)
+		}
+
+		return nil
 	}
+}
 
-	packageList, err := fetchPackageList(c.Context)
-	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+// searchRows returns matching commands as rows of CSV and --plain output: package, command, aliases, version, verified and description.
+// Packages matched only by name or title have a single row with empty command fields.
+func searchRows(found []packageListPackage) [][]string {
+	rows := make([][]string, 0, len(found))
+	for _, pkg := range found {
+		verified := ""
+		if pkg.Verified {
+			verified = "verified"
+		}
+		if len(pkg.Commands) == 0 {
+			rows = append(rows, []string{pkg.Name, "", "", "", verified, ""})
+		}
+		for _, cmd := range pkg.Commands {
+			rows = append(rows, []string{pkg.Name, cmd.Name, strings.Join(cmd.Aliases, ","), cmd.Version, verified, cmd.Description})
+		}
+	}
+	return rows
+}
+
+// installSearchResults prompts the user to pick packages from search results and installs the selected ones
+func installSearchResults(c *cli.Context, git git.Repository, langManager packages.LangManager, found []packageListPackage) error {
+	term := terminal.Get(c.Context)
+	if !term.IsTTY() {
+		return cli.Exit(color.RedString("Interactive install is only available in a terminal, use \"%s install [package]\" instead", tools.Self()), 1)
+	}
+
+	options := make([]string, 0, len(found))
+	repos := make(map[string]string, len(found))
+	for _, pkg := range found {
+		option := fmt.Sprintf("%s [%s]", pkg.Title, pkg.Name)
+		options = append(options, option)
+		repos[option] = pkg.Name
+		if pkg.URL != "" {
+			repos[option] = pkg.URL
+		}
 	}
 
-	err = searchPackages(c.Context, c.Args().Slice(), packageList)
+	selected, err := term.MultiSelect("Select packages to install:", options...)
 	if err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	return nil
+	toInstall := make([]string, 0, len(selected))
+	for _, option := range selected {
+		toInstall = append(toInstall, repos[option])
+	}
+	if len(toInstall) == 0 {
+		return nil
+	}
+
+	_, err = installPackages(c, git, langManager, toInstall)
+	return err
 }
 
-func fetchPackageList(ctx context.Context) (*packageList, error) {
+// fetchCachedPackageList returns the package list stored in CLI cache directory, refreshing it once a day
+func fetchCachedPackageList(ctx context.Context) (*packageList, error) {
 	logger := log.FromContext(ctx)
+	cachePath, ok := config.Get(ctx).GetValue("cli", cachePathKey)
+	if !ok || cachePath == "" {
+		return fetchPackageList(ctx)
+	}
+
+	if data, ok := readCache(ctx, cachePath, "package-list.json", sleepTime24Hours); ok {
+		result := &packageList{}
+		if err := json.Unmarshal(data, result); err == nil {
+			return result, nil
+		}
+	}
+
+	result, err := fetchPackageList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCache(cachePath, "package-list.json", data); err != nil {
+		logger.Debugf("Unable to cache package list: %s", err)
+	}
+	return result, nil
+}
+
+func fetchPackageList(ctx context.Context) (*packageList, error) {
 	var repo string
 	repo = "https://developer.akamai.com"
 	if customRepo := os.Getenv("AKAMAI_CLI_PACKAGE_REPO"); customRepo != "" {
 		repo = customRepo
 	}
-	repo = fmt.Sprintf("%s/cli/package-list.json", repo)
-	resp, err := http.Get(repo)
+	result, err := fetchPackageIndex(ctx, fmt.Sprintf("%s/cli/package-list.json", repo))
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
 	}
+	return result, nil
+}
+
+// fetchPackageIndex fetches a list of packages in the format of the package list from given URL
+func fetchPackageIndex(ctx context.Context, url string) (*packageList, error) {
+	logger := log.FromContext(ctx)
+	client := &http.Client{Transport: download.LimitTransport(ctx, nil)}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -103,25 +227,28 @@ func fetchPackageList(ctx context.Context) (*packageList, error) {
 		}
 	}()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
 	result := &packageList{}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+		return nil, err
 	}
 
-	err = json.Unmarshal(body, result)
-	if err != nil {
-		return nil, fmt.Errorf("unable to fetch remote Package List (%s)", err.Error())
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
-func searchPackages(ctx context.Context, keywords []string, packageList *packageList) error {
+// matchPackages returns packages matching given keywords, best matches first.
+// Commands of returned packages are limited to the ones matching any keyword.
+func matchPackages(keywords []string, packageList *packageList) []packageListPackage {
 	results := make(map[int]map[string]packageListPackage)
 
-	term := terminal.Get(ctx)
-
 	var hits int
 	for key, pkg := range packageList.Packages {
 		hits = 0
@@ -185,34 +312,48 @@ func searchPackages(ctx context.Context, keywords []string, packageList *package
 
 	sort.Sort(sort.Reverse(sort.IntSlice(resultHits)))
 	sort.Strings(resultPkgs)
-	bold := color.New(color.FgWhite, color.Bold)
-
-	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(resultPkgs))
 
+	found := make([]packageListPackage, 0, len(resultPkgs))
 	for _, hits := range resultHits {
 		for _, pkgName := range resultPkgs {
-			if _, ok := results[hits][pkgName]; ok {
-				pkg := results[hits][pkgName]
-				term.Printf(color.GreenString("Package: ")+"%s [%s]\n", pkg.Title, color.BlueString(pkg.Name))
-				for _, cmd := range results[hits][pkgName].Commands {
-					var aliases string
-					if len(cmd.Aliases) == 1 {
-						aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
-					} else if len(cmd.Aliases) > 1 {
-						aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
-					}
+			if pkg, ok := results[hits][pkgName]; ok {
+				found = append(found, pkg)
+			}
+		}
+	}
+	return found
+}
 
-					term.Printf(bold.Sprintf("  Command:")+" %s %s\n", cmd.Name, aliases)
-					term.Printf(bold.Sprintf("  Version:")+" %s\n", cmd.Version)
-					term.Printf(bold.Sprintf("  Description:")+" %s\n\n", cmd.Description)
-				}
+func searchPackages(ctx context.Context, keywords []string, packageList *packageList, noTrunc bool) ([]packageListPackage, error) {
+	term := terminal.Get(ctx)
+	found := matchPackages(keywords, packageList)
+	bold := color.New(color.FgWhite, color.Bold)
+
+	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(found))
+
+	for _, pkg := range found {
+		if pkg.Verified {
+			term.Printf(color.GreenString("Package: ")+"%s [%s] %s\n", pkg.Title, color.BlueString(pkg.Name), verifiedBadge())
+		} else {
+			term.Printf(color.GreenString("Package: ")+"%s [%s]\n", pkg.Title, color.BlueString(pkg.Name))
+		}
+		for _, cmd := range pkg.Commands {
+			var aliases string
+			if len(cmd.Aliases) == 1 {
+				aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
+			} else if len(cmd.Aliases) > 1 {
+				aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
 			}
+
+			term.Printf(bold.Sprintf("  Command:")+" %s %s\n", cmd.Name, aliases)
+			term.Printf(bold.Sprintf("  Version:")+" %s\n", cmd.Version)
+			term.Printf(bold.Sprintf("  Description:")+" %s\n\n", fitToTerminal(cmd.Description, len("  Description: "), noTrunc))
 		}
 	}
 
-	if len(resultHits) > 0 {
+	if len(found) > 0 {
 		term.Printf("\nInstall using \"%s\".\n", color.BlueString("%s install [package]", tools.Self()))
 	}
 
-	return nil
+	return found, nil
 }
@@ -3,16 +3,20 @@ package commands
 import (
 	"fmt"
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -51,7 +55,7 @@ func TestCmdSearch(t *testing.T) {
 				m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"test for match on title"}).
 					Return().Once()
 
-				m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Some CLI", color.BlueString("cli-4")}).
+				m.On("Printf", color.GreenString("Package: ")+"%s [%s] %s\n", []interface{}{"Some CLI", color.BlueString("cli-4"), color.GreenString("[verified]")}).
 					Return().Once()
 				m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"test", ""}).
 					Return().Once()
@@ -73,6 +77,63 @@ func TestCmdSearch(t *testing.T) {
 					Return().Once()
 			},
 		},
+		"search and install, no package selected": {
+			args:         []string{"--install", "description"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				expectSearchDescriptionResult(m)
+				m.On("IsTTY").Return(true).Once()
+				m.On("MultiSelect", "Select packages to install:", []string{"Some CLI [cli-2]"}).Return([]string{}, nil).Once()
+			},
+		},
+		"search and install, not a terminal": {
+			args:         []string{"--install", "description"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				expectSearchDescriptionResult(m)
+				m.On("IsTTY").Return(false).Once()
+			},
+			withError: "Interactive install is only available in a terminal",
+		},
+		"search with yaml output": {
+			args:         []string{"--output", "yaml", "description"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "%s", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.HasPrefix(args[0].(string), "- commands:\n") &&
+						strings.Contains(args[0].(string), "\n      name: desc-cmd\n") &&
+						strings.Contains(args[0].(string), "\n  name: cli-2\n")
+				})).Return().Once()
+			},
+		},
+		"search with plain output": {
+			args:         []string{"--plain", "test"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{"cli-1\ttitle-cmd\t-\t1.0.0\t-\ttest for match on title"}).Return(0, nil).Once()
+				m.On("Writeln", []interface{}{"cli-2\tdesc-cmd\t-\t1.0.0\t-\ttest - match on description"}).Return(0, nil).Once()
+				m.On("Writeln", []interface{}{"cli-4\ttest\t-\t1.0.0\tverified\ttest for match on command name"}).Return(0, nil).Once()
+				m.On("Writeln", []interface{}{"test-cli\ttest-cmd\ttest,abc\t1.0.0\t-\ttest for highest score"}).Return(0, nil).Once()
+				m.On("Writeln", []interface{}{"test-no-cmd-match\t-\t-\t-\t-\t-"}).Return(0, nil).Once()
+			},
+		},
+		"search with csv output": {
+			args:         []string{"--output", "csv", "description"},
+			responseFile: "packages-response.json",
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "%s", []interface{}{"package,command,aliases,version,verified,description\ncli-2,desc-cmd,,1.0.0,,test - match on description\n"}).Return().Once()
+			},
+		},
+		"search and install with plain output": {
+			args:      []string{"--install", "--plain", "description"},
+			init:      func(m *terminal.Mock) {},
+			withError: "--install cannot be combined with --plain",
+		},
+		"search and install with json output": {
+			args:      []string{"--install", "--output", "json", "description"},
+			init:      func(m *terminal.Mock) {},
+			withError: "--install cannot be combined with --output json",
+		},
 		"no match": {
 			args:         []string{"abc123"},
 			responseFile: "packages-response.json",
@@ -106,10 +167,18 @@ func TestCmdSearch(t *testing.T) {
 		require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
 		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 		t.Run(name, func(t *testing.T) {
-			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Maybe()
 			command := &cli.Command{
 				Name:   "search",
-				Action: cmdSearch,
+				Action: cmdSearch(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "install",
+					},
+					plainFlag(),
+					outputFlag(),
+				},
 			}
 			app, ctx := setupTestApp(command, m)
 			args := os.Args[0:1]
@@ -130,3 +199,18 @@ func TestCmdSearch(t *testing.T) {
 		})
 	}
 }
+
+func expectSearchDescriptionResult(m *terminal.Mock) {
+	bold := color.New(color.FgWhite, color.Bold)
+	m.On("Printf", color.YellowString("Results Found:")+" %d\n\n", []interface{}{1}).Return().Once()
+	m.On("Printf", color.GreenString("Package: ")+"%s [%s]\n", []interface{}{"Some CLI", color.BlueString("cli-2")}).
+		Return().Once()
+	m.On("Printf", bold.Sprintf("  Command:")+" %s %s\n", []interface{}{"desc-cmd", ""}).
+		Return().Once()
+	m.On("Printf", bold.Sprintf("  Version:")+" %s\n", []interface{}{"1.0.0"}).
+		Return().Once()
+	m.On("Printf", bold.Sprintf("  Description:")+" %s\n\n", []interface{}{"test - match on description"}).
+		Return().Once()
+	m.On("Printf", "\nInstall using \"%s\".\n", []interface{}{color.BlueString("%s install [package]", tools.Self())}).
+		Return().Once()
+}
@@ -0,0 +1,193 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+func cmdStatsShow(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("STATS SHOW START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("STATS SHOW FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("STATS SHOW ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	id := stats.GetIdentity(c.Context)
+	clientID := id.ClientID
+	if clientID == "" {
+		clientID = "none"
+	}
+	consent := "not configured"
+	if id.Enabled != nil {
+		consent = "disabled"
+		if *id.Enabled {
+			consent = fmt.Sprintf("enabled (version %s)", id.StatsVersion)
+		}
+	}
+	term.Printf("Client ID:  %s\n", clientID)
+	term.Printf("Statistics: %s\n", consent)
+	return nil
+}
+
+func cmdStatsReset(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("STATS RESET START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("STATS RESET FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("STATS RESET ERROR: %v", e.Error())
+		}
+	}()
+
+	if err := stats.ResetIdentity(c.Context, c.Bool("consent")); err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to reset statistics identity: %s", err)), 1)
+	}
+	return nil
+}
+
+func cmdStatsExport(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("STATS EXPORT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("STATS EXPORT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("STATS EXPORT ERROR: %v", e.Error())
+		}
+	}()
+
+	id := stats.GetIdentity(c.Context)
+	if c.Bool("no-client-id") {
+		id.ClientID = ""
+	}
+	out, err := json.MarshalIndent(id, "", "  ")
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to export statistics identity: %s", err)), 1)
+	}
+	terminal.Get(c.Context).Writeln(string(out))
+	return nil
+}
+
+func cmdStatsImport(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("STATS IMPORT START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("STATS IMPORT FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("STATS IMPORT ERROR: %v", e.Error())
+		}
+	}()
+
+	if !c.Args().Present() {
+		return cli.Exit(color.RedString("You must specify a file to import, or \"-\" to read from standard input"), 1)
+	}
+	var data []byte
+	var err error
+	if file := c.Args().First(); file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to import statistics identity: %s", err)), 1)
+	}
+
+	var id stats.Identity
+	if err := json.Unmarshal(data, &id); err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to import statistics identity: %s", err)), 1)
+	}
+	if c.Bool("no-client-id") {
+		id.ClientID = ""
+	}
+	if err := stats.ImportIdentity(c.Context, id); err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to import statistics identity: %s", err)), 1)
+	}
+	return nil
+}
+
+func cmdStatsPreview(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("STATS PREVIEW START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("STATS PREVIEW FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("STATS PREVIEW ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	last := c.Int("last")
+	if last < 1 {
+		return cli.Exit(color.RedString("The number of commands to preview must be greater than 0"), 1)
+	}
+	payloads, err := stats.RecentPayloads(last)
+	if err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Unable to read statistics history: %s", err)), 1)
+	}
+	if format != plugin.FormatTable {
+		return render(c, format, renderedOutput{Value: payloads})
+	}
+
+	if len(payloads) == 0 {
+		term.Writeln("No statistics payloads were recorded.")
+		return nil
+	}
+	for i, p := range payloads {
+		if i == 0 || payloads[i-1].Invocation != p.Invocation {
+			status := ""
+			if p.DryRun {
+				status = color.YellowString(" (dry run, not sent)")
+			}
+			term.Printf("%s %s%s\n", p.Time.Format(time.RFC3339), color.BlueString(p.Command), status)
+		}
+		term.Printf("  %s\n", p.Body)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdStats(t *testing.T) {
+	importFile := filepath.Join(os.TempDir(), "akamai-cli-stats-import.json")
+	require.NoError(t, ioutil.WriteFile(importFile, []byte(`{"clientId":"f2b7c1a4-2f0e-4a58-9b0e-7c8a1d2e3f40","enabled":false}`), 0644))
+	defer func() {
+		require.NoError(t, os.Remove(importFile))
+	}()
+
+	history := `[
+  {"time": "2021-02-10T11:55:26Z", "invocation": "1", "command": "install", "body": "ea=install"},
+  {"time": "2021-02-10T11:56:26Z", "invocation": "2", "command": "property", "dryRun": true, "body": "ea=property"},
+  {"time": "2021-02-10T11:56:27Z", "invocation": "2", "command": "property", "dryRun": true, "body": "ea=ping"}
+]`
+	historyFile := filepath.Join("testdata", ".akamai-cli", "stats-history.json")
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+
+	tests := map[string]struct {
+		args      []string
+		history   string
+		init      func(*mocked)
+		withError string
+	}{
+		"show enabled stats": {
+			args: []string{"show"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("1.1", true).Once()
+				m.cfg.On("GetValue", "cli", "stats-version").Return("1.1", true).Once()
+				m.term.On("Printf", "Client ID:  %s\n", []interface{}{"123"}).Return().Once()
+				m.term.On("Printf", "Statistics: %s\n", []interface{}{"enabled (version 1.1)"}).Return().Once()
+			},
+		},
+		"show not configured stats": {
+			args: []string{"show"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "client-id").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("", false).Once()
+				m.term.On("Printf", "Client ID:  %s\n", []interface{}{"none"}).Return().Once()
+				m.term.On("Printf", "Statistics: %s\n", []interface{}{"not configured"}).Return().Once()
+			},
+		},
+		"export without client ID": {
+			args: []string{"export", "--no-client-id"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+				m.term.On("Writeln", []interface{}{"{\n  \"enabled\": false\n}"}).Return(0, nil).Once()
+			},
+		},
+		"import from file": {
+			args: []string{"import", importFile},
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", "cli", "client-id", "f2b7c1a4-2f0e-4a58-9b0e-7c8a1d2e3f40").Return().Once()
+				m.cfg.On("SetValue", "cli", "enable-cli-statistics", "false").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"preview last command": {
+			args:    []string{"preview", "--last", "1"},
+			history: history,
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s %s%s\n", []interface{}{"2021-02-10T11:56:26Z", color.BlueString("property"), color.YellowString(" (dry run, not sent)")}).Return().Once()
+				m.term.On("Printf", "  %s\n", []interface{}{"ea=property"}).Return().Once()
+				m.term.On("Printf", "  %s\n", []interface{}{"ea=ping"}).Return().Once()
+			},
+		},
+		"preview as json": {
+			args:    []string{"preview", "--last", "2", "--output", "json"},
+			history: history,
+			init: func(m *mocked) {
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.Count(args[0].(string), `"invocation"`) == 3
+				})).Return(0, nil).Once()
+			},
+		},
+		"preview without history": {
+			args: []string{"preview"},
+			init: func(m *mocked) {
+				m.term.On("Writeln", []interface{}{"No statistics payloads were recorded."}).Return(0, nil).Once()
+			},
+		},
+		"preview invalid number of commands": {
+			args:      []string{"preview", "--last", "0"},
+			init:      func(m *mocked) {},
+			withError: "The number of commands to preview must be greater than 0",
+		},
+		"import without file": {
+			args:      []string{"import"},
+			init:      func(m *mocked) {},
+			withError: "You must specify a file to import",
+		},
+		"import missing file": {
+			args:      []string{"import", "testdata/does-not-exist.json"},
+			init:      func(m *mocked) {},
+			withError: "Unable to import statistics identity",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := &cli.Command{
+				Name: "stats",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "show",
+						Action: cmdStatsShow,
+					},
+					{
+						Name:   "export",
+						Action: cmdStatsExport,
+						Flags:  []cli.Flag{&cli.BoolFlag{Name: "no-client-id"}},
+					},
+					{
+						Name:   "import",
+						Action: cmdStatsImport,
+						Flags:  []cli.Flag{&cli.BoolFlag{Name: "no-client-id"}},
+					},
+					{
+						Name:   "preview",
+						Action: cmdStatsPreview,
+						Flags:  []cli.Flag{&cli.IntFlag{Name: "last", Value: 10}, outputFlag()},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "stats")
+			args = append(args, test.args...)
+
+			if test.history != "" {
+				require.NoError(t, ioutil.WriteFile(historyFile, []byte(test.history), 0600))
+				defer func() {
+					require.NoError(t, os.Remove(historyFile))
+				}()
+			}
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -15,12 +15,14 @@
 package commands
 
 import (
+	"context"
 	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/packages"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/akamai/cli/pkg/stats"
 	"github.com/akamai/cli/pkg/terminal"
@@ -34,83 +36,207 @@ import (
 func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
-		logger := log.WithCommand(c.Context, c.Command.Name)
-		term := terminal.Get(c.Context)
+		return runPackageCommand(c, git, langManager, c.Command.Name, os.Args[2:], "")
+	}
+}
+
+// packageDispatch describes how the executable of an installed command is run
+type packageDispatch struct {
+	Command     string            `json:"command"`
+	Package     string            `json:"package"`
+	Version     string            `json:"version,omitempty"`
+	Interpreter string            `json:"interpreter,omitempty"`
+	Args        []string          `json:"args"`
+	Dir         string            `json:"dir,omitempty"`
+	Env         map[string]string `json:"env"`
+	Workspace   string            `json:"workspace,omitempty"`
+
+	packageDir       string
+	pkg              subcommands
+	exitCodes        map[string]string
+	workspaceApplied []string
+}
+
+// resolvePackageCommand finds the executable of an installed command and returns
+// the command line, working directory and environment variables it would be run with
+func resolvePackageCommand(ctx context.Context, langManager packages.LangManager, name string, args []string, dir string) (*packageDispatch, error) {
+	invokedName := strings.ToLower(name)
+	_, commandName := splitCommandName(invokedName)
 
-		commandName := strings.ToLower(c.Command.Name)
+	executable, err := findCommandExec(ctx, langManager, invokedName)
+	if err != nil {
+		return nil, cli.Exit(color.RedString("Executable \"%s\" not found.", invokedName), 1)
+	}
 
-		executable, err := findExec(c.Context, langManager, commandName)
-		if err != nil {
-			errMsg := color.RedString("Executable \"%s\" not found.", commandName)
-			logger.Error(errMsg)
-			return cli.Exit(errMsg, 1)
+	var packageDir string
+	if len(executable) == 1 {
+		packageDir = findPackageDir(executable[0])
+	} else if len(executable) > 1 {
+		packageDir = findPackageDir(executable[1])
+	}
+
+	cmdPackage, _ := readPackage(packageDir)
+
+	var currentCmd command
+	for _, cmd := range cmdPackage.Commands {
+		if strings.EqualFold(cmd.Name, commandName) {
+			currentCmd = cmd
+			break
 		}
 
-		var packageDir string
-		if len(executable) == 1 {
-			packageDir = findPackageDir(executable[0])
-		} else if len(executable) > 1 {
-			packageDir = findPackageDir(executable[1])
+		for _, alias := range cmd.Aliases {
+			if strings.EqualFold(alias, commandName) {
+				currentCmd = cmd
+			}
 		}
+	}
 
-		cmdPackage, _ := readPackage(packageDir)
+	d := &packageDispatch{
+		Command:    commandName,
+		Package:    filepath.Base(packageDir),
+		Version:    currentCmd.Version,
+		Dir:        dir,
+		packageDir: packageDir,
+		pkg:        cmdPackage,
+		exitCodes:  currentCmd.ExitCodes,
+		Env: map[string]string{
+			"AKAMAI_CLI_COMMAND":         commandName,
+			"AKAMAI_CLI_COMMAND_VERSION": currentCmd.Version,
+		},
+	}
+	if len(executable) > 1 {
+		d.Interpreter = executable[0]
+	}
+	if cmdPackage.Requirements.Python != "" {
+		d.Env["PYTHONUSERBASE"] = packageDir
+	}
 
-		if cmdPackage.Requirements.Python != "" {
-			var err error
-			if runtime.GOOS == "linux" {
-				_, err = os.Stat(filepath.Join(packageDir, ".local"))
-			} else if runtime.GOOS == "darwin" {
-				_, err = os.Stat(filepath.Join(packageDir, "Library"))
-			} else if runtime.GOOS == "windows" {
-				_, err = os.Stat(filepath.Join(packageDir, "Lib"))
-			}
+	if dir != "" {
+		if executable, err = absolutePaths(executable); err != nil {
+			return nil, err
+		}
+	}
+	d.Args = append(executable, args...)
+	if err := applyWorkspace(ctx, d); err != nil {
+		return nil, cli.Exit(color.RedString("Unable to read workspace: %s", err), 1)
+	}
+	return d, nil
+}
 
-			if err == nil {
-				answer, err := term.Confirm("Would you like to reinstall it", true)
-				logger.Debugf("Would you like to reinstall it? %v", answer)
-				if err != nil {
-					return err
-				}
-				if !answer {
-					logger.Error(packages.ErrPackageNeedsReinstall.Error())
-					return cli.Exit(color.RedString(packages.ErrPackageNeedsReinstall.Error()), -1)
-				}
-
-				if err = uninstallPackage(c.Context, langManager, commandName, logger); err != nil {
-					return err
-				}
-
-				if _, err = installPackage(c.Context, git, langManager, commandName, false); err != nil {
-					return err
-				}
-			}
-			if err := os.Setenv("PYTHONUSERBASE", packageDir); err != nil {
+// runPackageCommand runs the executable of an installed command with given arguments.
+// If dir is not empty, the command is executed in that directory.
+func runPackageCommand(c *cli.Context, git git.Repository, langManager packages.LangManager, name string, args []string, dir string) error {
+	logger := log.WithCommand(c.Context, name)
+	term := terminal.Get(c.Context)
+
+	// problems found in the files of the package are repaired if the user agrees, otherwise the command runs as it is
+	if _, err := repairBeforeDispatch(c, git, langManager, name, false); err != nil {
+		logger.Warn(err.Error())
+	}
+	d, err := resolvePackageCommand(c.Context, langManager, name, args, dir)
+	if err != nil {
+		// the executable or its interpreter is missing, the problems found are reported instead of a missing executable
+		repaired, repairErr := repairBeforeDispatch(c, git, langManager, name, true)
+		if repaired {
+			d, err = resolvePackageCommand(c.Context, langManager, name, args, dir)
+		} else if repairErr != nil {
+			err = repairErr
+		}
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	packageDir := d.packageDir
+
+	if d.pkg.Requirements.Python != "" {
+		var err error
+		if runtime.GOOS == "linux" {
+			_, err = os.Stat(filepath.Join(packageDir, ".local"))
+		} else if runtime.GOOS == "darwin" {
+			_, err = os.Stat(filepath.Join(packageDir, "Library"))
+		} else if runtime.GOOS == "windows" {
+			_, err = os.Stat(filepath.Join(packageDir, "Lib"))
+		}
+
+		if err == nil {
+			answer, err := term.Confirm("Would you like to reinstall it", true)
+			logger.Debugf("Would you like to reinstall it? %v", answer)
+			if err != nil {
 				return err
 			}
-		}
+			if !answer {
+				logger.Error(packages.ErrPackageNeedsReinstall.Error())
+				return cli.Exit(color.RedString(packages.ErrPackageNeedsReinstall.Error()), -1)
+			}
 
-		var currentCmd command
-		for _, cmd := range cmdPackage.Commands {
-			if strings.EqualFold(cmd.Name, commandName) {
-				currentCmd = cmd
-				break
+			if err = uninstallPackage(c.Context, langManager, d.Command, logger); err != nil {
+				return err
 			}
 
-			for _, alias := range cmd.Aliases {
-				if strings.EqualFold(alias, commandName) {
-					currentCmd = cmd
-				}
+			if _, err = installPackage(c.Context, git, langManager, d.Command, "", false); err != nil {
+				return err
 			}
 		}
+	}
 
-		executable = append(executable, os.Args[2:]...)
-		if err := os.Setenv("AKAMAI_CLI_COMMAND", commandName); err != nil {
+	printWorkspace(c.Context, d)
+	// the command gets short-lived credentials instead of those of its .edgerc section, if the section can exchange them
+	releaseCredentials, err := withEphemeralCredentials(c.Context, d)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer releaseCredentials()
+	for key, val := range d.Env {
+		if err := os.Setenv(key, val); err != nil {
 			return err
 		}
-		if err := os.Setenv("AKAMAI_CLI_COMMAND_VERSION", currentCmd.Version); err != nil {
-			return err
+	}
+	stats.TrackEvent(c.Context, "exec", d.Command, d.Version)
+	// uninstall and update refuse to change the package while the command runs
+	finishRunning := markPackageRunning(c.Context, filepath.Base(packageDir), d.Command)
+	defer finishRunning()
+	pkgLogger, closeLog := openPackageLog(c.Context, filepath.Base(packageDir))
+	defer closeLog()
+	logPackageDispatch(pkgLogger, d.Args, dir)
+	stopProxy, err := startNetworkProxy(c.Context, d, pkgLogger)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer stopProxy()
+	start := time.Now()
+
+	subCmd := passthruCmd(d.Args)
+	subCmd.Dir = dir
+	finishProgress := startProgress(c.Context, subCmd)
+	startCmd, releaseLimits := limitedStart(c.Context, subCmd, packageResourceLimits(c.Context, filepath.Base(packageDir)))
+	defer releaseLimits()
+	if interval := stepTimeout(c.Context, heartbeatKey); interval > 0 {
+		err = packageExitError(c.Context, d.exitCodes, runWithHeartbeat(subCmd, interval, os.Stderr, startCmd))
+	} else if err = startCmd(); err == nil {
+		err = packageExitError(c.Context, d.exitCodes, subCmd.Wait())
+	}
+	finishProgress(err == nil)
+	logPackageExit(pkgLogger, start, err)
+	recordPackageRun(c.Context, filepath.Base(packageDir), name, d.Version, start, err)
+	return err
+}
+
+// absolutePaths converts the elements of an executable command line which are paths of existing files to absolute paths,
+// so that they stay valid when the command is executed in another directory
+func absolutePaths(executable []string) ([]string, error) {
+	result := make([]string, 0, len(executable))
+	for _, arg := range executable {
+		if _, err := os.Stat(arg); err == nil && strings.ContainsRune(arg, filepath.Separator) {
+			abs, err := filepath.Abs(arg)
+			if err != nil {
+				return nil, err
+			}
+			arg = abs
 		}
-		stats.TrackEvent(c.Context, "exec", commandName, currentCmd.Version)
-		return passthruCommand(executable)
+		result = append(result, arg)
 	}
+	return result, nil
 }
@@ -24,6 +24,9 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
 			},
 		},
 		"run installed akamai echo command as binary with alias": {
@@ -31,6 +34,9 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
 			},
 		},
 		"run installed akamai echo command with python required": {
@@ -38,6 +44,9 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
 			},
 		},
 		"run installed akamai echo command as .cmd file": {
@@ -45,6 +54,9 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, "testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd").
 					Return([]string{"testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd"}, nil)
 			},
@@ -54,6 +66,21 @@ func TestCmdSubcommand(t *testing.T) {
 			args:    []string{"abc"},
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("", false)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, "testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd").
+					Return([]string{"testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd"}, nil)
+			},
+		},
+		"run installed akamai echo command with heartbeat": {
+			command: "echo-cmd",
+			args:    []string{"abc"},
+			init: func(t *testing.T, m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("GetValue", "cli", "heartbeat-interval").Return("1m", true)
+				m.cfg.On("GetValue", "cli", "package-logs").Return("", false)
+				m.cfg.On("GetValue", "cli", "exit-codes").Return("", false).Maybe()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, "testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd").
 					Return([]string{"testdata/.akamai-cli/src/cli-echo/bin/akamai-echo-cmd.cmd"}, nil)
 			},
@@ -4,7 +4,9 @@ import (
 	"context"
 	"github.com/stretchr/testify/require"
 	"github.com/tj/assert"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -16,3 +18,50 @@ func TestCommandsLocator(t *testing.T) {
 		assert.True(t, strings.Compare(res[i].Name, res[i+1].Name) == -1)
 	}
 }
+
+func TestLookPathIn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "look-path")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(first, "akamai-edgedns"):  0755,
+		filepath.Join(second, "akamai-dns"):     0755,
+		filepath.Join(second, "akamai-Purge"):   0755,
+		filepath.Join(first, "akamai-property"): 0644,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh"), mode))
+	}
+	paths := strings.Join([]string{"", first, second}, string(os.PathListSeparator))
+
+	tests := map[string]struct {
+		names    []string
+		expected string
+	}{
+		"first name found in a later directory": {
+			names:    []string{"akamai-dns", "akamai-edgedns"},
+			expected: filepath.Join(second, "akamai-dns"),
+		},
+		"second name": {
+			names:    []string{"akamai-purge", "akamai-Purge"},
+			expected: filepath.Join(second, "akamai-Purge"),
+		},
+		"not executable": {
+			names: []string{"akamai-property", "akamai-Property"},
+		},
+		"not found": {
+			names: []string{"akamai-missing"},
+		},
+	}
+
+	pathEnv := os.Getenv("PATH")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, lookPathIn(paths, test.names...))
+			assert.Equal(t, pathEnv, os.Getenv("PATH"))
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/akamai/cli/pkg/packages"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/akamai/cli/pkg/stats"
@@ -44,16 +45,42 @@ func cmdUninstall(langManager packages.LangManager) cli.ActionFunc {
 				logger.Errorf("UNINSTALL ERROR: %v", e.Error())
 			}
 		}()
+		defer refreshCompletions(c)
+		defer refreshLaunchers(c)
+		if c.Bool("all") && c.Args().Present() {
+			return cli.Exit(color.RedString("Specify either --all or the commands to uninstall"), 1)
+		}
+		c.Context = withNoHooks(withWaitRunning(c.Context, c.Bool("wait")), c.Bool("no-hooks"))
+		printResults := jsonResults(c)
+		if c.Bool("all") || c.NArg() > 1 {
+			results, err := uninstallPackages(c, langManager)
+			if printErr := printResults(uninstallOutputs(results)); printErr != nil && err == nil {
+				return printErr
+			}
+			return err
+		}
+		var results []*uninstallResult
 		for _, cmd := range c.Args().Slice() {
+			res := &uninstallResult{Name: cmd, Package: cmd}
+			if jsonOutput(c) {
+				// the package is looked up before it is removed, so that its name, version and path can be reported
+				res = uninstallTargets(c, langManager, []string{cmd})[0]
+			}
+			results = append(results, res)
 			if err := uninstallPackage(c.Context, langManager, cmd, logger); err != nil {
+				res.Status, res.Err = uninstallStatusFailed, err
 				stats.TrackEvent(c.Context, "package.uninstall", "failed", cmd)
 				logger.Error(err.Error())
+				if printErr := printResults(uninstallOutputs(results)); printErr != nil {
+					return printErr
+				}
 				return cli.Exit(color.RedString(err.Error()), 1)
 			}
+			res.Status = uninstallStatusRemoved
 			stats.TrackEvent(c.Context, "package.uninstall", "success", cmd)
 		}
 
-		return nil
+		return printResults(uninstallOutputs(results))
 	}
 }
 
@@ -62,25 +89,36 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 
 	exec, err := findExec(ctx, langManager, cmd)
 	if err != nil {
-		return fmt.Errorf("command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())
+		return commandNotFound(cmd)
 	}
 
 	term.Spinner().Start(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd))
 	logger.Debugf("Attempting to uninstall \"%s\" command...", cmd)
 
-	var repoDir string
-	if len(exec) == 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[0]))
-	} else if len(exec) > 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
-	}
-
+	repoDir := execPackageDir(exec)
 	if repoDir == "" {
 		term.Spinner().Fail()
 		logger.Error("unable to uninstall, was it installed using \"akamai install\"?")
 		return fmt.Errorf("unable to uninstall, was it installed using " + color.CyanString("\"akamai install\"") + "?")
 	}
 
+	if err := checkPackageIdle(ctx, repoDir); err != nil {
+		term.Spinner().Fail()
+		logger.Errorf("unable to uninstall: %s", err)
+		return fmt.Errorf("unable to uninstall, %w", err)
+	}
+
+	var oldVersion string
+	if pkg, err := readPackage(repoDir); err == nil {
+		oldVersion = commandVersion(pkg, cmd)
+		if hookCommand(pkg, hookPreUninstall) != "" {
+			if err := runPackageHook(ctx, repoDir, pkg, hookPreUninstall); err != nil {
+				return fmt.Errorf("unable to uninstall, %s. Run \"%s uninstall --no-hooks %s\" to uninstall without hooks", err, tools.Self(), cmd)
+			}
+			term.Spinner().Start(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd))
+		}
+	}
+
 	if err := os.RemoveAll(repoDir); err != nil {
 		term.Spinner().Fail()
 		logger.Errorf("unable to remove directory: %s", repoDir)
@@ -88,6 +126,24 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 	}
 
 	term.Spinner().OK()
+	packageName := filepath.Base(repoDir)
+	if dependents := installedDependencies().dependents(packageName); len(dependents) > 0 {
+		warnMsg := fmt.Sprintf("Warning: package %s is required by %s, which may no longer work.", packageName, strings.Join(dependents, ", "))
+		term.Writeln(color.YellowString(warnMsg))
+		logger.Warn(warnMsg)
+	}
+	recordPackageEvent(ctx, packageEventUninstall, repoDir)
+	notifyPackageEvent(ctx, packageEventUninstall, repoDir, oldVersion, "")
 
 	return nil
 }
+
+// execPackageDir returns the directory of the package containing given executable, empty if it is not in a package
+func execPackageDir(exec []string) string {
+	if len(exec) == 1 {
+		return findPackageDir(filepath.Dir(exec[0]))
+	} else if len(exec) > 1 {
+		return findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	}
+	return ""
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
 	"os"
 	"testing"
 )
@@ -35,6 +37,95 @@ func TestCmdUninstall(t *testing.T) {
 				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+		},
+		"uninstall package required by another one": {
+			args: []string{"echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/cli.json", "./testdata/.akamai-cli/src/cli-echo-uninstall")
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin")
+				err := os.Rename("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall")
+				require.NoError(t, err)
+				err = os.Chmod("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall", 0755)
+				require.NoError(t, err)
+				require.NoError(t, os.MkdirAll("./testdata/.akamai-cli/src/cli-echo-dependent", 0755))
+				err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-echo-dependent/cli.json", []byte(`{"commands":[{"name":"echo-dependent"}],"dependencies":["echo-uninstall"]}`), 0644)
+				require.NoError(t, err)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.YellowString("Warning: package cli-echo-uninstall is required by cli-echo-dependent, which may no longer work.")}).Return(0, nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+		},
+		"command of the package is running": {
+			args: []string{"echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/cli.json", "./testdata/.akamai-cli/src/cli-echo-uninstall")
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin")
+				err := os.Rename("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall")
+				require.NoError(t, err)
+				err = os.Chmod("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall", 0755)
+				require.NoError(t, err)
+				require.NoError(t, os.MkdirAll("./testdata/.akamai-cli/running/cli-echo-uninstall", 0755))
+				err = ioutil.WriteFile(fmt.Sprintf("./testdata/.akamai-cli/running/cli-echo-uninstall/%d", os.Getppid()), []byte("echo-uninstall"), 0644)
+				require.NoError(t, err)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			withError: fmt.Sprintf(`unable to uninstall, package cli-echo-uninstall is in use by "echo-uninstall" (PID %d), wait for it to finish or use --wait`, os.Getppid()),
+		},
+		"pre-uninstall hook fails": {
+			args: []string{"echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin")
+				err := os.Rename("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall")
+				require.NoError(t, err)
+				err = os.Chmod("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall", 0755)
+				require.NoError(t, err)
+				err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-echo-uninstall/cli.json", []byte(`{"commands":[{"name":"echo-uninstall"}],"hooks":{"pre-uninstall":{"command":"exit 3"}}}`), 0644)
+				require.NoError(t, err)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Running %s hook of %s...", []interface{}{hookPreUninstall, "cli-echo-uninstall"}).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+				m.cfg.On("GetValue", "cli", hookTimeoutKey).Return("", false).Once()
+				m.cfg.On("GetValue", "cli", maxCaptureKey).Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			withError: fmt.Sprintf(`unable to uninstall, pre-uninstall hook of cli-echo-uninstall failed: exit status 3. Run "%s uninstall --no-hooks echo-uninstall" to uninstall without hooks`, tools.Self()),
+		},
+		"uninstall without hooks": {
+			args: []string{"--no-hooks", "echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				copyFile(t, "./testdata/.akamai-cli/src/cli-echo/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin")
+				err := os.Rename("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo", "./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall")
+				require.NoError(t, err)
+				err = os.Chmod("./testdata/.akamai-cli/src/cli-echo-uninstall/bin/akamai-echo-uninstall", 0755)
+				require.NoError(t, err)
+				err = ioutil.WriteFile("./testdata/.akamai-cli/src/cli-echo-uninstall/cli.json", []byte(`{"commands":[{"name":"echo-uninstall"}],"hooks":{"pre-uninstall":{"command":"exit 3"}}}`), 0644)
+				require.NoError(t, err)
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("Skipping pre-uninstall hook of cli-echo-uninstall: exit 3")}).Return(0, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to uninstall "echo-uninstall" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
 			},
 		},
@@ -60,7 +151,7 @@ func TestCmdUninstall(t *testing.T) {
 			init: func(t *testing.T, m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
 			},
-			withError: fmt.Sprintf(`command "invalid" not found. Try "%s help"`, tools.Self()),
+			withError: fmt.Sprintf("Command \"invalid\" not found. [command-not-found]\nTry: run \"%s help\"", tools.Self()),
 		},
 	}
 
@@ -71,10 +162,13 @@ func TestCmdUninstall(t *testing.T) {
 			command := &cli.Command{
 				Name:   "uninstall",
 				Action: cmdUninstall(m.langManager),
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "no-hooks"}},
 			}
 			app, ctx := setupTestApp(command, m)
 			defer func() {
 				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-uninstall"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-dependent"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/running"))
 			}()
 			args := os.Args[0:1]
 			args = append(args, "uninstall")
@@ -84,6 +178,184 @@ func TestCmdUninstall(t *testing.T) {
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdUninstallMany(t *testing.T) {
+	installEcho := func(t *testing.T, name string) {
+		dir := "./testdata/.akamai-cli/src/cli-" + name
+		require.NoError(t, os.MkdirAll(dir+"/bin", 0755))
+		err := ioutil.WriteFile(dir+"/cli.json", []byte(fmt.Sprintf(`{"commands":[{"name":%q,"version":"1.0.0"}]}`, name)), 0644)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(dir+"/bin/akamai-"+name, []byte("#!/bin/sh\n"), 0755))
+	}
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked)
+		removed   []string
+		kept      []string
+		expected  []string
+		withError string
+	}{
+		"uninstall several packages": {
+			args: []string{"--yes", "echo-many-a", "echo-many-b"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				installEcho(t, "echo-many-b")
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Uninstalling 2 packages...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "%s", mock.Anything).Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false)
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"command-owner": {"echo-many-a": "cli-echo-many-a", "echo": "cli-echo"},
+				}).Once()
+				m.cfg.On("UnsetValue", "command-owner", "echo-many-a").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+			removed:  []string{"cli-echo-many-a", "cli-echo-many-b"},
+			expected: []string{color.YellowString("\nUninstall Summary:\n")},
+		},
+		"command not found is reported in summary": {
+			args: []string{"-y", "echo-many-a", "invalid"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Uninstalling 1 packages...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "%s", mock.Anything).Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false)
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+			},
+			removed:   []string{"cli-echo-many-a"},
+			expected:  []string{color.YellowString("\nUninstall Summary:\n")},
+			withError: "Unable to uninstall 1 of 2 packages",
+		},
+		"json output": {
+			args: []string{"--json", "-y", "echo-many-a", "invalid"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Uninstalling 1 packages...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false)
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+			},
+			removed: []string{"cli-echo-many-a"},
+			expected: []string{`[
+  {
+    "command": "echo-many-a",
+    "package": "cli-echo-many-a",
+    "version": "1.0.0",
+    "path": "testdata/.akamai-cli/src/cli-echo-many-a",
+    "status": "uninstalled"
+  },
+  {
+    "command": "invalid",
+    "package": "invalid",
+    "status": "failed",
+    "error": "command \"invalid\" not found"
+  }
+]`},
+			withError: "Unable to uninstall 1 of 2 packages",
+		},
+		"json output of single package": {
+			args: []string{"--json", "echo-many-a"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Attempting to uninstall "echo-many-a" command...`, []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false)
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			removed: []string{"cli-echo-many-a"},
+			expected: []string{`[
+  {
+    "command": "echo-many-a",
+    "package": "cli-echo-many-a",
+    "version": "1.0.0",
+    "path": "testdata/.akamai-cli/src/cli-echo-many-a",
+    "status": "uninstalled"
+  }
+]`},
+		},
+		"confirmation declined": {
+			args: []string{"echo-many-a", "echo-many-b"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				installEcho(t, "echo-many-b")
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Confirm", "Uninstall 2 packages (cli-echo-many-a, cli-echo-many-b)?", false).Return(false, nil).Once()
+			},
+			kept:     []string{"cli-echo-many-a", "cli-echo-many-b"},
+			expected: []string{"Uninstall cancelled"},
+		},
+		"confirmation required without terminal": {
+			args: []string{"--all"},
+			init: func(t *testing.T, m *mocked) {
+				installEcho(t, "echo-many-a")
+				m.term.On("IsTTY").Return(false).Once()
+			},
+			kept:      []string{"cli-echo", "cli-echo-many-a"},
+			withError: "use --yes to uninstall them without asking",
+		},
+		"all with commands": {
+			args:      []string{"--all", "echo-many-a"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "Specify either --all or the commands to uninstall",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			var output []string
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Run(func(args mock.Arguments) {
+				output = append(output, fmt.Sprint(args.Get(0).([]interface{})...))
+			}).Maybe()
+			command := &cli.Command{
+				Name:   "uninstall",
+				Action: cmdUninstall(m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "all"},
+					&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}},
+					&cli.BoolFlag{Name: "json"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			defer func() {
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-many-a"))
+				require.NoError(t, os.RemoveAll("./testdata/.akamai-cli/src/cli-echo-many-b"))
+			}()
+			args := os.Args[0:1]
+			args = append(args, "uninstall")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			assert.Equal(t, test.expected, output)
+			for _, pkg := range test.removed {
+				assert.NoDirExists(t, "./testdata/.akamai-cli/src/"+pkg)
+			}
+			for _, pkg := range test.kept {
+				assert.DirExists(t, "./testdata/.akamai-cli/src/"+pkg)
+			}
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
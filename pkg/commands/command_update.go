@@ -16,14 +16,18 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/akamai/cli/pkg/packages"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/log"
@@ -31,6 +35,9 @@ import (
 	"github.com/akamai/cli/pkg/tools"
 )
 
+// newGitRepository returns the repository of each additional worker of a parallel update
+var newGitRepository = git.NewRepository
+
 func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
 	return func(c *cli.Context) (e error) {
 		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
@@ -39,11 +46,44 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 		logger.Debug("UPDATE START")
 		defer func() {
 			if e == nil {
-				logger.Debugf("UPDATE FINISH: %v", time.Now().Sub(start))
+				logger.Debugf("UPDATE FINISH: %s", formatDuration(since(start)))
 			} else {
 				logger.Errorf("UPDATE ERROR: %v", e.Error())
 			}
 		}()
+		defer refreshCompletions(c)
+		defer refreshLaunchers(c)
+		c.Context = withUnlock(withSkipVerify(c.Context, c.Bool("skip-verify")), c.Bool("unlock"))
+		c.Context = withGitHubToken(withNoHooks(c.Context, c.Bool("no-hooks")))
+		c.Context = withWaitRunning(c.Context, c.Bool("wait"))
+		if c.Bool("auto") {
+			return autoUpdatePackages(c, gitRepo, langManager)
+		}
+		asJSON := jsonOutput(c)
+		printResults := jsonResults(c)
+		results := make([]updateResult, 0)
+		showSummary := !c.Args().Present() || c.NArg() > 1
+		defer func() {
+			var err error
+			if asJSON {
+				err = printResults(results)
+			} else if showSummary && len(results) > 0 {
+				err = printUpdateSummary(c.Context, results)
+			}
+			if err != nil && e == nil {
+				e = err
+			}
+		}()
+
+		if c.IsSet("version") && c.NArg() != 1 {
+			return cli.Exit(color.RedString("--version can only be used when updating a single command"), 1)
+		}
+		concurrency := c.Int("concurrency")
+		if c.IsSet("concurrency") && concurrency < 1 {
+			return cli.Exit(color.RedString("--concurrency has to be at least 1"), 1)
+		}
+
+		cmds := c.Args().Slice()
 		if !c.Args().Present() {
 			var builtinCmds = make(map[string]bool)
 			for _, cmd := range getBuiltinCommands(c) {
@@ -53,31 +93,111 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 			for _, cmd := range getCommands(c) {
 				for _, command := range cmd.Commands {
 					if _, ok := builtinCmds[command.Name]; !ok {
-						if err := updatePackage(c.Context, gitRepo, langManager, logger, command.Name, c.Bool("force")); err != nil {
-							return err
-						}
+						cmds = append(cmds, command.Name)
 					}
 				}
 			}
+		}
 
-			return nil
+		// executables are looked up before any update starts, so that the package of every command is found
+		// among the packages as installed, not while other packages are updated in parallel
+		execs := make([][]string, len(cmds))
+		for i, cmd := range cmds {
+			execs[i], _ = findExec(c.Context, langManager, cmd)
 		}
 
-		for _, cmd := range c.Args().Slice() {
-			if err := updatePackage(c.Context, gitRepo, langManager, logger, cmd, c.Bool("force")); err != nil {
-				return err
+		// a failure of one package does not stop the updates of the others, the failed ones are listed at the end
+		errs := make([]error, len(cmds))
+		results = make([]updateResult, len(cmds))
+		update := func(ctx context.Context, repo git.Repository, i int) {
+			results[i], errs[i] = updatePackageExec(ctx, repo, langManager, logger, cmds[i], execs[i], c.String("version"), c.Bool("force"), c.Bool("reset"))
+			if errs[i] != nil && (len(cmds) > 1 || results[i].TimedOut || c.Bool("continue-on-error")) {
+				terminal.Get(ctx).WriteError(errs[i].Error())
+			}
+		}
+		if concurrency > 1 && len(cmds) > 1 {
+			updateInParallel(c.Context, gitRepo, execs, concurrency, update)
+		} else {
+			for i := range cmds {
+				update(c.Context, gitRepo, i)
 			}
 		}
 
-		return nil
+		if len(cmds) == 1 && errs[0] != nil && !results[0].TimedOut && !c.Bool("continue-on-error") {
+			return errs[0]
+		}
+		return updateFailuresError(results)
+	}
+}
+
+// updateInParallel runs update for each of the commands with given executables, with up to concurrency packages
+// updated at the same time. Commands of the same package are updated one after another, so that they do not
+// pull the same repository at once. Each worker uses a repository of its own and writes the output of a package
+// once it is done.
+func updateInParallel(ctx context.Context, gitRepo git.Repository, execs [][]string, concurrency int, update func(context.Context, git.Repository, int)) {
+	var groups [][]int
+	byDir := make(map[string]int)
+	for i, exec := range execs {
+		dir := execPackageDir(exec)
+		if j, ok := byDir[dir]; ok && dir != "" {
+			groups[j] = append(groups[j], i)
+			continue
+		}
+		byDir[dir] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	if concurrency > len(groups) {
+		concurrency = len(groups)
+	}
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		repo := gitRepo
+		if w > 0 {
+			repo = newGitRepository()
+		}
+		wg.Add(1)
+		go func(repo git.Repository) {
+			defer wg.Done()
+			for group := range jobs {
+				term := terminal.Buffered(terminal.Get(ctx))
+				for _, i := range group {
+					update(terminal.Context(ctx, term), repo, i)
+				}
+				term.Flush()
+			}
+		}(repo)
+	}
+	for _, group := range groups {
+		jobs <- group
 	}
+	close(jobs)
+	wg.Wait()
 }
 
-func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd string, forceBinary bool) error {
+// updatePackage updates the package providing cmd to the version it is pinned to, the tip of its update channel otherwise.
+// A non-empty version pins the package to given tag, semantic version range or commit, packageVersionLatest removes the pin.
+func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd, version string, forceBinary, reset bool) (updateResult, error) {
+	exec, _ := findExec(ctx, langManager, cmd)
+	return updatePackageExec(ctx, gitRepo, langManager, logger, cmd, exec, version, forceBinary, reset)
+}
+
+// updatePackageExec updates the package of cmd found with its executable, an empty exec meaning cmd was not found
+func updatePackageExec(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger log.Logger, cmd string, exec []string, version string, forceBinary, reset bool) (res updateResult, e error) {
 	term := terminal.Get(ctx)
-	exec, err := findExec(ctx, langManager, cmd)
-	if err != nil {
-		return cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
+	start := time.Now()
+	res = updateResult{Command: cmd, Status: updateStatusFailed}
+	defer func() {
+		if e != nil {
+			res.Error = e.Error()
+		}
+		res.Duration = elapsed(since(start))
+		logger.Debugf("Update of \"%s\" command took %s", cmd, res.Duration)
+	}()
+
+	if len(exec) == 0 {
+		return res, commandNotFound(cmd)
 	}
 
 	logger.Debugf("Command found: %s", filepath.Join(exec...))
@@ -94,76 +214,386 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 
 	if repoDir == "" {
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?"), 1)
+		return res, cli.Exit(color.RedString("unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?"), 1)
 	}
 
 	logger.Debugf("Repo found: %s", repoDir)
+	res.Package, res.Path = filepath.Base(repoDir), repoDir
+	if err := checkPackageIdle(ctx, repoDir); err != nil {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Unable to update, %s", err), 1)
+	}
+	if pkg := packageUpdateChannels()[res.Package]; pkg != nil && pkg.Archive != "" {
+		term.Spinner().WarnOK()
+		msg := fmt.Sprintf("Package \"%s\" was installed from archive %s and is not updated, uninstall it and run \"%s install --file <archive>\" with a newer archive instead", res.Package, pkg.Archive, tools.Self())
+		logger.Warn(msg)
+		term.Writeln(color.CyanString(msg))
+		res.Status = updateStatusSkipped
+		res.NewVersion = res.OldVersion
+		return res, nil
+	}
+	if err := checkPreflight(ctx, repoDir); err != nil {
+		term.Spinner().Fail()
+		return res, err
+	}
+	oldPkg, oldPkgErr := readPackage(repoDir)
+	if oldPkgErr == nil {
+		res.OldVersion = commandVersion(oldPkg, cmd)
+	}
+	if record, err := readSourceRecord(repoDir); err == nil {
+		return updateFromSource(ctx, langManager, logger, res, record, cmd, version, forceBinary)
+	}
 
-	err = gitRepo.Open(repoDir)
+	err := gitRepo.Open(repoDir)
 	if err != nil {
 		logger.Debug("Unable to open repo")
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
+		return res, cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
 	}
 
 	w, err := gitRepo.Worktree()
 	if err != nil {
 		logger.Debug("Unable to open repo")
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
+		return res, cli.Exit(color.RedString("unable to update, there an issue with the package repo: %s", err.Error()), 1)
 	}
-	refName := "refs/remotes/" + git.DefaultRemoteName + "/master"
-
 	refBeforePull, errBeforePull := gitRepo.Head()
 	logger.Debugf("Fetching from remote: %s", git.DefaultRemoteName)
-	logger.Debugf("Using ref: %s", refName)
 
-	if errBeforePull != nil {
+	// a package cloned from an empty repository has no commit yet, everything pulled is new
+	if errBeforePull != nil && !errors.Is(errBeforePull, git.ErrEmptyRepository) {
 		logger.Debugf("Fetch error: %s", errBeforePull.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", errBeforePull.Error()), 1)
+		return res, cli.Exit(color.RedString("Unable to fetch updates (%s)", errBeforePull.Error()), 1)
+	}
+	hashBeforePull := plumbing.ZeroHash
+	if errBeforePull == nil {
+		hashBeforePull = refBeforePull.Hash()
 	}
 
-	err = gitRepo.Pull(ctx, w)
-	if err != nil && err.Error() != alreadyUptoDate {
+	channel := packageUpdateChannels()[filepath.Base(repoDir)]
+	pin := version
+	if version == "" && channel != nil {
+		pin = channel.Pinned
+	}
+	if version == packageVersionLatest {
+		pin = ""
+	}
+	if channel != nil && channel.Locked != "" {
+		if !unlockRequested(ctx) {
+			term.Spinner().Fail()
+			return res, cli.Exit(color.RedString("Package \"%s\" is locked to commit %s, run \"%s update --unlock %s\" to update it", res.Package, shortHash(channel.Locked), tools.Self(), cmd), 1)
+		}
+		logger.Debugf("Unlocking package from commit %s", channel.Locked)
+		unlockPackage(ctx, repoDir)
+		if version == "" && pin == channel.Locked {
+			pin = ""
+		}
+	}
+	if err := runPreUpdateHook(ctx, repoDir, oldPkg, cmd); err != nil {
+		return res, err
+	}
+
+	pullCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	switch {
+	case pin != "":
+		logger.Debugf("Checking out version %s", pin)
+		err = checkoutPackageVersion(pullCtx, gitRepo, pin)
+	case updateTag(ctx) != "":
+		// automatic updates stay on the installed major version, which may not have the latest tag of the channel
+		logger.Debugf("Checking out tag %s", updateTag(ctx))
+		err = checkoutPackageVersion(pullCtx, gitRepo, updateTag(ctx))
+	case channel != nil && channel.channel() != channelBranch:
+		err = checkoutChannelTag(pullCtx, gitRepo, channel.channel())
+	case channel != nil && channel.Branch != "" && errBeforePull == nil && refBeforePull.Name() != plumbing.NewBranchReferenceName(channel.Branch):
+		// the package followed tags, was pinned before or was switched to another branch, so the branch it follows is checked out
+		logger.Debugf("Checking out branch %s", channel.Branch)
+		err = gitRepo.CheckoutBranch(pullCtx, git.DefaultRemoteName, channel.Branch)
+	case errBeforePull == nil && refBeforePull.Name() == plumbing.HEAD:
+		// no branch is known for a detached package, so it returns to the default branch of the remote
+		var branch string
+		if branch, err = gitRepo.DefaultBranch(pullCtx, git.DefaultRemoteName); err == nil {
+			logger.Debugf("Checking out default branch %s", branch)
+			err = gitRepo.CheckoutBranch(pullCtx, git.DefaultRemoteName, branch)
+		}
+	default:
+		if errBeforePull == nil && refBeforePull.Name().IsBranch() {
+			logger.Debugf("Using ref: %s", plumbing.NewRemoteReferenceName(git.DefaultRemoteName, refBeforePull.Name().Short()))
+		}
+		err = gitRepo.Pull(pullCtx, w)
+	}
+	switch {
+	case err == nil, errors.Is(err, git.ErrAlreadyUpToDate):
+	case errors.Is(err, git.ErrEmptyRepository):
+		logger.Debugf("Fetch error: %s", err.Error())
+		term.Spinner().WarnOK()
+		debugMessage := fmt.Sprintf("command \"%s\" has no commits upstream yet, nothing to update", cmd)
+		logger.Warn(debugMessage)
+		term.Writeln(color.CyanString(debugMessage))
+		res.Status = updateStatusUpToDate
+		res.NewVersion = res.OldVersion
+		return res, nil
+	case errors.Is(err, git.ErrHistoryRewritten), errors.Is(err, git.ErrDiverged):
+		logger.Debugf("Fetch error: %s", err.Error())
+		term.Spinner().Warn()
+		if err := resetPackage(ctx, gitRepo, logger, cmd, repoDir, err, reset); err != nil {
+			return res, err
+		}
+	default:
 		logger.Debugf("Fetch error: %s", err.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+		res.TimedOut = errors.Is(pullCtx.Err(), context.DeadlineExceeded) || errors.Is(err, git.ErrNetworkTimeout)
+		return res, fetchFailure(res.Package, err)
+	}
+
+	if version != "" {
+		branch := ""
+		if errBeforePull == nil && refBeforePull.Name().IsBranch() {
+			branch = refBeforePull.Name().Short()
+		}
+		pinPackage(ctx, repoDir, pin, branch)
 	}
 
 	ref, err := gitRepo.Head()
-	if err != nil && err.Error() != alreadyUptoDate {
+	if err != nil {
 		logger.Debugf("Fetch error: %s", err.Error())
 		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+		return res, cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
 	}
 
-	if refBeforePull.Hash() != ref.Hash() {
+	// dependencies are reinstalled unless the changes pulled are known not to affect them
+	reinstall := true
+	if hashBeforePull != ref.Hash() {
 		commit, err := gitRepo.CommitObject(ref.Hash())
-		logger.Debugf("HEAD differs: %s (old) vs %s (new)", refBeforePull.Hash().String(), ref.Hash().String())
+		logger.Debugf("HEAD differs: %s (old) vs %s (new)", hashBeforePull.String(), ref.Hash().String())
 		logger.Debugf("Latest commit: %s", commit)
 
-		if err != nil && err.Error() != alreadyUptoDate {
+		if err != nil {
 			logger.Debugf("Fetch error: %s", err.Error())
 			term.Spinner().Fail()
-			return cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+			return res, cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+		}
+		res.OldCommit, res.NewCommit = hashBeforePull.String(), ref.Hash().String()
+
+		changes, err := gitRepo.Changes(hashBeforePull, ref.Hash())
+		if err != nil {
+			logger.Debugf("Unable to calculate changes: %s", err.Error())
+		} else {
+			res.Commits = changes.Commits
+			res.FilesChanged = len(changes.FilesChanged)
+			res.DependencyChanges = dependencyChanges(changes.FilesChanged)
+			// without the previous cli.json, the requirements the dependencies were installed for are unknown
+			reinstall = forceBinary || oldPkgErr != nil || requiresReinstall(oldPkg.Requirements, changes.FilesChanged)
 		}
 	} else {
-		logger.Debugf("HEAD is the same as the remote: %s (old) vs %s (new)", refBeforePull.Hash().String(), ref.Hash().String())
+		logger.Debugf("HEAD is the same as the remote: %s (old) vs %s (new)", hashBeforePull.String(), ref.Hash().String())
 		term.Spinner().WarnOK()
 		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
+		if pin != "" {
+			debugMessage = fmt.Sprintf("command \"%s\" already up-to-date, pinned to version %s", cmd, pin)
+		}
 		logger.Warn(debugMessage)
 		term.Writeln(color.CyanString(debugMessage))
-		return nil
+		res.Status = updateStatusUpToDate
+		res.NewVersion = res.OldVersion
+		return res, nil
 	}
 
 	logger.Debug("Repo updated successfully")
 	term.Spinner().OK()
 
-	if ok, _ := installPackageDependencies(ctx, langManager, repoDir, forceBinary, logger); !ok {
+	if !reinstall {
+		// dependencies removed since the last install are installed again, even if no change affects them
+		if pkg, err := readPackage(repoDir); err == nil && !dependenciesMissing(repoDir, pkg) {
+			logger.Debug("No dependency or source changes, skipping dependency installation")
+			saveInstallRecord(ctx, repoDir, ref.Hash().String())
+			res.Status = updateStatusUpdated
+			res.NewVersion = commandVersion(pkg, cmd)
+			recordPackageEvent(ctx, packageEventUpdate, repoDir)
+			notifyPackageEvent(ctx, packageEventUpdate, repoDir, res.OldVersion, res.NewVersion)
+			res.Selftest = runInstallSelftests(ctx, langManager, repoDir, pkg)
+			return res, nil
+		}
+	}
+
+	pkg, err := installPackageDependencies(ctx, langManager, repoDir, hookPostUpdate, forceBinary, logger)
+	if err != nil {
 		logger.Trace("Error updating dependencies")
-		return cli.Exit("Unable to update command", 1)
+		res.TimedOut = errors.Is(err, packages.ErrStepTimeout)
+		return res, cli.Exit("Unable to update command", 1)
+	}
+	saveInstallRecord(ctx, repoDir, ref.Hash().String())
+	res.Status = updateStatusUpdated
+	res.NewVersion = commandVersion(*pkg, cmd)
+	recordPackageEvent(ctx, packageEventUpdate, repoDir)
+	notifyPackageEvent(ctx, packageEventUpdate, repoDir, res.OldVersion, res.NewVersion)
+	res.Selftest = runInstallSelftests(ctx, langManager, repoDir, *pkg)
+
+	return res, nil
+}
+
+// updateFromSource updates a package which was not cloned from a git repository by fetching it again from its source,
+// replacing the package directory if the revision fetched differs from the one installed
+func updateFromSource(ctx context.Context, langManager packages.LangManager, logger log.Logger, res updateResult, record *sourceRecord, cmd, version string, forceBinary bool) (updateResult, error) {
+	term := terminal.Get(ctx)
+	repoDir := res.Path
+	source := packageSourceByName(record.Source)
+	if source == nil {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Unable to update, package \"%s\" was fetched from unknown source %q", res.Package, record.Source), 1)
+	}
+	if version != "" {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Unable to update, versions are not supported for packages of the %s source", source.name()), 1)
+	}
+	if channel := packageUpdateChannels()[res.Package]; channel != nil && channel.Locked != "" && !unlockRequested(ctx) {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Package \"%s\" is locked to revision %s, run \"%s update --unlock %s\" to update it", res.Package, shortHash(channel.Locked), tools.Self(), cmd), 1)
+	} else if channel != nil && channel.Locked != "" {
+		unlockPackage(ctx, repoDir)
+	}
+	if pkg, err := readPackage(repoDir); err == nil {
+		if err := runPreUpdateHook(ctx, repoDir, pkg, cmd); err != nil {
+			return res, err
+		}
+	}
+
+	logger.Debugf("Fetching from %s source: %s", source.name(), record.URL)
+	fetchDir := filepath.Join(filepath.Dir(repoDir), "."+res.Package+"-update")
+	if err := os.RemoveAll(fetchDir); err != nil {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+	}
+	fetchCtx, cancel := cloneContext(ctx)
+	defer cancel()
+	revision, err := fetchFromSource(fetchCtx, source, record.URL, fetchDir, record.Revision)
+	if errors.Is(err, errSourceUnchanged) {
+		term.Spinner().WarnOK()
+		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
+		logger.Warn(debugMessage)
+		term.Writeln(color.CyanString(debugMessage))
+		res.Status = updateStatusUpToDate
+		res.NewVersion = res.OldVersion
+		return res, nil
+	}
+	if err != nil {
+		logger.Debugf("Fetch error: %s", err.Error())
+		term.Spinner().Fail()
+		res.TimedOut = errors.Is(fetchCtx.Err(), context.DeadlineExceeded)
+		if rmErr := os.RemoveAll(fetchDir); rmErr != nil {
+			logger.Debugf("Unable to remove %s: %s", fetchDir, rmErr)
+		}
+		return res, cli.Exit(color.RedString("Unable to fetch updates (%s)", err.Error()), 1)
+	}
+
+	backupDir := filepath.Join(filepath.Dir(repoDir), "."+res.Package+"-previous")
+	if err := os.RemoveAll(backupDir); err == nil {
+		err = os.Rename(repoDir, backupDir)
+	}
+	if err == nil {
+		err = os.Rename(fetchDir, repoDir)
+	}
+	if err != nil {
+		term.Spinner().Fail()
+		return res, cli.Exit(color.RedString("Unable to replace package \"%s\" (%s)", res.Package, err.Error()), 1)
+	}
+	logger.Debug("Package updated successfully")
+	term.Spinner().OK()
+
+	pkg, err := installPackageDependencies(ctx, langManager, repoDir, hookPostUpdate, forceBinary, logger)
+	if err != nil {
+		logger.Trace("Error updating dependencies, restoring the previous revision")
+		if rmErr := os.RemoveAll(repoDir); rmErr == nil {
+			if mvErr := os.Rename(backupDir, repoDir); mvErr != nil {
+				logger.Debugf("Unable to restore the previous revision: %s", mvErr)
+			}
+		}
+		res.TimedOut = errors.Is(err, packages.ErrStepTimeout)
+		return res, cli.Exit("Unable to update command", 1)
+	}
+	if err := os.RemoveAll(backupDir); err != nil {
+		logger.Debugf("Unable to remove the previous revision: %s", err)
+	}
+	saveInstallRecord(ctx, repoDir, revision)
+	res.OldCommit, res.NewCommit = record.Revision, revision
+	res.Status = updateStatusUpdated
+	res.NewVersion = commandVersion(*pkg, cmd)
+	recordPackageEvent(ctx, packageEventUpdate, repoDir)
+	notifyPackageEvent(ctx, packageEventUpdate, repoDir, res.OldVersion, res.NewVersion)
+	res.Selftest = runInstallSelftests(ctx, langManager, repoDir, *pkg)
+
+	return res, nil
+}
+
+// runPreUpdateHook runs the pre-update hook of the package before it is fetched, the update is cancelled if it fails
+func runPreUpdateHook(ctx context.Context, dir string, pkg subcommands, cmd string) error {
+	if hookCommand(pkg, hookPreUpdate) == "" {
+		return nil
+	}
+	term := terminal.Get(ctx)
+	if err := runPackageHook(ctx, dir, pkg, hookPreUpdate); err != nil {
+		return cli.Exit(color.RedString("Unable to update, %s. Run \"%s update --no-hooks %s\" to update without hooks", err, tools.Self(), cmd), 1)
+	}
+	term.Spinner().Start("Attempting to update \"%s\" command...", cmd)
+	return nil
+}
+
+// resetPackage resets the package repository to its remote branch after the update could not be fast-forwarded,
+// asking for confirmation first unless reset is set, as local commits are lost
+// The repository refuses to reset a worktree with uncommitted changes, which have to be handled by the user.
+func resetPackage(ctx context.Context, gitRepo git.Repository, logger log.Logger, cmd, dir string, pullErr error, reset bool) error {
+	term := terminal.Get(ctx)
+	reason := newFailure(failureHistoryRewritten, failureData{"Command": cmd}, nil)
+	if errors.Is(pullErr, git.ErrDiverged) {
+		reason = newFailure(failureLocalCommits, failureData{"Command": cmd}, nil)
+	}
+	if !reset {
+		if !term.IsTTY() {
+			return reason
+		}
+		answer, err := term.Confirm(fmt.Sprintf("%s. Reset it to the remote branch, discarding local commits", reason.Message()), false)
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		if !answer {
+			return reason
+		}
 	}
 
+	term.Spinner().Start("Resetting \"%s\" command to the remote branch...", cmd)
+	if err := gitRepo.ResetToRemote(git.DefaultRemoteName); err != nil {
+		logger.Debugf("Reset error: %s", err.Error())
+		term.Spinner().Fail()
+		if errors.Is(err, git.ErrLocalChanges) {
+			return newFailure(failureLocalChanges, failureData{"Command": cmd, "Dir": dir}, err)
+		}
+		return cli.Exit(color.RedString("Unable to reset to the remote branch (%s)", err.Error()), 1)
+	}
+	logger.Debugf("Repo reset to %s", git.DefaultRemoteName)
 	return nil
 }
+
+// updateFailuresError returns an error listing commands which were skipped because of an error
+// or because an update step exceeded its time limit
+func updateFailuresError(results []updateResult) error {
+	var failed, timedOut []string
+	for _, res := range results {
+		if res.TimedOut {
+			timedOut = append(timedOut, res.Command)
+		} else if res.Error != "" {
+			failed = append(failed, res.Command)
+		}
+	}
+	var messages []string
+	if len(failed) > 0 {
+		messages = append(messages, fmt.Sprintf("Update failed for: %s", strings.Join(failed, ", ")))
+	}
+	if len(timedOut) > 0 {
+		messages = append(messages, fmt.Sprintf("Update timed out for: %s", strings.Join(timedOut, ", ")))
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return cli.Exit(color.RedString(strings.Join(messages, "\n")), 1)
+}
@@ -15,13 +15,18 @@ import (
 	gogit "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCmdUpdate(t *testing.T) {
+	since = func(time.Time) time.Duration { return 1540 * time.Millisecond }
+	defer func() { since = time.Since }()
 	tests := map[string]struct {
 		args      []string
 		init      func(*testing.T, *mocked)
@@ -38,19 +43,84 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 2, FilesChanged: []string{"README.md", "go.sum"}}, nil).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-echo",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+		},
+		"skip dependency installation when only documentation changed": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md", "docs/usage.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+		},
+		"reinstall dependencies when the previous cli.json cannot be read": {
+			args: []string{"echo-old"},
+			init: func(t *testing.T, m *mocked) {
+				pkgDir := "testdata/.akamai-cli/src/cli-echo-old"
+				require.NoError(t, os.MkdirAll(filepath.Join(pkgDir, "bin"), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "bin", "akamai-echo-old"), []byte("#!/bin/sh\n"), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "cli.json"), []byte("invalid json"), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo-old"}).Return().Once()
+
+				m.gitRepo.On("Open", pkgDir).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil).Run(func(mock.Arguments) {
+					cliJSON := `{"requirements": {"go": "1.14.0"}, "commands": [{"name": "echo-old"}]}`
+					require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "cli.json"), []byte(cliJSON), 0644))
+				})
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", pkgDir, packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo-old"}).Return(nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.RemoveAll("testdata/.akamai-cli/src/cli-echo-old"))
 			},
 		},
 		"update all packages": {
@@ -63,19 +133,61 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 2, FilesChanged: []string{"README.md", "go.sum"}}, nil).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
 				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-echo",
 					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).Return(nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpdate Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", []interface{}{"" +
+					"  COMMAND  VERSION  COMMIT              COMMITS  FILES  DEPENDENCIES  TIME  STATUS\n" +
+					"  echo              0000000 -> 0100000  2        2      go.sum        1.5s  updated\n" +
+					"\n  1 updated, 0 up-to-date, 0 failed\n"}).Return().Once()
+			},
+		},
+		"update all packages with json summary": {
+			args: []string{"--json"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("WriteErrorf", "%s", []interface{}{color.CyanString("command \"echo\" already up-to-date") + "\n"}).Return().Once()
+
+				m.term.On("Writeln", []interface{}{`[
+  {
+    "command": "echo",
+    "package": "cli-echo",
+    "path": "testdata/.akamai-cli/src/cli-echo",
+    "status": "up-to-date",
+    "commits": 0,
+    "filesChanged": 0,
+    "dependencyChanges": null,
+    "durationMs": 1540
+  }
+]`}).Return(0, nil).Once()
 			},
 		},
 		"command is up to date": {
@@ -88,6 +200,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
 				m.term.On("Spinner").Return(m.term).Once()
@@ -105,9 +218,11 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 2, FilesChanged: []string{"README.md", "go.sum"}}, nil).Once()
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
@@ -120,6 +235,39 @@ func TestCmdUpdate(t *testing.T) {
 			},
 			withError: "Unable to update command",
 		},
+		"dependency installation timed out": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"main.go"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("1s", true).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", "testdata/.akamai-cli/src/cli-echo",
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}).
+					Return(fmt.Errorf("%w: dependency installation did not finish within 1s", packages.ErrStepTimeout)).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("Writeln", mock.Anything).Return(0, nil).Once()
+				m.term.On("WriteError", "Unable to update command").Return().Once()
+			},
+			withError: "Update timed out for: echo",
+		},
 		"error fetching commit by hash": {
 			args: []string{"echo-invalid-json"},
 			init: func(t *testing.T, m *mocked) {
@@ -130,6 +278,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
 				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(nil, fmt.Errorf("oops")).Once()
@@ -149,6 +298,7 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(nil)
 				m.gitRepo.On("Head").Return(nil, fmt.Errorf("oops")).Once()
 
@@ -167,12 +317,352 @@ func TestCmdUpdate(t *testing.T) {
 				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-invalid-json").Return(nil).Once()
 				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
 				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
 				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("oops"))
 
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Fail").Return().Once()
 			},
-			withError: "Unable to fetch updates (oops)",
+			withError: "Unable to fetch cli-echo-invalid-json [fetch-failed]\nCause: oops",
+		},
+		"empty upstream repository": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(nil, fmt.Errorf("%w: reference not found", git.ErrEmptyRepository)).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: remote repository is empty", git.ErrEmptyRepository))
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("command \"echo\" has no commits upstream yet, nothing to update")}).Return(0, nil).Once()
+			},
+		},
+		"remote history rewritten, reset confirmed": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: non-fast-forward update", git.ErrHistoryRewritten))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Confirm", `Unable to update, the remote history of command "echo" was rewritten. Reset it to the remote branch, discarding local commits`, false).Return(true, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Resetting "%s" command to the remote branch...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("ResetToRemote", git.DefaultRemoteName).Return(nil).Once()
+
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+		},
+		"diverged history, reset declined": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: non-fast-forward update", git.ErrDiverged))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Confirm", `Unable to update, command "echo" has local commits which are not on the remote. Reset it to the remote branch, discarding local commits`, false).Return(false, nil).Once()
+			},
+			withError: `Unable to update, command "echo" has local commits which are not on the remote`,
+		},
+		"diverged history without terminal": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: non-fast-forward update", git.ErrDiverged))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("IsTTY").Return(false).Once()
+			},
+			withError: "has local commits which are not on the remote [local-commits]\nTry: run \"" + tools.Self() + " update --reset echo\"",
+		},
+		"diverged history reset with flag": {
+			args: []string{"--reset", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: non-fast-forward update", git.ErrDiverged))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Resetting "%s" command to the remote branch...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("ResetToRemote", git.DefaultRemoteName).Return(fmt.Errorf("branch master not found on remote origin"))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "Unable to reset to the remote branch (branch master not found on remote origin)",
+		},
+		"reset refused with uncommitted changes": {
+			args: []string{"--reset", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(fmt.Errorf("%w: non-fast-forward update", git.ErrHistoryRewritten))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Resetting "%s" command to the remote branch...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("ResetToRemote", git.DefaultRemoteName).Return(fmt.Errorf("%w: cli.json", git.ErrLocalChanges))
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: `Unable to reset to the remote branch, command "echo" has uncommitted changes [local-changes]` + "\nCause: worktree has local changes: cli.json",
+		},
+		"update to latest stable tag": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "channel": "stable", "commands": ["echo"], "binaries": [],
+  "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0", "v1.2.0-beta.1"}, nil).Once()
+				m.gitRepo.On("CheckoutTag", "v1.1.0").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"pin to a version range": {
+			args: []string{"--version", "^1.0", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0", "v2.0.0"}, nil).Once()
+				m.gitRepo.On("CheckoutTag", "v1.1.0").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				pkg := packageUpdateChannels()["cli-echo"]
+				require.NotNil(t, pkg)
+				assert.Equal(t, "^1.0", pkg.Pinned)
+				assert.Equal(t, "main", pkg.Branch)
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"pinned package stays on its version": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "pinned": "v1.0.0", "branch": "main", "commands": ["echo"], "binaries": [],
+  "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("FetchTags", git.DefaultRemoteName).Return([]string{"v1.0.0", "v1.1.0"}, nil).Once()
+				m.gitRepo.On("CheckoutTag", "v1.0.0").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("command \"echo\" already up-to-date, pinned to version v1.0.0")}).Return(0, nil).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"remove the pin": {
+			args: []string{"--version", "latest", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "pinned": "v1.0.0", "branch": "main", "commands": ["echo"], "binaries": [],
+  "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "main").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{0}, plumbing.Hash{1}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				pkg := packageUpdateChannels()["cli-echo"]
+				require.NotNil(t, pkg)
+				assert.Empty(t, pkg.Pinned)
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"locked package is not updated": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "pinned": "0100000000000000000000000000000000000000", "locked": "0100000000000000000000000000000000000000",
+  "branch": "main", "commands": ["echo"], "binaries": [], "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+			withError: `Package "cli-echo" is locked to commit 0100000, run "commands.test update --unlock echo" to update it`,
+		},
+		"unlock a locked package": {
+			args: []string{"--unlock", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "pinned": "0100000000000000000000000000000000000000", "locked": "0100000000000000000000000000000000000000",
+  "branch": "main", "commands": ["echo"], "binaries": [], "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "main").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{2}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{1}, plumbing.Hash{2}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				pkg := packageUpdateChannels()["cli-echo"]
+				require.NotNil(t, pkg)
+				assert.Empty(t, pkg.Pinned)
+				assert.Empty(t, pkg.Locked)
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"follow the branch chosen in the channel setting": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, ioutil.WriteFile("testdata/.akamai-cli/packages.json", []byte(`{"version": 1, "packages": {"cli-echo": {
+  "name": "cli-echo", "installed": true, "branch": "beta", "commands": ["echo"], "binaries": [],
+  "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`), 0644))
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "beta").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("beta"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{2}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{1}, plumbing.Hash{2}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove("testdata/.akamai-cli/packages.json"))
+			},
+		},
+		"detached package returns to the default branch": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{1}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("DefaultBranch", git.DefaultRemoteName).Return("main", nil).Once()
+				m.gitRepo.On("CheckoutBranch", git.DefaultRemoteName, "main").Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{2}).Return(&object.Commit{}, nil).Once()
+				m.gitRepo.On("Changes", plumbing.Hash{1}, plumbing.Hash{2}).Return(&git.Changes{Commits: 1, FilesChanged: []string{"README.md"}}, nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			},
+		},
+		"version of several commands": {
+			args:      []string{"--version", "v1.0.0", "echo", "echo-python"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "--version can only be used when updating a single command",
 		},
 		"error getting HEAD of repository before pull": {
 			args: []string{"echo-invalid-json"},
@@ -217,10 +707,94 @@ func TestCmdUpdate(t *testing.T) {
 			},
 			withError: "unable to update, there an issue with the package repo: oops",
 		},
+		"continue with remaining packages on error": {
+			args: []string{"--continue-on-error", "not-found", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("WriteError", commandNotFound("not-found").Error()).Return().Once()
+
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("command \"echo\" already up-to-date")}).Return(0, nil).Once()
+
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpdate Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", mock.Anything).Return().Once()
+			},
+			withError: "Update failed for: not-found",
+		},
+		"continue with remaining packages by default": {
+			args: []string{"not-found", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("WriteError", commandNotFound("not-found").Error()).Return().Once()
+
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Twice()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("WarnOK").Return().Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("command \"echo\" already up-to-date")}).Return(0, nil).Once()
+
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpdate Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", []interface{}{"" +
+					"  COMMAND    VERSION  COMMIT  COMMITS  FILES  DEPENDENCIES  TIME  STATUS\n" +
+					"  not-found                   0        0      -             1.5s  failed\n" +
+					"  echo                        0        0      -             1.5s  up-to-date\n" +
+					"\n  0 updated, 1 up-to-date, 1 failed\n"}).Return().Once()
+			},
+			withError: "Update failed for: not-found",
+		},
+		"update packages in parallel": {
+			args: []string{"--concurrency", "2", "echo", "not-found", "e", "echo-python"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("WriteError", commandNotFound("not-found").Error()).Return().Once()
+
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term)
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo").Return(nil).Twice()
+				m.gitRepo.On("Open", "testdata/.akamai-cli/src/cli-echo-python").Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Times(3)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Times(6)
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Times(3)
+				m.gitRepo.On("Pull", worktree).Return(nil).Times(3)
+				m.term.On("WarnOK").Return().Times(3)
+				for _, cmd := range []string{"echo", "e", "echo-python"} {
+					m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{cmd}).Return().Once()
+					m.term.On("Writeln", []interface{}{color.CyanString("command \"%s\" already up-to-date", cmd)}).Return(0, nil).Once()
+				}
+
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpdate Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", []interface{}{"" +
+					"  COMMAND      VERSION  COMMIT  COMMITS  FILES  DEPENDENCIES  TIME  STATUS\n" +
+					"  echo                          0        0      -             1.5s  up-to-date\n" +
+					"  not-found                     0        0      -             1.5s  failed\n" +
+					"  e                             0        0      -             1.5s  up-to-date\n" +
+					"  echo-python                   0        0      -             1.5s  up-to-date\n" +
+					"\n  0 updated, 3 up-to-date, 1 failed\n"}).Return().Once()
+			},
+			withError: "Update failed for: not-found",
+		},
+		"invalid concurrency": {
+			args:      []string{"--concurrency", "0"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "--concurrency has to be at least 1",
+		},
 		"error finding executable": {
 			args:      []string{"not-found"},
 			init:      func(t *testing.T, m *mocked) {},
-			withError: fmt.Sprintf("Command \"not-found\" not found. Try \"%s help\".\n", tools.Self()),
+			withError: commandNotFound("not-found").Error(),
 		},
 	}
 
@@ -228,16 +802,38 @@ func TestCmdUpdate(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				assert.Equal(t, "/akamai/cli-test-command/releases/download/1.0.0/akamai-app-1-cmd-1", r.URL.String())
-				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Contains(t, []string{http.MethodHead, http.MethodGet}, r.Method)
 				_, err := w.Write([]byte(`binary content`))
 				assert.NoError(t, err)
 			}))
 			defer srv.Close()
 			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			newGitRepository = func() git.Repository { return m.gitRepo }
+			defer func() { newGitRepository = git.NewRepository }()
 			command := &cli.Command{
 				Name:   "update",
 				Action: cmdUpdate(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "json",
+					},
+					&cli.BoolFlag{
+						Name: "continue-on-error",
+					},
+					&cli.IntFlag{
+						Name: "concurrency",
+					},
+					&cli.BoolFlag{
+						Name: "reset",
+					},
+					&cli.BoolFlag{
+						Name: "unlock",
+					},
+					&cli.StringFlag{
+						Name: "version",
+					},
+				},
 			}
 			app, ctx := setupTestApp(command, m)
 			app.Commands = append(app.Commands, &cli.Command{
@@ -253,8 +849,10 @@ func TestCmdUpdate(t *testing.T) {
 			if test.teardown != nil {
 				test.teardown(t)
 			}
+			_ = os.Remove(filepath.Join("./testdata/.akamai-cli/src/cli-echo", installRecordFile))
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
@@ -264,3 +862,42 @@ func TestCmdUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiresReinstall(t *testing.T) {
+	tests := map[string]struct {
+		requirements packages.LanguageRequirements
+		files        []string
+		expected     bool
+	}{
+		"documentation changes": {
+			requirements: packages.LanguageRequirements{Go: "1.14.0"},
+			files:        []string{"README.md", "LICENSE"},
+			expected:     false,
+		},
+		"lockfile changed": {
+			requirements: packages.LanguageRequirements{Node: "7.0.0"},
+			files:        []string{"README.md", "package-lock.json"},
+			expected:     true,
+		},
+		"go source changed": {
+			requirements: packages.LanguageRequirements{Go: "1.14.0"},
+			files:        []string{"pkg/cmd/main.go"},
+			expected:     true,
+		},
+		"vendored dependency changed": {
+			requirements: packages.LanguageRequirements{Go: "1.14.0"},
+			files:        []string{"vendor/modules.txt"},
+			expected:     true,
+		},
+		"python source changed": {
+			requirements: packages.LanguageRequirements{Python: "3.0.0"},
+			files:        []string{"bin/akamai-echo", "echo/lib.py"},
+			expected:     false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, requiresReinstall(test.requirements, test.files))
+		})
+	}
+}
@@ -35,6 +35,7 @@ func cmdUpgrade(c *cli.Context) error {
 	defer func() {
 		logger.Debugf("UPGRADE FINISH: %v", time.Now().Sub(start))
 	}()
+	c.Context = withGitHubToken(c.Context)
 	term := terminal.Get(c.Context)
 
 	term.Spinner().Start("Checking for upgrades...")
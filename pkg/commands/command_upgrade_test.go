@@ -1,3 +1,5 @@
+//+build !noautoupgrade
+
 package commands
 
 import (
@@ -23,6 +25,7 @@ func TestCmdUpgrade(t *testing.T) {
 	tests := map[string]struct {
 		args              []string
 		respLatestVersion string
+		respPatch         string
 		init              func(*mocked)
 		expectedExitCode  int
 		withError         string
@@ -114,18 +117,57 @@ func TestCmdUpgrade(t *testing.T) {
 			},
 			expectedExitCode: 1,
 		},
+		"invalid binary patch, full release downloaded": {
+			args:              []string{"cli.testKey", "testValue"},
+			respLatestVersion: "10.0.0",
+			respPatch:         "not a patch",
+			init: func(m *mocked) {
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Checking for upgrades...", []interface{}(nil)).Return().Once()
+
+				// Checking if cli should be upgraded
+				m.term.On("IsTTY").Return(true).Once()
+				m.cfg.On("GetValue", "cli", "last-upgrade-check").Return("2021-02-10T11:55:26+01:00", true).Once()
+				m.cfg.On("SetValue", "cli", "last-upgrade-check", mock.AnythingOfType("string")).Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Stop", terminal.SpinnerStatusOK).Return().Once()
+				m.term.On("Confirm", fmt.Sprintf("New upgrade found: 10.0.0 (you are running: %s). Upgrade now? [Y/n]: ", version.Version), true).Return(true, nil).Once()
+
+				// start upgrade
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Upgrading Akamai CLI", []interface{}(nil)).Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true)
+			},
+			expectedExitCode: 1,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			var binDownloaded bool
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				url := r.URL.String()
 				if url == "/releases/latest" {
 					w.Header().Set("Location", test.respLatestVersion)
 					w.WriteHeader(http.StatusFound)
 				} else if binURLRegexp.MatchString(url) {
+					binDownloaded = true
 					_, err := w.Write([]byte("binary file"))
 					require.NoError(t, err)
+				} else if strings.HasSuffix(url, fmt.Sprintf(".from-%s.bsdiff", version.Version)) {
+					if test.respPatch == "" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, err := w.Write([]byte(test.respPatch))
+					require.NoError(t, err)
 				} else if strings.HasSuffix(url, ".sig") {
 					// a valid SHA256 checksum for "binary file" string
 					_, err := w.Write([]byte("9a3924b98ad3ce5e51d2c84a7129054c2523f39643a6ea27f8118511ecd4cdba"))
@@ -162,6 +204,7 @@ func TestCmdUpgrade(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
+			assert.True(t, binDownloaded)
 		})
 	}
 }
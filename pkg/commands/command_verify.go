@@ -0,0 +1,237 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// binary verification statuses
+const (
+	binaryStatusVerified   = "verified"
+	binaryStatusUnverified = "unverified"
+	binaryStatusModified   = "modified"
+	binaryStatusMismatch   = "checksum mismatch"
+	binaryStatusSignature  = "invalid signature"
+	binaryStatusMissing    = "missing"
+	binaryStatusFailed     = "failed"
+	binaryStatusNone       = "no downloaded binaries"
+)
+
+// binaryVerification is the result of checking a downloaded binary against the checksums and signature in cli.json
+type binaryVerification struct {
+	Package string `json:"package"`
+	Binary  string `json:"binary,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+func cmdVerify(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("VERIFY START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("VERIFY FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("VERIFY ERROR: %v", e.Error())
+			}
+		}()
+		asJSON := jsonOutput(c)
+		printResults := jsonResults(c)
+
+		dirs := make([]string, 0)
+		if c.Args().Present() {
+			store, err := loadPackageStore(c.Context)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+			}
+			store.migrateInstalled(c.Context, "")
+			srcPath, err := tools.GetAkamaiCliSrcPath()
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+			}
+			for _, name := range c.Args().Slice() {
+				pkgName, ok := installedPackageName(c, langManager, store, name)
+				if !ok {
+					return packageNotFound(name)
+				}
+				dirs = append(dirs, filepath.Join(srcPath, pkgName))
+			}
+		} else {
+			for _, dir := range getPackagePaths() {
+				if fileExists(filepath.Join(dir, "cli.json")) {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+
+		results := make([]binaryVerification, 0)
+		for _, dir := range dirs {
+			results = append(results, verifyPackageBinaries(c.Context, dir)...)
+		}
+		var err error
+		if asJSON {
+			err = printResults(results)
+		} else {
+			err = printVerifySummary(terminal.Get(c.Context), results)
+		}
+		if err != nil {
+			return err
+		}
+
+		var failed []string
+		for _, res := range results {
+			switch res.Status {
+			case binaryStatusVerified, binaryStatusUnverified, binaryStatusNone:
+			default:
+				if res.Binary == "" {
+					failed = append(failed, res.Package)
+				} else {
+					failed = append(failed, res.Binary)
+				}
+			}
+		}
+		if len(failed) > 0 {
+			return cli.Exit(color.RedString("Verification failed for: %s", strings.Join(failed, ", ")), 1)
+		}
+		return nil
+	}
+}
+
+// verifyPackageBinaries checks the downloaded binaries of the package in given directory against its cli.json.
+// Binaries of packages which publish neither checksums nor a signature are compared with their hash recorded at download.
+func verifyPackageBinaries(ctx context.Context, dir string) []binaryVerification {
+	pkgName := filepath.Base(dir)
+	binaries, err := readBinaryRecord(dir)
+	if err != nil {
+		return []binaryVerification{{Package: pkgName, Status: binaryStatusFailed, Error: err.Error()}}
+	}
+	if len(binaries) == 0 {
+		return []binaryVerification{{Package: pkgName, Status: binaryStatusNone}}
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return []binaryVerification{{Package: pkgName, Status: binaryStatusFailed, Error: err.Error()}}
+	}
+
+	names := make([]string, 0, len(binaries))
+	for name := range binaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]binaryVerification, 0, len(names))
+	for _, name := range names {
+		binary := binaries[name]
+		cmd := command{Name: name}
+		for _, c := range pkg.Commands {
+			if c.Name == name {
+				cmd = c
+			}
+		}
+		res := verifyBinary(ctx, dir, withPlatform(cmd), binary)
+		res.Package = pkgName
+		results = append(results, res)
+	}
+	return results
+}
+
+func verifyBinary(ctx context.Context, dir string, cmd command, binary downloadedBinary) binaryVerification {
+	res := binaryVerification{Binary: filepath.Base(filepath.FromSlash(binary.Path))}
+	path := filepath.Join(dir, filepath.FromSlash(binary.Path))
+	hash, err := fileHash(path)
+	if os.IsNotExist(err) {
+		res.Status = binaryStatusMissing
+		return res
+	}
+	if err != nil {
+		res.Status, res.Error = binaryStatusFailed, err.Error()
+		return res
+	}
+
+	sum, err := binaryChecksum(cmd, binary.Platform)
+	if err != nil {
+		res.Status, res.Error = binaryStatusMismatch, err.Error()
+		return res
+	}
+	if sum != nil && hex.EncodeToString(sum) != hash {
+		res.Status, res.Error = binaryStatusMismatch, fmt.Sprintf("expected %x, got %s", sum, hash)
+		return res
+	}
+	if cmd.Signature != nil {
+		if err := verifyBinarySignature(ctx, dir, *cmd.Signature, cmd, binary.URL, path); err != nil {
+			res.Status, res.Error = binaryStatusFailed, err.Error()
+			if errors.Is(err, download.ErrSignature) {
+				res.Status = binaryStatusSignature
+			}
+			return res
+		}
+	}
+
+	switch {
+	case sum != nil || cmd.Signature != nil:
+		res.Status = binaryStatusVerified
+	case hash != binary.SHA256:
+		res.Status = binaryStatusModified
+	default:
+		res.Status = binaryStatusUnverified
+	}
+	return res
+}
+
+func printVerifySummary(term terminal.Terminal, results []binaryVerification) error {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tBINARY\tSTATUS")
+	for _, res := range results {
+		binary := res.Binary
+		if binary == "" {
+			binary = "-"
+		}
+		status := res.Status
+		if res.Error != "" {
+			status = fmt.Sprintf("%s: %s", res.Status, res.Error)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", res.Package, binary, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("\nVerification Summary:\n"))
+	term.Printf("%s", buf.String())
+	return nil
+}
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type signedBinary struct {
+	content   []byte
+	checksum  string
+	key       string
+	signature string
+}
+
+func newSignedBinary(t *testing.T, content string) signedBinary {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(content))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(t, err)
+	return signedBinary{
+		content:   []byte(content),
+		checksum:  hex.EncodeToString(digest[:]),
+		key:       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		signature: base64.StdEncoding.EncodeToString(sig),
+	}
+}
+
+func TestDownloadBinVerification(t *testing.T) {
+	bin := newSignedBinary(t, "binary content")
+	other := newSignedBinary(t, "other content")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".sig"):
+			_, _ = w.Write([]byte(bin.signature))
+		case strings.HasSuffix(r.URL.Path, "/other.sig.txt"):
+			_, _ = w.Write([]byte(other.signature))
+		default:
+			_, _ = w.Write(bin.content)
+		}
+	}))
+	defer srv.Close()
+	platform := binaryPlatform(withPlatform(command{}))
+
+	tests := map[string]struct {
+		cmd        command
+		skipVerify bool
+		expected   *downloadedBinary
+		withError  string
+	}{
+		"matching checksum": {
+			cmd:      command{Checksums: map[string]string{platform: bin.checksum}},
+			expected: &downloadedBinary{Verified: true},
+		},
+		"checksum mismatch": {
+			cmd:       command{Checksums: map[string]string{platform: other.checksum}},
+			withError: "unable to fetch command binary: checksum mismatch",
+		},
+		"no checksum for the platform": {
+			cmd:       command{Checksums: map[string]string{"plan9-mips": bin.checksum}},
+			withError: fmt.Sprintf("no checksum of the dns binary is published for %s", platform),
+		},
+		"invalid checksum": {
+			cmd:       command{Checksums: map[string]string{platform: "abc"}},
+			withError: "invalid checksum of the dns binary",
+		},
+		"no checksums published": {
+			expected: &downloadedBinary{},
+		},
+		"verification skipped": {
+			cmd:        command{Checksums: map[string]string{platform: other.checksum}},
+			skipVerify: true,
+			expected:   &downloadedBinary{},
+		},
+		"valid signature": {
+			cmd:      command{Checksums: map[string]string{platform: bin.checksum}, Signature: &binarySignature{Type: download.SignatureCosign, Key: bin.key}},
+			expected: &downloadedBinary{Verified: true, Signature: download.SignatureCosign},
+		},
+		"signature key in package": {
+			cmd:      command{Signature: &binarySignature{Type: download.SignatureCosign, Key: "keys/release.pub"}},
+			expected: &downloadedBinary{Verified: true, Signature: download.SignatureCosign},
+		},
+		"signature of another binary": {
+			cmd:       command{Signature: &binarySignature{Type: download.SignatureCosign, URL: srv.URL + "/other.sig.txt", Key: bin.key}},
+			withError: "unable to verify signature of command binary: invalid signature",
+		},
+		"signature key outside of the package": {
+			cmd:       command{Signature: &binarySignature{Type: download.SignatureCosign, Key: "../release.pub"}},
+			withError: "signature key ../release.pub is outside of the package",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-verify")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "keys"), 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "keys", "release.pub"), []byte(bin.key), 0644))
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false)
+			cfg.On("GetValue", "cli", "proxy").Return("", false)
+			ctx := withSkipVerify(config.Context(context.Background(), cfg), test.skipVerify)
+
+			cmd := test.cmd
+			cmd.Name = "dns"
+			cmd.Bin = srv.URL + "/akamai-{{.Name}}-{{.OS}}{{.Arch}}{{.BinSuffix}}"
+			err = downloadBin(ctx, filepath.Join(dir, "bin"), cmd)
+			binaries, recordErr := readBinaryRecord(dir)
+			require.NoError(t, recordErr)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.Empty(t, binaries)
+				assert.False(t, fileExists(filepath.Join(dir, "bin", "akamai-dns"+withPlatform(cmd).BinSuffix)))
+				return
+			}
+			require.NoError(t, err)
+			expected := *test.expected
+			expected.Path = "bin/akamai-dns" + withPlatform(cmd).BinSuffix
+			expected.URL = fmt.Sprintf("%s/akamai-dns-%s%s%s", srv.URL, withPlatform(cmd).OS, withPlatform(cmd).Arch, withPlatform(cmd).BinSuffix)
+			expected.Platform = platform
+			expected.SHA256 = bin.checksum
+			assert.Equal(t, map[string]downloadedBinary{"dns": expected}, binaries)
+		})
+	}
+}
+
+func TestCmdVerify(t *testing.T) {
+	bin := newSignedBinary(t, "binary content")
+	other := newSignedBinary(t, "other content")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bin.signature))
+	}))
+	defer srv.Close()
+	platform := binaryPlatform(withPlatform(command{}))
+	binaryName := "akamai-dns" + withPlatform(command{}).BinSuffix
+
+	tests := map[string]struct {
+		command   map[string]interface{}
+		content   []byte
+		args      []string
+		noRecord  bool
+		expected  string
+		withError string
+	}{
+		"checksum matches": {
+			command:  map[string]interface{}{"checksums": map[string]string{platform: bin.checksum}},
+			content:  bin.content,
+			expected: "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  verified\n",
+		},
+		"binary replaced": {
+			command:   map[string]interface{}{"checksums": map[string]string{platform: bin.checksum}},
+			content:   other.content,
+			expected:  fmt.Sprintf("  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  checksum mismatch: expected %s, got %s\n", bin.checksum, other.checksum),
+			withError: "Verification failed for: " + binaryName,
+		},
+		"signature matches": {
+			command:  map[string]interface{}{"signature": map[string]string{"type": "cosign", "key": bin.key}},
+			content:  bin.content,
+			expected: "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  verified\n",
+		},
+		"signature does not match": {
+			command:   map[string]interface{}{"signature": map[string]string{"type": "cosign", "key": bin.key}},
+			content:   other.content,
+			expected:  "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  invalid signature: invalid signature: signature does not match the key\n",
+			withError: "Verification failed for: " + binaryName,
+		},
+		"nothing published, binary unchanged": {
+			content:  bin.content,
+			args:     []string{"cli-dns"},
+			expected: "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  unverified\n",
+		},
+		"nothing published, binary changed": {
+			content:   other.content,
+			args:      []string{"cli-dns"},
+			expected:  "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  modified\n",
+			withError: "Verification failed for: " + binaryName,
+		},
+		"binary missing": {
+			command:   map[string]interface{}{"checksums": map[string]string{platform: bin.checksum}},
+			expected:  "  PACKAGE  BINARY      STATUS\n  cli-dns  akamai-dns  missing\n",
+			withError: "Verification failed for: " + binaryName,
+		},
+		"built from source": {
+			noRecord: true,
+			expected: "  PACKAGE  BINARY  STATUS\n  cli-dns  -       no downloaded binaries\n",
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			withError: `Package "cli-missing" not found.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			cmd := map[string]interface{}{"name": "dns", "version": "1.0.0", "bin": srv.URL + "/akamai-dns"}
+			for key, val := range test.command {
+				cmd[key] = val
+			}
+			cliJSON, err := json.Marshal(map[string]interface{}{"commands": []interface{}{cmd}})
+			require.NoError(t, err)
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", string(cliJSON))
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+			if test.content != nil {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", binaryName), test.content, 0755))
+			}
+			if !test.noRecord {
+				recordDownloadedBinary(context.Background(), dir, "dns", downloadedBinary{
+					Path:     "bin/" + binaryName,
+					URL:      srv.URL + "/akamai-dns",
+					Platform: platform,
+					SHA256:   bin.checksum,
+				})
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false).Maybe()
+			m.cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			if test.expected != "" {
+				m.term.On("Writeln", []interface{}{color.YellowString("\nVerification Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", []interface{}{test.expected}).Return().Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{Name: "verify", Action: cmdVerify(m.langManager)}, m)
+
+			err = app.RunContext(ctx, append(append(os.Args[0:1], "verify"), test.args...))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/app"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// completeWith returns a BashComplete function printing the values returned by given function
+// in addition to the flags and subcommands completed by app.DefaultAutoComplete
+func completeWith(values func(c *cli.Context) []string) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		term := terminal.Get(c.Context)
+		for _, value := range values(c) {
+			term.Writeln(value)
+		}
+		app.DefaultAutoComplete(c)
+	}
+}
+
+// registryPackageNames returns the names of packages published in the package registry which are not installed yet
+func registryPackageNames(c *cli.Context) []string {
+	list, err := fetchCachedPackageList(c.Context)
+	if err != nil {
+		return nil
+	}
+	installed := make(map[string]bool)
+	for _, name := range installedCommandNames(c) {
+		installed[name] = true
+	}
+	names := make([]string, 0, len(list.Packages))
+	for _, pkg := range list.Packages {
+		provided := len(pkg.Commands) > 0
+		for _, cmd := range pkg.Commands {
+			provided = provided && installed[strings.ToLower(cmd.Name)]
+		}
+		if !provided {
+			names = append(names, pkg.Name)
+		}
+	}
+	return names
+}
+
+// installedCommandNames returns the names of commands provided by installed packages
+func installedCommandNames(c *cli.Context) []string {
+	names := make([]string, 0)
+	for _, cmd := range c.App.Commands {
+		if !cmd.Hidden && cmd.Category == color.YellowString("Installed Commands:") {
+			names = append(names, cmd.Name)
+		}
+	}
+	return names
+}
+
+// configSettingNames returns all settings stored in config in <section>.<key> format
+func configSettingNames(c *cli.Context) []string {
+	names := make([]string, 0)
+	for section, keys := range config.Get(c.Context).Values() {
+		for key := range keys {
+			names = append(names, section+"."+key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configSectionNames returns the names of non-empty config sections
+func configSectionNames(c *cli.Context) []string {
+	names := make([]string, 0)
+	for section, keys := range config.Get(c.Context).Values() {
+		if len(keys) > 0 {
+			names = append(names, section)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completingFlag returns true if the value of one of given flags is being completed,
+// that is, the flag is the last argument before the completion flag
+func completingFlag(args []string, names ...string) bool {
+	if len(args) > 0 && args[len(args)-1] == "--"+cli.BashCompletionFlag.Names()[0] {
+		args = args[:len(args)-1]
+	}
+	if len(args) == 0 {
+		return false
+	}
+	for _, name := range names {
+		if args[len(args)-1] == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// completeEdgercSection prints the sections of the .edgerc file if the value of --section flag of an installed
+// command is being completed. It returns false if another value is completed.
+func completeEdgercSection(c *cli.Context) bool {
+	if !completingFlag(os.Args, "section") {
+		return false
+	}
+	term := terminal.Get(c.Context)
+	for _, section := range edgercSections(os.Args) {
+		term.Writeln(section)
+	}
+	return true
+}
+
+// edgercSections returns the section names of the .edgerc file used by an installed command,
+// resolved from the --edgerc argument, AKAMAI_EDGERC environment variable or the default location
+func edgercSections(args []string) []string {
+	path, _ := dispatchEdgerc(args, nil)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	values, err := config.ParseValues(data)
+	if err != nil {
+		return nil
+	}
+	sections := make([]string, 0, len(values))
+	for section, keys := range values {
+		if len(keys) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+	return sections
+}
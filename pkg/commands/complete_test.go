@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEdgercSections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edgerc")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	edgerc := filepath.Join(dir, ".edgerc")
+	require.NoError(t, ioutil.WriteFile(edgerc, []byte("[default]\nhost = a\n\n[papi]\nhost = b\n"), 0600))
+
+	tests := map[string]struct {
+		args     []string
+		env      string
+		expected []string
+	}{
+		"edgerc from argument": {
+			args:     []string{"akamai", "property", "--edgerc", edgerc, "--section", "--generate-auto-complete"},
+			expected: []string{"default", "papi"},
+		},
+		"edgerc from argument with equals sign": {
+			args:     []string{"akamai", "property", "--edgerc=" + edgerc, "--section"},
+			expected: []string{"default", "papi"},
+		},
+		"edgerc from environment": {
+			args:     []string{"akamai", "property", "--section"},
+			env:      edgerc,
+			expected: []string{"default", "papi"},
+		},
+		"edgerc does not exist": {
+			args: []string{"akamai", "property", "--edgerc", filepath.Join(dir, "missing"), "--section"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_EDGERC", test.env))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_EDGERC"))
+			}()
+			sections := edgercSections(test.args)
+			if test.expected == nil {
+				assert.Empty(t, sections)
+				return
+			}
+			assert.Equal(t, test.expected, sections)
+		})
+	}
+}
+
+func TestCompletingFlag(t *testing.T) {
+	completion := "--" + cli.BashCompletionFlag.Names()[0]
+	assert.True(t, completingFlag([]string{"akamai", "property", "--section", completion}, "section"))
+	assert.True(t, completingFlag([]string{"akamai", "property", "--section"}, "section"))
+	assert.False(t, completingFlag([]string{"akamai", "property", "--section", "papi", completion}, "section"))
+	assert.False(t, completingFlag([]string{completion}, "section"))
+}
+
+func TestCompletionValues(t *testing.T) {
+	cfg := &config.Mock{}
+	cfg.On("Values").Return(map[string]map[string]string{
+		"DEFAULT": {},
+		"cli":     {"last-ping": "never", "cache-path": "/tmp"},
+		"custom":  {"key": "value"},
+	})
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{
+		{Name: "list"},
+		{Name: "property", Category: color.YellowString("Installed Commands:")},
+		{Name: "cli-property:property", Category: color.YellowString("Installed Commands:"), Hidden: true},
+	}
+	c := cli.NewContext(app, nil, nil)
+	c.Context = config.Context(context.Background(), cfg)
+
+	assert.Equal(t, []string{"cli.cache-path", "cli.last-ping", "custom.key"}, configSettingNames(c))
+	assert.Equal(t, []string{"cli", "custom"}, configSectionNames(c))
+	assert.Equal(t, []string{"property"}, installedCommandNames(c))
+}
@@ -0,0 +1,74 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/urfave/cli/v2"
+)
+
+// commandConflicts returns warnings about names of package commands which are ambiguous.
+// A name or alias used by more than one command runs the first command registered in the app,
+// and an executable with the same name as the command executable found on PATH runs instead of the package one when called directly.
+func commandConflicts(registered []*cli.Command, packageDir string, pkg subcommands) []string {
+	warnings := make([]string, 0)
+	packageName := filepath.Base(packageDir)
+	for _, cmd := range pkg.Commands {
+		name := strings.ToLower(cmd.Name)
+		for _, n := range append([]string{name}, cmd.Aliases...) {
+			order := make([]string, 0)
+			for _, c := range registered {
+				if !c.Hidden && c.HasName(n) {
+					order = append(order, c.Name)
+				}
+			}
+			if len(order) < 2 {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("Warning: \"%s\" of command \"%s\" is also used by another command, \"%s %s\" runs \"%s\". Resolution order: %s.",
+				n, name, tools.Self(), n, order[0], strings.Join(order, ", ")))
+		}
+
+		for _, executable := range executableNamesOf(name) {
+			path, err := exec.LookPath(executable)
+			if err != nil {
+				continue
+			}
+			if abs, err := filepath.Abs(path); err == nil && strings.HasPrefix(abs, packageDir+string(os.PathSeparator)) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("Warning: executable \"%s\" found on PATH at %s. \"%s %s\" runs the command from %s, while \"%s\" runs %s.",
+				executable, path, tools.Self(), name, packageName, executable, path))
+			break
+		}
+	}
+	return warnings
+}
+
+// executableNamesOf returns both executable names of a command, e.g. akamai-command-name and akamaiCommandName
+func executableNamesOf(cmd string) []string {
+	cmdName, cmdNameTitle := executableNames(cmd)
+	if cmdName == cmdNameTitle {
+		return []string{cmdName}
+	}
+	return []string{cmdName, cmdNameTitle}
+}
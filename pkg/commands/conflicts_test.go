@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCommandConflicts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires executable files without extension")
+	}
+	dir, err := ioutil.TempDir("", "cli-conflicts")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	binDir := filepath.Join(dir, "bin")
+	packageDir := filepath.Join(dir, "cli-dns")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "akamai-dns"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(packageDir, "bin", "akamai-zone"), []byte("#!/bin/sh\n"), 0755))
+
+	systemPath := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", binDir+string(os.PathListSeparator)+filepath.Join(packageDir, "bin")))
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", systemPath))
+	}()
+
+	registered := []*cli.Command{
+		{Name: "diagnose", Aliases: []string{"d"}},
+		{Name: "dns", Aliases: []string{"d"}},
+		{Name: "help", Aliases: []string{"h"}},
+		{Name: "cli-dns:dns", Hidden: true},
+		{Name: "zone", Aliases: []string{"h"}},
+	}
+	pkg := subcommands{Commands: []command{
+		{Name: "dns", Aliases: []string{"d"}},
+		{Name: "zone", Aliases: []string{"h"}},
+	}}
+
+	assert.Equal(t, []string{
+		fmt.Sprintf(`Warning: "d" of command "dns" is also used by another command, "%s d" runs "diagnose". Resolution order: diagnose, dns.`, tools.Self()),
+		fmt.Sprintf(`Warning: executable "akamai-dns" found on PATH at %s. "%s dns" runs the command from cli-dns, while "akamai-dns" runs %s.`,
+			filepath.Join(binDir, "akamai-dns"), tools.Self(), filepath.Join(binDir, "akamai-dns")),
+		fmt.Sprintf(`Warning: "h" of command "zone" is also used by another command, "%s h" runs "help". Resolution order: help, zone.`, tools.Self()),
+	}, commandConflicts(registered, packageDir, pkg))
+}
@@ -5,7 +5,5 @@ import (
 )
 
 const (
-	alreadyUptoDate  = "already up-to-date"
-	objectNotFound   = "object not found"
 	sleepTime24Hours = time.Hour * 24
 )
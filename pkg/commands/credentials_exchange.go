@@ -0,0 +1,162 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/edgegrid"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+)
+
+// credentialsTTLKey is the cli setting limiting the lifetime of the short-lived credentials given to each command
+const credentialsTTLKey = "credentials-ttl"
+
+// defaultCredentialsTTL is the lifetime of short-lived credentials requested when cli.credentials-ttl is not set
+const defaultCredentialsTTL = 15 * time.Minute
+
+// credentialsExpiresEnv is the environment variable telling commands when their short-lived credentials expire
+const credentialsExpiresEnv = "AKAMAI_CREDENTIALS_EXPIRES"
+
+// exchangeClient sends the requests exchanging credentials, it is a variable so that tests can replace it
+var exchangeClient = &http.Client{Timeout: 30 * time.Second}
+
+// longLivedEnvSuffixes are the suffixes of the environment variables EdgeGrid libraries read credentials from,
+// such as AKAMAI_CLIENT_SECRET or AKAMAI_PAPI_CLIENT_SECRET. They are removed from the environment of commands
+// given short-lived credentials.
+var longLivedEnvSuffixes = []string{"_CLIENT_SECRET", "_CLIENT_TOKEN", "_ACCESS_TOKEN"}
+
+// dispatchEdgerc returns the .edgerc file and section an installed command uses, resolved from its --edgerc and
+// --section arguments, the AKAMAI_EDGERC and AKAMAI_EDGERC_SECTION variables of env, set for the command,
+// or of the environment, or the defaults
+func dispatchEdgerc(args []string, env map[string]string) (string, string) {
+	path, section := os.Getenv("AKAMAI_EDGERC"), os.Getenv("AKAMAI_EDGERC_SECTION")
+	if val, ok := env["AKAMAI_EDGERC"]; ok {
+		path = val
+	}
+	if val, ok := env["AKAMAI_EDGERC_SECTION"]; ok {
+		section = val
+	}
+	for i, arg := range args {
+		switch {
+		case arg == "--edgerc" && i+1 < len(args):
+			path = args[i+1]
+		case strings.HasPrefix(arg, "--edgerc="):
+			path = strings.TrimPrefix(arg, "--edgerc=")
+		case arg == "--section" && i+1 < len(args):
+			section = args[i+1]
+		case strings.HasPrefix(arg, "--section="):
+			section = strings.TrimPrefix(arg, "--section=")
+		}
+	}
+	if path == "" {
+		path = plugin.DefaultEdgerc
+	}
+	if section == "" {
+		section = plugin.DefaultSection
+	}
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path, section
+}
+
+// withEphemeralCredentials exchanges the credentials of the .edgerc section used by the command for short-lived
+// ones, if the section declares an exchange_url. The command is then given an .edgerc file holding only the
+// short-lived credentials, in its --edgerc argument and AKAMAI_EDGERC, so that the long-lived secret is never
+// passed to it. The returned function removes the file once the command exits.
+// Sections without exchange_url are left for the command to read, as before.
+func withEphemeralCredentials(ctx context.Context, d *packageDispatch) (func(), error) {
+	logger := log.FromContext(ctx)
+	path, section := dispatchEdgerc(d.Args, d.Env)
+	creds, err := edgegrid.Load(path, section)
+	if err != nil {
+		logger.Debugf("Credentials not exchanged: %s", err)
+		return func() {}, nil
+	}
+	if !creds.CanExchange() {
+		return func() {}, nil
+	}
+
+	ttl := stepTimeout(ctx, credentialsTTLKey)
+	if ttl == 0 {
+		ttl = defaultCredentialsTTL
+	}
+	short, err := creds.Exchange(ctx, exchangeClient, ttl)
+	if err != nil {
+		return nil, credentialExchangeFailure(path, section, err)
+	}
+	dir, err := ioutil.TempDir("", "akamai-credentials")
+	if err != nil {
+		return nil, credentialExchangeFailure(path, section, err)
+	}
+	release := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warnf("Unable to remove short-lived credentials: %s", err)
+		}
+	}
+	shortPath := filepath.Join(dir, ".edgerc")
+	if err := short.Save(shortPath, section); err != nil {
+		release()
+		return nil, credentialExchangeFailure(path, section, err)
+	}
+	logger.Debugf("Credentials of section %s exchanged, valid until %s", section, short.Expires.Format(time.RFC3339))
+
+	for i, arg := range d.Args {
+		if arg == "--edgerc" && i+1 < len(d.Args) {
+			d.Args[i+1] = shortPath
+		} else if strings.HasPrefix(arg, "--edgerc=") {
+			d.Args[i] = "--edgerc=" + shortPath
+		}
+	}
+	d.Env["AKAMAI_EDGERC"] = shortPath
+	d.Env["AKAMAI_EDGERC_SECTION"] = section
+	d.Env[credentialsExpiresEnv] = short.Expires.UTC().Format(time.RFC3339)
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if !strings.HasPrefix(strings.ToUpper(name), "AKAMAI_") {
+			continue
+		}
+		for _, suffix := range longLivedEnvSuffixes {
+			if strings.HasSuffix(strings.ToUpper(name), suffix) {
+				if err := os.Unsetenv(name); err != nil {
+					release()
+					return nil, err
+				}
+			}
+		}
+	}
+	return release, nil
+}
+
+// credentialExchangeFailure is the failure of exchanging the credentials of a section, commands are not run
+// with the long-lived credentials instead
+func credentialExchangeFailure(path, section string, err error) error {
+	f := newFailure(failureCredentialExchange, failureData{"Edgerc": path, "Section": section}, err)
+	if errors.Is(err, edgegrid.ErrExchange) {
+		f.exitCode = plugin.ExitAuth
+	}
+	return f
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/edgegrid"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithEphemeralCredentials(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exchange" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"client_token": "short-token", "client_secret": "short-secret", "access_token": "short-access", "expires_in": 300}`))
+	}))
+	defer srv.Close()
+	client := exchangeClient
+	exchangeClient = srv.Client()
+	defer func() {
+		exchangeClient = client
+	}()
+	edgerc := fmt.Sprintf(`[default]
+host = akab-host.luna.akamaiapis.net
+client_token = long-token
+client_secret = long-secret
+access_token = long-access
+
+[papi]
+host = akab-papi.luna.akamaiapis.net
+client_token = long-token
+client_secret = long-secret
+access_token = long-access
+exchange_url = %[1]s/exchange
+
+[refused]
+host = akab-papi.luna.akamaiapis.net
+client_token = long-token
+client_secret = long-secret
+access_token = long-access
+exchange_url = %[1]s/refused
+`, srv.URL)
+
+	tests := map[string]struct {
+		args       []string
+		envSection string
+		exchanged  bool
+		withError  string
+	}{
+		"section without exchange_url": {
+			args: []string{"akamai-papi", "--edgerc", "{{edgerc}}", "list"},
+		},
+		"short-lived credentials": {
+			args:      []string{"akamai-papi", "--edgerc", "{{edgerc}}", "--section", "papi", "list"},
+			exchanged: true,
+		},
+		"section in the environment": {
+			args:       []string{"akamai-papi", "--edgerc={{edgerc}}", "list"},
+			envSection: "papi",
+			exchanged:  true,
+		},
+		"exchange refused": {
+			args:      []string{"akamai-papi", "--edgerc", "{{edgerc}}", "--section=refused", "list"},
+			withError: "Unable to get short-lived credentials for section refused of {{edgerc}} [credential-exchange-failed]",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-credentials")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			path := filepath.Join(dir, ".edgerc")
+			require.NoError(t, ioutil.WriteFile(path, []byte(edgerc), 0600))
+			args := make([]string, 0, len(test.args))
+			for _, arg := range test.args {
+				args = append(args, strings.ReplaceAll(arg, "{{edgerc}}", path))
+			}
+			require.NoError(t, os.Setenv("AKAMAI_EDGERC_SECTION", test.envSection))
+			require.NoError(t, os.Setenv("AKAMAI_PAPI_CLIENT_SECRET", "long-secret"))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_EDGERC_SECTION"))
+				require.NoError(t, os.Unsetenv("AKAMAI_PAPI_CLIENT_SECRET"))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", credentialsTTLKey).Return("", false).Maybe()
+			ctx := config.Context(context.Background(), cfg)
+			d := &packageDispatch{Args: append([]string{}, args...), Env: map[string]string{}}
+
+			release, err := withEphemeralCredentials(ctx, d)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), strings.ReplaceAll(test.withError, "{{edgerc}}", path))
+				assert.True(t, errors.Is(err, edgegrid.ErrExchange))
+				var f *failure
+				require.True(t, errors.As(err, &f))
+				assert.Equal(t, plugin.ExitAuth, f.ExitCode())
+				return
+			}
+			require.NoError(t, err)
+			if !test.exchanged {
+				release()
+				assert.Equal(t, args, d.Args)
+				assert.Empty(t, d.Env)
+				assert.Equal(t, "long-secret", os.Getenv("AKAMAI_PAPI_CLIENT_SECRET"))
+				return
+			}
+			shortPath := d.Env["AKAMAI_EDGERC"]
+			assert.NotEqual(t, path, shortPath)
+			assert.NotContains(t, d.Args, path)
+			assert.Contains(t, strings.Join(d.Args, " "), shortPath)
+			assert.Equal(t, "papi", d.Env["AKAMAI_EDGERC_SECTION"])
+			assert.NotEmpty(t, d.Env[credentialsExpiresEnv])
+			assert.Empty(t, os.Getenv("AKAMAI_PAPI_CLIENT_SECRET"))
+			data, err := ioutil.ReadFile(shortPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "short-secret")
+			assert.NotContains(t, string(data), "long-secret")
+
+			release()
+			assert.False(t, fileExists(shortPath))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// packageDependencies maps names of installed packages to the names of packages they depend on
+type packageDependencies map[string][]string
+
+// dependencyName returns the name of the package directory a dependency declared in cli.json is installed to
+func dependencyName(dep string) string {
+	return packageDirName(tools.Githubize(dep))
+}
+
+// packageInstalled returns true if a package with given name is present in the packages directory
+func packageInstalled(name string) bool {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(srcPath, name))
+	return err == nil
+}
+
+// installedDependencies reads the dependencies of all installed packages
+func installedDependencies() packageDependencies {
+	deps := make(packageDependencies)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(dir)
+		deps[name] = make([]string, 0, len(pkg.Dependencies))
+		for _, dep := range pkg.Dependencies {
+			deps[name] = append(deps[name], dependencyName(dep))
+		}
+	}
+	return deps
+}
+
+// dependents returns the sorted names of installed packages depending on given package
+func (d packageDependencies) dependents(name string) []string {
+	res := make([]string, 0)
+	for pkg, deps := range d {
+		for _, dep := range deps {
+			if strings.EqualFold(dep, name) && pkg != name {
+				res = append(res, pkg)
+				break
+			}
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// names returns the sorted names of installed packages
+func (d packageDependencies) names() []string {
+	names := make([]string, 0, len(d))
+	for pkg := range d {
+		names = append(names, pkg)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// roots returns the sorted names of installed packages no other installed package depends on.
+// Packages which are only part of a dependency cycle are included as well, so that every package is reachable.
+func (d packageDependencies) roots() []string {
+	res := make([]string, 0)
+	for pkg := range d {
+		if len(d.dependents(pkg)) == 0 {
+			res = append(res, pkg)
+		}
+	}
+	reachable := make(map[string]bool)
+	var visit func(string)
+	visit = func(pkg string) {
+		if reachable[pkg] {
+			return
+		}
+		reachable[pkg] = true
+		for _, dep := range d[pkg] {
+			visit(dep)
+		}
+	}
+	for _, pkg := range res {
+		visit(pkg)
+	}
+	for _, pkg := range d.names() {
+		if !reachable[pkg] {
+			res = append(res, pkg)
+			visit(pkg)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// renderTree returns the dependency tree of given package, one line per package.
+// Dependencies which are not installed and those closing a cycle are marked instead of being expanded.
+func (d packageDependencies) renderTree(name string) []string {
+	lines := []string{"  " + name}
+	path := map[string]bool{name: true}
+	var walk func(pkg, indent string)
+	walk = func(pkg, indent string) {
+		deps := d[pkg]
+		for i, dep := range deps {
+			branch, next := "├── ", "│   "
+			if i == len(deps)-1 {
+				branch, next = "└── ", "    "
+			}
+			line := indent + branch + dep
+			_, installed := d[dep]
+			switch {
+			case path[dep]:
+				lines = append(lines, line+" (cycle)")
+			case !installed:
+				lines = append(lines, line+" (not installed)")
+			default:
+				lines = append(lines, line)
+				path[dep] = true
+				walk(dep, indent+next)
+				delete(path, dep)
+			}
+		}
+	}
+	walk(name, "  ")
+	return lines
+}
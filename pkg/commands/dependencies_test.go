@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPackageDependencies(t *testing.T) {
+	deps := packageDependencies{
+		"cli-reporting": {"cli-property", "cli-missing"},
+		"cli-property":  {"cli-common"},
+		"cli-common":    {},
+		"cli-a":         {"cli-b"},
+		"cli-b":         {"cli-a"},
+	}
+
+	assert.Equal(t, []string{"cli-property"}, deps.dependents("cli-common"))
+	assert.Equal(t, []string{}, deps.dependents("cli-reporting"))
+	assert.Equal(t, []string{"cli-a", "cli-reporting"}, deps.roots())
+	assert.Equal(t, []string{
+		"  cli-reporting",
+		"  ├── cli-property",
+		"  │   └── cli-common",
+		"  └── cli-missing (not installed)",
+	}, deps.renderTree("cli-reporting"))
+	assert.Equal(t, []string{
+		"  cli-a",
+		"  └── cli-b",
+		"      └── cli-a (cycle)",
+	}, deps.renderTree("cli-a"))
+}
+
+func TestDependencyName(t *testing.T) {
+	tests := map[string]string{
+		"property":            "cli-property",
+		"akamai/cli-property": "cli-property",
+		"https://github.com/example/cli-report.git":  "cli-report",
+		"git@github.com:example/cli-report-plus.git": "cli-report-plus",
+	}
+	for dep, expected := range tests {
+		t.Run(dep, func(t *testing.T) {
+			assert.Equal(t, expected, dependencyName(dep))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/version"
+	"github.com/akamai/cli/pkg/warnings"
+)
+
+// deprecationsKey is the cli setting choosing what happens when a deprecated command or flag is used
+const deprecationsKey = "deprecations"
+
+// behaviors on the use of deprecated commands and flags
+const (
+	// deprecationsWarn prints a warning and runs the command, this is the default
+	deprecationsWarn = "warn"
+	// deprecationsError fails the command, to find uses of deprecated features in scripts before they are removed
+	deprecationsError = "error"
+	// deprecationsOff runs the command silently
+	deprecationsOff = "off"
+)
+
+// deprecation marks a built-in command, or a flag of it, to be removed in a later version
+type deprecation struct {
+	// Command is the full name of the command, such as "config export"
+	Command string
+	// Flag is the deprecated flag of the command, the whole command is deprecated if it is empty
+	Flag string
+	// Since is the version deprecating the command or flag
+	Since string
+	// RemovedIn is the version removing the command or flag. From this version on, using it fails whatever
+	// the deprecations setting, until the code is deleted.
+	RemovedIn string
+	// Replacement tells what to do instead
+	Replacement string
+}
+
+// deprecations lists the deprecated built-in commands and flags. Entries are removed with the code of the
+// command or flag, once the removal version is released.
+var deprecations = []deprecation{
+	{
+		Command:     "update",
+		Flag:        "continue-on-error",
+		Since:       "1.2.1",
+		RemovedIn:   "2.0.0",
+		Replacement: "failures no longer stop the update of the remaining packages, remove the flag",
+	},
+}
+
+// name returns how the deprecated command or flag is shown to the user
+func (d deprecation) name() string {
+	if d.Flag == "" {
+		return fmt.Sprintf("%q", d.Command)
+	}
+	return fmt.Sprintf("--%s of %q", d.Flag, d.Command)
+}
+
+// removed reports whether the version of Akamai CLI is the removal version or later
+func (d deprecation) removed() bool {
+	if d.RemovedIn == "" {
+		return false
+	}
+	cmp := version.Compare(d.RemovedIn, version.Version)
+	return cmp == 0 || cmp == 1
+}
+
+// note describes the deprecation in help texts
+func (d deprecation) note() string {
+	note := "deprecated"
+	if d.RemovedIn != "" {
+		note += ", to be removed in " + d.RemovedIn
+	}
+	if d.Replacement != "" {
+		note += ": " + d.Replacement
+	}
+	return note
+}
+
+// applyDeprecations marks the deprecated commands and flags in their help, and checks before running a
+// command that none of them is used
+func applyDeprecations(commands []*cli.Command, list []deprecation) {
+	walkCommands(commands, "", func(cmd *cli.Command, fullName string) {
+		matching := make([]deprecation, 0)
+		for _, d := range list {
+			if d.Command != fullName {
+				continue
+			}
+			matching = append(matching, d)
+			if d.Flag == "" {
+				cmd.Description = fmt.Sprintf("%s (%s)", cmd.Description, d.note())
+				continue
+			}
+			for _, f := range cmd.Flags {
+				if containsFold(f.Names(), d.Flag) {
+					deprecateFlagUsage(f, d.note())
+				}
+			}
+		}
+		if len(matching) == 0 {
+			return
+		}
+		before := cmd.Before
+		cmd.Before = func(c *cli.Context) error {
+			if err := checkDeprecations(c, matching); err != nil {
+				return err
+			}
+			if before != nil {
+				return before(c)
+			}
+			return nil
+		}
+	})
+}
+
+// walkCommands calls fn with each command and subcommand, and its name prefixed with the names of its parents
+func walkCommands(commands []*cli.Command, parent string, fn func(*cli.Command, string)) {
+	for _, cmd := range commands {
+		fullName := strings.TrimSpace(parent + " " + cmd.Name)
+		fn(cmd, fullName)
+		walkCommands(cmd.Subcommands, fullName, fn)
+	}
+}
+
+// checkDeprecations warns about, or fails on, the use of deprecated commands and flags, depending on the
+// deprecations setting. Uses are reported with the statistics, if they are enabled.
+func checkDeprecations(c *cli.Context, list []deprecation) error {
+	logger := log.FromContext(c.Context)
+	mode := deprecationsMode(c)
+	for _, d := range list {
+		if d.Flag != "" && !c.IsSet(d.Flag) {
+			continue
+		}
+		stats.TrackEvent(c.Context, "deprecated", d.Command, d.Flag)
+		if d.removed() || mode == deprecationsError {
+			return newFailure(failureDeprecated, failureData{
+				"Name":        d.name(),
+				"Removed":     d.removed(),
+				"RemovedIn":   d.RemovedIn,
+				"Replacement": d.Replacement,
+			}, nil)
+		}
+		msg := fmt.Sprintf("%s is deprecated since %s and will be removed", d.name(), d.Since)
+		if d.RemovedIn != "" {
+			msg += " in " + d.RemovedIn
+		}
+		if d.Replacement != "" {
+			msg += ": " + d.Replacement
+		}
+		if mode == deprecationsOff {
+			logger.Warn(msg)
+			continue
+		}
+		warnings.Warn(c.Context, "deprecated "+d.Command+" "+d.Flag, "Warning: "+msg)
+	}
+	return nil
+}
+
+// deprecationsMode returns the configured behavior on deprecations, warnings for unknown values
+func deprecationsMode(c *cli.Context) string {
+	val, _ := config.Get(c.Context).GetValue("cli", deprecationsKey)
+	switch mode := strings.ToLower(strings.TrimSpace(val)); mode {
+	case deprecationsError, deprecationsOff:
+		return mode
+	case "", deprecationsWarn:
+	default:
+		log.FromContext(c.Context).Warnf("Invalid value of cli.%s: %s", deprecationsKey, val)
+	}
+	return deprecationsWarn
+}
+
+// deprecateFlagUsage appends the deprecation note to the usage of flags of the types built-in commands use
+func deprecateFlagUsage(f cli.Flag, note string) {
+	switch flag := f.(type) {
+	case *cli.BoolFlag:
+		flag.Usage = fmt.Sprintf("%s (%s)", flag.Usage, note)
+	case *cli.StringFlag:
+		flag.Usage = fmt.Sprintf("%s (%s)", flag.Usage, note)
+	case *cli.StringSliceFlag:
+		flag.Usage = fmt.Sprintf("%s (%s)", flag.Usage, note)
+	case *cli.IntFlag:
+		flag.Usage = fmt.Sprintf("%s (%s)", flag.Usage, note)
+	case *cli.DurationFlag:
+		flag.Usage = fmt.Sprintf("%s (%s)", flag.Usage, note)
+	}
+}
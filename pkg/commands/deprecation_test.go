@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"os"
+	"testing"
+)
+
+func TestApplyDeprecations(t *testing.T) {
+	list := []deprecation{
+		{Command: "sync", Flag: "legacy", Since: "1.1.0", RemovedIn: "3.0.0", Replacement: "use --mode instead"},
+		{Command: "sync", Flag: "old", Since: "1.0.0", RemovedIn: "1.2.0"},
+		{Command: "sync remote", Since: "1.1.0", Replacement: "use \"sync --remote\" instead"},
+	}
+	tests := map[string]struct {
+		args      []string
+		mode      string
+		warning   string
+		withError string
+	}{
+		"deprecated flag not used": {
+			args: []string{"sync"},
+		},
+		"deprecated flag": {
+			args:    []string{"sync", "--legacy"},
+			warning: `Warning: --legacy of "sync" is deprecated since 1.1.0 and will be removed in 3.0.0: use --mode instead`,
+		},
+		"deprecated subcommand": {
+			args:    []string{"sync", "remote"},
+			warning: `Warning: "sync remote" is deprecated since 1.1.0 and will be removed: use "sync --remote" instead`,
+		},
+		"deprecations off": {
+			args: []string{"sync", "--legacy"},
+			mode: deprecationsOff,
+		},
+		"deprecations fail": {
+			args:      []string{"sync", "--legacy"},
+			mode:      deprecationsError,
+			withError: `--legacy of "sync" is deprecated and will be removed in 3.0.0 [deprecated]`,
+		},
+		"removal version released": {
+			args:      []string{"sync", "--old"},
+			mode:      deprecationsOff,
+			withError: `--old of "sync" was removed in Akamai CLI 1.2.0 [deprecated]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ran bool
+			action := func(c *cli.Context) error {
+				ran = true
+				return nil
+			}
+			cmd := &cli.Command{
+				Name:        "sync",
+				Description: "Synchronize",
+				Action:      action,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "legacy", Usage: "Legacy mode"},
+					&cli.BoolFlag{Name: "old"},
+				},
+				Subcommands: []*cli.Command{{Name: "remote", Description: "Synchronize remotely", Action: action}},
+			}
+			applyDeprecations([]*cli.Command{cmd}, list)
+			assert.Equal(t, "Legacy mode (deprecated, to be removed in 3.0.0: use --mode instead)", cmd.Flags[0].(*cli.BoolFlag).Usage)
+			assert.Equal(t, `Synchronize remotely (deprecated: use "sync --remote" instead)`, cmd.Subcommands[0].Description)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m.cfg.On("GetValue", "cli", deprecationsKey).Return(test.mode, test.mode != "")
+			m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Maybe()
+			out := &bytes.Buffer{}
+			m.term.On("Error").Return(out).Maybe()
+			app, ctx := setupTestApp(cmd, m)
+
+			err := app.RunContext(ctx, append(os.Args[0:1], test.args...))
+			if test.warning != "" {
+				assert.Equal(t, color.YellowString(test.warning)+"\n", out.String())
+			} else {
+				assert.Empty(t, out.String())
+			}
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.False(t, ran)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, ran)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+)
+
+// diagnostic statuses, problems prevent the package from running
+const (
+	diagnosticOK      = "ok"
+	diagnosticWarning = "warning"
+	diagnosticProblem = "problem"
+)
+
+// checks run on installed packages
+const (
+	checkManifest     = "manifest"
+	checkRuntime      = "runtime"
+	checkDependencies = "dependencies"
+	checkRepository   = "repository"
+	checkExecutables  = "executables"
+)
+
+// repairs of the problems found, applied by "akamai doctor --fix"
+const (
+	repairReinstall   = "reinstall dependencies"
+	repairReclone     = "re-clone"
+	repairPermissions = "make executable"
+)
+
+// diagnostic is the result of a single check of an installed package
+type diagnostic struct {
+	Package string `json:"package"`
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Repair  string `json:"repair,omitempty"`
+	path    string
+}
+
+// diagnosePackage checks that the package installed in dir can run: its cli.json is valid, the runtime it requires
+// is installed in the right version, its dependencies and executables are in place and its repository is a valid
+// worktree on the ref it is expected to follow
+func diagnosePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, dir string) []diagnostic {
+	name := filepath.Base(dir)
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return []diagnostic{{Package: name, Check: checkManifest, Status: diagnosticProblem, Message: err.Error(), Repair: repairReclone}}
+	}
+	results := []diagnostic{{Package: name, Check: checkManifest, Status: diagnosticOK, Message: fmt.Sprintf("%d commands", len(pkg.Commands))}}
+
+	runtimeDiagnostic, runtimeVersion := diagnoseRuntime(ctx, langManager, pkg)
+	results = append(results, runtimeDiagnostic)
+	results = append(results, diagnoseDependencies(dir, pkg, runtimeVersion)...)
+	results = append(results, diagnoseExecutables(dir, pkg)...)
+	results = append(results, diagnoseRepository(gitRepo, dir, packageUpdateChannels()[name])...)
+	for i := range results {
+		results[i].Package = name
+	}
+	return results
+}
+
+// diagnoseRuntime checks the runtime required by the package and returns its version, if it is found
+func diagnoseRuntime(ctx context.Context, langManager packages.LangManager, pkg subcommands) (diagnostic, string) {
+	if packages.Language(pkg.Requirements) == packages.Undefined {
+		return diagnostic{Check: checkRuntime, Status: diagnosticOK, Message: "no runtime required"}, ""
+	}
+	version, err := langManager.CheckRuntime(ctx, pkg.Requirements)
+	if err != nil {
+		// the runtime has to be installed or upgraded by the user, no repair is offered
+		return diagnostic{Check: checkRuntime, Status: diagnosticProblem, Message: err.Error()}, version
+	}
+	message := fmt.Sprintf("%s %s", packages.Language(pkg.Requirements), version)
+	if version == "" {
+		message = fmt.Sprintf("%s, version unknown", packages.Language(pkg.Requirements))
+	}
+	return diagnostic{Check: checkRuntime, Status: diagnosticOK, Message: message}, version
+}
+
+// diagnoseDependencies checks that the dependencies of the package were installed, for the runtime version found
+// if it is known: Python dependencies are installed in the package directory for a single minor version
+func diagnoseDependencies(dir string, pkg subcommands, runtimeVersion string) []diagnostic {
+	missing := diagnostic{Check: checkDependencies, Status: diagnosticProblem, Message: "dependencies are not installed", Repair: repairReinstall}
+	switch packages.Language(pkg.Requirements) {
+	case packages.Javascript:
+		if fileExists(filepath.Join(dir, "package.json")) && !fileExists(filepath.Join(dir, "node_modules")) {
+			return []diagnostic{missing}
+		}
+	case packages.PHP:
+		if fileExists(filepath.Join(dir, "composer.json")) && !fileExists(filepath.Join(dir, "vendor")) {
+			return []diagnostic{missing}
+		}
+	case packages.Python:
+		if !fileExists(filepath.Join(dir, "requirements.txt")) {
+			break
+		}
+		sitePackages := pythonSitePackages(dir)
+		if len(sitePackages) == 0 {
+			return []diagnostic{missing}
+		}
+		if minor := minorVersion(runtimeVersion); minor != "" {
+			if _, ok := sitePackages[minor]; !ok {
+				installed := make([]string, 0, len(sitePackages))
+				for version := range sitePackages {
+					installed = append(installed, version)
+				}
+				missing.Message = fmt.Sprintf("dependencies were installed for python %s, the runtime is python %s", strings.Join(installed, ", "), runtimeVersion)
+				return []diagnostic{missing}
+			}
+		}
+	default:
+		return []diagnostic{}
+	}
+	return []diagnostic{{Check: checkDependencies, Status: diagnosticOK, Message: "installed"}}
+}
+
+// pythonSitePackages returns the python versions which dependencies were installed for in the package directory,
+// pip installs them to PYTHONUSERBASE, which is the package directory, in a directory named after the minor version.
+// Versions are empty on Windows, where the directory name does not include dots.
+func pythonSitePackages(dir string) map[string]bool {
+	versions := make(map[string]bool)
+	found, _ := filepath.Glob(filepath.Join(dir, "lib", "python*", "site-packages"))
+	for _, path := range found {
+		versions[strings.TrimPrefix(filepath.Base(filepath.Dir(path)), "python")] = true
+	}
+	if found, _ = filepath.Glob(filepath.Join(dir, "Python*", "site-packages")); len(found) > 0 {
+		versions[""] = true
+	}
+	return versions
+}
+
+// minorVersion returns the major and minor part of version, e.g. 3.8 for 3.8.2, empty if version has no minor part
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// diagnoseExecutables checks that an executable exists for each command of the package. Executables which are not
+// run by an interpreter also need the execute permission, except on Windows.
+func diagnoseExecutables(dir string, pkg subcommands) []diagnostic {
+	results := make([]diagnostic, 0)
+	lang := packages.Language(pkg.Requirements)
+	for _, cmd := range pkg.Commands {
+		executables := commandExecutables(dir, cmd.Name)
+		if len(executables) == 0 {
+			repair := repairReclone
+			if cmd.Bin != "" || lang == packages.Go {
+				// binaries are downloaded or built when dependencies are installed
+				repair = repairReinstall
+			}
+			results = append(results, diagnostic{Check: checkExecutables, Status: diagnosticProblem, Message: fmt.Sprintf("executable for \"%s\" is missing", cmd.Name), Repair: repair})
+			continue
+		}
+		if runtime.GOOS != "windows" && (lang == packages.Go || lang == packages.Undefined) {
+			if info, err := os.Stat(executables[0]); err == nil && info.Mode()&0111 == 0 {
+				results = append(results, diagnostic{Check: checkExecutables, Status: diagnosticProblem, Message: fmt.Sprintf("%s is not executable", relativePath(dir, executables[0])), Repair: repairPermissions, path: executables[0]})
+				continue
+			}
+		}
+		results = append(results, diagnostic{Check: checkExecutables, Status: diagnosticOK, Message: relativePath(dir, executables[0])})
+	}
+	return results
+}
+
+func relativePath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// diagnoseRepository checks that the package repository is a valid worktree, checked out at the ref the package
+// follows according to metadata, which may be nil. Packages fetched from other sources are not git repositories.
+func diagnoseRepository(gitRepo git.Repository, dir string, metadata *packageMetadata) []diagnostic {
+	if record, err := readSourceRecord(dir); err == nil {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticOK, Message: fmt.Sprintf("fetched from %s source at revision %s", record.Source, shortHash(record.Revision))}}
+	}
+	if err := gitRepo.Open(dir); err != nil {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticProblem, Message: fmt.Sprintf("not a valid git repository: %s", err), Repair: repairReclone}}
+	}
+	head, err := gitRepo.Head()
+	if errors.Is(err, git.ErrEmptyRepository) {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticWarning, Message: "the repository has no commits yet"}}
+	}
+	if err != nil {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticProblem, Message: fmt.Sprintf("unable to read HEAD: %s", err), Repair: repairReclone}}
+	}
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticProblem, Message: fmt.Sprintf("invalid worktree: %s", err), Repair: repairReclone}}
+	}
+	dirty, err := worktreeDirty(worktree)
+	if err != nil {
+		return []diagnostic{{Check: checkRepository, Status: diagnosticProblem, Message: fmt.Sprintf("invalid worktree: %s", err), Repair: repairReclone}}
+	}
+
+	current := fmt.Sprintf("at commit %s", shortHash(head.Hash().String()))
+	if head.Name().IsBranch() {
+		current = fmt.Sprintf("on branch %s at %s", head.Name().Short(), shortHash(head.Hash().String()))
+	}
+	results := make([]diagnostic, 0)
+	switch {
+	case metadata != nil && metadata.Locked != "" && head.Hash().String() != metadata.Locked:
+		results = append(results, diagnostic{Check: checkRepository, Status: diagnosticWarning, Message: fmt.Sprintf("%s, but locked to %s", current, shortHash(metadata.Locked))})
+	case metadata != nil && metadata.Locked == "" && metadata.Pinned == "" && metadata.channel() == channelBranch &&
+		metadata.Branch != "" && head.Name() != plumbing.NewBranchReferenceName(metadata.Branch):
+		results = append(results, diagnostic{Check: checkRepository, Status: diagnosticWarning, Message: fmt.Sprintf("%s, but follows branch %s", current, metadata.Branch)})
+	default:
+		results = append(results, diagnostic{Check: checkRepository, Status: diagnosticOK, Message: current})
+	}
+	if dirty {
+		results = append(results, diagnostic{Check: checkRepository, Status: diagnosticWarning, Message: "package files were modified locally"})
+	}
+	return results
+}
+
+// dependenciesMissing returns true if the dependencies of the package in dir are known not to be installed
+func dependenciesMissing(dir string, pkg subcommands) bool {
+	for _, d := range diagnoseDependencies(dir, pkg, "") {
+		if d.Status != diagnosticOK {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnoseDependencies(t *testing.T) {
+	python := packages.LanguageRequirements{Python: "3.0.0"}
+	tests := map[string]struct {
+		files          []string
+		reqs           packages.LanguageRequirements
+		runtimeVersion string
+		expected       diagnostic
+	}{
+		"python dependencies installed": {
+			files:          []string{"requirements.txt", "lib/python3.8/site-packages/dep.py"},
+			reqs:           python,
+			runtimeVersion: "3.8.2",
+			expected:       diagnostic{Check: checkDependencies, Status: diagnosticOK, Message: "installed"},
+		},
+		"python dependencies of another version": {
+			files:          []string{"requirements.txt", "lib/python3.6/site-packages/dep.py"},
+			reqs:           python,
+			runtimeVersion: "3.8.2",
+			expected:       diagnostic{Check: checkDependencies, Status: diagnosticProblem, Message: "dependencies were installed for python 3.6, the runtime is python 3.8.2", Repair: repairReinstall},
+		},
+		"python dependencies, runtime unknown": {
+			files:    []string{"requirements.txt", "lib/python3.6/site-packages/dep.py"},
+			reqs:     python,
+			expected: diagnostic{Check: checkDependencies, Status: diagnosticOK, Message: "installed"},
+		},
+		"python dependencies not installed": {
+			files:          []string{"requirements.txt"},
+			reqs:           python,
+			runtimeVersion: "3.8.2",
+			expected:       diagnostic{Check: checkDependencies, Status: diagnosticProblem, Message: "dependencies are not installed", Repair: repairReinstall},
+		},
+		"python package without requirements": {
+			reqs:     python,
+			expected: diagnostic{Check: checkDependencies, Status: diagnosticOK, Message: "installed"},
+		},
+		"php dependencies not installed": {
+			files:    []string{"composer.json"},
+			reqs:     packages.LanguageRequirements{Php: "7.0.0"},
+			expected: diagnostic{Check: checkDependencies, Status: diagnosticProblem, Message: "dependencies are not installed", Repair: repairReinstall},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-diagnostics")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			for _, file := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("test"), 0644))
+			}
+
+			results := diagnoseDependencies(dir, subcommands{Requirements: test.reqs}, test.runtimeVersion)
+			assert.Equal(t, []diagnostic{test.expected}, results)
+		})
+	}
+}
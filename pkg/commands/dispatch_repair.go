@@ -0,0 +1,139 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// autoRepairKey is the config key deciding what happens when the package of a command about to run is broken:
+// "true" repairs it without asking and "false" never repairs it. If not set, the user is asked when run in a terminal.
+const autoRepairKey = "auto-repair"
+
+// dispatchPackageDir returns the directory of the package which runs the command name, empty if no package provides it
+func dispatchPackageDir(ctx context.Context, name string) string {
+	packageName, commandName := splitCommandName(strings.ToLower(name))
+	if packageName == "" {
+		packageName = commandOwner(ctx, commandName)
+	}
+	if packageName == "" {
+		owners := commandPackages(commandName)
+		if len(owners) == 0 {
+			return ""
+		}
+		packageName = owners[0]
+	}
+	if strings.ContainsAny(packageName, `/\`) || packageName == ".." {
+		return ""
+	}
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(srcPath, packageName)
+}
+
+// dispatchProblems returns the problems found in the package in dir which prevent its commands from running.
+// Only the files of the package are checked, unless withRuntime is set: checking the runtime runs the interpreter,
+// which would slow down every command.
+func dispatchProblems(ctx context.Context, langManager packages.LangManager, dir string, withRuntime bool) []diagnostic {
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil
+	}
+	var runtimeVersion string
+	diagnostics := make([]diagnostic, 0)
+	if withRuntime {
+		var runtimeDiagnostic diagnostic
+		runtimeDiagnostic, runtimeVersion = diagnoseRuntime(ctx, langManager, pkg)
+		diagnostics = append(diagnostics, runtimeDiagnostic)
+	}
+	diagnostics = append(diagnostics, diagnoseDependencies(dir, pkg, runtimeVersion)...)
+	diagnostics = append(diagnostics, diagnoseExecutables(dir, pkg)...)
+
+	problems := make([]diagnostic, 0)
+	for _, d := range diagnostics {
+		if d.Status == diagnosticProblem {
+			problems = append(problems, d)
+		}
+	}
+	return problems
+}
+
+// repairBeforeDispatch checks the package of the command name before it runs and, if problems are found, repairs it
+// the way "akamai doctor --fix" does, after asking the user unless the auto-repair setting says otherwise.
+// It returns true if the package was repaired, and a failure describing the problems if they were found but not repaired.
+func repairBeforeDispatch(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager, name string, withRuntime bool) (bool, error) {
+	logger := log.FromContext(c.Context)
+	term := terminal.Get(c.Context)
+	dir := dispatchPackageDir(c.Context, name)
+	if dir == "" {
+		return false, nil
+	}
+	problems := dispatchProblems(c.Context, langManager, dir, withRuntime)
+	if len(problems) == 0 {
+		return false, nil
+	}
+	pkgName := filepath.Base(dir)
+	messages := make([]string, 0, len(problems))
+	for _, d := range problems {
+		messages = append(messages, d.Message)
+	}
+	broken := failureData{"Package": pkgName, "Problems": strings.Join(messages, "; ")}
+	logger.Warnf("Package %s cannot run: %s", pkgName, broken["Problems"])
+	if !repairable(problems) {
+		return false, newFailure(failurePackageBroken, broken, nil)
+	}
+
+	val, _ := config.Get(c.Context).GetValue("cli", autoRepairKey)
+	switch strings.TrimSpace(val) {
+	case "true":
+	case "false":
+		return false, newFailure(failurePackageBroken, broken, nil)
+	default:
+		if !term.IsTTY() {
+			return false, newFailure(failurePackageBroken, broken, nil)
+		}
+		term.Writeln(color.YellowString("Package %s cannot run: %s", pkgName, broken["Problems"]))
+		answer, err := term.Confirm("Would you like to repair it now? [Y/n]: ", true)
+		if err != nil {
+			return false, err
+		}
+		if !answer {
+			return false, newFailure(failurePackageBroken, broken, nil)
+		}
+	}
+
+	spinner := term.Spinner()
+	spinner.Start("Repairing package %s...", pkgName)
+	if err := repairPackage(c.Context, gitRepo, langManager, dir, problems); err != nil {
+		spinner.Fail()
+		return false, newFailure(failurePackageBroken, broken, err)
+	}
+	spinner.OK()
+	return true, nil
+}
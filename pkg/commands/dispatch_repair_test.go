@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRepairBeforeDispatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables do not need execute permission on Windows")
+	}
+	tests := map[string]struct {
+		autoRepair string
+		tty        bool
+		answer     bool
+		healthy    bool
+		repaired   bool
+		withError  string
+	}{
+		"healthy package":          {healthy: true},
+		"repaired automatically":   {autoRepair: "true", repaired: true},
+		"repair disabled":          {autoRepair: "false", withError: "Package cli-dns cannot run: bin/akamai-dns is not executable [package-broken]"},
+		"repaired after prompt":    {tty: true, answer: true, repaired: true},
+		"repair declined":          {tty: true, withError: "[package-broken]"},
+		"not run in a terminal":    {withError: "[package-broken]"},
+		"command of other package": {healthy: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns"}]}`)
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+			mode := os.FileMode(0644)
+			if test.healthy {
+				mode = 0755
+			}
+			exec := filepath.Join(dir, "bin", "akamai-dns")
+			require.NoError(t, ioutil.WriteFile(exec, []byte("#!/bin/sh\n"), mode))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", autoRepairKey).Return(test.autoRepair, test.autoRepair != "").Maybe()
+			m.cfg.On("GetValue", commandOwnerSection, "dns").Return("", false).Maybe()
+			if !test.healthy && test.autoRepair == "" {
+				m.term.On("IsTTY").Return(test.tty).Once()
+			}
+			if test.tty {
+				m.term.On("Writeln", []interface{}{"Package cli-dns cannot run: bin/akamai-dns is not executable"}).Return(0, nil).Once()
+				m.term.On("Confirm", "Would you like to repair it now? [Y/n]: ", true).Return(test.answer, nil).Once()
+			}
+			if test.repaired {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Repairing package %s...", []interface{}{"cli-dns"}).Return().Once()
+				m.term.On("OK").Return().Once()
+			}
+			ctx := terminal.Context(config.Context(context.Background(), m.cfg), m.term)
+			c := cli.NewContext(cli.NewApp(), flag.NewFlagSet("", flag.ContinueOnError), nil)
+			c.Context = ctx
+
+			command := "dns"
+			if name == "command of other package" {
+				command = "purge"
+			}
+			repaired, err := repairBeforeDispatch(c, m.gitRepo, m.langManager, command, false)
+			m.term.AssertExpectations(t)
+			assert.Equal(t, test.repaired, repaired)
+			info, statErr := os.Stat(exec)
+			require.NoError(t, statErr)
+			if test.withError != "" {
+				require.Error(t, err)
+				var f *failure
+				assert.True(t, errors.As(err, &f))
+				assert.Contains(t, err.Error(), test.withError)
+				assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+				return
+			}
+			require.NoError(t, err)
+			assert.NotZero(t, info.Mode()&0111)
+		})
+	}
+}
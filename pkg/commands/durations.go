@@ -0,0 +1,49 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// since returns the time elapsed from start, measured with the monotonic clock.
+// It is a variable so that tests can report fixed durations.
+var since = time.Since
+
+// elapsed is a duration reported in summaries, encoded in JSON as a number of milliseconds
+type elapsed time.Duration
+
+func (d elapsed) String() string {
+	return formatDuration(time.Duration(d))
+}
+
+// MarshalJSON encodes the duration as a number of milliseconds
+func (d elapsed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).Milliseconds())
+}
+
+// formatDuration rounds d to a precision which is meaningful for the user: milliseconds below a second,
+// tenths of a second below a minute and seconds otherwise, e.g. 350ms, 4.2s or 1m42s
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[string]struct {
+		duration time.Duration
+		expected string
+	}{
+		"milliseconds":        {duration: 350*time.Millisecond + 420*time.Microsecond, expected: "350ms"},
+		"tenths of a second":  {duration: 4240 * time.Millisecond, expected: "4.2s"},
+		"minutes and seconds": {duration: 102600 * time.Millisecond, expected: "1m43s"},
+		"zero":                {expected: "0s"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, formatDuration(test.duration))
+		})
+	}
+}
+
+func TestElapsedJSON(t *testing.T) {
+	out, err := json.Marshal(struct {
+		Duration elapsed `json:"durationMs"`
+	}{elapsed(1540*time.Millisecond + 300*time.Microsecond)})
+	require.NoError(t, err)
+	assert.Equal(t, `{"durationMs":1540}`, string(out))
+}
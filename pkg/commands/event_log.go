@@ -0,0 +1,379 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// eventLogKey is the config key which disables the event log when set to "false"
+	eventLogKey = "event-log"
+
+	eventLogFile = "events.jsonl"
+
+	// eventLogMaxSize is the size in bytes after which the event log is rotated
+	eventLogMaxSize = 10 << 20
+
+	// eventLogBackups is the number of rotated event log files kept next to the current one
+	eventLogBackups = 3
+
+	// eventSchema is the version of the event format, increased on incompatible changes only
+	eventSchema = 1
+
+	eventFollowInterval = 500 * time.Millisecond
+)
+
+// events recorded in the event log, next to the package events also posted to the webhook
+const (
+	logEventRun   = "run"
+	logEventError = "error"
+)
+
+// logEvent is a line of the event log. Fields are only ever added to it, so that tools reading the log keep working.
+type logEvent struct {
+	Schema     int       `json:"schema"`
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Package    string    `json:"package,omitempty"`
+	Command    string    `json:"command,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	OldVersion string    `json:"oldVersion,omitempty"`
+	NewVersion string    `json:"newVersion,omitempty"`
+	ExitCode   *int      `json:"exitCode,omitempty"`
+	DurationMS *int64    `json:"durationMs,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CLIVersion string    `json:"cliVersion"`
+	PID        int       `json:"pid"`
+	Trigger    string    `json:"trigger,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// eventLogPath returns the path of the event log in Akamai CLI home
+func eventLogPath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, eventLogFile), nil
+}
+
+// recordEvent appends the event to the event log, unless it is disabled.
+// Failures are only logged, as they should never fail the operation the event describes.
+func recordEvent(ctx context.Context, event logEvent) {
+	if val, ok := config.Get(ctx).GetValue("cli", eventLogKey); ok && strings.TrimSpace(val) == "false" {
+		return
+	}
+	logger := log.FromContext(ctx)
+	path, err := eventLogPath()
+	if err != nil {
+		logger.Debugf("Unable to record event: %s", err)
+		return
+	}
+	event.Schema = eventSchema
+	event.CLIVersion = version.Version
+	event.PID = os.Getpid()
+	if event.Trigger == "" {
+		event.Trigger = updateTrigger(ctx)
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	event.Error = strings.TrimSpace(ansiEscape.ReplaceAllString(event.Error, ""))
+	line, err := json.Marshal(event)
+	if err != nil {
+		logger.Debugf("Unable to encode event: %s", err)
+		return
+	}
+	if err := rotateLog(path, eventLogMaxSize, eventLogBackups); err != nil {
+		logger.Debugf("Unable to rotate event log: %s", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		logger.Debugf("Unable to open event log: %s", err)
+		return
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Debugf("Unable to close event log: %s", err)
+		}
+	}()
+	// a single write of a whole line keeps lines of concurrent processes from interleaving
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logger.Debugf("Unable to record event: %s", err)
+	}
+}
+
+// recordPackageRun records a finished run of a package command with its exit code and duration
+func recordPackageRun(ctx context.Context, packageName, command, version string, start time.Time, err error) {
+	exitCode := 0
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		exitCode = 1
+	}
+	duration := time.Since(start).Milliseconds()
+	event := logEvent{Event: logEventRun, Package: packageName, Command: command, Version: version, ExitCode: &exitCode, DurationMS: &duration}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	recordEvent(ctx, event)
+}
+
+// RecordCommandError records the failure of a command in the event log
+func RecordCommandError(ctx context.Context, command string, err error) {
+	if err == nil {
+		return
+	}
+	exitCode := 1
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		exitCode = exitErr.ExitCode()
+	}
+	recordEvent(ctx, logEvent{Event: logEventError, Command: command, ExitCode: &exitCode, Error: err.Error()})
+}
+
+// parseSince parses the --since flag, either a duration before now such as 2h, an RFC 3339 timestamp or a date
+func parseSince(val string, now time.Time) (time.Time, error) {
+	val = strings.TrimSpace(val)
+	if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", val, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q, expected a duration such as 2h, a date such as 2021-03-01 or an RFC 3339 timestamp", val)
+}
+
+// eventFilter selects events of the event log shown by the events command
+type eventFilter struct {
+	since    time.Time
+	events   []string
+	packages []string
+}
+
+// matches returns true if the line is an event selected by the filter, lines which are not events are skipped
+func (f eventFilter) matches(line []byte) bool {
+	var event logEvent
+	if err := json.Unmarshal(line, &event); err != nil || event.Event == "" {
+		return false
+	}
+	if !f.since.IsZero() && event.Time.Before(f.since) {
+		return false
+	}
+	if len(f.events) > 0 && !containsFold(f.events, event.Event) {
+		return false
+	}
+	if len(f.packages) > 0 && !containsFold(f.packages, event.Package) && !containsFold(f.packages, event.Command) {
+		return false
+	}
+	return true
+}
+
+// readEvents returns the lines of the event log, including its rotated files, matching the filter, oldest first.
+// The size of the current file is returned, so that it can be followed from there.
+func readEvents(path string, filter eventFilter) ([]string, int64, error) {
+	var lines []string
+	var size int64
+	for i := eventLogBackups; i >= 0; i-- {
+		name := path
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", path, i)
+		}
+		f, err := os.Open(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		n, err := scanEvents(f, filter, func(line string) {
+			lines = append(lines, line)
+		})
+		_ = f.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+		if i == 0 {
+			size = n
+		}
+	}
+	return lines, size, nil
+}
+
+// scanEvents passes complete lines read from r which match the filter to fn and returns the number of bytes consumed,
+// a trailing line which is still being written is left for the next read
+func scanEvents(r io.Reader, filter eventFilter, fn func(string)) (int64, error) {
+	reader := bufio.NewReader(r)
+	var consumed int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return consumed, nil
+		}
+		if err != nil {
+			return consumed, err
+		}
+		consumed += int64(len(line))
+		if filter.matches(line) {
+			fn(strings.TrimSpace(string(line)))
+		}
+	}
+}
+
+// followEvents prints events appended to the event log after given offset until the context is canceled
+func followEvents(ctx context.Context, path string, offset int64, filter eventFilter) error {
+	term := terminal.Get(ctx)
+	ticker := time.NewTicker(eventFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			offset = 0
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			// the log was rotated, the new file is read from its start
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return err
+		}
+		n, err := scanEvents(f, filter, func(line string) {
+			term.Printf("%s\n", line)
+		})
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		offset += n
+	}
+}
+
+func eventFilterFromFlags(c *cli.Context) (eventFilter, error) {
+	filter := eventFilter{events: c.StringSlice("event"), packages: c.StringSlice("package")}
+	if since := c.String("since"); since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return filter, cli.Exit(color.RedString(err.Error()), 1)
+		}
+		filter.since = t
+	}
+	return filter, nil
+}
+
+// cmdEvents prints events of the event log, one JSON object per line
+func cmdEvents(c *cli.Context) error {
+	return showEvents(c, 0)
+}
+
+// cmdEventsTail prints the last events of the event log, and with --follow keeps printing new ones
+func cmdEventsTail(c *cli.Context) error {
+	return showEvents(c, c.Int("lines"))
+}
+
+func showEvents(c *cli.Context, last int) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	start := time.Now()
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	logger.Debug("EVENTS START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("EVENTS FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("EVENTS ERROR: %v", e.Error())
+		}
+	}()
+	filter, err := eventFilterFromFlags(c)
+	if err != nil {
+		return err
+	}
+	path, err := eventLogPath()
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to find event log: %s", err), 1)
+	}
+	lines, size, err := readEvents(path, filter)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to read event log: %s", err), 1)
+	}
+	if last > 0 && len(lines) > last {
+		lines = lines[len(lines)-last:]
+	}
+	term := terminal.Get(c.Context)
+	for _, line := range lines {
+		term.Printf("%s\n", line)
+	}
+	if c.Bool("follow") {
+		if err := followEvents(c.Context, path, size, filter); err != nil {
+			return cli.Exit(color.RedString("Unable to read event log: %s", err), 1)
+		}
+	}
+	return nil
+}
+
+// eventFlags returns the flags selecting events, shared by events and events tail
+func eventFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Only print events since a duration ago such as 2h, a date such as 2021-03-01 or an RFC 3339 timestamp",
+		},
+		&cli.StringSliceFlag{
+			Name:  "event",
+			Usage: "Only print events of given type: install, update, uninstall, rollback, run, error or auto-update-skipped, can be specified multiple times",
+		},
+		&cli.StringSliceFlag{
+			Name:  "package",
+			Usage: "Only print events of given package or command, can be specified multiple times",
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordEvent(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", eventLogKey).Return("", false).Times(3)
+	cfg.On("GetValue", "cli", webhookKey).Return("", false).Once()
+	ctx := config.Context(context.Background(), cfg)
+
+	notifyPackageEvent(ctx, packageEventInstall, "/home/user/.akamai-cli/src/cli-dns", "", "1.0.0")
+	recordPackageRun(ctx, "cli-dns", "dns", "1.0.0", time.Now(), cli.Exit(color.RedString("zone not found"), 3))
+	RecordCommandError(ctx, "install", errors.New("package not found"))
+	RecordCommandError(ctx, "list", nil)
+
+	data, err := ioutil.ReadFile(filepath.Join(cliHome, ".akamai-cli", eventLogFile))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	events := make([]logEvent, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &events[i]))
+		assert.Equal(t, eventSchema, events[i].Schema)
+		assert.Equal(t, os.Getpid(), events[i].PID)
+		assert.False(t, events[i].Time.IsZero())
+	}
+	assert.Equal(t, packageEventInstall, events[0].Event)
+	assert.Equal(t, "cli-dns", events[0].Package)
+	assert.Equal(t, "1.0.0", events[0].NewVersion)
+	assert.Nil(t, events[0].ExitCode)
+
+	assert.Equal(t, logEventRun, events[1].Event)
+	assert.Equal(t, "dns", events[1].Command)
+	require.NotNil(t, events[1].ExitCode)
+	assert.Equal(t, 3, *events[1].ExitCode)
+	assert.NotNil(t, events[1].DurationMS)
+	assert.Equal(t, "zone not found", events[1].Error)
+
+	assert.Equal(t, logEventError, events[2].Event)
+	assert.Equal(t, "install", events[2].Command)
+	assert.Equal(t, "package not found", events[2].Error)
+	cfg.AssertExpectations(t)
+
+	disabled := &config.Mock{}
+	disabled.On("GetValue", "cli", eventLogKey).Return("false", true).Once()
+	RecordCommandError(config.Context(context.Background(), disabled), "install", errors.New("package not found"))
+	after, err := ioutil.ReadFile(filepath.Join(cliHome, ".akamai-cli", eventLogFile))
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		value     string
+		expected  time.Time
+		withError bool
+	}{
+		"duration":  {value: "2h", expected: now.Add(-2 * time.Hour)},
+		"timestamp": {value: "2021-02-28T10:00:00Z", expected: time.Date(2021, 2, 28, 10, 0, 0, 0, time.UTC)},
+		"date":      {value: "2021-02-28", expected: time.Date(2021, 2, 28, 0, 0, 0, 0, time.Local)},
+		"invalid":   {value: "yesterday", withError: true},
+		"negative":  {value: "-2h", withError: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			since, err := parseSince(test.value, now)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, test.expected.Equal(since), "expected %s, got %s", test.expected, since)
+		})
+	}
+}
+
+func TestCmdEvents(t *testing.T) {
+	now := time.Now().UTC()
+	event := func(name, pkg string, age time.Duration) string {
+		return fmt.Sprintf(`{"schema":1,"time":"%s","event":"%s","package":"%s","cliVersion":"1.2.1","pid":1}`, now.Add(-age).Format(time.RFC3339Nano), name, pkg)
+	}
+	rotated := []string{event("install", "cli-dns", 72*time.Hour), event("run", "cli-dns", 48*time.Hour)}
+	current := []string{event("update", "cli-dns", 3*time.Hour), "not an event", event("install", "cli-property", time.Hour), event("run", "cli-property", time.Minute)}
+
+	tests := map[string]struct {
+		args      []string
+		expected  []string
+		withError string
+	}{
+		"all events":       {args: []string{"events"}, expected: append(append([]string{}, rotated...), current[0], current[2], current[3])},
+		"since":            {args: []string{"events", "--since", "2h"}, expected: []string{current[2], current[3]}},
+		"event type":       {args: []string{"events", "--event", "install"}, expected: []string{rotated[0], current[2]}},
+		"package":          {args: []string{"events", "--package", "cli-dns", "--event", "run"}, expected: []string{rotated[1]}},
+		"tail":             {args: []string{"events", "tail", "-n", "2"}, expected: []string{current[2], current[3]}},
+		"tail with filter": {args: []string{"events", "tail", "--event", "run"}, expected: []string{rotated[1], current[3]}},
+		"invalid since":    {args: []string{"events", "--since", "yesterday"}, withError: `invalid --since value "yesterday"`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			path := filepath.Join(cliHome, ".akamai-cli", eventLogFile)
+			require.NoError(t, ioutil.WriteFile(path+".1", []byte(strings.Join(rotated, "\n")+"\n"), 0600))
+			require.NoError(t, ioutil.WriteFile(path, []byte(strings.Join(current, "\n")+"\n"), 0600))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			for _, line := range test.expected {
+				m.term.On("Printf", "%s\n", []interface{}{line}).Return().Once()
+			}
+			command := &cli.Command{
+				Name:   "events",
+				Action: cmdEvents,
+				Flags:  eventFlags(),
+				Subcommands: []*cli.Command{
+					{Name: "tail", Action: cmdEventsTail, Flags: append(eventFlags(), &cli.IntFlag{Name: "lines", Aliases: []string{"n"}, Value: 10}, &cli.BoolFlag{Name: "follow"})},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+
+			err := app.RunContext(ctx, append(os.Args[0:1], test.args...))
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+			m.term.AssertNumberOfCalls(t, "Printf", len(test.expected))
+		})
+	}
+}
+
+func TestFollowEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-events")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, eventLogFile)
+	first := `{"event":"install","package":"cli-dns"}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(first+"\n"), 0600))
+
+	term := &terminal.Mock{}
+	printed := make(chan struct{})
+	term.On("Printf", "%s\n", []interface{}{`{"event":"run","package":"cli-dns"}`}).Return().Run(func(mock.Arguments) {
+		close(printed)
+	}).Once()
+	ctx, cancel := context.WithCancel(terminal.Context(context.Background(), term))
+	done := make(chan error)
+	go func() {
+		done <- followEvents(ctx, path, int64(len(first)+1), eventFilter{})
+	}()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	// a partial line is only printed once it is complete
+	_, err = f.WriteString(`{"event":"run",`)
+	require.NoError(t, err)
+	time.Sleep(2 * eventFollowInterval)
+	_, err = f.WriteString(`"package":"cli-dns"}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	select {
+	case <-printed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("new event was not printed")
+	}
+	cancel()
+	require.NoError(t, <-done)
+	term.AssertExpectations(t)
+}
@@ -0,0 +1,90 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+
+	"github.com/urfave/cli/v2"
+)
+
+// exitCodesKey is the config key which, when set to "strict", makes Akamai CLI report exit codes of installed commands
+// using only the codes of the documented scheme. Otherwise, codes which are not translated in cli.json are passed through.
+const exitCodesKey = "exit-codes"
+
+// exitCodeNames are the names of the exit codes of the scheme, used to translate package specific codes in cli.json
+var exitCodeNames = map[string]int{
+	"ok":      plugin.ExitOK,
+	"error":   plugin.ExitError,
+	"usage":   plugin.ExitUsage,
+	"auth":    plugin.ExitAuth,
+	"network": plugin.ExitNetwork,
+	"crash":   plugin.ExitCrash,
+}
+
+// packageExitError converts the error returned by an executed installed command into an exit error,
+// translating its exit code with the "exit-codes" of the command in cli.json, e.g. {"7": "auth"}
+func packageExitError(ctx context.Context, translations map[string]string, err error) error {
+	if err == nil {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	code, crashed := processExitCode(err)
+	strict, _ := config.Get(ctx).GetValue("cli", exitCodesKey)
+	name, translated := translations[strconv.Itoa(code)]
+	if translated && !crashed {
+		mapped, ok := exitCodeNames[strings.ToLower(strings.TrimSpace(name))]
+		if ok {
+			logger.Debugf("Translating exit code %d to %d (%s)", code, mapped, name)
+			code = mapped
+		} else {
+			logger.Warnf("Unknown exit code name in cli.json: %s", name)
+			translated = false
+		}
+	}
+	if !translated && strings.TrimSpace(strict) == "strict" {
+		switch {
+		case crashed:
+			code = plugin.ExitCrash
+		case code < plugin.ExitOK || code > plugin.ExitCrash:
+			logger.Debugf("Reporting exit code %d outside of the scheme as %d", code, plugin.ExitError)
+			code = plugin.ExitError
+		}
+	}
+	if code == plugin.ExitOK {
+		return nil
+	}
+	return cli.Exit("", code)
+}
+
+// processExitCode returns the exit code of an executed command, and whether it was terminated by a signal
+func processExitCode(err error) (int, bool) {
+	exitError, ok := err.(*exec.ExitError)
+	if !ok {
+		return plugin.ExitError, false
+	}
+	if waitStatus, ok := exitError.Sys().(syscall.WaitStatus); ok {
+		return waitStatus.ExitStatus(), waitStatus.Signaled()
+	}
+	return plugin.ExitError, false
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestPackageExitError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := map[string]struct {
+		script       string
+		mode         string
+		translations map[string]string
+		expectedCode int
+	}{
+		"success": {
+			script: "exit 0",
+		},
+		"exit code passed through": {
+			script:       "exit 42",
+			expectedCode: 42,
+		},
+		"exit code translated": {
+			script:       "exit 42",
+			translations: map[string]string{"42": "auth"},
+			expectedCode: 3,
+		},
+		"exit code translated to success": {
+			script:       "exit 9",
+			translations: map[string]string{"9": "OK"},
+		},
+		"unknown translation is ignored": {
+			script:       "exit 42",
+			translations: map[string]string{"42": "forbidden"},
+			expectedCode: 42,
+		},
+		"strict mode keeps codes of the scheme": {
+			script:       "exit 4",
+			mode:         "strict",
+			expectedCode: 4,
+		},
+		"strict mode maps other codes to error": {
+			script:       "exit 42",
+			mode:         "strict",
+			expectedCode: 1,
+		},
+		"strict mode translates codes": {
+			script:       "exit 42",
+			mode:         "strict",
+			translations: map[string]string{"42": "network"},
+			expectedCode: 4,
+		},
+		"strict mode reports killed command as crash": {
+			script:       "kill -9 $$",
+			mode:         "strict",
+			expectedCode: 5,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &config.Mock{}
+			m.On("GetValue", "cli", "exit-codes").Return(test.mode, test.mode != "").Maybe()
+			err := packageExitError(config.Context(context.Background(), m), test.translations, exec.Command("sh", "-c", test.script).Run())
+			m.AssertExpectations(t)
+			if test.expectedCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+			exitErr, ok := err.(cli.ExitCoder)
+			require.True(t, ok)
+			assert.Equal(t, test.expectedCode, exitErr.ExitCode())
+		})
+	}
+}
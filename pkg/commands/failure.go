@@ -0,0 +1,265 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/fatih/color"
+)
+
+// failure codes identify user-facing errors independently of their wording, so that scripts, documentation
+// and support can refer to them whatever the language of the messages. Codes are never renamed or reused.
+const (
+	failurePackageNotFound        = "package-not-found"
+	failureCommandNotFound        = "command-not-found"
+	failurePackageExists          = "package-exists"
+	failureRuntimeNotFound        = "runtime-not-found"
+	failureRuntimeVersion         = "runtime-version"
+	failurePackageManagerNotFound = "package-manager-not-found"
+	failureDependencies           = "dependencies-failed"
+	failureAuth                   = "auth-failed"
+	failureFetch                  = "fetch-failed"
+	failureLocalCommits           = "local-commits"
+	failureHistoryRewritten       = "history-rewritten"
+	failureLocalChanges           = "local-changes"
+	failureNotWritable            = "not-writable"
+	failureDiskSpace              = "disk-space"
+	failurePackageBroken          = "package-broken"
+	failureDeprecated             = "deprecated"
+	failureCredentialExchange     = "credential-exchange-failed"
+)
+
+// failureTemplate holds the text of a failure: what failed and what the user can do about it.
+// Templates take named values, so that translated messages can order them differently.
+type failureTemplate struct {
+	message string
+	hint    string
+}
+
+// failureTemplates are the messages of user-facing failures by code. The Self value, the name Akamai CLI
+// is run with, is available in all of them.
+var failureTemplates = map[string]failureTemplate{
+	failurePackageNotFound: {
+		message: `Package "{{.Package}}" not found.`,
+		hint:    `run "{{.Self}} list" to see the installed packages, or "{{.Self}} search {{.Package}}" to find one to install`,
+	},
+	failureCommandNotFound: {
+		message: `Command "{{.Command}}" not found.`,
+		hint:    `run "{{.Self}} help" to see the available commands`,
+	},
+	failurePackageExists: {
+		message: `Package directory already exists ({{.Dir}})`,
+		hint:    `run "{{.Self}} update {{.Package}}" to update the package, or "{{.Self}} uninstall {{.Package}}" before installing it again`,
+	},
+	failureRuntimeNotFound: {
+		message: `{{.Runtime}} is required by {{.Package}}, but it was not found`,
+		hint:    `install {{.Runtime}}{{if .Requirement}} {{.Requirement}} or later{{end}}, make sure it is in your PATH, and run "{{.Self}} install {{.Package}}" again`,
+	},
+	failureRuntimeVersion: {
+		message: `{{.Package}} requires {{.Runtime}} {{.Requirement}} or later`,
+		hint:    `upgrade {{.Runtime}}, or put a newer version first in your PATH, and run "{{.Self}} install {{.Package}}" again`,
+	},
+	failurePackageManagerNotFound: {
+		message: `The package manager of {{.Runtime}} needed to install {{.Package}} was not found`,
+		hint:    `install the package manager named below, make sure it is in your PATH, and run "{{.Self}} install {{.Package}}" again`,
+	},
+	failureDependencies: {
+		message: `Unable to install the dependencies of {{.Package}}`,
+		hint:    `run "{{.Self}} -v install {{.Package}}" to see the output of the package manager, or "{{.Self}} doctor {{.Package}}" once installed`,
+	},
+	failureAuth: {
+		message: `Access to {{.Repository}} was denied`,
+		hint:    `check that the repository exists and that you can read it, then store a token with "{{.Self}} auth github" or set AKAMAI_CLI_GIT_TOKEN`,
+	},
+	failureFetch: {
+		message: `Unable to fetch {{.Repository}}`,
+		hint:    `check your network connection, proxy settings and the repository URL, then try again`,
+	},
+	failureLocalCommits: {
+		message: `Unable to update, command "{{.Command}}" has local commits which are not on the remote`,
+		hint:    `run "{{.Self}} update --reset {{.Command}}" to reset it to the remote branch, discarding the local commits`,
+	},
+	failureHistoryRewritten: {
+		message: `Unable to update, the remote history of command "{{.Command}}" was rewritten`,
+		hint:    `run "{{.Self}} update --reset {{.Command}}" to reset it to the remote branch`,
+	},
+	failureLocalChanges: {
+		message: `Unable to reset to the remote branch, command "{{.Command}}" has uncommitted changes`,
+		hint:    `commit or discard the changes in {{.Dir}} and run "{{.Self}} update {{.Command}}" again`,
+	},
+	failureNotWritable: {
+		message: `{{.Dir}} is not writable by {{.User}}`,
+		hint:    `fix the permissions of the directory, or set AKAMAI_CLI_HOME to a directory you own`,
+	},
+	failureDiskSpace: {
+		message: `Not enough free space in {{.Dir}}: {{.Free}} available, {{.Required}} required`,
+		hint:    `free up space, or change the requirement with "{{.Self}} config set cli.{{.Key}} <size>"`,
+	},
+	failurePackageBroken: {
+		message: `Package {{.Package}} cannot run: {{.Problems}}`,
+		hint:    `run "{{.Self}} doctor --fix {{.Package}}" to repair it, or reinstall it with "{{.Self}} uninstall {{.Package}}" and "{{.Self}} install {{.Package}}"`,
+	},
+	failureCredentialExchange: {
+		message: `Unable to get short-lived credentials for section {{.Section}} of {{.Edgerc}}`,
+		hint:    `check the exchange_url of the section and your network connection, or remove exchange_url to let commands read the credentials of the section`,
+	},
+	failureDeprecated: {
+		message: `{{.Name}} {{if .Removed}}was removed in Akamai CLI {{.RemovedIn}}{{else}}is deprecated{{if .RemovedIn}} and will be removed in {{.RemovedIn}}{{end}}{{end}}`,
+		hint:    `{{with .Replacement}}{{.}}{{if not $.Removed}}, or {{end}}{{end}}{{if not .Removed}}run "{{.Self}} config set cli.deprecations warn" to only warn about deprecated commands and flags{{end}}`,
+	},
+}
+
+// failureData holds the values of a failure template by name
+type failureData map[string]interface{}
+
+// failure is a user-facing error with a stable code, its cause if any, and a remediation hint, rendered from
+// the templates of its code. It is an exit coder, so commands can return it as they return cli.Exit errors.
+type failure struct {
+	code     string
+	data     failureData
+	cause    error
+	exitCode int
+}
+
+// newFailure returns the failure of given code, caused by an optional error
+func newFailure(code string, data failureData, cause error) *failure {
+	return &failure{code: code, data: data, cause: cause, exitCode: 1}
+}
+
+// Code returns the stable code of the failure
+func (f *failure) Code() string {
+	return f.code
+}
+
+// Message returns the message of the failure, without its code, cause and hint
+func (f *failure) Message() string {
+	return f.render(failureTemplates[f.code].message)
+}
+
+// Hint returns the remediation hint of the failure
+func (f *failure) Hint() string {
+	return f.render(failureTemplates[f.code].hint)
+}
+
+// Error renders the message with the code, the cause and the hint in separate lines
+func (f *failure) Error() string {
+	lines := []string{color.RedString("%s [%s]", f.Message(), f.code)}
+	if f.cause != nil {
+		lines = append(lines, color.RedString("Cause: %s", strings.TrimSpace(ansiEscape.ReplaceAllString(f.cause.Error(), ""))))
+	}
+	if hint := f.Hint(); hint != "" {
+		lines = append(lines, color.CyanString("Try: %s", hint))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExitCode returns the exit code of Akamai CLI failing with the failure
+func (f *failure) ExitCode() int {
+	return f.exitCode
+}
+
+// Unwrap returns the cause of the failure, so that errors.Is matches it
+func (f *failure) Unwrap() error {
+	return f.cause
+}
+
+func (f *failure) render(text string) string {
+	if text == "" {
+		return ""
+	}
+	tmpl, err := template.New(f.code).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return text
+	}
+	data := failureData{"Self": tools.Self()}
+	for key, val := range f.data {
+		data[key] = val
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return text
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", "")
+}
+
+// runtimeNames are the names of the runtimes of built-in languages shown in failures
+var runtimeNames = map[string]string{
+	packages.Go:         "Go",
+	packages.Javascript: "Node.js",
+	packages.PHP:        "PHP",
+	packages.Python:     "Python",
+	packages.Ruby:       "Ruby",
+}
+
+// installFailure translates an error of installing the dependencies of a package into a failure,
+// telling which runtime or package manager is missing
+func installFailure(pkg subcommands, packageName string, err error) error {
+	var f *failure
+	if err == nil || errors.As(err, &f) {
+		return err
+	}
+	lang, requirement := pkg.Requirements.Language()
+	runtime, ok := runtimeNames[lang]
+	if !ok {
+		runtime = lang
+	}
+	if requirement == "*" {
+		requirement = ""
+	}
+	data := failureData{"Package": packageName, "Runtime": runtime, "Requirement": requirement}
+	switch {
+	case errors.Is(err, packages.ErrRuntimeNotFound):
+		return newFailure(failureRuntimeNotFound, data, err)
+	case errors.Is(err, packages.ErrRuntimeMinimumVersionRequired), errors.Is(err, packages.ErrRuntimeNoVersionFound):
+		return newFailure(failureRuntimeVersion, data, err)
+	case errors.Is(err, packages.ErrPackageManagerNotFound):
+		return newFailure(failurePackageManagerNotFound, data, err)
+	case errors.Is(err, packages.ErrPackageManagerExec), errors.Is(err, packages.ErrPackageCompileFailure):
+		return newFailure(failureDependencies, data, err)
+	}
+	return err
+}
+
+// fetchFailure translates an error of cloning or fetching a repository into a failure,
+// exiting with the auth or network code of the conventions of packages
+func fetchFailure(repo string, err error) error {
+	data := failureData{"Repository": repo}
+	if git.IsAuthError(err) {
+		f := newFailure(failureAuth, data, err)
+		f.exitCode = plugin.ExitAuth
+		return f
+	}
+	f := newFailure(failureFetch, data, err)
+	f.exitCode = plugin.ExitNetwork
+	return f
+}
+
+// packageNotFound is the failure of a command given a package which is not installed
+func packageNotFound(name string) error {
+	return newFailure(failurePackageNotFound, failureData{"Package": name}, nil)
+}
+
+// commandNotFound is the failure of a command given a command which is not installed
+func commandNotFound(name string) error {
+	return newFailure(failureCommandNotFound, failureData{"Command": name}, nil)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"testing"
+)
+
+func TestFailure(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		code     string
+		message  string
+		hint     string
+		exitCode int
+	}{
+		"package not found": {
+			err:      packageNotFound("cli-dns"),
+			code:     failurePackageNotFound,
+			message:  `Package "cli-dns" not found.`,
+			hint:     fmt.Sprintf(`run "%[1]s list" to see the installed packages, or "%[1]s search cli-dns" to find one to install`, tools.Self()),
+			exitCode: 1,
+		},
+		"runtime not found": {
+			err:      installFailure(subcommands{Requirements: packages.LanguageRequirements{Python: "3.6.0"}}, "cli-dns", packages.ErrRuntimeNotFound),
+			code:     failureRuntimeNotFound,
+			message:  "Python is required by cli-dns, but it was not found",
+			hint:     fmt.Sprintf(`install Python 3.6.0 or later, make sure it is in your PATH, and run "%s install cli-dns" again`, tools.Self()),
+			exitCode: 1,
+		},
+		"runtime of any version not found": {
+			err:      installFailure(subcommands{Requirements: packages.LanguageRequirements{Node: "*"}}, "cli-dns", packages.ErrRuntimeNotFound),
+			code:     failureRuntimeNotFound,
+			message:  "Node.js is required by cli-dns, but it was not found",
+			hint:     fmt.Sprintf(`install Node.js, make sure it is in your PATH, and run "%s install cli-dns" again`, tools.Self()),
+			exitCode: 1,
+		},
+		"authentication failed": {
+			err:      fetchFailure("https://github.com/akamai/cli-dns.git", transport.ErrAuthenticationRequired),
+			code:     failureAuth,
+			message:  "Access to https://github.com/akamai/cli-dns.git was denied",
+			hint:     fmt.Sprintf(`check that the repository exists and that you can read it, then store a token with "%s auth github" or set AKAMAI_CLI_GIT_TOKEN`, tools.Self()),
+			exitCode: plugin.ExitAuth,
+		},
+		"fetch failed": {
+			err:      fetchFailure("https://github.com/akamai/cli-dns.git", errors.New("connection refused")),
+			code:     failureFetch,
+			message:  "Unable to fetch https://github.com/akamai/cli-dns.git",
+			hint:     "check your network connection, proxy settings and the repository URL, then try again",
+			exitCode: plugin.ExitNetwork,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var f *failure
+			require.True(t, errors.As(test.err, &f), "expected a failure, got %v", test.err)
+			assert.Equal(t, test.code, f.Code())
+			assert.Equal(t, test.message, f.Message())
+			assert.Equal(t, test.hint, f.Hint())
+			var exitErr cli.ExitCoder
+			require.True(t, errors.As(test.err, &exitErr))
+			assert.Equal(t, test.exitCode, exitErr.ExitCode())
+		})
+	}
+}
+
+func TestFailureError(t *testing.T) {
+	err := newFailure(failureLocalChanges, failureData{"Command": "dns", "Dir": "src/cli-dns"}, errors.New(color.RedString("worktree has local changes")))
+	expected := color.RedString(`Unable to reset to the remote branch, command "dns" has uncommitted changes [local-changes]`) + "\n" +
+		color.RedString("Cause: worktree has local changes") + "\n" +
+		color.CyanString(`Try: commit or discard the changes in src/cli-dns and run "%s update dns" again`, tools.Self())
+	assert.Equal(t, expected, err.Error())
+	assert.Equal(t, "worktree has local changes", ansiEscape.ReplaceAllString(errors.Unwrap(err).Error(), ""))
+
+	unknownErr := errors.New("unexpected")
+	assert.Equal(t, unknownErr, installFailure(subcommands{}, "cli-dns", unknownErr), "other errors are returned as they are")
+}
@@ -0,0 +1,103 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// heartbeatKey is the config key holding the interval of inactivity after which a heartbeat is printed, e.g. "1m"
+const heartbeatKey = "heartbeat-interval"
+
+// activityWriter passes writes to the underlying writer and records the time of the last one
+type activityWriter struct {
+	w    io.Writer
+	mu   *sync.Mutex
+	last *time.Time
+}
+
+func (a *activityWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	*a.last = time.Now()
+	a.mu.Unlock()
+	return a.w.Write(p)
+}
+
+// runWithHeartbeat runs given command and prints a "still running" message to out
+// each time the command produces no output on stdout or stderr for the given interval.
+// The command is started with start, which may apply resource limits to its process.
+// Watching the output requires a pipe, so a command writing to a terminal keeps it and runs without heartbeats,
+// which are meant for CI logs anyway.
+func runWithHeartbeat(subCmd *exec.Cmd, interval time.Duration, out io.Writer, start func() error) error {
+	if isTerminal(subCmd.Stdout) || isTerminal(subCmd.Stderr) {
+		if err := start(); err != nil {
+			return err
+		}
+		return subCmd.Wait()
+	}
+	var mu sync.Mutex
+	started := time.Now()
+	last := started
+	if subCmd.Stdout != nil {
+		subCmd.Stdout = &activityWriter{w: subCmd.Stdout, mu: &mu, last: &last}
+	}
+	if subCmd.Stderr != nil {
+		subCmd.Stderr = &activityWriter{w: subCmd.Stderr, mu: &mu, last: &last}
+	}
+	if err := start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				mu.Lock()
+				idle := now.Sub(last) >= interval
+				if idle {
+					last = now
+				}
+				mu.Unlock()
+				if idle {
+					fmt.Fprintf(out, "still running (%s)\n", now.Sub(started).Round(time.Second))
+				}
+			}
+		}
+	}()
+
+	err := subCmd.Wait()
+	close(done)
+	<-finished
+	return err
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunWithHeartbeat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := map[string]struct {
+		script        string
+		withHeartbeat bool
+		withError     bool
+	}{
+		"silent command prints heartbeat": {
+			script:        "sleep 0.5",
+			withHeartbeat: true,
+		},
+		"command producing output prints no heartbeat": {
+			script: "for i in 1 2 3 4 5; do echo $i; sleep 0.1; done",
+		},
+		"failing command returns error": {
+			script:    "exit 3",
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdout, heartbeats := &bytes.Buffer{}, &bytes.Buffer{}
+			subCmd := exec.Command("sh", "-c", test.script)
+			subCmd.Stdout = stdout
+			err := runWithHeartbeat(subCmd, 200*time.Millisecond, heartbeats, subCmd.Start)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.withHeartbeat {
+				assert.Contains(t, heartbeats.String(), "still running (")
+				return
+			}
+			assert.Empty(t, heartbeats.String())
+			assert.Equal(t, "1\n2\n3\n4\n5\n", stdout.String())
+		})
+	}
+}
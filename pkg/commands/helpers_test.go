@@ -3,9 +3,12 @@ package commands
 import (
 	"context"
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
 	"github.com/akamai/cli/pkg/git"
 	"github.com/akamai/cli/pkg/packages"
 	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/warnings"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 	"io"
@@ -21,8 +24,36 @@ type mocked struct {
 	langManager *packages.Mock
 }
 
+// TestMain removes the package store written to testdata by install, update and uninstall tests
+func TestMain(m *testing.M) {
+	code := m.Run()
+	_ = os.Remove(filepath.Join("testdata", ".akamai-cli", packageStoreFile))
+	os.Exit(code)
+}
+
 func setupTestApp(command *cli.Command, m *mocked) (*cli.App, context.Context) {
 	cli.OsExiter = func(rc int) {}
+	// downloads are not limited in tests, whatever operation they belong to
+	m.cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false).Maybe()
+	m.cfg.On("GetValue", "cli", download.ProxyKey).Return("", false).Maybe()
+	// packages are built on the host, unless a test configures a build container
+	m.cfg.On("GetValue", "cli", buildContainerKey).Return("", false).Maybe()
+	// no GitHub token is stored, unless a test stores one before setting up the app
+	m.cfg.On("GetValue", "cli", githubUserKey).Return("", false).Maybe()
+	// pending updates are not reported in a digest, so the daily upgrade check applies
+	m.cfg.On("GetValue", "cli", updateDigestKey).Return("", false).Maybe()
+	// events are not recorded in the event log of the test home, unless a test enables it
+	m.cfg.On("GetValue", "cli", eventLogKey).Return("false", true).Maybe()
+	// broken packages are not repaired before their commands run, unless a test sets it up
+	m.cfg.On("GetValue", "cli", autoRepairKey).Return("false", true).Maybe()
+	// launchers of installed commands are not written to the test home
+	m.cfg.On("GetValue", "cli", launchersKey).Return("false", true).Maybe()
+	// the free space of the test home is not checked, unless a test requires some
+	m.cfg.On("GetValue", "cli", minFreeSpaceKey).Return("0", true).Maybe()
+	// warnings are written on every run, without recording them in the test home
+	m.cfg.On("GetValue", "cli", warnings.IntervalKey).Return("0", true).Maybe()
+	// package commands run without resource limits
+	m.cfg.On("GetValue", resourceLimitsSection, mock.Anything).Return("", false).Maybe()
 	ctx := terminal.Context(context.Background(), m.term)
 	ctx = config.Context(ctx, m.cfg)
 	app := cli.NewApp()
@@ -0,0 +1,154 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+)
+
+// package lifecycle events which hooks can be declared for in cli.json
+const (
+	hookPostInstall  = "post-install"
+	hookPreUpdate    = "pre-update"
+	hookPostUpdate   = "post-update"
+	hookPreUninstall = "pre-uninstall"
+)
+
+const (
+	defaultHookTimeout = 5 * time.Minute
+	// hookOutputLines is the number of last output lines of a failed hook which are reported
+	hookOutputLines = 10
+)
+
+// packageHook is a shell command run in the package directory on a lifecycle event
+type packageHook struct {
+	Command string `json:"command"`
+	// OS replaces the command on given operating systems, by GOOS such as "windows" or "darwin".
+	// An empty command skips the hook on that system.
+	OS map[string]string `json:"os,omitempty"`
+}
+
+// commandFor returns the command of the hook to run on given operating system, empty if there is none
+func (h packageHook) commandFor(goos string) string {
+	if cmd, ok := h.OS[goos]; ok {
+		return strings.TrimSpace(cmd)
+	}
+	return strings.TrimSpace(h.Command)
+}
+
+// hookCommand returns the command of the package hook for given event on the current system, empty if none is declared
+func hookCommand(pkg subcommands, event string) string {
+	hook, ok := pkg.Hooks[event]
+	if !ok {
+		return ""
+	}
+	return hook.commandFor(runtime.GOOS)
+}
+
+type noHooksContextType string
+
+var noHooksContext noHooksContextType = "no-hooks"
+
+// withNoHooks disables package hooks if disable is set
+func withNoHooks(ctx context.Context, disable bool) context.Context {
+	if !disable {
+		return ctx
+	}
+	return context.WithValue(ctx, noHooksContext, true)
+}
+
+func hooksDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noHooksContext).(bool)
+	return disabled
+}
+
+// runPackageHook runs the hook the package in dir declares for event, if any, with the package directory as working directory.
+// The hook has AKAMAI_CLI_HOOK and AKAMAI_CLI_PACKAGE_DIR set and has to exit with 0 within the hook time limit.
+func runPackageHook(ctx context.Context, dir string, pkg subcommands, event string) error {
+	command := hookCommand(pkg, event)
+	if command == "" {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	pkgName := filepath.Base(dir)
+	if hooksDisabled(ctx) {
+		msg := fmt.Sprintf("Skipping %s hook of %s: %s", event, pkgName, command)
+		logger.Warn(msg)
+		term.Writeln(color.CyanString(msg))
+		return nil
+	}
+
+	logger.Infof("Running %s hook of %s: %s", event, pkgName, command)
+	term.Spinner().Start("Running %s hook of %s...", event, pkgName)
+	start := time.Now()
+	timeout := hookTimeout(ctx)
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	hookCmd := shellCommand(hookCtx, command)
+	hookCmd.Dir = dir
+	hookCmd.Env = append(os.Environ(), "AKAMAI_CLI_HOOK="+event, "AKAMAI_CLI_PACKAGE_DIR="+dir)
+	output := newCaptureBuffer(ctx)
+	hookCmd.Stdout = output
+	hookCmd.Stderr = output
+	err := hookCmd.Run()
+	if dropped := output.Dropped(); dropped > 0 {
+		logger.Debugf("Output of %s hook of %s, without the first %d bytes:\n%s", event, pkgName, dropped, output.String())
+	} else {
+		logger.Debugf("Output of %s hook of %s:\n%s", event, pkgName, output.String())
+	}
+	if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("did not finish within %s", timeout)
+	}
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Errorf("The %s hook of %s failed after %s: %s", event, pkgName, formatDuration(since(start)), err)
+		if out := lastLines(output.String(), hookOutputLines); out != "" {
+			term.Writeln(out)
+		}
+		return fmt.Errorf("%s hook of %s failed: %s", event, pkgName, err)
+	}
+	term.Spinner().OK()
+	logger.Debugf("The %s hook of %s took %s", event, pkgName, formatDuration(since(start)))
+	return nil
+}
+
+// shellCommand returns a command running given command line with the shell of the system
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func hookTimeout(ctx context.Context) time.Duration {
+	if timeout := stepTimeout(ctx, hookTimeoutKey); timeout > 0 {
+		return timeout
+	}
+	return defaultHookTimeout
+}
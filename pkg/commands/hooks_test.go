@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPackageHookCommandFor(t *testing.T) {
+	hook := packageHook{Command: " ./migrate.sh ", OS: map[string]string{"windows": "migrate.cmd", "darwin": ""}}
+	tests := map[string]struct {
+		goos     string
+		expected string
+	}{
+		"default command":   {goos: "linux", expected: "./migrate.sh"},
+		"command of the OS": {goos: "windows", expected: "migrate.cmd"},
+		"skipped on the OS": {goos: "darwin", expected: ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, hook.commandFor(test.goos))
+		})
+	}
+}
+
+func TestRunPackageHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := map[string]struct {
+		hooks     map[string]packageHook
+		noHooks   bool
+		timeout   string
+		init      func(*terminal.Mock)
+		ran       bool
+		withError string
+	}{
+		"hook succeeds": {
+			hooks: map[string]packageHook{hookPostUpdate: {Command: `echo "$AKAMAI_CLI_HOOK $AKAMAI_CLI_PACKAGE_DIR" > hook.out`}},
+			init: func(m *terminal.Mock) {
+				m.On("Start", "Running %s hook of %s...", []interface{}{hookPostUpdate, "cli-dns"}).Return().Once()
+				m.On("OK").Return().Once()
+			},
+			ran: true,
+		},
+		"hook fails": {
+			hooks: map[string]packageHook{hookPostUpdate: {Command: "echo broken; exit 2"}},
+			init: func(m *terminal.Mock) {
+				m.On("Start", "Running %s hook of %s...", []interface{}{hookPostUpdate, "cli-dns"}).Return().Once()
+				m.On("Fail").Return().Once()
+				m.On("Writeln", []interface{}{"broken"}).Return(0, nil).Once()
+			},
+			withError: "post-update hook of cli-dns failed: exit status 2",
+		},
+		"hook times out": {
+			hooks:   map[string]packageHook{hookPostUpdate: {Command: "exec sleep 5"}},
+			timeout: "100ms",
+			init: func(m *terminal.Mock) {
+				m.On("Start", "Running %s hook of %s...", []interface{}{hookPostUpdate, "cli-dns"}).Return().Once()
+				m.On("Fail").Return().Once()
+			},
+			withError: "post-update hook of cli-dns failed: did not finish within 100ms",
+		},
+		"hooks disabled": {
+			hooks:   map[string]packageHook{hookPostUpdate: {Command: "exit 2"}},
+			noHooks: true,
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{color.CyanString("Skipping post-update hook of cli-dns: exit 2")}).Return(0, nil).Once()
+			},
+		},
+		"hook of another event": {
+			hooks: map[string]packageHook{hookPreUpdate: {Command: "exit 2"}},
+			init:  func(m *terminal.Mock) {},
+		},
+		"hook skipped on the OS": {
+			hooks: map[string]packageHook{hookPostUpdate: {Command: "exit 2", OS: map[string]string{runtime.GOOS: ""}}},
+			init:  func(m *terminal.Mock) {},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmp, err := ioutil.TempDir("", "cli-hooks")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(tmp))
+			}()
+			dir := filepath.Join(tmp, "cli-dns")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+
+			term := &terminal.Mock{}
+			term.On("Spinner").Return(term).Maybe()
+			test.init(term)
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", hookTimeoutKey).Return(test.timeout, test.timeout != "").Maybe()
+			cfg.On("GetValue", "cli", maxCaptureKey).Return("", false).Maybe()
+			ctx := withNoHooks(terminal.Context(config.Context(context.Background(), cfg), term), test.noHooks)
+
+			err = runPackageHook(ctx, dir, subcommands{Hooks: test.hooks}, hookPostUpdate)
+			term.AssertExpectations(t)
+			if test.ran {
+				out, err := ioutil.ReadFile(filepath.Join(dir, "hook.out"))
+				require.NoError(t, err)
+				assert.Equal(t, hookPostUpdate+" "+dir, strings.TrimSpace(string(out)))
+			}
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
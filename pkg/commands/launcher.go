@@ -0,0 +1,209 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// launchersKey is the config key which disables launchers of installed commands when set to "false"
+	launchersKey = "launchers"
+	// launchersInPathKey records whether the launcher directory was added to PATH on first run: "yes" or "no"
+	launchersInPathKey = "launchers-in-path"
+	// launcherMarker identifies launchers written by Akamai CLI, other files of the launcher directory are left alone
+	launcherMarker = "Generated by Akamai CLI"
+)
+
+// launcherSafe matches the command names launchers are written for, other names cannot be safely used in a file name
+var launcherSafe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// launcherDir returns the directory holding the launchers of installed commands, bin in the Akamai CLI home
+func launcherDir() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, "bin"), nil
+}
+
+// launchersEnabled returns false if launchers were disabled with the launchers setting
+func launchersEnabled(ctx context.Context) bool {
+	val, ok := config.Get(ctx).GetValue("cli", launchersKey)
+	return !ok || strings.TrimSpace(val) != "false"
+}
+
+// refreshLaunchers writes an akamai-<command> launcher for each installed command after packages are installed, updated,
+// uninstalled, enabled or disabled, and removes launchers of commands which are no longer installed.
+// Launchers run the command through Akamai CLI, so tools which can only run a single executable get the same
+// environment, credentials and settings as "akamai <command>".
+func refreshLaunchers(c *cli.Context) {
+	if !launchersEnabled(c.Context) {
+		return
+	}
+	if err := writeLaunchers(); err != nil {
+		log.FromContext(c.Context).Warnf("Unable to update command launchers: %s", err)
+	}
+}
+
+func writeLaunchers() error {
+	dir, err := launcherDir()
+	if err != nil {
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+
+	launchers := make(map[string]string)
+	for _, pkgDir := range getPackagePaths() {
+		pkg, err := readPackage(pkgDir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			name := strings.ToLower(cmd.Name)
+			if !launcherSafe.MatchString(name) {
+				continue
+			}
+			launchers[launcherFileName(name)] = launcherScript(self, name)
+		}
+	}
+	if len(launchers) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, file := range files {
+		if _, ok := launchers[file.Name()]; ok || file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		if data, err := ioutil.ReadFile(path); err == nil && strings.Contains(string(data), launcherMarker) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	for name, script := range launchers {
+		path := filepath.Join(dir, name)
+		if data, err := ioutil.ReadFile(path); err == nil && string(data) == script {
+			continue
+		}
+		if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// launchersInPath tells whether the launcher directory is one of the directories of PATH
+func launchersInPath(dir string) bool {
+	for _, path := range filepath.SplitList(os.Getenv("PATH")) {
+		if path == "" {
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			if strings.EqualFold(filepath.Clean(path), filepath.Clean(dir)) {
+				return true
+			}
+		} else if filepath.Clean(path) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// installLaunchers writes the launchers of installed commands and adds their directory to PATH
+func installLaunchers(ctx context.Context) error {
+	term := terminal.Get(ctx)
+	dir, err := launcherDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := writeLaunchers(); err != nil {
+		return err
+	}
+	if launchersInPath(dir) {
+		return nil
+	}
+	location, err := addToPath(dir)
+	if err != nil {
+		return err
+	}
+	term.Writeln(color.CyanString("Added %s to PATH in %s, open a new terminal to run installed commands as \"akamai-<command>\"", dir, location))
+	return nil
+}
+
+// FirstRunCheckLaunchers offers to add the directory of launchers of installed commands to PATH,
+// so that they can be run directly, e.g. as "akamai-dns". The question is asked once.
+func FirstRunCheckLaunchers(ctx context.Context, bannerShown bool) (bool, error) {
+	term := terminal.Get(ctx)
+	cfg := config.Get(ctx)
+	if _, ok := cfg.GetValue("cli", launchersInPathKey); ok || !launchersEnabled(ctx) {
+		return bannerShown, nil
+	}
+	dir, err := launcherDir()
+	if err != nil {
+		return bannerShown, err
+	}
+	if launchersInPath(dir) {
+		return bannerShown, nil
+	}
+	if !bannerShown {
+		terminal.ShowBanner(ctx)
+		bannerShown = true
+	}
+	answer, err := term.Confirm("Installed commands can also be run directly, e.g. as akamai-dns, would you like to add their launchers to your PATH? [Y/n]: ", true)
+	if err != nil {
+		return bannerShown, err
+	}
+	inPath := "no"
+	if answer {
+		if err := installLaunchers(ctx); err != nil {
+			term.Writeln(color.RedString("Unable to add %s to PATH: %s", dir, err))
+		} else {
+			inPath = "yes"
+		}
+	}
+	cfg.SetValue("cli", launchersInPathKey, inPath)
+	return bannerShown, cfg.Save(ctx)
+}
@@ -0,0 +1,81 @@
+// +build !windows
+
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// launcherFileName returns the name of the launcher of a command
+func launcherFileName(name string) string {
+	return "akamai-" + name
+}
+
+// launcherScript returns a shell script running the command with the Akamai CLI executable at self
+func launcherScript(self, name string) string {
+	return fmt.Sprintf("#!/bin/sh\n# %s, runs \"akamai %s\".\nexec '%s' %s \"$@\"\n", launcherMarker, name, strings.ReplaceAll(self, "'", `'\''`), name)
+}
+
+// addToPath adds dir to PATH in the startup file of the shell of the user and returns the file
+func addToPath(dir string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	quoted := `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(dir) + `"`
+	profile := filepath.Join(home, ".profile")
+	line := fmt.Sprintf("export PATH=%s:\"$PATH\"", quoted)
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		profile = filepath.Join(home, ".zshrc")
+	case "bash":
+		profile = filepath.Join(home, ".bashrc")
+		if runtime.GOOS == "darwin" {
+			profile = filepath.Join(home, ".bash_profile")
+		}
+	case "fish":
+		profile = filepath.Join(home, ".config", "fish", "config.fish")
+		line = fmt.Sprintf("set -gx PATH %s $PATH", quoted)
+	}
+
+	data, err := ioutil.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if strings.Contains(string(data), line) {
+		return profile, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fmt.Fprintf(f, "\n# launchers of commands installed with Akamai CLI\n%s\n", line); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return profile, f.Close()
+}
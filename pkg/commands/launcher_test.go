@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteLaunchers(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+	binDir := filepath.Join(cliHome, ".akamai-cli", "bin")
+	writeStorePackage(t, srcDir, "cli-dns", `{"commands": [{"name": "dns"}, {"name": "Zone"}, {"name": "bad name"}]}`)
+	writeStorePackage(t, srcDir, "cli-purge", `{"commands": [{"name": "purge"}]}`)
+
+	require.NoError(t, writeLaunchers())
+	for _, name := range []string{"dns", "zone", "purge"} {
+		data, err := ioutil.ReadFile(filepath.Join(binDir, launcherFileName(name)))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), launcherMarker)
+		assert.Contains(t, string(data), " "+name+" ")
+	}
+	files, err := ioutil.ReadDir(binDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 3, "no launcher is written for unsafe names")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "akamai-custom"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.RemoveAll(filepath.Join(srcDir, "cli-purge")))
+	require.NoError(t, writeLaunchers())
+	_, err = os.Stat(filepath.Join(binDir, launcherFileName("purge")))
+	assert.True(t, os.IsNotExist(err), "launchers of uninstalled commands are removed")
+	assert.FileExists(t, filepath.Join(binDir, "akamai-custom"), "other files are left alone")
+	assert.FileExists(t, filepath.Join(binDir, launcherFileName("dns")))
+}
+
+func TestLaunchersInPath(t *testing.T) {
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	dir := filepath.Join(os.TempDir(), "akamai-bin")
+	require.NoError(t, os.Setenv("PATH", strings.Join([]string{os.TempDir(), dir + string(filepath.Separator)}, string(filepath.ListSeparator))))
+	assert.True(t, launchersInPath(dir))
+	require.NoError(t, os.Setenv("PATH", os.TempDir()))
+	assert.False(t, launchersInPath(dir))
+}
+
+func TestAddToPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("PATH of the user is stored in the registry on Windows")
+	}
+	home, err := ioutil.TempDir("", "cli-profile")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(home))
+	}()
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	defer os.Setenv("SHELL", os.Getenv("SHELL"))
+	require.NoError(t, os.Setenv("HOME", home))
+
+	tests := map[string]struct {
+		shell    string
+		profile  string
+		expected string
+	}{
+		"zsh":   {shell: "/bin/zsh", profile: ".zshrc", expected: `export PATH="/opt/akamai/bin":"$PATH"`},
+		"fish":  {shell: "/usr/bin/fish", profile: ".config/fish/config.fish", expected: `set -gx PATH "/opt/akamai/bin" $PATH`},
+		"other": {shell: "/bin/ksh", profile: ".profile", expected: `export PATH="/opt/akamai/bin":"$PATH"`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("SHELL", test.shell))
+			for i := 0; i < 2; i++ {
+				profile, err := addToPath("/opt/akamai/bin")
+				require.NoError(t, err)
+				assert.Equal(t, filepath.Join(home, test.profile), profile)
+			}
+			data, err := ioutil.ReadFile(filepath.Join(home, test.profile))
+			require.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(string(data), test.expected), "PATH is extended once")
+		})
+	}
+}
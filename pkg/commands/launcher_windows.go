@@ -0,0 +1,56 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// launcherFileName returns the name of the launcher of a command
+func launcherFileName(name string) string {
+	return "akamai-" + name + ".cmd"
+}
+
+// launcherScript returns a batch file running the command with the Akamai CLI executable at self
+func launcherScript(self, name string) string {
+	return fmt.Sprintf("@echo off\r\nrem %s, runs \"akamai %s\".\r\n\"%s\" %s %%*\r\n", launcherMarker, name, self, name)
+}
+
+// addToPath adds dir to the PATH of the user in the registry and returns the registry key
+func addToPath(dir string) (string, error) {
+	const location = `HKEY_CURRENT_USER\Environment`
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+	path, _, err := key.GetStringValue("Path")
+	if err != nil && err != registry.ErrNotExist {
+		return "", err
+	}
+	for _, entry := range filepath.SplitList(path) {
+		if strings.EqualFold(filepath.Clean(entry), filepath.Clean(dir)) {
+			return location, nil
+		}
+	}
+	if path != "" && !strings.HasSuffix(path, ";") {
+		path += ";"
+	}
+	return location, key.SetExpandStringValue("Path", path+dir)
+}
@@ -0,0 +1,110 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// maintenanceWindow is a recurring period of local time in which upgrades of Akamai CLI or packages are allowed
+type maintenanceWindow struct {
+	days [7]bool
+	// from and to are offsets from midnight, equal values mean the whole day
+	from, to time.Duration
+}
+
+// parseMaintenanceWindow parses a window in "<days> [HH:MM-HH:MM]" format. Days are a comma separated list
+// of day names or ranges, such as "mon,wed" or "fri-mon", or "daily". Hours may span midnight.
+func parseMaintenanceWindow(val string) (*maintenanceWindow, error) {
+	fields := strings.Fields(strings.ToLower(val))
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid maintenance window %q, expected a value such as \"sat-sun\" or \"mon-fri 22:00-06:00\"", val)
+	}
+	w := &maintenanceWindow{}
+	for _, item := range strings.Split(fields[0], ",") {
+		if item == "daily" || item == "*" {
+			w.days = [7]bool{true, true, true, true, true, true, true}
+			continue
+		}
+		bounds := strings.SplitN(item, "-", 2)
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q in maintenance window %q", bounds[0], val)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[bounds[1]]; !ok {
+				return nil, fmt.Errorf("invalid day %q in maintenance window %q", bounds[1], val)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	if len(fields) == 2 {
+		hours := strings.SplitN(fields[1], "-", 2)
+		if len(hours) != 2 {
+			return nil, fmt.Errorf("invalid hours %q in maintenance window %q, expected HH:MM-HH:MM", fields[1], val)
+		}
+		var err error
+		if w.from, err = parseTimeOfDay(hours[0]); err != nil {
+			return nil, fmt.Errorf("invalid hours %q in maintenance window %q, expected HH:MM-HH:MM", fields[1], val)
+		}
+		if w.to, err = parseTimeOfDay(hours[1]); err != nil {
+			return nil, fmt.Errorf("invalid hours %q in maintenance window %q, expected HH:MM-HH:MM", fields[1], val)
+		}
+	}
+	return w, nil
+}
+
+func parseTimeOfDay(val string) (time.Duration, error) {
+	t, err := time.Parse("15:04", val)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// allows returns true if given time falls into the window
+func (w *maintenanceWindow) allows(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	switch {
+	case w.from == w.to:
+		return w.days[t.Weekday()]
+	case w.from < w.to:
+		return w.days[t.Weekday()] && offset >= w.from && offset < w.to
+	}
+	// the window spans midnight, early hours belong to the window started on the previous day
+	if offset >= w.from {
+		return w.days[t.Weekday()]
+	}
+	return offset < w.to && w.days[(t.Weekday()+6)%7]
+}
@@ -0,0 +1,130 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/urfave/cli/v2"
+)
+
+// commandOwnerSection is the config section mapping command names provided by more than one package
+// to the package which should run them, e.g. "command-owner.purge = cli-purge"
+const commandOwnerSection = "command-owner"
+
+// namespaceSeparator separates package and command name in namespaced invocations, e.g. "akamai cli-purge:purge"
+const namespaceSeparator = ":"
+
+// splitCommandName returns package and command name of a namespaced command, package name is empty for regular commands
+func splitCommandName(name string) (string, string) {
+	if i := strings.Index(name, namespaceSeparator); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// packageCliCommands returns commands of an installed package, along with hidden namespaced variants of each of them.
+// Commands with names already present in registered are only returned in their namespaced form and listed as collisions.
+// Names of returned commands are added to registered.
+func packageCliCommands(registered map[string]bool, packageName string, pkg subcommands, gitRepo git.Repository, langManager packages.LangManager) ([]*cli.Command, []string) {
+	commands := make([]*cli.Command, 0)
+	collisions := make([]string, 0)
+	for _, command := range subcommandToCliCommands(pkg, gitRepo, langManager) {
+		namespaced := *command
+		namespaced.Name = packageName + namespaceSeparator + command.Name
+		namespaced.Aliases = nil
+		namespaced.Hidden = true
+		commands = append(commands, &namespaced)
+
+		if registered[command.Name] {
+			collisions = append(collisions, command.Name)
+			continue
+		}
+		registered[command.Name] = true
+		commands = append(commands, command)
+	}
+	return commands, collisions
+}
+
+// commandPackages returns names of installed packages providing given command
+func commandPackages(name string) []string {
+	owners := make([]string, 0)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			if strings.EqualFold(cmd.Name, name) {
+				owners = append(owners, filepath.Base(dir))
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// commandOwner returns the package which should run given command if more than one package provides it.
+// The package set in command-owner config section is preferred, otherwise the first one is used.
+// Empty string is returned if there is no collision.
+func commandOwner(ctx context.Context, name string) string {
+	owners := commandPackages(name)
+	if len(owners) < 2 {
+		return ""
+	}
+	if preferred, ok := config.Get(ctx).GetValue(commandOwnerSection, name); ok && preferred != "" {
+		for _, owner := range owners {
+			if owner == preferred {
+				return owner
+			}
+		}
+		log.FromContext(ctx).Warnf("Package %s set in %s.%s does not provide the command, using %s", preferred, commandOwnerSection, name, owners[0])
+	}
+	return owners[0]
+}
+
+// findCommandExec looks for the executable of a regular or namespaced command,
+// limiting the search to a single package when the command is namespaced or provided by more than one package
+func findCommandExec(ctx context.Context, langManager packages.LangManager, name string) ([]string, error) {
+	packageName, commandName := splitCommandName(name)
+	if packageName == "" {
+		packageName = commandOwner(ctx, commandName)
+	}
+	if packageName == "" {
+		return findExec(ctx, langManager, commandName)
+	}
+
+	if strings.ContainsAny(packageName, `/\`) || packageName == ".." {
+		return nil, fmt.Errorf("invalid package name: %s", packageName)
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil, err
+	}
+	packageDir := filepath.Join(srcPath, packageName)
+	paths := strings.Join([]string{packageDir, filepath.Join(packageDir, "bin")}, string(os.PathListSeparator))
+	return findExecInPaths(ctx, langManager, commandName, paths)
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSplitCommandName(t *testing.T) {
+	tests := map[string]struct {
+		name            string
+		expectedPackage string
+		expectedCommand string
+	}{
+		"regular command": {
+			name:            "purge",
+			expectedCommand: "purge",
+		},
+		"namespaced command": {
+			name:            "cli-purge:purge",
+			expectedPackage: "cli-purge",
+			expectedCommand: "purge",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pkg, cmd := splitCommandName(test.name)
+			assert.Equal(t, test.expectedPackage, pkg)
+			assert.Equal(t, test.expectedCommand, cmd)
+		})
+	}
+}
+
+func TestPackageCliCommands(t *testing.T) {
+	registered := map[string]bool{"purge": true}
+	pkg := subcommands{Commands: []command{{Name: "purge", Aliases: []string{"p"}}, {Name: "fast-purge"}}}
+
+	cmds, collisions := packageCliCommands(registered, "cli-other", pkg, nil, nil)
+
+	assert.Equal(t, []string{"purge"}, collisions)
+	names := make([]string, 0)
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name)
+		if cmd.Hidden {
+			assert.Empty(t, cmd.Aliases)
+		}
+	}
+	assert.Equal(t, []string{"cli-other:purge", "cli-other:fast-purge", "fast-purge"}, names)
+	assert.True(t, registered["fast-purge"])
+}
+
+func TestFindCommandExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires executable scripts")
+	}
+	cliHome, err := ioutil.TempDir("", "cli-home")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+	}()
+	srcPath := filepath.Join(cliHome, ".akamai-cli", "src")
+	for _, pkg := range []string{"cli-a", "cli-b"} {
+		binPath := filepath.Join(srcPath, pkg, "bin")
+		require.NoError(t, os.MkdirAll(binPath, 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, pkg, "cli.json"), []byte(`{"commands": [{"name": "dup"}]}`), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(binPath, "akamai-dup"), []byte("#!/bin/sh\n"), 0755))
+	}
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+
+	tests := map[string]struct {
+		name            string
+		init            func(*config.Mock)
+		expectedPackage string
+		withError       bool
+	}{
+		"namespaced command": {
+			name:            "cli-b:dup",
+			init:            func(m *config.Mock) {},
+			expectedPackage: "cli-b",
+		},
+		"collision, no preference": {
+			name: "dup",
+			init: func(m *config.Mock) {
+				m.On("GetValue", "command-owner", "dup").Return("", false).Once()
+			},
+			expectedPackage: "cli-a",
+		},
+		"collision, preferred package": {
+			name: "dup",
+			init: func(m *config.Mock) {
+				m.On("GetValue", "command-owner", "dup").Return("cli-b", true).Once()
+			},
+			expectedPackage: "cli-b",
+		},
+		"collision, preferred package does not provide the command": {
+			name: "dup",
+			init: func(m *config.Mock) {
+				m.On("GetValue", "command-owner", "dup").Return("cli-c", true).Once()
+			},
+			expectedPackage: "cli-a",
+		},
+		"invalid package name": {
+			name:      "../cli-b:dup",
+			init:      func(m *config.Mock) {},
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			test.init(cfg)
+			ctx := config.Context(context.Background(), cfg)
+
+			executable, err := findCommandExec(ctx, &packages.Mock{}, test.name)
+
+			cfg.AssertExpectations(t)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{filepath.Join(srcPath, test.expectedPackage, "bin", "akamai-dup")}, executable)
+		})
+	}
+}
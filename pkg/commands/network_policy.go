@@ -0,0 +1,378 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+)
+
+// networkPolicyKey is the cli setting choosing whether installed commands may only connect to the hosts declared
+// in the "hosts" of their cli.json
+const networkPolicyKey = "network-policy"
+
+// network policies of installed commands
+const (
+	// networkPolicyOff lets commands connect anywhere, this is the default
+	networkPolicyOff = "off"
+	// networkPolicyAudit logs connections to undeclared hosts and lets them through
+	networkPolicyAudit = "audit"
+	// networkPolicyEnforce logs and refuses connections to undeclared hosts
+	networkPolicyEnforce = "enforce"
+)
+
+// proxyEnv are the variables pointing commands to the proxy of the network policy
+var proxyEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"}
+
+// networkPolicy returns the configured network policy of installed commands, warnings for unknown values
+func networkPolicy(ctx context.Context) string {
+	val, _ := config.Get(ctx).GetValue("cli", networkPolicyKey)
+	switch mode := strings.ToLower(strings.TrimSpace(val)); mode {
+	case networkPolicyAudit, networkPolicyEnforce:
+		return mode
+	case "", networkPolicyOff:
+	default:
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", networkPolicyKey, val)
+	}
+	return networkPolicyOff
+}
+
+// dispatchNetworkPolicy returns the network policy applying to the command, off if its package declares no hosts
+func dispatchNetworkPolicy(ctx context.Context, d *packageDispatch) string {
+	if len(d.pkg.Hosts) == 0 {
+		return networkPolicyOff
+	}
+	return networkPolicy(ctx)
+}
+
+// hostAllowed reports whether host matches one of the patterns, which are host names, optionally starting
+// with "*." to match any subdomain, such as "*.luna.akamaiapis.net"
+func hostAllowed(patterns []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if suffix := strings.TrimPrefix(pattern, "*"); suffix != pattern {
+			if strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// networkProxy is the HTTP proxy installed commands are pointed to while the network policy applies. It forwards
+// requests and HTTPS tunnels to the hosts declared by the package, through the proxy configured before, if any.
+type networkProxy struct {
+	pkgName   string
+	hosts     []string
+	enforce   bool
+	logger    log.Logger
+	pkgLogger log.Logger
+	term      terminal.Terminal
+	upstream  upstreamProxy
+	transport *http.Transport
+
+	mu       sync.Mutex
+	reported map[string]bool
+}
+
+// upstreamProxy is the proxy configured in the environment before the network proxy replaced it
+type upstreamProxy struct {
+	http, https string
+	noProxy     []string
+}
+
+// startNetworkProxy starts the proxy of the network policy for the command, if the policy is enabled and its package
+// declares hosts, and points the command to it with the proxy environment variables. The returned function stops
+// the proxy and restores the environment.
+func startNetworkProxy(ctx context.Context, d *packageDispatch, pkgLogger log.Logger) (func(), error) {
+	policy := dispatchNetworkPolicy(ctx, d)
+	if policy == networkPolicyOff {
+		return func() {}, nil
+	}
+	logger := log.FromContext(ctx)
+
+	p := &networkProxy{
+		pkgName:   d.Package,
+		hosts:     d.pkg.Hosts,
+		enforce:   policy == networkPolicyEnforce,
+		logger:    logger,
+		pkgLogger: pkgLogger,
+		term:      terminal.Get(ctx),
+		upstream:  upstreamFromEnv(),
+		reported:  make(map[string]bool),
+	}
+	p.transport = &http.Transport{Proxy: p.upstream.proxyURL}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the proxy of the network policy: %w", err)
+	}
+	server := &http.Server{Handler: p}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	logger.Debugf("Restricting %s to hosts %s with proxy %s", d.Command, strings.Join(p.hosts, ", "), listener.Addr())
+
+	proxyURL := "http://" + listener.Addr().String()
+	restore := make(map[string]*string)
+	for _, name := range append(proxyEnv, "NO_PROXY", "no_proxy") {
+		if val, ok := os.LookupEnv(name); ok {
+			restore[name] = &val
+		} else {
+			restore[name] = nil
+		}
+		val := proxyURL
+		if strings.EqualFold(name, "NO_PROXY") {
+			val = ""
+		}
+		if err := os.Setenv(name, val); err != nil {
+			_ = server.Close()
+			return nil, err
+		}
+	}
+	return func() {
+		_ = server.Close()
+		p.transport.CloseIdleConnections()
+		for name, val := range restore {
+			if val == nil {
+				_ = os.Unsetenv(name)
+			} else {
+				_ = os.Setenv(name, *val)
+			}
+		}
+	}, nil
+}
+
+// ServeHTTP forwards plain HTTP requests and tunnels CONNECT requests of allowed hosts
+func (p *networkProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if r.Method == http.MethodConnect {
+		host = r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+	}
+	if !p.allow(host) {
+		http.Error(w, fmt.Sprintf("%s is not among the hosts declared by package %s", host, p.pkgName), http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+// allow reports whether the command may connect to host, logging the first connection to each undeclared host
+func (p *networkProxy) allow(host string) bool {
+	if hostAllowed(p.hosts, host) {
+		return true
+	}
+	p.mu.Lock()
+	first := !p.reported[host]
+	p.reported[host] = true
+	p.mu.Unlock()
+	if first {
+		action := "allowed by the audit policy"
+		if p.enforce {
+			action = "refused"
+		}
+		p.logger.Warnf("Connection of package %s to undeclared host %s %s", p.pkgName, host, action)
+		p.pkgLogger.WithField("host", host).WithField("enforced", p.enforce).Warn("Connection to undeclared host")
+		if p.enforce {
+			p.term.WriteErrorf("%s\n", color.YellowString("Connection to %s refused, it is not among the hosts declared by package %s (cli.%s is %s)", host, p.pkgName, networkPolicyKey, networkPolicyEnforce))
+		}
+	}
+	return !p.enforce
+}
+
+func (p *networkProxy) forward(w http.ResponseWriter, r *http.Request) {
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	req.Header.Del("Proxy-Connection")
+	req.Header.Del("Proxy-Authorization")
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	for key, values := range resp.Header {
+		for _, val := range values {
+			w.Header().Add(key, val)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (p *networkProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	target, err := p.upstream.dial(r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		_ = target.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		_ = target.Close()
+		return
+	}
+	_, _ = client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, target)
+		done <- struct{}{}
+	}()
+	<-done
+	_ = client.Close()
+	_ = target.Close()
+}
+
+func upstreamFromEnv() upstreamProxy {
+	get := func(names ...string) string {
+		for _, name := range names {
+			if val := os.Getenv(name); val != "" {
+				return val
+			}
+		}
+		return ""
+	}
+	u := upstreamProxy{
+		http:  get("HTTP_PROXY", "http_proxy"),
+		https: get("HTTPS_PROXY", "https_proxy"),
+	}
+	for _, host := range strings.Split(get("NO_PROXY", "no_proxy"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			u.noProxy = append(u.noProxy, host)
+		}
+	}
+	return u
+}
+
+// bypassed reports whether host is excluded from the upstream proxy by NO_PROXY
+func (u upstreamProxy) bypassed(host string) bool {
+	for _, pattern := range u.noProxy {
+		if pattern == "*" {
+			return true
+		}
+		pattern = strings.TrimPrefix(pattern, "*")
+		if strings.EqualFold(host, strings.TrimPrefix(pattern, ".")) || strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(strings.TrimPrefix(pattern, "."))) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyURL returns the upstream proxy of a plain HTTP request, nil to connect directly
+func (u upstreamProxy) proxyURL(r *http.Request) (*url.URL, error) {
+	if u.http == "" || u.bypassed(r.URL.Hostname()) {
+		return nil, nil
+	}
+	return parseProxyURL(u.http)
+}
+
+// dial connects to address, a host and port, through the upstream HTTPS proxy if there is one
+func (u upstreamProxy) dial(address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if u.https == "" || u.bypassed(host) {
+		return net.DialTimeout("tcp", address, 30*time.Second)
+	}
+	proxyURL, err := parseProxyURL(u.https)
+	if err != nil {
+		return nil, err
+	}
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+	}
+	conn, err := net.DialTimeout("tcp", proxyAddr, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	req := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: address}, Host: address, Header: make(http.Header)}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		req.SetBasicAuth(proxyURL.User.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyURL.Host, address, resp.Status)
+	}
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	return url.Parse(proxy)
+}
+
+// bufferedConn reads what the reader of the CONNECT response buffered before the rest of the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestHostAllowed(t *testing.T) {
+	patterns := []string{"api.example.com", "*.luna.akamaiapis.net"}
+	tests := map[string]struct {
+		host    string
+		allowed bool
+	}{
+		"declared host":         {host: "api.example.com", allowed: true},
+		"case insensitive":      {host: "API.Example.com.", allowed: true},
+		"subdomain of wildcard": {host: "akab-1.luna.akamaiapis.net", allowed: true},
+		"domain of wildcard":    {host: "luna.akamaiapis.net"},
+		"subdomain of host":     {host: "www.api.example.com"},
+		"similar domain":        {host: "evil-luna.akamaiapis.net"},
+		"undeclared host":       {host: "example.org"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.allowed, hostAllowed(patterns, test.host))
+		})
+	}
+}
+
+func TestNetworkProxy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}))
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tunneled"))
+	}))
+	defer tlsSrv.Close()
+
+	tests := map[string]struct {
+		policy   string
+		hosts    []string
+		proxied  bool
+		allowed  bool
+		violated bool
+	}{
+		"declared host":             {policy: networkPolicyEnforce, hosts: []string{"127.0.0.1"}, proxied: true, allowed: true},
+		"undeclared host, enforced": {policy: networkPolicyEnforce, hosts: []string{"api.example.com"}, proxied: true, violated: true},
+		"undeclared host, audited":  {policy: networkPolicyAudit, hosts: []string{"api.example.com"}, proxied: true, allowed: true, violated: true},
+		"policy off":                {policy: networkPolicyOff, hosts: []string{"api.example.com"}, allowed: true},
+		"no hosts declared":         {policy: networkPolicyEnforce, allowed: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("HTTPS_PROXY", "http://upstream.example.com:3128"))
+			require.NoError(t, os.Setenv("NO_PROXY", "127.0.0.1"))
+			defer func() {
+				require.NoError(t, os.Unsetenv("HTTPS_PROXY"))
+				require.NoError(t, os.Unsetenv("NO_PROXY"))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", networkPolicyKey).Return(test.policy, true).Maybe()
+			term := &terminal.Mock{}
+			if test.violated && test.policy == networkPolicyEnforce {
+				term.On("WriteErrorf", "%s\n", mock.Anything).Return().Once()
+			}
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+			pkgLog := &bytes.Buffer{}
+			d := &packageDispatch{Command: "dns", Package: "cli-dns", pkg: subcommands{Hosts: test.hosts}}
+
+			stop, err := startNetworkProxy(ctx, d, log.New(pkgLog))
+			require.NoError(t, err)
+			proxy := os.Getenv("HTTP_PROXY")
+			if !test.proxied {
+				stop()
+				assert.Empty(t, proxy)
+				return
+			}
+			assert.Equal(t, proxy, os.Getenv("HTTPS_PROXY"))
+			assert.Empty(t, os.Getenv("NO_PROXY"))
+			proxyURL, err := url.Parse(proxy)
+			require.NoError(t, err)
+
+			client := tlsSrv.Client()
+			client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+			for _, target := range []struct{ url, body string }{{srv.URL, "plain"}, {tlsSrv.URL, "tunneled"}} {
+				resp, err := client.Get(target.url)
+				if !test.allowed && target.url == tlsSrv.URL {
+					require.Error(t, err, "the tunnel is refused")
+					continue
+				}
+				require.NoError(t, err)
+				body, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+				if !test.allowed {
+					assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+					assert.Contains(t, string(body), "127.0.0.1 is not among the hosts declared by package cli-dns")
+					continue
+				}
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, target.body, string(body))
+			}
+			stop()
+
+			assert.Equal(t, "http://upstream.example.com:3128", os.Getenv("HTTPS_PROXY"), "environment is restored")
+			assert.Equal(t, "127.0.0.1", os.Getenv("NO_PROXY"))
+			_, ok := os.LookupEnv("HTTP_PROXY")
+			assert.False(t, ok)
+			if test.violated {
+				assert.Contains(t, pkgLog.String(), "Connection to undeclared host")
+				assert.Contains(t, pkgLog.String(), "host=127.0.0.1")
+			} else {
+				assert.Empty(t, pkgLog.String())
+			}
+			term.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats of built-in commands which are not passed to packages
+const (
+	// formatPlain is the output format requested with --plain
+	formatPlain = "plain"
+	// formatCSV writes comma-separated values with a header line
+	formatCSV = "csv"
+)
+
+// outputFormats lists the formats accepted by the --output flag of built-in commands
+var outputFormats = append(append([]string{}, plugin.OutputFormats...), formatCSV)
+
+// outputFlag returns the --output flag of built-in commands supporting machine-readable output.
+// It defaults to the format requested with the global --output flag or cli.output-format setting.
+func outputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Usage:   fmt.Sprintf("Output format: %s", strings.Join(outputFormats, ", ")),
+		EnvVars: []string{plugin.OutputFormatEnv},
+	}
+}
+
+// plainFlag returns the --plain flag of built-in commands listing packages or commands
+func plainFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "plain",
+		Usage: "Print tab-separated fields in a fixed order, without colors or truncation",
+	}
+}
+
+// outputFormat returns the format requested with --plain or --output flag, plugin.FormatTable if none was requested.
+// --plain takes precedence, so that it also applies when a default format is set in the environment.
+func outputFormat(c *cli.Context) (string, error) {
+	if c.Bool("plain") {
+		return formatPlain, nil
+	}
+	format := strings.ToLower(c.String("output"))
+	if format == "" {
+		return plugin.FormatTable, nil
+	}
+	if _, ok := renderers[format]; !ok || format == formatPlain {
+		return "", cli.Exit(color.RedString("Unsupported output format: %s, expected one of: %s", format, strings.Join(outputFormats, ", ")), 1)
+	}
+	return format, nil
+}
+
+// renderedOutput holds the results of a built-in command in the forms output formats are rendered from.
+// Value is written as JSON or YAML. Rows, one for each result, are written as CSV under a header line of Columns,
+// and as tab-separated lines with --plain. Commands without Columns do not support CSV and plain output.
+type renderedOutput struct {
+	Value   interface{}
+	Columns []string
+	Rows    [][]string
+	// Table prints the results for humans, by default Rows are printed as a table under Columns in upper case
+	Table func() error
+}
+
+// renderer writes the results of a built-in command in one output format
+type renderer func(ctx context.Context, out renderedOutput) error
+
+// renderers maps output formats of built-in commands to the renderer writing them,
+// commands call render with the format returned by outputFormat instead of formatting results by themselves
+var renderers = map[string]renderer{
+	plugin.FormatTable: renderTable,
+	plugin.FormatJSON:  renderValue(plugin.FormatJSON),
+	plugin.FormatYAML:  renderValue(plugin.FormatYAML),
+	formatCSV:          renderCSV,
+	formatPlain:        renderPlain,
+}
+
+// render writes out in given format, failing if c does not support it
+func render(c *cli.Context, format string, out renderedOutput) error {
+	r, ok := renderers[format]
+	if !ok || ((format == formatCSV || format == formatPlain) && out.Columns == nil) {
+		return cli.Exit(color.RedString("Output format %s is not supported by \"%s\"", format, c.Command.FullName()), 1)
+	}
+	return r(c.Context, out)
+}
+
+func renderValue(format string) renderer {
+	return func(ctx context.Context, out renderedOutput) error {
+		return writeOutput(ctx, format, out.Value)
+	}
+}
+
+func renderTable(ctx context.Context, out renderedOutput) error {
+	if out.Table != nil {
+		return out.Table()
+	}
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  %s\n", strings.ToUpper(strings.Join(out.Columns, "\t")))
+	for _, row := range out.Rows {
+		fields := make([]string, 0, len(row))
+		for _, field := range row {
+			if field == "" {
+				field = "-"
+			}
+			fields = append(fields, field)
+		}
+		fmt.Fprintf(w, "  %s\n", strings.Join(fields, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	terminal.Get(ctx).Printf("%s", buf.String())
+	return nil
+}
+
+// renderCSV writes rows in the order of results, unlike plain output which is sorted
+func renderCSV(ctx context.Context, out renderedOutput) error {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	if err := w.Write(out.Columns); err != nil {
+		return err
+	}
+	if err := w.WriteAll(out.Rows); err != nil {
+		return err
+	}
+	terminal.Get(ctx).Printf("%s", buf.String())
+	return nil
+}
+
+func renderPlain(ctx context.Context, out renderedOutput) error {
+	writePlain(ctx, out.Rows)
+	return nil
+}
+
+// jsonOutput returns true if results were requested as JSON with the global --json flag or the --json flag of the command
+func jsonOutput(c *cli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("json") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonResults prepares c for a command printing its results as JSON if they were requested, see jsonOutput.
+// Status messages of the command are then written to stderr, and the returned function prints v to stdout.
+// If JSON output was not requested, c is left unchanged and the returned function does nothing.
+func jsonResults(c *cli.Context) func(v interface{}) error {
+	if !jsonOutput(c) {
+		return func(interface{}) error { return nil }
+	}
+	ctx := c.Context
+	c.Context = terminal.Context(c.Context, terminal.StatusTerminal(terminal.Get(c.Context)))
+	return func(v interface{}) error {
+		return writeOutput(ctx, plugin.FormatJSON, v)
+	}
+}
+
+// writeOutput prints v as JSON or YAML, depending on format.
+// YAML documents use the same field names as JSON ones, so v is converted through its JSON representation.
+func writeOutput(ctx context.Context, format string, v interface{}) error {
+	term := terminal.Get(ctx)
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == plugin.FormatYAML {
+		var doc interface{}
+		if err := json.Unmarshal(out, &doc); err != nil {
+			return err
+		}
+		if out, err = yaml.Marshal(doc); err != nil {
+			return err
+		}
+		term.Printf("%s", string(out))
+		return nil
+	}
+	term.Writeln(string(out))
+	return nil
+}
+
+// writePlain prints rows as lines of tab-separated fields, sorted byte-wise so that the order does not depend on the locale.
+// Tabs and line breaks inside a field are replaced with spaces and empty fields are printed as "-",
+// so that every line of the output has the same number of fields.
+func writePlain(ctx context.Context, rows [][]string) {
+	term := terminal.Get(ctx)
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		fields := make([]string, 0, len(row))
+		for _, field := range row {
+			field = strings.Join(strings.Fields(field), " ")
+			if field == "" {
+				field = "-"
+			}
+			fields = append(fields, field)
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		term.Writeln(line)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"os"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	results := renderedOutput{
+		Value:   []map[string]string{{"name": "dns", "description": "Manage DNS, zones and records"}, {"name": "purge"}},
+		Columns: []string{"name", "description"},
+		Rows:    [][]string{{"dns", "Manage DNS, zones and records"}, {"purge", ""}},
+	}
+	tests := map[string]struct {
+		args      []string
+		out       renderedOutput
+		init      func(*terminal.Mock)
+		withError string
+	}{
+		"table of rows": {
+			out: results,
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "%s", []interface{}{"  NAME   DESCRIPTION\n  dns    Manage DNS, zones and records\n  purge  -\n"}).Return().Once()
+			},
+		},
+		"custom table": {
+			out: renderedOutput{Value: results.Value, Table: func() error {
+				return nil
+			}},
+			init: func(m *terminal.Mock) {},
+		},
+		"json": {
+			args: []string{"--output", "json"},
+			out:  results,
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{"[\n  {\n    \"description\": \"Manage DNS, zones and records\",\n    \"name\": \"dns\"\n  },\n  {\n    \"name\": \"purge\"\n  }\n]"}).Return(0, nil).Once()
+			},
+		},
+		"yaml": {
+			args: []string{"--output", "yaml"},
+			out:  results,
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "%s", []interface{}{"- description: Manage DNS, zones and records\n  name: dns\n- name: purge\n"}).Return().Once()
+			},
+		},
+		"csv": {
+			args: []string{"--output", "CSV"},
+			out:  results,
+			init: func(m *terminal.Mock) {
+				m.On("Printf", "%s", []interface{}{"name,description\ndns,\"Manage DNS, zones and records\"\npurge,\n"}).Return().Once()
+			},
+		},
+		"plain": {
+			args: []string{"--plain", "--output", "json"},
+			out:  results,
+			init: func(m *terminal.Mock) {
+				m.On("Writeln", []interface{}{"dns\tManage DNS, zones and records"}).Return(0, nil).Once()
+				m.On("Writeln", []interface{}{"purge\t-"}).Return(0, nil).Once()
+			},
+		},
+		"csv without rows": {
+			args:      []string{"--output", "csv"},
+			out:       renderedOutput{Value: results.Value},
+			init:      func(m *terminal.Mock) {},
+			withError: `Output format csv is not supported by "render"`,
+		},
+		"plain as output format": {
+			args:      []string{"--output", "plain"},
+			out:       results,
+			init:      func(m *terminal.Mock) {},
+			withError: "Unsupported output format: plain, expected one of: table, json, yaml, csv",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Unsetenv(plugin.OutputFormatEnv))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			test.init(m.term)
+			command := &cli.Command{
+				Name: "render",
+				Action: func(c *cli.Context) error {
+					format, err := outputFormat(c)
+					if err != nil {
+						return err
+					}
+					return render(c, format, test.out)
+				},
+				Flags: []cli.Flag{plainFlag(), outputFlag()},
+			}
+			app, ctx := setupTestApp(command, m)
+
+			err := app.RunContext(ctx, append(append(os.Args[0:1], "render"), test.args...))
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
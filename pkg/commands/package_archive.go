@@ -0,0 +1,451 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// archiveManifestFile is the first entry of package archives, describing the package they contain
+	archiveManifestFile    = "akamai-package.json"
+	archiveManifestVersion = 1
+)
+
+// archiveManifest describes the package bundled in an archive written by "package export"
+type archiveManifest struct {
+	Version        int       `json:"version"`
+	Name           string    `json:"name"`
+	Source         string    `json:"source,omitempty"`
+	Commit         string    `json:"commit,omitempty"`
+	PackageVersion string    `json:"packageVersion,omitempty"`
+	Platform       string    `json:"platform"`
+	Created        time.Time `json:"created"`
+}
+
+func cmdPackageExport(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE EXPORT START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE EXPORT FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE EXPORT ERROR: %v", e.Error())
+			}
+		}()
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+		dir := installedPackageDir(c, langManager, c.Args().First())
+		if dir == "" {
+			return packageNotFound(c.Args().First())
+		}
+		sub, err := readPackage(dir)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package: %s", err), 1)
+		}
+		manifest := archiveManifest{
+			Version:        archiveManifestVersion,
+			Name:           filepath.Base(dir),
+			PackageVersion: packageVersion(sub),
+			Platform:       binaryPlatform(withPlatform(command{})),
+			Created:        time.Now().UTC().Truncate(time.Second),
+		}
+		if source, err := git.OriginURL(dir); err == nil {
+			manifest.Source = source
+		}
+		if record, err := readInstallRecord(dir); err == nil {
+			manifest.Commit = record.Commit
+		}
+
+		out := c.String("out")
+		if out == "" {
+			out = manifest.Name + ".tar.gz"
+		}
+		term := terminal.Get(c.Context)
+		term.Spinner().Start("Exporting package %s...", manifest.Name)
+		if err := writePackageArchive(out, dir, manifest); err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to export package: %s", err), 1)
+		}
+		term.Spinner().OK()
+		term.Printf("Package %s exported to %s.\n", color.BlueString(manifest.Name), color.BlueString(out))
+		term.Printf("Install it without network access using \"%s\".\n", color.BlueString("%s install --file %s", tools.Self(), out))
+		return nil
+	}
+}
+
+// writePackageArchive writes the manifest and all the files of the package in dir, including its git repository,
+// downloaded binaries and installed dependencies, to a gzipped tarball at given path
+func writePackageArchive(out, dir string, manifest archiveManifest) (e error) {
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && e == nil {
+			e = err
+		}
+		if e != nil {
+			_ = os.Remove(out)
+		}
+	}()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: archiveManifestFile, Mode: 0644, Size: int64(len(data)), ModTime: manifest.Created, Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(manifest.Name, filepath.ToSlash(rel))
+		var link string
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// openPackageArchive opens a package archive and reads its manifest, the returned closer closes the archive file
+func openPackageArchive(archive string) (*tar.Reader, *archiveManifest, io.Closer, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, nil, fmt.Errorf("not a package archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil || header.Name != archiveManifestFile {
+		_ = file.Close()
+		return nil, nil, nil, fmt.Errorf("not a package archive: %s is missing", archiveManifestFile)
+	}
+	manifest := &archiveManifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		_ = file.Close()
+		return nil, nil, nil, fmt.Errorf("invalid %s: %w", archiveManifestFile, err)
+	}
+	if manifest.Version != archiveManifestVersion {
+		_ = file.Close()
+		return nil, nil, nil, fmt.Errorf("unsupported package archive version %d, expected %d", manifest.Version, archiveManifestVersion)
+	}
+	if manifest.Name == "" || manifest.Name != filepath.Base(manifest.Name) || strings.HasPrefix(manifest.Name, ".") {
+		_ = file.Close()
+		return nil, nil, nil, fmt.Errorf("invalid package name %q", manifest.Name)
+	}
+	return tr, manifest, file, nil
+}
+
+// extractPackageArchive extracts the files of the package in the archive to dir, which must not exist yet.
+// Files are expected in the name directory of the archive, or at its root if name is empty.
+// Files are extracted to a temporary directory next to dir first, so that a failure leaves no partial package behind.
+func extractPackageArchive(tr *tar.Reader, name, dir string) (e error) {
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e != nil {
+			_ = os.RemoveAll(tmp)
+		}
+	}()
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		rel := path.Clean(header.Name)
+		if name != "" {
+			if rel == name {
+				continue
+			}
+			if rel = strings.TrimPrefix(rel, name+"/"); rel == path.Clean(header.Name) {
+				return fmt.Errorf("file %s is outside of package %s", header.Name, name)
+			}
+		}
+		if rel == "." {
+			continue
+		}
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("file %s is outside of package %s", header.Name, filepath.Base(dir))
+		}
+		target := filepath.Join(tmp, filepath.FromSlash(rel))
+		if err := checkArchiveEntryPath(tmp, rel, header.Name); err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) || !strings.HasPrefix(filepath.Join(filepath.Dir(target), header.Linkname), tmp+string(filepath.Separator)) {
+				return fmt.Errorf("link %s points outside of package %s", header.Name, name)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			// hardlinks name their target as archive entries do, and it must be a file of the package extracted before
+			source := path.Clean(header.Linkname)
+			if name != "" {
+				if source = strings.TrimPrefix(source, name+"/"); source == path.Clean(header.Linkname) {
+					return fmt.Errorf("link %s points outside of package %s", header.Name, filepath.Base(dir))
+				}
+			}
+			if path.IsAbs(source) || source == "." || source == ".." || strings.HasPrefix(source, "../") {
+				return fmt.Errorf("link %s points outside of package %s", header.Name, filepath.Base(dir))
+			}
+			if err := checkArchiveEntryPath(tmp, source, header.Name); err != nil {
+				return err
+			}
+			sourcePath := filepath.Join(tmp, filepath.FromSlash(source))
+			if info, err := os.Lstat(sourcePath); err != nil || !info.Mode().IsRegular() {
+				return fmt.Errorf("link %s points to %s, which is not a file extracted before", header.Name, header.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Link(sourcePath, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := extractArchiveFile(tr, target, os.FileMode(header.Mode).Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(tmp, dir)
+}
+
+// checkArchiveEntryPath returns an error if the entry at rel in root, or any of its parent directories, is a link
+// extracted before. Links are only checked to point inside the package lexically, so writing through chained links
+// could still reach files outside of it.
+func checkArchiveEntryPath(root, rel, name string) error {
+	current := root
+	for _, part := range strings.Split(rel, "/") {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("file %s is written through link %s", name, filepath.ToSlash(strings.TrimPrefix(current, root+string(filepath.Separator))))
+		}
+	}
+	return nil
+}
+
+func extractArchiveFile(r io.Reader, target string, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// installFromArchive installs the package bundled in an archive written by "package export" without any network access.
+// The dependencies and binaries in the archive are used as they are, and updates skip the package.
+func installFromArchive(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager, archive string) ([]installResult, error) {
+	start := time.Now()
+	oldCmds := getCommands(c)
+	term := terminal.Get(c.Context)
+	logger := log.FromContext(c.Context)
+
+	tr, manifest, closer, err := openPackageArchive(archive)
+	if err != nil {
+		return nil, cli.Exit(color.RedString("Unable to read package archive: %s", err), 1)
+	}
+	defer func() {
+		if err := closer.Close(); err != nil {
+			logger.Debugf("Unable to close package archive: %s", err)
+		}
+	}()
+	res := installResult{Package: manifest.Name, Repository: manifest.Source, Status: installStatusFailed, Commands: []string{}}
+	fail := func(err error) ([]installResult, error) {
+		res.Error = err.Error()
+		return []installResult{res}, err
+	}
+	if manifest.Source != "" {
+		if err := requireVerified(c.Context, manifest.Source); err != nil {
+			return fail(err)
+		}
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return fail(err)
+	}
+	dir := filepath.Join(srcPath, manifest.Name)
+	if _, err := os.Stat(dir); err == nil {
+		errorMsg := fmt.Sprintf("Package directory already exists (%s)", dir)
+		logger.Error(errorMsg)
+		return fail(cli.Exit(color.RedString(errorMsg), 1))
+	}
+	if platform := binaryPlatform(withPlatform(command{})); manifest.Platform != platform {
+		term.Writeln(color.YellowString("Warning: package %s was exported on %s, its binaries and dependencies may not work on %s", manifest.Name, manifest.Platform, platform))
+	}
+
+	term.Spinner().Start("Installing package %s from %s...", manifest.Name, archive)
+	if err := extractPackageArchive(tr, manifest.Name, dir); err != nil {
+		term.Spinner().Fail()
+		return fail(cli.Exit(color.RedString("Unable to extract package archive: %s", err), 1))
+	}
+	subCmd, err := readPackage(dir)
+	if err != nil {
+		term.Spinner().Fail()
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warnf("Unable to remove package directory: %s", err)
+		}
+		return fail(cli.Exit(color.RedString("Unable to install selected package: %s", err), 1))
+	}
+	term.Spinner().OK()
+
+	seedPackageConfig(c.Context, subCmd)
+	saveInstallRecord(c.Context, dir, manifest.Commit)
+	recordPackageEvent(c.Context, packageEventInstall, dir)
+	if abs, err := filepath.Abs(archive); err == nil {
+		archive = abs
+	}
+	markArchivePackage(c.Context, dir, archive)
+	notifyPackageEvent(c.Context, packageEventInstall, dir, "", packageVersion(subCmd))
+
+	res.Status = installStatusInstalled
+	res.Version = packageVersion(subCmd)
+	res.Commit = manifest.Commit
+	res.Path = dir
+	for _, cmd := range subCmd.Commands {
+		res.Commands = append(res.Commands, cmd.Name)
+	}
+
+	registered := make(map[string]bool)
+	for _, cmd := range c.App.Commands {
+		registered[cmd.Name] = true
+	}
+	cmds, _ := packageCliCommands(registered, manifest.Name, subCmd, gitRepo, langManager)
+	c.App.Commands = append(c.App.Commands, cmds...)
+	sortCommands(c.App.Commands)
+
+	packageListDiff(c, oldCmds)
+	term.Writeln(fmt.Sprintf("Installation took %s", formatDuration(since(start))))
+	return []installResult{res}, nil
+}
+
+// markArchivePackage records the archive the package in given directory was installed from, so that updates skip it
+func markArchivePackage(ctx context.Context, dir, archive string) {
+	err := updatePackageStore(ctx, func(store *packageStore) error {
+		pkg, ok := store.Packages[filepath.Base(dir)]
+		if !ok {
+			return errPackageStoreUnchanged
+		}
+		pkg.Archive = archive
+		return nil
+	})
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to update package store: %s", err)
+	}
+}
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageExportAndInstall(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	dir := installLockablePackage(t, cliHome, "cli-dns", "https://github.com/akamai/cli-dns.git", lockedCommit)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-dns"), []byte("binary"), 0755))
+	archive := filepath.Join(cliHome, "cli-dns.tar.gz")
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", "Exporting package %s...", []interface{}{"cli-dns"}).Return().Once()
+	m.term.On("OK").Return().Once()
+	m.term.On("Printf", "Package %s exported to %s.\n", []interface{}{color.BlueString("cli-dns"), color.BlueString(archive)}).Return().Once()
+	m.term.On("Printf", "Install it without network access using \"%s\".\n", mock.Anything).Return().Once()
+	app, ctx := setupTestApp(&cli.Command{
+		Name:   "export",
+		Action: cmdPackageExport(m.langManager),
+		Flags:  []cli.Flag{&cli.StringFlag{Name: "out", Aliases: []string{"o"}}},
+	}, m)
+	require.NoError(t, app.RunContext(ctx, append(os.Args[0:1], "export", "-o", archive, "cli-dns")))
+	m.term.AssertExpectations(t)
+
+	_, manifest, closer, err := openPackageArchive(archive)
+	require.NoError(t, err)
+	require.NoError(t, closer.Close())
+	assert.Equal(t, "cli-dns", manifest.Name)
+	assert.Equal(t, "https://github.com/akamai/cli-dns.git", manifest.Source)
+	assert.Equal(t, lockedCommit, manifest.Commit)
+	assert.Equal(t, "1.0.0", manifest.PackageVersion)
+	assert.Equal(t, binaryPlatform(withPlatform(command{})), manifest.Platform)
+
+	// the package is installed again from the archive, without any git or package manager calls
+	require.NoError(t, os.RemoveAll(dir))
+	m = &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", "Installing package %s from %s...", []interface{}{"cli-dns", archive}).Return().Once()
+	m.term.On("OK").Return().Once()
+	m.term.On("Writeln", mock.Anything).Return(0, nil)
+	m.term.On("Printf", mock.Anything, mock.Anything).Return()
+	m.cfg.On("GetValue", "cli", verifiedOnlyKey).Return("", false).Once()
+	m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Maybe()
+	app, ctx = setupTestApp(&cli.Command{
+		Name:   "install",
+		Action: cmdInstall(m.gitRepo, m.langManager),
+		Flags:  []cli.Flag{&cli.StringFlag{Name: "file"}, &cli.StringFlag{Name: "from-lock"}, &cli.StringSliceFlag{Name: "mirror"}},
+	}, m)
+	require.NoError(t, app.RunContext(ctx, append(os.Args[0:1], "install", "--file", archive)))
+	m.cfg.AssertExpectations(t)
+	m.gitRepo.AssertExpectations(t)
+	m.langManager.AssertExpectations(t)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "bin", "akamai-dns"))
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data))
+	if origin, err := git.OriginURL(dir); assert.NoError(t, err) {
+		assert.Equal(t, "https://github.com/akamai/cli-dns.git", origin)
+	}
+	store, err := loadPackageStore(context.Background())
+	require.NoError(t, err)
+	pkg := store.Packages["cli-dns"]
+	assert.Equal(t, archive, pkg.Archive)
+	assert.Equal(t, lockedCommit, pkg.Commit)
+
+	// updates skip packages installed from an archive
+	term := &terminal.Mock{}
+	term.On("Spinner").Return(term)
+	term.On("Start", "Attempting to update \"%s\" command...", []interface{}{"dns"}).Return().Once()
+	term.On("WarnOK").Return().Once()
+	term.On("Writeln", mock.Anything).Return(0, nil).Once()
+	updateCtx := terminal.Context(context.Background(), term)
+	res, err := updatePackageExec(updateCtx, &git.Mock{}, &packages.Mock{}, log.FromContext(updateCtx), "dns", []string{filepath.Join(dir, "bin", "akamai-dns")}, "", false, false)
+	require.NoError(t, err)
+	term.AssertExpectations(t)
+	assert.Equal(t, updateStatusSkipped, res.Status)
+}
+
+func TestOpenPackageArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-archive")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	tests := map[string]struct {
+		manifest  interface{}
+		files     map[string]string
+		withError string
+	}{
+		"files outside of the package": {
+			manifest:  archiveManifest{Version: archiveManifestVersion, Name: "cli-dns"},
+			files:     map[string]string{"cli-dns/cli.json": "{}", "cli-dns/../cli-other/cli.json": "{}"},
+			withError: "file cli-dns/../cli-other/cli.json is outside of package cli-dns",
+		},
+		"invalid package name": {
+			manifest:  archiveManifest{Version: archiveManifestVersion, Name: "../cli-dns"},
+			withError: `invalid package name "../cli-dns"`,
+		},
+		"newer archive": {
+			manifest:  archiveManifest{Version: archiveManifestVersion + 1, Name: "cli-dns"},
+			withError: "unsupported package archive version 2, expected 1",
+		},
+		"no manifest": {
+			files:     map[string]string{"cli-dns/cli.json": "{}"},
+			withError: "not a package archive: akamai-package.json is missing",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive := filepath.Join(dir, "archive.tar.gz")
+			f, err := os.Create(archive)
+			require.NoError(t, err)
+			gz := gzip.NewWriter(f)
+			tw := tar.NewWriter(gz)
+			entries := make([][2]string, 0)
+			if test.manifest != nil {
+				data, err := json.Marshal(test.manifest)
+				require.NoError(t, err)
+				entries = append(entries, [2]string{archiveManifestFile, string(data)})
+			}
+			for name, content := range test.files {
+				entries = append(entries, [2]string{name, content})
+			}
+			for _, entry := range entries {
+				require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry[0], Mode: 0644, Size: int64(len(entry[1])), Typeflag: tar.TypeReg}))
+				_, err := tw.Write([]byte(entry[1]))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, gz.Close())
+			require.NoError(t, f.Close())
+
+			tr, manifest, closer, err := openPackageArchive(archive)
+			if err == nil {
+				defer func() {
+					require.NoError(t, closer.Close())
+				}()
+				err = extractPackageArchive(tr, manifest.Name, filepath.Join(dir, manifest.Name))
+				assert.False(t, fileExists(filepath.Join(dir, manifest.Name)))
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.withError, err.Error())
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, files, 1, "only the archive is left")
+		})
+	}
+}
+
+func TestExtractPackageArchiveChainedLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-archive")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	srcDir := filepath.Join(dir, "home", "src")
+	require.NoError(t, os.MkdirAll(srcDir, 0755))
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b/", Mode: 0755, Typeflag: tar.TypeDir}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b/x", Linkname: "../../a", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b/x/y", Linkname: "../../../pwned", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b/x/y", Mode: 0644, Size: 6, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("pwned\n"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	err = extractPackageArchive(tar.NewReader(buf), "", filepath.Join(srcDir, "cli-dns"))
+	require.Error(t, err)
+	assert.Equal(t, "file a/b/x/y is written through link a/b/x", err.Error())
+	assert.False(t, fileExists(filepath.Join(srcDir, "cli-dns")))
+	for _, path := range []string{filepath.Join(dir, "pwned"), filepath.Join(dir, "home", "pwned"), filepath.Join(srcDir, "pwned")} {
+		assert.False(t, fileExists(path), "nothing is written to %s", path)
+	}
+	files, err := ioutil.ReadDir(srcDir)
+	require.NoError(t, err)
+	assert.Empty(t, files, "the temporary directory is removed")
+}
+
+func TestExtractPackageArchiveHardlinks(t *testing.T) {
+	tests := map[string]struct {
+		linkname  string
+		withError string
+	}{
+		"link to a file of the package": {
+			linkname: "cli-dns/bin/akamai-dns",
+		},
+		"link outside of the package": {
+			linkname:  "other/bin/akamai-dns",
+			withError: "link cli-dns/bin/akamai-dns-legacy points outside of package cli-dns",
+		},
+		"link escaping the package": {
+			linkname:  "cli-dns/../../pwned",
+			withError: "link cli-dns/bin/akamai-dns-legacy points outside of package cli-dns",
+		},
+		"link to a file not extracted yet": {
+			linkname:  "cli-dns/bin/akamai-dns-next",
+			withError: "link cli-dns/bin/akamai-dns-legacy points to cli-dns/bin/akamai-dns-next, which is not a file extracted before",
+		},
+		"link to a directory": {
+			linkname:  "cli-dns/bin",
+			withError: "link cli-dns/bin/akamai-dns-legacy points to cli-dns/bin, which is not a file extracted before",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-archive")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+
+			buf := &bytes.Buffer{}
+			tw := tar.NewWriter(buf)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "cli-dns/bin/", Mode: 0755, Typeflag: tar.TypeDir}))
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "cli-dns/bin/akamai-dns", Mode: 0755, Size: 4, Typeflag: tar.TypeReg}))
+			_, err = tw.Write([]byte("dns\n"))
+			require.NoError(t, err)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "cli-dns/bin/akamai-dns-legacy", Linkname: test.linkname, Typeflag: tar.TypeLink}))
+			require.NoError(t, tw.Close())
+
+			pkgDir := filepath.Join(dir, "cli-dns")
+			err = extractPackageArchive(tar.NewReader(buf), "cli-dns", pkgDir)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				assert.False(t, fileExists(pkgDir))
+				return
+			}
+			require.NoError(t, err)
+			data, err := ioutil.ReadFile(filepath.Join(pkgDir, "bin", "akamai-dns-legacy"))
+			require.NoError(t, err)
+			assert.Equal(t, "dns\n", string(data))
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// update channels of a package, chosen with "package channel"
+const (
+	// channelBranch follows the tip of the checked out branch, which is the default
+	channelBranch = "branch"
+	// channelStable follows the latest tag which is a semantic version without pre-release suffix
+	channelStable = "stable"
+	// channelPrerelease follows the latest tag which is a semantic version, including pre-releases such as 1.2.0-beta.1
+	channelPrerelease = "prerelease"
+)
+
+var updateChannels = []string{channelBranch, channelStable, channelPrerelease}
+
+// channelKey is the setting of a package choosing its update channel or the branch it follows, e.g. "config set cli-dns.channel beta"
+const channelKey = "channel"
+
+func cmdPackageChannel(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("PACKAGE CHANNEL START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("PACKAGE CHANNEL FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("PACKAGE CHANNEL ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+
+		store, err := loadPackageStore(c.Context)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+		}
+		store.migrateInstalled(c.Context, "")
+		name := c.Args().First()
+		pkgName, ok := installedPackageName(c, langManager, store, name)
+		if !ok {
+			return packageNotFound(name)
+		}
+		pkg := store.Packages[pkgName]
+		if c.NArg() < 2 {
+			term.Printf("Package %s follows the %s channel.\n", color.BlueString(pkgName), color.CyanString(pkg.channel()))
+			return nil
+		}
+
+		channel := strings.ToLower(c.Args().Get(1))
+		if !isUpdateChannel(channel) {
+			return cli.Exit(color.RedString("Unknown channel \"%s\", use one of: %s", channel, strings.Join(updateChannels, ", ")), 1)
+		}
+		if channel == pkg.channel() {
+			term.Printf("Package %s already follows the %s channel.\n", color.BlueString(pkgName), color.CyanString(channel))
+			return nil
+		}
+
+		if err := setPackageChannel(c.Context, gitRepo, pkgName, channel, ""); err != nil {
+			return cli.Exit(color.RedString("Unable to update package store: %s", err), 1)
+		}
+		term.Printf("Package %s now follows the %s channel.\n", color.BlueString(pkgName), color.CyanString(channel))
+		term.Printf("Run \"%s\" to apply it.\n", color.BlueString("%s update %s", tools.Self(), pkgName))
+		return nil
+	}
+}
+
+// setPackageChannel makes the package follow given update channel and, if branch is not empty, the tip of that branch.
+// The branch checked out is remembered when switching to tags, as the HEAD is detached once a tag is checked out.
+func setPackageChannel(ctx context.Context, gitRepo git.Repository, pkgName, channel, branch string) error {
+	return updatePackageStore(ctx, func(store *packageStore) error {
+		store.migrateInstalled(ctx, "")
+		pkg, ok := store.Packages[pkgName]
+		if !ok {
+			return fmt.Errorf("package %s not found", pkgName)
+		}
+		if pkg.channel() == channelBranch && branch == "" {
+			srcPath, err := tools.GetAkamaiCliSrcPath()
+			if err != nil {
+				return err
+			}
+			if err := gitRepo.Open(filepath.Join(srcPath, pkgName)); err == nil {
+				if head, err := gitRepo.Head(); err == nil && head.Name().IsBranch() {
+					pkg.Branch = head.Name().Short()
+				}
+			}
+		}
+		pkg.Channel = channel
+		if channel == channelBranch {
+			pkg.Channel = ""
+		}
+		if branch != "" {
+			pkg.Branch = branch
+		}
+		return nil
+	})
+}
+
+// setChannelSetting applies "config set <package>.channel <value>" to the package metadata, value being an update channel or a branch.
+// It returns false if section is not the name of an installed package or of one of its commands.
+func setChannelSetting(c *cli.Context, gitRepo git.Repository, section, value string) (bool, error) {
+	store, err := loadPackageStore(c.Context)
+	if err != nil {
+		return false, nil
+	}
+	store.migrateInstalled(c.Context, "")
+	pkgName, ok := store.packageByName(section)
+	if !ok || !store.Packages[pkgName].Installed {
+		return false, nil
+	}
+	term := terminal.Get(c.Context)
+	value = strings.TrimSpace(value)
+	channel, branch := strings.ToLower(value), ""
+	if !isUpdateChannel(channel) {
+		if err := validateBranchName(value); err != nil {
+			return true, cli.Exit(color.RedString("Unable to set config value: %s", err), 1)
+		}
+		channel, branch = channelBranch, value
+	}
+	if err := setPackageChannel(c.Context, gitRepo, pkgName, channel, branch); err != nil {
+		return true, cli.Exit(color.RedString("Unable to update package store: %s", err), 1)
+	}
+	if branch != "" {
+		term.Printf("Package %s now follows branch %s.\n", color.BlueString(pkgName), color.CyanString(branch))
+	} else {
+		term.Printf("Package %s now follows the %s channel.\n", color.BlueString(pkgName), color.CyanString(channel))
+	}
+	term.Printf("Run \"%s\" to apply it.\n", color.BlueString("%s update %s", tools.Self(), pkgName))
+	return true, nil
+}
+
+// validateBranchName rejects names which git does not accept as branch names
+func validateBranchName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") ||
+		strings.HasSuffix(name, ".lock") || strings.Contains(name, "..") || strings.Contains(name, "@{") ||
+		strings.ContainsAny(name, " \t~^:?*[\\") {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+func isUpdateChannel(channel string) bool {
+	for _, ch := range updateChannels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// channel returns the update channel of the package, channelBranch if none was chosen
+func (p *packageMetadata) channel() string {
+	if p.Channel == "" {
+		return channelBranch
+	}
+	return p.Channel
+}
+
+// packageUpdateChannels returns update channels, pinned versions, locks, archives and remembered branches of packages, keyed by package name
+// Like disabledPackages, the store is only read, and packages following branch tips are left out.
+func packageUpdateChannels() map[string]*packageMetadata {
+	channels := make(map[string]*packageMetadata)
+	path, err := packageStorePath()
+	if err != nil {
+		return channels
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return channels
+	}
+	var store packageStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return channels
+	}
+	for name, pkg := range store.Packages {
+		if pkg != nil && pkg.Installed && (pkg.Channel != "" || pkg.Branch != "" || pkg.Pinned != "" || pkg.Locked != "" || pkg.Archive != "") {
+			channels[name] = pkg
+		}
+	}
+	return channels
+}
+
+// commandChannels returns the update channel of commands whose package follows tags instead of its branch
+func commandChannels() map[string]string {
+	channels := make(map[string]string)
+	for _, pkg := range packageUpdateChannels() {
+		if pkg.channel() == channelBranch {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			channels[cmd] = pkg.channel()
+		}
+	}
+	return channels
+}
+
+// latestChannelTag returns the highest semantic version among tags, skipping pre-releases unless channel is channelPrerelease
+func latestChannelTag(tags []string, channel string) (string, bool) {
+	var latest string
+	var latestVersion *semver.Version
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil || (channel != channelPrerelease && v.Prerelease() != "") {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = tag, v
+		}
+	}
+	return latest, latestVersion != nil
+}
+
+// checkoutChannelTag fetches tags of the package repository and checks out the latest one of given channel
+func checkoutChannelTag(ctx context.Context, gitRepo git.Repository, channel string) error {
+	logger := log.FromContext(ctx)
+	tags, err := gitRepo.FetchTags(ctx, git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	tag, ok := latestChannelTag(tags, channel)
+	if !ok {
+		return fmt.Errorf("no %s release tags found", channel)
+	}
+	logger.Debugf("Checking out tag %s of the %s channel", tag, channel)
+	return gitRepo.CheckoutTag(tag)
+}
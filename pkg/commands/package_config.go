@@ -0,0 +1,87 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// seedPackageConfig adds the default settings declared in the "config" of cli.json to the config, keeping the values
+// which are set already. Only settings in sections named after the commands of the package are added, so that
+// a package cannot change the settings of Akamai CLI or of other packages. Sections owned by Akamai CLI are never
+// seeded, even if the package declares a command of the same name.
+func seedPackageConfig(ctx context.Context, pkg subcommands) {
+	if len(pkg.Config) == 0 {
+		return
+	}
+	logger := log.FromContext(ctx)
+	cfg := config.Get(ctx)
+	reserved := reservedConfigSections()
+	sections := make(map[string]bool)
+	for _, cmd := range pkg.Commands {
+		if name := strings.ToLower(cmd.Name); !reserved[name] {
+			sections[name] = true
+		}
+	}
+
+	seeded := make([]string, 0)
+	for name, value := range pkg.Config {
+		path := strings.Split(name, ".")
+		section, key := strings.ToLower(path[0]), strings.Join(path[1:], "-")
+		if len(path) < 2 || key == "" || !sections[section] {
+			logger.Warnf("Default setting %s is ignored, packages can only set <command>.<key> settings of their own commands", name)
+			continue
+		}
+		if _, ok := cfg.GetValue(section, key); ok {
+			logger.Debugf("Default setting %s.%s is already set, keeping its value", section, key)
+			continue
+		}
+		cfg.SetValue(section, key, value)
+		seeded = append(seeded, section+"."+key)
+	}
+	if len(seeded) == 0 {
+		return
+	}
+	if err := cfg.Save(ctx); err != nil {
+		logger.Warnf("Unable to save default settings: %s", err)
+		return
+	}
+	sort.Strings(seeded)
+	terminal.Get(ctx).Writeln(color.CyanString("Default settings added to the config: %s", strings.Join(seeded, ", ")))
+}
+
+// reservedConfigSections returns the config sections owned by Akamai CLI and its built-in commands
+func reservedConfigSections() map[string]bool {
+	reserved := map[string]bool{
+		"cli":                 true,
+		commandOwnerSection:   true,
+		registriesSection:     true,
+		resourceLimitsSection: true,
+	}
+	for _, cmd := range createBuiltinCommands() {
+		for _, name := range cmd.Names() {
+			reserved[strings.ToLower(name)] = true
+		}
+	}
+	return reserved
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"testing"
+)
+
+func TestSeedPackageConfig(t *testing.T) {
+	tests := map[string]struct {
+		commands []string
+		config   map[string]string
+		init     func(*mocked)
+	}{
+		"add default settings": {
+			config: map[string]string{"dns.default-zone": "example.com", "dns.retry.count": "3"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "dns", "default-zone").Return("", false).Once()
+				m.cfg.On("GetValue", "dns", "retry-count").Return("", false).Once()
+				m.cfg.On("SetValue", "dns", "default-zone", "example.com").Return().Once()
+				m.cfg.On("SetValue", "dns", "retry-count", "3").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Writeln", []interface{}{color.CyanString("Default settings added to the config: %s", "dns.default-zone, dns.retry-count")}).Return(0, nil).Once()
+			},
+		},
+		"keep values set by the user": {
+			config: map[string]string{"dns.default-zone": "example.com"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "dns", "default-zone").Return("", true).Once()
+			},
+		},
+		"settings of other sections are ignored": {
+			config: map[string]string{"cli.verified-only": "false", "property.section": "papi", "dns": "x"},
+			init:   func(m *mocked) {},
+		},
+		"sections of Akamai CLI are ignored": {
+			commands: []string{"cli", "registries", "command-owner", "limits", "config", "dns"},
+			config: map[string]string{
+				"cli.verified-only":  "false",
+				"registries.default": "https://example.com/registry.json",
+				"command-owner.dns":  "cli-other",
+				"limits.memory":      "0",
+				"config.path":        "/tmp",
+				"dns.default-zone":   "example.com",
+			},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "dns", "default-zone").Return("", true).Once()
+			},
+		},
+		"save fails": {
+			config: map[string]string{"DNS.zone": "example.com"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "dns", "zone").Return("", false).Once()
+				m.cfg.On("SetValue", "dns", "zone", "example.com").Return().Once()
+				m.cfg.On("Save").Return(errors.New("read-only file system")).Once()
+			},
+		},
+		"no default settings": {
+			init: func(m *mocked) {},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			test.init(m)
+			ctx := terminal.Context(config.Context(context.Background(), m.cfg), m.term)
+
+			commands := []command{{Name: "dns"}}
+			if test.commands != nil {
+				commands = commands[:0]
+				for _, name := range test.commands {
+					commands = append(commands, command{Name: name})
+				}
+			}
+			seedPackageConfig(ctx, subcommands{Commands: commands, Config: test.config})
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+
+	"github.com/akamai/cli/pkg/git"
+)
+
+// checkPackagesHealth inspects installed packages and returns problems found in each of them, keyed by command name,
+// so that broken installs can be reported before they fail at runtime
+func checkPackagesHealth(gitRepo git.Repository) map[string][]string {
+	health := make(map[string][]string)
+	for _, dir := range getPackagePaths() {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		issues := packageHealth(gitRepo, dir, pkg)
+		if len(issues) == 0 {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			health[cmd.Name] = issues
+		}
+	}
+	return health
+}
+
+// packageHealth returns problems found in the package installed in given directory
+// Dependencies are checked as by doctor, including the site-packages of Python packages, but without running
+// the runtime, so Python dependencies installed for another minor version are only reported by doctor.
+func packageHealth(gitRepo git.Repository, dir string, pkg subcommands) []string {
+	issues := make([]string, 0)
+	if isWorktreeDirty(gitRepo, dir) {
+		issues = append(issues, "package files were modified locally")
+	}
+	for _, d := range append(diagnoseDependencies(dir, pkg, ""), diagnoseExecutables(dir, pkg)...) {
+		if d.Status != diagnosticOK {
+			issues = append(issues, d.Message)
+		}
+	}
+	return issues
+}
+
+// isWorktreeDirty returns true if tracked files in the package repository were changed; untracked files,
+// such as installed dependencies or built binaries, are not taken into account
+func isWorktreeDirty(gitRepo git.Repository, dir string) bool {
+	if err := gitRepo.Open(dir); err != nil {
+		return false
+	}
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		return false
+	}
+	dirty, _ := worktreeDirty(worktree)
+	return dirty
+}
+
+// worktreeDirty returns true if tracked files in worktree were changed, an error if their status cannot be read
+func worktreeDirty(worktree *gogit.Worktree) (bool, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, fileStatus := range status {
+		if fileStatus.Worktree != gogit.Untracked && fileStatus.Worktree != gogit.Unmodified ||
+			fileStatus.Staging != gogit.Untracked && fileStatus.Staging != gogit.Unmodified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// commandExecutables returns paths of executables of given command found in package directory or its bin subdirectory
+func commandExecutables(dir, cmd string) []string {
+	cmdName, cmdNameTitle := executableNames(cmd)
+	executables := make([]string, 0)
+	for _, path := range []string{dir, filepath.Join(dir, "bin")} {
+		for _, name := range []string{cmdName, cmdNameTitle, cmdName + ".*", cmdNameTitle + ".*"} {
+			files, _ := filepath.Glob(filepath.Join(path, name))
+			executables = append(executables, files...)
+		}
+	}
+	return executables
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// executableNames returns possible executable names for given command:
+// "command-name" becomes "akamai-command-name" and "akamaiCommandName"
+func executableNames(cmd string) (string, string) {
+	cmdName := "akamai"
+	cmdNameTitle := "akamai"
+	for _, cmdPart := range strings.Split(cmd, "-") {
+		cmdName += "-" + strings.ToLower(cmdPart)
+		cmdNameTitle += strings.Title(strings.ToLower(cmdPart))
+	}
+	return cmdName, cmdNameTitle
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestPackageHealth(t *testing.T) {
+	tests := map[string]struct {
+		files    []string
+		pkg      subcommands
+		gitRepo  bool
+		modify   bool
+		expected []string
+	}{
+		"healthy package": {
+			files:    []string{"cli.json", "bin/akamai-echo"},
+			pkg:      subcommands{Commands: []command{{Name: "echo"}}},
+			expected: []string{},
+		},
+		"missing executable": {
+			files:    []string{"cli.json", "bin/akamai-other"},
+			pkg:      subcommands{Commands: []command{{Name: "echo"}}},
+			expected: []string{`executable for "echo" is missing`},
+		},
+		"executable with extension in package root": {
+			files:    []string{"cli.json", "akamaiEcho.exe"},
+			pkg:      subcommands{Commands: []command{{Name: "echo"}}},
+			expected: []string{},
+		},
+		"node dependencies not installed": {
+			files: []string{"cli.json", "package.json", "bin/akamai-echo"},
+			pkg: subcommands{
+				Commands:     []command{{Name: "echo"}},
+				Requirements: packages.LanguageRequirements{Node: "7.0.0"},
+			},
+			expected: []string{"dependencies are not installed"},
+		},
+		"python dependencies not installed": {
+			files: []string{"cli.json", "requirements.txt", "bin/akamai-echo"},
+			pkg: subcommands{
+				Commands:     []command{{Name: "echo"}},
+				Requirements: packages.LanguageRequirements{Python: "3.0.0"},
+			},
+			expected: []string{"dependencies are not installed"},
+		},
+		"python dependencies installed": {
+			files: []string{"cli.json", "requirements.txt", "bin/akamai-echo", "lib/python3.8/site-packages/edgegrid.py"},
+			pkg: subcommands{
+				Commands:     []command{{Name: "echo"}},
+				Requirements: packages.LanguageRequirements{Python: "3.0.0"},
+			},
+			expected: []string{},
+		},
+		"clean repository with untracked files": {
+			files:    []string{"cli.json", "bin/akamai-echo"},
+			pkg:      subcommands{Commands: []command{{Name: "echo"}}},
+			gitRepo:  true,
+			expected: []string{},
+		},
+		"modified repository": {
+			files:    []string{"cli.json", "bin/akamai-echo"},
+			pkg:      subcommands{Commands: []command{{Name: "echo"}}},
+			gitRepo:  true,
+			modify:   true,
+			expected: []string{"package files were modified locally"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-health")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			for _, file := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("test"), 0755))
+			}
+			if test.gitRepo {
+				repo, err := gogit.PlainInit(dir, false)
+				require.NoError(t, err)
+				worktree, err := repo.Worktree()
+				require.NoError(t, err)
+				_, err = worktree.Add("cli.json")
+				require.NoError(t, err)
+				_, err = worktree.Commit("init", &gogit.CommitOptions{
+					Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+				})
+				require.NoError(t, err)
+			}
+			if test.modify {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte("modified"), 0755))
+			}
+
+			issues := packageHealth(git.NewRepository(), dir, test.pkg)
+			assert.Equal(t, test.expected, issues)
+		})
+	}
+}
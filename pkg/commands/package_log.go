@@ -0,0 +1,134 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// packageLogsKey is the config key which, when set to "true", writes CLI-side logs of package commands
+	// to a separate file for each package
+	packageLogsKey = "package-logs"
+
+	packageLogFile = "cli.log"
+
+	// packageLogMaxSize is the size in bytes after which a package log file is rotated
+	packageLogMaxSize = 1 << 20
+
+	// packageLogBackups is the number of rotated package log files kept next to the current one
+	packageLogBackups = 3
+)
+
+// packageLogEnv lists the environment variables recorded when a package command is dispatched
+var packageLogEnv = []string{
+	"AKAMAI_CLI_COMMAND",
+	"AKAMAI_CLI_COMMAND_VERSION",
+	"AKAMAI_CLI_HOME",
+	"AKAMAI_EDGERC",
+	"AKAMAI_EDGERC_SECTION",
+	"AKAMAI_OUTPUT_FORMAT",
+	"PYTHONUSERBASE",
+}
+
+// openPackageLog returns a logger writing to the log file of given package, rotating the file if it grew too big
+// If package logs are disabled or the file cannot be opened, the returned logger discards all entries
+func openPackageLog(ctx context.Context, packageName string) (log.Logger, func()) {
+	discard := log.New(ioutil.Discard)
+	val, ok := config.Get(ctx).GetValue("cli", packageLogsKey)
+	if !ok || strings.TrimSpace(val) != "true" || packageName == "" || packageName == "." {
+		return discard, func() {}
+	}
+
+	logger := log.FromContext(ctx)
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		logger.Debugf("Unable to open package log: %s", err)
+		return discard, func() {}
+	}
+	dir := filepath.Join(cliPath, "logs", packageName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.Debugf("Unable to open package log: %s", err)
+		return discard, func() {}
+	}
+	path := filepath.Join(dir, packageLogFile)
+	if err := rotateLog(path, packageLogMaxSize, packageLogBackups); err != nil {
+		logger.Debugf("Unable to rotate package log: %s", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		logger.Debugf("Unable to open package log: %s", err)
+		return discard, func() {}
+	}
+	return log.New(f), func() {
+		if err := f.Close(); err != nil {
+			logger.Debugf("Unable to close package log: %s", err)
+		}
+	}
+}
+
+// rotateLog renames the file at given path to path.1, path.1 to path.2 and so on once it reaches maxSize bytes,
+// removing the oldest file beyond given number of backups
+func rotateLog(path string, maxSize int64, backups int) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < maxSize {
+		return nil
+	}
+	if err := os.Remove(fmt.Sprintf("%s.%d", path, backups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := backups - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(path, path+".1")
+}
+
+// logPackageDispatch records the executed command line together with its working directory and environment
+func logPackageDispatch(logger log.Logger, executable []string, dir string) {
+	entry := logger.WithField("args", strings.Join(executable, " "))
+	if dir != "" {
+		entry = entry.WithField("dir", dir)
+	}
+	for _, name := range packageLogEnv {
+		if val, ok := os.LookupEnv(name); ok {
+			entry = entry.WithField(name, val)
+		}
+	}
+	entry.Info("Dispatching command")
+}
+
+// logPackageExit records the duration and exit code of a finished command
+func logPackageExit(logger log.Logger, start time.Time, err error) {
+	exitCode := 0
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		exitCode = 1
+	}
+	logger.WithField("duration", time.Since(start).Round(time.Millisecond)).WithField("exit-code", exitCode).Info("Command finished")
+}
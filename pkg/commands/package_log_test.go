@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateLog(t *testing.T) {
+	tests := map[string]struct {
+		size     int
+		backups  []string
+		expected []string
+	}{
+		"small file is not rotated": {
+			size:     5,
+			expected: []string{"cli.log"},
+		},
+		"big file is rotated": {
+			size:     10,
+			expected: []string{"cli.log.1"},
+		},
+		"oldest backup is removed": {
+			size:     10,
+			backups:  []string{"cli.log.1", "cli.log.2"},
+			expected: []string{"cli.log.1", "cli.log.2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "logs")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			path := filepath.Join(dir, "cli.log")
+			require.NoError(t, ioutil.WriteFile(path, make([]byte, test.size), 0600))
+			for i, backup := range test.backups {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, backup), []byte(fmt.Sprint(i)), 0600))
+			}
+
+			require.NoError(t, rotateLog(path, 10, 2))
+
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			names := make([]string, 0, len(files))
+			for _, f := range files {
+				names = append(names, f.Name())
+			}
+			assert.Equal(t, test.expected, names)
+			if len(test.backups) > 0 {
+				data, err := ioutil.ReadFile(filepath.Join(dir, "cli.log.2"))
+				require.NoError(t, err)
+				assert.Equal(t, "0", string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+// installRecordFile is the file in package directory holding the state of the package recorded at install
+const installRecordFile = ".akamai-cli-install.json"
+
+// installRecord describes the state of a package right after it was installed or updated
+type installRecord struct {
+	Commit      string            `json:"commit,omitempty"`
+	InstalledAt time.Time         `json:"installedAt"`
+	Binaries    map[string]string `json:"binaries"`
+	Lockfiles   map[string]string `json:"lockfiles"`
+}
+
+// packageDrift describes a difference between the recorded and the current state of a package
+type packageDrift struct {
+	Path     string `json:"path,omitempty"`
+	Change   string `json:"change"`
+	Recorded string `json:"recorded,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// drift changes
+const (
+	driftModified = "modified"
+	driftMissing  = "missing"
+	driftAdded    = "added"
+	driftCommit   = "commit changed"
+)
+
+// newInstallRecord computes hashes of command executables and dependency files of the package in given directory
+func newInstallRecord(dir, commit string) (*installRecord, error) {
+	record := &installRecord{
+		Commit:      commit,
+		InstalledAt: time.Now().UTC().Truncate(time.Second),
+		Binaries:    make(map[string]string),
+		Lockfiles:   make(map[string]string),
+	}
+	pkg, err := readPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, cmd := range pkg.Commands {
+		for _, path := range commandExecutables(dir, cmd.Name) {
+			if err := addFileHash(record.Binaries, dir, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for name := range dependencyFiles {
+		path := filepath.Join(dir, name)
+		if !fileExists(path) {
+			continue
+		}
+		if err := addFileHash(record.Lockfiles, dir, path); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+func addFileHash(hashes map[string]string, dir, path string) error {
+	hash, err := fileHash(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+	hashes[filepath.ToSlash(rel)] = hash
+	return nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// saveInstallRecord stores the state of the package in given directory, failures are only logged
+// as they do not affect the package itself
+func saveInstallRecord(ctx context.Context, dir, commit string) {
+	logger := log.FromContext(ctx)
+	record, err := newInstallRecord(dir, commit)
+	if err != nil {
+		logger.Warnf("Unable to record package state: %s", err)
+		return
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		logger.Warnf("Unable to record package state: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, installRecordFile), data, 0644); err != nil {
+		logger.Warnf("Unable to record package state: %s", err)
+	}
+}
+
+// readInstallRecord returns the state of the package recorded at install or last update
+func readInstallRecord(dir string) (*installRecord, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, installRecordFile))
+	if err != nil {
+		return nil, err
+	}
+	record := &installRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// compareInstallRecord returns differences between recorded and current state of a package, sorted by path
+func compareInstallRecord(recorded, current *installRecord) []packageDrift {
+	drift := make([]packageDrift, 0)
+	if recorded.Commit != "" && current.Commit != recorded.Commit {
+		drift = append(drift, packageDrift{Change: driftCommit, Recorded: recorded.Commit, Current: current.Commit})
+	}
+	files := make([]packageDrift, 0)
+	for _, hashes := range []struct{ recorded, current map[string]string }{
+		{recorded.Binaries, current.Binaries},
+		{recorded.Lockfiles, current.Lockfiles},
+	} {
+		for path, hash := range hashes.recorded {
+			currentHash, ok := hashes.current[path]
+			if !ok {
+				files = append(files, packageDrift{Path: path, Change: driftMissing, Recorded: hash})
+			} else if currentHash != hash {
+				files = append(files, packageDrift{Path: path, Change: driftModified, Recorded: hash, Current: currentHash})
+			}
+		}
+		for path, hash := range hashes.current {
+			if _, ok := hashes.recorded[path]; !ok {
+				files = append(files, packageDrift{Path: path, Change: driftAdded, Current: hash})
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return append(drift, files...)
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewInstallRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-package")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(`{"commands": [{"name": "test-cmd"}]}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-test-cmd"), []byte("binary"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), []byte("sum"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0644))
+
+	record, err := newInstallRecord(dir, "abc")
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc", record.Commit)
+	assert.Equal(t, map[string]string{
+		"bin/akamai-test-cmd": "9a3a45d01531a20e89ac6ae10b0b0beb0492acd7216a368aa062d1a5fecaf9cd",
+	}, record.Binaries)
+	assert.Equal(t, map[string]string{
+		"cli.json": mustFileHash(t, filepath.Join(dir, "cli.json")),
+		"go.sum":   "09f5ffef28309853265c4a98d0e56e1be522b6b402d8193594fd05103064fc6a",
+	}, record.Lockfiles)
+}
+
+func TestCompareInstallRecord(t *testing.T) {
+	recorded := &installRecord{
+		Commit:    "abc",
+		Binaries:  map[string]string{"bin/akamai-a": "1", "bin/akamai-b": "2"},
+		Lockfiles: map[string]string{"go.sum": "3"},
+	}
+	tests := map[string]struct {
+		current  *installRecord
+		expected []packageDrift
+	}{
+		"no changes": {
+			current: &installRecord{
+				Commit:    "abc",
+				Binaries:  map[string]string{"bin/akamai-a": "1", "bin/akamai-b": "2"},
+				Lockfiles: map[string]string{"go.sum": "3"},
+			},
+			expected: []packageDrift{},
+		},
+		"commit and files changed": {
+			current: &installRecord{
+				Commit:    "def",
+				Binaries:  map[string]string{"bin/akamai-a": "10", "bin/akamai-c": "4"},
+				Lockfiles: map[string]string{"go.sum": "3"},
+			},
+			expected: []packageDrift{
+				{Change: driftCommit, Recorded: "abc", Current: "def"},
+				{Path: "bin/akamai-a", Change: driftModified, Recorded: "1", Current: "10"},
+				{Path: "bin/akamai-b", Change: driftMissing, Recorded: "2"},
+				{Path: "bin/akamai-c", Change: driftAdded, Current: "4"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, compareInstallRecord(recorded, test.current))
+		})
+	}
+}
+
+func mustFileHash(t *testing.T, path string) string {
+	hash, err := fileHash(path)
+	require.NoError(t, err)
+	return hash
+}
@@ -0,0 +1,128 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+)
+
+// registriesSection is the config section mapping names of private package registries to the URLs of their package index,
+// e.g. "registries.internal = https://cli.example.com/package-list.json"
+const registriesSection = "registries"
+
+type packageRegistry struct {
+	Name string
+	URL  string
+}
+
+// packageRegistries returns package registries configured in the registries section, sorted by name
+func packageRegistries(ctx context.Context) []packageRegistry {
+	registries := make([]packageRegistry, 0)
+	for name, url := range config.Get(ctx).Values()[registriesSection] {
+		if url = strings.TrimSpace(url); url != "" {
+			registries = append(registries, packageRegistry{Name: name, URL: url})
+		}
+	}
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Name < registries[j].Name
+	})
+	return registries
+}
+
+// fetchRegistryIndex fetches the package index of a private registry
+func fetchRegistryIndex(ctx context.Context, registry packageRegistry) (*packageList, error) {
+	index, err := fetchPackageIndex(ctx, registry.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch package index of registry %s (%s)", registry.Name, err.Error())
+	}
+	return index, nil
+}
+
+// fetchSearchIndex returns the package list along with packages of all configured registries.
+// Names of registry packages are prefixed with the registry name, so that they can be installed by that name.
+// Registries which cannot be reached are skipped with a warning.
+func fetchSearchIndex(ctx context.Context) (*packageList, error) {
+	result, err := fetchPackageList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, registry := range packageRegistries(ctx) {
+		index, err := fetchRegistryIndex(ctx, registry)
+		if err != nil {
+			log.FromContext(ctx).Warn(err.Error())
+			terminal.Get(ctx).Writeln(color.YellowString("Warning: %s", err.Error()))
+			continue
+		}
+		for _, pkg := range index.Packages {
+			pkg.Name = registry.Name + "/" + pkg.Name
+			result.Packages = append(result.Packages, pkg)
+		}
+	}
+	return result, nil
+}
+
+// resolveRegistryRepo returns the repository of a package given as "<registry>/<package>", where registry is configured
+// in the registries section. The package is looked up by its name, with or without the "cli-" prefix, or by the name
+// of one of its commands. Any other repository is returned unchanged.
+func resolveRegistryRepo(ctx context.Context, repo string) (string, error) {
+	i := strings.Index(repo, "/")
+	if i <= 0 || strings.ContainsAny(repo, ":@") || strings.Contains(repo[i+1:], "/") {
+		return repo, nil
+	}
+	registryName, name := repo[:i], repo[i+1:]
+	url, ok := config.Get(ctx).GetValue(registriesSection, registryName)
+	if !ok || strings.TrimSpace(url) == "" {
+		return repo, nil
+	}
+	registry := packageRegistry{Name: registryName, URL: strings.TrimSpace(url)}
+	index, err := fetchRegistryIndex(ctx, registry)
+	if err != nil {
+		return "", err
+	}
+	pkg := findIndexPackage(index, name)
+	if pkg == nil {
+		return "", fmt.Errorf("package %s not found in registry %s", name, registry.Name)
+	}
+	if pkg.URL == "" {
+		return "", fmt.Errorf("package %s of registry %s has no repository URL", pkg.Name, registry.Name)
+	}
+	log.FromContext(ctx).Debugf("Package %s resolved to %s", repo, pkg.URL)
+	return pkg.URL, nil
+}
+
+func findIndexPackage(index *packageList, name string) *packageListPackage {
+	for i, pkg := range index.Packages {
+		if pkg.Name == name || pkg.Name == "cli-"+name {
+			return &index.Packages[i]
+		}
+	}
+	for i, pkg := range index.Packages {
+		for _, cmd := range pkg.Commands {
+			if cmd.Name == name {
+				return &index.Packages[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func registryServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var index packageList
+		switch r.URL.Path {
+		case "/cli/package-list.json":
+			index.Packages = []packageListPackage{{Name: "cli-dns", Commands: []command{{Name: "dns"}}}}
+		case "/internal/index.json":
+			index.Packages = []packageListPackage{
+				{Name: "cli-deploy", URL: "https://git.example.com/tools/cli-deploy.git", Commands: []command{{Name: "deploy"}, {Name: "rollout"}}},
+				{Name: "cli-audit"},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(index))
+	}))
+}
+
+func TestResolveRegistryRepo(t *testing.T) {
+	srv := registryServer(t)
+	defer srv.Close()
+
+	tests := map[string]struct {
+		repo      string
+		expected  string
+		withError string
+	}{
+		"package name":           {repo: "internal/cli-deploy", expected: "https://git.example.com/tools/cli-deploy.git"},
+		"name without prefix":    {repo: "internal/deploy", expected: "https://git.example.com/tools/cli-deploy.git"},
+		"command name":           {repo: "internal/rollout", expected: "https://git.example.com/tools/cli-deploy.git"},
+		"github repository":      {repo: "akamai/cli-dns", expected: "akamai/cli-dns"},
+		"bare name":              {repo: "dns", expected: "dns"},
+		"url":                    {repo: "https://github.com/akamai/cli-dns", expected: "https://github.com/akamai/cli-dns"},
+		"not in registry":        {repo: "internal/cli-missing", withError: "package cli-missing not found in registry internal"},
+		"no repository url":      {repo: "internal/audit", withError: "package cli-audit of registry internal has no repository URL"},
+		"registry not reachable": {repo: "broken/cli-deploy", withError: "unable to fetch package index of registry broken (unexpected response status: 404 Not Found)"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			cfg.On("GetValue", registriesSection, "internal").Return(srv.URL+"/internal/index.json", true).Maybe()
+			cfg.On("GetValue", registriesSection, "broken").Return(srv.URL+"/broken/index.json", true).Maybe()
+			cfg.On("GetValue", registriesSection, mock.Anything).Return("", false).Maybe()
+			cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false).Maybe()
+			cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+			ctx := config.Context(context.Background(), cfg)
+
+			repo, err := resolveRegistryRepo(ctx, test.repo)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.withError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, repo)
+		})
+	}
+}
+
+func TestFetchSearchIndex(t *testing.T) {
+	srv := registryServer(t)
+	defer srv.Close()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+	defer func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_PACKAGE_REPO"))
+	}()
+
+	cfg := &config.Mock{}
+	cfg.On("Values").Return(map[string]map[string]string{registriesSection: {
+		"internal": srv.URL + "/internal/index.json",
+		"broken":   srv.URL + "/broken/index.json",
+	}}).Once()
+	cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false).Maybe()
+	cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+	term := &terminal.Mock{}
+	term.On("Writeln", []interface{}{color.YellowString("Warning: %s", "unable to fetch package index of registry broken (unexpected response status: 404 Not Found)")}).Return(0, nil).Once()
+	ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+	index, err := fetchSearchIndex(ctx)
+	require.NoError(t, err)
+	cfg.AssertExpectations(t)
+	term.AssertExpectations(t)
+	names := make([]string, 0, len(index.Packages))
+	for _, pkg := range index.Packages {
+		names = append(names, pkg.Name)
+	}
+	assert.Equal(t, []string{"cli-dns", "internal/cli-deploy", "internal/cli-audit"}, names)
+	found := matchPackages([]string{"rollout"}, index)
+	require.Len(t, found, 1)
+	assert.Equal(t, "https://git.example.com/tools/cli-deploy.git", found[0].URL)
+}
@@ -0,0 +1,166 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// runningDir is the directory in CLI home holding, for each package, a file per running command named after
+// the PID of the Akamai CLI process running it
+const runningDir = "running"
+
+// runningPollInterval is how often a waiting uninstall or update checks whether the package is still in use
+var runningPollInterval = 500 * time.Millisecond
+
+type (
+	// runningCommand is a command of a package which is currently executing
+	runningCommand struct {
+		PID     int
+		Command string
+	}
+
+	waitRunningContextType string
+)
+
+var waitRunningContext waitRunningContextType = "wait-running"
+
+// withWaitRunning makes uninstall and update wait for running commands of a package to finish if wait is set,
+// instead of refusing to change the package
+func withWaitRunning(ctx context.Context, wait bool) context.Context {
+	if !wait {
+		return ctx
+	}
+	return context.WithValue(ctx, waitRunningContext, true)
+}
+
+func waitRunning(ctx context.Context) bool {
+	wait, _ := ctx.Value(waitRunningContext).(bool)
+	return wait
+}
+
+func packageRunningDir(pkgName string) (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, runningDir, pkgName), nil
+}
+
+// markPackageRunning records that this process runs given command of a package until the returned function is called.
+// Failures are only logged, as they must not prevent the command from running.
+func markPackageRunning(ctx context.Context, pkgName, cmd string) func() {
+	logger := log.FromContext(ctx)
+	dir, err := packageRunningDir(pkgName)
+	if err == nil {
+		err = os.MkdirAll(dir, 0700)
+	}
+	path := filepath.Join(dir, strconv.Itoa(os.Getpid()))
+	if err == nil {
+		err = ioutil.WriteFile(path, []byte(cmd), 0600)
+	}
+	if err != nil {
+		logger.Debugf("Unable to record running command: %s", err)
+		return func() {}
+	}
+	return func() {
+		if err := os.Remove(path); err != nil {
+			logger.Debugf("Unable to remove running command record: %s", err)
+		}
+		// removing fails as long as other commands of the package are running
+		_ = os.Remove(dir)
+	}
+}
+
+// runningCommands returns the commands of given package which are executing, sorted by PID.
+// Records left behind by processes which no longer exist are removed.
+func runningCommands(pkgName string) []runningCommand {
+	dir, err := packageRunningDir(pkgName)
+	if err != nil {
+		return nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	running := make([]runningCommand, 0)
+	for _, f := range files {
+		path := filepath.Join(dir, f.Name())
+		pid, err := strconv.Atoi(f.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		if !processAlive(pid) {
+			_ = os.Remove(path)
+			continue
+		}
+		cmd, _ := ioutil.ReadFile(path)
+		running = append(running, runningCommand{PID: pid, Command: strings.TrimSpace(string(cmd))})
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].PID < running[j].PID
+	})
+	return running
+}
+
+// checkPackageIdle returns an error if a command of the package in given directory is running,
+// so that its files are not changed or removed under a live process. If waiting was requested with withWaitRunning,
+// it waits for the commands to finish instead.
+func checkPackageIdle(ctx context.Context, dir string) error {
+	pkgName := filepath.Base(dir)
+	running := runningCommands(pkgName)
+	if len(running) == 0 {
+		return nil
+	}
+	if !waitRunning(ctx) {
+		return fmt.Errorf("package %s is in use by %s, wait for it to finish or use --wait", pkgName, describeRunning(running))
+	}
+
+	terminal.Get(ctx).Writeln(color.CyanString("Waiting for %s to finish...", describeRunning(running)))
+	log.FromContext(ctx).Debugf("Waiting for running commands of package %s", pkgName)
+	ticker := time.NewTicker(runningPollInterval)
+	defer ticker.Stop()
+	for len(running) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			running = runningCommands(pkgName)
+		}
+	}
+	return nil
+}
+
+func describeRunning(running []runningCommand) string {
+	names := make([]string, 0, len(running))
+	for _, r := range running {
+		names = append(names, fmt.Sprintf("\"%s\" (PID %d)", r.Command, r.PID))
+	}
+	return strings.Join(names, ", ")
+}
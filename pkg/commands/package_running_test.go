@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// stalePID is above the highest PID any supported system assigns, so no process is running with it
+const stalePID = 1 << 30
+
+func writeRunningRecord(t *testing.T, pkgName string, pid int, cmd string) string {
+	dir, err := packageRunningDir(pkgName)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	path := filepath.Join(dir, strconv.Itoa(pid))
+	require.NoError(t, ioutil.WriteFile(path, []byte(cmd), 0600))
+	return path
+}
+
+func TestMarkPackageRunning(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	dir, err := packageRunningDir("cli-dns")
+	require.NoError(t, err)
+
+	finish := markPackageRunning(context.Background(), "cli-dns", "dns")
+	data, err := ioutil.ReadFile(filepath.Join(dir, strconv.Itoa(os.Getpid())))
+	require.NoError(t, err)
+	assert.Equal(t, "dns", string(data))
+	// the process does not block changes of packages it runs commands of itself
+	assert.Empty(t, runningCommands("cli-dns"))
+
+	finish()
+	assert.False(t, fileExists(dir))
+}
+
+func TestRunningCommands(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	writeRunningRecord(t, "cli-dns", os.Getppid(), "dns")
+	stale := writeRunningRecord(t, "cli-dns", stalePID, "dns")
+
+	assert.Equal(t, []runningCommand{{PID: os.Getppid(), Command: "dns"}}, runningCommands("cli-dns"))
+	assert.False(t, fileExists(stale))
+	assert.Empty(t, runningCommands("cli-property"))
+}
+
+func TestCheckPackageIdle(t *testing.T) {
+	interval := runningPollInterval
+	runningPollInterval = 10 * time.Millisecond
+	defer func() {
+		runningPollInterval = interval
+	}()
+	tests := map[string]struct {
+		running   bool
+		wait      bool
+		cancel    bool
+		withError string
+	}{
+		"no running commands": {},
+		"running command": {
+			running:   true,
+			withError: fmt.Sprintf(`package cli-dns is in use by "dns" (PID %d), wait for it to finish or use --wait`, os.Getppid()),
+		},
+		"wait for running command": {
+			running: true,
+			wait:    true,
+		},
+		"waiting cancelled": {
+			running:   true,
+			wait:      true,
+			cancel:    true,
+			withError: context.Canceled.Error(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			term := &terminal.Mock{}
+			ctx, cancel := context.WithCancel(terminal.Context(context.Background(), term))
+			defer cancel()
+			ctx = withWaitRunning(ctx, test.wait)
+			if test.running {
+				path := writeRunningRecord(t, "cli-dns", os.Getppid(), "dns")
+				defer func() {
+					_ = os.Remove(path)
+				}()
+				if test.wait {
+					term.On("Writeln", []interface{}{color.CyanString("Waiting for %s to finish...", fmt.Sprintf(`"dns" (PID %d)`, os.Getppid()))}).Return(0, nil).Once()
+					cancelWait := test.cancel
+					go func() {
+						time.Sleep(50 * time.Millisecond)
+						if cancelWait {
+							cancel()
+						} else {
+							_ = os.Remove(path)
+						}
+					}()
+				}
+			}
+
+			err := checkPackageIdle(ctx, filepath.Join(cliHome, ".akamai-cli", "src", "cli-dns"))
+			term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// sourceRecordFile is kept in packages which are not git repositories, recording where they are updated from
+const sourceRecordFile = ".akamai-source.json"
+
+// allowHTTPTarballsKey is the config key allowing tarballs to be downloaded over plain HTTP, which is not authenticated
+const allowHTTPTarballsKey = "allow-http-tarballs"
+
+// errSourceUnchanged is returned by packageSource.fetch when the published package is the revision already installed
+var errSourceUnchanged = errors.New("package is unchanged")
+
+type (
+	// packageSource fetches packages published somewhere else than in a git repository, which is the default source.
+	// Each source defines what an update is: fetching the package again and comparing the revision it reports.
+	packageSource interface {
+		// name identifies the source in the records of installed packages
+		name() string
+		// handles returns true if the package at repo is fetched from this source
+		handles(repo string) bool
+		// packageName returns the name of the directory the package at repo is installed to
+		packageName(repo string) string
+		// fetch downloads the package at repo to dir, which must not exist yet, and returns the revision fetched.
+		// errSourceUnchanged is returned, and nothing is written, if the revision is current.
+		fetch(ctx context.Context, repo, dir, current string) (string, error)
+	}
+
+	// sourceRecord describes where a package which is not a git repository was fetched from
+	sourceRecord struct {
+		Source   string `json:"source"`
+		URL      string `json:"url"`
+		Revision string `json:"revision"`
+	}
+
+	// tarballSource fetches packages published as gzipped tarballs over HTTPS, revisions are checksums of the tarballs
+	tarballSource struct{}
+)
+
+// packageSources are the sources tried, in order, before packages are cloned from git repositories
+var packageSources = []packageSource{tarballSource{}, ociSource{}}
+
+// findPackageSource returns the source of the package at repo, nil for packages in git repositories
+func findPackageSource(repo string) packageSource {
+	for _, source := range packageSources {
+		if source.handles(repo) {
+			return source
+		}
+	}
+	return nil
+}
+
+// packageRepo returns the repository URL of a package given on the command line, which is kept as is for other sources
+func packageRepo(repo string) string {
+	if findPackageSource(repo) != nil {
+		return repo
+	}
+	return tools.Githubize(repo)
+}
+
+func packageSourceByName(name string) packageSource {
+	for _, source := range packageSources {
+		if source.name() == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// fetchFromSource fetches the package at repo to dir and records where it comes from
+func fetchFromSource(ctx context.Context, source packageSource, repo, dir, current string) (string, error) {
+	revision, err := source.fetch(ctx, repo, dir, current)
+	if err != nil {
+		return "", err
+	}
+	if !fileExists(filepath.Join(dir, "cli.json")) {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("no cli.json found in %s", repo)
+	}
+	data, err := json.MarshalIndent(sourceRecord{Source: source.name(), URL: repo, Revision: revision}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, sourceRecordFile), data, 0644); err != nil {
+		return "", err
+	}
+	return revision, nil
+}
+
+// readSourceRecord returns where the package in dir was fetched from, an error if it was cloned from a git repository
+func readSourceRecord(dir string) (*sourceRecord, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, sourceRecordFile))
+	if err != nil {
+		return nil, err
+	}
+	record := &sourceRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+var tarballVersionSuffix = regexp.MustCompile(`-v?[0-9]+(\.[0-9]+)*$`)
+
+func (tarballSource) name() string {
+	return "tarball"
+}
+
+func (tarballSource) handles(repo string) bool {
+	u, err := url.Parse(repo)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	return strings.HasSuffix(u.Path, ".tar.gz") || strings.HasSuffix(u.Path, ".tgz")
+}
+
+// packageName is the name of the tarball without extension and version, e.g. cli-dns for cli-dns-1.2.0.tar.gz
+func (tarballSource) packageName(repo string) string {
+	name := path.Base(repo)
+	if u, err := url.Parse(repo); err == nil {
+		name = path.Base(u.Path)
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".tgz"), ".tar.gz")
+	return tarballVersionSuffix.ReplaceAllString(name, "")
+}
+
+func (s tarballSource) fetch(ctx context.Context, repo, dir, current string) (string, error) {
+	if u, err := url.Parse(repo); err == nil && u.Scheme == "http" {
+		if val, _ := config.Get(ctx).GetValue("cli", allowHTTPTarballsKey); strings.TrimSpace(val) != "true" {
+			return "", fmt.Errorf("tarballs are only downloaded over HTTPS, set cli.%s to true to download %s", allowHTTPTarballsKey, repo)
+		}
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "."+filepath.Base(dir)+"-download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			log.FromContext(ctx).Debugf("Unable to remove downloaded tarball: %s", err)
+		}
+	}()
+	tarball := filepath.Join(tmp, "package.tar.gz")
+	client := &http.Client{Transport: download.ProxyTransport(ctx)}
+	if err := download.File(ctx, repo, tarball, download.Options{Client: client, Limiter: download.ConfiguredLimiter(ctx)}); err != nil {
+		return "", err
+	}
+	revision, err := fileHash(tarball)
+	if err != nil {
+		return "", err
+	}
+	if revision == current {
+		return "", errSourceUnchanged
+	}
+
+	if err := extractTarball(ctx, tarball, dir); err != nil {
+		return "", err
+	}
+	return revision, nil
+}
+
+// extractTarball extracts the package in the gzipped tarball to dir, skipping the directory holding all its files
+func extractTarball(ctx context.Context, tarball, dir string) error {
+	prefix, err := tarballPrefix(tarball)
+	if err != nil {
+		return fmt.Errorf("invalid tarball: %w", err)
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.FromContext(ctx).Debugf("Unable to close tarball: %s", err)
+		}
+	}()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid tarball: %w", err)
+	}
+	return extractPackageArchive(tar.NewReader(gz), prefix, dir)
+}
+
+// tarballPrefix returns the directory holding all the files of the tarball, such as the "<repository>-<tag>" directory
+// of tarballs created by GitHub releases, empty if files are at the root of the tarball
+func tarballPrefix(tarball string) (string, error) {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	tr := tar.NewReader(gz)
+	prefix, first := "", true
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return prefix, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		name := path.Clean(header.Name)
+		top := strings.SplitN(name, "/", 2)[0]
+		if first {
+			prefix, first = top, false
+		}
+		if top != prefix || (name == top && header.Typeflag != tar.TypeDir) {
+			return "", nil
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/oci"
+)
+
+// mediaTypePackageLayer is the media type of the layer holding the package, a gzipped tarball of its files
+const mediaTypePackageLayer = "application/vnd.akamai.cli.package.layer.v1.tar+gzip"
+
+// ociSource fetches packages pushed to OCI registries as artifacts, e.g. with "oras push". Revisions are digests
+// of the artifact manifests, so a package referenced by digest is pinned to it and one referenced by tag follows it.
+type ociSource struct{}
+
+func (ociSource) name() string {
+	return "oci"
+}
+
+func (ociSource) handles(repo string) bool {
+	return strings.HasPrefix(repo, oci.Scheme)
+}
+
+// packageName is the last component of the repository, e.g. cli-dns for oci://registry.example.com/akamai/cli-dns:1.2.0
+func (ociSource) packageName(repo string) string {
+	ref, err := oci.ParseReference(repo)
+	if err != nil {
+		return path.Base(strings.TrimPrefix(repo, oci.Scheme))
+	}
+	return ref.Name()
+}
+
+func (ociSource) fetch(ctx context.Context, repo, dir, current string) (string, error) {
+	ref, err := oci.ParseReference(repo)
+	if err != nil {
+		return "", err
+	}
+	client := &oci.Client{
+		HTTP:        &http.Client{Transport: download.LimitTransport(ctx, nil)},
+		Credentials: oci.DockerCredentials,
+		PlainHTTP:   oci.IsLocal(ref.Registry),
+	}
+	manifest, digest, err := client.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if digest == current {
+		return "", errSourceUnchanged
+	}
+	layer, err := packageLayer(manifest)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "."+filepath.Base(dir)+"-download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			log.FromContext(ctx).Debugf("Unable to remove downloaded layer: %s", err)
+		}
+	}()
+	tarball := filepath.Join(tmp, "package.tar.gz")
+	f, err := os.Create(tarball)
+	if err != nil {
+		return "", err
+	}
+	err = client.FetchBlob(ctx, ref, *layer, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := extractTarball(ctx, tarball, dir); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// packageLayer returns the layer of the artifact holding the package: the layer of the package media type,
+// or the only layer of the artifact if it is a gzipped tarball
+func packageLayer(manifest *oci.Manifest) (*oci.Descriptor, error) {
+	for i, layer := range manifest.Layers {
+		if layer.MediaType == mediaTypePackageLayer {
+			return &manifest.Layers[i], nil
+		}
+	}
+	if len(manifest.Layers) == 1 && strings.HasSuffix(manifest.Layers[0].MediaType, "tar+gzip") {
+		return &manifest.Layers[0], nil
+	}
+	return nil, fmt.Errorf("no layer of media type %s found", mediaTypePackageLayer)
+}
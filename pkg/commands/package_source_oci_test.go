@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOCISource(t *testing.T) {
+	tests := map[string]struct {
+		repo     string
+		handles  bool
+		expected string
+	}{
+		"tag":            {repo: "oci://registry.example.com/akamai/cli-dns:1.2.0", handles: true, expected: "cli-dns"},
+		"digest":         {repo: "oci://localhost:5000/cli-dns@sha256:" + strings.Repeat("a", 64), handles: true, expected: "cli-dns"},
+		"git repository": {repo: "https://github.com/akamai/cli-dns.git"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.handles, ociSource{}.handles(test.repo))
+			if test.handles {
+				assert.Equal(t, test.expected, packageDirName(test.repo))
+				assert.Equal(t, test.repo, packageRepo(test.repo))
+			}
+		})
+	}
+}
+
+func TestFetchFromRegistry(t *testing.T) {
+	layer := newTarball(t, false, [2]string{"cli.json", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`}, [2]string{"bin/akamai-dns", "binary"})
+	digestOf := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	manifestOf := func(mediaTypes ...string) []byte {
+		layers := make([]oci.Descriptor, 0)
+		for _, mediaType := range mediaTypes {
+			layers = append(layers, oci.Descriptor{MediaType: mediaType, Digest: digestOf(layer), Size: int64(len(layer))})
+		}
+		data, err := json.Marshal(oci.Manifest{SchemaVersion: 2, MediaType: oci.MediaTypeImageManifest, Layers: layers})
+		require.NoError(t, err)
+		return data
+	}
+	manifests := map[string][]byte{
+		"1.0.0":    manifestOf("application/vnd.oci.image.config.v1+json", mediaTypePackageLayer),
+		"single":   manifestOf("application/vnd.oci.image.layer.v1.tar+gzip"),
+		"no-layer": manifestOf("application/vnd.oci.image.config.v1+json", "text/plain"),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "/v2/akamai/cli-dns/"
+		name := strings.TrimPrefix(r.URL.Path, prefix+"manifests/")
+		for tag, manifest := range manifests {
+			if name == tag || name == digestOf(manifest) {
+				_, _ = w.Write(manifest)
+				return
+			}
+		}
+		if r.URL.Path == prefix+"blobs/"+digestOf(layer) {
+			_, _ = w.Write(layer)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	registry := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := map[string]struct {
+		ref       string
+		current   string
+		unchanged bool
+		withError string
+	}{
+		"tag":              {ref: "oci://" + registry + "/akamai/cli-dns:1.0.0"},
+		"digest":           {ref: "oci://" + registry + "/akamai/cli-dns@" + digestOf(manifests["1.0.0"])},
+		"single layer":     {ref: "oci://" + registry + "/akamai/cli-dns:single"},
+		"unchanged":        {ref: "oci://" + registry + "/akamai/cli-dns:1.0.0", current: digestOf(manifests["1.0.0"]), unchanged: true},
+		"no package layer": {ref: "oci://" + registry + "/akamai/cli-dns:no-layer", withError: "no layer of media type " + mediaTypePackageLayer + " found"},
+		"tag not found":    {ref: "oci://" + registry + "/akamai/cli-dns:2.0.0", withError: "not found in " + registry},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-source")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false)
+			cfg.On("GetValue", "cli", "proxy").Return("", false)
+			ctx := config.Context(context.Background(), cfg)
+			pkgDir := filepath.Join(dir, "cli-dns")
+
+			revision, err := fetchFromSource(ctx, ociSource{}, test.ref, pkgDir, test.current)
+			switch {
+			case test.unchanged:
+				assert.True(t, errors.Is(err, errSourceUnchanged), "expected unchanged package, got %v", err)
+				assert.False(t, fileExists(pkgDir))
+			case test.withError != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.False(t, fileExists(pkgDir))
+			default:
+				require.NoError(t, err)
+				data, err := ioutil.ReadFile(filepath.Join(pkgDir, "bin", "akamai-dns"))
+				require.NoError(t, err)
+				assert.Equal(t, "binary", string(data))
+				record, err := readSourceRecord(pkgDir)
+				require.NoError(t, err)
+				assert.Equal(t, sourceRecord{Source: "oci", URL: test.ref, Revision: revision}, *record)
+				assert.True(t, strings.HasPrefix(revision, "sha256:"))
+			}
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			for _, file := range files {
+				assert.Equal(t, "cli-dns", file.Name(), "downloads are removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func newTarball(t *testing.T, globalHeader bool, files ...[2]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	if globalHeader {
+		// git archive, used for GitHub release tarballs, starts with the commit in a pax global header
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "0a1b2c3"}, Format: tar.FormatPAX}))
+	}
+	for _, file := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0644, Size: int64(len(file[1])), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(file[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestTarballSource(t *testing.T) {
+	tests := map[string]struct {
+		repo     string
+		handles  bool
+		expected string
+	}{
+		"release tarball":       {repo: "https://example.com/releases/cli-dns-1.2.0.tar.gz", handles: true, expected: "cli-dns"},
+		"tgz with v prefix":     {repo: "https://example.com/cli-dns-v1.2.tgz?token=abc", handles: true, expected: "cli-dns"},
+		"no version":            {repo: "http://example.com/cli-dns.tar.gz", handles: true, expected: "cli-dns"},
+		"git repository":        {repo: "https://github.com/akamai/cli-dns.git"},
+		"tarball on filesystem": {repo: "file:///tmp/cli-dns.tar.gz"},
+		"zip archive":           {repo: "https://example.com/cli-dns-1.2.0.zip"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.handles, tarballSource{}.handles(test.repo))
+			if test.handles {
+				assert.Equal(t, test.expected, tarballSource{}.packageName(test.repo))
+				assert.Equal(t, test.expected, packageDirName(test.repo))
+			}
+		})
+	}
+}
+
+func TestFetchTarball(t *testing.T) {
+	cliJSON := `{"commands": [{"name": "dns", "version": "1.0.0"}]}`
+	tarballs := map[string][]byte{
+		"/release.tar.gz": newTarball(t, true, [2]string{"cli-dns-1.0.0/cli.json", cliJSON}, [2]string{"cli-dns-1.0.0/bin/akamai-dns", "binary"}),
+		"/root.tar.gz":    newTarball(t, false, [2]string{"cli.json", cliJSON}, [2]string{"bin/akamai-dns", "binary"}),
+		"/empty.tar.gz":   newTarball(t, false, [2]string{"cli-dns/README.md", "readme"}),
+		"/invalid.tar.gz": []byte("not a tarball"),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := tarballs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		path      string
+		current   string
+		unchanged bool
+		noHTTP    bool
+		withError string
+	}{
+		"files in a directory": {path: "/release.tar.gz"},
+		"files at the root":    {path: "/root.tar.gz"},
+		"unchanged":            {path: "/root.tar.gz", unchanged: true},
+		"no cli.json":          {path: "/empty.tar.gz", withError: "no cli.json found in " + srv.URL + "/empty.tar.gz"},
+		"not a tarball":        {path: "/invalid.tar.gz", withError: "invalid tarball"},
+		"not found":            {path: "/missing.tar.gz", withError: "404"},
+		"plain http":           {path: "/root.tar.gz", noHTTP: true, withError: "tarballs are only downloaded over HTTPS, set cli.allow-http-tarballs to true to download " + srv.URL + "/root.tar.gz"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cli-source")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "bandwidth-limit").Return("", false).Maybe()
+			cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+			cfg.On("GetValue", "cli", allowHTTPTarballsKey).Return(strconv.FormatBool(!test.noHTTP), true)
+			ctx := config.Context(context.Background(), cfg)
+			repo := srv.URL + test.path
+			pkgDir := filepath.Join(dir, "cli-dns")
+			if test.unchanged {
+				test.current, err = fetchFromSource(ctx, tarballSource{}, repo, filepath.Join(dir, "first"), "")
+				require.NoError(t, err)
+			}
+
+			revision, err := fetchFromSource(ctx, tarballSource{}, repo, pkgDir, test.current)
+			switch {
+			case test.unchanged:
+				assert.True(t, errors.Is(err, errSourceUnchanged), "expected unchanged package, got %v", err)
+				assert.False(t, fileExists(pkgDir))
+			case test.withError != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.False(t, fileExists(pkgDir))
+			default:
+				require.NoError(t, err)
+				data, err := ioutil.ReadFile(filepath.Join(pkgDir, "bin", "akamai-dns"))
+				require.NoError(t, err)
+				assert.Equal(t, "binary", string(data))
+				record, err := readSourceRecord(pkgDir)
+				require.NoError(t, err)
+				assert.Equal(t, sourceRecord{Source: "tarball", URL: repo, Revision: revision}, *record)
+			}
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			for _, file := range files {
+				assert.Contains(t, []string{"first", "cli-dns"}, file.Name(), "downloads are removed")
+			}
+		})
+	}
+}
+
+func TestInstallAndUpdateFromTarball(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	tarball := newTarball(t, false, [2]string{"cli.json", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer srv.Close()
+	repo := srv.URL + "/cli-dns-1.0.0.tar.gz"
+	dir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-dns")
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", mock.Anything, mock.Anything).Return()
+	m.term.On("OK").Return()
+	m.term.On("WarnOK").Return()
+	m.term.On("Fail").Return()
+	m.term.On("Writeln", mock.Anything).Return(0, nil)
+	m.term.On("Printf", mock.Anything, mock.Anything).Return()
+	m.cfg.On("GetValue", "cli", allowHTTPTarballsKey).Return("true", true)
+	m.cfg.On("GetValue", "cli", mock.Anything).Return("", false)
+	m.cfg.On("GetValue", "dns", mock.Anything).Return("", false).Maybe()
+	m.langManager.On("Install", dir, packages.LanguageRequirements{}, []string{"dns"}).Return(nil).Twice()
+	_, ctx := setupTestApp(&cli.Command{Name: "install"}, m)
+
+	_, err := installPackage(ctx, m.gitRepo, m.langManager, repo, "", false)
+	require.NoError(t, err)
+	record, err := readSourceRecord(dir)
+	require.NoError(t, err)
+	store, err := loadPackageStore(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, repo, store.Packages["cli-dns"].Source)
+	assert.Equal(t, record.Revision, store.Packages["cli-dns"].Commit)
+
+	exec := []string{filepath.Join(dir, "cli.json")}
+	res, err := updatePackageExec(ctx, m.gitRepo, m.langManager, log.FromContext(ctx), "dns", exec, "", false, false)
+	require.NoError(t, err)
+	assert.Equal(t, updateStatusUpToDate, res.Status)
+
+	tarball = newTarball(t, false, [2]string{"cli.json", `{"commands": [{"name": "dns", "version": "1.1.0"}]}`})
+	res, err = updatePackageExec(ctx, m.gitRepo, m.langManager, log.FromContext(ctx), "dns", exec, "", false, false)
+	require.NoError(t, err)
+	assert.Equal(t, updateStatusUpdated, res.Status)
+	assert.Equal(t, "1.0.0", res.OldVersion)
+	assert.Equal(t, "1.1.0", res.NewVersion)
+	assert.Equal(t, record.Revision, res.OldCommit)
+	assert.NotEqual(t, record.Revision, res.NewCommit)
+
+	_, err = updatePackageExec(ctx, m.gitRepo, m.langManager, log.FromContext(ctx), "dns", exec, "v1.0.0", false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "versions are not supported for packages of the tarball source")
+	m.gitRepo.AssertExpectations(t)
+	m.langManager.AssertExpectations(t)
+	files, err := ioutil.ReadDir(filepath.Dir(dir))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "only the package is left")
+}
@@ -0,0 +1,414 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// packageStoreFile is the file in CLI home directory holding metadata of all packages
+	packageStoreFile = "packages.json"
+
+	// packageStoreVersion is the current schema version of the package store, increased with every incompatible change
+	packageStoreVersion = 1
+
+	// packageEventMigrate is recorded for packages found on disk which were not tracked by the store yet,
+	// e.g. installed by an older version of Akamai CLI
+	packageEventMigrate = "migrate"
+	// packageEventSetRemote is recorded when the package repository is changed with "package set-remote"
+	packageEventSetRemote = "set-remote"
+	packageEventEnable    = "enable"
+	packageEventDisable   = "disable"
+)
+
+// packageStoreMu serializes the updates of the package store made by packages updated in parallel,
+// the store is locked against other processes by updatePackageStore
+var packageStoreMu sync.Mutex
+
+// errPackageStoreUnchanged is returned by updates of the package store which have nothing to save
+var errPackageStoreUnchanged = errors.New("package store unchanged")
+
+// packageStoreMigrations upgrade a store of schema version i+1 to version i+2
+var packageStoreMigrations []func(*packageStore)
+
+var packageStoreEvents = map[string]bool{
+	packageEventInstall:   true,
+	packageEventUpdate:    true,
+	packageEventUninstall: true,
+	packageEventMigrate:   true,
+	packageEventSetRemote: true,
+	packageEventEnable:    true,
+	packageEventDisable:   true,
+	packageEventRollback:  true,
+}
+
+type (
+	// packageStore holds metadata of installed and uninstalled packages, keyed by package directory name
+	packageStore struct {
+		Version  int                         `json:"version"`
+		Packages map[string]*packageMetadata `json:"packages"`
+	}
+
+	// packageMetadata describes a package as of its last recorded event
+	packageMetadata struct {
+		Name      string              `json:"name"`
+		Installed bool                `json:"installed"`
+		Disabled  bool                `json:"disabled,omitempty"`
+		Channel   string              `json:"channel,omitempty"`
+		Branch    string              `json:"branch,omitempty"`
+		Pinned    string              `json:"pinned,omitempty"`
+		Locked    string              `json:"locked,omitempty"`
+		Archive   string              `json:"archive,omitempty"`
+		Previous  string              `json:"previous,omitempty"`
+		Source    string              `json:"source,omitempty"`
+		Version   string              `json:"version,omitempty"`
+		Language  string              `json:"language,omitempty"`
+		Commit    string              `json:"commit,omitempty"`
+		Commands  []string            `json:"commands"`
+		Binaries  []string            `json:"binaries"`
+		History   []packageStoreEvent `json:"history"`
+	}
+
+	// packageStoreEvent is a single entry of package install history
+	packageStoreEvent struct {
+		Event   string    `json:"event"`
+		Version string    `json:"version,omitempty"`
+		Commit  string    `json:"commit,omitempty"`
+		Source  string    `json:"source,omitempty"`
+		Time    time.Time `json:"time"`
+	}
+)
+
+func packageStorePath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, packageStoreFile), nil
+}
+
+// loadPackageStore reads the package store, an empty one if it does not exist yet
+// A store failing validation is kept aside as packages.json.invalid and created again, a store written by a newer
+// version of Akamai CLI is returned as an error, so that it is never overwritten
+func loadPackageStore(ctx context.Context) (*packageStore, error) {
+	path, err := packageStorePath()
+	if err != nil {
+		return nil, err
+	}
+	logger := log.FromContext(ctx)
+	store := &packageStore{}
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		store = &packageStore{Version: packageStoreVersion, Packages: make(map[string]*packageMetadata)}
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, store); err == nil && store.Version > packageStoreVersion {
+			return nil, fmt.Errorf("package store %s has schema version %d, this version of Akamai CLI supports up to %d", path, store.Version, packageStoreVersion)
+		}
+		if err := store.decode(data); err != nil {
+			logger.Warnf("Invalid package store %s, creating it again from installed packages: %s", path, err)
+			if err := os.Rename(path, path+".invalid"); err != nil {
+				return nil, err
+			}
+			store = &packageStore{Version: packageStoreVersion, Packages: make(map[string]*packageMetadata)}
+		}
+	}
+	return store, nil
+}
+
+// decode parses and validates the store, upgrading it to the current schema version
+func (s *packageStore) decode(data []byte) error {
+	*s = packageStore{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return err
+	}
+	if s.Version < 1 {
+		return fmt.Errorf("missing schema version")
+	}
+	for ; s.Version < packageStoreVersion; s.Version++ {
+		packageStoreMigrations[s.Version-1](s)
+	}
+	if s.Packages == nil {
+		return fmt.Errorf("missing packages")
+	}
+	for name, pkg := range s.Packages {
+		if err := pkg.validate(name); err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// status returns whether the package is installed, disabled or uninstalled
+func (p *packageMetadata) status() string {
+	switch {
+	case !p.Installed:
+		return "uninstalled"
+	case p.Disabled:
+		return "disabled"
+	default:
+		return "installed"
+	}
+}
+
+func (p *packageMetadata) validate(name string) error {
+	if p == nil {
+		return fmt.Errorf("missing metadata")
+	}
+	if p.Name != name || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid name %q", p.Name)
+	}
+	if len(p.History) == 0 {
+		return fmt.Errorf("missing history")
+	}
+	for _, event := range p.History {
+		if !packageStoreEvents[event.Event] {
+			return fmt.Errorf("unknown event %q", event.Event)
+		}
+		if event.Time.IsZero() {
+			return fmt.Errorf("missing time of event %s", event.Event)
+		}
+	}
+	return nil
+}
+
+// migrateInstalled adds packages found in the packages directory which are not tracked by the store yet,
+// except the package with given name, which is about to be recorded by the caller
+// It returns true if any package was added.
+func (s *packageStore) migrateInstalled(ctx context.Context, except string) bool {
+	var migrated bool
+	for _, dir := range getPackagePaths() {
+		name := filepath.Base(dir)
+		if name == except {
+			continue
+		}
+		if pkg, ok := s.Packages[name]; ok && pkg.Installed {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, "cli.json")); err != nil {
+			continue
+		}
+		at := time.Now().UTC().Truncate(time.Second)
+		if record, err := readInstallRecord(dir); err == nil {
+			at = record.InstalledAt
+		}
+		if err := s.record(packageEventMigrate, dir, at); err != nil {
+			log.FromContext(ctx).Debugf("Unable to migrate package %s: %s", name, err)
+			continue
+		}
+		migrated = true
+	}
+	return migrated
+}
+
+// record reads the current state of the package in given directory and appends the event to its history
+// An uninstalled package keeps its metadata and history, so that they are available when it is installed again
+func (s *packageStore) record(event, dir string, at time.Time) error {
+	name := filepath.Base(dir)
+	pkg, ok := s.Packages[name]
+	if !ok {
+		pkg = &packageMetadata{Name: name}
+	}
+	if event == packageEventUninstall {
+		pkg.Installed = false
+		pkg.Disabled = false
+		pkg.Channel, pkg.Branch = "", ""
+		pkg.Pinned, pkg.Previous, pkg.Locked = "", "", ""
+		pkg.Archive = ""
+		pkg.Binaries = []string{}
+		pkg.History = append(pkg.History, packageStoreEvent{Event: event, Version: pkg.Version, Time: at})
+		s.Packages[name] = pkg
+		return nil
+	}
+
+	sub, err := readPackage(dir)
+	if err != nil {
+		return err
+	}
+	s.Packages[name] = pkg
+	pkg.Installed = true
+	switch event {
+	case packageEventInstall:
+		pkg.Archive = ""
+	case packageEventEnable:
+		pkg.Disabled = false
+	case packageEventDisable:
+		pkg.Disabled = true
+	}
+	pkg.Version = packageVersion(sub)
+	pkg.Language = packages.Language(sub.Requirements)
+	previous := pkg.Commit
+	pkg.Commit = ""
+	if record, err := readInstallRecord(dir); err == nil {
+		pkg.Commit = record.Commit
+	}
+	// the commit replaced by an update is kept for "rollback", which in turn keeps the commit it replaced
+	if (event == packageEventUpdate || event == packageEventRollback) && previous != "" && previous != pkg.Commit {
+		pkg.Previous = previous
+	}
+	if source, err := git.OriginURL(dir); err == nil {
+		pkg.Source = source
+	} else if record, err := readSourceRecord(dir); err == nil {
+		pkg.Source = record.URL
+	}
+	pkg.Commands = make([]string, 0, len(sub.Commands))
+	pkg.Binaries = make([]string, 0)
+	for _, cmd := range sub.Commands {
+		pkg.Commands = append(pkg.Commands, cmd.Name)
+		for _, path := range commandExecutables(dir, cmd.Name) {
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				pkg.Binaries = append(pkg.Binaries, filepath.ToSlash(rel))
+			}
+		}
+	}
+	sort.Strings(pkg.Binaries)
+	pkg.History = append(pkg.History, packageStoreEvent{Event: event, Version: pkg.Version, Commit: pkg.Commit, Source: pkg.Source, Time: at})
+	return nil
+}
+
+// save writes the store to a temporary file first, so that an interrupted write never leaves a truncated store
+func (s *packageStore) save() error {
+	path, err := packageStorePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), packageStoreFile+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// updatePackageStore loads the package store, applies update to it and saves it, holding the store lock all the time,
+// so that changes made at the same time by other processes, e.g. the background automatic update, are never lost.
+// The store is not saved if update returns an error, errPackageStoreUnchanged is not reported.
+func updatePackageStore(ctx context.Context, update func(*packageStore) error) error {
+	packageStoreMu.Lock()
+	defer packageStoreMu.Unlock()
+	path, err := packageStorePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := config.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store, err := loadPackageStore(ctx)
+	if err != nil {
+		return err
+	}
+	if err := update(store); err != nil {
+		if errors.Is(err, errPackageStoreUnchanged) {
+			return nil
+		}
+		return err
+	}
+	return store.save()
+}
+
+// recordPackageEvent updates the package store after an operation on the package in given directory,
+// failures are only logged as they do not affect the package itself
+func recordPackageEvent(ctx context.Context, event, dir string) {
+	err := updatePackageStore(ctx, func(store *packageStore) error {
+		store.migrateInstalled(ctx, filepath.Base(dir))
+		if event == packageEventUninstall && store.Packages[filepath.Base(dir)] == nil {
+			return nil
+		}
+		return store.record(event, dir, time.Now().UTC().Truncate(time.Second))
+	})
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to update package store: %s", err)
+	}
+}
+
+// packageByName returns the name of the package stored under given name, or providing a command with that name
+func (s *packageStore) packageByName(name string) (string, bool) {
+	if _, ok := s.Packages[name]; ok {
+		return name, true
+	}
+	name = strings.ToLower(name)
+	for pkgName, pkg := range s.Packages {
+		if !pkg.Installed {
+			continue
+		}
+		for _, cmd := range pkg.Commands {
+			if cmd == name {
+				return pkgName, true
+			}
+		}
+	}
+	return "", false
+}
+
+// disabledPackages returns names of packages disabled with "akamai disable", which are hidden from dispatch and help
+// It is called on every command lookup, so it only reads the store without migrating it; an unreadable store disables nothing.
+func disabledPackages() map[string]bool {
+	disabled := make(map[string]bool)
+	path, err := packageStorePath()
+	if err != nil {
+		return disabled
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return disabled
+	}
+	var store packageStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return disabled
+	}
+	for name, pkg := range store.Packages {
+		if pkg != nil && pkg.Installed && pkg.Disabled {
+			disabled[name] = true
+		}
+	}
+	return disabled
+}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestLoadPackageStore(t *testing.T) {
+	tests := map[string]struct {
+		content       string
+		expectInvalid bool
+		expected      []string
+		withError     string
+	}{
+		"no store": {
+			expected: []string{},
+		},
+		"valid store": {
+			content:  `{"version": 1, "packages": {"cli-dns": {"name": "cli-dns", "installed": true, "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`,
+			expected: []string{"cli-dns"},
+		},
+		"unknown event": {
+			content:       `{"version": 1, "packages": {"cli-dns": {"name": "cli-dns", "history": [{"event": "explode", "time": "2021-03-01T10:00:00Z"}]}}}`,
+			expectInvalid: true,
+			expected:      []string{},
+		},
+		"name mismatch": {
+			content:       `{"version": 1, "packages": {"cli-dns": {"name": "../cli-dns", "history": [{"event": "install", "time": "2021-03-01T10:00:00Z"}]}}}`,
+			expectInvalid: true,
+			expected:      []string{},
+		},
+		"malformed json": {
+			content:       `{"version": 1, "packages": `,
+			expectInvalid: true,
+			expected:      []string{},
+		},
+		"newer schema version": {
+			content:   `{"version": 99, "packages": {}}`,
+			withError: "has schema version 99",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			path := filepath.Join(cliHome, ".akamai-cli", packageStoreFile)
+			if test.content != "" {
+				require.NoError(t, ioutil.WriteFile(path, []byte(test.content), 0644))
+			}
+
+			store, err := loadPackageStore(context.Background())
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				content, err := ioutil.ReadFile(path)
+				require.NoError(t, err)
+				assert.Equal(t, test.content, string(content))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, packageStoreVersion, store.Version)
+			names := make([]string, 0)
+			for name := range store.Packages {
+				names = append(names, name)
+			}
+			assert.Equal(t, test.expected, names)
+			if test.expectInvalid {
+				assert.FileExists(t, path+".invalid")
+			}
+		})
+	}
+}
+
+func TestRecordPackageEvent(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+	dnsDir := writeStorePackage(t, srcDir, "cli-dns", `{"requirements": {"node": "7.0.0"}, "commands": [{"name": "dns", "version": "1.2.0"}]}`)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dnsDir, "akamai-dns"), []byte("#!/bin/sh"), 0755))
+	writeStorePackage(t, srcDir, "cli-property", `{"commands": [{"name": "property", "version": "2.0.0"}]}`)
+	ctx := context.Background()
+
+	recordPackageEvent(ctx, packageEventInstall, dnsDir)
+	store, err := loadPackageStore(ctx)
+	require.NoError(t, err)
+	require.Contains(t, store.Packages, "cli-dns")
+	dns := store.Packages["cli-dns"]
+	assert.True(t, dns.Installed)
+	assert.Equal(t, "1.2.0", dns.Version)
+	assert.Equal(t, packages.Javascript, dns.Language)
+	assert.Equal(t, []string{"dns"}, dns.Commands)
+	assert.Equal(t, []string{"akamai-dns"}, dns.Binaries)
+	require.Len(t, dns.History, 1)
+	assert.Equal(t, packageEventInstall, dns.History[0].Event)
+	// packages installed before the store existed are migrated
+	require.Contains(t, store.Packages, "cli-property")
+	require.Len(t, store.Packages["cli-property"].History, 1)
+	assert.Equal(t, packageEventMigrate, store.Packages["cli-property"].History[0].Event)
+
+	require.NoError(t, os.RemoveAll(dnsDir))
+	recordPackageEvent(ctx, packageEventUninstall, dnsDir)
+	store, err = loadPackageStore(ctx)
+	require.NoError(t, err)
+	dns = store.Packages["cli-dns"]
+	assert.False(t, dns.Installed)
+	assert.Empty(t, dns.Binaries)
+	require.Len(t, dns.History, 2)
+	assert.Equal(t, packageEventUninstall, dns.History[1].Event)
+	assert.Equal(t, "1.2.0", dns.History[1].Version)
+}
+
+func TestUpdatePackageStoreLocked(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+	dnsDir := writeStorePackage(t, srcDir, "cli-dns", `{"commands": [{"name": "dns", "version": "1.2.0"}]}`)
+	path, err := packageStorePath()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// another process, e.g. the background automatic update, holds the store
+	unlock, err := config.LockFile(path)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		recordPackageEvent(ctx, packageEventInstall, dnsDir)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("package store updated while locked by another process")
+	case <-time.After(200 * time.Millisecond):
+	}
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	unlock()
+	<-done
+
+	store, err := loadPackageStore(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, store.Packages, "cli-dns")
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.ElementsMatch(t, []string{"src", packageStoreFile}, names)
+}
+
+func TestCmdPackageInfo(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		init      func(*testing.T, *mocked)
+		withError string
+	}{
+		"uninstalled package": {
+			args: []string{"cli-dns"},
+			init: func(t *testing.T, m *mocked) {
+				bold := color.New(color.FgWhite, color.Bold)
+				m.term.On("Printf", bold.Sprint("Package:")+" %s (%s)\n", []interface{}{"cli-dns", color.YellowString("uninstalled")}).Return().Once()
+				m.term.On("Printf", bold.Sprint("Source:")+" %s\n", []interface{}{"https://github.com/akamai/cli-dns.git"}).Return().Once()
+				m.term.On("Printf", bold.Sprint("Version:")+" %s\n", []interface{}{"1.2.0"}).Return().Once()
+				m.term.On("Writeln", []interface{}{bold.Sprint("History:")}).Return(0, nil).Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && regexp.MustCompile(` install\s+1.2.0 \(0123456\)$`).MatchString(args[0].(string))
+				})).Return(0, nil).Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && regexp.MustCompile(` uninstall\s+1.2.0$`).MatchString(args[0].(string))
+				})).Return(0, nil).Once()
+			},
+		},
+		"json output": {
+			args: []string{"--output", "json", "cli-dns"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && assert.Contains(t, args[0], `"installed": false`) && assert.Contains(t, args[0], `"event": "uninstall"`)
+				})).Return(0, nil).Once()
+			},
+		},
+		"unknown package": {
+			args:      []string{"cli-missing"},
+			init:      func(t *testing.T, m *mocked) {},
+			withError: `Package "cli-missing" not found`,
+		},
+		"no args": {
+			init:      func(t *testing.T, m *mocked) {},
+			withError: "You must specify a package or command name",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			installedAt := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+			store := &packageStore{Version: packageStoreVersion, Packages: map[string]*packageMetadata{
+				"cli-dns": {
+					Name:    "cli-dns",
+					Source:  "https://github.com/akamai/cli-dns.git",
+					Version: "1.2.0",
+					History: []packageStoreEvent{
+						{Event: packageEventInstall, Version: "1.2.0", Commit: "0123456789", Time: installedAt},
+						{Event: packageEventUninstall, Version: "1.2.0", Time: installedAt.Add(time.Hour)},
+					},
+				},
+			}}
+			require.NoError(t, store.save())
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			command := &cli.Command{
+				Name: "package",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "info",
+						Action: cmdPackageInfo(m.langManager),
+						Flags:  []cli.Flag{outputFlag()},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "package", "info")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// packageStoreHome creates an empty CLI home directory and points AKAMAI_CLI_HOME to it
+func packageStoreHome(t *testing.T) string {
+	cliHome, err := ioutil.TempDir("", "cli-home")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(cliHome, ".akamai-cli", "src"), 0755))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	return cliHome
+}
+
+func writeStorePackage(t *testing.T, srcDir, name, cliJSON string) string {
+	dir := filepath.Join(srcDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cli.json"), []byte(cliJSON), 0644))
+	return dir
+}
@@ -0,0 +1,190 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// packageVersionLatest given to "update --version" removes the pin, so that the package follows its update channel again
+const packageVersionLatest = "latest"
+
+// packageEventRollback is recorded when a package is restored to the commit installed before its last update
+const packageEventRollback = "rollback"
+
+func cmdRollback(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("ROLLBACK START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("ROLLBACK FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("ROLLBACK ERROR: %v", e.Error())
+			}
+		}()
+		term := terminal.Get(c.Context)
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify a package or command name"), 1)
+		}
+
+		store, err := loadPackageStore(c.Context)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read package store: %s", err), 1)
+		}
+		store.migrateInstalled(c.Context, "")
+		name := c.Args().First()
+		pkgName, ok := installedPackageName(c, langManager, store, name)
+		if !ok {
+			return packageNotFound(name)
+		}
+		pkg := store.Packages[pkgName]
+		if pkg.Previous == "" {
+			return cli.Exit(color.RedString("No previous version of package \"%s\" is recorded, it has to be updated first", pkgName), 1)
+		}
+		srcPath, err := tools.GetAkamaiCliSrcPath()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to find packages directory: %s", err), 1)
+		}
+		dir := filepath.Join(srcPath, pkgName)
+		previous := pkg.Previous
+
+		term.Spinner().Start("Restoring package \"%s\" to commit %s...", pkgName, shortHash(previous))
+		if err := gitRepo.Open(dir); err != nil {
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to roll back, there is an issue with the package repo: %s", err.Error()), 1)
+		}
+		branch := headBranch(gitRepo)
+		checkoutCtx, cancel := cloneContext(c.Context)
+		defer cancel()
+		if err := gitRepo.CheckoutCommit(checkoutCtx, git.DefaultRemoteName, previous); err != nil {
+			logger.Debugf("Checkout error: %s", err.Error())
+			term.Spinner().Fail()
+			return cli.Exit(color.RedString("Unable to roll back (%s)", err.Error()), 1)
+		}
+		term.Spinner().OK()
+
+		oldVersion := pkg.Version
+		restored, err := installPackageDependencies(c.Context, langManager, dir, "", c.Bool("force"), logger)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to install dependencies of package \"%s\"", pkgName), 1)
+		}
+		saveInstallRecord(c.Context, dir, previous)
+		recordPackageEvent(c.Context, packageEventRollback, dir)
+		pinPackage(c.Context, dir, previous, branch)
+		notifyPackageEvent(c.Context, packageEventRollback, dir, oldVersion, packageVersion(*restored))
+
+		term.Printf("Package %s restored to commit %s.\n", color.BlueString(pkgName), shortHash(previous))
+		term.Printf("It stays on this commit until you run \"%s\".\n", color.BlueString("%s update %s --version %s", tools.Self(), pkgName, packageVersionLatest))
+		return nil
+	}
+}
+
+// splitPackageVersion splits a "package@version" argument of install, returning an empty version if there is none.
+// The @ in user names of repository URLs, such as git@github.com:akamai/cli-property.git, does not start a version.
+func splitPackageVersion(arg string) (string, string) {
+	i := strings.LastIndex(arg, "@")
+	if i <= 0 || strings.ContainsAny(arg[i+1:], "/:") {
+		return arg, ""
+	}
+	return arg[:i], arg[i+1:]
+}
+
+// resolvePackageVersion returns the tag equal to version or, if version is a semantic version range such as ^1.2,
+// the highest tag satisfying it; false is returned if no tag matches
+func resolvePackageVersion(tags []string, version string) (string, bool) {
+	for _, tag := range tags {
+		if tag == version {
+			return tag, true
+		}
+	}
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", false
+	}
+	var latest string
+	var latestVersion *semver.Version
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = tag, v
+		}
+	}
+	return latest, latestVersion != nil
+}
+
+// checkoutPackageVersion checks out the tag or commit matching version, looking tags up on the remote first.
+// A version which is neither a tag nor a range matched by tags is treated as a commit hash.
+func checkoutPackageVersion(ctx context.Context, gitRepo git.Repository, version string) error {
+	tags, err := gitRepo.FetchTags(ctx, git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	if tag, ok := resolvePackageVersion(tags, version); ok {
+		log.FromContext(ctx).Debugf("Checking out tag %s for version %s", tag, version)
+		return gitRepo.CheckoutTag(tag)
+	}
+	if err := gitRepo.CheckoutCommit(ctx, git.DefaultRemoteName, version); err != nil {
+		return fmt.Errorf("no tag or commit matches version %s: %w", version, err)
+	}
+	return nil
+}
+
+// headBranch returns the name of the branch checked out in the repository, empty if the HEAD is detached
+func headBranch(gitRepo git.Repository) string {
+	if head, err := gitRepo.Head(); err == nil && head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+	return ""
+}
+
+// pinPackage stores the version the package in given directory is pinned to, an empty one removes the pin.
+// The branch checked out before, if given, is remembered so that update returns to it once the pin is removed.
+func pinPackage(ctx context.Context, dir, version, branch string) {
+	err := updatePackageStore(ctx, func(store *packageStore) error {
+		store.migrateInstalled(ctx, "")
+		pkg, ok := store.Packages[filepath.Base(dir)]
+		if !ok {
+			return errPackageStoreUnchanged
+		}
+		pkg.Pinned = version
+		if branch != "" {
+			pkg.Branch = branch
+		}
+		return nil
+	})
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to update package store: %s", err)
+	}
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSplitPackageVersion(t *testing.T) {
+	tests := map[string]struct {
+		arg, repo, version string
+	}{
+		"no version":             {arg: "property", repo: "property"},
+		"tag":                    {arg: "property@v1.2.0", repo: "property", version: "v1.2.0"},
+		"range":                  {arg: "akamai/cli-property@^1.2", repo: "akamai/cli-property", version: "^1.2"},
+		"ssh url":                {arg: "git@github.com:akamai/cli-property.git", repo: "git@github.com:akamai/cli-property.git"},
+		"ssh url with version":   {arg: "git@github.com:akamai/cli-property.git@0a1b2c3", repo: "git@github.com:akamai/cli-property.git", version: "0a1b2c3"},
+		"https url with user":    {arg: "https://user@example.com/cli-property.git", repo: "https://user@example.com/cli-property.git"},
+		"https url with version": {arg: "https://example.com/cli-property.git@v1", repo: "https://example.com/cli-property.git", version: "v1"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, version := splitPackageVersion(test.arg)
+			assert.Equal(t, test.repo, repo)
+			assert.Equal(t, test.version, version)
+		})
+	}
+}
+
+func TestResolvePackageVersion(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.2.0", "v1.3.0-beta.1", "1.10.0", "v2.0.0", "latest"}
+	tests := map[string]struct {
+		version  string
+		expected string
+		ok       bool
+	}{
+		"exact tag":               {version: "v1.2.0", expected: "v1.2.0", ok: true},
+		"tag which is no version": {version: "latest", expected: "latest", ok: true},
+		"version without prefix":  {version: "1.0.0", expected: "v1.0.0", ok: true},
+		"caret range":             {version: "^1.2", expected: "1.10.0", ok: true},
+		"tilde range":             {version: "~1.2.0", expected: "v1.2.0", ok: true},
+		"no matching tag":         {version: ">=3.0"},
+		"commit hash":             {version: "0a1b2c3"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, ok := resolvePackageVersion(tags, test.version)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, tag)
+		})
+	}
+}
+
+func TestRecordPreviousCommit(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+	ctx := context.Background()
+	store, err := loadPackageStore(ctx)
+	require.NoError(t, err)
+
+	for _, step := range []struct {
+		event, commit, previous string
+	}{
+		{event: packageEventInstall, commit: "aaa"},
+		{event: packageEventUpdate, commit: "aaa"},
+		{event: packageEventUpdate, commit: "bbb", previous: "aaa"},
+		{event: packageEventRollback, commit: "aaa", previous: "bbb"},
+		{event: packageEventEnable, commit: "aaa", previous: "bbb"},
+	} {
+		saveInstallRecord(ctx, dir, step.commit)
+		require.NoError(t, store.record(step.event, dir, time.Now()))
+		assert.Equal(t, step.previous, store.Packages["cli-dns"].Previous, "after %s of %s", step.event, step.commit)
+	}
+	require.NoError(t, store.record(packageEventUninstall, dir, time.Now()))
+	assert.Empty(t, store.Packages["cli-dns"].Previous)
+}
+
+func TestCmdRollback(t *testing.T) {
+	current, previous := plumbing.Hash{2}.String(), plumbing.Hash{1}.String()
+	tests := map[string]struct {
+		previous       string
+		init           func(*mocked, string)
+		expectedCommit string
+		withError      string
+	}{
+		"restore previous commit": {
+			previous: previous,
+			init: func(m *mocked, dir string) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Restoring package \"%s\" to commit %s...", []interface{}{"cli-dns", "0100000"}).Return().Once()
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, previous).Return(nil).Once()
+				m.term.On("OK").Return().Twice()
+				m.term.On("Start", "Installing...", []interface{}(nil)).Return().Once()
+				m.cfg.On("GetValue", "cli", "install-timeout").Return("", false).Once()
+				m.cfg.On("GetValue", "cli", "build-timeout").Return("", false).Once()
+				m.langManager.On("Install", dir, packages.LanguageRequirements{}, []string{"dns"}).Return(nil).Once()
+				m.cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+				m.term.On("Printf", "Package %s restored to commit %s.\n", []interface{}{color.BlueString("cli-dns"), "0100000"}).Return().Once()
+				m.term.On("Printf", "It stays on this commit until you run \"%s\".\n", mock.Anything).Return().Once()
+			},
+			expectedCommit: previous,
+		},
+		"checkout fails": {
+			previous: previous,
+			init: func(m *mocked, dir string) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Restoring package \"%s\" to commit %s...", []interface{}{"cli-dns", "0100000"}).Return().Once()
+				m.gitRepo.On("Open", dir).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.Hash{2}), nil).Once()
+				m.cfg.On("GetValue", "cli", "clone-timeout").Return("", false).Once()
+				m.gitRepo.On("CheckoutCommit", git.DefaultRemoteName, previous).Return(plumbing.ErrObjectNotFound).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "Unable to roll back (object not found)",
+		},
+		"no previous commit": {
+			init:      func(m *mocked, dir string) {},
+			withError: `No previous version of package "cli-dns" is recorded, it has to be updated first`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			store, err := loadPackageStore(context.Background())
+			require.NoError(t, err)
+			saveInstallRecord(context.Background(), dir, current)
+			require.NoError(t, store.record(packageEventInstall, dir, time.Now()))
+			store.Packages["cli-dns"].Previous = test.previous
+			require.NoError(t, store.save())
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.Mock{}, &packages.Mock{}}
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			app, ctx := setupTestApp(&cli.Command{Name: "rollback", Action: cmdRollback(m.gitRepo, m.langManager)}, m)
+			test.init(m, dir)
+
+			err = app.RunContext(ctx, append(os.Args[0:1], "rollback", "cli-dns"))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			store, err = loadPackageStore(context.Background())
+			require.NoError(t, err)
+			pkg := store.Packages["cli-dns"]
+			assert.Equal(t, test.expectedCommit, pkg.Commit)
+			assert.Equal(t, test.expectedCommit, pkg.Pinned)
+			assert.Equal(t, current, pkg.Previous)
+			assert.Equal(t, "main", pkg.Branch)
+			assert.Equal(t, packageEventRollback, pkg.History[len(pkg.History)-1].Event)
+		})
+	}
+}
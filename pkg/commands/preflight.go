@@ -0,0 +1,112 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// minFreeSpaceKey is the config key holding the free space required before a package is installed or updated,
+// e.g. "1G". "0" disables the check.
+const minFreeSpaceKey = "min-free-space"
+
+// defaultMinFreeSpace is the free space required when cli.min-free-space is not set, enough for
+// the dependencies of typical Python and Node.js packages
+const defaultMinFreeSpace = 200 << 20
+
+// checkPreflight verifies that packages can be written to dir before they are fetched or built there:
+// the directory must be writable by the current user and its file system must have enough free space.
+// Failing early leaves no half-installed package behind.
+func checkPreflight(ctx context.Context, dir string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err == nil {
+		err = checkWritable(dir)
+	}
+	if err != nil {
+		name := "the current user"
+		if u, userErr := user.Current(); userErr == nil {
+			name = u.Username
+		}
+		return newFailure(failureNotWritable, failureData{"Dir": dir, "User": name}, err)
+	}
+
+	required := minFreeSpace(ctx)
+	if required == 0 {
+		return nil
+	}
+	free, err := diskFree(dir)
+	if err != nil {
+		log.FromContext(ctx).Debugf("Unable to check free space in %s: %s", dir, err)
+		return nil
+	}
+	if free < required {
+		return newFailure(failureDiskSpace, failureData{"Dir": dir, "Free": formatSize(free), "Required": formatSize(required), "Key": minFreeSpaceKey}, nil)
+	}
+	return nil
+}
+
+// checkWritable creates and removes a file in dir, which also accounts for ACLs and read-only file systems
+func checkWritable(dir string) error {
+	f, err := ioutil.TempFile(dir, ".akamai-cli-preflight")
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			return pathErr.Err
+		}
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(f.Name())
+}
+
+// minFreeSpace returns the free space required in bytes, the default one if the setting is invalid
+func minFreeSpace(ctx context.Context) uint64 {
+	val, ok := config.Get(ctx).GetValue("cli", minFreeSpaceKey)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return defaultMinFreeSpace
+	}
+	if val == "0" {
+		return 0
+	}
+	size, err := parseSize(val)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", minFreeSpaceKey, err)
+		return defaultMinFreeSpace
+	}
+	return size
+}
+
+// formatSize formats a number of bytes with the largest k, M or G suffix which keeps it at least 1
+func formatSize(size uint64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1fk", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckPreflight(t *testing.T) {
+	tests := map[string]struct {
+		minFree   string
+		dir       func(t *testing.T, root string) string
+		withError string
+	}{
+		"enough space": {
+			minFree: "1k",
+		},
+		"default requirement": {},
+		"check disabled": {
+			minFree: "0",
+		},
+		"directory created": {
+			minFree: "0",
+			dir: func(t *testing.T, root string) string {
+				return filepath.Join(root, "home", "src")
+			},
+		},
+		"not enough space": {
+			minFree:   "1000000000G",
+			withError: "Not enough free space in",
+		},
+		"path is a file": {
+			dir: func(t *testing.T, root string) string {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(root, "src"), nil, 0600))
+				return filepath.Join(root, "src")
+			},
+			withError: "is not writable by",
+		},
+		"not writable": {
+			dir: func(t *testing.T, root string) string {
+				if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+					t.Skip("permissions are not enforced")
+				}
+				dir := filepath.Join(root, "src")
+				require.NoError(t, os.Mkdir(dir, 0500))
+				return dir
+			},
+			withError: "is not writable by",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "cli-preflight")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.Chmod(root, 0700))
+				require.NoError(t, os.RemoveAll(root))
+			}()
+			dir := root
+			if test.dir != nil {
+				dir = test.dir(t, root)
+			}
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", minFreeSpaceKey).Return(test.minFree, test.minFree != "").Maybe()
+			ctx := config.Context(context.Background(), cfg)
+
+			err = checkPreflight(ctx, dir)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, files, "the write check leaves no files behind")
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512B", formatSize(512))
+	assert.Equal(t, "1.5k", formatSize(1536))
+	assert.Equal(t, "200.0M", formatSize(defaultMinFreeSpace))
+	assert.Equal(t, "2.0G", formatSize(2<<30))
+}
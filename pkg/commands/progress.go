@@ -0,0 +1,140 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// progressDrainTimeout is the time given to the progress reader to consume remaining events after the command exits,
+// the pipe may stay open if the command started background processes which inherited it
+const progressDrainTimeout = time.Second
+
+// progressRenderer displays progress events reported by an installed command using the CLI spinner
+type progressRenderer struct {
+	term   terminal.Terminal
+	logger log.Logger
+	phase  string
+}
+
+// startProgress lets the command report its progress through a pipe, passed as an extra file descriptor
+// whose number is set in plugin.ProgressFDEnv. The returned function has to be called once the command exits;
+// it finishes the phase in progress, if any, with a status matching the result of the command.
+// Progress is not reported on Windows, where commands cannot inherit extra file descriptors.
+func startProgress(ctx context.Context, subCmd *exec.Cmd) func(success bool) {
+	logger := log.FromContext(ctx)
+	if runtime.GOOS == "windows" {
+		return func(bool) {}
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		logger.Debugf("Unable to create progress pipe: %s", err)
+		return func(bool) {}
+	}
+	subCmd.ExtraFiles = append(subCmd.ExtraFiles, w)
+	if subCmd.Env == nil {
+		subCmd.Env = os.Environ()
+	}
+	subCmd.Env = append(subCmd.Env, fmt.Sprintf("%s=%d", plugin.ProgressFDEnv, 2+len(subCmd.ExtraFiles)))
+
+	renderer := &progressRenderer{term: terminal.Get(ctx), logger: logger}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		renderer.read(r)
+	}()
+	return func(success bool) {
+		if err := w.Close(); err != nil {
+			logger.Debugf("Unable to close progress pipe: %s", err)
+		}
+		select {
+		case <-done:
+		case <-time.After(progressDrainTimeout):
+		}
+		_ = r.Close()
+		<-done
+		renderer.finish(success)
+	}
+}
+
+// read handles progress events until the pipe is closed, invalid lines are ignored
+func (p *progressRenderer) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var event plugin.ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Phase == "" {
+			p.logger.Debugf("Ignoring invalid progress event: %s", scanner.Text())
+			continue
+		}
+		p.handle(event)
+	}
+}
+
+func (p *progressRenderer) handle(event plugin.ProgressEvent) {
+	p.logger.Debugf("Progress: %s %d%% %s", event.Phase, event.Percent, event.Status)
+	if !p.term.IsTTY() {
+		return
+	}
+	if event.Phase != p.phase {
+		p.stop(plugin.ProgressOK)
+		p.phase = event.Phase
+		p.term.Spinner().Start("%s", event.Phase)
+	}
+	if event.Status != "" {
+		p.stop(event.Status)
+		return
+	}
+	if event.Percent >= 0 {
+		if _, err := p.term.Spinner().Write([]byte(fmt.Sprintf("%d%%", event.Percent))); err != nil {
+			p.logger.Debugf("Unable to display progress: %s", err)
+		}
+	}
+}
+
+// finish stops the spinner of an unfinished phase once the command exited
+func (p *progressRenderer) finish(success bool) {
+	if success {
+		p.stop(plugin.ProgressOK)
+	} else {
+		p.stop(plugin.ProgressFail)
+	}
+}
+
+func (p *progressRenderer) stop(status string) {
+	if p.phase == "" {
+		return
+	}
+	p.phase = ""
+	switch status {
+	case plugin.ProgressWarn:
+		p.term.Spinner().Warn()
+	case plugin.ProgressFail:
+		p.term.Spinner().Fail()
+	default:
+		p.term.Spinner().OK()
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/terminal"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestStartProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("progress is not reported on Windows")
+	}
+	tests := map[string]struct {
+		script string
+		tty    bool
+		init   func(*terminal.Mock)
+	}{
+		"phases with percentages": {
+			script: `echo '{"phase":"Uploading","percent":50}' >&$AKAMAI_CLI_PROGRESS_FD
+echo 'not json' >&$AKAMAI_CLI_PROGRESS_FD
+echo '{"phase":"Uploading","percent":100,"status":"warn"}' >&$AKAMAI_CLI_PROGRESS_FD
+echo '{"phase":"Activating 100%","percent":-1}' >&$AKAMAI_CLI_PROGRESS_FD`,
+			tty: true,
+			init: func(m *terminal.Mock) {
+				m.On("Start", "%s", []interface{}{"Uploading"}).Return().Once()
+				m.On("Write", []byte("50%")).Return(3, nil).Once()
+				m.On("Warn").Return().Once()
+				m.On("Start", "%s", []interface{}{"Activating 100%"}).Return().Once()
+				m.On("OK").Return().Once()
+			},
+		},
+		"unfinished phase of failed command": {
+			script: `echo '{"phase":"Uploading","percent":10}' >&$AKAMAI_CLI_PROGRESS_FD; exit 1`,
+			tty:    true,
+			init: func(m *terminal.Mock) {
+				m.On("Start", "%s", []interface{}{"Uploading"}).Return().Once()
+				m.On("Write", []byte("10%")).Return(3, nil).Once()
+				m.On("Fail").Return().Once()
+			},
+		},
+		"not a terminal": {
+			script: `echo '{"phase":"Uploading","percent":10}' >&$AKAMAI_CLI_PROGRESS_FD`,
+			init:   func(m *terminal.Mock) {},
+		},
+		"no progress": {
+			script: `test -n "$AKAMAI_CLI_PROGRESS_FD"`,
+			tty:    true,
+			init:   func(m *terminal.Mock) {},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &terminal.Mock{}
+			m.On("IsTTY").Return(test.tty).Maybe()
+			m.On("Spinner").Return(m).Maybe()
+			test.init(m)
+
+			subCmd := exec.Command("sh", "-c", test.script)
+			finish := startProgress(terminal.Context(context.Background(), m), subCmd)
+			err := subCmd.Run()
+			finish(err == nil)
+
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// resourceLimitsSection is the config section holding resource limits of package subprocesses, keyed by package name,
+// e.g. "cli-dns = memory=512M cpu=5m files=256". Limits under the "default" key apply to packages without their own entry.
+const resourceLimitsSection = "limits"
+
+// defaultLimitsKey is the key of resource limits applied to packages without an entry of their own
+const defaultLimitsKey = "default"
+
+// errLimitsUnsupported is returned when resource limits cannot be enforced on the current platform
+var errLimitsUnsupported = errors.New("resource limits are not supported on this platform")
+
+// resourceLimits are the limits applied to the process of a package command, zero values mean no limit
+type resourceLimits struct {
+	// Memory is the maximum memory in bytes, address space on Linux and committed memory on Windows
+	Memory uint64
+	// CPU is the maximum processor time
+	CPU time.Duration
+	// Files is the maximum number of open file descriptors, not enforced on Windows
+	Files uint64
+}
+
+func (l resourceLimits) empty() bool {
+	return l == resourceLimits{}
+}
+
+// parseResourceLimits parses limits in "memory=512M cpu=5m files=256" format, separated with spaces or commas.
+// Memory takes an optional k, M or G suffix, multiples of 1024, and cpu is a duration.
+func parseResourceLimits(val string) (resourceLimits, error) {
+	var limits resourceLimits
+	fields := strings.FieldsFunc(val, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return limits, fmt.Errorf("expected a name=value limit, got %q", field)
+		}
+		name, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch name {
+		case "memory":
+			size, err := parseSize(value)
+			if err != nil {
+				return limits, fmt.Errorf("memory: %w", err)
+			}
+			limits.Memory = size
+		case "cpu":
+			cpu, err := time.ParseDuration(value)
+			if err != nil || cpu < time.Second {
+				return limits, fmt.Errorf("cpu: expected a duration of at least 1s such as 90s or 10m, got %q", value)
+			}
+			limits.CPU = cpu
+		case "files":
+			files, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || files == 0 {
+				return limits, fmt.Errorf("files: expected a positive number, got %q", value)
+			}
+			limits.Files = files
+		default:
+			return limits, fmt.Errorf("unknown limit %q, expected memory, cpu or files", name)
+		}
+	}
+	return limits, nil
+}
+
+// parseSize parses a number of bytes with an optional k, M or G suffix, multiples of 1024
+func parseSize(s string) (uint64, error) {
+	val := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	multiplier := uint64(1)
+	switch {
+	case strings.HasSuffix(val, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(val, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(val, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		val = val[:len(val)-1]
+	}
+	num, err := strconv.ParseUint(strings.TrimSpace(val), 10, 64)
+	if err != nil || num == 0 {
+		return 0, fmt.Errorf("expected a size such as 512M or 2G, got %q", s)
+	}
+	return num * multiplier, nil
+}
+
+// packageResourceLimits returns the limits configured for given package, falling back to the default limits.
+// Invalid limits are reported and ignored.
+func packageResourceLimits(ctx context.Context, pkgName string) resourceLimits {
+	cfg := config.Get(ctx)
+	key := pkgName
+	val, ok := cfg.GetValue(resourceLimitsSection, key)
+	if !ok || strings.TrimSpace(val) == "" {
+		key = defaultLimitsKey
+		val, _ = cfg.GetValue(resourceLimitsSection, key)
+	}
+	limits, err := parseResourceLimits(val)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid value of %s.%s: %s", resourceLimitsSection, key, err)
+		return resourceLimits{}
+	}
+	return limits
+}
+
+// limitedStart returns a function starting cmd with given limits applied to its process, and a function releasing
+// what is held to enforce them once the process exits. The process is killed if the limits cannot be applied,
+// except on platforms which do not support them, where a warning is printed and the process runs without limits.
+func limitedStart(ctx context.Context, cmd *exec.Cmd, limits resourceLimits) (func() error, func()) {
+	release := func() {}
+	if limits.empty() {
+		return cmd.Start, release
+	}
+	start := func() error {
+		if err := limitExec(cmd, limits); err != nil {
+			return fmt.Errorf("unable to apply resource limits: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		var err error
+		release, err = limitProcess(cmd.Process.Pid, limits)
+		if errors.Is(err, errLimitsUnsupported) {
+			log.FromContext(ctx).Warnf("Running %s without resource limits: %s", cmd.Path, err)
+			release = func() {}
+			return nil
+		}
+		if err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return fmt.Errorf("unable to apply resource limits: %w", err)
+		}
+		log.FromContext(ctx).Debugf("Applied resource limits to process %d: %+v", cmd.Process.Pid, limits)
+		return nil
+	}
+	return start, func() { release() }
+}
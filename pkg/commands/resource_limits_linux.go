@@ -0,0 +1,121 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// cpuGrace is the processor time a process gets after SIGXCPU at the soft cpu limit, before it is killed
+const cpuGrace = 5
+
+const (
+	// limitsShimEnv holds the limits which the shim applies before executing the command, in parseResourceLimits format
+	limitsShimEnv = "AKAMAI_CLI_RESOURCE_LIMITS"
+	// limitsShimExecEnv holds the path of the command executed by the shim
+	limitsShimExecEnv = "AKAMAI_CLI_RESOURCE_LIMITS_EXEC"
+)
+
+// init runs the shim when Akamai CLI is started by limitExec: the limits are set on the process itself,
+// which then executes the command, so that the command never runs without them.
+func init() {
+	val, ok := os.LookupEnv(limitsShimEnv)
+	if !ok {
+		return
+	}
+	path := os.Getenv(limitsShimExecEnv)
+	if err := runLimitsShim(val, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to apply resource limits to %s: %s\n", path, err)
+		os.Exit(126)
+	}
+}
+
+func runLimitsShim(val, path string) error {
+	limits, err := parseResourceLimits(val)
+	if err != nil {
+		return err
+	}
+	if limits.Memory > 0 {
+		if err := setrlimit(syscall.RLIMIT_AS, limits.Memory, limits.Memory); err != nil {
+			return fmt.Errorf("memory: %w", err)
+		}
+	}
+	if limits.CPU > 0 {
+		seconds := uint64(limits.CPU.Seconds())
+		if err := setrlimit(syscall.RLIMIT_CPU, seconds, seconds+cpuGrace); err != nil {
+			return fmt.Errorf("cpu: %w", err)
+		}
+	}
+	if limits.Files > 0 {
+		if err := setrlimit(syscall.RLIMIT_NOFILE, limits.Files, limits.Files); err != nil {
+			return fmt.Errorf("files: %w", err)
+		}
+	}
+	env := make([]string, 0, len(os.Environ()))
+	for _, entry := range os.Environ() {
+		if !strings.HasPrefix(entry, limitsShimEnv+"=") && !strings.HasPrefix(entry, limitsShimExecEnv+"=") {
+			env = append(env, entry)
+		}
+	}
+	return syscall.Exec(path, os.Args, env)
+}
+
+// limitExec makes cmd start Akamai CLI as a shim, which lowers its own rlimits and then executes the command in
+// the same process, so that the limits apply from its first instruction and to the processes it starts.
+// Limits above the current hard limit are capped to it, as raising them requires privileges.
+func limitExec(cmd *exec.Cmd, limits resourceLimits) error {
+	// the running executable, even if it was replaced by an upgrade in the meantime
+	const self = "/proc/self/exe"
+	if _, err := os.Stat(self); err != nil {
+		return err
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	val := fmt.Sprintf("memory=%d cpu=%s files=%d", limits.Memory, limits.CPU, limits.Files)
+	values := make([]string, 0, 3)
+	for _, field := range strings.Fields(val) {
+		if !strings.HasSuffix(field, "=0") && !strings.HasSuffix(field, "=0s") {
+			values = append(values, field)
+		}
+	}
+	cmd.Env = append(env, limitsShimEnv+"="+strings.Join(values, " "), limitsShimExecEnv+"="+cmd.Path)
+	cmd.Path = self
+	return nil
+}
+
+// limitProcess has nothing to do, the limits were set by the shim before the command was executed
+func limitProcess(int, resourceLimits) (func(), error) {
+	return func() {}, nil
+}
+
+func setrlimit(resource int, soft, hard uint64) error {
+	var current syscall.Rlimit
+	if err := syscall.Getrlimit(resource, &current); err != nil {
+		return err
+	}
+	if hard > current.Max {
+		hard = current.Max
+	}
+	if soft > hard {
+		soft = hard
+	}
+	return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: soft, Max: hard})
+}
@@ -0,0 +1,29 @@
+// +build !linux,!windows
+
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import "os/exec"
+
+// limitProcess is not supported on this platform, which only allows processes to lower their own limits
+func limitProcess(int, resourceLimits) (func(), error) {
+	return nil, errLimitsUnsupported
+}
+
+// limitExec has nothing to do, limits are not supported on this platform
+func limitExec(*exec.Cmd, resourceLimits) error {
+	return nil
+}
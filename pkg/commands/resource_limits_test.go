@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseResourceLimits(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  resourceLimits
+		withError string
+	}{
+		"all limits":         {value: "memory=512M cpu=5m files=256", expected: resourceLimits{Memory: 512 << 20, CPU: 5 * time.Minute, Files: 256}},
+		"comma separated":    {value: "memory=2GB,files=64", expected: resourceLimits{Memory: 2 << 30, Files: 64}},
+		"memory in bytes":    {value: "Memory=1048576", expected: resourceLimits{Memory: 1 << 20}},
+		"empty":              {value: " "},
+		"invalid memory":     {value: "memory=lots", withError: `memory: expected a size such as 512M or 2G, got "lots"`},
+		"cpu below a second": {value: "cpu=500ms", withError: `cpu: expected a duration of at least 1s`},
+		"no files":           {value: "files=0", withError: `files: expected a positive number, got "0"`},
+		"unknown limit":      {value: "threads=4", withError: `unknown limit "threads"`},
+		"missing value":      {value: "memory", withError: `expected a name=value limit, got "memory"`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limits, err := parseResourceLimits(test.value)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, limits)
+		})
+	}
+}
+
+func TestPackageResourceLimits(t *testing.T) {
+	tests := map[string]struct {
+		values   map[string]string
+		expected resourceLimits
+	}{
+		"package limits":             {values: map[string]string{"cli-dns": "memory=512M", "default": "memory=1G"}, expected: resourceLimits{Memory: 512 << 20}},
+		"default limits":             {values: map[string]string{"default": "files=128"}, expected: resourceLimits{Files: 128}},
+		"no limits":                  {},
+		"invalid limits are ignored": {values: map[string]string{"cli-dns": "memory=-1"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Mock{}
+			for _, key := range []string{"cli-dns", defaultLimitsKey} {
+				val, ok := test.values[key]
+				cfg.On("GetValue", resourceLimitsSection, key).Return(val, ok).Maybe()
+			}
+			ctx := log.SetupContext(config.Context(context.Background(), cfg), &bytes.Buffer{})
+			assert.Equal(t, test.expected, packageResourceLimits(ctx, "cli-dns"))
+		})
+	}
+}
+
+func TestLimitedStart(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("requires prlimit")
+	}
+	stdout := &bytes.Buffer{}
+	subCmd := exec.Command("sh", "-c", "ulimit -n; ulimit -t; echo $AKAMAI_CLI_RESOURCE_LIMITS")
+	subCmd.Stdout = stdout
+	start, release := limitedStart(log.SetupContext(context.Background(), &bytes.Buffer{}), subCmd, resourceLimits{CPU: time.Minute, Files: 64})
+	defer release()
+	require.NoError(t, start())
+	require.NoError(t, subCmd.Wait())
+	assert.Equal(t, "64\n60\n\n", stdout.String())
+}
@@ -0,0 +1,70 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os/exec"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// limitProcess assigns a started process to a job object limiting its memory and processor time, which also
+// applies to the processes it starts. The number of open files cannot be limited on Windows and is ignored.
+// The returned function closes the job object.
+func limitProcess(pid int, limits resourceLimits) (func(), error) {
+	if limits.Memory == 0 && limits.CPU == 0 {
+		return nil, fmt.Errorf("%w: files", errLimitsUnsupported)
+	}
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	release := func() {
+		_ = windows.CloseHandle(job)
+	}
+	var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	if limits.Memory > 0 {
+		info.BasicLimitInformation.LimitFlags |= windows.JOB_OBJECT_LIMIT_PROCESS_MEMORY
+		info.ProcessMemoryLimit = uintptr(limits.Memory)
+	}
+	if limits.CPU > 0 {
+		info.BasicLimitInformation.LimitFlags |= windows.JOB_OBJECT_LIMIT_PROCESS_TIME
+		// the limit is expressed in 100-nanosecond ticks
+		info.BasicLimitInformation.PerProcessUserTimeLimit = int64(limits.CPU / 100)
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		release()
+		return nil, err
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	defer windows.CloseHandle(process)
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// limitExec has nothing to do before the process starts, it is assigned to a job object once started
+func limitExec(*exec.Cmd, resourceLimits) error {
+	return nil
+}
@@ -0,0 +1,266 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// selftestKey is the config key disabling self-tests after install and update when set to "false"
+	selftestKey = "selftest"
+
+	defaultSelftestTimeout = 30 * time.Second
+	// selftestOutputLines is the number of last output lines of a failed self-test which are reported
+	selftestOutputLines = 10
+)
+
+// self-test statuses
+const (
+	selftestStatusPassed = "passed"
+	selftestStatusFailed = "failed"
+)
+
+// selftestResult is the outcome of running the self-test of a command
+type selftestResult struct {
+	Package  string   `json:"package"`
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Duration elapsed  `json:"durationMs"`
+}
+
+func cmdSelftest(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		start := time.Now()
+		logger := log.WithCommand(c.Context, c.Command.Name)
+		logger.Debug("SELFTEST START")
+		defer func() {
+			if e == nil {
+				logger.Debugf("SELFTEST FINISH: %v", time.Now().Sub(start))
+			} else {
+				logger.Errorf("SELFTEST ERROR: %v", e.Error())
+			}
+		}()
+		asJSON := jsonOutput(c)
+		printResults := jsonResults(c)
+
+		dirs := make([]string, 0)
+		if c.Args().Present() {
+			for _, name := range c.Args().Slice() {
+				dir := installedPackageDir(c, langManager, name)
+				if dir == "" {
+					return packageNotFound(name)
+				}
+				dirs = append(dirs, dir)
+			}
+		} else {
+			for _, dir := range getPackagePaths() {
+				if fileExists(filepath.Join(dir, "cli.json")) {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+
+		results := make([]selftestResult, 0)
+		for _, dir := range dirs {
+			pkg, err := readPackage(dir)
+			if err != nil {
+				return cli.Exit(color.RedString("Unable to read package %s: %s", filepath.Base(dir), err), 1)
+			}
+			results = append(results, runPackageSelftests(c.Context, langManager, dir, pkg)...)
+		}
+
+		var err error
+		if asJSON {
+			err = printResults(results)
+		} else if len(results) == 0 {
+			terminal.Get(c.Context).Writeln("No installed package declares a self-test.")
+		} else {
+			err = printSelftestSummary(terminal.Get(c.Context), results)
+		}
+		if err != nil {
+			return err
+		}
+
+		var failed []string
+		for _, res := range results {
+			if res.Status == selftestStatusFailed {
+				failed = append(failed, res.Command)
+			}
+		}
+		if len(failed) > 0 {
+			return cli.Exit(color.RedString("Self-test failed for: %s", strings.Join(failed, ", ")), 1)
+		}
+		return nil
+	}
+}
+
+// runPackageSelftests runs the self-tests declared by commands of the package in given directory
+func runPackageSelftests(ctx context.Context, langManager packages.LangManager, dir string, pkg subcommands) []selftestResult {
+	results := make([]selftestResult, 0)
+	for _, cmd := range pkg.Commands {
+		if len(cmd.Selftest) > 0 {
+			results = append(results, runSelftest(ctx, langManager, filepath.Base(dir), cmd))
+		}
+	}
+	return results
+}
+
+// runSelftest runs the command with its self-test arguments and reports whether it exited successfully.
+// The command runs in an empty temporary directory, without input, with AKAMAI_CLI_SELFTEST set and within the self-test time limit.
+func runSelftest(ctx context.Context, langManager packages.LangManager, pkgName string, cmd command) (res selftestResult) {
+	start := time.Now()
+	res = selftestResult{Package: pkgName, Command: cmd.Name, Args: cmd.Selftest, Status: selftestStatusFailed}
+	defer func() {
+		res.Duration = elapsed(since(start))
+		log.FromContext(ctx).Debugf("Self-test of %s %s: %s", cmd.Name, strings.Join(cmd.Selftest, " "), res.Status)
+	}()
+
+	dir, err := ioutil.TempDir("", "akamai-selftest")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.FromContext(ctx).Debugf("Unable to remove self-test directory: %s", err)
+		}
+	}()
+	d, err := resolvePackageCommand(ctx, langManager, pkgName+namespaceSeparator+cmd.Name, cmd.Selftest, dir)
+	if err != nil {
+		res.Error = fmt.Sprintf("executable of command %s not found", cmd.Name)
+		return res
+	}
+
+	timeout := selftestTimeout(ctx)
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	subCmd := exec.CommandContext(testCtx, d.Args[0], d.Args[1:]...)
+	subCmd.Dir = dir
+	subCmd.Env = append(os.Environ(), "AKAMAI_CLI_SELFTEST=1")
+	for key, val := range d.Env {
+		subCmd.Env = append(subCmd.Env, key+"="+val)
+	}
+	output := newCaptureBuffer(ctx)
+	subCmd.Stdout = output
+	subCmd.Stderr = output
+	err = subCmd.Run()
+	switch {
+	case errors.Is(testCtx.Err(), context.DeadlineExceeded):
+		res.Error = fmt.Sprintf("did not finish within %s", timeout)
+	case err != nil:
+		res.Error = err.Error()
+	default:
+		res.Status = selftestStatusPassed
+		return res
+	}
+	res.Output = lastLines(output.String(), selftestOutputLines)
+	return res
+}
+
+// runInstallSelftests runs self-tests of a package which was just installed or updated, unless they are disabled in config,
+// and warns about the failed ones. It returns the overall status, empty if the package declares no self-test.
+func runInstallSelftests(ctx context.Context, langManager packages.LangManager, dir string, pkg subcommands) string {
+	if !declaresSelftest(pkg) {
+		return ""
+	}
+	if val, ok := config.Get(ctx).GetValue("cli", selftestKey); ok && strings.TrimSpace(val) == "false" {
+		return ""
+	}
+	term := terminal.Get(ctx)
+	results := runPackageSelftests(ctx, langManager, dir, pkg)
+	status := selftestStatusPassed
+	for _, res := range results {
+		if res.Status == selftestStatusPassed {
+			term.Writeln(color.GreenString("Self-test of %s passed", res.Command))
+			continue
+		}
+		status = selftestStatusFailed
+		warnMsg := fmt.Sprintf("Warning: self-test of %s failed, %s", res.Command, res.Error)
+		log.FromContext(ctx).Warn(warnMsg)
+		term.Writeln(color.YellowString(warnMsg))
+		if res.Output != "" {
+			term.Writeln(res.Output)
+		}
+	}
+	return status
+}
+
+func declaresSelftest(pkg subcommands) bool {
+	for _, cmd := range pkg.Commands {
+		if len(cmd.Selftest) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func selftestTimeout(ctx context.Context) time.Duration {
+	if timeout := stepTimeout(ctx, selftestTimeoutKey); timeout > 0 {
+		return timeout
+	}
+	return defaultSelftestTimeout
+}
+
+// lastLines returns up to n last lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func printSelftestSummary(term terminal.Terminal, results []selftestResult) error {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tCOMMAND\tTIME\tSTATUS")
+	for _, res := range results {
+		status := res.Status
+		if res.Error != "" {
+			status = fmt.Sprintf("%s: %s", res.Status, res.Error)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", res.Package, strings.Join(append([]string{res.Command}, res.Args...), " "), res.Duration, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("\nSelf-test Summary:\n"))
+	term.Printf("%s", buf.String())
+	return nil
+}
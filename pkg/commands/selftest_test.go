@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeSelftestPackage installs a package with the dns command, implemented by given shell script, in the package store of cliHome
+func writeSelftestPackage(t *testing.T, cliHome, script string, selftest []string) string {
+	cliJSON, err := json.Marshal(map[string]interface{}{"commands": []interface{}{
+		map[string]interface{}{"name": "dns", "version": "1.0.0", "selftest": selftest},
+	}})
+	require.NoError(t, err)
+	dir := writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", string(cliJSON))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "akamai-dns"), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return dir
+}
+
+func TestCmdSelftest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := map[string]struct {
+		script    string
+		selftest  []string
+		timeout   string
+		args      []string
+		expected  []string
+		withError string
+	}{
+		"self-test passes": {
+			script:   `[ "$1" = "--version" ] && [ "$AKAMAI_CLI_SELFTEST" = "1" ] && [ -z "$(ls)" ] || exit 3`,
+			selftest: []string{"--version"},
+			args:     []string{"cli-dns"},
+			expected: []string{"  PACKAGE  COMMAND        TIME", "  cli-dns  dns --version  ", "  passed\n"},
+		},
+		"self-test fails": {
+			script:    "echo broken; exit 2",
+			selftest:  []string{"--version"},
+			expected:  []string{"  cli-dns  dns --version  ", "  failed: exit status 2\n"},
+			withError: "Self-test failed for: dns",
+		},
+		"self-test times out": {
+			script:    "exec sleep 5",
+			selftest:  []string{"--version"},
+			timeout:   "100ms",
+			expected:  []string{"  failed: did not finish within 100ms\n"},
+			withError: "Self-test failed for: dns",
+		},
+		"no self-test declared": {
+			script: "exit 3",
+		},
+		"package not found": {
+			args:      []string{"cli-missing"},
+			withError: `Package "cli-missing" not found.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			writeSelftestPackage(t, cliHome, test.script, test.selftest)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, &packages.Mock{}}
+			m.cfg.On("GetValue", "cli", "selftest-timeout").Return(test.timeout, test.timeout != "").Maybe()
+			m.cfg.On("GetValue", "cli", "max-capture-size").Return("", false).Maybe()
+			m.langManager.On("FindExec", mock.Anything, mock.Anything, mock.Anything).Return(nil, packages.ErrRuntimeNotFound).Maybe()
+			switch {
+			case len(test.expected) > 0:
+				m.term.On("Writeln", []interface{}{color.YellowString("\nSelf-test Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "%s", mock.MatchedBy(func(args []interface{}) bool {
+					for _, expected := range test.expected {
+						if !strings.Contains(args[0].(string), expected) {
+							return false
+						}
+					}
+					return true
+				})).Return().Once()
+			case test.withError == "":
+				m.term.On("Writeln", []interface{}{"No installed package declares a self-test."}).Return(0, nil).Once()
+			}
+			app, ctx := setupTestApp(&cli.Command{Name: "selftest", Action: cmdSelftest(m.langManager)}, m)
+
+			err := app.RunContext(ctx, append(append(os.Args[0:1], "selftest"), test.args...))
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunInstallSelftests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := map[string]struct {
+		script   string
+		selftest []string
+		disabled bool
+		init     func(*terminal.Mock)
+		expected string
+	}{
+		"passed": {
+			script:   "exit 0",
+			selftest: []string{"--version"},
+			init: func(term *terminal.Mock) {
+				term.On("Writeln", []interface{}{color.GreenString("Self-test of dns passed")}).Return(0, nil).Once()
+			},
+			expected: selftestStatusPassed,
+		},
+		"failed with output": {
+			script:   "echo one; echo two; exit 1",
+			selftest: []string{"--version"},
+			init: func(term *terminal.Mock) {
+				term.On("Writeln", []interface{}{color.YellowString("Warning: self-test of dns failed, exit status 1")}).Return(0, nil).Once()
+				term.On("Writeln", []interface{}{"one\ntwo"}).Return(0, nil).Once()
+			},
+			expected: selftestStatusFailed,
+		},
+		"disabled in config": {
+			script:   "exit 1",
+			selftest: []string{"--version"},
+			disabled: true,
+		},
+		"nothing declared": {
+			script: "exit 1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			dir := writeSelftestPackage(t, cliHome, test.script, test.selftest)
+			pkg, err := readPackage(dir)
+			require.NoError(t, err)
+
+			term, cfg, langManager := &terminal.Mock{}, &config.Mock{}, &packages.Mock{}
+			if len(test.selftest) > 0 {
+				if test.disabled {
+					cfg.On("GetValue", "cli", "selftest").Return("false", true).Once()
+				} else {
+					cfg.On("GetValue", "cli", "selftest").Return("", false).Once()
+					cfg.On("GetValue", "cli", "selftest-timeout").Return("", false).Once()
+					cfg.On("GetValue", "cli", "max-capture-size").Return("", false).Maybe()
+				}
+			}
+			if test.init != nil {
+				test.init(term)
+			}
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+			assert.Equal(t, test.expected, runInstallSelftests(ctx, langManager, dir, pkg))
+			cfg.AssertExpectations(t)
+			term.AssertExpectations(t)
+		})
+	}
+}
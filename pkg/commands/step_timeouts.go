@@ -0,0 +1,71 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+)
+
+// config keys holding step time limits, values are durations such as "90s" or "10m"
+const (
+	cloneTimeoutKey   = "clone-timeout"
+	installTimeoutKey = "install-timeout"
+	buildTimeoutKey   = "build-timeout"
+	// selftestTimeoutKey limits a single package self-test, 30s by default
+	selftestTimeoutKey = "selftest-timeout"
+	// hookTimeoutKey limits a single package hook, 5m by default
+	hookTimeoutKey = "hook-timeout"
+)
+
+// minStepTimeout is the shortest duration accepted by stepTimeout, shorter ones are typos such as 10ns for 10m
+// and cannot be measured by the tickers of heartbeats
+const minStepTimeout = time.Millisecond
+
+// stepTimeout returns the duration configured under given key, or 0 if no limit is set
+func stepTimeout(ctx context.Context, key string) time.Duration {
+	val, ok := config.Get(ctx).GetValue("cli", key)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(val)
+	if err != nil || timeout < 0 || timeout > 0 && timeout < minStepTimeout {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s, expected a duration of at least %s such as 10m", key, val, minStepTimeout)
+		return 0
+	}
+	return timeout
+}
+
+// cloneContext returns a context limiting the time of fetching package repository
+func cloneContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := stepTimeout(ctx, cloneTimeoutKey); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// withInstallTimeouts sets time limits of dependency installation and build steps in the context
+func withInstallTimeouts(ctx context.Context) context.Context {
+	return packages.WithStepTimeouts(ctx, packages.StepTimeouts{
+		Install: stepTimeout(ctx, installTimeoutKey),
+		Build:   stepTimeout(ctx, buildTimeoutKey),
+	})
+}
@@ -20,15 +20,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/akamai/cli/pkg/packages"
-	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"text/template"
 
+	"github.com/akamai/cli/pkg/download"
 	"github.com/akamai/cli/pkg/log"
 	"github.com/urfave/cli/v2"
 
@@ -38,7 +37,12 @@ import (
 type subcommands struct {
 	Commands     []command                     `json:"commands"`
 	Requirements packages.LanguageRequirements `json:"requirements"`
-	Action       cli.ActionFunc                `json:"-"`
+	Dependencies []string                      `json:"dependencies,omitempty"`
+	Config       map[string]string             `json:"config,omitempty"`
+	Hooks        map[string]packageHook        `json:"hooks,omitempty"`
+	// Hosts are the hosts the commands connect to, such as "*.luna.akamaiapis.net", which the network policy allows
+	Hosts  []string       `json:"hosts,omitempty"`
+	Action cli.ActionFunc `json:"-"`
 }
 
 func readPackage(dir string) (subcommands, error) {
@@ -67,18 +71,37 @@ func readPackage(dir string) (subcommands, error) {
 	return packageData, nil
 }
 
+// getPackagePaths returns directories of installed packages, except disabled ones
 func getPackagePaths() []string {
 	akamaiCliPath, err := tools.GetAkamaiCliSrcPath()
 	if err == nil && akamaiCliPath != "" {
 		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
 		if len(paths) > 0 {
-			return paths
+			return enabledPackagePaths(paths)
 		}
 	}
 
 	return []string{}
 }
 
+// enabledPackagePaths removes paths inside disabled package directories from the list
+func enabledPackagePaths(paths []string) []string {
+	disabled := disabledPackages()
+	if len(disabled) == 0 {
+		return paths
+	}
+	srcPath, _ := tools.GetAkamaiCliSrcPath()
+	enabled := make([]string, 0, len(paths))
+	for _, path := range paths {
+		rel, err := filepath.Rel(srcPath, path)
+		if err == nil && disabled[strings.Split(filepath.ToSlash(rel), "/")[0]] {
+			continue
+		}
+		enabled = append(enabled, path)
+	}
+	return enabled
+}
+
 func findPackageDir(dir string) string {
 	if stat, err := os.Stat(dir); err == nil && stat != nil && !stat.IsDir() {
 		dir = filepath.Dir(dir)
@@ -97,8 +120,8 @@ func findPackageDir(dir string) string {
 	return dir
 }
 
-func downloadBin(ctx context.Context, dir string, cmd command) error {
-	logger := log.FromContext(ctx)
+// withPlatform sets the fields of cmd describing the current platform, which the bin URL template can refer to
+func withPlatform(cmd command) command {
 	cmd.Arch = runtime.GOARCH
 
 	cmd.OS = runtime.GOOS
@@ -109,6 +132,12 @@ func downloadBin(ctx context.Context, dir string, cmd command) error {
 	if runtime.GOOS == "windows" {
 		cmd.BinSuffix = ".exe"
 	}
+	return cmd
+}
+
+func downloadBin(ctx context.Context, dir string, cmd command) error {
+	logger := log.FromContext(ctx)
+	cmd = withPlatform(cmd)
 
 	t := template.Must(template.New("url").Parse(cmd.Bin))
 	buf := &bytes.Buffer{}
@@ -120,39 +149,48 @@ func downloadBin(ctx context.Context, dir string, cmd command) error {
 	url := buf.String()
 	logger.Debugf("Fetching binary from %s", url)
 
+	platform := binaryPlatform(cmd)
+	opts := download.Options{Limiter: download.ConfiguredLimiter(ctx)}
+	verify := !skipVerify(ctx)
+	if verify {
+		sum, err := binaryChecksum(cmd, platform)
+		if err != nil {
+			return err
+		}
+		if sum == nil && cmd.Signature == nil {
+			logger.Warnf("Package does not publish checksums or signatures of the %s binary, it is not verified", cmd.Name)
+		}
+		opts.Checksum = sum
+	} else {
+		logger.Warnf("Skipping verification of the %s binary", cmd.Name)
+	}
+
 	binName := filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+cmd.BinSuffix)
-	bin, err := os.Create(binName)
-	if err != nil {
-		return err
+	if err := download.File(ctx, url, binName, opts); err != nil {
+		return fmt.Errorf("unable to fetch command binary: %w", err)
 	}
-	defer func() {
-		if err := bin.Close(); err != nil {
-			logger.Errorf("Error closing file: %s", err)
-		}
-	}()
 
+	packageDir := filepath.Dir(dir)
+	binary := downloadedBinary{URL: url, Platform: platform, Verified: verify && opts.Checksum != nil}
+	if verify && cmd.Signature != nil {
+		if err := verifyBinarySignature(ctx, packageDir, *cmd.Signature, cmd, url, binName); err != nil {
+			if rmErr := os.Remove(binName); rmErr != nil {
+				logger.Errorf("Unable to remove binary: %s", rmErr)
+			}
+			return fmt.Errorf("unable to verify signature of command binary: %w", err)
+		}
+		binary.Verified, binary.Signature = true, cmd.Signature.Type
+	}
 	if err := os.Chmod(binName, 0775); err != nil {
 		return err
 	}
 
-	res, err := http.Get(url)
+	hash, err := fileHash(binName)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			logger.Errorf("Error closing request body: %s", err)
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("invalid response status while fetching command binary: %d", res.StatusCode)
-	}
-
-	n, err := io.Copy(bin, res.Body)
-	if err != nil || n == 0 {
-		return err
-	}
-
+	binary.SHA256 = hash
+	binary.Path = filepath.ToSlash(filepath.Join(filepath.Base(dir), filepath.Base(binName)))
+	recordDownloadedBinary(ctx, packageDir, strings.ToLower(cmd.Name), binary)
 	return nil
 }
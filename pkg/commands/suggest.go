@@ -0,0 +1,138 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/pkg/stats"
+
+	"github.com/urfave/cli/v2"
+)
+
+// maxSuggestions is the number of commands suggested in place of an unknown one
+const maxSuggestions = 3
+
+// RankCommandsByUsage orders commands by how often the user ran them, according to the local statistics history,
+// so that the most used commands are listed first in help and completions. Commands never run stay in alphabetical order.
+func RankCommandsByUsage(ctx context.Context, commands []*cli.Command) {
+	usage := stats.CommandUsage(ctx)
+	if len(usage) == 0 {
+		return
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commandUsage(usage, commands[i]) > commandUsage(usage, commands[j])
+	})
+}
+
+// SuggestCommands returns the names of visible commands similar to given unknown name, the most used ones first
+func SuggestCommands(ctx context.Context, app *cli.App, name string) []string {
+	name = strings.ToLower(name)
+	type suggestion struct {
+		name     string
+		distance int
+		usage    int
+	}
+	usage := stats.CommandUsage(ctx)
+	suggestions := make([]suggestion, 0)
+	for _, cmd := range app.Commands {
+		if cmd.Hidden {
+			continue
+		}
+		distance := -1
+		for _, alias := range cmd.Names() {
+			d := editDistance(name, strings.ToLower(alias))
+			if strings.HasPrefix(strings.ToLower(alias), name) {
+				d = 0
+			}
+			if distance == -1 || d < distance {
+				distance = d
+			}
+		}
+		if distance > maxSuggestionDistance(name) {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{name: cmd.Name, distance: distance, usage: commandUsage(usage, cmd)})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].usage != suggestions[j].usage {
+			return suggestions[i].usage > suggestions[j].usage
+		}
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	names := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// commandUsage returns the number of recorded invocations of cmd under any of its names
+func commandUsage(usage map[string]int, cmd *cli.Command) int {
+	count := 0
+	for _, name := range cmd.Names() {
+		count += usage[strings.ToLower(name)]
+	}
+	return count
+}
+
+// maxSuggestionDistance is the number of typos allowed in a name for a command to be suggested
+func maxSuggestionDistance(name string) int {
+	if len(name) < 4 {
+		return 1
+	}
+	return 2
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// needed to turn a into b
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := rows[i-1][j-1] + cost
+			if rows[i-1][j]+1 < d {
+				d = rows[i-1][j] + 1
+			}
+			if rows[i][j-1]+1 < d {
+				d = rows[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && rows[i-2][j-2]+1 < d {
+				d = rows[i-2][j-2] + 1
+			}
+			rows[i][j] = d
+		}
+	}
+	return rows[len(a)][len(b)]
+}
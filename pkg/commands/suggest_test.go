@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func usageContext(t *testing.T, commands ...string) context.Context {
+	history := make([]stats.Payload, 0, len(commands))
+	for i, cmd := range commands {
+		history = append(history, stats.Payload{Invocation: string(rune('a' + i)), Command: cmd})
+	}
+	data, err := json.Marshal(history)
+	require.NoError(t, err)
+	path, err := stats.HistoryPath()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", "enable-cli-statistics").Return("1.1", true)
+	return config.Context(context.Background(), cfg)
+}
+
+func TestRankCommandsByUsage(t *testing.T) {
+	cliHome := packageStoreHome(t)
+	defer func() {
+		require.NoError(t, os.RemoveAll(cliHome))
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	}()
+	ctx := usageContext(t, "echo", "pm", "property-manager", "pm", "install")
+	commands := []*cli.Command{{Name: "config"}, {Name: "dns"}, {Name: "echo"}, {Name: "install"}, {Name: "property-manager", Aliases: []string{"pm"}}}
+
+	RankCommandsByUsage(ctx, commands)
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	assert.Equal(t, []string{"property-manager", "echo", "install", "config", "dns"}, names)
+}
+
+func TestSuggestCommands(t *testing.T) {
+	app := &cli.App{Commands: []*cli.Command{
+		{Name: "dns"}, {Name: "dmo"}, {Name: "edgeworkers", Aliases: []string{"ew"}}, {Name: "install"}, {Name: "debug", Hidden: true},
+	}}
+	tests := map[string]struct {
+		name     string
+		used     []string
+		expected []string
+	}{
+		"typo":                     {name: "dsn", expected: []string{"dns"}},
+		"prefix":                   {name: "edge", expected: []string{"edgeworkers"}},
+		"alias":                    {name: "ev", expected: []string{"edgeworkers"}},
+		"closest first":            {name: "dnso", expected: []string{"dns", "dmo"}},
+		"most used first":          {name: "dnso", used: []string{"dmo"}, expected: []string{"dmo", "dns"}},
+		"hidden commands excluded": {name: "debig", expected: []string{}},
+		"nothing similar":          {name: "purge", expected: []string{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			ctx := usageContext(t, test.used...)
+
+			assert.Equal(t, test.expected, SuggestCommands(ctx, app, test.name))
+		})
+	}
+}
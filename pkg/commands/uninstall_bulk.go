@@ -0,0 +1,315 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/stats"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// uninstallConcurrency is the number of packages removed at the same time by a bulk uninstall
+const uninstallConcurrency = 4
+
+// uninstall statuses reported in the summary
+const (
+	uninstallStatusRemoved = "uninstalled"
+	uninstallStatusFailed  = "failed"
+)
+
+type uninstallResult struct {
+	Name    string
+	Package string
+	Version string
+	Status  string
+	Err     error
+
+	dir string
+}
+
+// uninstallOutput is the outcome of uninstalling a package, as reported with --json
+type uninstallOutput struct {
+	Command string `json:"command"`
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// uninstallPackages removes several packages at once, or all of them with --all, after asking for confirmation.
+// Package directories are removed concurrently, the remaining cleanup of each package is done one by one,
+// as it updates the package store and the config.
+func uninstallPackages(c *cli.Context, langManager packages.LangManager) ([]*uninstallResult, error) {
+	ctx := c.Context
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+
+	var results []*uninstallResult
+	if c.Bool("all") {
+		results = installedUninstallTargets()
+		if len(results) == 0 {
+			term.Writeln("No packages installed")
+			return results, nil
+		}
+	} else {
+		results = uninstallTargets(c, langManager, c.Args().Slice())
+	}
+
+	var pending []*uninstallResult
+	for _, res := range results {
+		if res.Err == nil {
+			pending = append(pending, res)
+		}
+	}
+	if len(pending) > 0 && !c.Bool("yes") {
+		if !term.IsTTY() {
+			return nil, cli.Exit(color.RedString("Confirmation required to uninstall %d packages, use --yes to uninstall them without asking", len(pending)), 1)
+		}
+		names := make([]string, 0, len(pending))
+		for _, res := range pending {
+			names = append(names, res.Package)
+		}
+		answer, err := term.Confirm(fmt.Sprintf("Uninstall %d packages (%s)?", len(pending), strings.Join(names, ", ")), false)
+		if err != nil {
+			return nil, cli.Exit(color.RedString(err.Error()), 1)
+		}
+		if !answer {
+			term.Writeln("Uninstall cancelled")
+			return nil, nil
+		}
+	}
+
+	// packages with running commands are not removed, unless --wait was given and they finish,
+	// nor are packages whose pre-uninstall hook fails
+	idle := make([]*uninstallResult, 0, len(pending))
+	for _, res := range pending {
+		if err := checkPackageIdle(ctx, res.dir); err != nil {
+			res.Status, res.Err = uninstallStatusFailed, err
+			continue
+		}
+		if pkg, err := readPackage(res.dir); err == nil {
+			if err := runPackageHook(ctx, res.dir, pkg, hookPreUninstall); err != nil {
+				res.Status, res.Err = uninstallStatusFailed, err
+				continue
+			}
+		}
+		idle = append(idle, res)
+	}
+
+	if len(pending) > 0 {
+		term.Spinner().Start(fmt.Sprintf("Uninstalling %d packages...", len(pending)))
+		removePackageDirs(idle, logger)
+		failed := false
+		for _, res := range pending {
+			failed = failed || res.Err != nil
+		}
+		if failed {
+			term.Spinner().Warn()
+		} else {
+			term.Spinner().OK()
+		}
+	}
+
+	removed := make(map[string]bool)
+	for _, res := range pending {
+		if res.Err != nil {
+			stats.TrackEvent(ctx, "package.uninstall", "failed", res.Name)
+			continue
+		}
+		removed[res.Package] = true
+		stats.TrackEvent(ctx, "package.uninstall", "success", res.Name)
+		recordPackageEvent(ctx, packageEventUninstall, res.dir)
+		notifyPackageEvent(ctx, packageEventUninstall, res.dir, res.Version, "")
+	}
+	if err := unsetCommandOwners(ctx, removed); err != nil {
+		logger.Warnf("Unable to remove command owner settings: %s", err)
+	}
+	deps := installedDependencies()
+	for _, res := range pending {
+		if !removed[res.Package] {
+			continue
+		}
+		if dependents := deps.dependents(res.Package); len(dependents) > 0 {
+			warnMsg := fmt.Sprintf("Warning: package %s is required by %s, which may no longer work.", res.Package, strings.Join(dependents, ", "))
+			term.Writeln(color.YellowString(warnMsg))
+			logger.Warn(warnMsg)
+		}
+	}
+
+	if !jsonOutput(c) {
+		if err := printUninstallSummary(term, results); err != nil {
+			return results, err
+		}
+	}
+	var failed int
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, cli.Exit(color.RedString("Unable to uninstall %d of %d packages", failed, len(results)), 1)
+	}
+	return results, nil
+}
+
+// installedUninstallTargets returns all installed packages, including disabled ones
+func installedUninstallTargets() []*uninstallResult {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil
+	}
+	dirs, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	sort.Strings(dirs)
+	results := make([]*uninstallResult, 0, len(dirs))
+	for _, dir := range dirs {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		res := &uninstallResult{Name: filepath.Base(dir), Package: filepath.Base(dir), dir: dir}
+		if len(pkg.Commands) > 0 {
+			res.Version = pkg.Commands[0].Version
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// uninstallTargets finds the packages containing given commands, a package providing several of them is listed once
+func uninstallTargets(c *cli.Context, langManager packages.LangManager, cmds []string) []*uninstallResult {
+	results := make([]*uninstallResult, 0, len(cmds))
+	seen := make(map[string]bool)
+	for _, cmd := range cmds {
+		res := &uninstallResult{Name: cmd, Package: cmd, Status: uninstallStatusFailed}
+		exec, err := findExec(c.Context, langManager, cmd)
+		if err != nil {
+			res.Err = fmt.Errorf("command \"%s\" not found", cmd)
+			results = append(results, res)
+			continue
+		}
+		res.dir = execPackageDir(exec)
+		if res.dir == "" {
+			res.Err = fmt.Errorf("not installed using \"%s install\"", tools.Self())
+			results = append(results, res)
+			continue
+		}
+		if seen[res.dir] {
+			continue
+		}
+		seen[res.dir] = true
+		res.Package = filepath.Base(res.dir)
+		if pkg, err := readPackage(res.dir); err == nil {
+			res.Version = commandVersion(pkg, cmd)
+		}
+		res.Status = ""
+		results = append(results, res)
+	}
+	return results
+}
+
+// removePackageDirs removes the package directories concurrently and sets the status of each result
+func removePackageDirs(results []*uninstallResult, logger log.Logger) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, uninstallConcurrency)
+	for _, res := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *uninstallResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			logger.Debugf("Removing package directory: %s", res.dir)
+			if err := os.RemoveAll(res.dir); err != nil {
+				logger.Errorf("unable to remove directory: %s: %s", res.dir, err)
+				res.Status, res.Err = uninstallStatusFailed, fmt.Errorf("unable to remove directory: %s", res.dir)
+				return
+			}
+			res.Status = uninstallStatusRemoved
+		}(res)
+	}
+	wg.Wait()
+}
+
+// unsetCommandOwners removes command-owner settings choosing one of the removed packages
+func unsetCommandOwners(ctx context.Context, removed map[string]bool) error {
+	cfg := config.Get(ctx)
+	changed := false
+	for cmd, owner := range cfg.Values()[commandOwnerSection] {
+		if removed[owner] {
+			cfg.UnsetValue(commandOwnerSection, cmd)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return cfg.Save(ctx)
+}
+
+// uninstallOutputs converts the results for JSON output
+func uninstallOutputs(results []*uninstallResult) []uninstallOutput {
+	outputs := make([]uninstallOutput, 0, len(results))
+	for _, res := range results {
+		out := uninstallOutput{Command: res.Name, Package: res.Package, Version: res.Version, Path: res.dir, Status: res.Status}
+		if res.Err != nil {
+			out.Status, out.Error = uninstallStatusFailed, res.Err.Error()
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs
+}
+
+func printUninstallSummary(term terminal.Terminal, results []*uninstallResult) error {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tVERSION\tSTATUS")
+	for _, res := range results {
+		version := res.Version
+		if version == "" {
+			version = "-"
+		}
+		status := res.Status
+		if res.Err != nil {
+			status = fmt.Sprintf("%s: %s", uninstallStatusFailed, res.Err)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", res.Package, version, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	term.Writeln(color.YellowString("\nUninstall Summary:\n"))
+	term.Printf("%s", buf.String())
+	return nil
+}
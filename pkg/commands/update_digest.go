@@ -0,0 +1,168 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// updateDigestKey is the config key enabling the digest of pending updates: "daily", "weekly" or an interval such as 72h
+	updateDigestKey = "update-digest"
+	// lastUpdateDigestKey holds the time the digest was last reported
+	lastUpdateDigestKey = "last-update-digest"
+
+	// packageEventDigest is the event of digests posted to the webhook
+	packageEventDigest = "update-digest"
+)
+
+// updateDigest lists the pending updates of Akamai CLI and installed commands, as posted to the webhook
+type updateDigest struct {
+	Event            string              `json:"event"`
+	Host             string              `json:"host"`
+	CLIVersion       string              `json:"cliVersion"`
+	LatestCLIVersion string              `json:"latestCliVersion,omitempty"`
+	Commands         []upgradableCommand `json:"commands"`
+	Time             time.Time           `json:"time"`
+}
+
+// updateDigestInterval returns the interval set in cli.update-digest, false if the digest is not enabled
+func updateDigestInterval(ctx context.Context) (time.Duration, bool) {
+	return settingInterval(ctx, updateDigestKey)
+}
+
+// settingInterval returns the interval set in given cli setting, false if it is off
+func settingInterval(ctx context.Context, key string) (time.Duration, bool) {
+	val, _ := config.Get(ctx).GetValue("cli", key)
+	interval, err := parseInterval(key, val)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", key, val)
+		return 0, false
+	}
+	return interval, interval > 0
+}
+
+// parseInterval returns the interval of a setting such as cli.update-digest, zero when it is off
+func parseInterval(key, val string) (time.Duration, error) {
+	switch val = strings.ToLower(strings.TrimSpace(val)); val {
+	case "", "off", "false":
+		return 0, nil
+	case "daily":
+		return sleepTime24Hours, nil
+	case "weekly":
+		return 7 * sleepTime24Hours, nil
+	}
+	interval, err := time.ParseDuration(val)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("cli.%s: %q is not a valid interval, expected daily, weekly, off or a duration such as 72h", key, val)
+	}
+	return interval, nil
+}
+
+// CheckUpdateDigest reports the pending updates of Akamai CLI and installed commands in a single digest once the
+// interval set in cli.update-digest has elapsed since the last one. The digest is printed to stderr in a terminal and
+// posted to the webhook, if any. Without either, the digest waits for the next run in a terminal.
+func CheckUpdateDigest(ctx context.Context) {
+	interval, ok := updateDigestInterval(ctx)
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	cfg := config.Get(ctx)
+	term := terminal.Get(ctx)
+	if val, ok := cfg.GetValue("cli", lastUpdateDigestKey); ok {
+		if last, err := time.Parse(time.RFC3339, strings.TrimSpace(val)); err == nil && time.Since(last) < interval {
+			return
+		}
+	}
+	webhook, _ := cfg.GetValue("cli", webhookKey)
+	webhook = strings.TrimSpace(webhook)
+	if !term.IsTTY() && webhook == "" {
+		return
+	}
+
+	commands, err := findUpgradableCommands(ctx)
+	if err != nil {
+		// the digest is reported on the next run instead
+		logger.Debugf("Unable to check for package updates: %s", err)
+		return
+	}
+	digest := updateDigest{
+		Event:      packageEventDigest,
+		CLIVersion: version.Version,
+		Commands:   commands,
+		Time:       time.Now().UTC(),
+	}
+	if latest := latestCLIVersion(ctx); latest != "" && version.Compare(version.Version, latest) == 1 {
+		digest.LatestCLIVersion = latest
+	}
+	cfg.SetValue("cli", lastUpdateDigestKey, digest.Time.Format(time.RFC3339))
+	if err := cfg.Save(ctx); err != nil {
+		logger.Debugf("Unable to save the time of the update digest: %s", err)
+	}
+	if digest.LatestCLIVersion == "" && len(digest.Commands) == 0 {
+		logger.Debug("No pending updates for the update digest")
+		return
+	}
+
+	if term.IsTTY() {
+		printUpdateDigest(term, digest)
+	}
+	if webhook != "" {
+		if digest.Host, err = os.Hostname(); err != nil {
+			logger.Debugf("Unable to read hostname: %s", err)
+		}
+		body, err := json.Marshal(digest)
+		if err != nil {
+			logger.Debugf("Unable to encode update digest: %s", err)
+			return
+		}
+		if err := postWebhook(ctx, webhook, body); err != nil {
+			logger.Debugf("Unable to post update digest to %s: %s", webhook, err)
+		}
+	}
+}
+
+func printUpdateDigest(term terminal.Terminal, digest updateDigest) {
+	out := term.Error()
+	fmt.Fprintln(out, color.YellowString("Pending updates:"))
+	if digest.LatestCLIVersion != "" {
+		fmt.Fprintf(out, "  Akamai CLI %s -> %s\n", digest.CLIVersion, color.GreenString(digest.LatestCLIVersion))
+	}
+	for _, cmd := range digest.Commands {
+		fmt.Fprintf(out, "  %s %s -> %s\n", cmd.Name, cmd.Current, color.GreenString(cmd.Latest))
+	}
+	var hints []string
+	if len(digest.Commands) > 0 {
+		hints = append(hints, fmt.Sprintf("\"%s update\" to update the commands", tools.Self()))
+	}
+	if digest.LatestCLIVersion != "" {
+		hints = append(hints, fmt.Sprintf("\"%s upgrade\" to upgrade Akamai CLI", tools.Self()))
+	}
+	fmt.Fprintf(out, "Run %s.\n", strings.Join(hints, " and "))
+}
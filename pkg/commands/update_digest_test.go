@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseUpdateDigest(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		withError bool
+	}{
+		"weekly":   {value: "weekly", expected: 7 * 24 * time.Hour},
+		"daily":    {value: " Daily ", expected: 24 * time.Hour},
+		"duration": {value: "72h", expected: 72 * time.Hour},
+		"off":      {value: "off"},
+		"empty":    {},
+		"negative": {value: "-1h", withError: true},
+		"invalid":  {value: "monthly", withError: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			interval, err := parseInterval(updateDigestKey, test.value)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, interval)
+		})
+	}
+}
+
+func TestCheckUpdateDigest(t *testing.T) {
+	var posted []updateDigest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases/latest":
+			w.Header().Set("Location", "/releases/tag/99.0.0")
+			w.WriteHeader(http.StatusFound)
+		case "/webhook":
+			var digest updateDigest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&digest))
+			posted = append(posted, digest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		digest   string
+		last     string
+		tty      bool
+		webhook  bool
+		latest   string
+		expected string
+		saved    bool
+	}{
+		"disabled": {
+			tty: true,
+		},
+		"interval not elapsed": {
+			digest: "weekly",
+			last:   time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+			tty:    true,
+		},
+		"pending updates": {
+			digest: "weekly",
+			last:   time.Now().Add(-8 * 24 * time.Hour).Format(time.RFC3339),
+			tty:    true,
+			latest: "1.1.0",
+			expected: color.YellowString("Pending updates:") + "\n" +
+				"  Akamai CLI " + version.Version + " -> " + color.GreenString("99.0.0") + "\n" +
+				"  dns 1.0.0 -> " + color.GreenString("1.1.0") + "\n" +
+				"Run \"commands.test update\" to update the commands and \"commands.test upgrade\" to upgrade Akamai CLI.\n",
+			saved: true,
+		},
+		"first digest posted to the webhook": {
+			digest:  "daily",
+			webhook: true,
+			latest:  "1.1.0",
+			saved:   true,
+		},
+		"commands up-to-date": {
+			digest: "72h",
+			tty:    true,
+			latest: "1.0.0",
+			expected: color.YellowString("Pending updates:") + "\n" +
+				"  Akamai CLI " + version.Version + " -> " + color.GreenString("99.0.0") + "\n" +
+				"Run \"commands.test upgrade\" to upgrade Akamai CLI.\n",
+			saved: true,
+		},
+		"not a terminal": {
+			digest: "weekly",
+		},
+		"invalid interval": {
+			digest: "monthly",
+			tty:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			posted = nil
+			require.NoError(t, os.Setenv("CLI_REPOSITORY", srv.URL))
+			defer func() {
+				require.NoError(t, os.Unsetenv("CLI_REPOSITORY"))
+			}()
+			cliHome := packageStoreHome(t)
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliHome))
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			}()
+			writeStorePackage(t, filepath.Join(cliHome, ".akamai-cli", "src"), "cli-dns", `{"commands": [{"name": "dns", "version": "1.0.0"}]}`)
+			cachePath := filepath.Join(cliHome, "cache")
+			list, err := json.Marshal(packageList{Packages: []packageListPackage{{Name: "cli-dns", Commands: []command{{Name: "dns", Version: test.latest}}}}})
+			require.NoError(t, err)
+			require.NoError(t, writeCache(cachePath, "package-list.json", list))
+
+			cfg, term := &config.Mock{}, &terminal.Mock{}
+			cfg.On("GetValue", "cli", updateDigestKey).Return(test.digest, test.digest != "")
+			cfg.On("GetValue", "cli", lastUpdateDigestKey).Return(test.last, test.last != "").Maybe()
+			webhook := ""
+			if test.webhook {
+				webhook = srv.URL + "/webhook"
+			}
+			cfg.On("GetValue", "cli", webhookKey).Return(webhook, test.webhook).Maybe()
+			cfg.On("GetValue", "cli", cachePathKey).Return(cachePath, true).Maybe()
+			cfg.On("GetValue", "cli", mock.Anything).Return("", false).Maybe()
+			if test.saved {
+				cfg.On("SetValue", "cli", lastUpdateDigestKey, mock.Anything).Return().Once()
+				cfg.On("Save", mock.Anything).Return(nil).Once()
+			}
+			term.On("IsTTY").Return(test.tty).Maybe()
+			out := &bytes.Buffer{}
+			term.On("Error").Return(out).Maybe()
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+			CheckUpdateDigest(ctx)
+			cfg.AssertExpectations(t)
+			assert.Equal(t, test.expected, out.String())
+			if test.webhook {
+				require.Len(t, posted, 1)
+				assert.Equal(t, packageEventDigest, posted[0].Event)
+				assert.Equal(t, "99.0.0", posted[0].LatestCLIVersion)
+				assert.Equal(t, []upgradableCommand{{Name: "dns", Current: "1.0.0", Latest: "1.1.0"}}, posted[0].Commands)
+			} else {
+				assert.Empty(t, posted)
+			}
+		})
+	}
+}
+
+func TestCheckUpgradeVersionWithDigest(t *testing.T) {
+	cfg, term := &config.Mock{}, &terminal.Mock{}
+	cfg.On("GetValue", "cli", "last-upgrade-check").Return("never", true)
+	cfg.On("GetValue", "cli", updateDigestKey).Return("weekly", true)
+	term.On("IsTTY").Return(true)
+	ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+	assert.Empty(t, CheckUpgradeVersion(ctx, false))
+	cfg.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// update statuses reported in the summary
+const (
+	updateStatusUpdated  = "updated"
+	updateStatusUpToDate = "up-to-date"
+	updateStatusSkipped  = "skipped"
+	updateStatusFailed   = "failed"
+)
+
+// dependencyFiles lists the manifests and lockfiles which affect installed package dependencies
+var dependencyFiles = map[string]bool{
+	"cli.json":          true,
+	"requirements.txt":  true,
+	"setup.py":          true,
+	"package.json":      true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"go.mod":            true,
+	"go.sum":            true,
+	"glide.lock":        true,
+	"Gopkg.lock":        true,
+	"composer.json":     true,
+	"composer.lock":     true,
+	"Gemfile":           true,
+	"Gemfile.lock":      true,
+}
+
+type updateResult struct {
+	Command           string   `json:"command"`
+	Package           string   `json:"package,omitempty"`
+	Path              string   `json:"path,omitempty"`
+	Status            string   `json:"status"`
+	OldVersion        string   `json:"oldVersion,omitempty"`
+	NewVersion        string   `json:"newVersion,omitempty"`
+	OldCommit         string   `json:"oldCommit,omitempty"`
+	NewCommit         string   `json:"newCommit,omitempty"`
+	Commits           int      `json:"commits"`
+	FilesChanged      int      `json:"filesChanged"`
+	DependencyChanges []string `json:"dependencyChanges"`
+	Selftest          string   `json:"selftest,omitempty"`
+	Error             string   `json:"error,omitempty"`
+	TimedOut          bool     `json:"timedOut,omitempty"`
+	Duration          elapsed  `json:"durationMs"`
+}
+
+func commandVersion(pkg subcommands, cmd string) string {
+	for _, command := range pkg.Commands {
+		if strings.EqualFold(command.Name, cmd) {
+			return command.Version
+		}
+		for _, alias := range command.Aliases {
+			if strings.EqualFold(alias, cmd) {
+				return command.Version
+			}
+		}
+	}
+	return ""
+}
+
+func dependencyChanges(files []string) []string {
+	changed := make([]string, 0)
+	for _, file := range files {
+		if dependencyFiles[filepath.Base(file)] {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+func printUpdateSummary(ctx context.Context, results []updateResult) error {
+	term := terminal.Get(ctx)
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  COMMAND\tVERSION\tCOMMIT\tCOMMITS\tFILES\tDEPENDENCIES\tTIME\tSTATUS")
+	counts := make(map[string]int)
+	for _, res := range results {
+		counts[res.Status]++
+		version := res.OldVersion
+		if res.NewVersion != res.OldVersion {
+			version = fmt.Sprintf("%s -> %s", res.OldVersion, res.NewVersion)
+		}
+		var commit string
+		if res.OldCommit != "" {
+			commit = fmt.Sprintf("%s -> %s", shortHash(res.OldCommit), shortHash(res.NewCommit))
+		}
+		deps := strings.Join(res.DependencyChanges, ", ")
+		if deps == "" {
+			deps = "-"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", res.Command, version, commit, res.Commits, res.FilesChanged, deps, res.Duration, res.Status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if counts[updateStatusSkipped] > 0 {
+		fmt.Fprintf(buf, "\n  %d updated, %d up-to-date, %d skipped, %d failed\n", counts[updateStatusUpdated], counts[updateStatusUpToDate], counts[updateStatusSkipped], counts[updateStatusFailed])
+	} else {
+		fmt.Fprintf(buf, "\n  %d updated, %d up-to-date, %d failed\n", counts[updateStatusUpdated], counts[updateStatusUpToDate], counts[updateStatusFailed])
+	}
+
+	term.Writeln(color.YellowString("\nUpdate Summary:\n"))
+	term.Printf("%s", buf.String())
+	return nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// requiresReinstall returns true if any of the changed files affects installed dependencies or, for Go packages,
+// the built binaries, so that updates touching e.g. only documentation do not rerun package managers
+func requiresReinstall(requirements packages.LanguageRequirements, files []string) bool {
+	if len(dependencyChanges(files)) > 0 {
+		return true
+	}
+	if requirements.Go == "" {
+		return false
+	}
+	for _, file := range files {
+		if filepath.Ext(file) == ".go" || strings.HasPrefix(filepath.ToSlash(file), "vendor/") {
+			return true
+		}
+	}
+	return false
+}
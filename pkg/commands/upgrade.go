@@ -20,10 +20,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -34,6 +36,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/akamai/cli/pkg/version"
 	"github.com/fatih/color"
@@ -55,6 +58,10 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	if data == "ignore" && !force {
 		return ""
 	}
+	// the update digest reports new versions instead of the daily prompt
+	if _, digest := updateDigestInterval(ctx); digest && !force {
+		return ""
+	}
 
 	checkForUpgrade := false
 	if data == "never" || force {
@@ -86,6 +93,9 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 		comp := version.Compare(version.Version, latestVersion)
 		if comp == 1 {
 			term.Spinner().Stop(terminal.SpinnerStatusOK)
+			if !force && !upgradeWindowOpen(ctx, latestVersion) {
+				return ""
+			}
 			if answer, err := term.Confirm(fmt.Sprintf(
 				"New upgrade found: %s (you are running: %s). Upgrade now? [Y/n]: ",
 				color.BlueString(latestVersion),
@@ -103,18 +113,23 @@ func CheckUpgradeVersion(ctx context.Context, force bool) string {
 	return ""
 }
 
+// latestCLIVersion returns the latest release of Akamai CLI, empty if it cannot be checked
+func latestCLIVersion(ctx context.Context) string {
+	if latest := getLatestReleaseVersion(ctx); latest != "0" {
+		return latest
+	}
+	return ""
+}
+
 func getLatestReleaseVersion(ctx context.Context) string {
 	logger := log.FromContext(ctx)
 	client := &http.Client{
+		Transport: download.LimitTransport(ctx, nil),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
-	repo := "https://github.com/akamai/cli"
-	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
-		repo = r
-	}
-	resp, err := client.Head(fmt.Sprintf("%s/releases/latest", repo))
+	resp, err := client.Head(fmt.Sprintf("%s/releases/latest", upgradeRepository(ctx)))
 	if err != nil {
 		return "0"
 	}
@@ -134,6 +149,18 @@ func getLatestReleaseVersion(ctx context.Context) string {
 	return latestVersion
 }
 
+// upgradeRepository returns the base URL of Akamai CLI releases
+// It can be pointed to an internal mirror using CLI_REPOSITORY environment variable or cli.upgrade-url config value
+func upgradeRepository(ctx context.Context) string {
+	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
+		return r
+	}
+	if r, ok := config.Get(ctx).GetValue("cli", "upgrade-url"); ok && strings.TrimSpace(r) != "" {
+		return strings.TrimSuffix(strings.TrimSpace(r), "/")
+	}
+	return "https://github.com/akamai/cli"
+}
+
 // UpgradeCli ...
 func UpgradeCli(ctx context.Context, latestVersion string) bool {
 	term := terminal.Get(ctx)
@@ -141,13 +168,73 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 
 	term.Spinner().Start("Upgrading Akamai CLI")
 
-	repo := "https://github.com/akamai/cli"
-	if r := os.Getenv("CLI_REPOSITORY"); r != "" {
-		repo = r
+	client := &http.Client{Transport: download.ProxyTransport(ctx)}
+	binURL, err := upgradeBinaryURL(ctx, latestVersion)
+	if err != nil {
+		return false
+	}
+	shaResp, err := client.Get(fmt.Sprintf("%v%v", binURL, ".sig"))
+	if err != nil || shaResp.StatusCode != http.StatusOK {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
+		return false
+	}
+	defer func() {
+		if err := shaResp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+
+	shabody, err := ioutil.ReadAll(shaResp.Body)
+	if err != nil {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
+		return false
+	}
+
+	shasum, err := hex.DecodeString(strings.TrimSpace(string(shabody)))
+	if err != nil {
+		term.Spinner().Fail()
+		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
+		return false
+	}
+
+	selfPath := os.Args[0]
+
+	err = applyUpgradePatch(ctx, client, upgradePatchURL(binURL, version.Version), selfPath, shasum)
+	if err != nil {
+		if rerr := update.RollbackError(err); rerr != nil {
+			term.Spinner().Fail()
+			term.Writeln(color.RedString("Unable to install or rollback, please re-install."))
+			os.Exit(1)
+			return false
+		}
+		logger.Debugf("Unable to upgrade using a binary patch (%s), downloading full release", err)
+		if !applyUpgradeRelease(ctx, client, binURL, selfPath, shasum) {
+			return false
+		}
+	}
+
+	term.Spinner().OK()
+
+	if err == nil {
+		os.Args[0] = selfPath
+	}
+	err = passthruCommand(os.Args)
+	if err != nil {
+		cli.OsExiter(1)
+		return false
 	}
+	cli.OsExiter(0)
+
+	return true
+}
+
+// upgradeBinaryURL returns the location of the release binary of given version for the current platform
+func upgradeBinaryURL(ctx context.Context, releaseVersion string) (string, error) {
 	cmd := command{
-		Version: latestVersion,
-		Bin:     fmt.Sprintf("%s/releases/download/{{.Version}}/akamai-{{.Version}}-{{.OS}}{{.Arch}}{{.BinSuffix}}", repo),
+		Version: releaseVersion,
+		Bin:     fmt.Sprintf("%s/releases/download/{{.Version}}/akamai-{{.Version}}-{{.OS}}{{.Arch}}{{.BinSuffix}}", upgradeRepository(ctx)),
 		Arch:    runtime.GOARCH,
 		OS:      runtime.GOOS,
 	}
@@ -163,52 +250,72 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 	t := template.Must(template.New("url").Parse(cmd.Bin))
 	buf := &bytes.Buffer{}
 	if err := t.Execute(buf, cmd); err != nil {
-		return false
+		return "", err
 	}
+	return buf.String(), nil
+}
 
-	resp, err := http.Get(buf.String())
-	if err != nil || resp.StatusCode != http.StatusOK {
-		term.Spinner().Fail()
-		errMsg := color.RedString("Unable to download release, please try again.")
-		term.Writeln(errMsg)
-		logger.Error(errMsg)
-		return false
+// upgradePatchURL returns the location of the binary patch upgrading given version to the release at binURL
+func upgradePatchURL(binURL, fromVersion string) string {
+	return fmt.Sprintf("%s.from-%s.bsdiff", binURL, fromVersion)
+}
+
+// applyUpgradePatch downloads a bsdiff patch between the running version and the new release and applies it to the executable
+// Releases only publish patches from the previous version, any error means the full release has to be downloaded
+func applyUpgradePatch(ctx context.Context, client *http.Client, patchURL, targetPath string, checksum []byte) error {
+	logger := log.FromContext(ctx)
+	resp, err := client.Get(patchURL)
+	if err != nil {
+		return err
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			logger.Error(err.Error())
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download patch: %s", resp.Status)
+	}
+	return update.Apply(resp.Body, update.Options{TargetPath: targetPath, Checksum: checksum, Patcher: update.NewBSDiffPatcher()})
+}
 
-	shaResp, err := http.Get(fmt.Sprintf("%v%v", buf.String(), ".sig"))
-	if err != nil || shaResp.StatusCode != http.StatusOK {
+// applyUpgradeRelease downloads the full release binary and replaces the executable with it
+// The release is downloaded to the temporary directory, where an interrupted download is resumed by the next upgrade
+func applyUpgradeRelease(ctx context.Context, client *http.Client, binURL, targetPath string, checksum []byte) bool {
+	term := terminal.Get(ctx)
+	logger := log.FromContext(ctx)
+
+	releasePath := filepath.Join(os.TempDir(), path.Base(binURL))
+	if err := download.File(ctx, binURL, releasePath, download.Options{Client: client, Checksum: checksum, Limiter: download.ConfiguredLimiter(ctx)}); err != nil {
 		term.Spinner().Fail()
-		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
+		if errors.Is(err, download.ErrChecksum) {
+			term.Writeln(color.RedString(err.Error()))
+			term.Writeln(color.RedString("Checksums do not match, please try again."))
+			return false
+		}
+		errMsg := color.RedString("Unable to download release, please try again.")
+		term.Writeln(errMsg)
+		logger.Errorf("%s: %s", errMsg, err)
 		return false
 	}
 	defer func() {
-		if err := shaResp.Body.Close(); err != nil {
+		if err := os.Remove(releasePath); err != nil {
 			logger.Error(err.Error())
 		}
 	}()
-
-	shabody, err := ioutil.ReadAll(shaResp.Body)
-	if err != nil {
-		term.Spinner().Fail()
-		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
-		return false
-	}
-
-	shasum, err := hex.DecodeString(strings.TrimSpace(string(shabody)))
+	release, err := os.Open(releasePath)
 	if err != nil {
 		term.Spinner().Fail()
-		term.Writeln(color.RedString("Unable to retrieve signature for verification, please try again."))
+		term.Writeln(color.RedString(err.Error()))
 		return false
 	}
+	defer func() {
+		if err := release.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
 
-	selfPath := os.Args[0]
-
-	err = update.Apply(resp.Body, update.Options{TargetPath: selfPath, Checksum: shasum})
+	err = update.Apply(release, update.Options{TargetPath: targetPath, Checksum: checksum})
 	if err != nil {
 		term.Spinner().Fail()
 		if rerr := update.RollbackError(err); rerr != nil {
@@ -223,19 +330,6 @@ func UpgradeCli(ctx context.Context, latestVersion string) bool {
 		term.Writeln(color.RedString(err.Error()))
 		return false
 	}
-
-	term.Spinner().OK()
-
-	if err == nil {
-		os.Args[0] = selfPath
-	}
-	err = passthruCommand(os.Args)
-	if err != nil {
-		cli.OsExiter(1)
-		return false
-	}
-	cli.OsExiter(0)
-
 	return true
 }
 
@@ -243,6 +337,32 @@ func getUpgradeCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "upgrade",
 		Description: "Upgrade Akamai CLI to the latest version",
-		Action:      cmdUpgrade,
+		UsageText:   "Examples:\n\n   akamai upgrade\n   akamai upgrade --check-only --json\n   akamai upgrade --schedule \"sat-sun\"\n   akamai upgrade --schedule \"mon-fri 22:00-06:00\"\n   akamai upgrade --schedule off",
+		Action: func(c *cli.Context) error {
+			if c.Bool("check-only") {
+				return cmdUpgradeCheck(c)
+			}
+			if c.Bool("json") {
+				return cli.Exit(color.RedString("--json can only be used with --check-only"), 1)
+			}
+			if c.IsSet("schedule") {
+				return cmdUpgradeSchedule(c)
+			}
+			return cmdUpgrade(c)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "schedule",
+				Usage: "Days and hours of automatic upgrades, such as \"sat-sun\" or \"mon-fri 22:00-06:00\"; outside of them new versions are only announced. Use \"off\" to allow upgrades at any time",
+			},
+			&cli.BoolFlag{
+				Name:  "check-only",
+				Usage: "Only check for a new version, without upgrading or changing any settings",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the result of --check-only as JSON",
+			},
+		},
 	}
 }
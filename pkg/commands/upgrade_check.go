@@ -0,0 +1,119 @@
+//+build !noautoupgrade
+
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/plugin"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// upgradeChannel is the release channel upgrades come from, only the latest release, which is never a pre-release, is offered
+const upgradeChannel = "stable"
+
+type upgradeCheck struct {
+	CurrentVersion   string     `json:"currentVersion"`
+	LatestVersion    string     `json:"latestVersion"`
+	UpgradeAvailable bool       `json:"upgradeAvailable"`
+	Channel          string     `json:"channel"`
+	URL              string     `json:"url"`
+	Published        *time.Time `json:"published,omitempty"`
+}
+
+// cmdUpgradeCheck reports whether a new version is available.
+// Unlike the daily check, it neither prompts nor updates the last-upgrade-check setting, so it can be run by automation.
+func cmdUpgradeCheck(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("UPGRADE CHECK START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("UPGRADE CHECK FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("UPGRADE CHECK ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	latestVersion := getLatestReleaseVersion(c.Context)
+	if latestVersion == "0" {
+		return cli.Exit(color.RedString("Unable to check for upgrades"), plugin.ExitNetwork)
+	}
+	binURL, err := upgradeBinaryURL(c.Context, latestVersion)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to check for upgrades: %s", err), 1)
+	}
+	check := upgradeCheck{
+		CurrentVersion:   version.Version,
+		LatestVersion:    latestVersion,
+		UpgradeAvailable: version.Compare(version.Version, latestVersion) == 1,
+		Channel:          upgradeChannel,
+		URL:              binURL,
+		Published:        releasePublished(c.Context, binURL),
+	}
+
+	if c.Bool("json") {
+		return writeOutput(c.Context, plugin.FormatJSON, check)
+	}
+	if !check.UpgradeAvailable {
+		term.Writeln(fmt.Sprintf("Akamai CLI (%s) is already up-to-date", color.CyanString("v"+check.CurrentVersion)))
+		return nil
+	}
+	term.Writeln(fmt.Sprintf("New version available: %s (you are running: %s)", color.BlueString(check.LatestVersion), color.BlueString(check.CurrentVersion)))
+	if check.Published != nil {
+		term.Writeln(fmt.Sprintf("Published: %s", check.Published.Format(time.RFC3339)))
+	}
+	term.Writeln(fmt.Sprintf("Download: %s", check.URL))
+	return nil
+}
+
+// releasePublished returns the time the release binary was published, based on its Last-Modified header
+// nil is returned if the time is not known, e.g. when a mirror does not send the header
+func releasePublished(ctx context.Context, binURL string) *time.Time {
+	logger := log.FromContext(ctx)
+	client := &http.Client{Transport: download.ProxyTransport(ctx)}
+	resp, err := client.Head(binURL)
+	if err != nil {
+		logger.Debugf("Unable to check release publish time: %s", err)
+		return nil
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	published, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return nil
+	}
+	published = published.UTC()
+	return &published
+}
@@ -30,6 +30,11 @@ func getLatestReleaseVersion() string {
 	return "0"
 }
 
+// latestCLIVersion returns no version, Akamai CLI is upgraded by the package manager it was installed with
+func latestCLIVersion(context.Context) string {
+	return ""
+}
+
 func UpgradeCli(ctx context.Context, latestVersion string) bool {
 	return false
 }
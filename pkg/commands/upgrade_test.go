@@ -0,0 +1,156 @@
+//+build !noautoupgrade
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestUpgradeRepository(t *testing.T) {
+	tests := map[string]struct {
+		env      string
+		init     func(*config.Mock)
+		expected string
+	}{
+		"default repository": {
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "upgrade-url").Return("", false).Once()
+			},
+			expected: "https://github.com/akamai/cli",
+		},
+		"mirror from config": {
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "upgrade-url").Return("https://mirror.example.com/akamai-cli/", true).Once()
+			},
+			expected: "https://mirror.example.com/akamai-cli",
+		},
+		"repository from environment": {
+			env:      "https://env.example.com/cli",
+			init:     func(m *config.Mock) {},
+			expected: "https://env.example.com/cli",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("CLI_REPOSITORY", test.env))
+			m := &config.Mock{}
+			test.init(m)
+			assert.Equal(t, test.expected, upgradeRepository(config.Context(context.Background(), m)))
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCmdUpgradeCheck(t *testing.T) {
+	binURLRegexp := regexp.MustCompile(`/releases/download/[0-9]+\.[0-9]+\.[0-9]+/akamai-[0-9]+\.[0-9]+\.[0-9]+-[A-Za-z0-9]+$`)
+	tests := map[string]struct {
+		args              []string
+		respLatestVersion string
+		lastModified      string
+		expected          string
+		withError         string
+	}{
+		"upgrade available as JSON": {
+			args:              []string{"--json"},
+			respLatestVersion: "10.0.0",
+			lastModified:      "Tue, 09 Mar 2021 10:15:00 GMT",
+			expected: fmt.Sprintf(`{
+  "currentVersion": %q,
+  "latestVersion": "10.0.0",
+  "upgradeAvailable": true,
+  "channel": "stable",
+  "url": "{{URL}}",
+  "published": "2021-03-09T10:15:00Z"
+}`, version.Version),
+		},
+		"up to date as JSON without publish time": {
+			args:              []string{"--json"},
+			respLatestVersion: version.Version,
+			expected: fmt.Sprintf(`{
+  "currentVersion": %q,
+  "latestVersion": %q,
+  "upgradeAvailable": false,
+  "channel": "stable",
+  "url": "{{URL}}"
+}`, version.Version, version.Version),
+		},
+		"upgrade available": {
+			respLatestVersion: "10.0.0",
+			lastModified:      "Tue, 09 Mar 2021 10:15:00 GMT",
+			expected:          fmt.Sprintf("New version available: 10.0.0 (you are running: %s)\nPublished: 2021-03-09T10:15:00Z\nDownload: {{URL}}", version.Version),
+		},
+		"up to date": {
+			respLatestVersion: version.Version,
+			expected:          fmt.Sprintf("Akamai CLI (v%s) is already up-to-date", version.Version),
+		},
+		"latest version not found": {
+			withError: "Unable to check for upgrades",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodHead {
+					t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+				}
+				switch {
+				case r.URL.Path == "/releases/latest" && test.respLatestVersion != "":
+					w.Header().Set("Location", test.respLatestVersion)
+					w.WriteHeader(http.StatusFound)
+				case binURLRegexp.MatchString(r.URL.Path) && test.lastModified != "":
+					w.Header().Set("Last-Modified", test.lastModified)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("CLI_REPOSITORY", srv.URL))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			m.cfg.On("GetValue", "cli", "proxy").Return("", false)
+			var output []string
+			m.term.On("Writeln", mock.Anything).Return(0, nil).Run(func(args mock.Arguments) {
+				output = append(output, fmt.Sprint(args.Get(0).([]interface{})...))
+			}).Maybe()
+			command := &cli.Command{
+				Name:   "upgrade",
+				Action: cmdUpgradeCheck,
+				Flags:  []cli.Flag{&cli.BoolFlag{Name: "json"}},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "upgrade")
+			args = append(args, test.args...)
+
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			binURL, err := upgradeBinaryURL(ctx, test.respLatestVersion)
+			require.NoError(t, err)
+			assert.Equal(t, strings.ReplaceAll(test.expected, "{{URL}}", binURL), strings.Join(output, "\n"))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+//+build !noautoupgrade
+
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+	"github.com/akamai/cli/pkg/version"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// upgradeWindowKey is the config key holding the days and hours during which automatic upgrades are offered,
+// e.g. "sat-sun" or "mon-fri 22:00-06:00". Outside the window a new version is only announced.
+const upgradeWindowKey = "upgrade-window"
+
+// inUpgradeWindow returns true if automatic upgrades are allowed at given time. If the window is not configured,
+// upgrades are always allowed; if it is not valid, they are never offered, so that the policy is not bypassed by a typo.
+func inUpgradeWindow(ctx context.Context, t time.Time) (bool, string) {
+	val, ok := config.Get(ctx).GetValue("cli", upgradeWindowKey)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return true, ""
+	}
+	w, err := parseMaintenanceWindow(val)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", upgradeWindowKey, err)
+		return false, val
+	}
+	return w.allows(t), val
+}
+
+// upgradeWindowOpen returns true if automatic upgrades are allowed now, otherwise it only announces the latest version
+func upgradeWindowOpen(ctx context.Context, latestVersion string) bool {
+	allowed, window := inUpgradeWindow(ctx, time.Now())
+	if !allowed {
+		terminal.Get(ctx).Printf("New upgrade found: %s (you are running: %s). Upgrades are allowed only during %s, run \"%s\" to upgrade now.\n",
+			color.BlueString(latestVersion),
+			color.BlueString(version.Version),
+			color.BlueString(window),
+			color.BlueString("%s upgrade", tools.Self()),
+		)
+	}
+	return allowed
+}
+
+// cmdUpgradeSchedule stores the upgrade window given with --schedule flag in config
+func cmdUpgradeSchedule(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.WithCommand(c.Context, c.Command.Name)
+	start := time.Now()
+	logger.Debug("UPGRADE SCHEDULE START")
+	defer func() {
+		if e == nil {
+			logger.Debugf("UPGRADE SCHEDULE FINISH: %v", time.Now().Sub(start))
+		} else {
+			logger.Errorf("UPGRADE SCHEDULE ERROR: %v", e.Error())
+		}
+	}()
+	term := terminal.Get(c.Context)
+	cfg := config.Get(c.Context)
+
+	schedule := strings.TrimSpace(c.String("schedule"))
+	if strings.EqualFold(schedule, "off") {
+		cfg.UnsetValue("cli", upgradeWindowKey)
+		if err := cfg.Save(c.Context); err != nil {
+			return cli.Exit(color.RedString("Unable to save upgrade schedule: %s", err), 1)
+		}
+		term.Writeln("Automatic upgrades are allowed at any time.")
+		return nil
+	}
+	if _, err := parseMaintenanceWindow(schedule); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	cfg.SetValue("cli", upgradeWindowKey, schedule)
+	if err := cfg.Save(c.Context); err != nil {
+		return cli.Exit(color.RedString("Unable to save upgrade schedule: %s", err), 1)
+	}
+	term.Printf("Automatic upgrades are allowed only during %s.\n", color.BlueString(schedule))
+	return nil
+}
@@ -0,0 +1,146 @@
+//+build !noautoupgrade
+
+package commands
+
+import (
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUpgradeWindowAllows(t *testing.T) {
+	// 2021-02-13 is a Saturday
+	saturday := func(hour, min int) time.Time {
+		return time.Date(2021, 2, 13, hour, min, 0, 0, time.UTC)
+	}
+	tests := map[string]struct {
+		window    string
+		at        time.Time
+		expected  bool
+		withError bool
+	}{
+		"weekend, saturday": {
+			window:   "sat-sun",
+			at:       saturday(12, 0),
+			expected: true,
+		},
+		"weekend, friday": {
+			window: "sat-sun",
+			at:     saturday(12, 0).AddDate(0, 0, -1),
+		},
+		"range wrapping the week": {
+			window:   "fri-mon",
+			at:       saturday(12, 0).AddDate(0, 0, 2),
+			expected: true,
+		},
+		"list of days": {
+			window:   "Mon,sat",
+			at:       saturday(12, 0),
+			expected: true,
+		},
+		"within hours": {
+			window:   "daily 02:00-06:00",
+			at:       saturday(5, 59),
+			expected: true,
+		},
+		"outside hours": {
+			window: "daily 02:00-06:00",
+			at:     saturday(6, 0),
+		},
+		"overnight window started on previous day": {
+			window:   "mon-fri 22:00-06:00",
+			at:       saturday(3, 0),
+			expected: true,
+		},
+		"overnight window not started on previous day": {
+			window: "mon-fri 22:00-06:00",
+			at:     saturday(3, 0).AddDate(0, 0, 1),
+		},
+		"overnight window, evening of a listed day": {
+			window:   "sat 22:00-06:00",
+			at:       saturday(23, 0),
+			expected: true,
+		},
+		"invalid day": {
+			window:    "weekend",
+			withError: true,
+		},
+		"invalid hours": {
+			window:    "sat 2-6",
+			withError: true,
+		},
+		"too many fields": {
+			window:    "sat 02:00 06:00",
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w, err := parseMaintenanceWindow(test.window)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, w.allows(test.at))
+		})
+	}
+}
+
+func TestCmdUpgradeSchedule(t *testing.T) {
+	tests := map[string]struct {
+		schedule  string
+		init      func(*mocked)
+		withError string
+	}{
+		"set schedule": {
+			schedule: "sat-sun",
+			init: func(m *mocked) {
+				m.cfg.On("SetValue", "cli", "upgrade-window", "sat-sun").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Automatic upgrades are allowed only during %s.\n", []interface{}{color.BlueString("sat-sun")}).Return().Once()
+			},
+		},
+		"turn schedule off": {
+			schedule: "off",
+			init: func(m *mocked) {
+				m.cfg.On("UnsetValue", "cli", "upgrade-window").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Writeln", []interface{}{"Automatic upgrades are allowed at any time."}).Return(0, nil).Once()
+			},
+		},
+		"invalid schedule": {
+			schedule:  "weekend",
+			init:      func(m *mocked) {},
+			withError: "invalid day",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil}
+			command := getUpgradeCommand()
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "upgrade", "--schedule", test.schedule)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
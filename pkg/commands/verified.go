@@ -0,0 +1,71 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+// verifiedOnlyKey is the config key which, when set to "true", allows installing only packages from verified publishers
+const verifiedOnlyKey = "verified-only"
+
+// verifiedBadge returns the label displayed next to packages from verified publishers
+func verifiedBadge() string {
+	return color.GreenString("[verified]")
+}
+
+// requireVerified returns an error if the verified-only policy is enabled and the repository does not belong
+// to a verified package in the registry
+func requireVerified(ctx context.Context, repo string) error {
+	val, ok := config.Get(ctx).GetValue("cli", verifiedOnlyKey)
+	if !ok || strings.TrimSpace(val) != "true" {
+		return nil
+	}
+
+	list, err := fetchCachedPackageList(ctx)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to verify package publisher: %s", err.Error()), 1)
+	}
+
+	if pkg := findRegistryPackage(list, repo); pkg != nil && pkg.Verified {
+		return nil
+	}
+	errorMsg := "Package " + repo + " is not published by a verified publisher"
+	log.FromContext(ctx).Error(errorMsg)
+	return cli.Exit(color.RedString("%s. To allow it, run \"%s config set cli.%s false\"", errorMsg, tools.Self(), verifiedOnlyKey), 1)
+}
+
+// findRegistryPackage returns the registry entry installed from given repository, or nil if there is none
+func findRegistryPackage(list *packageList, repo string) *packageListPackage {
+	repo = normalizeRepo(repo)
+	for i, pkg := range list.Packages {
+		if normalizeRepo(pkg.Name) == repo || (pkg.URL != "" && normalizeRepo(pkg.URL) == repo) {
+			return &list.Packages[i]
+		}
+	}
+	return nil
+}
+
+func normalizeRepo(repo string) string {
+	return strings.ToLower(strings.TrimSuffix(tools.Githubize(repo), ".git"))
+}
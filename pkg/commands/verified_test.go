@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireVerified(t *testing.T) {
+	tests := map[string]struct {
+		repo      string
+		response  string
+		init      func(*config.Mock)
+		withError string
+	}{
+		"policy disabled": {
+			repo: "https://github.com/akamai/cli-unknown.git",
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("", false).Once()
+			},
+		},
+		"verified package matched by name": {
+			repo:     "https://github.com/akamai/cli-property.git",
+			response: `{"packages": [{"name":"cli-property","verified":true}]}`,
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("true", true).Once()
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+		},
+		"verified package matched by url": {
+			repo:     "https://github.com/example/cli-custom.git",
+			response: `{"packages": [{"name":"custom","url":"https://github.com/example/cli-custom","verified":true}]}`,
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("true", true).Once()
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+		},
+		"package is not verified": {
+			repo:     "https://github.com/akamai/cli-property.git",
+			response: `{"packages": [{"name":"cli-property"}]}`,
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("true", true).Once()
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			withError: "Package https://github.com/akamai/cli-property.git is not published by a verified publisher",
+		},
+		"package is not in the registry": {
+			repo:     "https://github.com/akamai-typo/cli-property.git",
+			response: `{"packages": [{"name":"cli-property","verified":true}]}`,
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("true", true).Once()
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			withError: "is not published by a verified publisher",
+		},
+		"invalid package list": {
+			repo:     "https://github.com/akamai/cli-property.git",
+			response: `abc`,
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "verified-only").Return("true", true).Once()
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			withError: "Unable to verify package publisher",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := w.Write([]byte(test.response))
+				assert.NoError(t, err)
+			}))
+			defer srv.Close()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_PACKAGE_REPO", srv.URL))
+			m := &config.Mock{}
+			m.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false).Maybe()
+			m.On("GetValue", "cli", download.ProxyKey).Return("", false).Maybe()
+			m.On("GetValue", "cli", buildContainerKey).Return("", false).Maybe()
+			test.init(m)
+			err := requireVerified(config.Context(context.Background(), m), test.repo)
+			m.AssertExpectations(t)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/version"
+)
+
+const (
+	// webhookKey is the config key holding the URL which package install, update and uninstall events are posted to
+	webhookKey = "webhook-url"
+
+	webhookTimeout = 5 * time.Second
+)
+
+// package events posted to the webhook
+const (
+	packageEventInstall   = "install"
+	packageEventUpdate    = "update"
+	packageEventUninstall = "uninstall"
+)
+
+// packageEvent describes a change of an installed package, as posted to the webhook
+type packageEvent struct {
+	Event      string    `json:"event"`
+	Host       string    `json:"host"`
+	Package    string    `json:"package"`
+	OldVersion string    `json:"oldVersion,omitempty"`
+	NewVersion string    `json:"newVersion,omitempty"`
+	CLIVersion string    `json:"cliVersion"`
+	Trigger    string    `json:"trigger,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// notifyPackageEvent records the event in the event log and posts it to the configured webhook, if any
+// Delivery failures are only logged, as they should never fail the package operation itself
+func notifyPackageEvent(ctx context.Context, event, packageDir, oldVersion, newVersion string) {
+	recordEvent(ctx, logEvent{Event: event, Package: filepath.Base(packageDir), OldVersion: oldVersion, NewVersion: newVersion})
+	url, ok := config.Get(ctx).GetValue("cli", webhookKey)
+	url = strings.TrimSpace(url)
+	if !ok || url == "" {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	host, err := os.Hostname()
+	if err != nil {
+		logger.Debugf("Unable to read hostname: %s", err)
+	}
+	body, err := json.Marshal(packageEvent{
+		Event:      event,
+		Host:       host,
+		Package:    filepath.Base(packageDir),
+		OldVersion: oldVersion,
+		NewVersion: newVersion,
+		CLIVersion: version.Version,
+		Trigger:    updateTrigger(ctx),
+		Time:       time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Debugf("Unable to encode package event: %s", err)
+		return
+	}
+	if err := postWebhook(ctx, url, body); err != nil {
+		logger.Debugf("Unable to post package event to %s: %s", url, err)
+		return
+	}
+	logger.Debugf("Package event \"%s\" posted to %s", event, url)
+}
+
+func postWebhook(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "akamai-cli/"+version.Version)
+	// webhooks go through cli.proxy, like downloads and git operations
+	transport := download.ProxyTransport(ctx)
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport, Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// packageVersion returns the version of the first command of given package, which is the one packages are versioned by
+func packageVersion(pkg subcommands) string {
+	if len(pkg.Commands) == 0 {
+		return ""
+	}
+	return pkg.Commands[0].Version
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotifyPackageEvent(t *testing.T) {
+	tests := map[string]struct {
+		status   int
+		disabled bool
+		proxied  bool
+		posted   bool
+	}{
+		"event posted": {
+			status: http.StatusNoContent,
+			posted: true,
+		},
+		"endpoint error is ignored": {
+			status: http.StatusInternalServerError,
+			posted: true,
+		},
+		"event posted through the proxy": {
+			status:  http.StatusNoContent,
+			proxied: true,
+			posted:  true,
+		},
+		"webhook not configured": {
+			disabled: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var event *packageEvent
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				if test.proxied {
+					assert.Equal(t, "webhook.example.com", r.URL.Host)
+				}
+				event = &packageEvent{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(event))
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", eventLogKey).Return("false", true)
+			switch {
+			case test.disabled:
+				cfg.On("GetValue", "cli", "webhook-url").Return("", false).Once()
+			case test.proxied:
+				cfg.On("GetValue", "cli", "webhook-url").Return("http://webhook.example.com/events", true).Once()
+				cfg.On("GetValue", "cli", download.ProxyKey).Return(srv.URL, true).Once()
+			default:
+				cfg.On("GetValue", "cli", "webhook-url").Return(srv.URL, true).Once()
+				cfg.On("GetValue", "cli", download.ProxyKey).Return("", false).Once()
+			}
+			ctx := config.Context(context.Background(), cfg)
+
+			notifyPackageEvent(ctx, packageEventUpdate, "testdata/.akamai-cli/src/cli-echo", "1.0.0", "1.1.0")
+
+			cfg.AssertExpectations(t)
+			if !test.posted {
+				assert.Nil(t, event)
+				return
+			}
+			require.NotNil(t, event)
+			assert.Equal(t, packageEventUpdate, event.Event)
+			assert.Equal(t, "cli-echo", event.Package)
+			assert.Equal(t, "1.0.0", event.OldVersion)
+			assert.Equal(t, "1.1.0", event.NewVersion)
+			assert.NotEmpty(t, event.Host)
+		})
+	}
+}
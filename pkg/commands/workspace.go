@@ -0,0 +1,151 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// workspaceDir is the directory declaring a project workspace, searched for in the working directory of a command and its parents
+	workspaceDir = ".akamai"
+	// workspaceFile holds the credentials commands run inside the workspace use by default
+	workspaceFile = "workspace.json"
+	// noWorkspaceEnv is the environment variable which, when set to "true", disables workspace defaults, as "run --no-workspace" does
+	noWorkspaceEnv = "AKAMAI_CLI_NO_WORKSPACE"
+)
+
+// workspace holds the defaults declared in the workspace.json file of a project workspace.
+// Edgerc is relative to the directory containing the .akamai directory.
+type workspace struct {
+	Edgerc     string `json:"edgerc,omitempty"`
+	Section    string `json:"section,omitempty"`
+	AccountKey string `json:"accountKey,omitempty"`
+
+	root string
+}
+
+// findWorkspace returns the workspace which dir belongs to, nil if there is none.
+// The search stops at the home directory, whose .akamai directory is not a workspace.
+func findWorkspace(dir string) (*workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	home, _ := os.UserHomeDir()
+	for ; dir != home; dir = filepath.Dir(dir) {
+		path := filepath.Join(dir, workspaceDir, workspaceFile)
+		data, err := ioutil.ReadFile(path)
+		if err == nil {
+			ws := &workspace{root: dir}
+			if err := json.Unmarshal(data, ws); err != nil {
+				return nil, fmt.Errorf("invalid workspace file %s: %w", path, err)
+			}
+			if ws.Edgerc != "" && !filepath.IsAbs(ws.Edgerc) && !strings.HasPrefix(ws.Edgerc, "~") {
+				ws.Edgerc = filepath.Join(dir, ws.Edgerc)
+			}
+			return ws, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// workspaceDisabled returns true if workspace defaults were turned off with AKAMAI_CLI_NO_WORKSPACE
+func workspaceDisabled() bool {
+	disabled, err := strconv.ParseBool(os.Getenv(noWorkspaceEnv))
+	return err == nil && disabled
+}
+
+// applyWorkspace sets the .edgerc file, section and account switch key declared by the workspace of the command working
+// directory in the command environment. Values given to the command in its arguments or environment take precedence.
+// The values applied are described for the user in d.workspaceApplied.
+func applyWorkspace(ctx context.Context, d *packageDispatch) error {
+	if workspaceDisabled() {
+		return nil
+	}
+	dir := d.Dir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+	ws, err := findWorkspace(dir)
+	if err != nil || ws == nil {
+		return err
+	}
+	d.Workspace = ws.root
+
+	logger := log.FromContext(ctx)
+	for _, setting := range []struct {
+		name, flag, env, value string
+	}{
+		{"section", "--section", "AKAMAI_EDGERC_SECTION", ws.Section},
+		{"account key", "--accountkey", "AKAMAI_EDGERC_ACCOUNT_KEY", ws.AccountKey},
+		{"edgerc", "--edgerc", "AKAMAI_EDGERC", ws.Edgerc},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		if _, ok := argValue(d.Args, setting.flag); ok || os.Getenv(setting.env) != "" {
+			logger.Debugf("Workspace %s %s overridden by %s", setting.name, setting.value, setting.flag)
+			continue
+		}
+		d.Env[setting.env] = setting.value
+		d.workspaceApplied = append(d.workspaceApplied, fmt.Sprintf("%s %s", setting.name, setting.value))
+	}
+	return nil
+}
+
+// printWorkspace tells the user which credentials of a workspace a command runs with, unless status lines are hidden
+func printWorkspace(ctx context.Context, d *packageDispatch) {
+	if len(d.workspaceApplied) == 0 || terminal.GetVerbosity(ctx) <= terminal.VerbosityQuiet {
+		return
+	}
+	terminal.Get(ctx).WriteErrorf("%s\n", color.CyanString("Using %s of workspace %s, pass other values to the command or use \"%s run --no-workspace\" to override them",
+		strings.Join(d.workspaceApplied, ", "), d.Workspace, tools.Self()))
+}
+
+// argValue returns the value of a flag in the arguments of an installed command, given either as "--flag value" or "--flag=value"
+func argValue(args []string, flag string) (string, bool) {
+	for i, arg := range args {
+		switch {
+		case arg == flag && i+1 < len(args):
+			return args[i+1], true
+		case strings.HasPrefix(arg, flag+"="):
+			return strings.TrimPrefix(arg, flag+"="), true
+		}
+	}
+	return "", false
+}
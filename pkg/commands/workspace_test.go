@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyWorkspace(t *testing.T) {
+	tests := map[string]struct {
+		workspace string
+		args      []string
+		env       map[string]string
+		expected  map[string]string
+		applied   []string
+		withError string
+	}{
+		"workspace defaults": {
+			workspace: `{"section": "staging", "accountKey": "1-ABCD", "edgerc": ".edgerc"}`,
+			expected:  map[string]string{"AKAMAI_EDGERC_SECTION": "staging", "AKAMAI_EDGERC_ACCOUNT_KEY": "1-ABCD", "AKAMAI_EDGERC": "{{root}}" + string(filepath.Separator) + ".edgerc"},
+			applied:   []string{"section staging", "account key 1-ABCD", "edgerc {{root}}" + string(filepath.Separator) + ".edgerc"},
+		},
+		"section given to the command": {
+			workspace: `{"section": "staging", "accountKey": "1-ABCD"}`,
+			args:      []string{"list", "--section", "production"},
+			expected:  map[string]string{"AKAMAI_EDGERC_ACCOUNT_KEY": "1-ABCD"},
+			applied:   []string{"account key 1-ABCD"},
+		},
+		"section in flag with value": {
+			workspace: `{"section": "staging"}`,
+			args:      []string{"--section=production"},
+			expected:  map[string]string{},
+		},
+		"section in the environment": {
+			workspace: `{"section": "staging"}`,
+			env:       map[string]string{"AKAMAI_EDGERC_SECTION": "production"},
+			expected:  map[string]string{},
+		},
+		"workspace disabled": {
+			workspace: `{"section": "staging"}`,
+			env:       map[string]string{noWorkspaceEnv: "true"},
+			expected:  map[string]string{},
+		},
+		"no workspace": {
+			expected: map[string]string{},
+		},
+		"invalid workspace": {
+			workspace: `{"section": `,
+			withError: "invalid workspace file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "cli-workspace")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(root))
+			}()
+			for key, val := range test.env {
+				require.NoError(t, os.Setenv(key, val))
+			}
+			defer func() {
+				for key := range test.env {
+					require.NoError(t, os.Unsetenv(key))
+				}
+			}()
+			if test.workspace != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, workspaceDir), 0755))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(root, workspaceDir, workspaceFile), []byte(test.workspace), 0644))
+			}
+			dir := filepath.Join(root, "property", "rules")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+
+			d := &packageDispatch{Args: append([]string{"akamai-property"}, test.args...), Dir: dir, Env: map[string]string{}}
+			err = applyWorkspace(context.Background(), d)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			// paths of the workspace are resolved against its root
+			for key, val := range test.expected {
+				test.expected[key] = strings.ReplaceAll(val, "{{root}}", root)
+			}
+			assert.Equal(t, test.expected, d.Env)
+			for i, val := range test.applied {
+				test.applied[i] = strings.ReplaceAll(val, "{{root}}", root)
+			}
+			assert.Equal(t, test.applied, d.workspaceApplied)
+			if test.workspace != "" && test.env[noWorkspaceEnv] == "" {
+				assert.Equal(t, root, d.Workspace)
+			} else {
+				assert.Empty(t, d.Workspace)
+			}
+		})
+	}
+}
+
+func TestPrintWorkspace(t *testing.T) {
+	term := &terminal.Mock{}
+	term.On("WriteErrorf", "%s\n", []interface{}{color.CyanString("Using section staging of workspace /project, pass other values to the command or use \"commands.test run --no-workspace\" to override them")}).Return().Once()
+	ctx := terminal.Context(context.Background(), term)
+	d := &packageDispatch{Workspace: "/project", workspaceApplied: []string{"section staging"}}
+	printWorkspace(ctx, d)
+	printWorkspace(terminal.WithVerbosity(ctx, terminal.VerbosityQuiet), d)
+	printWorkspace(ctx, &packageDispatch{})
+	term.AssertExpectations(t)
+}
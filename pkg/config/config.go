@@ -15,6 +15,7 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/akamai/cli/pkg/log"
@@ -49,6 +50,9 @@ type (
 	IniConfig struct {
 		path string
 		file *ini.File
+
+		// loaded holds the values read from the file, used to find which settings were changed before saving
+		loaded map[string]map[string]string
 	}
 
 	contextType string
@@ -70,7 +74,7 @@ func NewIni() (*IniConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &IniConfig{path: path, file: iniFile}, nil
+	return &IniConfig{path: path, file: iniFile, loaded: sectionValues(iniFile)}, nil
 }
 
 // Context sets the config in the context
@@ -89,9 +93,11 @@ func Get(ctx context.Context) Config {
 }
 
 // Save stores the ini file in filesystem
+// Settings changed since the file was loaded are merged into its current contents, so that changes saved
+// in the meantime by other processes are kept. The file is locked while saving and replaced atomically.
 func (c *IniConfig) Save(ctx context.Context) error {
 	term := terminal.Get(ctx)
-	if err := c.file.SaveTo(c.path); err != nil {
+	if err := c.save(); err != nil {
 		term.Writeln(err.Error())
 		log.FromContext(ctx).Error(err.Error())
 		return err
@@ -100,10 +106,54 @@ func (c *IniConfig) Save(ctx context.Context) error {
 	return nil
 }
 
+func (c *IniConfig) save() error {
+	unlock, err := lockFile(c.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file := ini.Empty()
+	if _, err := os.Stat(c.path); err == nil {
+		if file, err = ini.Load(c.path); err != nil {
+			return err
+		}
+	}
+	mergeChanges(file, c.loaded, sectionValues(c.file))
+	buf := &bytes.Buffer{}
+	if _, err := file.WriteTo(buf); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.path, buf.Bytes()); err != nil {
+		return err
+	}
+	c.file = file
+	c.loaded = sectionValues(file)
+	return nil
+}
+
 // Values returns a map containing sections from the config. Each section contans a key-value map of its contents
 func (c *IniConfig) Values() map[string]map[string]string {
+	return sectionValues(c.file)
+}
+
+// FilePath returns the location of the config file
+func FilePath() (string, error) {
+	return getConfigFilePath()
+}
+
+// ParseValues parses config file contents and returns its sections in the same format as Values
+func ParseValues(data []byte) (map[string]map[string]string, error) {
+	file, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	return sectionValues(file), nil
+}
+
+func sectionValues(file *ini.File) map[string]map[string]string {
 	sections := make(map[string]map[string]string)
-	for _, section := range c.file.Sections() {
+	for _, section := range file.Sections() {
 		values := make(map[string]string)
 		for _, key := range section.Keys() {
 			values[key.Name()] = key.String()
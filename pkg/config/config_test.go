@@ -9,7 +9,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewIni(t *testing.T) {
@@ -67,6 +70,119 @@ func TestSave(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSaveMergesConcurrentChanges(t *testing.T) {
+	dir, err := ioutil.TempDir(".", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+	defer func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+	}()
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+
+	initial, err := NewIni()
+	require.NoError(t, err)
+	initial.SetValue("cli", "last-ping", "never")
+	initial.SetValue("cli", "cache-path", "/tmp")
+	require.NoError(t, initial.Save(ctx))
+
+	first, err := NewIni()
+	require.NoError(t, err)
+	second, err := NewIni()
+	require.NoError(t, err)
+
+	first.SetValue("cli", "last-ping", "2021-02-10T11:55:26+01:00")
+	second.SetValue("cli", "auto-install", "true")
+	second.UnsetValue("cli", "cache-path")
+	require.NoError(t, first.Save(ctx))
+	require.NoError(t, second.Save(ctx))
+
+	saved, err := NewIni()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"last-ping":    "2021-02-10T11:55:26+01:00",
+		"auto-install": "true",
+	}, saved.Values()["cli"])
+	val, _ := second.GetValue("cli", "last-ping")
+	assert.Equal(t, "2021-02-10T11:55:26+01:00", val)
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, ".akamai-cli"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "config", files[0].Name())
+}
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.lock")
+
+	unlock, err := lockFile(path)
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+	unlock()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// stale lock left by a crashed process is removed
+	require.NoError(t, ioutil.WriteFile(path, []byte("1"), 0600))
+	old := time.Now().Add(-2 * staleLockAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+	unlock, err = lockFile(path)
+	require.NoError(t, err)
+	unlock()
+
+	// a lock replaced by another process is not removed on release
+	unlock, err = lockFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, ioutil.WriteFile(path, []byte("1"), 0600))
+	unlock()
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+}
+
+func TestLockFileStaleConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.lock")
+	require.NoError(t, ioutil.WriteFile(path, []byte("1"), 0600))
+	old := time.Now().Add(-2 * staleLockAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	var holders, maxHolders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lockFile(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				max := atomic.LoadInt32(&maxHolders)
+				if n <= max || atomic.CompareAndSwapInt32(&maxHolders, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), maxHolders)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
 func TestContext(t *testing.T) {
 	cfg := IniConfig{
 		path: "test",
@@ -340,3 +456,34 @@ func TestExportConfigEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestParseValues(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected map[string]map[string]string
+		wantErr  bool
+	}{
+		"valid config": {
+			data: "[cli]\nconfig-version = 1.1\n",
+			expected: map[string]map[string]string{
+				"DEFAULT": {},
+				"cli":     {"config-version": "1.1"},
+			},
+		},
+		"invalid config": {
+			data:    "[cli\nconfig-version = 1.1\n",
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			vals, err := ParseValues([]byte(test.data))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, vals)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+
+	// staleLockAge is the age after which a lock file left behind by a crashed process is removed
+	staleLockAge = 30 * time.Second
+)
+
+// ErrLocked is returned when the config file is locked by another process for longer than lockTimeout
+var ErrLocked = errors.New("config file is locked by another process")
+
+// lockFile creates the lock file at given path, waiting until other processes release it.
+// The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			token := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+			_, err = f.WriteString(token)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, err
+			}
+			// a process which found the previous lock stale may have replaced this one in the meantime
+			if ownsLock(path, token) {
+				return func() {
+					if ownsLock(path, token) {
+						_ = os.Remove(path)
+					}
+				}, nil
+			}
+			continue
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if removeStaleLock(path) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// LockFile locks the file at given path against other processes of Akamai CLI, until the returned function is called
+func LockFile(path string) (func(), error) {
+	return lockFile(path + ".lock")
+}
+
+// ownsLock returns true if the lock file at given path still holds the token written by its creator
+func ownsLock(path, token string) bool {
+	data, err := ioutil.ReadFile(path)
+	return err == nil && string(data) == token
+}
+
+// removeStaleLock removes the lock file at given path if it is older than staleLockAge, returning true if it did.
+// The lock is moved aside under a unique name before it is removed, so that a fresh lock created by another process,
+// which found the same lock stale first, is never removed instead; such a lock is moved back.
+func removeStaleLock(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) <= staleLockAge {
+		return false
+	}
+	stale, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	aside := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		return false
+	}
+	defer func() {
+		_ = os.Remove(aside)
+	}()
+	if moved, err := ioutil.ReadFile(aside); err == nil && string(moved) != string(stale) {
+		_ = os.Link(aside, path)
+		return false
+	}
+	return true
+}
+
+// mergeChanges applies the settings which differ between loaded and current to file,
+// leaving settings changed in file by other processes intact
+func mergeChanges(file *ini.File, loaded, current map[string]map[string]string) {
+	for section, keys := range current {
+		for key, value := range keys {
+			if old, ok := loaded[section][key]; !ok || old != value {
+				file.Section(section).Key(key).SetValue(value)
+			}
+		}
+	}
+	for section, keys := range loaded {
+		for key := range keys {
+			if _, ok := current[section][key]; !ok {
+				file.Section(section).DeleteKey(key)
+			}
+		}
+	}
+}
+
+// WriteFile replaces the config file at given path with data, holding the config lock
+func WriteFile(path string, data []byte) error {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path,
+// so that readers never see a partially written config
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
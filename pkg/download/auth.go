@@ -0,0 +1,63 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type hostTokensContextType string
+
+var hostTokensContext hostTokensContextType = "host-tokens"
+
+// WithHostToken returns a context in which requests to host sent through AuthTransport, and git remotes on host,
+// are authenticated with token
+func WithHostToken(ctx context.Context, host, token string) context.Context {
+	tokens := make(map[string]string)
+	if current, ok := ctx.Value(hostTokensContext).(map[string]string); ok {
+		for h, t := range current {
+			tokens[h] = t
+		}
+	}
+	tokens[strings.ToLower(host)] = token
+	return context.WithValue(ctx, hostTokensContext, tokens)
+}
+
+// HostToken returns the token set for host in the context, empty if there is none
+func HostToken(ctx context.Context, host string) string {
+	tokens, _ := ctx.Value(hostTokensContext).(map[string]string)
+	return tokens[strings.ToLower(host)]
+}
+
+// AuthTransport wraps the transport so that HTTPS requests to hosts with a token in the context carry it as a bearer token,
+// unless they are authorized already. Redirects to other hosts are not authenticated with the token of the first host.
+// A nil base is replaced with the transport going through the configured proxy.
+func AuthTransport(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = ProxyTransport(ctx)
+	}
+	if !hasHostTokens(ctx) {
+		return base
+	}
+	return &authTransport{ctx: ctx, base: base}
+}
+
+func hasHostTokens(ctx context.Context) bool {
+	_, ok := ctx.Value(hostTokensContext).(map[string]string)
+	return ok
+}
+
+type authTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := HostToken(t.ctx, req.URL.Hostname())
+	if token == "" || req.URL.Scheme != "https" || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,51 @@
+package download
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+type recordingTransport struct {
+	authorization string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.authorization = req.Header.Get("Authorization")
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestAuthTransport(t *testing.T) {
+	ctx := WithHostToken(WithHostToken(context.Background(), "GitHub.com", "github_pat_abc"), "api.github.com", "github_pat_abc")
+	tests := map[string]struct {
+		ctx           context.Context
+		url           string
+		authorization string
+		expected      string
+	}{
+		"host with a token":          {ctx: ctx, url: "https://github.com/akamai/cli/releases/download/1.0.0/akamai", expected: "Bearer github_pat_abc"},
+		"another host with a token":  {ctx: ctx, url: "https://api.github.com/rate_limit", expected: "Bearer github_pat_abc"},
+		"host without a token":       {ctx: ctx, url: "https://example.com/akamai"},
+		"plain HTTP":                 {ctx: ctx, url: "http://github.com/akamai/cli"},
+		"request authorized already": {ctx: ctx, url: "https://github.com/akamai/cli", authorization: "Basic abc", expected: "Basic abc"},
+		"no tokens":                  {ctx: context.Background(), url: "https://github.com/akamai/cli"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			base := &recordingTransport{}
+			req, err := http.NewRequest(http.MethodGet, test.url, nil)
+			require.NoError(t, err)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			_, err = AuthTransport(test.ctx, base).RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, base.authorization)
+			assert.Equal(t, test.authorization, req.Header.Get("Authorization"), "the original request is not modified")
+		})
+	}
+	assert.Equal(t, "github_pat_abc", HostToken(ctx, "github.com"))
+	assert.Empty(t, HostToken(context.Background(), "github.com"))
+}
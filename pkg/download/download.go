@@ -0,0 +1,397 @@
+// Package download fetches large files over HTTP.
+//
+// Files are downloaded next to their destination, in parts which are kept when a download fails,
+// so that the next attempt continues where the previous one stopped using HTTP range requests.
+// Servers supporting range requests are asked for several parts of big files in parallel.
+// The checksum of the file is computed while it is written, so it is verified without reading the file again.
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+const (
+	// DefaultChunks is the number of parts of a file downloaded in parallel
+	DefaultChunks = 4
+
+	// MinChunkSize is the smallest part of a file downloaded separately, smaller files are downloaded in one request
+	MinChunkSize = 4 << 20
+)
+
+var (
+	// ErrChecksum is returned when the downloaded file does not match the expected checksum
+	ErrChecksum = errors.New("checksum mismatch")
+
+	errRangeIgnored = errors.New("server ignored the range request")
+)
+
+type (
+	// Options of a download
+	Options struct {
+		// Client sending the requests, http.DefaultClient if nil
+		Client *http.Client
+		// Checksum is the expected SHA-256 sum of the file, it is not verified if empty
+		Checksum []byte
+		// Chunks is the maximum number of parts downloaded in parallel, DefaultChunks if not set
+		Chunks int
+		// Limiter caps the download rate of all parts together, the rate is not limited if nil
+		Limiter *Limiter
+	}
+
+	// state describes the parts of an unfinished download, it is used to decide if they can be resumed
+	state struct {
+		URL       string `json:"url"`
+		Validator string `json:"validator,omitempty"`
+		Size      int64  `json:"size"`
+		Chunks    int    `json:"chunks"`
+	}
+
+	// chunk is a range of bytes of the file, end is -1 if the size of the file is unknown
+	chunk struct {
+		path       string
+		start, end int64
+	}
+)
+
+// File downloads the resource at url to path, resuming an earlier download of the same resource if one was interrupted
+func File(ctx context.Context, url, path string, opts Options) error {
+	logger := log.FromContext(ctx)
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if hasHostTokens(ctx) {
+		authorized := *client
+		authorized.Transport = client.Transport
+		if authorized.Transport == nil {
+			authorized.Transport = http.DefaultTransport
+		}
+		authorized.Transport = AuthTransport(ctx, authorized.Transport)
+		client = &authorized
+	}
+	if opts.Limiter != nil {
+		limited := *client
+		limited.Transport = opts.Limiter.Transport(client.Transport)
+		client = &limited
+	}
+	maxChunks := opts.Chunks
+	if maxChunks < 1 {
+		maxChunks = DefaultChunks
+	}
+
+	remote := probe(ctx, client, url)
+	statePath := path + ".part.json"
+	if previous, err := readState(statePath); err == nil && previous.resumable(remote) {
+		logger.Debugf("Resuming download of %s", url)
+		remote.Chunks = previous.Chunks
+	} else {
+		if err == nil {
+			logger.Debugf("Discarding partial download of %s, the remote file has changed", url)
+			removeParts(path, previous.Chunks)
+		}
+		removeParts(path, 1)
+		remote.Chunks = chunkCount(remote, maxChunks)
+		if err := writeState(statePath, remote); err != nil {
+			return err
+		}
+	}
+
+	chunks := splitChunks(path, remote)
+	var sum hash.Hash
+	if len(chunks) == 1 {
+		// the only part is hashed while it is downloaded
+		sum = sha256.New()
+	}
+	if err := fetchChunks(ctx, client, url, remote, chunks, sum); err != nil {
+		if errors.Is(err, errRangeIgnored) {
+			removeParts(path, remote.Chunks)
+			_ = os.Remove(statePath)
+		}
+		return err
+	}
+
+	if sum != nil {
+		if err := os.Rename(chunks[0].path, path); err != nil {
+			return err
+		}
+	} else {
+		joined, err := joinChunks(path, chunks)
+		if err != nil {
+			return err
+		}
+		sum = joined
+	}
+	_ = os.Remove(statePath)
+	if len(opts.Checksum) > 0 && !bytes.Equal(sum.Sum(nil), opts.Checksum) {
+		_ = os.Remove(path)
+		return fmt.Errorf("%w: expected %x, got %x", ErrChecksum, opts.Checksum, sum.Sum(nil))
+	}
+	logger.Debugf("Downloaded %s to %s in %d part(s)", url, path, len(chunks))
+	return nil
+}
+
+// probe returns the size and validator of the remote file, if the server supports range requests
+func probe(ctx context.Context, client *http.Client, url string) state {
+	remote := state{URL: url, Size: -1}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return remote
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return remote
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return remote
+	}
+	remote.Size = resp.ContentLength
+	if resp.Header.Get("Accept-Ranges") != "bytes" || remote.Size < 0 {
+		return remote
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		remote.Validator = etag
+	} else {
+		remote.Validator = resp.Header.Get("Last-Modified")
+	}
+	return remote
+}
+
+// resumable checks if parts downloaded earlier belong to the same version of the remote file
+// Without a validator there is no way to tell, so such downloads always start from the beginning
+func (s state) resumable(remote state) bool {
+	return s.Validator != "" && s.URL == remote.URL && s.Validator == remote.Validator && s.Size == remote.Size && s.Chunks >= 1
+}
+
+// chunkCount returns the number of parts of the file downloaded in parallel
+func chunkCount(remote state, maxChunks int) int {
+	// ranges are useless without a validator, the parts could come from different versions of the file
+	if remote.Validator == "" || remote.Size < 2*MinChunkSize {
+		return 1
+	}
+	if chunks := int(remote.Size / MinChunkSize); chunks < maxChunks {
+		return chunks
+	}
+	return maxChunks
+}
+
+func splitChunks(path string, remote state) []chunk {
+	if remote.Chunks == 1 {
+		return []chunk{{path: partPath(path, 0), end: remote.Size - 1}}
+	}
+	size := remote.Size / int64(remote.Chunks)
+	chunks := make([]chunk, remote.Chunks)
+	for i := range chunks {
+		chunks[i] = chunk{path: partPath(path, i), start: int64(i) * size, end: int64(i+1)*size - 1}
+	}
+	chunks[len(chunks)-1].end = remote.Size - 1
+	return chunks
+}
+
+// fetchChunks downloads all chunks in parallel, the first error cancels the remaining requests
+func fetchChunks(ctx context.Context, client *http.Client, url string, remote state, chunks []chunk, sum hash.Hash) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, c := range chunks {
+		wg.Add(1)
+		go func(c chunk) {
+			defer wg.Done()
+			if err := fetchChunk(ctx, client, url, remote, c, sum); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(c)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// fetchChunk downloads the missing bytes of a chunk, appending them to bytes downloaded by earlier attempts
+func fetchChunk(ctx context.Context, client *http.Client, url string, remote state, c chunk, sum hash.Hash) error {
+	f, err := os.OpenFile(c.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.FromContext(ctx).Errorf("Error closing file: %s", err)
+		}
+	}()
+	have, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if c.end >= 0 && have > c.end-c.start+1 {
+		if have, err = truncate(f, 0); err != nil {
+			return err
+		}
+	}
+	if sum != nil && have > 0 {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(sum, f, have); err != nil {
+			return err
+		}
+	}
+	if c.end >= 0 && have == c.end-c.start+1 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	ranged := have > 0 || c.start > 0 || (c.end >= 0 && c.end < remote.Size-1)
+	if ranged {
+		req.Header.Set("Range", rangeHeader(c.start+have, c.end))
+		req.Header.Set("If-Range", remote.Validator)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.FromContext(ctx).Errorf("Error closing response body: %s", err)
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && ranged:
+	case resp.StatusCode == http.StatusOK && c.start == 0 && c.end == remote.Size-1:
+		// the whole file is sent again, e.g. because it changed since the previous attempt
+		if have > 0 {
+			log.FromContext(ctx).Debugf("Server does not resume the download of %s, starting from the beginning", url)
+			if sum != nil {
+				sum.Reset()
+			}
+			if _, err := truncate(f, 0); err != nil {
+				return err
+			}
+		}
+	case resp.StatusCode == http.StatusOK:
+		return errRangeIgnored
+	default:
+		return fmt.Errorf("invalid response status while downloading %s: %d", url, resp.StatusCode)
+	}
+
+	var w io.Writer = f
+	if sum != nil {
+		w = io.MultiWriter(f, sum)
+	}
+	if c.end < 0 {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	missing := c.end - c.start + 1 - have
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	n, err := io.CopyN(w, resp.Body, missing)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if n < missing {
+		return fmt.Errorf("download of %s interrupted: %w", url, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// joinChunks writes the downloaded chunks to path in order and returns their checksum
+func joinChunks(path string, chunks []chunk) (hash.Hash, error) {
+	sum := sha256.New()
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chunks {
+		if err := appendFile(io.MultiWriter(out, sum), c.path); err != nil {
+			_ = out.Close()
+			return nil, err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	for _, c := range chunks {
+		_ = os.Remove(c.path)
+	}
+	return sum, nil
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func truncate(f *os.File, size int64) (int64, error) {
+	if err := f.Truncate(size); err != nil {
+		return 0, err
+	}
+	return f.Seek(size, io.SeekStart)
+}
+
+func rangeHeader(start, end int64) string {
+	if end < 0 {
+		return fmt.Sprintf("bytes=%d-", start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", start, end)
+}
+
+func partPath(path string, i int) string {
+	return path + ".part" + strconv.Itoa(i)
+}
+
+func removeParts(path string, chunks int) {
+	for i := 0; i < chunks; i++ {
+		_ = os.Remove(partPath(path, i))
+	}
+}
+
+func readState(path string) (*state, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func writeState(path string, s state) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
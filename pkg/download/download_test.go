@@ -0,0 +1,160 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	small := []byte("binary file")
+	big := bytes.Repeat([]byte("0123456789abcdef"), (2*MinChunkSize+100)/16)
+	tests := map[string]struct {
+		content        []byte
+		etag           string
+		noRanges       bool
+		checksum       []byte
+		partial        []byte
+		state          *state
+		withError      error
+		expectRequests []string
+	}{
+		"single request": {
+			content:        small,
+			etag:           `"v1"`,
+			checksum:       sha256Sum(small),
+			expectRequests: []string{"HEAD", "GET"},
+		},
+		"parallel chunks": {
+			content:        big,
+			etag:           `"v1"`,
+			checksum:       sha256Sum(big),
+			expectRequests: []string{"HEAD", "GET bytes=0-4194351", "GET bytes=4194352-8388703"},
+		},
+		"resume interrupted download": {
+			content:        small,
+			etag:           `"v1"`,
+			checksum:       sha256Sum(small),
+			partial:        small[:6],
+			state:          &state{Validator: `"v1"`, Size: int64(len(small)), Chunks: 1},
+			expectRequests: []string{"HEAD", "GET bytes=6-10"},
+		},
+		"remote file changed": {
+			content:        small,
+			etag:           `"v2"`,
+			checksum:       sha256Sum(small),
+			partial:        []byte("old fi"),
+			state:          &state{Validator: `"v1"`, Size: int64(len(small)), Chunks: 1},
+			expectRequests: []string{"HEAD", "GET"},
+		},
+		"no range support": {
+			content:        small,
+			noRanges:       true,
+			checksum:       sha256Sum(small),
+			partial:        []byte("old fi"),
+			state:          &state{Size: int64(len(small)), Chunks: 1},
+			expectRequests: []string{"HEAD", "GET"},
+		},
+		"checksum mismatch": {
+			content:        small,
+			etag:           `"v1"`,
+			checksum:       sha256Sum([]byte("other file")),
+			withError:      ErrChecksum,
+			expectRequests: []string{"HEAD", "GET"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				lock     sync.Mutex
+				requests []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				requests = append(requests, strings.TrimSpace(r.Method+" "+r.Header.Get("Range")))
+				lock.Unlock()
+				if test.noRanges {
+					_, err := w.Write(test.content)
+					assert.NoError(t, err)
+					return
+				}
+				w.Header().Set("ETag", test.etag)
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(test.content))
+			}))
+			defer srv.Close()
+			dir, err := ioutil.TempDir("", "download")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+			path := filepath.Join(dir, "akamai-test")
+			if test.state != nil {
+				test.state.URL = srv.URL + "/bin"
+				require.NoError(t, writeState(path+".part.json", *test.state))
+				require.NoError(t, ioutil.WriteFile(partPath(path, 0), test.partial, 0644))
+			}
+
+			err = File(context.Background(), srv.URL+"/bin", path, Options{Checksum: test.checksum})
+			assert.ElementsMatch(t, test.expectRequests, requests)
+			files, _ := filepath.Glob(path + ".part*")
+			assert.Empty(t, files)
+			if test.withError != nil {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, test.withError), err.Error())
+				assert.NoFileExists(t, path)
+				return
+			}
+			require.NoError(t, err)
+			content, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, test.content, content)
+		})
+	}
+}
+
+func TestFileKeepsPartsOfFailedDownload(t *testing.T) {
+	content := []byte("binary file")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", "11")
+		if r.Method == http.MethodGet {
+			_, err := w.Write(content[:6])
+			assert.NoError(t, err)
+		}
+	}))
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "download")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "akamai-test")
+
+	err = File(context.Background(), srv.URL+"/bin", path, Options{})
+	require.Error(t, err)
+	partial, err := ioutil.ReadFile(partPath(path, 0))
+	require.NoError(t, err)
+	assert.Equal(t, content[:6], partial)
+	s, err := readState(path + ".part.json")
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, s.Validator)
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
@@ -0,0 +1,171 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// BandwidthLimitKey is the config key holding the maximum download rate in bytes per second, e.g. "500k" or "2M".
+// The limit is shared by all downloads of the process, including clones and update checks, so that parallel
+// downloads do not exceed it together. An empty value or "0" disables the limit.
+const BandwidthLimitKey = "bandwidth-limit"
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[int64]*Limiter)
+)
+
+type (
+	// Limiter caps the rate at which response bodies are read
+	Limiter struct {
+		rate int64
+		mu   sync.Mutex
+		next time.Time
+	}
+
+	limitedTransport struct {
+		limiter *Limiter
+		base    http.RoundTripper
+	}
+
+	limitedBody struct {
+		io.ReadCloser
+		ctx     context.Context
+		limiter *Limiter
+	}
+)
+
+// NewLimiter returns a limiter allowing given number of bytes per second
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	return &Limiter{rate: bytesPerSecond}
+}
+
+// ConfiguredLimiter returns the limiter shared by downloads for the rate set in cli.bandwidth-limit, nil if there is no limit
+func ConfiguredLimiter(ctx context.Context) *Limiter {
+	val, ok := config.Get(ctx).GetValue("cli", BandwidthLimitKey)
+	if !ok || strings.TrimSpace(val) == "" {
+		return nil
+	}
+	rate, err := ParseRate(val)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", BandwidthLimitKey, err)
+		return nil
+	}
+	if rate == 0 {
+		return nil
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if _, ok := limiters[rate]; !ok {
+		limiters[rate] = NewLimiter(rate)
+	}
+	return limiters[rate]
+}
+
+// LimitTransport wraps the transport so that response bodies are read no faster than cli.bandwidth-limit allows,
+// and requests are authenticated with the host tokens of the context.
+// A nil base is replaced with the transport going through the configured proxy.
+func LimitTransport(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	return ConfiguredLimiter(ctx).Transport(AuthTransport(ctx, base))
+}
+
+// ParseRate parses a rate in bytes per second with an optional k, M or G suffix, multiples of 1024
+func ParseRate(s string) (int64, error) {
+	val := strings.ToUpper(strings.TrimSpace(s))
+	val = strings.TrimSuffix(strings.TrimSuffix(val, "/S"), "B")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(val, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(val, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(val, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		val = val[:len(val)-1]
+	}
+	num, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("expected a rate in bytes per second such as 500k or 2M, got %q", s)
+	}
+	return int64(num * float64(multiplier)), nil
+}
+
+// Transport returns a transport reading response bodies through the limiter, base itself if the limiter is nil
+func (l *Limiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if l == nil {
+		return base
+	}
+	return &limitedTransport{limiter: l, base: base}
+}
+
+// Reader returns a reader of r which waits as long as needed to keep to the rate of the limiter
+func (l *Limiter) Reader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	return &limitedBody{ReadCloser: r, ctx: ctx, limiter: l}
+}
+
+// wait reserves n bytes and sleeps until the bytes reserved before them have been transferred at the limiter rate
+func (l *Limiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.rate))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// chunkSize is the largest read allowed at once, so that a single read cannot take more than a tenth of a second of the rate
+func (l *Limiter) chunkSize() int {
+	if size := l.rate / 10; size > 1024 {
+		return int(size)
+	}
+	return 1024
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	resp.Body = t.limiter.Reader(req.Context(), resp.Body)
+	return resp, nil
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if size := b.limiter.chunkSize(); len(p) > size {
+		p = p[:size]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.wait(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,77 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  int64
+		withError bool
+	}{
+		"plain bytes":       {given: "100", expected: 100},
+		"kilobytes":         {given: "500k", expected: 500 * 1024},
+		"megabytes":         {given: "2M", expected: 2 * 1024 * 1024},
+		"fraction and unit": {given: "1.5MB", expected: 3 * 512 * 1024},
+		"per second":        {given: "64KB/s", expected: 64 * 1024},
+		"zero":              {given: "0", expected: 0},
+		"invalid":           {given: "fast", withError: true},
+		"negative":          {given: "-1k", withError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rate, err := ParseRate(test.given)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rate)
+		})
+	}
+}
+
+func TestLimiterTransport(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 40*1024/16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write(content)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	var nilLimiter *Limiter
+	assert.Equal(t, http.DefaultTransport, nilLimiter.Transport(nil))
+
+	client := &http.Client{Transport: NewLimiter(100 * 1024).Transport(nil)}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	elapsed := time.Since(start)
+
+	assert.Equal(t, content, body)
+	// 40KB at 100KB/s, only the time of the last chunk of at most 10KB is not waited for
+	assert.True(t, elapsed >= 300*time.Millisecond, "download took %s", elapsed)
+	assert.True(t, elapsed < 2*time.Second, "download took %s", elapsed)
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	limiter := NewLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := limiter.Reader(ctx, ioutil.NopCloser(bytes.NewReader(make([]byte, 4096))))
+	_, err := ioutil.ReadAll(body)
+	assert.Equal(t, context.Canceled, err)
+}
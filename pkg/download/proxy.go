@@ -0,0 +1,40 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+)
+
+// ProxyKey is the config key holding the URL of the proxy all downloads and clones go through, e.g. "http://proxy.example.com:3128".
+// Without it, the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are honored.
+const ProxyKey = "proxy"
+
+// ProxyURL returns the proxy set in cli.proxy, nil if there is none or it is invalid
+func ProxyURL(ctx context.Context) *url.URL {
+	proxy, ok := config.Get(ctx).GetValue("cli", ProxyKey)
+	proxy = strings.TrimSpace(proxy)
+	if !ok || proxy == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s", ProxyKey, proxy)
+		return nil
+	}
+	return proxyURL
+}
+
+// ProxyTransport returns a copy of the default transport sending requests through the proxy set in cli.proxy,
+// or the one from environment variables if the setting is missing
+func ProxyTransport(ctx context.Context) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL := ProxyURL(ctx); proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport
+}
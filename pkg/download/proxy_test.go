@@ -0,0 +1,45 @@
+package download
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestProxyTransport(t *testing.T) {
+	tests := map[string]struct {
+		proxy    string
+		expected string
+	}{
+		"proxy from config": {
+			proxy:    "http://proxy.example.com:3128",
+			expected: "http://proxy.example.com:3128",
+		},
+		"invalid proxy": {
+			proxy: "proxy",
+		},
+		"no proxy configured": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("HTTPS_PROXY", ""))
+			m := &config.Mock{}
+			m.On("GetValue", "cli", "proxy").Return(test.proxy, test.proxy != "").Once()
+			transport := ProxyTransport(config.Context(context.Background(), m))
+			req, err := http.NewRequest(http.MethodGet, "https://github.com/akamai/cli", nil)
+			require.NoError(t, err)
+			proxyURL, err := transport.Proxy(req)
+			require.NoError(t, err)
+			if test.expected == "" {
+				assert.Nil(t, proxyURL)
+				return
+			}
+			assert.Equal(t, test.expected, proxyURL.String())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package download
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// signature types which downloaded files can be verified with
+const (
+	// SignatureGPG is a detached OpenPGP signature, armored or binary, checked against an armored public key
+	SignatureGPG = "gpg"
+	// SignatureCosign is a base64-encoded signature created with "cosign sign-blob --key", checked against
+	// the PEM-encoded ECDSA public key of the key pair. Keyless signatures are not supported.
+	SignatureCosign = "cosign"
+)
+
+// ErrSignature is returned when a file does not match its signature
+var ErrSignature = errors.New("invalid signature")
+
+// VerifySignature checks that signature, made with the private part of key, is a valid signature of the content of r
+func VerifySignature(kind string, key, signature []byte, r io.Reader) error {
+	switch kind {
+	case SignatureGPG:
+		return verifyGPG(key, signature, r)
+	case SignatureCosign:
+		return verifyCosign(key, signature, r)
+	default:
+		return fmt.Errorf("unsupported signature type %q, expected %q or %q", kind, SignatureGPG, SignatureCosign)
+	}
+}
+
+func verifyGPG(key, signature []byte, r io.Reader) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return fmt.Errorf("unable to read public key: %w", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, r, bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, r, bytes.NewReader(signature))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrSignature, err)
+	}
+	return nil
+}
+
+func verifyCosign(key, signature []byte, r io.Reader) error {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return errors.New("unable to read public key: no PEM data found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to read public key: %w", err)
+	}
+	ecdsaKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unable to read public key: expected an ECDSA key, got %T", pub)
+	}
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64-encoded", ErrSignature)
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 {
+		return fmt.Errorf("%w: malformed signature", ErrSignature)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	if !ecdsa.Verify(ecdsaKey, h.Sum(nil), sig.R, sig.S) {
+		return fmt.Errorf("%w: signature does not match the key", ErrSignature)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package download
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	content := []byte("binary content")
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+	cosignKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	digest := sha256.Sum256(content)
+	r, sigS, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, sigS})
+	require.NoError(t, err)
+	cosignSignature := []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+
+	entity, err := openpgp.NewEntity("Release", "", "release@example.com", nil)
+	require.NoError(t, err)
+	gpgKey := &bytes.Buffer{}
+	w, err := armor.Encode(gpgKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	gpgSignature := &bytes.Buffer{}
+	require.NoError(t, openpgp.ArmoredDetachSign(gpgSignature, entity, bytes.NewReader(content), nil))
+	binarySignature := &bytes.Buffer{}
+	require.NoError(t, openpgp.DetachSign(binarySignature, entity, bytes.NewReader(content), nil))
+
+	tests := map[string]struct {
+		kind          string
+		key           []byte
+		signature     []byte
+		content       string
+		withError     string
+		withSignature bool
+	}{
+		"cosign":                 {kind: SignatureCosign, key: cosignKey, signature: cosignSignature, content: string(content)},
+		"cosign, modified file":  {kind: SignatureCosign, key: cosignKey, signature: cosignSignature, content: "modified", withSignature: true},
+		"cosign, not base64":     {kind: SignatureCosign, key: cosignKey, signature: []byte("???"), content: string(content), withSignature: true},
+		"cosign, invalid key":    {kind: SignatureCosign, key: []byte("key"), signature: cosignSignature, content: string(content), withError: "no PEM data found"},
+		"gpg armored":            {kind: SignatureGPG, key: gpgKey.Bytes(), signature: gpgSignature.Bytes(), content: string(content)},
+		"gpg binary":             {kind: SignatureGPG, key: gpgKey.Bytes(), signature: binarySignature.Bytes(), content: string(content)},
+		"gpg, modified file":     {kind: SignatureGPG, key: gpgKey.Bytes(), signature: gpgSignature.Bytes(), content: "modified", withSignature: true},
+		"gpg, key of cosign":     {kind: SignatureGPG, key: cosignKey, signature: gpgSignature.Bytes(), content: string(content), withError: "unable to read public key"},
+		"unknown signature type": {kind: "minisign", key: cosignKey, signature: cosignSignature, content: string(content), withError: `unsupported signature type "minisign"`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := VerifySignature(test.kind, test.key, test.signature, strings.NewReader(test.content))
+			switch {
+			case test.withSignature:
+				assert.True(t, errors.Is(err, ErrSignature), "expected invalid signature, got %v", err)
+			case test.withError != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package edgegrid signs requests to Akamai APIs with EdgeGrid credentials.
+//
+// Credentials are read from a section of an .edgerc file, and each request is signed
+// with the EG1-HMAC-SHA256 algorithm described at https://techdocs.akamai.com/developer/docs/authenticate-with-edgegrid
+package edgegrid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// DefaultMaxBody is the number of request body bytes covered by the signature when max_body is not set
+	DefaultMaxBody = 131072
+
+	// AccountSwitchKeyParam is the query parameter selecting the account a request is made for
+	AccountSwitchKeyParam = "accountSwitchKey"
+
+	timestampFormat = "20060102T15:04:05-0700"
+)
+
+var (
+	// ErrSectionNotFound is returned when the .edgerc file does not contain the requested section
+	ErrSectionNotFound = errors.New("section not found")
+	// ErrMissingCredential is returned when a section does not contain one of the required settings
+	ErrMissingCredential = errors.New("missing credential")
+)
+
+// Credentials are the settings of an .edgerc section
+type Credentials struct {
+	Host         string
+	ClientToken  string
+	ClientSecret string
+	AccessToken  string
+	MaxBody      int
+	// ExchangeURL is the endpoint exchanging the credentials for short-lived ones, if the section has one
+	ExchangeURL string
+	// Expires is the expiry of short-lived credentials, it is zero for credentials read from a file
+	Expires time.Time
+}
+
+// Load reads credentials from given section of an .edgerc file
+func Load(path, section string) (*Credentials, error) {
+	file, err := ini.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := file.GetSection(section)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s in %s", ErrSectionNotFound, section, path)
+	}
+	creds := &Credentials{
+		Host:         strings.TrimSuffix(strings.TrimPrefix(s.Key("host").String(), "https://"), "/"),
+		ClientToken:  s.Key("client_token").String(),
+		ClientSecret: s.Key("client_secret").String(),
+		AccessToken:  s.Key("access_token").String(),
+		MaxBody:      DefaultMaxBody,
+		ExchangeURL:  s.Key("exchange_url").String(),
+	}
+	if maxBody := s.Key("max_body").String(); maxBody != "" {
+		if creds.MaxBody, err = strconv.Atoi(maxBody); err != nil {
+			return nil, fmt.Errorf("invalid max_body in section %s: %s", section, maxBody)
+		}
+	}
+	for name, val := range map[string]string{"host": creds.Host, "client_token": creds.ClientToken, "client_secret": creds.ClientSecret, "access_token": creds.AccessToken} {
+		if val == "" {
+			return nil, fmt.Errorf("%w: %s in section %s", ErrMissingCredential, name, section)
+		}
+	}
+	return creds, nil
+}
+
+// Save writes the credentials as the only section of an .edgerc file readable by the user only
+func (c *Credentials) Save(path, section string) error {
+	file := ini.Empty()
+	s, err := file.NewSection(section)
+	if err != nil {
+		return err
+	}
+	for _, kv := range [][2]string{{"host", c.Host}, {"client_token", c.ClientToken}, {"client_secret", c.ClientSecret}, {"access_token", c.AccessToken}, {"max_body", strconv.Itoa(c.MaxBody)}} {
+		if _, err := s.NewKey(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	buf := &bytes.Buffer{}
+	if _, err := file.WriteTo(buf); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// Sign sets the Authorization header of the request, its body is read and restored so that the request can still be sent
+func (c *Credentials) Sign(req *http.Request) error {
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return c.sign(req, time.Now(), hex.EncodeToString(nonce))
+}
+
+func (c *Credentials) sign(req *http.Request, now time.Time, nonce string) error {
+	contentHash, err := c.contentHash(req)
+	if err != nil {
+		return err
+	}
+	authHeader := fmt.Sprintf("EG1-HMAC-SHA256 client_token=%s;access_token=%s;timestamp=%s;nonce=%s;",
+		c.ClientToken, c.AccessToken, now.UTC().Format(timestampFormat), nonce)
+	dataToSign := strings.Join([]string{
+		req.Method,
+		req.URL.Scheme,
+		req.URL.Host,
+		req.URL.RequestURI(),
+		"",
+		contentHash,
+		authHeader,
+	}, "\t")
+	signingKey := hmacSum([]byte(c.ClientSecret), now.UTC().Format(timestampFormat))
+	req.Header.Set("Authorization", authHeader+"signature="+hmacSum([]byte(signingKey), dataToSign))
+	return nil
+}
+
+// contentHash returns the hash of the body of POST requests, other requests are signed without their body
+func (c *Credentials) contentHash(req *http.Request) (string, error) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return "", nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := req.Body.Close(); err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", nil
+	}
+	if c.MaxBody > 0 && len(body) > c.MaxBody {
+		body = body[:c.MaxBody]
+	}
+	sum := sha256.Sum256(body)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+func hmacSum(key []byte, data string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+package edgegrid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEdgerc = `[default]
+host = akab-host.luna.akamaiapis.net
+client_token = akab-client-token
+client_secret = client-secret
+access_token = akab-access-token
+
+[papi]
+host = https://akab-papi.luna.akamaiapis.net/
+client_token = papi-client-token
+client_secret = papi-secret
+access_token = papi-access-token
+max_body = 16
+
+[broken]
+host = akab-broken.luna.akamaiapis.net
+client_token = broken-client-token
+`
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edgerc")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir)) // clean up
+	}()
+	path := filepath.Join(dir, ".edgerc")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testEdgerc), 0600))
+
+	tests := map[string]struct {
+		section   string
+		expected  *Credentials
+		withError error
+	}{
+		"default section": {
+			section: "default",
+			expected: &Credentials{
+				Host:         "akab-host.luna.akamaiapis.net",
+				ClientToken:  "akab-client-token",
+				ClientSecret: "client-secret",
+				AccessToken:  "akab-access-token",
+				MaxBody:      DefaultMaxBody,
+			},
+		},
+		"host with scheme and max body": {
+			section: "papi",
+			expected: &Credentials{
+				Host:         "akab-papi.luna.akamaiapis.net",
+				ClientToken:  "papi-client-token",
+				ClientSecret: "papi-secret",
+				AccessToken:  "papi-access-token",
+				MaxBody:      16,
+			},
+		},
+		"missing section": {
+			section:   "ccu",
+			withError: ErrSectionNotFound,
+		},
+		"missing credential": {
+			section:   "broken",
+			withError: ErrMissingCredential,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			creds, err := Load(path, test.section)
+			if test.withError != nil {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, test.withError), "unexpected error: %s", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, creds)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	creds := &Credentials{
+		Host:         "akab-host.luna.akamaiapis.net",
+		ClientToken:  "akab-client-token",
+		ClientSecret: "client-secret",
+		AccessToken:  "akab-access-token",
+		MaxBody:      16,
+	}
+	now := time.Date(2021, 3, 21, 19, 34, 21, 0, time.FixedZone("CET", 3600))
+	authHeader := "EG1-HMAC-SHA256 client_token=akab-client-token;access_token=akab-access-token;timestamp=20210321T18:34:21+0000;nonce=nonce-1;"
+
+	tests := map[string]struct {
+		method, url, body string
+		contentHash       string
+	}{
+		"GET without body": {
+			method: http.MethodGet,
+			url:    "https://akab-host.luna.akamaiapis.net/papi/v1/contracts?accountSwitchKey=1-ABC",
+		},
+		"PUT body is not signed": {
+			method: http.MethodPut,
+			url:    "https://akab-host.luna.akamaiapis.net/papi/v1/properties/prp_1",
+			body:   `{"name":"example"}`,
+		},
+		"POST body is truncated to max body": {
+			method:      http.MethodPost,
+			url:         "https://akab-host.luna.akamaiapis.net/papi/v1/cpcodes",
+			body:        `{"cpcodeName":"example"}`,
+			contentHash: sha256Base64(`{"cpcodeName":"e`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			require.NoError(t, err)
+			require.NoError(t, creds.sign(req, now, "nonce-1"))
+
+			uri := strings.TrimPrefix(test.url, "https://akab-host.luna.akamaiapis.net")
+			dataToSign := test.method + "\thttps\takab-host.luna.akamaiapis.net\t" + uri + "\t\t" + test.contentHash + "\t" + authHeader
+			signingKey := hmacBase64("client-secret", "20210321T18:34:21+0000")
+			assert.Equal(t, authHeader+"signature="+hmacBase64(signingKey, dataToSign), req.Header.Get("Authorization"))
+
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.body, string(body))
+		})
+	}
+}
+
+func TestSignNonce(t *testing.T) {
+	creds := &Credentials{Host: "akab-host.luna.akamaiapis.net", ClientToken: "a", ClientSecret: "b", AccessToken: "c"}
+	first, err := http.NewRequest(http.MethodGet, "https://akab-host.luna.akamaiapis.net/", nil)
+	require.NoError(t, err)
+	second := first.Clone(first.Context())
+	require.NoError(t, creds.Sign(first))
+	require.NoError(t, creds.Sign(second))
+	assert.Contains(t, first.Header.Get("Authorization"), ";nonce=")
+	assert.NotEqual(t, first.Header.Get("Authorization"), second.Header.Get("Authorization"))
+}
+
+func hmacBase64(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func sha256Base64(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package edgegrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrExchange is returned when long-lived credentials cannot be exchanged for short-lived ones
+var ErrExchange = errors.New("credential exchange failed")
+
+// exchangeRequest is the body of the request sent to the exchange endpoint of a section
+type exchangeRequest struct {
+	TTL int `json:"ttl"`
+}
+
+// exchangeResponse holds the short-lived credentials returned by the exchange endpoint. The host is
+// the one of the long-lived credentials if it is not returned.
+type exchangeResponse struct {
+	Host         string `json:"host"`
+	ClientToken  string `json:"client_token"`
+	ClientSecret string `json:"client_secret"`
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// CanExchange reports whether the section of the credentials declares an exchange endpoint
+func (c *Credentials) CanExchange() bool {
+	return c.ExchangeURL != ""
+}
+
+// Exchange trades the credentials for short-lived ones, valid for at most ttl, at the exchange endpoint of
+// the section. The endpoint is requested with a POST signed with the credentials, relative URLs are resolved
+// against the host of the section.
+func (c *Credentials) Exchange(ctx context.Context, client *http.Client, ttl time.Duration) (*Credentials, error) {
+	if !c.CanExchange() {
+		return nil, fmt.Errorf("%w: no exchange_url in the section", ErrExchange)
+	}
+	endpoint := c.ExchangeURL
+	if !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		endpoint = "https://" + c.Host + "/" + strings.TrimPrefix(endpoint, "/")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid exchange_url: %s", ErrExchange, err)
+	}
+	// the short-lived client secret is returned in the response body, so it must never be sent in plaintext
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: exchange_url must use https, got %s", ErrExchange, u.Scheme)
+	}
+	body, err := json.Marshal(exchangeRequest{TTL: int(ttl.Seconds())})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.Sign(req); err != nil {
+		return nil, err
+	}
+	requested := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExchange, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrExchange, resp.Status)
+	}
+	var exchanged exchangeResponse
+	if err := json.Unmarshal(data, &exchanged); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %s", ErrExchange, err)
+	}
+	if exchanged.ClientToken == "" || exchanged.ClientSecret == "" || exchanged.AccessToken == "" {
+		return nil, fmt.Errorf("%w: %s", ErrExchange, ErrMissingCredential)
+	}
+	short := &Credentials{
+		Host:         strings.TrimSuffix(strings.TrimPrefix(exchanged.Host, "https://"), "/"),
+		ClientToken:  exchanged.ClientToken,
+		ClientSecret: exchanged.ClientSecret,
+		AccessToken:  exchanged.AccessToken,
+		MaxBody:      c.MaxBody,
+		Expires:      requested.Add(ttl),
+	}
+	if short.Host == "" {
+		short.Host = c.Host
+	}
+	if exchanged.ExpiresIn > 0 {
+		short.Expires = requested.Add(time.Duration(exchanged.ExpiresIn) * time.Second)
+	}
+	return short, nil
+}
@@ -0,0 +1,142 @@
+package edgegrid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExchange(t *testing.T) {
+	tests := map[string]struct {
+		status    int
+		response  string
+		path      string
+		expected  *Credentials
+		expiresIn time.Duration
+		withError string
+	}{
+		"short-lived credentials": {
+			status:    http.StatusOK,
+			response:  `{"client_token": "short-token", "client_secret": "short-secret", "access_token": "short-access", "expires_in": 300}`,
+			expected:  &Credentials{ClientToken: "short-token", ClientSecret: "short-secret", AccessToken: "short-access", MaxBody: DefaultMaxBody},
+			expiresIn: 5 * time.Minute,
+		},
+		"expiry not returned": {
+			status:    http.StatusOK,
+			response:  `{"host": "https://akab-short.luna.akamaiapis.net/", "client_token": "short-token", "client_secret": "short-secret", "access_token": "short-access"}`,
+			expected:  &Credentials{Host: "akab-short.luna.akamaiapis.net", ClientToken: "short-token", ClientSecret: "short-secret", AccessToken: "short-access", MaxBody: DefaultMaxBody},
+			expiresIn: 15 * time.Minute,
+		},
+		"exchange refused": {
+			status:    http.StatusForbidden,
+			response:  `{"title": "Forbidden"}`,
+			withError: "credential exchange failed: 403 Forbidden",
+		},
+		"incomplete response": {
+			status:    http.StatusOK,
+			response:  `{"client_token": "short-token"}`,
+			withError: "credential exchange failed: missing credential",
+		},
+		"invalid response": {
+			status:    http.StatusOK,
+			response:  `<html>`,
+			withError: "credential exchange failed: invalid response",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/identity/v1/exchange", r.URL.Path)
+				assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "EG1-HMAC-SHA256 client_token=akab-client-token;"))
+				var body exchangeRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, 900, body.TTL)
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer srv.Close()
+			creds := &Credentials{
+				Host:         strings.TrimPrefix(srv.URL, "https://"),
+				ClientToken:  "akab-client-token",
+				ClientSecret: "client-secret",
+				AccessToken:  "akab-access-token",
+				MaxBody:      DefaultMaxBody,
+				ExchangeURL:  srv.URL + "/identity/v1/exchange",
+			}
+
+			start := time.Now()
+			short, err := creds.Exchange(context.Background(), srv.Client(), 15*time.Minute)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrExchange))
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expected.Host == "" {
+				test.expected.Host = creds.Host
+			}
+			assert.WithinDuration(t, start.Add(test.expiresIn), short.Expires, 5*time.Second)
+			short.Expires = time.Time{}
+			assert.Equal(t, test.expected, short)
+		})
+	}
+}
+
+func TestExchangePlaintext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("credentials exchanged over plaintext http")
+	}))
+	defer srv.Close()
+	creds := &Credentials{
+		Host:         "akab-host.luna.akamaiapis.net",
+		ClientToken:  "akab-client-token",
+		ClientSecret: "client-secret",
+		AccessToken:  "akab-access-token",
+		ExchangeURL:  srv.URL + "/identity/v1/exchange",
+	}
+	_, err := creds.Exchange(context.Background(), srv.Client(), time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrExchange))
+	assert.Contains(t, err.Error(), "exchange_url must use https")
+}
+
+func TestExchangeNotSupported(t *testing.T) {
+	creds := &Credentials{Host: "akab-host.luna.akamaiapis.net"}
+	assert.False(t, creds.CanExchange())
+	_, err := creds.Exchange(context.Background(), http.DefaultClient, time.Minute)
+	assert.True(t, errors.Is(err, ErrExchange))
+}
+
+func TestSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edgerc")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, ".edgerc")
+	creds := &Credentials{Host: "akab-host.luna.akamaiapis.net", ClientToken: "token", ClientSecret: "secret", AccessToken: "access", MaxBody: 16}
+
+	require.NoError(t, creds.Save(path, "papi"))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	loaded, err := Load(path, "papi")
+	require.NoError(t, err)
+	assert.Equal(t, creds, loaded)
+}
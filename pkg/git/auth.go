@@ -0,0 +1,79 @@
+package git
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/akamai/cli/pkg/download"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+const (
+	// TokenEnv is the environment variable holding an access token used for HTTPS remotes.
+	TokenEnv = "AKAMAI_CLI_GIT_TOKEN"
+
+	// TokenUserEnv overrides the user name sent together with the access token.
+	TokenUserEnv = "AKAMAI_CLI_GIT_USER"
+
+	// TokenHostsEnv lists the hosts, separated by commas, to which the token of TokenEnv is sent, github.com by default.
+	TokenHostsEnv = "AKAMAI_CLI_GIT_TOKEN_HOSTS"
+
+	defaultTokenHost = "github.com"
+)
+
+// EnvToken returns the token of TokenEnv if it can be sent to given host, empty otherwise
+func EnvToken(host string) string {
+	token := os.Getenv(TokenEnv)
+	if token == "" {
+		return ""
+	}
+	hosts := os.Getenv(TokenHostsEnv)
+	if strings.TrimSpace(hosts) == "" {
+		hosts = defaultTokenHost
+	}
+	for _, h := range strings.Split(hosts, ",") {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return token
+		}
+	}
+	return ""
+}
+
+// tokenUser returns the user name a git host expects for access token authentication.
+// GitLab accepts tokens for the "oauth2" user and Bitbucket for "x-token-auth",
+// while GitHub and Gitea accept any non-empty user name, except for GitHub App tokens which need "x-access-token".
+func tokenUser(host string) string {
+	if user := os.Getenv(TokenUserEnv); user != "" {
+		return user
+	}
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return "oauth2"
+	case strings.Contains(host, "bitbucket"):
+		return "x-token-auth"
+	case host == "github.com":
+		return "x-access-token"
+	}
+	return "git"
+}
+
+// authMethod returns token authentication for HTTPS repositories if a token is configured, nil otherwise
+// The token of TokenEnv is used for the hosts of TokenHostsEnv, otherwise the token set for the host of the repository in the context.
+func authMethod(ctx context.Context, repo string) transport.AuthMethod {
+	u, err := url.Parse(repo)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return nil
+	}
+	token := EnvToken(u.Hostname())
+	if token == "" {
+		token = download.HostToken(ctx, u.Hostname())
+	}
+	if token == "" {
+		return nil
+	}
+	return &http.BasicAuth{Username: tokenUser(u.Hostname()), Password: token}
+}
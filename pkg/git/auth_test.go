@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"os"
+	"testing"
+)
+
+func TestAuthMethod(t *testing.T) {
+	tests := map[string]struct {
+		repo      string
+		envToken  string
+		envHosts  string
+		hostToken string
+		expected  transport.AuthMethod
+	}{
+		"token of the environment sent to github.com by default": {
+			repo:     "https://github.com/akamai/cli-dns.git",
+			envToken: "abc",
+			expected: &http.BasicAuth{Username: "x-access-token", Password: "abc"},
+		},
+		"token of the environment not sent to other hosts": {
+			repo:     "https://git.example.com/akamai/cli-dns.git",
+			envToken: "abc",
+		},
+		"token of the environment sent to listed hosts": {
+			repo:     "https://GitLab.example.com/akamai/cli-dns.git",
+			envToken: "abc",
+			envHosts: "github.com, gitlab.example.com",
+			expected: &http.BasicAuth{Username: "oauth2", Password: "abc"},
+		},
+		"token of the host used for hosts not listed": {
+			repo:      "https://github.com/akamai/cli-dns.git",
+			envToken:  "abc",
+			envHosts:  "gitlab.example.com",
+			hostToken: "github_pat_abc",
+			expected:  &http.BasicAuth{Username: "x-access-token", Password: "github_pat_abc"},
+		},
+		"no token over plain HTTP": {
+			repo:     "http://github.com/akamai/cli-dns.git",
+			envToken: "abc",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv(TokenEnv, test.envToken))
+			require.NoError(t, os.Setenv(TokenHostsEnv, test.envHosts))
+			defer func() {
+				require.NoError(t, os.Unsetenv(TokenEnv))
+				require.NoError(t, os.Unsetenv(TokenHostsEnv))
+			}()
+			ctx := context.Background()
+			if test.hostToken != "" {
+				ctx = download.WithHostToken(ctx, "github.com", test.hostToken)
+			}
+			assert.Equal(t, test.expected, authMethod(ctx, test.repo))
+		})
+	}
+}
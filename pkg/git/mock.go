@@ -58,3 +58,90 @@ func (m *Mock) CommitObject(h plumbing.Hash) (*object.Commit, error) {
 	}
 	return args.Get(0).(*object.Commit), args.Error(1)
 }
+
+// Changes mock
+func (m *Mock) Changes(from, to plumbing.Hash) (*Changes, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Changes), args.Error(1)
+}
+
+// FileContents mock
+func (m *Mock) FileContents(h plumbing.Hash, path string) ([]byte, error) {
+	args := m.Called(h, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// AddRemote mock
+func (m *Mock) AddRemote(name, url string) error {
+	args := m.Called(name, url)
+	return args.Error(0)
+}
+
+// RemoteURL mock
+func (m *Mock) RemoteURL(name string) (string, error) {
+	args := m.Called(name)
+	return args.String(0), args.Error(1)
+}
+
+// SetRemoteURL mock
+func (m *Mock) SetRemoteURL(name, url string) error {
+	args := m.Called(name, url)
+	return args.Error(0)
+}
+
+// CheckoutBranch mock
+func (m *Mock) CheckoutBranch(_ context.Context, remote, branch string) error {
+	args := m.Called(remote, branch)
+	return args.Error(0)
+}
+
+// DefaultBranch mock
+func (m *Mock) DefaultBranch(_ context.Context, remote string) (string, error) {
+	args := m.Called(remote)
+	return args.String(0), args.Error(1)
+}
+
+// ResetToRemote mock
+func (m *Mock) ResetToRemote(remote string) error {
+	args := m.Called(remote)
+	return args.Error(0)
+}
+
+// FetchTags mock
+func (m *Mock) FetchTags(_ context.Context, remote string) ([]string, error) {
+	args := m.Called(remote)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// CheckoutTag mock
+func (m *Mock) CheckoutTag(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// CheckoutCommit mock
+func (m *Mock) CheckoutCommit(_ context.Context, remote, hash string) error {
+	args := m.Called(remote, hash)
+	return args.Error(0)
+}
+
+// FetchBranch mock
+func (m *Mock) FetchBranch(_ context.Context, remote, branch string) (plumbing.Hash, error) {
+	args := m.Called(remote, branch)
+	return args.Get(0).(plumbing.Hash), args.Error(1)
+}
+
+// TagCommit mock
+func (m *Mock) TagCommit(name string) (plumbing.Hash, error) {
+	args := m.Called(name)
+	return args.Get(0).(plumbing.Hash), args.Error(1)
+}
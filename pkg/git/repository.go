@@ -2,18 +2,47 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/config"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 
 	"gopkg.in/src-d/go-git.v4"
 
+	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/terminal"
 )
 
 const (
 	// DefaultRemoteName will provide default origin.
 	DefaultRemoteName = git.DefaultRemoteName
+
+	// MirrorRemotePrefix is the name prefix of secondary remotes used when the default remote is not available.
+	MirrorRemotePrefix = "mirror-"
+)
+
+var (
+	// ErrAlreadyUpToDate is returned by Pull when the remote has no new commits
+	ErrAlreadyUpToDate = git.NoErrAlreadyUpToDate
+	// ErrEmptyRepository is returned when the repository or its remote does not have any commit yet
+	ErrEmptyRepository = errors.New("repository is empty")
+	// ErrHistoryRewritten is returned by Pull when the remote branch was force-pushed and no longer contains the checked out commit
+	ErrHistoryRewritten = errors.New("remote history was rewritten")
+	// ErrDiverged is returned by Pull when both the local and the remote branch have commits missing in the other one
+	ErrDiverged = errors.New("local and remote histories have diverged")
+	// ErrLocalChanges is returned by ResetToRemote when tracked files of the worktree were modified
+	ErrLocalChanges = errors.New("worktree has local changes")
 )
 
 // Repository interface.
@@ -24,6 +53,35 @@ type Repository interface {
 	Head() (*plumbing.Reference, error)
 	Worktree() (*git.Worktree, error)
 	CommitObject(h plumbing.Hash) (*object.Commit, error)
+	Changes(from, to plumbing.Hash) (*Changes, error)
+	FileContents(h plumbing.Hash, path string) ([]byte, error)
+	AddRemote(name, url string) error
+	RemoteURL(name string) (string, error)
+	SetRemoteURL(name, url string) error
+	CheckoutBranch(ctx context.Context, remote, branch string) error
+	DefaultBranch(ctx context.Context, remote string) (string, error)
+	ResetToRemote(remote string) error
+	FetchTags(ctx context.Context, remote string) ([]string, error)
+	CheckoutTag(name string) error
+	CheckoutCommit(ctx context.Context, remote, hash string) error
+	FetchBranch(ctx context.Context, remote, branch string) (plumbing.Hash, error)
+	TagCommit(name string) (plumbing.Hash, error)
+}
+
+// Changes describes the difference between two commits of a repository.
+type Changes struct {
+	Commits      int      `json:"commits"`
+	FilesChanged []string `json:"filesChanged"`
+	// Log lists the commits reachable from the newer commit but not from the older one, newest first
+	Log []CommitSummary `json:"log,omitempty"`
+}
+
+// CommitSummary describes a single commit of Changes.
+type CommitSummary struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
 }
 
 type repository struct {
@@ -45,26 +103,504 @@ func (r *repository) Open(path string) error {
 }
 
 func (r *repository) Clone(ctx context.Context, path, repo string, isBare bool, progress terminal.Spinner) error {
-	gitRepo, err := git.PlainCloneContext(ctx, path, isBare, &git.CloneOptions{
+	op := startOperation(ctx, repo)
+	defer op.done()
+	gitRepo, err := git.PlainCloneContext(op.ctx, path, isBare, &git.CloneOptions{
 		URL:      repo,
+		Auth:     authMethod(ctx, repo),
 		Progress: progress,
 	})
 	if err != nil {
-		return err
+		err = op.result(err)
+		if !op.retryable() || !systemGitFallbackEnabled(ctx) {
+			return err
+		}
+		log.FromContext(ctx).Debugf("Unable to clone repository (%s), falling back to system git", err)
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		args := []string{"clone", repo, path}
+		if isBare {
+			args = []string{"clone", "--bare", repo, path}
+		}
+		if fallbackErr := runSystemGit(ctx, "", args...); fallbackErr != nil {
+			return fmt.Errorf("%s (system git: %s)", err, fallbackErr)
+		}
+		if gitRepo, err = git.PlainOpen(path); err != nil {
+			return err
+		}
 	}
 	r.gitRepo = gitRepo
 	return nil
 }
 
+// Pull fetches changes from the default remote, trying mirror remotes in order if it fails
 func (r *repository) Pull(ctx context.Context, worktree *git.Worktree) error {
-	return worktree.PullContext(ctx, &git.PullOptions{RemoteName: DefaultRemoteName})
+	logger := log.FromContext(ctx)
+	var err error
+	for _, remote := range r.remoteNames() {
+		err = r.pullRemote(ctx, worktree, remote)
+		if err == nil || errors.Is(err, ErrAlreadyUpToDate) || errors.Is(err, ErrHistoryRewritten) || errors.Is(err, ErrDiverged) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		logger.Debugf("Unable to pull from remote %s: %s", remote, err)
+	}
+	return err
+}
+
+func (r *repository) pullRemote(ctx context.Context, worktree *git.Worktree, remote string) error {
+	opts := &git.PullOptions{RemoteName: remote}
+	var fetched plumbing.Hash
+	var remoteURL string
+	if r.gitRepo != nil {
+		// pull the checked out branch, as default branches differ between hosts (main, master, develop...)
+		if head, err := r.gitRepo.Head(); err == nil && head.Name().IsBranch() {
+			opts.ReferenceName = head.Name()
+			if ref, err := r.gitRepo.Reference(plumbing.NewRemoteReferenceName(remote, head.Name().Short()), true); err == nil {
+				fetched = ref.Hash()
+			}
+		}
+		if rem, err := r.gitRepo.Remote(remote); err == nil && len(rem.Config().URLs) > 0 {
+			remoteURL = rem.Config().URLs[0]
+			opts.Auth = authMethod(ctx, remoteURL)
+		}
+	}
+	op := startOperation(ctx, remoteURL)
+	err := r.pullError(op.result(worktree.PullContext(op.ctx, opts)), fetched)
+	op.done()
+	if err == nil || errors.Is(err, ErrAlreadyUpToDate) || errors.Is(err, ErrEmptyRepository) ||
+		errors.Is(err, ErrHistoryRewritten) || errors.Is(err, ErrDiverged) || !op.retryable() || !systemGitFallbackEnabled(ctx) {
+		return err
+	}
+	log.FromContext(ctx).Debugf("Unable to pull repository (%s), falling back to system git", err)
+	root := worktree.Filesystem.Root()
+	ref := "HEAD"
+	if opts.ReferenceName != "" {
+		ref = opts.ReferenceName.Short()
+	}
+	if fallbackErr := runSystemGit(ctx, root, "pull", "--ff-only", remote, ref); fallbackErr != nil {
+		return fmt.Errorf("%s (system git: %s)", err, fallbackErr)
+	}
+	return r.Open(root)
+}
+
+// IsAuthError returns true if err means the repository refused the credentials, or requires some.
+// Errors of the system git fallback are matched by message, as they are only available as text.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, transport.ErrAuthenticationRequired.Error()) || strings.Contains(msg, transport.ErrAuthorizationFailed.Error()) ||
+		strings.Contains(msg, "authentication failed")
+}
+
+// pullError translates the errors go-git returns when the branch cannot be fast-forwarded or the remote is empty
+// If the checked out commit is the one last fetched from the remote, the branch has no local commits and was force-pushed,
+// otherwise the local branch has commits of its own.
+// go-git may also fail with "object not found" instead of a non-fast-forward error when the remote history was rewritten.
+func (r *repository) pullError(err error, fetched plumbing.Hash) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return fmt.Errorf("%w: %s", ErrEmptyRepository, err)
+	case errors.Is(err, git.ErrNonFastForwardUpdate), errors.Is(err, plumbing.ErrObjectNotFound):
+		if r.gitRepo != nil {
+			if head, headErr := r.gitRepo.Head(); headErr == nil && head.Hash() == fetched {
+				return fmt.Errorf("%w: %s", ErrHistoryRewritten, err)
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrDiverged, err)
+	}
+	return err
+}
+
+// remoteNames returns the default remote followed by mirror remotes in the order they were added,
+// as given by the number in their names, so that mirror-10 is tried after mirror-2
+func (r *repository) remoteNames() []string {
+	names := []string{DefaultRemoteName}
+	if r.gitRepo == nil {
+		return names
+	}
+	remotes, err := r.gitRepo.Remotes()
+	if err != nil {
+		return names
+	}
+	mirrors := make([]string, 0)
+	for _, remote := range remotes {
+		if name := remote.Config().Name; strings.HasPrefix(name, MirrorRemotePrefix) {
+			mirrors = append(mirrors, name)
+		}
+	}
+	sort.Slice(mirrors, func(i, j int) bool {
+		ni, errI := strconv.Atoi(strings.TrimPrefix(mirrors[i], MirrorRemotePrefix))
+		nj, errJ := strconv.Atoi(strings.TrimPrefix(mirrors[j], MirrorRemotePrefix))
+		switch {
+		case errI == nil && errJ == nil && ni != nj:
+			return ni < nj
+		case (errI == nil) != (errJ == nil):
+			return errI == nil
+		default:
+			return mirrors[i] < mirrors[j]
+		}
+	})
+	return append(names, mirrors...)
+}
+
+func (r *repository) AddRemote(name, url string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	_, err := r.gitRepo.CreateRemote(&config.RemoteConfig{
+		Name: name,
+		URLs: []string{url},
+	})
+	return err
+}
+
+// OriginURL returns the URL of the default remote of the repository at given path
+func OriginURL(path string) (string, error) {
+	r := &repository{}
+	if err := r.Open(path); err != nil {
+		return "", err
+	}
+	return r.RemoteURL(DefaultRemoteName)
+}
+
+// RemoteURL returns the first URL of the remote with given name
+func (r *repository) RemoteURL(name string) (string, error) {
+	if r.gitRepo == nil {
+		return "", fmt.Errorf("repository is not yet initialized")
+	}
+	remote, err := r.gitRepo.Remote(name)
+	if err != nil {
+		return "", err
+	}
+	if len(remote.Config().URLs) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+	return remote.Config().URLs[0], nil
+}
+
+// SetRemoteURL replaces the URLs of an existing remote, the remote is created if it does not exist
+func (r *repository) SetRemoteURL(name, url string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	cfg, err := r.gitRepo.Config()
+	if err != nil {
+		return err
+	}
+	remote, ok := cfg.Remotes[name]
+	if !ok {
+		return r.AddRemote(name, url)
+	}
+	remote.URLs = []string{url}
+	return r.gitRepo.Storer.SetConfig(cfg)
+}
+
+// CheckoutBranch fetches a branch from given remote and checks it out, replacing the local branch of the same name
+func (r *repository) CheckoutBranch(ctx context.Context, remote, branch string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	remoteRef := plumbing.NewRemoteReferenceName(remote, branch)
+	opts := &git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef))},
+	}
+	url, _ := r.RemoteURL(remote)
+	if url != "" {
+		opts.Auth = authMethod(ctx, url)
+	}
+	op := startOperation(ctx, url)
+	defer op.done()
+	if err := op.result(r.gitRepo.FetchContext(op.ctx, opts)); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	ref, err := r.gitRepo.Reference(remoteRef, true)
+	if err != nil {
+		return fmt.Errorf("branch %s not found on remote %s: %w", branch, remote, err)
+	}
+	localRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), ref.Hash())
+	if err := r.gitRepo.Storer.SetReference(localRef); err != nil {
+		return err
+	}
+	worktree, err := r.gitRepo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Branch: localRef.Name()})
+}
+
+// DefaultBranch returns the branch the HEAD of given remote points to, such as main or master
+func (r *repository) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	if r.gitRepo == nil {
+		return "", fmt.Errorf("repository is not yet initialized")
+	}
+	rem, err := r.gitRepo.Remote(remote)
+	if err != nil {
+		return "", err
+	}
+	opts := &git.ListOptions{}
+	url, _ := r.RemoteURL(remote)
+	if url != "" {
+		opts.Auth = authMethod(ctx, url)
+	}
+	op := startOperation(ctx, url)
+	defer op.done()
+	refs, err := rem.List(opts)
+	if err := op.result(err); err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("default branch of remote %s not found", remote)
+}
+
+// FetchTags fetches all tags of given remote and returns the names of tags known to the repository
+func (r *repository) FetchTags(ctx context.Context, remote string) ([]string, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	opts := &git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+		Tags:       git.AllTags,
+	}
+	url, _ := r.RemoteURL(remote)
+	if url != "" {
+		opts.Auth = authMethod(ctx, url)
+	}
+	op := startOperation(ctx, url)
+	defer op.done()
+	if err := op.result(r.gitRepo.FetchContext(op.ctx, opts)); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+	iter, err := r.gitRepo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	return tags, err
+}
+
+// FetchBranch fetches a branch from given remote and returns the commit it points to, leaving the worktree unchanged
+func (r *repository) FetchBranch(ctx context.Context, remote, branch string) (plumbing.Hash, error) {
+	if r.gitRepo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repository is not yet initialized")
+	}
+	remoteRef := plumbing.NewRemoteReferenceName(remote, branch)
+	opts := &git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef))},
+	}
+	url, _ := r.RemoteURL(remote)
+	if url != "" {
+		opts.Auth = authMethod(ctx, url)
+	}
+	op := startOperation(ctx, url)
+	defer op.done()
+	if err := op.result(r.gitRepo.FetchContext(op.ctx, opts)); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, err
+	}
+	ref, err := r.gitRepo.Reference(remoteRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("branch %s not found on remote %s: %w", branch, remote, err)
+	}
+	return ref.Hash(), nil
+}
+
+// TagCommit returns the commit given tag points to, following annotated tags
+func (r *repository) TagCommit(name string) (plumbing.Hash, error) {
+	if r.gitRepo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repository is not yet initialized")
+	}
+	hash, err := r.gitRepo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName(name)))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("tag %s not found: %w", name, err)
+	}
+	return *hash, nil
+}
+
+// CheckoutTag checks out the commit of given tag, leaving the HEAD detached
+func (r *repository) CheckoutTag(name string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	hash, err := r.TagCommit(name)
+	if err != nil {
+		return err
+	}
+	worktree, err := r.gitRepo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Hash: hash})
+}
+
+// CheckoutCommit checks out the commit with given full or abbreviated hash, leaving the HEAD detached
+// Branches of given remote are fetched first if the commit is not in the repository yet.
+func (r *repository) CheckoutCommit(ctx context.Context, remote, hash string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	commit, err := r.findCommit(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		opts := &git.FetchOptions{RemoteName: remote}
+		url, _ := r.RemoteURL(remote)
+		if url != "" {
+			opts.Auth = authMethod(ctx, url)
+		}
+		op := startOperation(ctx, url)
+		fetchErr := op.result(r.gitRepo.FetchContext(op.ctx, opts))
+		op.done()
+		if fetchErr != nil && !errors.Is(fetchErr, git.NoErrAlreadyUpToDate) {
+			return fetchErr
+		}
+		commit, err = r.findCommit(hash)
+	}
+	if err != nil {
+		return fmt.Errorf("commit %s not found: %w", hash, err)
+	}
+	worktree, err := r.gitRepo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Hash: commit})
+}
+
+// findCommit returns the hash of the only commit starting with given hash, which has to be at least 4 characters long
+func (r *repository) findCommit(hash string) (plumbing.Hash, error) {
+	hash = strings.ToLower(hash)
+	if len(hash) < 4 || len(hash) > 40 || strings.Trim(hash, "0123456789abcdef") != "" {
+		return plumbing.ZeroHash, fmt.Errorf("invalid commit hash %q", hash)
+	}
+	if len(hash) == 40 {
+		commit, err := r.gitRepo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return commit.Hash, nil
+	}
+	iter, err := r.gitRepo.CommitObjects()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	var found []plumbing.Hash
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if strings.HasPrefix(commit.Hash.String(), hash) {
+			found = append(found, commit.Hash)
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return plumbing.ZeroHash, err
+	case len(found) == 0:
+		return plumbing.ZeroHash, plumbing.ErrObjectNotFound
+	case len(found) > 1:
+		return plumbing.ZeroHash, fmt.Errorf("abbreviated hash %s is ambiguous", hash)
+	}
+	return found[0], nil
 }
 
+// ResetToRemote resets the checked out branch to its state last fetched from given remote, discarding local commits
+// ErrLocalChanges is returned instead if tracked files were changed, or if an untracked file would be replaced by the remote branch.
+// Other untracked files are kept, go-git removes them on a hard reset so they are restored afterwards.
+func (r *repository) ResetToRemote(remote string) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	head, err := r.gitRepo.Head()
+	if err != nil {
+		return err
+	}
+	if !head.Name().IsBranch() {
+		return fmt.Errorf("no branch is checked out")
+	}
+	ref, err := r.gitRepo.Reference(plumbing.NewRemoteReferenceName(remote, head.Name().Short()), true)
+	if err != nil {
+		return fmt.Errorf("branch %s not found on remote %s: %w", head.Name().Short(), remote, err)
+	}
+	worktree, err := r.gitRepo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	commit, err := r.gitRepo.CommitObject(ref.Hash())
+	if err != nil {
+		return err
+	}
+	untracked := make(map[string]untrackedFile)
+	for file, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked {
+			if _, err := commit.File(file); err == nil {
+				return fmt.Errorf("%w: %s", ErrLocalChanges, file)
+			}
+			path := filepath.Join(worktree.Filesystem.Root(), filepath.FromSlash(file))
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			untracked[path] = untrackedFile{data: data, mode: info.Mode()}
+			continue
+		}
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			return fmt.Errorf("%w: %s", ErrLocalChanges, file)
+		}
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset}); err != nil {
+		return err
+	}
+	for path, file := range untracked {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, file.data, file.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// untrackedFile is the content of a file kept by ResetToRemote
+type untrackedFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+// Head returns the checked out reference, ErrEmptyRepository if there is no commit yet
 func (r *repository) Head() (*plumbing.Reference, error) {
 	if r.gitRepo == nil {
 		return nil, fmt.Errorf("repository is not yet initialized")
 	}
-	return r.gitRepo.Head()
+	head, err := r.gitRepo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrEmptyRepository, err)
+	}
+	return head, err
 }
 
 func (r *repository) Worktree() (*git.Worktree, error) {
@@ -80,3 +616,68 @@ func (r *repository) CommitObject(h plumbing.Hash) (*object.Commit, error) {
 	}
 	return r.gitRepo.CommitObject(h)
 }
+
+func (r *repository) Changes(from, to plumbing.Hash) (*Changes, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	fromCommit, err := r.gitRepo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := r.gitRepo.CommitObject(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{FilesChanged: make([]string, 0)}
+	iter, err := r.gitRepo.Log(&git.LogOptions{From: to})
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash == from {
+			return storer.ErrStop
+		}
+		changes.Commits++
+		changes.Log = append(changes.Log, CommitSummary{
+			Hash:    c.Hash.String(),
+			Subject: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+			Author:  c.Author.Name,
+			Time:    c.Author.When,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := fromCommit.Patch(toCommit)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range patch.Stats() {
+		changes.FilesChanged = append(changes.FilesChanged, stat.Name)
+	}
+	return changes, nil
+}
+
+// FileContents returns the content of the file at path in the tree of given commit
+func (r *repository) FileContents(h plumbing.Hash, path string) ([]byte, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	commit, err := r.gitRepo.CommitObject(h)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found in commit %s: %w", path, h, err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(contents), nil
+}
@@ -0,0 +1,323 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetRemoteAndCheckoutBranch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "set-remote")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	// the fork has a branch with a commit missing in the upstream repository
+	upstreamDir, forkDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "fork"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	commitFile(t, upstream, upstreamDir, "cli.json", "{}")
+	fork, err := git.PlainClone(forkDir, false, &git.CloneOptions{URL: upstreamDir})
+	require.NoError(t, err)
+	w, err := fork.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("fix"), Create: true}))
+	patch := commitFile(t, fork, forkDir, "patch.txt", "patch")
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+
+	require.NoError(t, repo.SetRemoteURL(DefaultRemoteName, forkDir))
+	url, err := repo.RemoteURL(DefaultRemoteName)
+	require.NoError(t, err)
+	assert.Equal(t, forkDir, url)
+
+	require.NoError(t, repo.CheckoutBranch(ctx, DefaultRemoteName, "fix"))
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.NewBranchReferenceName("fix"), head.Name())
+	assert.Equal(t, patch, head.Hash())
+	assert.FileExists(t, filepath.Join(pkgDir, "patch.txt"))
+
+	assert.Error(t, repo.CheckoutBranch(ctx, DefaultRemoteName, "missing"))
+}
+
+func TestDefaultBranch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "default-branch")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	// the upstream default branch is main, the package has another branch checked out
+	upstreamDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	commitFile(t, upstream, upstreamDir, "cli.json", "{}")
+	upstreamHead, err := upstream.Head()
+	require.NoError(t, err)
+	w, err := upstream.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Hash: upstreamHead.Hash(), Branch: plumbing.NewBranchReferenceName("main"), Create: true}))
+	require.NoError(t, upstream.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))))
+	require.NoError(t, upstream.Storer.RemoveReference(plumbing.Master))
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("beta"), Create: true}))
+	commitFile(t, upstream, upstreamDir, "beta.txt", "beta")
+	require.NoError(t, upstream.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))))
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+	require.NoError(t, repo.CheckoutBranch(ctx, DefaultRemoteName, "beta"))
+
+	branch, err := repo.DefaultBranch(ctx, DefaultRemoteName)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	_, err = repo.DefaultBranch(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestPullRewrittenAndDivergedHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pull-history")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	upstreamDir, emptyDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "empty"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	first := commitFile(t, upstream, upstreamDir, "cli.json", "{}")
+	commitFile(t, upstream, upstreamDir, "README.md", "v1")
+	_, err = git.PlainInit(emptyDir, false)
+	require.NoError(t, err)
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	assert.True(t, errors.Is(repo.Pull(ctx, worktree), ErrAlreadyUpToDate))
+
+	// upstream is force-pushed with a commit replacing the one checked out in the package
+	upstreamWorktree, err := upstream.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, upstreamWorktree.Reset(&git.ResetOptions{Commit: first, Mode: git.HardReset}))
+	rewritten := commitFile(t, upstream, upstreamDir, "README.md", "v2")
+	err = repo.Pull(ctx, worktree)
+	assert.True(t, errors.Is(err, ErrHistoryRewritten), "unexpected error: %s", err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "cli.json"), []byte(`{"changed":true}`), 0644))
+	err = repo.ResetToRemote(DefaultRemoteName)
+	assert.True(t, errors.Is(err, ErrLocalChanges), "unexpected error: %s", err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "cli.json"), []byte("{}"), 0644))
+	// untracked files, e.g. created by the package build, are neither local changes nor lost
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgDir, "build"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkgDir, "build", "output.txt"), []byte("built"), 0644))
+	require.NoError(t, repo.ResetToRemote(DefaultRemoteName))
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, rewritten, head.Hash())
+	built, err := ioutil.ReadFile(filepath.Join(pkgDir, "build", "output.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "built", string(built))
+	require.NoError(t, os.RemoveAll(filepath.Join(pkgDir, "build")))
+
+	// both the package and upstream get new commits
+	pkgRepo, err := git.PlainOpen(pkgDir)
+	require.NoError(t, err)
+	commitFile(t, pkgRepo, pkgDir, "local.txt", "local")
+	commitFile(t, upstream, upstreamDir, "README.md", "v3")
+	err = repo.Pull(ctx, worktree)
+	assert.True(t, errors.Is(err, ErrDiverged), "unexpected error: %s", err)
+
+	require.NoError(t, repo.SetRemoteURL(DefaultRemoteName, emptyDir))
+	err = repo.Pull(ctx, worktree)
+	assert.True(t, errors.Is(err, ErrEmptyRepository), "unexpected error: %s", err)
+}
+
+func TestFetchAndCheckoutTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	upstreamDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	release := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.0.0"}`)
+	_, err = upstream.CreateTag("v1.0.0", release, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "release 1.0.0",
+	})
+	require.NoError(t, err)
+	beta := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.1.0-beta.1"}`)
+	_, err = upstream.CreateTag("v1.1.0-beta.1", beta, nil)
+	require.NoError(t, err)
+	commitFile(t, upstream, upstreamDir, "README.md", "unreleased")
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+
+	tags, err := repo.FetchTags(ctx, DefaultRemoteName)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v1.0.0", "v1.1.0-beta.1"}, tags)
+
+	for tag, expected := range map[string]plumbing.Hash{"v1.0.0": release, "v1.1.0-beta.1": beta} {
+		require.NoError(t, repo.CheckoutTag(tag))
+		head, err := repo.Head()
+		require.NoError(t, err)
+		assert.Equal(t, expected, head.Hash())
+		assert.False(t, head.Name().IsBranch())
+	}
+	assert.Error(t, repo.CheckoutTag("v2.0.0"))
+
+	commit, err := repo.TagCommit("v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, release, commit)
+	_, err = repo.TagCommit("v2.0.0")
+	assert.Error(t, err)
+}
+
+func TestFetchBranch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fetch-branch")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	upstreamDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	installed := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.0.0"}`)
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+	latest := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.1.0"}`)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	hash, err := repo.FetchBranch(ctx, DefaultRemoteName, head.Name().Short())
+	require.NoError(t, err)
+	assert.Equal(t, latest, hash)
+	head, err = repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, installed, head.Hash(), "fetched commits are not merged")
+	data, err := ioutil.ReadFile(filepath.Join(pkgDir, "cli.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"1.0.0"}`, string(data))
+
+	// fetched commits can be compared to the checked out one
+	changes, err := repo.Changes(installed, hash)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes.Commits)
+	assert.Equal(t, []string{"cli.json"}, changes.FilesChanged)
+	require.Len(t, changes.Log, 1)
+	assert.Equal(t, CommitSummary{Hash: latest.String(), Subject: "add cli.json", Author: "test", Time: changes.Log[0].Time}, changes.Log[0])
+	data, err = repo.FileContents(hash, "cli.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"1.1.0"}`, string(data))
+	_, err = repo.FileContents(hash, "missing.json")
+	assert.Error(t, err)
+
+	_, err = repo.FetchBranch(ctx, DefaultRemoteName, "missing")
+	assert.Error(t, err)
+}
+
+func TestCheckoutCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commits")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	upstreamDir, pkgDir := filepath.Join(dir, "upstream"), filepath.Join(dir, "package")
+	upstream, err := git.PlainInit(upstreamDir, false)
+	require.NoError(t, err)
+	first := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.0.0"}`)
+	commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.1.0"}`)
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	ctx := config.Context(context.Background(), cfg)
+	repo := NewRepository()
+	require.NoError(t, repo.Clone(ctx, pkgDir, upstreamDir, false, nil))
+
+	// a commit pushed after the clone is fetched first
+	latest := commitFile(t, upstream, upstreamDir, "cli.json", `{"version":"1.2.0"}`)
+	for _, hash := range []plumbing.Hash{first, latest} {
+		require.NoError(t, repo.CheckoutCommit(ctx, DefaultRemoteName, hash.String()[:7]))
+		head, err := repo.Head()
+		require.NoError(t, err)
+		assert.Equal(t, hash, head.Hash())
+		assert.False(t, head.Name().IsBranch())
+	}
+	require.NoError(t, repo.CheckoutCommit(ctx, DefaultRemoteName, first.String()))
+	assert.Error(t, repo.CheckoutCommit(ctx, DefaultRemoteName, "0000000"))
+	assert.Error(t, repo.CheckoutCommit(ctx, DefaultRemoteName, "v1.0.0"))
+}
+
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content string) plumbing.Hash {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = w.Add(name)
+	require.NoError(t, err)
+	hash, err := w.Commit("add "+name, &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestRemoteNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote-names")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	_, err = git.PlainInit(dir, false)
+	require.NoError(t, err)
+	repo := NewRepository()
+	require.NoError(t, repo.Open(dir))
+	for _, name := range []string{DefaultRemoteName, "mirror-10", "mirror-2", "upstream", "mirror-backup", "mirror-1"} {
+		require.NoError(t, repo.AddRemote(name, "https://github.com/akamai/"+name+".git"))
+	}
+
+	assert.Equal(t, []string{DefaultRemoteName, "mirror-1", "mirror-2", "mirror-10", "mirror-backup"}, repo.(*repository).remoteNames())
+}
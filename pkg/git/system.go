@@ -0,0 +1,51 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+// systemGitFallbackEnabled checks if failed go-git operations should be retried with the system git binary.
+// The fallback is enabled by default and can be disabled with 'akamai config set cli.git-fallback false'.
+func systemGitFallbackEnabled(ctx context.Context) bool {
+	val, ok := config.Get(ctx).GetValue("cli", "git-fallback")
+	if ok && strings.TrimSpace(val) == "false" {
+		return false
+	}
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// runSystemGit executes system git binary with given arguments in the provided directory
+func runSystemGit(ctx context.Context, dir string, args ...string) error {
+	logger := log.FromContext(ctx)
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		return err
+	}
+	args = append(append(proxyArgs(ctx), lowSpeedArgs(ctx)...), args...)
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+	logger.Debugf("Running system git: %s %s", bin, strings.Join(args, " "))
+	if w := terminal.OutputWriter(ctx); w != nil {
+		var output bytes.Buffer
+		cmd.Stdout = io.MultiWriter(&output, w)
+		cmd.Stderr = cmd.Stdout
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %s", err, strings.TrimSpace(output.String()))
+		}
+		return nil
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
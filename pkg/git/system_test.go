@@ -0,0 +1,182 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGit is a system git binary which records its arguments, copies FAKE_GIT_REPO to the destination of a clone
+// with the cp binary at given path, and fails with FAKE_GIT_FAIL if it is set
+const fakeGit = `#!/bin/sh
+echo "$@" >> "$FAKE_GIT_LOG"
+if [ -n "$FAKE_GIT_FAIL" ]; then
+	echo "fatal: $FAKE_GIT_FAIL" >&2
+	exit 128
+fi
+for last; do :; done
+case " $* " in
+*" clone "*) %s -R "$FAKE_GIT_REPO" "$last" ;;
+esac
+`
+
+func TestSystemGitFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake git binary is a shell script")
+	}
+	// PATH only holds the fake git during the test, so that a missing git can be tested on machines which have it
+	cp, err := exec.LookPath("cp")
+	require.NoError(t, err)
+	tests := map[string]struct {
+		pull        bool
+		noGit       bool
+		disabled    bool
+		gitFails    bool
+		expectedRun string
+		withError   string
+	}{
+		"clone falls back to system git": {
+			expectedRun: "clone {{remote}} {{dir}}",
+		},
+		"clone fails with system git": {
+			gitFails:    true,
+			expectedRun: "clone {{remote}} {{dir}}",
+			withError:   "(system git: exit status 128: fatal: remote unavailable)",
+		},
+		"clone without system git": {
+			noGit:     true,
+			withError: "500",
+		},
+		"clone with fallback disabled": {
+			disabled:  true,
+			withError: "500",
+		},
+		"pull falls back to system git": {
+			pull:        true,
+			expectedRun: "pull --ff-only origin master",
+		},
+		"pull fails with system git": {
+			pull:        true,
+			gitFails:    true,
+			expectedRun: "pull --ff-only origin master",
+			withError:   "(system git: exit status 128: fatal: remote unavailable)",
+		},
+		"pull without system git": {
+			pull:      true,
+			noGit:     true,
+			withError: "500",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer srv.Close()
+			remote := srv.URL + "/cli-test.git"
+			tmp, err := ioutil.TempDir("", "system-git")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(tmp))
+			}()
+
+			// the repository the fake git clones, and pulls into
+			upstreamDir := filepath.Join(tmp, "upstream")
+			upstream, err := git.PlainInit(upstreamDir, false)
+			require.NoError(t, err)
+			require.NoError(t, ioutil.WriteFile(filepath.Join(upstreamDir, "cli.json"), []byte(`{}`), 0644))
+			worktree, err := upstream.Worktree()
+			require.NoError(t, err)
+			_, err = worktree.Add("cli.json")
+			require.NoError(t, err)
+			_, err = worktree.Commit("init", &git.CommitOptions{
+				Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+			})
+			require.NoError(t, err)
+
+			binDir := filepath.Join(tmp, "bin")
+			require.NoError(t, os.MkdirAll(binDir, 0755))
+			if !test.noGit {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "git"), []byte(fmt.Sprintf(fakeGit, cp)), 0755))
+			}
+			logPath := filepath.Join(tmp, "git.log")
+			failure := ""
+			if test.gitFails {
+				failure = "remote unavailable"
+			}
+			for key, val := range map[string]string{"PATH": binDir, "FAKE_GIT_LOG": logPath, "FAKE_GIT_REPO": upstreamDir, "FAKE_GIT_FAIL": failure} {
+				prev, ok := os.LookupEnv(key)
+				require.NoError(t, os.Setenv(key, val))
+				defer func(key, prev string, ok bool) {
+					if ok {
+						require.NoError(t, os.Setenv(key, prev))
+					} else {
+						require.NoError(t, os.Unsetenv(key))
+					}
+				}(key, prev, ok)
+			}
+
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("0", true)
+			cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+			cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+			if test.disabled {
+				cfg.On("GetValue", "cli", "git-fallback").Return("false", true)
+			} else {
+				cfg.On("GetValue", "cli", "git-fallback").Return("", false)
+			}
+			ctx := config.Context(context.Background(), cfg)
+
+			dir := filepath.Join(tmp, "cli-test")
+			repo := NewRepository()
+			if test.pull {
+				_, err = upstream.CreateRemote(&gitconfig.RemoteConfig{Name: DefaultRemoteName, URLs: []string{remote}})
+				require.NoError(t, err)
+				require.NoError(t, repo.Open(upstreamDir))
+				dir = upstreamDir
+				err = repo.Pull(ctx, worktree)
+			} else {
+				err = repo.Clone(ctx, dir, remote, false, nil)
+			}
+
+			var runs string
+			if data, readErr := ioutil.ReadFile(logPath); readErr == nil {
+				runs = strings.TrimSpace(string(data))
+			}
+			if test.expectedRun != "" {
+				expected := strings.NewReplacer("{{remote}}", remote, "{{dir}}", dir).Replace(test.expectedRun)
+				assert.Equal(t, expected, runs)
+			} else {
+				assert.Empty(t, runs)
+			}
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			head, err := repo.Head()
+			require.NoError(t, err)
+			upstreamHead, err := upstream.Head()
+			require.NoError(t, err)
+			assert.Equal(t, upstreamHead.Hash(), head.Hash())
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/akamai/cli/pkg/log"
+)
+
+const (
+	// NetworkTimeoutKey is the config key holding the time after which a git operation is aborted
+	// if the remote does not send or accept any data, e.g. "30s". Setting it to "0" disables the limit.
+	NetworkTimeoutKey = "network-timeout"
+
+	defaultNetworkTimeout = time.Minute
+)
+
+// ErrNetworkTimeout is returned when a remote stops transferring data for longer than the network timeout
+var ErrNetworkTimeout = errors.New("network timeout")
+
+// networkTimeout returns the configured network timeout, the default one if the setting is missing or invalid
+func networkTimeout(ctx context.Context) time.Duration {
+	val, ok := config.Get(ctx).GetValue("cli", NetworkTimeoutKey)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return defaultNetworkTimeout
+	}
+	timeout, err := time.ParseDuration(val)
+	if err != nil || timeout < 0 {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s, expected a duration such as 30s", NetworkTimeoutKey, val)
+		return defaultNetworkTimeout
+	}
+	return timeout
+}
+
+// operation is the HTTP transport of a single go-git network operation
+// go-git does not pass the context to every request it makes, e.g. when listing remote references,
+// so the transport attaches it to them, which makes all requests stop once the operation is cancelled
+type operation struct {
+	ctx       context.Context
+	timeout   time.Duration
+	transport *http.Transport
+	base      http.RoundTripper
+	endpoint  string
+	timedOut  int32
+}
+
+// operations holds the running go-git operations by the endpoint of their remote, for requests made without their context
+var operations = struct {
+	sync.Mutex
+	byEndpoint map[string][]*operation
+}{byEndpoint: make(map[string][]*operation)}
+
+type operationContextType string
+
+var operationContext operationContextType = "git-operation"
+
+var installTransport sync.Once
+
+// startOperation prepares the HTTP(S) transport of a go-git operation on given remote, bound to given context.
+// The context of the operation has to be passed to go-git, and done has to be called once the operation returned.
+// go-git keeps transports in a global map which is not safe for concurrent use, so a single transport is installed
+// once, which hands each request to the operation it belongs to.
+func startOperation(ctx context.Context, remote string) *operation {
+	installTransport.Do(func() {
+		c := githttp.NewClient(&http.Client{Transport: operationTransport{}})
+		client.InstallProtocol("http", c)
+		client.InstallProtocol("https", c)
+	})
+	op := &operation{timeout: networkTimeout(ctx), endpoint: operationEndpoint(remote)}
+	transport := download.ProxyTransport(ctx)
+	if op.timeout > 0 {
+		dialer := &net.Dialer{Timeout: op.timeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				op.checkTimeout(err)
+				return nil, err
+			}
+			return &idleTimeoutConn{Conn: conn, op: op}, nil
+		}
+	}
+	op.transport = transport
+	op.base = download.LimitTransport(ctx, transport)
+	op.ctx = context.WithValue(ctx, operationContext, op)
+
+	if op.endpoint != "" {
+		operations.Lock()
+		operations.byEndpoint[op.endpoint] = append(operations.byEndpoint[op.endpoint], op)
+		operations.Unlock()
+	}
+	return op
+}
+
+// done unregisters the operation, so that following requests to its remote are not made through it,
+// and closes its idle connections, which are never reused as every operation has its own transport
+func (op *operation) done() {
+	op.transport.CloseIdleConnections()
+	if op.endpoint == "" {
+		return
+	}
+	operations.Lock()
+	defer operations.Unlock()
+	ops := operations.byEndpoint[op.endpoint]
+	for i, o := range ops {
+		if o == op {
+			ops = append(ops[:i], ops[i+1:]...)
+			break
+		}
+	}
+	if len(ops) == 0 {
+		delete(operations.byEndpoint, op.endpoint)
+		return
+	}
+	operations.byEndpoint[op.endpoint] = ops
+}
+
+// operationEndpoint returns the key of operations on the remote at given URL, the host and path of the repository
+func operationEndpoint(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return requestEndpoint(u)
+}
+
+// requestEndpoint returns the key of the operation a go-git request belongs to, requests are made to paths of the repository
+func requestEndpoint(u *url.URL) string {
+	p := u.Path
+	for _, suffix := range []string{"/info/refs", "/git-upload-pack", "/git-receive-pack"} {
+		p = strings.TrimSuffix(p, suffix)
+	}
+	return strings.ToLower(u.Host) + strings.TrimSuffix(p, "/")
+}
+
+// operationTransport is the transport installed in go-git, it sends each request through the transport of its operation
+type operationTransport struct{}
+
+func (operationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if op, ok := req.Context().Value(operationContext).(*operation); ok {
+		return op.base.RoundTrip(req)
+	}
+	operations.Lock()
+	var op *operation
+	if ops := operations.byEndpoint[requestEndpoint(req.URL)]; len(ops) > 0 {
+		op = ops[len(ops)-1]
+	}
+	operations.Unlock()
+	if op == nil {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	if req.Context().Done() == nil {
+		req = req.WithContext(op.ctx)
+	}
+	return op.base.RoundTrip(req)
+}
+
+// result marks errors caused by a stalled remote with ErrNetworkTimeout
+func (op *operation) result(err error) error {
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) || atomic.LoadInt32(&op.timedOut) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: no data transferred for %s (%s)", ErrNetworkTimeout, op.timeout, err)
+}
+
+// retryable returns false if the operation was cancelled or timed out, so that it should not be attempted again
+func (op *operation) retryable() bool {
+	return op.ctx.Err() == nil && atomic.LoadInt32(&op.timedOut) == 0
+}
+
+// idleTimeoutConn fails reads and writes which do not transfer any data within the network timeout
+type idleTimeoutConn struct {
+	net.Conn
+	op *operation
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.op.timeout)); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Read(b)
+	c.op.checkTimeout(err)
+	return n, err
+}
+
+func (c *idleTimeoutConn) Write(b []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.op.timeout)); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Write(b)
+	c.op.checkTimeout(err)
+	return n, err
+}
+
+// checkTimeout records that the operation timed out if given error is a network timeout
+func (op *operation) checkTimeout(err error) {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		atomic.StoreInt32(&op.timedOut, 1)
+	}
+}
+
+// proxyArgs returns system git options sending requests through the proxy set in cli.proxy, if any
+func proxyArgs(ctx context.Context) []string {
+	proxyURL := download.ProxyURL(ctx)
+	if proxyURL == nil {
+		return nil
+	}
+	return []string{"-c", "http.proxy=" + proxyURL.String()}
+}
+
+// lowSpeedArgs returns system git options aborting transfers which stall for longer than the network timeout
+func lowSpeedArgs(ctx context.Context) []string {
+	timeout := networkTimeout(ctx)
+	if timeout <= 0 {
+		return nil
+	}
+	seconds := int(timeout.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return []string{"-c", "http.lowSpeedLimit=1", "-c", fmt.Sprintf("http.lowSpeedTime=%d", seconds)}
+}
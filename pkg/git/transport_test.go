@@ -0,0 +1,165 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloneStalledRemote(t *testing.T) {
+	tests := map[string]struct {
+		timeout   string
+		cancel    bool
+		withError error
+	}{
+		"network timeout": {
+			timeout:   "100ms",
+			withError: ErrNetworkTimeout,
+		},
+		"operation cancelled": {
+			timeout:   "0",
+			cancel:    true,
+			withError: context.Canceled,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-release:
+				case <-time.After(5 * time.Second):
+				}
+			}))
+			defer srv.Close()
+			defer close(release)
+			dir, err := ioutil.TempDir("", "clone")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.RemoveAll(dir))
+			}()
+
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", NetworkTimeoutKey).Return(test.timeout, true)
+			cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+			cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+			ctx, cancel := context.WithCancel(config.Context(context.Background(), cfg))
+			defer cancel()
+			if test.cancel {
+				time.AfterFunc(100*time.Millisecond, cancel)
+			}
+
+			start := time.Now()
+			err = NewRepository().Clone(ctx, dir, srv.URL+"/cli-test.git", false, nil)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, test.withError), err.Error())
+			assert.Less(t, int64(time.Since(start)), int64(3*time.Second))
+			cfg.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCloneConcurrentOperations(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	clone := func(name, timeout string, cancelAfter time.Duration) error {
+		dir, err := ioutil.TempDir("", "clone")
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, os.RemoveAll(dir))
+		}()
+		cfg := &config.Mock{}
+		cfg.On("GetValue", "cli", NetworkTimeoutKey).Return(timeout, true)
+		cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+		cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+		ctx, cancel := context.WithCancel(config.Context(context.Background(), cfg))
+		defer cancel()
+		if cancelAfter > 0 {
+			time.AfterFunc(cancelAfter, cancel)
+		}
+		return NewRepository().Clone(ctx, dir, srv.URL+"/"+name+".git", false, nil)
+	}
+
+	var wg sync.WaitGroup
+	var cancelled, timedOut error
+	start := time.Now()
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cancelled = clone("cancelled", "0", 100*time.Millisecond)
+	}()
+	go func() {
+		defer wg.Done()
+		timedOut = clone("timed-out", "200ms", 0)
+	}()
+	wg.Wait()
+
+	require.Error(t, cancelled)
+	assert.True(t, errors.Is(cancelled, context.Canceled), cancelled.Error())
+	require.Error(t, timedOut)
+	assert.True(t, errors.Is(timedOut, ErrNetworkTimeout), timedOut.Error())
+	assert.Less(t, int64(time.Since(start)), int64(3*time.Second))
+}
+
+func TestOperationDoneClosesConnections(t *testing.T) {
+	var mu sync.Mutex
+	closed := make(map[net.Conn]bool)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch state {
+		case http.StateNew:
+			closed[conn] = false
+		case http.StateClosed:
+			closed[conn] = true
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", NetworkTimeoutKey).Return("", false)
+	cfg.On("GetValue", "cli", download.BandwidthLimitKey).Return("", false)
+	cfg.On("GetValue", "cli", download.ProxyKey).Return("", false)
+	op := startOperation(config.Context(context.Background(), cfg), srv.URL+"/cli-test.git")
+	req, err := http.NewRequestWithContext(op.ctx, http.MethodGet, srv.URL+"/cli-test.git/info/refs", nil)
+	require.NoError(t, err)
+	resp, err := operationTransport{}.RoundTrip(req)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	op.done()
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range closed {
+			if !c {
+				return false
+			}
+		}
+		return len(closed) == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,46 @@
+// Package keychain stores secrets in the credential store of the operating system: the login keychain on macOS,
+// the Secret Service through secret-tool on Linux and the Credential Manager on Windows.
+package keychain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when no secret is stored for the service and account
+	ErrNotFound = errors.New("secret not found in the keychain")
+	// ErrUnsupported is returned when the system has no keychain Akamai CLI can use
+	ErrUnsupported = errors.New("no keychain is available on this system")
+)
+
+type (
+	// Keychain stores secrets by service and account
+	Keychain interface {
+		Get(service, account string) (string, error)
+		Set(service, account, secret string) error
+		Delete(service, account string) error
+	}
+
+	contextType string
+)
+
+const keychainContext contextType = "keychain"
+
+// System returns the keychain of the operating system
+func System() Keychain {
+	return systemKeychain{}
+}
+
+// Context sets the keychain in the context
+func Context(ctx context.Context, kc Keychain) context.Context {
+	return context.WithValue(ctx, keychainContext, kc)
+}
+
+// Get returns the keychain of the context, the keychain of the operating system if none is set
+func Get(ctx context.Context) Keychain {
+	if kc, ok := ctx.Value(keychainContext).(Keychain); ok {
+		return kc
+	}
+	return System()
+}
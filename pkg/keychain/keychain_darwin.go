@@ -0,0 +1,64 @@
+// +build darwin
+
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errItemNotFound is the exit code of the security tool when the keychain holds no such item
+const errItemNotFound = 44
+
+type systemKeychain struct{}
+
+// Get reads the password of the generic keychain item of the service and account
+func (systemKeychain) Get(service, account string) (string, error) {
+	out, err := runSecurity(nil, "find-generic-password", "-s", service, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set adds or updates the generic keychain item, the command is passed on the standard input of "security -i"
+// so that the secret does not show in the process list
+func (systemKeychain) Set(service, account, secret string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(service), quote(account), quote(secret))
+	_, err := runSecurity(strings.NewReader(command), "-i")
+	return err
+}
+
+// Delete removes the generic keychain item, ErrNotFound is returned if there is none
+func (systemKeychain) Delete(service, account string) error {
+	_, err := runSecurity(nil, "delete-generic-password", "-s", service, "-a", account)
+	return err
+}
+
+func runSecurity(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("/usr/bin/security", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound {
+			return nil, ErrNotFound
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("security %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("security %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// quote quotes s for the command parser of "security -i"
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
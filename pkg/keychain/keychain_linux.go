@@ -0,0 +1,64 @@
+// +build linux
+
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretTool is the command line client of the Secret Service, part of libsecret
+var secretTool = "secret-tool"
+
+type systemKeychain struct{}
+
+// Get looks the secret up with secret-tool, which prints nothing and exits with 1 if there is none
+func (systemKeychain) Get(service, account string) (string, error) {
+	out, err := runSecretTool(nil, "lookup", "service", service, "account", account)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if len(out) == 0 {
+		return "", ErrNotFound
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set stores the secret with secret-tool, which reads it from the standard input so that it does not show in the process list
+func (systemKeychain) Set(service, account, secret string) error {
+	_, err := runSecretTool(strings.NewReader(secret), "store", "--label", fmt.Sprintf("%s (%s)", service, account), "service", service, "account", account)
+	return err
+}
+
+// Delete removes the secret, secret-tool does not report whether there was one
+func (systemKeychain) Delete(service, account string) error {
+	_, err := runSecretTool(nil, "clear", "service", service, "account", account)
+	return err
+}
+
+func runSecretTool(stdin *strings.Reader, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(secretTool)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s not found, install libsecret-tools", ErrUnsupported, secretTool)
+	}
+	cmd := exec.Command(path, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("%s %s: %w: %s", secretTool, args[0], err, msg)
+		}
+		return stdout.Bytes(), fmt.Errorf("%s %s: %w", secretTool, args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
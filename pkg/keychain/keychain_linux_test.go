@@ -0,0 +1,59 @@
+package keychain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSecretTool keeps secrets in files of the directory, named after the service and account
+const fakeSecretTool = `#!/bin/sh
+file="$STORE/$3-$5"
+case "$1" in
+  lookup) [ -f "$file" ] && cat "$file" || exit 1 ;;
+  store) [ "$2" = "--label" ] && file="$STORE/$5-$7"; cat > "$file" ;;
+  clear) rm -f "$file" ;;
+esac
+`
+
+func TestSystemKeychain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-keychain")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	tool := filepath.Join(dir, "secret-tool")
+	require.NoError(t, ioutil.WriteFile(tool, []byte(fakeSecretTool), 0755))
+	require.NoError(t, os.Setenv("STORE", dir))
+	defer func() {
+		require.NoError(t, os.Unsetenv("STORE"))
+	}()
+	secretTool = tool
+	defer func() { secretTool = "secret-tool" }()
+	kc := System()
+
+	_, err = kc.Get("akamai-cli", "github.com")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected secret not found, got %v", err)
+
+	require.NoError(t, kc.Set("akamai-cli", "github.com", "github_pat_abc"))
+	secret, err := kc.Get("akamai-cli", "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "github_pat_abc", secret)
+
+	require.NoError(t, kc.Set("akamai-cli", "github.com", "github_pat_def"))
+	secret, err = kc.Get("akamai-cli", "github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "github_pat_def", secret)
+
+	require.NoError(t, kc.Delete("akamai-cli", "github.com"))
+	_, err = kc.Get("akamai-cli", "github.com")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected secret not found, got %v", err)
+
+	secretTool = filepath.Join(dir, "missing")
+	_, err = kc.Get("akamai-cli", "github.com")
+	assert.True(t, errors.Is(err, ErrUnsupported), "expected unsupported keychain, got %v", err)
+}
@@ -0,0 +1,17 @@
+// +build !darwin,!linux,!windows
+
+package keychain
+
+type systemKeychain struct{}
+
+func (systemKeychain) Get(string, string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (systemKeychain) Set(string, string, string) error {
+	return ErrUnsupported
+}
+
+func (systemKeychain) Delete(string, string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,109 @@
+// +build windows
+
+package keychain
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// constants of the Credential Manager API
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+type systemKeychain struct{}
+
+// Get reads the generic credential of the Credential Manager targeting the service and account
+func (systemKeychain) Get(service, account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ret, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		return "", credentialError(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	blob := (*[1 << 20]byte)(unsafe.Pointer(cred.CredentialBlob))[:cred.CredentialBlobSize:cred.CredentialBlobSize]
+	return string(blob), nil
+}
+
+// Set writes the generic credential, which persists across logon sessions of the user
+func (systemKeychain) Set(service, account, secret string) error {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("empty secret")
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if ret, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return credentialError(err)
+	}
+	return nil
+}
+
+// Delete removes the generic credential, ErrNotFound is returned if there is none
+func (systemKeychain) Delete(service, account string) error {
+	target, err := syscall.UTF16PtrFromString(targetName(service, account))
+	if err != nil {
+		return err
+	}
+	if ret, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ret == 0 {
+		return credentialError(err)
+	}
+	return nil
+}
+
+func targetName(service, account string) string {
+	return service + ":" + account
+}
+
+func credentialError(err error) error {
+	if errors.Is(err, errorNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
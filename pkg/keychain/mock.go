@@ -0,0 +1,26 @@
+package keychain
+
+import "github.com/stretchr/testify/mock"
+
+// Mock impl of Keychain interface
+type Mock struct {
+	mock.Mock
+}
+
+// Get mock
+func (m *Mock) Get(service, account string) (string, error) {
+	args := m.Called(service, account)
+	return args.String(0), args.Error(1)
+}
+
+// Set mock
+func (m *Mock) Set(service, account, secret string) error {
+	args := m.Called(service, account, secret)
+	return args.Error(0)
+}
+
+// Delete mock
+func (m *Mock) Delete(service, account string) error {
+	args := m.Called(service, account)
+	return args.Error(0)
+}
@@ -70,6 +70,25 @@ func SetupContext(ctx context.Context, defaultWriter io.Writer) context.Context
 	return log.NewContext(ctx, logger)
 }
 
+// SetLevel changes the level of the logger in the context, unless the level was chosen with AKAMAI_LOG environment variable
+func SetLevel(ctx context.Context, level string) {
+	logger, ok := log.FromContext(ctx).(*log.Logger)
+	if !ok || os.Getenv("AKAMAI_LOG") != "" {
+		return
+	}
+	if logLevel, err := log.ParseLevel(level); err == nil {
+		logger.Level = logLevel
+	}
+}
+
+// New creates a Logger writing entries of all levels to given writer, without colors
+func New(w io.Writer) Logger {
+	return &log.Logger{
+		Level:   log.DebugLevel,
+		Handler: NewHandler(w, false),
+	}
+}
+
 // FromContext wraps log.FromContext function to simplify imports in the project
 func FromContext(ctx context.Context) Logger {
 	return log.FromContext(ctx)
@@ -0,0 +1,142 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manager exposes Akamai CLI package management to other Go programs.
+//
+// All operations work on the same packages directory as the akamai binary ($AKAMAI_CLI_HOME/.akamai-cli/src)
+// and require a context created with Context:
+//
+//	ctx, err := manager.Context(context.Background(), terminal.DiscardWriter())
+//	if err != nil {
+//		return err
+//	}
+//	mgr := manager.New()
+//	pkg, err := mgr.Install(ctx, "property")
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/pkg/commands"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/git"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+type (
+	// Manager performs operations on installed Akamai CLI packages
+	Manager struct {
+		git         git.Repository
+		langManager packages.LangManager
+	}
+
+	// Package describes an installed package
+	Package struct {
+		Dir          string                        `json:"dir"`
+		Commands     []Command                     `json:"commands"`
+		Requirements packages.LanguageRequirements `json:"requirements"`
+	}
+
+	// Command describes a single command provided by a package
+	Command struct {
+		Name        string   `json:"name"`
+		Aliases     []string `json:"aliases"`
+		Version     string   `json:"version"`
+		Description string   `json:"description"`
+	}
+)
+
+// New returns a Manager using default git and language integrations
+func New() *Manager {
+	return &Manager{
+		git:         git.NewRepository(),
+		langManager: packages.NewLangManager(),
+	}
+}
+
+// Context loads Akamai CLI config and returns a context, with terminal and logger writing to out, required by Manager operations
+func Context(ctx context.Context, out terminal.Writer) (context.Context, error) {
+	cfg, err := config.NewIni()
+	if err != nil {
+		return nil, err
+	}
+	term := terminal.New(out, os.Stdin, out)
+	ctx = config.Context(ctx, cfg)
+	ctx = terminal.Context(ctx, term)
+	return log.SetupContext(ctx, out), nil
+}
+
+// Install installs a package from a repository, accepting the same formats as 'akamai install'
+func (m *Manager) Install(ctx context.Context, repo string) (*Package, error) {
+	dir, err := commands.InstallPackage(ctx, m.git, m.langManager, repo, false)
+	if err != nil {
+		return nil, err
+	}
+	return readPackage(dir)
+}
+
+// Update updates the package providing given command
+func (m *Manager) Update(ctx context.Context, command string) error {
+	return commands.UpdatePackage(ctx, m.git, m.langManager, command, false)
+}
+
+// List returns all installed packages
+func (m *Manager) List() ([]Package, error) {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := filepath.Glob(filepath.Join(srcPath, "*"))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Package, 0, len(dirs))
+	for _, dir := range dirs {
+		pkg, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+		result = append(result, *pkg)
+	}
+	return result, nil
+}
+
+// Resolve returns the command line which should be executed to run given command
+func (m *Manager) Resolve(ctx context.Context, command string) ([]string, error) {
+	return commands.FindExec(ctx, m.langManager, strings.ToLower(command))
+}
+
+func readPackage(dir string) (*Package, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "cli.json"))
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{}
+	if err := json.Unmarshal(data, pkg); err != nil {
+		return nil, err
+	}
+	pkg.Dir = dir
+	for i := range pkg.Commands {
+		pkg.Commands[i].Name = strings.ToLower(pkg.Commands[i].Name)
+	}
+	return pkg, nil
+}
@@ -0,0 +1,26 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/pkg/packages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	res, err := New().List()
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{
+			Dir: filepath.Join("testdata", ".akamai-cli", "src", "cli-echo"),
+			Commands: []Command{
+				{Name: "echo", Aliases: []string{"e"}, Version: "1.0.0", Description: "echo command"},
+			},
+			Requirements: packages.LanguageRequirements{Go: "1.14.0"},
+		},
+	}, res)
+}
@@ -0,0 +1,295 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+// media types of manifests accepted by Resolve
+const (
+	MediaTypeImageManifest  = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// maxManifestSize is the largest manifest read, manifests list layers only and are much smaller
+const maxManifestSize = 4 << 20
+
+type (
+	// Descriptor points to a blob of an artifact
+	Descriptor struct {
+		MediaType   string            `json:"mediaType"`
+		Digest      string            `json:"digest"`
+		Size        int64             `json:"size"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+
+	// Manifest lists the blobs an artifact is made of
+	Manifest struct {
+		SchemaVersion int          `json:"schemaVersion"`
+		MediaType     string       `json:"mediaType,omitempty"`
+		ArtifactType  string       `json:"artifactType,omitempty"`
+		Config        Descriptor   `json:"config"`
+		Layers        []Descriptor `json:"layers"`
+	}
+
+	// Client pulls artifacts from registries. Requests are sent anonymously first, credentials are only looked up
+	// when a registry requires authentication.
+	Client struct {
+		// HTTP sends the requests, http.DefaultClient if nil
+		HTTP *http.Client
+		// Credentials returns the credentials of given registry, requests are anonymous if nil
+		Credentials func(registry string) (Credential, error)
+		// PlainHTTP sends requests over HTTP instead of HTTPS, for registries on the local machine
+		PlainHTTP bool
+
+		mu     sync.Mutex
+		tokens map[string]string
+	}
+
+	// challenge is the authentication scheme requested by a registry in the WWW-Authenticate header
+	challenge struct {
+		scheme string
+		params map[string]string
+	}
+)
+
+// IsLocal returns true if the registry runs on the local machine, where registries are usually served over plain HTTP
+func IsLocal(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Resolve fetches the manifest of the artifact and returns it with its digest.
+// The manifest of an artifact referenced by digest is verified against it.
+func (c *Client) Resolve(ctx context.Context, ref Reference) (*Manifest, string, error) {
+	accept := strings.Join([]string{MediaTypeImageManifest, MediaTypeDockerManifest}, ", ")
+	resp, err := c.get(ctx, ref, "/manifests/"+ref.manifestReference(), accept)
+	if err != nil {
+		return nil, "", err
+	}
+	defer closeBody(ctx, resp)
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if ref.Digest != "" && digest != ref.Digest {
+		return nil, "", fmt.Errorf("%w: manifest of %s is %s", ErrDigest, ref, digest)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("invalid manifest of %s: %w", ref, err)
+	}
+	if manifest.SchemaVersion != 2 {
+		return nil, "", fmt.Errorf("unsupported manifest of %s, schema version %d", ref, manifest.SchemaVersion)
+	}
+	log.FromContext(ctx).Debugf("Resolved %s to %s", ref, digest)
+	return manifest, digest, nil
+}
+
+// FetchBlob writes the blob to w, verifying it against its digest
+func (c *Client) FetchBlob(ctx context.Context, ref Reference, blob Descriptor, w io.Writer) error {
+	if !digestPattern.MatchString(blob.Digest) {
+		return fmt.Errorf("unsupported digest %q", blob.Digest)
+	}
+	resp, err := c.get(ctx, ref, "/blobs/"+blob.Digest, "")
+	if err != nil {
+		return err
+	}
+	defer closeBody(ctx, resp)
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return err
+	}
+	if digest := "sha256:" + hex.EncodeToString(h.Sum(nil)); digest != blob.Digest {
+		return fmt.Errorf("%w: expected %s, got %s", ErrDigest, blob.Digest, digest)
+	}
+	return nil
+}
+
+// get requests path of the repository API, authenticating as the registry asks if the anonymous request is refused
+func (c *Client) get(ctx context.Context, ref Reference, path, accept string) (*http.Response, error) {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s%s", scheme, ref.host(), ref.Repository, path)
+	resp, err := c.send(ctx, u, accept, c.token(ref))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		auth, err := c.authenticate(ctx, ref, resp.Header.Get("WWW-Authenticate"))
+		closeBody(ctx, resp)
+		if err != nil {
+			return nil, fmt.Errorf("unable to authenticate to %s: %w", ref.Registry, err)
+		}
+		if resp, err = c.send(ctx, u, accept, auth); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		closeBody(ctx, resp)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%s not found in %s", ref, ref.Registry)
+		}
+		return nil, fmt.Errorf("unable to fetch %s: %s", ref, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *Client) send(ctx context.Context, u, accept, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// authenticate returns the Authorization header answering the challenge of the registry: credentials for basic
+// authentication, or a bearer token obtained from the realm of the registry, with credentials if there are any
+func (c *Client) authenticate(ctx context.Context, ref Reference, header string) (string, error) {
+	ch, ok := parseChallenge(header)
+	if !ok {
+		return "", fmt.Errorf("unsupported authentication challenge %q", header)
+	}
+	cred := Credential{}
+	if c.Credentials != nil {
+		var err error
+		if cred, err = c.Credentials(ref.Registry); err != nil {
+			return "", err
+		}
+	}
+
+	switch ch.scheme {
+	case "basic":
+		if cred.Username == "" {
+			return "", errors.New("credentials required, log in with \"docker login\"")
+		}
+		return cred.basicAuth(), nil
+	case "bearer":
+		realm, err := url.Parse(ch.params["realm"])
+		if err != nil || realm.Host == "" {
+			return "", fmt.Errorf("invalid token realm %q", ch.params["realm"])
+		}
+		query := realm.Query()
+		if service := ch.params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+		realm.RawQuery = query.Encode()
+		auth := ""
+		if cred.Username != "" {
+			auth = cred.basicAuth()
+		}
+		resp, err := c.send(ctx, realm.String(), "application/json", auth)
+		if err != nil {
+			return "", err
+		}
+		defer closeBody(ctx, resp)
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("token request refused: %s", resp.Status)
+		}
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("invalid token response: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		if token.Token == "" {
+			return "", errors.New("no token received")
+		}
+		bearer := "Bearer " + token.Token
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.tokens == nil {
+			c.tokens = make(map[string]string)
+		}
+		c.tokens[ref.Registry+"/"+ref.Repository] = bearer
+		return bearer, nil
+	}
+	return "", fmt.Errorf("unsupported authentication scheme %q", ch.scheme)
+}
+
+// token returns the bearer token obtained for the repository before, if any
+func (c *Client) token(ref Reference) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[ref.Registry+"/"+ref.Repository]
+}
+
+// parseChallenge parses a WWW-Authenticate header such as: Bearer realm="https://auth.example.com/token",service="registry"
+func parseChallenge(header string) (challenge, bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if parts[0] == "" {
+		return challenge{}, false
+	}
+	ch := challenge{scheme: strings.ToLower(parts[0]), params: make(map[string]string)}
+	if len(parts) == 1 {
+		return ch, true
+	}
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq <= 0 {
+			return challenge{}, false
+		}
+		key, value := strings.ToLower(strings.TrimSpace(rest[:eq])), ""
+		rest = strings.TrimSpace(rest[eq+1:])
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return challenge{}, false
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else if comma := strings.Index(rest, ","); comma >= 0 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		ch.params[key] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return ch, true
+}
+
+func closeBody(ctx context.Context, resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.FromContext(ctx).Debugf("Unable to close response body: %s", err)
+	}
+}
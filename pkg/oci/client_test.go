@@ -0,0 +1,181 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// testRegistry serves a single artifact tagged 1.0.0, requiring a bearer token obtained with given credentials
+type testRegistry struct {
+	manifest []byte
+	blob     []byte
+	user     string
+	password string
+	requests []string
+	// tampered is served instead of the manifest or blob of the path
+	tampered map[string][]byte
+}
+
+func newTestRegistry(t *testing.T, blob []byte) *testRegistry {
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        Descriptor{MediaType: "application/vnd.oci.empty.v1+json", Digest: digestOf([]byte("{}")), Size: 2},
+		Layers:        []Descriptor{{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Digest: digestOf(blob), Size: int64(len(blob))}},
+	})
+	require.NoError(t, err)
+	return &testRegistry{manifest: manifest, blob: blob, user: "user", password: "secret"}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests = append(r.requests, req.URL.Path)
+	if req.URL.Path == "/token" {
+		user, password, ok := req.BasicAuth()
+		if !ok || user != r.user || password != r.password || req.URL.Query().Get("scope") != "repository:akamai/cli-dns:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token": "abc"}`))
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer abc" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if data, ok := r.tampered[req.URL.Path]; ok {
+		_, _ = w.Write(data)
+		return
+	}
+	switch req.URL.Path {
+	case "/v2/akamai/cli-dns/manifests/1.0.0", "/v2/akamai/cli-dns/manifests/" + digestOf(r.manifest):
+		w.Header().Set("Content-Type", MediaTypeImageManifest)
+		_, _ = w.Write(r.manifest)
+	case "/v2/akamai/cli-dns/blobs/" + digestOf(r.blob):
+		_, _ = w.Write(r.blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClient(t *testing.T) {
+	registry := newTestRegistry(t, []byte("package"))
+	srv := httptest.NewServer(registry)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	credentials := func(r string) (Credential, error) {
+		assert.Equal(t, host, r)
+		return Credential{Username: "user", Password: "secret"}, nil
+	}
+
+	tests := map[string]struct {
+		ref         string
+		credentials func(string) (Credential, error)
+		withError   string
+	}{
+		"tag":                 {ref: host + "/akamai/cli-dns:1.0.0", credentials: credentials},
+		"digest":              {ref: host + "/akamai/cli-dns@" + digestOf(registry.manifest), credentials: credentials},
+		"tag not found":       {ref: host + "/akamai/cli-dns:2.0.0", credentials: credentials, withError: "cli-dns:2.0.0 not found in " + host},
+		"no credentials":      {ref: host + "/akamai/cli-dns:1.0.0", withError: "unable to authenticate to " + host + ": token request refused: 401 Unauthorized"},
+		"credentials refused": {ref: host + "/akamai/cli-dns:1.0.0", credentials: func(string) (Credential, error) { return Credential{Username: "user", Password: "wrong"}, nil }, withError: "token request refused"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseReference(test.ref)
+			require.NoError(t, err)
+			client := &Client{Credentials: test.credentials, PlainHTTP: true}
+			manifest, digest, err := client.Resolve(context.Background(), ref)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, digestOf(registry.manifest), digest)
+			require.Len(t, manifest.Layers, 1)
+			blob := &strings.Builder{}
+			require.NoError(t, client.FetchBlob(context.Background(), ref, manifest.Layers[0], blob))
+			assert.Equal(t, "package", blob.String())
+		})
+	}
+}
+
+func TestClientDigestMismatch(t *testing.T) {
+	registry := newTestRegistry(t, []byte("package"))
+	srv := httptest.NewServer(registry)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	client := &Client{Credentials: func(string) (Credential, error) { return Credential{Username: "user", Password: "secret"}, nil }, PlainHTTP: true}
+	ref, err := ParseReference(host + "/akamai/cli-dns:1.0.0")
+	require.NoError(t, err)
+	manifest, digest, err := client.Resolve(context.Background(), ref)
+	require.NoError(t, err)
+
+	registry.requests = nil
+	registry.tampered = map[string][]byte{
+		"/v2/akamai/cli-dns/blobs/" + manifest.Layers[0].Digest: []byte("tampered"),
+		"/v2/akamai/cli-dns/manifests/" + digest:                append(registry.manifest, ' '),
+	}
+	err = client.FetchBlob(context.Background(), ref, manifest.Layers[0], &strings.Builder{})
+	assert.True(t, errors.Is(err, ErrDigest), "expected digest mismatch, got %v", err)
+	assert.NotContains(t, registry.requests, "/token", "the token is reused")
+
+	ref.Tag, ref.Digest = "", digest
+	_, _, err = client.Resolve(context.Background(), ref)
+	assert.True(t, errors.Is(err, ErrDigest), "expected digest mismatch, got %v", err)
+}
+
+func TestParseChallenge(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		expected challenge
+		ok       bool
+	}{
+		"bearer": {
+			header:   `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull"`,
+			expected: challenge{scheme: "bearer", params: map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com", "scope": "repository:a/b:pull"}},
+			ok:       true,
+		},
+		"basic":            {header: `Basic realm="Registry"`, expected: challenge{scheme: "basic", params: map[string]string{"realm": "Registry"}}, ok: true},
+		"unquoted":         {header: `Basic realm=Registry, charset="UTF-8"`, expected: challenge{scheme: "basic", params: map[string]string{"realm": "Registry", "charset": "UTF-8"}}, ok: true},
+		"empty":            {header: ""},
+		"unterminated":     {header: `Bearer realm="https://auth.example.com`},
+		"missing equality": {header: `Bearer realm`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ch, ok := parseChallenge(test.header)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.Equal(t, test.expected, ch)
+			}
+		})
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	for registry, expected := range map[string]bool{
+		"localhost:5000":       true,
+		"127.0.0.1:5000":       true,
+		"[::1]:5000":           true,
+		"registry.example.com": false,
+		"10.0.0.1:5000":        false,
+	} {
+		assert.Equal(t, expected, IsLocal(registry), registry)
+	}
+}
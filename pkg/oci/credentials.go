@@ -0,0 +1,126 @@
+package oci
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dockerHubServer is the key of Docker Hub credentials in the docker config
+const dockerHubServer = "https://index.docker.io/v1/"
+
+type (
+	// Credential authenticates to a registry
+	Credential struct {
+		Username string
+		Password string
+	}
+
+	// dockerConfig is the part of the docker config file holding credentials
+	dockerConfig struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+		CredsStore  string            `json:"credsStore"`
+		CredHelpers map[string]string `json:"credHelpers"`
+	}
+)
+
+func (c Credential) basicAuth() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+}
+
+// DockerCredentials returns the credentials docker uses for the registry, as stored by "docker login".
+// The credential helper configured for the registry in credHelpers is asked first, then the one of credsStore,
+// then the auths of the config file. Empty credentials are returned if none are found.
+func DockerCredentials(registry string) (Credential, error) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credential{}, nil
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(configDir, "config.json"))
+	if os.IsNotExist(err) {
+		return Credential{}, nil
+	}
+	if err != nil {
+		return Credential{}, err
+	}
+	config := dockerConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Credential{}, fmt.Errorf("invalid docker config: %w", err)
+	}
+
+	server := registry
+	if registry == "docker.io" {
+		server = dockerHubServer
+	}
+	if helper, ok := config.CredHelpers[registry]; ok {
+		return helperCredentials(helper, server)
+	}
+	if config.CredsStore != "" {
+		cred, err := helperCredentials(config.CredsStore, server)
+		if err != nil || cred.Username != "" {
+			return cred, err
+		}
+	}
+	for key, auth := range config.Auths {
+		if serverHost(key) != serverHost(server) {
+			continue
+		}
+		if auth.Username != "" {
+			return Credential{Username: auth.Username, Password: auth.Password}, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return Credential{}, fmt.Errorf("invalid docker credentials of %s: %w", registry, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return Credential{}, fmt.Errorf("invalid docker credentials of %s", registry)
+		}
+		return Credential{Username: parts[0], Password: parts[1]}, nil
+	}
+	return Credential{}, nil
+}
+
+// helperCredentials runs "docker-credential-<helper> get", which reads the server on standard input and prints
+// its credentials as JSON. Identity tokens, returned with the <token> user name, are used as passwords.
+func helperCredentials(helper, server string) (Credential, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(string(out), "credentials not found") || strings.Contains(stderr.String(), "credentials not found") {
+			return Credential{}, nil
+		}
+		return Credential{}, fmt.Errorf("docker credential helper %s failed: %w", helper, err)
+	}
+	var cred struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return Credential{}, fmt.Errorf("invalid output of docker credential helper %s: %w", helper, err)
+	}
+	return Credential{Username: cred.Username, Password: cred.Secret}, nil
+}
+
+// serverHost returns the host of a server in the docker config, which may be given as a URL
+func serverHost(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	return strings.SplitN(server, "/", 2)[0]
+}
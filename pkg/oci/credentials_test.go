@@ -0,0 +1,84 @@
+package oci
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDockerCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	dir, err := ioutil.TempDir("", "cli-docker")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	// the helper returns credentials for helper.example.com only, like helpers do for servers they do not know
+	helper := `#!/bin/sh
+read server
+if [ "$server" = "helper.example.com" ] || [ "$server" = "store.example.com" ]; then
+  echo '{"ServerURL": "'$server'", "Username": "'$1'-user", "Secret": "helper-secret"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-credential-broken"), []byte("#!/bin/sh\nexit 2\n"), 0755))
+	path := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", dir))
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+		require.NoError(t, os.Unsetenv("DOCKER_CONFIG"))
+	}()
+	auth := base64.StdEncoding.EncodeToString([]byte("auth-user:auth:secret"))
+	config := `{
+  "auths": {
+    "https://auths.example.com/v1/": {"auth": "` + auth + `"},
+    "https://index.docker.io/v1/": {"username": "hub-user", "password": "hub-secret"},
+    "invalid.example.com": {"auth": "???"}
+  },
+  "credsStore": "test",
+  "credHelpers": {"helper.example.com": "test", "broken.example.com": "broken"}
+}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644))
+
+	tests := map[string]struct {
+		registry  string
+		expected  Credential
+		withError string
+	}{
+		"credential helper":           {registry: "helper.example.com", expected: Credential{Username: "get-user", Password: "helper-secret"}},
+		"credentials store":           {registry: "store.example.com", expected: Credential{Username: "get-user", Password: "helper-secret"}},
+		"auths, store has none":       {registry: "auths.example.com", expected: Credential{Username: "auth-user", Password: "auth:secret"}},
+		"docker hub":                  {registry: "docker.io", expected: Credential{Username: "hub-user", Password: "hub-secret"}},
+		"no credentials":              {registry: "other.example.com"},
+		"failing credential helper":   {registry: "broken.example.com", withError: "docker credential helper broken failed"},
+		"invalid credentials in file": {registry: "invalid.example.com", withError: "invalid docker credentials of invalid.example.com"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cred, err := DockerCredentials(test.registry)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cred)
+		})
+	}
+
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", filepath.Join(dir, "missing")))
+	cred, err := DockerCredentials("helper.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, Credential{}, cred, "no credentials without a docker config")
+}
@@ -0,0 +1,97 @@
+// Package oci pulls artifacts, such as Akamai CLI packages, from registries implementing the OCI distribution
+// specification, authenticating with the credentials docker is configured with.
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scheme prefixes references of packages published in OCI registries on the command line
+const Scheme = "oci://"
+
+const defaultTag = "latest"
+
+var (
+	digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+	tagPattern    = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+)
+
+// Reference identifies an artifact in a registry, e.g. registry.example.com/akamai/cli-dns:1.2.0.
+// An artifact referenced by digest is pinned to that digest, whatever its tag points to.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses a reference of the form [oci://]<registry>/<repository>[:<tag>][@<digest>].
+// The tag defaults to latest if neither a tag nor a digest is given.
+func ParseReference(s string) (Reference, error) {
+	ref := Reference{}
+	rest := strings.TrimPrefix(s, Scheme)
+	if i := strings.Index(rest, "@"); i >= 0 {
+		rest, ref.Digest = rest[:i], rest[i+1:]
+		if !digestPattern.MatchString(ref.Digest) {
+			return Reference{}, fmt.Errorf("invalid digest %q in %s, expected sha256:<64 hex digits>", ref.Digest, s)
+		}
+	}
+	slash := strings.Index(rest, "/")
+	if slash <= 0 {
+		return Reference{}, fmt.Errorf("invalid reference %s, expected <registry>/<repository>[:<tag>]", s)
+	}
+	ref.Registry, rest = rest[:slash], rest[slash+1:]
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		rest, ref.Tag = rest[:i], rest[i+1:]
+		if !tagPattern.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("invalid tag %q in %s", ref.Tag, s)
+		}
+	}
+	if rest == "" || strings.HasSuffix(rest, "/") || strings.ToLower(rest) != rest {
+		return Reference{}, fmt.Errorf("invalid repository %q in %s", rest, s)
+	}
+	ref.Repository = rest
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+	return ref, nil
+}
+
+// String returns the reference in the form accepted by ParseReference, without scheme
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Name returns the last component of the repository, e.g. cli-dns for registry.example.com/akamai/cli-dns:1.2.0
+func (r Reference) Name() string {
+	return r.Repository[strings.LastIndex(r.Repository, "/")+1:]
+}
+
+// manifestReference is the digest of the manifest if the reference is pinned, its tag otherwise
+func (r Reference) manifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// host returns the host serving the registry API, which differs from the registry name for Docker Hub
+func (r Reference) host() string {
+	if r.Registry == "docker.io" {
+		return "registry-1.docker.io"
+	}
+	return r.Registry
+}
+
+// ErrDigest is returned when a manifest or blob does not match its digest
+var ErrDigest = errors.New("digest mismatch")
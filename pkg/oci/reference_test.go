@@ -0,0 +1,51 @@
+package oci
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := map[string]struct {
+		ref       string
+		expected  Reference
+		withError string
+	}{
+		"tag":               {ref: "oci://registry.example.com/akamai/cli-dns:1.2.0", expected: Reference{Registry: "registry.example.com", Repository: "akamai/cli-dns", Tag: "1.2.0"}},
+		"no tag":            {ref: "registry.example.com/cli-dns", expected: Reference{Registry: "registry.example.com", Repository: "cli-dns", Tag: "latest"}},
+		"digest":            {ref: "oci://registry.example.com/akamai/cli-dns@" + digest, expected: Reference{Registry: "registry.example.com", Repository: "akamai/cli-dns", Digest: digest}},
+		"tag and digest":    {ref: "oci://localhost:5000/cli-dns:1.2.0@" + digest, expected: Reference{Registry: "localhost:5000", Repository: "cli-dns", Tag: "1.2.0", Digest: digest}},
+		"registry port":     {ref: "oci://localhost:5000/akamai/cli-dns", expected: Reference{Registry: "localhost:5000", Repository: "akamai/cli-dns", Tag: "latest"}},
+		"no registry":       {ref: "oci://cli-dns:1.2.0", withError: "invalid reference oci://cli-dns:1.2.0, expected <registry>/<repository>[:<tag>]"},
+		"invalid digest":    {ref: "oci://registry.example.com/cli-dns@sha256:abc", withError: `invalid digest "sha256:abc"`},
+		"invalid tag":       {ref: "oci://registry.example.com/cli-dns:-1", withError: `invalid tag "-1"`},
+		"uppercase":         {ref: "oci://registry.example.com/Akamai/cli-dns", withError: `invalid repository "Akamai/cli-dns"`},
+		"empty repository":  {ref: "oci://registry.example.com/", withError: `invalid repository ""`},
+		"trailing slash":    {ref: "oci://registry.example.com/akamai/", withError: `invalid repository "akamai/"`},
+		"empty after colon": {ref: "oci://registry.example.com/cli-dns:", withError: `invalid tag ""`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseReference(test.ref)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ref)
+			again, err := ParseReference(ref.String())
+			require.NoError(t, err)
+			assert.Equal(t, ref, again)
+		})
+	}
+}
+
+func TestReferenceName(t *testing.T) {
+	ref, err := ParseReference("oci://registry.example.com/akamai/cli-dns:1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "cli-dns", ref.Name())
+}
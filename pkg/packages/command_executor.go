@@ -1,27 +1,85 @@
 package packages
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
+	"sync"
+
+	"github.com/akamai/cli/pkg/terminal"
 )
 
 type (
 	executor interface {
-		ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error)
+		ExecCommand(ctx context.Context, cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error)
 		LookPath(string) (string, error)
 		FileExists(string) (bool, error)
 	}
 
 	defaultExecutor struct{}
+
+	// lockedWriter serializes the writes to w made by several goroutines
+	lockedWriter struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
 )
 
-func (d *defaultExecutor) ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
+// ExecCommand runs the command, killing it if the context is done before the command finishes
+// Environment variables set for the package operation in the context are added to the command environment.
+func (d *defaultExecutor) ExecCommand(ctx context.Context, cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
+	if env := operationEnv(ctx); len(env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, env...)
+	}
+	if ctx.Done() != nil {
+		// the context of a command can only be set when it is created, so every exported field is copied to a new one
+		ctxCmd := exec.CommandContext(ctx, cmd.Path)
+		ctxCmd.Args, ctxCmd.Dir, ctxCmd.Env = cmd.Args, cmd.Dir, cmd.Env
+		ctxCmd.Stdin, ctxCmd.Stdout, ctxCmd.Stderr = cmd.Stdin, cmd.Stdout, cmd.Stderr
+		ctxCmd.ExtraFiles, ctxCmd.SysProcAttr = cmd.ExtraFiles, cmd.SysProcAttr
+		cmd = ctxCmd
+	}
+	if w := terminal.OutputWriter(ctx); w != nil {
+		return streamCommand(cmd, w, len(withCombinedOutput) > 0)
+	}
 	if len(withCombinedOutput) > 0 {
 		return cmd.CombinedOutput()
 	}
 	return cmd.Output()
 }
 
+// streamCommand runs the command writing its output to w as it is produced, while still capturing it as cmd.Output does
+func streamCommand(cmd *exec.Cmd, w io.Writer, combined bool) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	if combined {
+		cmd.Stdout = io.MultiWriter(&stdout, w)
+		cmd.Stderr = cmd.Stdout
+	} else {
+		// exec copies separate streams to their writers from separate goroutines
+		w = &lockedWriter{w: w}
+		cmd.Stdout = io.MultiWriter(&stdout, w)
+		cmd.Stderr = io.MultiWriter(&stderr, w)
+	}
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
 func (d *defaultExecutor) LookPath(file string) (string, error) {
 	return exec.LookPath(file)
 }
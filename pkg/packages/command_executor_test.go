@@ -1,20 +1,98 @@
 package packages
 
 import (
+	"bytes"
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecCommand(t *testing.T) {
 	executor := defaultExecutor{}
 	cmd := exec.Command("echo", "test")
-	res, err := executor.ExecCommand(cmd)
+	res, err := executor.ExecCommand(context.Background(), cmd)
 	assert.NoError(t, err)
 	assert.Equal(t, "test\n", string(res))
 }
 
+func TestExecCommandOperationEnv(t *testing.T) {
+	executor := defaultExecutor{}
+	ctx := withEnv(context.Background(), "TMPDIR", "/tmp/akamai-cli-test")
+	cmd := exec.Command("sh", "-c", "echo $TMPDIR")
+	res, err := executor.ExecCommand(ctx, cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/akamai-cli-test\n", string(res))
+}
+
+func TestExecCommandTimeout(t *testing.T) {
+	executor := defaultExecutor{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cmd := exec.Command("sleep", "5")
+	start := time.Now()
+	_, err := executor.ExecCommand(ctx, cmd)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestExecCommandTimeoutKeepsExtraFiles(t *testing.T) {
+	executor := defaultExecutor{}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+	cmd := exec.Command("sh", "-c", "echo extra >&3")
+	cmd.ExtraFiles = []*os.File{w}
+	_, err = executor.ExecCommand(ctx, cmd)
+	require.NoError(t, w.Close())
+	require.NoError(t, err)
+	extra, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "extra\n", string(extra))
+}
+
+func TestStreamCommand(t *testing.T) {
+	tests := map[string]struct {
+		combined         bool
+		expectedOutput   string
+		expectedStreamed string
+	}{
+		"stdout only": {
+			expectedOutput:   "out\n",
+			expectedStreamed: "out\nerr\n",
+		},
+		"combined output": {
+			combined:         true,
+			expectedOutput:   "out\nerr\n",
+			expectedStreamed: "out\nerr\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var streamed bytes.Buffer
+			cmd := exec.Command("sh", "-c", "echo out; echo err >&2; exit 3")
+			res, err := streamCommand(cmd, &streamed, test.combined)
+			require.Error(t, err)
+			exitErr, ok := err.(*exec.ExitError)
+			require.True(t, ok)
+			assert.Equal(t, 3, exitErr.ExitCode())
+			assert.Equal(t, test.expectedOutput, string(res))
+			// stdout and stderr are copied separately unless combined, so their lines may be streamed in any order
+			assert.ElementsMatch(t, strings.Split(test.expectedStreamed, "\n"), strings.Split(streamed.String(), "\n"))
+		})
+	}
+}
+
 func TestLookPath(t *testing.T) {
 	executor := defaultExecutor{}
 	res, err := executor.LookPath("go")
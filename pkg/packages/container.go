@@ -0,0 +1,165 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+// BuildContainer configures installing package dependencies and building binaries inside a container instead of on the host
+type BuildContainer struct {
+	// Engine is the container engine executable, such as docker or podman
+	Engine string
+	// Images maps languages to the builder images used for them, instead of the default ones
+	Images map[string]string
+}
+
+// builder images used when no other image is configured, official images of the language runtimes.
+// Only languages whose installed dependencies do not depend on the interpreter of the host can be built in a container:
+// Go binaries are cross-compiled for the host and node_modules are loaded by any Node.js version.
+var defaultBuildImages = map[string]string{
+	Go:         "golang:latest",
+	Javascript: "node:lts",
+}
+
+// containerProxyEnv lists the environment variables passed from the host to the builder container, if set
+var containerProxyEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+var buildContainerContext contextType = "build-container"
+
+// ErrContainerEngineNotFound is returned when builds are configured to run in a container, but the engine is not installed
+var ErrContainerEngineNotFound = errors.New("unable to locate container engine")
+
+// WithBuildContainer makes package installations using the context build packages in a container
+func WithBuildContainer(ctx context.Context, container BuildContainer) context.Context {
+	return context.WithValue(ctx, buildContainerContext, container)
+}
+
+func buildContainer(ctx context.Context) (BuildContainer, bool) {
+	container, ok := ctx.Value(buildContainerContext).(BuildContainer)
+	return container, ok && container.Engine != ""
+}
+
+// image returns the builder image for given language, false if packages in that language cannot be built in a container
+func (c BuildContainer) image(lang string) (string, bool) {
+	image, ok := defaultBuildImages[lang]
+	if !ok {
+		return "", false
+	}
+	if configured := c.Images[lang]; configured != "" {
+		image = configured
+	}
+	return image, true
+}
+
+// installInContainer runs the same dependency installation and build steps as an installation on the host,
+// each in a new container with the package directory mounted, so the results are written to the package directory
+func (l *langManager) installInContainer(ctx context.Context, engine, image, lang, dir string, commands []string) error {
+	logger := log.FromContext(ctx)
+	bin, err := l.commandExecutor.LookPath(engine)
+	if err != nil {
+		return fmt.Errorf("%w: %s. Please verify if the executable is included in your PATH", ErrContainerEngineNotFound, engine)
+	}
+	logger.Debugf("Building package in %s container using %s", image, bin)
+	run := func(ctx context.Context, env []string, args ...string) error {
+		return runInContainer(ctx, l.commandExecutor, bin, image, dir, env, args...)
+	}
+
+	switch lang {
+	case Go:
+		env := []string{"GOOS=" + runtime.GOOS, "GOARCH=" + runtime.GOARCH, "CGO_ENABLED=0", "GOPATH=/tmp/go", "GOCACHE=/tmp/go-cache"}
+		err := runStep(ctx, StepInstall, func(ctx context.Context) error {
+			if ok, _ := l.commandExecutor.FileExists(filepath.Join(dir, "go.sum")); !ok {
+				if dep, _ := l.commandExecutor.FileExists(filepath.Join(dir, "Gopkg.lock")); !dep {
+					return nil
+				}
+				if err := run(ctx, env, "go", "mod", "init", filepath.Base(dir)); err != nil {
+					return fmt.Errorf("%w: %s", ErrPackageManagerExec, "go mod init")
+				}
+			}
+			if err := run(ctx, env, "go", "mod", "tidy"); err != nil {
+				return fmt.Errorf("%w: %s", ErrPackageManagerExec, "go mod")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return runStep(ctx, StepBuild, func(ctx context.Context) error {
+			for _, command := range commands {
+				pkg := "."
+				if len(commands) > 1 {
+					pkg = "./" + command
+				}
+				if err := run(ctx, env, "go", "build", "-o", "akamai-"+strings.ToLower(command), pkg); err != nil {
+					return fmt.Errorf("%w: %s", ErrPackageCompileFailure, command)
+				}
+			}
+			return nil
+		})
+	case Javascript:
+		return runStep(ctx, StepInstall, func(ctx context.Context) error {
+			if ok, _ := l.commandExecutor.FileExists(filepath.Join(dir, "yarn.lock")); ok {
+				if err := run(ctx, nil, "yarn", "install"); err != nil {
+					return fmt.Errorf("%w: %s", ErrPackageManagerExec, "yarn")
+				}
+			}
+			if ok, _ := l.commandExecutor.FileExists(filepath.Join(dir, "package.json")); ok {
+				if err := run(ctx, nil, "npm", "install"); err != nil {
+					return fmt.Errorf("%w: %s", ErrPackageManagerExec, "npm")
+				}
+			}
+			return nil
+		})
+	}
+	return ErrUnknownLang
+}
+
+// runInContainer executes the command in a new container of given image, with dir mounted as its working directory.
+// The temporary directory of the package operation is mounted as /tmp, so that caches are shared by the steps,
+// and the command runs as the current user, so that the files it creates are not owned by root.
+func runInContainer(ctx context.Context, cmdExecutor executor, engine, image, dir string, env []string, args ...string) error {
+	logger := log.FromContext(ctx)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	runArgs := []string{engine, "run", "--rm", "-v", absDir + ":/src", "-w", "/src", "-e", "HOME=/tmp"}
+	for _, kv := range operationEnv(ctx) {
+		if tmpDir := strings.TrimPrefix(kv, "TMPDIR="); tmpDir != kv {
+			runArgs = append(runArgs, "-v", tmpDir+":/tmp")
+			break
+		}
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		runArgs = append(runArgs, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	for _, key := range containerProxyEnv {
+		if _, ok := os.LookupEnv(key); ok {
+			runArgs = append(runArgs, "-e", key)
+		}
+	}
+	for _, kv := range env {
+		runArgs = append(runArgs, "-e", kv)
+	}
+	runArgs = append(runArgs, image)
+	runArgs = append(runArgs, args...)
+
+	cmd := exec.Command(runArgs[0], runArgs[1:]...)
+	cmd.Dir = dir
+	if _, err := cmdExecutor.ExecCommand(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			logger.Debugf("Unable to execute %s in %s container: \n%s", strings.Join(args, " "), image, exitErr.Stderr)
+		}
+		return err
+	}
+	return nil
+}
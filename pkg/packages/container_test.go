@@ -0,0 +1,173 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestInstallInContainer(t *testing.T) {
+	for _, key := range containerProxyEnv {
+		if val, ok := os.LookupEnv(key); ok {
+			require.NoError(t, os.Unsetenv(key))
+			defer func(key, val string) { require.NoError(t, os.Setenv(key, val)) }(key, val)
+		}
+	}
+	require.NoError(t, os.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128"))
+	defer func() { require.NoError(t, os.Unsetenv("HTTPS_PROXY")) }()
+
+	absDir, err := filepath.Abs("testDir")
+	require.NoError(t, err)
+	run := func(image string, env []string, args ...string) *exec.Cmd {
+		runArgs := []string{"/test/docker", "run", "--rm", "-v", absDir + ":/src", "-w", "/src", "-e", "HOME=/tmp", "-v", "/tmp/op:/tmp"}
+		if uid := os.Getuid(); uid >= 0 {
+			runArgs = append(runArgs, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+		}
+		runArgs = append(runArgs, "-e", "HTTPS_PROXY")
+		for _, kv := range env {
+			runArgs = append(runArgs, "-e", kv)
+		}
+		runArgs = append(append(runArgs, image), args...)
+		cmd := exec.Command(runArgs[0], runArgs[1:]...)
+		cmd.Dir = "testDir"
+		return cmd
+	}
+	goEnv := []string{"GOOS=" + runtime.GOOS, "GOARCH=" + runtime.GOARCH, "CGO_ENABLED=0", "GOPATH=/tmp/go", "GOCACHE=/tmp/go-cache"}
+
+	tests := map[string]struct {
+		lang, image string
+		commands    []string
+		init        func(*mocked)
+		withError   error
+	}{
+		"go modules, multiple commands": {
+			lang:     Go,
+			image:    "golang:1.16",
+			commands: []string{"test1", "test2"},
+			init: func(m *mocked) {
+				m.On("FileExists", "testDir/go.sum").Return(true, nil)
+				m.On("ExecCommand", run("golang:1.16", goEnv, "go", "mod", "tidy")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("golang:1.16", goEnv, "go", "build", "-o", "akamai-test1", "./test1")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("golang:1.16", goEnv, "go", "build", "-o", "akamai-test2", "./test2")).Return(nil, nil).Once()
+			},
+		},
+		"go modules initialized from dep": {
+			lang:     Go,
+			image:    "golang:latest",
+			commands: []string{"test"},
+			init: func(m *mocked) {
+				m.On("FileExists", "testDir/go.sum").Return(false, nil)
+				m.On("FileExists", "testDir/Gopkg.lock").Return(true, nil)
+				m.On("ExecCommand", run("golang:latest", goEnv, "go", "mod", "init", "testDir")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("golang:latest", goEnv, "go", "mod", "tidy")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("golang:latest", goEnv, "go", "build", "-o", "akamai-test", ".")).Return(nil, nil).Once()
+			},
+		},
+		"go build fails": {
+			lang:     Go,
+			image:    "golang:latest",
+			commands: []string{"test"},
+			init: func(m *mocked) {
+				m.On("FileExists", "testDir/go.sum").Return(true, nil)
+				m.On("ExecCommand", run("golang:latest", goEnv, "go", "mod", "tidy")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("golang:latest", goEnv, "go", "build", "-o", "akamai-test", ".")).Return(nil, &exec.ExitError{}).Once()
+			},
+			withError: ErrPackageCompileFailure,
+		},
+		"javascript with yarn and npm": {
+			lang:  Javascript,
+			image: "node:lts",
+			init: func(m *mocked) {
+				m.On("FileExists", "testDir/yarn.lock").Return(true, nil)
+				m.On("FileExists", "testDir/package.json").Return(true, nil)
+				m.On("ExecCommand", run("node:lts", nil, "yarn", "install")).Return(nil, nil).Once()
+				m.On("ExecCommand", run("node:lts", nil, "npm", "install")).Return(nil, nil).Once()
+			},
+		},
+		"npm fails": {
+			lang:  Javascript,
+			image: "node:lts",
+			init: func(m *mocked) {
+				m.On("FileExists", "testDir/yarn.lock").Return(false, nil)
+				m.On("FileExists", "testDir/package.json").Return(true, nil)
+				m.On("ExecCommand", run("node:lts", nil, "npm", "install")).Return(nil, fmt.Errorf("oops")).Once()
+			},
+			withError: ErrPackageManagerExec,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mocked)
+			m.On("LookPath", "docker").Return("/test/docker", nil).Once()
+			test.init(m)
+			l := langManager{m}
+			ctx := withEnv(context.Background(), "TMPDIR", "/tmp/op")
+			err := l.installInContainer(ctx, "docker", test.image, test.lang, "testDir", test.commands)
+			m.AssertExpectations(t)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInstallBuildContainer(t *testing.T) {
+	tests := map[string]struct {
+		reqs      LanguageRequirements
+		container BuildContainer
+		init      func(*mocked)
+		withError error
+	}{
+		"engine not found": {
+			reqs:      LanguageRequirements{Go: "1.14.0"},
+			container: BuildContainer{Engine: "podman"},
+			init: func(m *mocked) {
+				m.On("LookPath", "podman").Return("", fmt.Errorf("not found")).Once()
+			},
+			withError: ErrContainerEngineNotFound,
+		},
+		"language built on the host": {
+			reqs:      LanguageRequirements{Ruby: "2.0.0"},
+			container: BuildContainer{Engine: "docker"},
+			init: func(m *mocked) {
+				m.On("LookPath", "ruby").Return("", fmt.Errorf("not found")).Once()
+			},
+			withError: ErrRuntimeNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mocked)
+			test.init(m)
+			l := langManager{m}
+			err := l.Install(WithBuildContainer(context.Background(), test.container), "testDir", test.reqs, []string{"test"})
+			m.AssertExpectations(t)
+			assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+		})
+	}
+}
+
+func TestBuildContainerImage(t *testing.T) {
+	container := BuildContainer{Engine: "docker", Images: map[string]string{Go: "registry.example.com/golang:1.16"}}
+	image, ok := container.image(Go)
+	assert.True(t, ok)
+	assert.Equal(t, "registry.example.com/golang:1.16", image)
+	image, ok = container.image(Javascript)
+	assert.True(t, ok)
+	assert.Equal(t, "node:lts", image)
+	_, ok = container.image(Python)
+	assert.False(t, ok)
+	_, ok = buildContainer(WithBuildContainer(context.Background(), BuildContainer{}))
+	assert.False(t, ok)
+}
@@ -44,7 +44,7 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 
 	if ver != "" && ver != "*" {
 		cmd := exec.Command(bin, "version")
-		output, _ := l.commandExecutor.ExecCommand(cmd)
+		output, _ := l.commandExecutor.ExecCommand(ctx, cmd)
 		logger.Debugf("%s version: %s", bin, bytes.ReplaceAll(output, []byte("\n"), []byte("")))
 		r := regexp.MustCompile("go version go(.*?) .*")
 		matches := r.FindStringSubmatch(string(output))
@@ -66,17 +66,25 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 	if err != nil {
 		return cli.Exit(color.RedString("Unable to determine CLI home directory"), 1)
 	}
-	if err := os.Setenv("GOPATH", cliPath); err != nil {
+	ctx = withEnv(ctx, "GOPATH", cliPath)
+	err = runStep(ctx, StepInstall, func(ctx context.Context) error {
+		if err := installGolangModules(ctx, l.commandExecutor, dir); err != nil {
+			logger.Info("go.sum not found, running glide package manager[WARN: Usage of Glide is DEPRECTED]")
+			return installGolangDepsGlide(ctx, l.commandExecutor, dir)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
-	if err = installGolangModules(logger, l.commandExecutor, dir); err != nil {
-		logger.Info("go.sum not found, running glide package manager[WARN: Usage of Glide is DEPRECTED]")
 
-		if err = installGolangDepsGlide(logger, l.commandExecutor, dir); err != nil {
-			return err
-		}
-	}
+	return runStep(ctx, StepBuild, func(ctx context.Context) error {
+		return buildGolang(ctx, l.commandExecutor, bin, dir, commands)
+	})
+}
 
+func buildGolang(ctx context.Context, cmdExecutor executor, bin, dir string, commands []string) error {
+	logger := log.FromContext(ctx)
 	for _, command := range commands {
 		execName := "akamai-" + strings.ToLower(command)
 
@@ -88,8 +96,7 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 		}
 
 		cmd.Dir = dir
-		_, err = l.commandExecutor.ExecCommand(cmd)
-		if err != nil {
+		if _, err := cmdExecutor.ExecCommand(ctx, cmd); err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
 				logger.Debugf("Unable to build binary (%s): \n%s", execName, exitErr.Stderr)
@@ -101,7 +108,8 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 	return nil
 }
 
-func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string) error {
+func installGolangDepsGlide(ctx context.Context, cmdExecutor executor, dir string) error {
+	logger := log.FromContext(ctx)
 	if ok, _ := cmdExecutor.FileExists(filepath.Join(dir, "glide.lock")); !ok {
 		return nil
 	}
@@ -110,7 +118,7 @@ func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string)
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
@@ -126,7 +134,8 @@ func installGolangDepsGlide(logger log.Logger, cmdExecutor executor, dir string)
 	return nil
 }
 
-func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) error {
+func installGolangModules(ctx context.Context, cmdExecutor executor, dir string) error {
+	logger := log.FromContext(ctx)
 	bin, err := cmdExecutor.LookPath("go")
 	if err != nil {
 		err = fmt.Errorf("%w: %s. Please verify if the executable is included in your PATH", ErrRuntimeNotFound, "go")
@@ -142,7 +151,7 @@ func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) e
 		moduleName := filepath.Base(dir)
 		cmd := exec.Command(bin, "mod", "init", moduleName)
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
@@ -154,7 +163,7 @@ func installGolangModules(logger log.Logger, cmdExecutor executor, dir string) e
 	logger.Info("go.sum found, running go module package manager")
 	cmd := exec.Command(bin, "mod", "tidy")
 	cmd.Dir = dir
-	_, err = cmdExecutor.ExecCommand(cmd)
+	_, err = cmdExecutor.ExecCommand(ctx, cmd)
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
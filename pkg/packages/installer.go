@@ -0,0 +1,202 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HelperPrefix is the prefix of the executables installing packages written in languages without an Installer. The
+// helper of a language is named after the requirement in cli.json, e.g. akamai-installer-deno for "deno", and looked
+// up in PATH. It is run as:
+//
+//	akamai-installer-<lang> install <requirement> <command>...    in the package directory, to install dependencies
+//	akamai-installer-<lang> exec <requirement> <executable>       to print the command line running an executable, one argument per line
+//	akamai-installer-<lang> version <requirement>                 to print the version of the runtime
+//
+// A non-zero exit status fails the operation, with the error output of the helper as the reason.
+const HelperPrefix = "akamai-installer-"
+
+// Installer installs the dependencies of packages written in a language and locates the runtime of their commands.
+// Installers of the built-in languages are provided, installers of other languages are added with RegisterInstaller,
+// e.g. in the init function of a file built with a build tag, or by an external helper, see HelperPrefix.
+type Installer interface {
+	// Install installs the dependencies of the package in dir, and builds its commands if the language requires it
+	Install(ctx context.Context, dir, requirement string, commands []string) error
+	// FindExec returns the command line running the executable of a command
+	FindExec(ctx context.Context, requirement, cmdExec string) ([]string, error)
+	// CheckRuntime locates the runtime, verifies that its version meets the requirement and returns the version
+	CheckRuntime(ctx context.Context, requirement string) (string, error)
+}
+
+var (
+	installersLock sync.RWMutex
+	installers     = make(map[string]Installer)
+)
+
+// RegisterInstaller makes installer handle the packages requiring lang in cli.json. It panics if an installer
+// is already registered for lang or lang is a built-in language.
+func RegisterInstaller(lang string, installer Installer) {
+	lang = strings.ToLower(lang)
+	installersLock.Lock()
+	defer installersLock.Unlock()
+	if _, ok := runtimeVersions[lang]; ok || builtinRequirement(lang) {
+		panic(fmt.Sprintf("packages: %s is a built-in language", lang))
+	}
+	if _, ok := installers[lang]; ok {
+		panic(fmt.Sprintf("packages: installer of %s registered twice", lang))
+	}
+	installers[lang] = installer
+}
+
+// Languages returns the languages which packages can be written in: built-in ones and those of registered installers,
+// without those of external helpers
+func Languages() []string {
+	installersLock.RLock()
+	defer installersLock.RUnlock()
+	langs := make([]string, 0, len(runtimeVersions)+len(installers))
+	for lang := range runtimeVersions {
+		langs = append(langs, lang)
+	}
+	for lang := range installers {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// installer returns the Installer of lang: the built-in one, the registered one or an external helper, in that order
+func (l *langManager) installer(lang string) (Installer, error) {
+	if lang == Undefined {
+		return nil, ErrUnknownLang
+	}
+	if _, ok := runtimeVersions[lang]; ok {
+		return &builtinInstaller{lang: lang, l: l}, nil
+	}
+	installersLock.RLock()
+	installer, ok := installers[lang]
+	installersLock.RUnlock()
+	if ok {
+		return installer, nil
+	}
+	if bin, err := l.commandExecutor.LookPath(HelperPrefix + lang); err == nil {
+		return &helperInstaller{bin: bin, commandExecutor: l.commandExecutor}, nil
+	}
+	return nil, fmt.Errorf("%w: no installer for %s, add %s%s to your PATH", ErrUnknownLang, lang, HelperPrefix, lang)
+}
+
+// builtinInstaller is the Installer of a language supported by Akamai CLI itself
+type builtinInstaller struct {
+	lang string
+	l    *langManager
+}
+
+func (b *builtinInstaller) Install(ctx context.Context, dir, requirement string, commands []string) error {
+	switch b.lang {
+	case PHP:
+		return b.l.installPHP(ctx, dir, requirement)
+	case Javascript:
+		return b.l.installJavaScript(ctx, dir, requirement)
+	case Ruby:
+		return b.l.installRuby(ctx, dir, requirement)
+	case Python:
+		return b.l.installPython(ctx, dir, requirement)
+	case Go:
+		return b.l.installGolang(ctx, dir, requirement, commands)
+	}
+	return ErrUnknownLang
+}
+
+func (b *builtinInstaller) FindExec(ctx context.Context, requirement, cmdExec string) ([]string, error) {
+	// FIXME: Add support for other languages defined in readme: Ruby and PHP
+	switch b.lang {
+	case Javascript:
+		bin, err := b.l.commandExecutor.LookPath("node")
+		if err != nil {
+			bin, _ = b.l.commandExecutor.LookPath("nodejs")
+		}
+		return []string{bin, cmdExec}, nil
+	case Python:
+		bin, err := findPythonBin(ctx, b.l.commandExecutor, requirement)
+		if err != nil {
+			return nil, err
+		}
+		return []string{bin, cmdExec}, nil
+	default:
+		return []string{cmdExec}, nil
+	}
+}
+
+func (b *builtinInstaller) CheckRuntime(ctx context.Context, requirement string) (string, error) {
+	return b.l.checkBuiltinRuntime(ctx, b.lang, requirement)
+}
+
+// helperInstaller is the Installer of a language provided by an external helper executable
+type helperInstaller struct {
+	bin             string
+	commandExecutor executor
+}
+
+func (h *helperInstaller) Install(ctx context.Context, dir, requirement string, commands []string) error {
+	return runStep(ctx, StepInstall, func(ctx context.Context) error {
+		cmd := exec.Command(h.bin, append([]string{"install", requirement}, commands...)...)
+		cmd.Dir = dir
+		if _, err := h.run(ctx, cmd); err != nil {
+			return fmt.Errorf("%w: %s", ErrPackageManagerExec, err)
+		}
+		return nil
+	})
+}
+
+func (h *helperInstaller) FindExec(ctx context.Context, requirement, cmdExec string) ([]string, error) {
+	output, err := h.run(ctx, exec.Command(h.bin, "exec", requirement, cmdExec))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			args = append(args, line)
+		}
+	}
+	if len(args) == 0 {
+		return []string{cmdExec}, nil
+	}
+	return args, nil
+}
+
+func (h *helperInstaller) CheckRuntime(ctx context.Context, requirement string) (string, error) {
+	output, err := h.run(ctx, exec.Command(h.bin, "version", requirement))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrRuntimeNotFound, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// run runs the helper, returning its error output as the error if it fails
+func (h *helperInstaller) run(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	output, err := h.commandExecutor.ExecCommand(ctx, cmd)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(strings.TrimSpace(string(exitErr.Stderr))) > 0 {
+		return output, fmt.Errorf("%s: %s", cmd.Args[1], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
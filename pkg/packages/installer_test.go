@@ -0,0 +1,171 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"testing"
+)
+
+type testInstaller struct {
+	installed []string
+}
+
+func (i *testInstaller) Install(_ context.Context, dir, requirement string, commands []string) error {
+	i.installed = append(i.installed, fmt.Sprintf("%s %s %v", dir, requirement, commands))
+	return nil
+}
+
+func (i *testInstaller) FindExec(_ context.Context, _, cmdExec string) ([]string, error) {
+	return []string{"/usr/bin/zig", "run", cmdExec}, nil
+}
+
+func (i *testInstaller) CheckRuntime(_ context.Context, requirement string) (string, error) {
+	return "0.11.0", nil
+}
+
+func TestRegisterInstaller(t *testing.T) {
+	installer := &testInstaller{}
+	RegisterInstaller("Zig", installer)
+	defer func() {
+		installersLock.Lock()
+		delete(installers, "zig")
+		installersLock.Unlock()
+	}()
+	assert.Contains(t, Languages(), "zig")
+	assert.Panics(t, func() { RegisterInstaller("zig", &testInstaller{}) })
+	assert.Panics(t, func() { RegisterInstaller("node", &testInstaller{}) })
+	assert.Panics(t, func() { RegisterInstaller(Javascript, &testInstaller{}) })
+
+	l := &langManager{&mocked{}}
+	reqs := LanguageRequirements{Other: map[string]string{"zig": "0.11"}}
+	require.NoError(t, l.Install(context.Background(), "testdata", reqs, []string{"test"}))
+	assert.Equal(t, []string{"testdata 0.11 [test]"}, installer.installed)
+	cmd, err := l.FindExec(context.Background(), reqs, "main.zig")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/usr/bin/zig", "run", "main.zig"}, cmd)
+	ver, err := l.CheckRuntime(context.Background(), reqs)
+	require.NoError(t, err)
+	assert.Equal(t, "0.11.0", ver)
+}
+
+func TestHelperInstaller(t *testing.T) {
+	helperArgs := func(args ...string) interface{} {
+		return mock.MatchedBy(func(cmd *exec.Cmd) bool {
+			return assert.ObjectsAreEqual(append([]string{"/bin/akamai-installer-deno"}, args...), cmd.Args)
+		})
+	}
+	tests := map[string]struct {
+		init      func(*mocked)
+		run       func(*langManager) (interface{}, error)
+		expected  interface{}
+		withError error
+	}{
+		"install": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", mock.MatchedBy(func(cmd *exec.Cmd) bool {
+					return cmd.Dir == "testdata" && assert.ObjectsAreEqual([]string{"/bin/akamai-installer-deno", "install", "1.30", "test"}, cmd.Args)
+				})).Return([]byte("done"), nil).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return nil, l.Install(context.Background(), "testdata", LanguageRequirements{Other: map[string]string{"deno": "1.30"}}, []string{"test"})
+			},
+		},
+		"install fails": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", helperArgs("install", "1.30", "test")).Return(nil, &exec.ExitError{Stderr: []byte("no deno.json\n")}).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return nil, l.Install(context.Background(), "testdata", LanguageRequirements{Other: map[string]string{"deno": "1.30"}}, []string{"test"})
+			},
+			withError: ErrPackageManagerExec,
+		},
+		"exec": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", helperArgs("exec", "1.30", "main.ts")).Return([]byte("/bin/deno\nrun\n--allow-net\nmain.ts\n"), nil).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return l.FindExec(context.Background(), LanguageRequirements{Other: map[string]string{"deno": "1.30"}}, "main.ts")
+			},
+			expected: []string{"/bin/deno", "run", "--allow-net", "main.ts"},
+		},
+		"exec as is": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", helperArgs("exec", "1.30", "main")).Return([]byte(""), nil).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return l.FindExec(context.Background(), LanguageRequirements{Other: map[string]string{"deno": "1.30"}}, "main")
+			},
+			expected: []string{"main"},
+		},
+		"version": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", helperArgs("version", "1.30")).Return([]byte("1.32.1\n"), nil).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return l.CheckRuntime(context.Background(), LanguageRequirements{Other: map[string]string{"deno": "1.30"}})
+			},
+			expected: "1.32.1",
+		},
+		"runtime not found": {
+			init: func(m *mocked) {
+				m.On("ExecCommand", helperArgs("version", "1.30")).Return(nil, &exec.ExitError{Stderr: []byte("deno not found")}).Once()
+			},
+			run: func(l *langManager) (interface{}, error) {
+				return l.CheckRuntime(context.Background(), LanguageRequirements{Other: map[string]string{"deno": "1.30"}})
+			},
+			withError: ErrRuntimeNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{}
+			m.On("LookPath", "akamai-installer-deno").Return("/bin/akamai-installer-deno", nil)
+			test.init(m)
+			res, err := test.run(&langManager{m})
+			m.AssertExpectations(t)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestNoInstaller(t *testing.T) {
+	m := &mocked{}
+	m.On("LookPath", "akamai-installer-deno").Return("", fmt.Errorf("not found"))
+	l := &langManager{m}
+	reqs := LanguageRequirements{Other: map[string]string{"deno": "1.30"}}
+
+	err := l.Install(context.Background(), "testdata", reqs, nil)
+	assert.True(t, errors.Is(err, ErrUnknownLang), "want: %s; got: %s", ErrUnknownLang, err)
+	_, err = l.CheckRuntime(context.Background(), reqs)
+	assert.True(t, errors.Is(err, ErrUnknownLang), "want: %s; got: %s", ErrUnknownLang, err)
+	cmd, err := l.FindExec(context.Background(), reqs, "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main"}, cmd)
+}
+
+func TestLanguageRequirementsJSON(t *testing.T) {
+	var reqs LanguageRequirements
+	require.NoError(t, json.Unmarshal([]byte(`{"python": "3.0.0", "Deno": "1.30", "zig": "", "os": ["linux"]}`), &reqs))
+	assert.Equal(t, LanguageRequirements{Python: "3.0.0", Other: map[string]string{"deno": "1.30", "zig": ""}}, reqs)
+	assert.Equal(t, Python, Language(reqs))
+	assert.Equal(t, "deno", Language(LanguageRequirements{Other: reqs.Other}))
+
+	data, err := json.Marshal(reqs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"go": "", "php": "", "node": "", "ruby": "", "python": "3.0.0", "deno": "1.30", "zig": ""}`, string(data))
+	data, err = json.Marshal(LanguageRequirements{Go: "1.14"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"go":"1.14","php":"","node":"","ruby":"","python":""}`, string(data))
+}
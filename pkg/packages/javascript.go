@@ -42,7 +42,7 @@ func (l *langManager) installJavaScript(ctx context.Context, dir, ver string) er
 
 	if ver != "" && ver != "*" {
 		cmd := exec.Command(bin, "-v")
-		output, _ := l.commandExecutor.ExecCommand(cmd)
+		output, _ := l.commandExecutor.ExecCommand(ctx, cmd)
 		logger.Debugf("%s -v: %s", bin, bytes.ReplaceAll(output, []byte("\n"), []byte("")))
 		r := regexp.MustCompile("^v(.*?)\\s*$")
 		matches := r.FindStringSubmatch(string(output))
@@ -57,15 +57,13 @@ func (l *langManager) installJavaScript(ctx context.Context, dir, ver string) er
 		}
 	}
 
-	if err := installNodeDepsYarn(ctx, l.commandExecutor, dir); err != nil {
-		return err
-	}
-
-	if err := installNodeDepsNpm(ctx, l.commandExecutor, dir); err != nil {
-		return err
-	}
+	return runStep(ctx, StepInstall, func(ctx context.Context) error {
+		if err := installNodeDepsYarn(ctx, l.commandExecutor, dir); err != nil {
+			return err
+		}
 
-	return nil
+		return installNodeDepsNpm(ctx, l.commandExecutor, dir)
+	})
 }
 
 func installNodeDepsYarn(ctx context.Context, cmdExecutor executor, dir string) error {
@@ -79,7 +77,7 @@ func installNodeDepsYarn(ctx context.Context, cmdExecutor executor, dir string)
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
@@ -106,7 +104,7 @@ func installNodeDepsNpm(ctx context.Context, cmdExecutor executor, dir string) e
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
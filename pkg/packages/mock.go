@@ -24,3 +24,9 @@ func (m *Mock) FindExec(_ context.Context, requirements LanguageRequirements, cm
 	}
 	return args.Get(0).([]string), args.Error(1)
 }
+
+// CheckRuntime mock
+func (m *Mock) CheckRuntime(_ context.Context, requirements LanguageRequirements) (string, error) {
+	args := m.Called(requirements)
+	return args.String(0), args.Error(1)
+}
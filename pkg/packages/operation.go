@@ -0,0 +1,45 @@
+package packages
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/akamai/cli/pkg/log"
+)
+
+var envContext contextType = "env"
+
+// withEnv returns a context with an environment variable set for commands executed as part of the package operation.
+// Unlike os.Setenv, it does not affect other operations running at the same time.
+func withEnv(ctx context.Context, key, value string) context.Context {
+	env := operationEnv(ctx)
+	return context.WithValue(ctx, envContext, append(env[:len(env):len(env)], key+"="+value))
+}
+
+// operationEnv returns environment variables set in the context with withEnv, in KEY=VALUE form
+func operationEnv(ctx context.Context) []string {
+	env, _ := ctx.Value(envContext).([]string)
+	return env
+}
+
+// startOperation creates a temporary directory used only by the installation of the package in given directory
+// and makes package managers use it instead of the shared one.
+// The returned function removes the directory, it has to be called whether the installation succeeded or not.
+func startOperation(ctx context.Context, dir string) (context.Context, func(), error) {
+	tmpDir, err := ioutil.TempDir("", "akamai-cli-"+filepath.Base(dir)+"-")
+	if err != nil {
+		return nil, nil, err
+	}
+	logger := log.FromContext(ctx)
+	logger.Debugf("Using temporary directory: %s", tmpDir)
+	for _, key := range []string{"TMPDIR", "TMP", "TEMP"} {
+		ctx = withEnv(ctx, key, tmpDir)
+	}
+	return ctx, func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logger.Warnf("Unable to remove temporary directory %s: %s", tmpDir, err)
+		}
+	}, nil
+}
@@ -0,0 +1,34 @@
+package packages
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestStartOperation(t *testing.T) {
+	ctx, cleanup, err := startOperation(context.Background(), "/src/cli-test")
+	require.NoError(t, err)
+	env := operationEnv(ctx)
+	require.Len(t, env, 3)
+	tmpDir := env[0][len("TMPDIR="):]
+	assert.Contains(t, tmpDir, "akamai-cli-cli-test-")
+	assert.Equal(t, []string{"TMPDIR=" + tmpDir, "TMP=" + tmpDir, "TEMP=" + tmpDir}, env)
+	_, err = os.Stat(tmpDir)
+	require.NoError(t, err)
+
+	cleanup()
+	_, err = os.Stat(tmpDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWithEnv(t *testing.T) {
+	ctx := withEnv(context.Background(), "A", "1")
+	first := withEnv(ctx, "B", "2")
+	second := withEnv(ctx, "C", "3")
+	assert.Equal(t, []string{"A=1"}, operationEnv(ctx))
+	assert.Equal(t, []string{"A=1", "B=2"}, operationEnv(first))
+	assert.Equal(t, []string{"A=1", "C=3"}, operationEnv(second))
+}
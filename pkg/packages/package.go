@@ -2,7 +2,11 @@ package packages
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
+
 	"github.com/akamai/cli/pkg/log"
 )
 
@@ -11,15 +15,18 @@ type (
 	LangManager interface {
 		Install(ctx context.Context, dir string, requirements LanguageRequirements, commands []string) error
 		FindExec(ctx context.Context, requirements LanguageRequirements, cmdExec string) ([]string, error)
+		CheckRuntime(ctx context.Context, requirements LanguageRequirements) (string, error)
 	}
 
-	// LanguageRequirements contains version requirements for all supported programming languages
+	// LanguageRequirements contains version requirements for all supported programming languages,
+	// Other holds the requirements of languages handled by registered installers or external helpers
 	LanguageRequirements struct {
-		Go     string `json:"go"`
-		Php    string `json:"php"`
-		Node   string `json:"node"`
-		Ruby   string `json:"ruby"`
-		Python string `json:"python"`
+		Go     string            `json:"go"`
+		Php    string            `json:"php"`
+		Node   string            `json:"node"`
+		Ruby   string            `json:"ruby"`
+		Python string            `json:"python"`
+		Other  map[string]string `json:"-"`
 	}
 )
 
@@ -43,6 +50,7 @@ var (
 	ErrPackageManagerExec            = errors.New("unable to execute package manager")
 	ErrPackageNeedsReinstall         = errors.New("you must reinstall this package to continue")
 	ErrPackageCompileFailure         = errors.New("unable to build binary")
+	ErrStepTimeout                   = errors.New("time limit exceeded")
 )
 
 type langManager struct {
@@ -58,48 +66,51 @@ func NewLangManager() LangManager {
 
 // Install builds and installs contents of a directory based on provided language requirements
 func (l *langManager) Install(ctx context.Context, dir string, reqs LanguageRequirements, commands []string) error {
+	ctx, cleanup, err := startOperation(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
 	lang, requirements := determineLangAndRequirements(reqs)
-	switch lang {
-	case PHP:
-		return l.installPHP(ctx, dir, requirements)
-	case Javascript:
-		return l.installJavaScript(ctx, dir, requirements)
-	case Ruby:
-		return l.installRuby(ctx, dir, requirements)
-	case Python:
-		return l.installPython(ctx, dir, requirements)
-	case Go:
-		return l.installGolang(ctx, dir, requirements, commands)
-	}
-	return ErrUnknownLang
+	if container, ok := buildContainer(ctx); ok && lang != Undefined {
+		if image, ok := container.image(lang); ok {
+			return l.installInContainer(ctx, container.Engine, image, lang, dir, commands)
+		}
+		log.FromContext(ctx).Warnf("Packages written in %s cannot be built in a container, installing dependencies on the host", lang)
+	}
+	installer, err := l.installer(lang)
+	if err != nil {
+		return err
+	}
+	return installer.Install(ctx, dir, requirements, commands)
 }
 
 // FindExec locates language's CLI executable
 func (l *langManager) FindExec(ctx context.Context, reqs LanguageRequirements, cmdExec string) ([]string, error) {
 	logger := log.FromContext(ctx)
 	lang, requirements := determineLangAndRequirements(reqs)
-	// FIXME: Add support for other languages defined in readme: Ruby and PHP
-	switch lang {
-	case Go:
-		return []string{cmdExec}, nil
-	case Javascript:
-		bin, err := l.commandExecutor.LookPath("node")
-		if err != nil {
-			bin, err = l.commandExecutor.LookPath("nodejs")
-		}
-		return []string{bin, cmdExec}, nil
-	case Python:
-		bin, err := findPythonBin(ctx, l.commandExecutor, requirements)
-		if err != nil {
-			return nil, err
-		}
-		return []string{bin, cmdExec}, nil
-	case Undefined:
+	if lang == Undefined {
 		logger.Debugf("command language is not defined")
 		return []string{cmdExec}, nil
-	default:
+	}
+	installer, err := l.installer(lang)
+	if err != nil {
+		logger.Debugf("%s, running %s as is", err, cmdExec)
 		return []string{cmdExec}, nil
 	}
+	return installer.FindExec(ctx, requirements, cmdExec)
+}
+
+// Language returns the language of a package with given requirements, Undefined if it has none
+func Language(reqs LanguageRequirements) string {
+	lang, _ := determineLangAndRequirements(reqs)
+	return lang
+}
+
+// Language returns the language of the package runtime and its version requirement, the same the package is installed with
+func (reqs LanguageRequirements) Language() (string, string) {
+	return determineLangAndRequirements(reqs)
 }
 
 func determineLangAndRequirements(reqs LanguageRequirements) (string, string) {
@@ -123,5 +134,64 @@ func determineLangAndRequirements(reqs LanguageRequirements) (string, string) {
 		return Python, reqs.Python
 	}
 
+	langs := make([]string, 0, len(reqs.Other))
+	for lang, requirement := range reqs.Other {
+		if requirement != "" {
+			langs = append(langs, lang)
+		}
+	}
+	if len(langs) > 0 {
+		sort.Strings(langs)
+		return langs[0], reqs.Other[langs[0]]
+	}
+
 	return Undefined, ""
 }
+
+// builtinRequirement reports whether key is a requirement of a built-in language in cli.json
+func builtinRequirement(key string) bool {
+	switch key {
+	case "go", "php", "node", "ruby", "python":
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes the requirements of the built-in languages, and any other string requirement into Other
+func (r *LanguageRequirements) UnmarshalJSON(data []byte) error {
+	type builtin LanguageRequirements
+	var reqs builtin
+	if err := json.Unmarshal(data, &reqs); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key, val := range all {
+		requirement, ok := val.(string)
+		if !ok || builtinRequirement(key) {
+			continue
+		}
+		if reqs.Other == nil {
+			reqs.Other = make(map[string]string)
+		}
+		reqs.Other[strings.ToLower(key)] = requirement
+	}
+	*r = LanguageRequirements(reqs)
+	return nil
+}
+
+// MarshalJSON encodes the requirements of the built-in languages followed by those in Other
+func (r LanguageRequirements) MarshalJSON() ([]byte, error) {
+	type builtin LanguageRequirements
+	data, err := json.Marshal(builtin(r))
+	if err != nil || len(r.Other) == 0 {
+		return data, err
+	}
+	other, err := json.Marshal(r.Other)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(data[:len(data)-1], ','), other[1:]...), nil
+}
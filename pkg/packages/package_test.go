@@ -153,7 +153,7 @@ func TestLangManager_FindExec(t *testing.T) {
 	}
 }
 
-func (m *mocked) ExecCommand(cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
+func (m *mocked) ExecCommand(_ context.Context, cmd *exec.Cmd, withCombinedOutput ...bool) ([]byte, error) {
 	var args mock.Arguments
 	if len(withCombinedOutput) > 0 {
 		args = m.Called(cmd, withCombinedOutput[0])
@@ -38,7 +38,7 @@ func (l *langManager) installPHP(ctx context.Context, dir, cmdReq string) error
 
 	if cmdReq != "" && cmdReq != "*" {
 		cmd := exec.Command(bin, "-v")
-		output, _ := l.commandExecutor.ExecCommand(cmd)
+		output, _ := l.commandExecutor.ExecCommand(ctx, cmd)
 		logger.Debugf("%s -v: %s", bin, output)
 		r := regexp.MustCompile("PHP (.*?) .*")
 		matches := r.FindStringSubmatch(string(output))
@@ -53,7 +53,10 @@ func (l *langManager) installPHP(ctx context.Context, dir, cmdReq string) error
 		}
 	}
 
-	if err := installPHPDepsComposer(ctx, l.commandExecutor, bin, dir); err != nil {
+	err = runStep(ctx, StepInstall, func(ctx context.Context) error {
+		return installPHPDepsComposer(ctx, l.commandExecutor, bin, dir)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -72,7 +75,7 @@ func installPHPDepsComposer(ctx context.Context, cmdExecutor executor, phpBin, d
 	if ok, _ := cmdExecutor.FileExists(phar); ok {
 		cmd := exec.Command(phpBin, phar, "install")
 		cmd.Dir = dir
-		_, err := cmdExecutor.ExecCommand(cmd)
+		_, err := cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
@@ -87,7 +90,7 @@ func installPHPDepsComposer(ctx context.Context, cmdExecutor executor, phpBin, d
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
@@ -102,7 +105,7 @@ func installPHPDepsComposer(ctx context.Context, cmdExecutor executor, phpBin, d
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
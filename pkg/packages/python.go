@@ -19,7 +19,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -43,7 +42,7 @@ func (l *langManager) installPython(ctx context.Context, dir, cmdReq string) err
 
 	if cmdReq != "" && cmdReq != "*" {
 		cmd := exec.Command(pythonBin, "--version")
-		output, _ := l.commandExecutor.ExecCommand(cmd, true)
+		output, _ := l.commandExecutor.ExecCommand(ctx, cmd, true)
 		logger.Debugf("%s --version: %s", pythonBin, bytes.ReplaceAll(output, []byte("\n"), []byte("")))
 		r := regexp.MustCompile(`Python (\d+\.\d+\.\d+).*`)
 		matches := r.FindStringSubmatch(string(output))
@@ -58,7 +57,10 @@ func (l *langManager) installPython(ctx context.Context, dir, cmdReq string) err
 		}
 	}
 
-	if err := installPythonDepsPip(ctx, l.commandExecutor, pipBin, dir); err != nil {
+	err = runStep(ctx, StepInstall, func(ctx context.Context) error {
+		return installPythonDepsPip(ctx, l.commandExecutor, pipBin, dir)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -136,13 +138,11 @@ func installPythonDepsPip(ctx context.Context, cmdExecutor executor, bin, dir st
 	}
 	logger.Info("requirements.txt found, running pip package manager")
 
-	if err := os.Setenv("PYTHONUSERBASE", dir); err != nil {
-		return err
-	}
+	ctx = withEnv(ctx, "PYTHONUSERBASE", dir)
 	args := []string{bin, "install", "--user", "--ignore-installed", "-r", "requirements.txt"}
 	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Dir = dir
-	if _, err := cmdExecutor.ExecCommand(cmd); err != nil {
+	if _, err := cmdExecutor.ExecCommand(ctx, cmd); err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			logger.Debugf("Unable execute package manager (PYTHONUSERBASE=%s %s): \n %s", dir, strings.Join(args, " "), exitErr.Stderr)
@@ -39,7 +39,7 @@ func (l *langManager) installRuby(ctx context.Context, dir, cmdReq string) error
 
 	if cmdReq != "" && cmdReq != "*" {
 		cmd := exec.Command(bin, "-v")
-		output, _ := l.commandExecutor.ExecCommand(cmd)
+		output, _ := l.commandExecutor.ExecCommand(ctx, cmd)
 		logger.Debugf("%s -v: %s", bin, output)
 		r := regexp.MustCompile("^ruby (.*?)(p.*?) (.*)")
 		matches := r.FindStringSubmatch(string(output))
@@ -54,7 +54,10 @@ func (l *langManager) installRuby(ctx context.Context, dir, cmdReq string) error
 		}
 	}
 
-	if err := installRubyDepsBundler(ctx, l.commandExecutor, dir); err != nil {
+	err = runStep(ctx, StepInstall, func(ctx context.Context) error {
+		return installRubyDepsBundler(ctx, l.commandExecutor, dir)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -72,7 +75,7 @@ func installRubyDepsBundler(ctx context.Context, cmdExecutor executor, dir strin
 	if err == nil {
 		cmd := exec.Command(bin, "install")
 		cmd.Dir = dir
-		_, err = cmdExecutor.ExecCommand(cmd)
+		_, err = cmdExecutor.ExecCommand(ctx, cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
 			if errors.As(err, &exitErr) {
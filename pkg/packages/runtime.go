@@ -0,0 +1,90 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/version"
+)
+
+// runtimeVersion describes how the runtime of a language is found and how its version is read
+type runtimeVersion struct {
+	name     string
+	bins     []string
+	arg      string
+	pattern  *regexp.Regexp
+	combined bool
+}
+
+// runtimeVersions use the same binaries and version output as the installers of each language
+var runtimeVersions = map[string]runtimeVersion{
+	PHP:        {name: "php", bins: []string{"php"}, arg: "-v", pattern: regexp.MustCompile("PHP (.*?) .*")},
+	Javascript: {name: "Node.js", bins: []string{"node", "nodejs"}, arg: "-v", pattern: regexp.MustCompile("^v(.*?)\\s*$")},
+	Ruby:       {name: "ruby", bins: []string{"ruby"}, arg: "-v", pattern: regexp.MustCompile("^ruby (.*?)(p.*?) (.*)")},
+	Go:         {name: "go", bins: []string{"go"}, arg: "version", pattern: regexp.MustCompile("go version go(.*?) .*")},
+	Python:     {name: "python", arg: "--version", pattern: regexp.MustCompile(`Python (\d+\.\d+\.\d+).*`), combined: true},
+}
+
+// CheckRuntime locates the runtime of the language with given requirements and returns its version,
+// an empty version if the runtime does not report it and no minimum version is required
+func (l *langManager) CheckRuntime(ctx context.Context, reqs LanguageRequirements) (string, error) {
+	lang, requirement := determineLangAndRequirements(reqs)
+	installer, err := l.installer(lang)
+	if err != nil {
+		return "", err
+	}
+	return installer.CheckRuntime(ctx, requirement)
+}
+
+// checkBuiltinRuntime checks the runtime of a built-in language
+func (l *langManager) checkBuiltinRuntime(ctx context.Context, lang, requirement string) (string, error) {
+	logger := log.FromContext(ctx)
+	runtime, ok := runtimeVersions[lang]
+	if !ok {
+		return "", ErrUnknownLang
+	}
+
+	var bin string
+	var err error
+	if lang == Python {
+		bin, err = findPythonBin(ctx, l.commandExecutor, requirement)
+	} else if bin, err = lookForBins(l.commandExecutor, runtime.bins...); err != nil {
+		err = fmt.Errorf("%w: %s. Please verify if the executable is included in your PATH", ErrRuntimeNotFound, runtime.name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	output, _ := l.commandExecutor.ExecCommand(ctx, exec.Command(bin, runtime.arg), runtime.combined)
+	logger.Debugf("%s %s: %s", bin, runtime.arg, bytes.ReplaceAll(output, []byte("\n"), []byte("")))
+	matches := runtime.pattern.FindStringSubmatch(string(output))
+	required := requirement != "" && requirement != "*"
+	if len(matches) == 0 {
+		if required {
+			return "", fmt.Errorf("%w: %s:%s", ErrRuntimeNoVersionFound, runtime.name, requirement)
+		}
+		return "", nil
+	}
+	if required && version.Compare(requirement, matches[1]) == -1 {
+		return matches[1], fmt.Errorf("%w: required: %s:%s, have: %s. Please upgrade your runtime", ErrRuntimeMinimumVersionRequired, runtime.name, requirement, matches[1])
+	}
+	return matches[1], nil
+}
@@ -0,0 +1,80 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os/exec"
+	"testing"
+)
+
+func TestCheckRuntime(t *testing.T) {
+	tests := map[string]struct {
+		givenReqs LanguageRequirements
+		init      func(*mocked)
+		expected  string
+		withError error
+	}{
+		"node satisfies requirement": {
+			givenReqs: LanguageRequirements{Node: "7.0.0"},
+			init: func(m *mocked) {
+				m.On("LookPath", "node").Return("/test/node", nil).Once()
+				m.On("ExecCommand", &exec.Cmd{Path: "/test/node", Args: []string{"/test/node", "-v"}}, false).Return([]byte("v14.15.0\n"), nil).Once()
+			},
+			expected: "14.15.0",
+		},
+		"python version too low": {
+			givenReqs: LanguageRequirements{Python: "3.8.0"},
+			init: func(m *mocked) {
+				m.On("LookPath", "python3").Return("/test/python3", nil).Once()
+				m.On("ExecCommand", &exec.Cmd{Path: "/test/python3", Args: []string{"/test/python3", "--version"}}, true).Return([]byte("Python 3.6.9"), nil).Once()
+			},
+			expected:  "3.6.9",
+			withError: ErrRuntimeMinimumVersionRequired,
+		},
+		"go without version output": {
+			givenReqs: LanguageRequirements{Go: "*"},
+			init: func(m *mocked) {
+				m.On("LookPath", "go").Return("/test/go", nil).Once()
+				m.On("ExecCommand", &exec.Cmd{Path: "/test/go", Args: []string{"/test/go", "version"}}, false).Return([]byte(""), nil).Once()
+			},
+		},
+		"ruby version not found": {
+			givenReqs: LanguageRequirements{Ruby: "2.0.0"},
+			init: func(m *mocked) {
+				m.On("LookPath", "ruby").Return("/test/ruby", nil).Once()
+				m.On("ExecCommand", &exec.Cmd{Path: "/test/ruby", Args: []string{"/test/ruby", "-v"}}, false).Return([]byte("unknown"), nil).Once()
+			},
+			withError: ErrRuntimeNoVersionFound,
+		},
+		"php not installed": {
+			givenReqs: LanguageRequirements{Php: "7.0.0"},
+			init: func(m *mocked) {
+				m.On("LookPath", "php").Return("", fmt.Errorf("not found")).Once()
+			},
+			withError: ErrRuntimeNotFound,
+		},
+		"undefined language": {
+			init:      func(m *mocked) {},
+			withError: ErrUnknownLang,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mocked)
+			test.init(m)
+			l := langManager{m}
+			version, err := l.CheckRuntime(context.Background(), test.givenReqs)
+			m.AssertExpectations(t)
+			assert.Equal(t, test.expected, version)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
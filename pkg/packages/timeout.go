@@ -0,0 +1,60 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// StepTimeouts contains time limits for package installation steps, zero value means no limit
+	StepTimeouts struct {
+		Install time.Duration
+		Build   time.Duration
+	}
+
+	contextType string
+)
+
+// installation steps
+const (
+	StepInstall = "dependency installation"
+	StepBuild   = "build"
+)
+
+var timeoutsContext contextType = "timeouts"
+
+// WithStepTimeouts sets the installation step timeouts in the context
+func WithStepTimeouts(ctx context.Context, timeouts StepTimeouts) context.Context {
+	return context.WithValue(ctx, timeoutsContext, timeouts)
+}
+
+func stepTimeout(ctx context.Context, step string) time.Duration {
+	timeouts, ok := ctx.Value(timeoutsContext).(StepTimeouts)
+	if !ok {
+		return 0
+	}
+	switch step {
+	case StepInstall:
+		return timeouts.Install
+	case StepBuild:
+		return timeouts.Build
+	}
+	return 0
+}
+
+// runStep executes fn, canceling its context once the timeout configured for the step is exceeded
+func runStep(ctx context.Context, step string, fn func(context.Context) error) error {
+	timeout := stepTimeout(ctx, step)
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := fn(stepCtx)
+	if err != nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s did not finish within %s", ErrStepTimeout, step, timeout)
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStep(t *testing.T) {
+	tests := map[string]struct {
+		timeouts  *StepTimeouts
+		step      string
+		fn        func(context.Context) error
+		withError error
+	}{
+		"no timeouts configured": {
+			step: StepInstall,
+			fn: func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.False(t, ok)
+				return nil
+			},
+		},
+		"step finishes in time": {
+			timeouts: &StepTimeouts{Install: time.Minute},
+			step:     StepInstall,
+			fn: func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+				return nil
+			},
+		},
+		"step exceeds timeout": {
+			timeouts: &StepTimeouts{Build: 10 * time.Millisecond},
+			step:     StepBuild,
+			fn: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			withError: ErrStepTimeout,
+		},
+		"step fails before timeout": {
+			timeouts: &StepTimeouts{Build: time.Minute},
+			step:     StepBuild,
+			fn: func(ctx context.Context) error {
+				return ErrPackageCompileFailure
+			},
+			withError: ErrPackageCompileFailure,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.timeouts != nil {
+				ctx = WithStepTimeouts(ctx, *test.timeouts)
+			}
+			err := runStep(ctx, test.step, test.fn)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
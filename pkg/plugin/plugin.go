@@ -0,0 +1,232 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin contains helpers for Akamai CLI packages written in Go.
+//
+// It provides the flags every Akamai package is expected to support (--edgerc, --section, --accountkey and --json),
+// resolves EdgeGrid credentials location, writes JSON output and maps errors to exit codes the way Akamai CLI does:
+//
+//	app := plugin.NewApp("akamai-example", "Example command", "1.0.0")
+//	app.Action = func(c *cli.Context) error {
+//		path, section, err := plugin.Edgerc(c)
+//		if err != nil {
+//			return plugin.Exit(err)
+//		}
+//		...
+//		return plugin.Output(c, result)
+//	}
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// exit codes returned by Akamai CLI packages, Akamai CLI reports installed commands failures with the same codes
+const (
+	ExitOK      = 0
+	ExitError   = 1
+	ExitUsage   = 2
+	ExitAuth    = 3
+	ExitNetwork = 4
+	ExitCrash   = 5
+)
+
+// default EdgeGrid credentials location
+const (
+	DefaultEdgerc  = "~/.edgerc"
+	DefaultSection = "default"
+)
+
+// OutputFormatEnv is the environment variable in which Akamai CLI passes the output format requested by the user
+const OutputFormatEnv = "AKAMAI_OUTPUT_FORMAT"
+
+// output formats packages are expected to honor
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// OutputFormats lists the supported output formats
+var OutputFormats = []string{FormatTable, FormatJSON, FormatYAML}
+
+var (
+	// ErrEdgercNotFound is returned when the .edgerc file does not exist
+	ErrEdgercNotFound = errors.New("edgerc file not found")
+	// ErrUsage should be wrapped by errors caused by invalid command usage, Exit maps them to ExitUsage
+	ErrUsage = errors.New("invalid usage")
+	// ErrAuth should be wrapped by errors caused by missing or rejected credentials, Exit maps them to ExitAuth
+	ErrAuth = errors.New("authentication failed")
+	// ErrNetwork should be wrapped by errors caused by unreachable or failing APIs, Exit maps them to ExitNetwork
+	ErrNetwork = errors.New("network error")
+)
+
+// Flags returns the flags supported by all Akamai CLI packages
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "edgerc",
+			Usage:   "Location of the credentials file",
+			Value:   DefaultEdgerc,
+			EnvVars: []string{"AKAMAI_EDGERC"},
+		},
+		&cli.StringFlag{
+			Name:    "section",
+			Usage:   "Section of the credentials file",
+			Value:   DefaultSection,
+			EnvVars: []string{"AKAMAI_EDGERC_SECTION"},
+		},
+		&cli.StringFlag{
+			Name:    "accountkey",
+			Usage:   "Account switch key",
+			EnvVars: []string{"AKAMAI_EDGERC_ACCOUNT_KEY"},
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Output as JSON",
+		},
+	}
+}
+
+// NewApp returns a cli.App with common Akamai CLI package flags and behavior
+func NewApp(name, usage, version string) *cli.App {
+	app := cli.NewApp()
+	app.Name = name
+	app.Usage = usage
+	app.Version = version
+	app.Flags = Flags()
+	app.ExitErrHandler = func(c *cli.Context, err error) {
+		if err == nil {
+			return
+		}
+		if _, ok := err.(cli.ExitCoder); !ok {
+			err = Exit(err)
+		}
+		cli.HandleExitCoder(err)
+	}
+	return app
+}
+
+// Edgerc returns the credentials file path and section resolved from command flags and environment
+func Edgerc(c *cli.Context) (string, string, error) {
+	path := c.String("edgerc")
+	if path == "" {
+		path = DefaultEdgerc
+	}
+	section := c.String("section")
+	if section == "" {
+		section = DefaultSection
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrEdgercNotFound, path)
+	}
+	return path, section, nil
+}
+
+// IsCLI returns true if the package was executed by Akamai CLI
+func IsCLI() bool {
+	return os.Getenv("AKAMAI_CLI") != ""
+}
+
+// CommandName returns the name of the command as invoked by Akamai CLI
+func CommandName() string {
+	return os.Getenv("AKAMAI_CLI_COMMAND")
+}
+
+// CommandVersion returns the version of the command as declared in cli.json
+func CommandVersion() string {
+	return os.Getenv("AKAMAI_CLI_COMMAND_VERSION")
+}
+
+// IsOutputFormat returns true if given name is one of OutputFormats
+func IsOutputFormat(name string) bool {
+	for _, format := range OutputFormats {
+		if name == format {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputFormat returns the output format requested by the user.
+// The --json flag takes precedence over the format passed by Akamai CLI in AKAMAI_OUTPUT_FORMAT, FormatTable is the default.
+func OutputFormat(c *cli.Context) string {
+	if c.Bool("json") {
+		return FormatJSON
+	}
+	if format := strings.ToLower(os.Getenv(OutputFormatEnv)); IsOutputFormat(format) {
+		return format
+	}
+	return FormatTable
+}
+
+// Output writes v as indented JSON if JSON output was requested, otherwise prints it using default formatting
+func Output(c *cli.Context, v interface{}) error {
+	if OutputFormat(c) == FormatJSON {
+		return WriteJSON(c.App.Writer, v)
+	}
+	_, err := fmt.Fprintln(c.App.Writer, v)
+	return err
+}
+
+// WriteJSON writes v to w as indented JSON
+func WriteJSON(w io.Writer, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// Exit converts an error into cli.ExitCoder with an exit code matching Akamai CLI conventions
+func Exit(err error) cli.ExitCoder {
+	if err == nil {
+		return nil
+	}
+	code := ExitError
+	switch {
+	case errors.Is(err, ErrUsage):
+		code = ExitUsage
+	case errors.Is(err, ErrAuth):
+		code = ExitAuth
+	case errors.Is(err, ErrNetwork):
+		code = ExitNetwork
+	}
+	return cli.Exit(color.RedString(err.Error()), code)
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestEdgerc(t *testing.T) {
+	tests := map[string]struct {
+		args            []string
+		expectedPath    string
+		expectedSection string
+		withError       error
+	}{
+		"edgerc and section from flags": {
+			args:            []string{"--edgerc", "./testdata/.edgerc", "--section", "other"},
+			expectedPath:    "./testdata/.edgerc",
+			expectedSection: "other",
+		},
+		"default section": {
+			args:            []string{"--edgerc", "./testdata/.edgerc"},
+			expectedPath:    "./testdata/.edgerc",
+			expectedSection: "default",
+		},
+		"edgerc does not exist": {
+			args:      []string{"--edgerc", "./testdata/missing"},
+			withError: ErrEdgercNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			app := NewApp("akamai-test", "", "1.0.0")
+			var path, section string
+			var err error
+			app.Action = func(c *cli.Context) error {
+				path, section, err = Edgerc(c)
+				return nil
+			}
+			require.NoError(t, app.Run(append([]string{"akamai-test"}, test.args...)))
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPath, path)
+			assert.Equal(t, test.expectedSection, section)
+		})
+	}
+}
+
+func TestOutput(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		format   string
+		expected string
+	}{
+		"json output": {
+			args:     []string{"--json"},
+			expected: "{\n  \"name\": \"test\"\n}\n",
+		},
+		"json output requested by Akamai CLI": {
+			format:   "json",
+			expected: "{\n  \"name\": \"test\"\n}\n",
+		},
+		"default output": {
+			expected: "{test}\n",
+		},
+		"unknown format requested": {
+			format:   "xml",
+			expected: "{test}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv(OutputFormatEnv, test.format))
+			defer func() {
+				require.NoError(t, os.Unsetenv(OutputFormatEnv))
+			}()
+			out := &bytes.Buffer{}
+			app := NewApp("akamai-test", "", "1.0.0")
+			app.Writer = out
+			app.Action = func(c *cli.Context) error {
+				return Output(c, struct {
+					Name string `json:"name"`
+				}{Name: "test"})
+			}
+			require.NoError(t, app.Run(append([]string{"akamai-test"}, test.args...)))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestExit(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedCode int
+	}{
+		"generic error": {
+			err:          errors.New("oops"),
+			expectedCode: ExitError,
+		},
+		"usage error": {
+			err:          fmt.Errorf("%w: missing argument", ErrUsage),
+			expectedCode: ExitUsage,
+		},
+		"auth error": {
+			err:          fmt.Errorf("%w: invalid client token", ErrAuth),
+			expectedCode: ExitAuth,
+		},
+		"network error": {
+			err:          fmt.Errorf("%w: connection refused", ErrNetwork),
+			expectedCode: ExitNetwork,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := Exit(test.err)
+			assert.Equal(t, test.expectedCode, res.ExitCode())
+			assert.Equal(t, color.RedString(test.err.Error()), res.Error())
+		})
+	}
+	assert.Nil(t, Exit(nil))
+}
+
+func TestCommandEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("AKAMAI_CLI", "1"))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_COMMAND", "test"))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_COMMAND_VERSION", "1.0.0"))
+	assert.True(t, IsCLI())
+	assert.Equal(t, "test", CommandName())
+	assert.Equal(t, "1.0.0", CommandVersion())
+}
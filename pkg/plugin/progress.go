@@ -0,0 +1,83 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ProgressFDEnv is the environment variable holding the file descriptor to which a package reports its progress.
+// Akamai CLI sets it when it can render the progress; it is not set on Windows.
+const ProgressFDEnv = "AKAMAI_CLI_PROGRESS_FD"
+
+// statuses of a finished phase
+const (
+	ProgressOK   = "ok"
+	ProgressWarn = "warn"
+	ProgressFail = "fail"
+)
+
+// ProgressEvent is a single line of the progress protocol, written as JSON
+type ProgressEvent struct {
+	// Phase names the current step of the command, e.g. "Uploading files"
+	Phase string `json:"phase"`
+	// Percent of the phase which is done, from 0 to 100, negative if unknown
+	Percent int `json:"percent"`
+	// Status is set when the phase finishes, to one of ProgressOK, ProgressWarn or ProgressFail
+	Status string `json:"status,omitempty"`
+}
+
+var progress struct {
+	sync.Mutex
+	fd   string
+	file *os.File
+}
+
+// Progress reports that the command is in given phase, with percent done from 0 to 100, or negative if unknown
+// It does nothing if the package was not executed by Akamai CLI or the progress is not rendered.
+func Progress(phase string, percent int) error {
+	return writeProgress(ProgressEvent{Phase: phase, Percent: percent})
+}
+
+// ProgressDone reports that given phase finished with one of ProgressOK, ProgressWarn or ProgressFail statuses
+func ProgressDone(phase, status string) error {
+	return writeProgress(ProgressEvent{Phase: phase, Percent: 100, Status: status})
+}
+
+func writeProgress(event ProgressEvent) error {
+	progress.Lock()
+	defer progress.Unlock()
+	fd := os.Getenv(ProgressFDEnv)
+	if fd == "" {
+		return nil
+	}
+	if progress.file == nil || progress.fd != fd {
+		n, err := strconv.Atoi(fd)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s: %s", ProgressFDEnv, fd)
+		}
+		progress.fd, progress.file = fd, os.NewFile(uintptr(n), "akamai-cli-progress")
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = progress.file.Write(append(line, '\n'))
+	return err
+}
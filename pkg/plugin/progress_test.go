@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	require.NoError(t, os.Unsetenv(ProgressFDEnv))
+	assert.NoError(t, Progress("Uploading", 10), "progress is ignored when not executed by Akamai CLI")
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+		require.NoError(t, w.Close())
+		require.NoError(t, os.Unsetenv(ProgressFDEnv))
+	}()
+	require.NoError(t, os.Setenv(ProgressFDEnv, fmt.Sprint(w.Fd())))
+
+	require.NoError(t, Progress("Uploading", 10))
+	require.NoError(t, ProgressDone("Uploading", ProgressWarn))
+	require.NoError(t, Progress("Activating", -1))
+
+	scanner := bufio.NewScanner(r)
+	for _, expected := range []string{
+		`{"phase":"Uploading","percent":10}`,
+		`{"phase":"Uploading","percent":100,"status":"warn"}`,
+		`{"phase":"Activating","percent":-1}`,
+	} {
+		require.True(t, scanner.Scan())
+		assert.Equal(t, expected, scanner.Text())
+	}
+
+	require.NoError(t, os.Setenv(ProgressFDEnv, "stdout"))
+	assert.Error(t, Progress("Uploading", 10))
+}
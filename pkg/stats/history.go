@@ -0,0 +1,146 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// DryRunKey is the config key which, when set to "true", logs statistics payloads instead of sending them
+	DryRunKey = "statistics-dryrun"
+
+	historyFile = "stats-history.json"
+
+	// historySize is the number of most recent payloads kept in the history file
+	historySize = 100
+)
+
+// invocation identifies payloads built by the current process
+var invocation = time.Now().UTC().Format(time.RFC3339Nano)
+
+// Payload is a statistics event in the exact form it is submitted to the analytics service
+type Payload struct {
+	Time       time.Time `json:"time"`
+	Invocation string    `json:"invocation"`
+	Command    string    `json:"command,omitempty"`
+	DryRun     bool      `json:"dryRun,omitempty"`
+	Body       string    `json:"body"`
+}
+
+// HistoryPath returns the location of the file holding the most recent statistics payloads
+func HistoryPath() (string, error) {
+	return historyPath()
+}
+
+func historyPath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, historyFile), nil
+}
+
+func readHistory() ([]Payload, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Payload{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []Payload
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// recordPayload appends given payload to the history file, keeping only the most recent ones
+func recordPayload(p Payload) error {
+	history, err := readHistory()
+	if err != nil {
+		history = []Payload{}
+	}
+	history = append(history, p)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// RecentPayloads returns the payloads built by the last n invocations of Akamai CLI, oldest first
+func RecentPayloads(n int) ([]Payload, error) {
+	history, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+	invocations := 0
+	i := len(history)
+	for ; i > 0; i-- {
+		if i == len(history) || history[i-1].Invocation != history[i].Invocation {
+			if invocations == n {
+				break
+			}
+			invocations++
+		}
+	}
+	return history[i:], nil
+}
+
+// CommandUsage returns how many of the recorded invocations of Akamai CLI ran each command.
+// Nothing is returned unless the user opted in to statistics, only then the history is kept.
+func CommandUsage(ctx context.Context) map[string]int {
+	usage := make(map[string]int)
+	if val, ok := config.Get(ctx).GetValue("cli", "enable-cli-statistics"); !ok || val == "false" {
+		return usage
+	}
+	history, err := readHistory()
+	if err != nil {
+		log.FromContext(ctx).Debugf("Unable to read statistics history: %s", err)
+		return usage
+	}
+	counted := make(map[string]bool)
+	for _, p := range history {
+		if p.Command == "" || counted[p.Invocation+" "+p.Command] {
+			continue
+		}
+		counted[p.Invocation+" "+p.Command] = true
+		usage[strings.ToLower(p.Command)]++
+	}
+	return usage
+}
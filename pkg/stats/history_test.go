@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// setupCliHome points AKAMAI_CLI_HOME to a temporary directory, the returned function restores it
+func setupCliHome(t *testing.T) func() {
+	cliHome, err := ioutil.TempDir("", "cli-home")
+	require.NoError(t, err)
+	prev, hadPrev := os.LookupEnv("AKAMAI_CLI_HOME")
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	return func() {
+		if hadPrev {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", prev))
+		} else {
+			require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		}
+		require.NoError(t, os.RemoveAll(cliHome))
+	}
+}
+
+func TestRecentPayloads(t *testing.T) {
+	tests := map[string]struct {
+		recorded []Payload
+		last     int
+		expected []string
+	}{
+		"no history": {
+			last:     5,
+			expected: []string{},
+		},
+		"payloads of last invocation": {
+			recorded: []Payload{
+				{Invocation: "1", Body: "a"},
+				{Invocation: "2", Body: "b"},
+				{Invocation: "2", Body: "c"},
+			},
+			last:     1,
+			expected: []string{"b", "c"},
+		},
+		"fewer invocations than requested": {
+			recorded: []Payload{
+				{Invocation: "1", Body: "a"},
+				{Invocation: "2", Body: "b"},
+			},
+			last:     5,
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer setupCliHome(t)()
+			for _, p := range test.recorded {
+				require.NoError(t, recordPayload(p))
+			}
+
+			payloads, err := RecentPayloads(test.last)
+			require.NoError(t, err)
+			bodies := make([]string, 0, len(payloads))
+			for _, p := range payloads {
+				bodies = append(bodies, p.Body)
+			}
+			assert.Equal(t, test.expected, bodies)
+		})
+	}
+}
+
+func TestRecordPayloadLimit(t *testing.T) {
+	defer setupCliHome(t)()
+	for i := 0; i < historySize+5; i++ {
+		require.NoError(t, recordPayload(Payload{Invocation: "1"}))
+	}
+	history, err := readHistory()
+	require.NoError(t, err)
+	assert.Len(t, history, historySize)
+}
+
+func TestCommandUsage(t *testing.T) {
+	recorded := []Payload{
+		{Invocation: "1", Command: "dns"},
+		{Invocation: "1", Command: "dns"},
+		{Invocation: "2", Command: "DNS"},
+		{Invocation: "3", Command: "install"},
+		{Invocation: "4"},
+	}
+	tests := map[string]struct {
+		stats    string
+		enabled  bool
+		expected map[string]int
+	}{
+		"statistics enabled": {
+			stats:    "1.1",
+			enabled:  true,
+			expected: map[string]int{"dns": 2, "install": 1},
+		},
+		"statistics disabled": {
+			stats:    "false",
+			enabled:  true,
+			expected: map[string]int{},
+		},
+		"statistics never enabled": {
+			expected: map[string]int{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer setupCliHome(t)()
+			for _, p := range recorded {
+				require.NoError(t, recordPayload(p))
+			}
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "enable-cli-statistics").Return(test.stats, test.enabled)
+
+			assert.Equal(t, test.expected, CommandUsage(config.Context(context.Background(), cfg)))
+			cfg.AssertExpectations(t)
+		})
+	}
+}
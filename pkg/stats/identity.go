@@ -0,0 +1,91 @@
+// Copyright 2018. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/akamai/cli/pkg/config"
+)
+
+// Identity contains the anonymous client identifier and the decision whether to send statistics
+type Identity struct {
+	ClientID     string `json:"clientId,omitempty"`
+	Enabled      *bool  `json:"enabled,omitempty"`
+	StatsVersion string `json:"statsVersion,omitempty"`
+}
+
+// GetIdentity returns the client identifier and consent stored in config. Enabled is nil if the user was not asked yet
+func GetIdentity(ctx context.Context) Identity {
+	cfg := config.Get(ctx)
+	var id Identity
+	id.ClientID, _ = cfg.GetValue("cli", "client-id")
+	if val, ok := cfg.GetValue("cli", "enable-cli-statistics"); ok {
+		enabled := val != "false"
+		id.Enabled = &enabled
+		if enabled {
+			id.StatsVersion, _ = cfg.GetValue("cli", "stats-version")
+		}
+	}
+	return id
+}
+
+// ResetIdentity replaces the client identifier with a new one. If withConsent is true, the decision whether to send statistics
+// is removed as well, so that the user is asked again on the next run
+func ResetIdentity(ctx context.Context, withConsent bool) error {
+	cfg := config.Get(ctx)
+	cfg.UnsetValue("cli", "client-id")
+	if withConsent {
+		cfg.UnsetValue("cli", "enable-cli-statistics")
+		cfg.UnsetValue("cli", "stats-version")
+		cfg.UnsetValue("cli", "last-ping")
+	} else if err := setupUUID(cfg); err != nil {
+		return err
+	}
+	return cfg.Save(ctx)
+}
+
+// ImportIdentity stores given client identifier and consent in config. Empty values are left unchanged and no events are sent
+func ImportIdentity(ctx context.Context, id Identity) error {
+	cfg := config.Get(ctx)
+	if id.ClientID != "" {
+		if _, err := uuid.Parse(id.ClientID); err != nil {
+			return fmt.Errorf("invalid client ID %q: %s", id.ClientID, err)
+		}
+		cfg.SetValue("cli", "client-id", id.ClientID)
+	}
+	if id.Enabled != nil {
+		if !*id.Enabled {
+			cfg.SetValue("cli", "enable-cli-statistics", "false")
+		} else {
+			// an older stats version makes Akamai CLI ask to re-affirm the decision, as it does after an upgrade
+			version := strings.TrimSpace(id.StatsVersion)
+			if version == "" {
+				version = statsVersion
+			}
+			cfg.SetValue("cli", "enable-cli-statistics", version)
+			cfg.SetValue("cli", "stats-version", version)
+			cfg.SetValue("cli", "last-ping", "never")
+			if err := setupUUID(cfg); err != nil {
+				return err
+			}
+		}
+	}
+	return cfg.Save(ctx)
+}
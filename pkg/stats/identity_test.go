@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetIdentity(t *testing.T) {
+	enabled, disabled := true, false
+	tests := map[string]struct {
+		init     func(*config.Mock)
+		expected Identity
+	}{
+		"stats enabled": {
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.On("GetValue", "cli", "enable-cli-statistics").Return("1.1", true).Once()
+				m.On("GetValue", "cli", "stats-version").Return("1.1", true).Once()
+			},
+			expected: Identity{ClientID: "123", Enabled: &enabled, StatsVersion: "1.1"},
+		},
+		"stats disabled": {
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "client-id").Return("", false).Once()
+				m.On("GetValue", "cli", "enable-cli-statistics").Return("false", true).Once()
+			},
+			expected: Identity{Enabled: &disabled},
+		},
+		"not configured": {
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "client-id").Return("", false).Once()
+				m.On("GetValue", "cli", "enable-cli-statistics").Return("", false).Once()
+			},
+			expected: Identity{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &config.Mock{}
+			test.init(m)
+			id := GetIdentity(config.Context(context.Background(), m))
+			m.AssertExpectations(t)
+			assert.Equal(t, test.expected, id)
+		})
+	}
+}
+
+func TestResetIdentity(t *testing.T) {
+	tests := map[string]struct {
+		withConsent bool
+		init        func(*config.Mock)
+	}{
+		"new client ID": {
+			init: func(m *config.Mock) {
+				m.On("UnsetValue", "cli", "client-id").Return().Once()
+				m.On("GetValue", "cli", "client-id").Return("", false).Once()
+				m.On("SetValue", "cli", "client-id", mock.AnythingOfType("string")).Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"remove consent": {
+			withConsent: true,
+			init: func(m *config.Mock) {
+				m.On("UnsetValue", "cli", "client-id").Return().Once()
+				m.On("UnsetValue", "cli", "enable-cli-statistics").Return().Once()
+				m.On("UnsetValue", "cli", "stats-version").Return().Once()
+				m.On("UnsetValue", "cli", "last-ping").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &config.Mock{}
+			test.init(m)
+			err := ResetIdentity(config.Context(context.Background(), m), test.withConsent)
+			m.AssertExpectations(t)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestImportIdentity(t *testing.T) {
+	enabled, disabled := true, false
+	tests := map[string]struct {
+		id        Identity
+		init      func(*config.Mock)
+		withError string
+	}{
+		"import client ID and consent": {
+			id: Identity{ClientID: "f2b7c1a4-2f0e-4a58-9b0e-7c8a1d2e3f40", Enabled: &enabled, StatsVersion: "1.0"},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "cli", "client-id", "f2b7c1a4-2f0e-4a58-9b0e-7c8a1d2e3f40").Return().Once()
+				m.On("SetValue", "cli", "enable-cli-statistics", "1.0").Return().Once()
+				m.On("SetValue", "cli", "stats-version", "1.0").Return().Once()
+				m.On("SetValue", "cli", "last-ping", "never").Return().Once()
+				m.On("GetValue", "cli", "client-id").Return("f2b7c1a4-2f0e-4a58-9b0e-7c8a1d2e3f40", true).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"import opt-out only": {
+			id: Identity{Enabled: &disabled},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "cli", "enable-cli-statistics", "false").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"invalid client ID": {
+			id:        Identity{ClientID: "abc"},
+			init:      func(m *config.Mock) {},
+			withError: `invalid client ID "abc"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &config.Mock{}
+			test.init(m)
+			err := ImportIdentity(config.Context(context.Background(), m), test.id)
+			m.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
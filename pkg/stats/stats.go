@@ -76,27 +76,33 @@ func FirstRunCheckStats(ctx context.Context, bannerShown bool) bool {
 		return bannerShown
 	}
 
-	if !answer {
+	if err := ConfigureStats(ctx, answer); err != nil {
+		return false
+	}
+
+	return bannerShown
+}
+
+// ConfigureStats enables or disables sending anonymous usage statistics without prompting the user
+func ConfigureStats(ctx context.Context, enabled bool) error {
+	cfg := config.Get(ctx)
+	if !enabled {
 		TrackEvent(ctx, "first-run", "stats-opt-out", "true")
 		cfg.SetValue("cli", "enable-cli-statistics", "false")
-		if err := cfg.Save(ctx); err != nil {
-			return false
-		}
-		return bannerShown
+		return cfg.Save(ctx)
 	}
 
 	cfg.SetValue("cli", "enable-cli-statistics", statsVersion)
 	cfg.SetValue("cli", "stats-version", statsVersion)
 	cfg.SetValue("cli", "last-ping", "never")
 	if err := setupUUID(cfg); err != nil {
-		return false
+		return err
 	}
 	if err := cfg.Save(ctx); err != nil {
-		return false
+		return err
 	}
 	TrackEvent(ctx, "first-run", "stats-enabled", statsVersion)
-
-	return bannerShown
+	return nil
 }
 
 func migrateStats(ctx context.Context, bannerShown bool) bool {
@@ -185,6 +191,21 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 	form.Add("ea", action)    // Action
 	form.Add("el", value)     // Label
 
+	payload := Payload{Time: time.Now(), Invocation: invocation, Body: form.Encode()}
+	if len(os.Args) > 1 {
+		payload.Command = os.Args[1]
+	}
+	if val, _ := cfg.GetValue("cli", DryRunKey); strings.TrimSpace(val) == "true" {
+		payload.DryRun = true
+	}
+	if err := recordPayload(payload); err != nil {
+		log.FromContext(ctx).Debugf("Unable to record statistics payload: %s", err)
+	}
+	if payload.DryRun {
+		log.FromContext(ctx).Infof("Statistics dry run, payload not sent: %s", payload.Body)
+		return
+	}
+
 	hc := http.Client{}
 	debug := os.Getenv("AKAMAI_CLI_DEBUG_ANALYTICS")
 	var req *http.Request
@@ -195,9 +216,9 @@ func TrackEvent(ctx context.Context, category, action, value string) {
 		analyticsURL = customURL
 	}
 	if debug != "" {
-		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/debug/collect", analyticsURL), strings.NewReader(form.Encode()))
+		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/debug/collect", analyticsURL), strings.NewReader(payload.Body))
 	} else {
-		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/collect", analyticsURL), strings.NewReader(form.Encode()))
+		req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/collect", analyticsURL), strings.NewReader(payload.Body))
 	}
 
 	if err != nil {
@@ -40,6 +40,7 @@ func TestTrackEvent(t *testing.T) {
 			init: func(m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 			},
 			expectedURL:  "/collect",
 			expectedBody: `aip=1&cid=123&ea=test-action&ec=test-category&el=test-value&t=event&tid=UA-34796267-23&v=1`,
@@ -52,11 +53,22 @@ func TestTrackEvent(t *testing.T) {
 			init: func(m *mocked) {
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 				m.term.On("Writeln", []interface{}{"stats uploaded"}).Return(0, nil).Once()
 			},
 			expectedURL:  "/debug/collect",
 			expectedBody: `aip=1&cid=123&ea=test-action&ec=test-category&el=test-value&t=event&tid=UA-34796267-23&v=1`,
 		},
+		"dry run, payload not sent": {
+			givenCategory: "test-category",
+			givenAction:   "test-action",
+			givenValue:    "test-value",
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
+				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("true", true).Once()
+			},
+		},
 		"stats disabled": {
 			givenCategory: "test-category",
 			givenAction:   "test-action",
@@ -81,6 +93,7 @@ func TestTrackEvent(t *testing.T) {
 				assert.NoError(t, err)
 			}))
 			require.NoError(t, os.Setenv("AKAMAI_CLI_ANALYTICS_URL", srv.URL))
+			defer setupCliHome(t)()
 			if test.withDebug {
 				require.NoError(t, os.Setenv("AKAMAI_CLI_DEBUG_ANALYTICS", "true"))
 				defer func() {
@@ -110,6 +123,7 @@ func TestCheckPing(t *testing.T) {
 				m.cfg.On("GetValue", "cli", "last-ping").Return("never", true).Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 				m.cfg.On("SetValue", "cli", "last-ping", mock.AnythingOfType("string")).Return().Once()
 				m.cfg.On("Save").Return(nil).Once()
 			},
@@ -120,6 +134,7 @@ func TestCheckPing(t *testing.T) {
 				m.cfg.On("GetValue", "cli", "last-ping").Return("2021-02-10T11:55:26+01:00", true).Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 				m.cfg.On("SetValue", "cli", "last-ping", mock.AnythingOfType("string")).Return().Once()
 				m.cfg.On("Save").Return(nil).Once()
 			},
@@ -142,6 +157,7 @@ func TestCheckPing(t *testing.T) {
 				m.cfg.On("GetValue", "cli", "last-ping").Return("never", true).Once()
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 				m.cfg.On("SetValue", "cli", "last-ping", mock.AnythingOfType("string")).Return().Once()
 				m.cfg.On("Save").Return(fmt.Errorf("oops")).Once()
 			},
@@ -162,6 +178,7 @@ func TestCheckPing(t *testing.T) {
 				assert.NoError(t, err)
 			}))
 			require.NoError(t, os.Setenv("AKAMAI_CLI_ANALYTICS_URL", srv.URL))
+			defer setupCliHome(t)()
 			m := &mocked{&config.Mock{}, &terminal.Mock{}}
 			ctx := terminal.Context(context.Background(), m.term)
 			ctx = config.Context(ctx, m.cfg)
@@ -214,6 +231,7 @@ func TestFirstRunCheckStats(t *testing.T) {
 				// track "first-run" event
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 			},
 			expectedBody: `aip=1&cid=123&ea=stats-enabled&ec=first-run&el=1.1&t=event&tid=UA-34796267-23&v=1`,
 		},
@@ -239,6 +257,7 @@ func TestFirstRunCheckStats(t *testing.T) {
 				// track "opt-out" event
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 
 				m.cfg.On("SetValue", "cli", "enable-cli-statistics", "false").Return().Once()
 				m.cfg.On("Save").Return(nil).Once()
@@ -273,6 +292,7 @@ func TestFirstRunCheckStats(t *testing.T) {
 				// track "stats-update-opt-in" event
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 			},
 			expectedBody: `aip=1&cid=123&ea=stats-update-opt-in&ec=first-run&el=1.1&t=event&tid=UA-34796267-23&v=1`,
 		},
@@ -304,6 +324,7 @@ func TestFirstRunCheckStats(t *testing.T) {
 				// track "stats-update-opt-in" event
 				m.cfg.On("GetValue", "cli", "enable-cli-statistics").Return("true", true).Once()
 				m.cfg.On("GetValue", "cli", "client-id").Return("123", true).Once()
+				m.cfg.On("GetValue", "cli", "statistics-dryrun").Return("", false).Once()
 			},
 			expectedBody: `aip=1&cid=123&ea=stats-update-opt-out&ec=first-run&el=1.1&t=event&tid=UA-34796267-23&v=1`,
 		},
@@ -322,6 +343,7 @@ func TestFirstRunCheckStats(t *testing.T) {
 				assert.NoError(t, err)
 			}))
 			require.NoError(t, os.Setenv("AKAMAI_CLI_ANALYTICS_URL", srv.URL))
+			defer setupCliHome(t)()
 			m := &mocked{&config.Mock{}, &terminal.Mock{}}
 			ctx := terminal.Context(context.Background(), m.term)
 			ctx = config.Context(ctx, m.cfg)
@@ -0,0 +1,35 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+// AccessibilityKey is the cli setting enabling the accessibility mode, meant for screen readers
+const AccessibilityKey = "accessibility"
+
+// accessibleWidth is the number of columns output is wrapped at in accessibility mode
+const accessibleWidth = 80
+
+// accessible is set in accessibility mode, like color.NoColor it applies to all output of the process
+var accessible bool
+
+// SetAccessible turns the accessibility mode on or off. In accessibility mode spinners are replaced with numbered
+// status lines which spell out the result of each step, and wrapped output is kept within 80 columns.
+func SetAccessible(enabled bool) {
+	accessible = enabled
+}
+
+// Accessible reports whether the accessibility mode is on
+func Accessible() bool {
+	return accessible
+}
@@ -0,0 +1,170 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type (
+	// BufferedTerminal records the output of one of several tasks running at the same time and writes it
+	// to the wrapped terminal in one piece on Flush, so that the output of the tasks does not interleave.
+	// Spinner steps are shown once they are finished, and the terminal cannot prompt for input.
+	BufferedTerminal struct {
+		term Terminal
+		mu   sync.Mutex
+		ops  []func(Terminal)
+	}
+
+	bufferedSpinner struct {
+		t *BufferedTerminal
+	}
+
+	bufferedErrorWriter struct {
+		t *BufferedTerminal
+	}
+)
+
+// ErrNoInput is returned by the prompts of a buffered terminal
+var ErrNoInput = errors.New("input is not available while tasks run in parallel")
+
+// flushMu makes buffered terminals sharing the wrapped terminal flush one after another
+var flushMu sync.Mutex
+
+// Buffered returns a terminal recording the output written to it until it is flushed to t
+func Buffered(t Terminal) *BufferedTerminal {
+	return &BufferedTerminal{term: t}
+}
+
+// Flush writes the output recorded so far to the wrapped terminal
+func (t *BufferedTerminal) Flush() {
+	t.mu.Lock()
+	ops := t.ops
+	t.ops = nil
+	t.mu.Unlock()
+
+	flushMu.Lock()
+	defer flushMu.Unlock()
+	for _, op := range ops {
+		op(t.term)
+	}
+}
+
+func (t *BufferedTerminal) record(op func(Terminal)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ops = append(t.ops, op)
+}
+
+func (t *BufferedTerminal) Write(v []byte) (int, error) {
+	b := append([]byte(nil), v...)
+	t.record(func(term Terminal) { _, _ = term.Write(b) })
+	return len(v), nil
+}
+
+// Printf records a formatted message for the output stream
+func (t *BufferedTerminal) Printf(f string, args ...interface{}) {
+	t.record(func(term Terminal) { term.Printf(f, args...) })
+}
+
+// Writeln records a line for the output stream
+func (t *BufferedTerminal) Writeln(args ...interface{}) (int, error) {
+	t.record(func(term Terminal) { _, _ = term.Writeln(args...) })
+	return len(fmt.Sprintln(args...)), nil
+}
+
+// WriteError records a message for the error stream
+func (t *BufferedTerminal) WriteError(v interface{}) {
+	t.record(func(term Terminal) { term.WriteError(v) })
+}
+
+// WriteErrorf records a formatted message for the error stream
+func (t *BufferedTerminal) WriteErrorf(f string, args ...interface{}) {
+	t.record(func(term Terminal) { term.WriteErrorf(f, args...) })
+}
+
+// Error returns a writer recording output for the error stream
+func (t *BufferedTerminal) Error() io.Writer {
+	return &bufferedErrorWriter{t: t}
+}
+
+// Ask returns ErrNoInput
+func (t *BufferedTerminal) Ask(Question) (string, error) {
+	return "", ErrNoInput
+}
+
+// Prompt returns ErrNoInput
+func (t *BufferedTerminal) Prompt(string, ...string) (string, error) {
+	return "", ErrNoInput
+}
+
+// MultiSelect returns ErrNoInput
+func (t *BufferedTerminal) MultiSelect(string, ...string) ([]string, error) {
+	return nil, ErrNoInput
+}
+
+// Confirm returns ErrNoInput
+func (t *BufferedTerminal) Confirm(string, bool) (bool, error) {
+	return false, ErrNoInput
+}
+
+// IsTTY returns false, so that commands do not try to prompt for input
+func (t *BufferedTerminal) IsTTY() bool {
+	return false
+}
+
+// Spinner returns a spinner recording its steps
+func (t *BufferedTerminal) Spinner() Spinner {
+	return &bufferedSpinner{t: t}
+}
+
+func (s *bufferedSpinner) Write(v []byte) (int, error) {
+	b := append([]byte(nil), v...)
+	s.t.record(func(term Terminal) { _, _ = term.Spinner().Write(b) })
+	return len(v), nil
+}
+
+func (s *bufferedSpinner) Start(f string, args ...interface{}) {
+	s.t.record(func(term Terminal) { term.Spinner().Start(f, args...) })
+}
+
+func (s *bufferedSpinner) Stop(status SpinnerStatus) {
+	s.t.record(func(term Terminal) { term.Spinner().Stop(status) })
+}
+
+func (s *bufferedSpinner) OK() {
+	s.t.record(func(term Terminal) { term.Spinner().OK() })
+}
+
+func (s *bufferedSpinner) WarnOK() {
+	s.t.record(func(term Terminal) { term.Spinner().WarnOK() })
+}
+
+func (s *bufferedSpinner) Warn() {
+	s.t.record(func(term Terminal) { term.Spinner().Warn() })
+}
+
+func (s *bufferedSpinner) Fail() {
+	s.t.record(func(term Terminal) { term.Spinner().Fail() })
+}
+
+func (w *bufferedErrorWriter) Write(v []byte) (int, error) {
+	b := append([]byte(nil), v...)
+	w.t.record(func(term Terminal) { _, _ = term.Error().Write(b) })
+	return len(v), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestBufferedTerminal(t *testing.T) {
+	out, err := ioutil.TempFile("", "buffered-terminal")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, out.Close())
+		require.NoError(t, os.Remove(out.Name()))
+	}()
+	errOut := &bytes.Buffer{}
+	term := New(out, nil, errOut)
+
+	first, second := Buffered(term), Buffered(term)
+	first.Printf("Updating %s...\n", "cli-dns")
+	second.Printf("Updating %s...\n", "cli-purge")
+	_, err = first.Writeln("cli-dns updated")
+	require.NoError(t, err)
+	second.WriteErrorf("cli-purge failed: %s\n", "oops")
+	_, err = second.Error().Write([]byte("details\n"))
+	require.NoError(t, err)
+
+	second.Flush()
+	first.Flush()
+	first.Flush()
+
+	content, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "Updating cli-purge...\nUpdating cli-dns...\ncli-dns updated\n", string(content))
+	assert.Equal(t, "cli-purge failed: oops\ndetails\n", errOut.String())
+
+	assert.False(t, first.IsTTY())
+	_, err = first.Confirm("Reset?", true)
+	assert.Equal(t, ErrNoInput, err)
+}
@@ -0,0 +1,39 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-colorable"
+)
+
+// ColorEnabled reports whether colored output should be written to the stream with given file descriptor.
+// Colors are used only for terminals, and are disabled for all streams when NO_COLOR is set or TERM is dumb.
+func ColorEnabled(fd uintptr) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(fd)
+}
+
+// colorStream returns a writer for given stream, color escape sequences are removed from the output if the stream should not be colored
+func colorStream(f *os.File) io.Writer {
+	if ColorEnabled(f.Fd()) {
+		return colorable.NewColorable(f)
+	}
+	return colorable.NewNonColorable(f)
+}
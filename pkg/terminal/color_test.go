@@ -0,0 +1,55 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+	"github.com/tj/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestColorStream(t *testing.T) {
+	out, err := ioutil.TempFile("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, out.Close())
+		require.NoError(t, os.Remove(out.Name())) // clean up
+	}()
+
+	assert.False(t, ColorEnabled(out.Fd()))
+
+	red := color.New(color.FgRed)
+	red.EnableColor()
+	_, err = colorStream(out).Write([]byte(red.Sprint("error") + " message\n"))
+	require.NoError(t, err)
+
+	_, err = out.Seek(0, 0)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, "error message\n", string(data))
+}
+
+func TestColorEnabledNoColor(t *testing.T) {
+	require.NoError(t, os.Setenv("NO_COLOR", ""))
+	defer func() {
+		require.NoError(t, os.Unsetenv("NO_COLOR"))
+	}()
+	assert.False(t, ColorEnabled(os.Stdout.Fd()))
+	assert.False(t, ColorEnabled(os.Stderr.Fd()))
+}
@@ -0,0 +1,203 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownRenderer renders Markdown documents as terminal text, using ANSI escape sequences for emphasis, headings, code and links.
+// Raw HTML is dropped, as it cannot be displayed in a terminal.
+type markdownRenderer struct {
+	styles    [][]color.Attribute
+	listDepth int
+}
+
+// RenderMarkdown converts a Markdown document, such as a package README, to text which can be displayed in a terminal
+func RenderMarkdown(src []byte) string {
+	out := blackfriday.Run(src, blackfriday.WithRenderer(&markdownRenderer{}), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return strings.TrimRight(string(out), "\n") + "\n"
+}
+
+// RenderNode implements blackfriday.Renderer
+func (r *markdownRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	switch node.Type {
+	case blackfriday.Heading:
+		if entering {
+			if node.Level <= 2 {
+				r.push(w, color.Bold, color.FgCyan)
+			} else {
+				r.push(w, color.Bold)
+			}
+			return blackfriday.GoToNext
+		}
+		r.pop(w)
+		fmt.Fprint(w, "\n\n")
+	case blackfriday.Paragraph:
+		if entering {
+			if inBlockQuote(node) {
+				fmt.Fprint(w, "> ")
+			}
+			return blackfriday.GoToNext
+		}
+		fmt.Fprint(w, "\n")
+		if node.Parent == nil || node.Parent.Type != blackfriday.Item {
+			fmt.Fprint(w, "\n")
+		}
+	case blackfriday.List:
+		if entering {
+			r.listDepth++
+			return blackfriday.GoToNext
+		}
+		r.listDepth--
+		if r.listDepth == 0 {
+			fmt.Fprint(w, "\n")
+		}
+	case blackfriday.Item:
+		if entering {
+			fmt.Fprint(w, strings.Repeat("  ", r.listDepth-1)+itemMarker(node))
+		}
+	case blackfriday.CodeBlock:
+		r.push(w, color.FgYellow)
+		for _, line := range strings.Split(strings.TrimRight(string(node.Literal), "\n"), "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+		r.pop(w)
+		fmt.Fprint(w, "\n")
+	case blackfriday.Code:
+		r.push(w, color.FgYellow)
+		fmt.Fprint(w, string(node.Literal))
+		r.pop(w)
+	case blackfriday.Text:
+		text := string(node.Literal)
+		if inBlockQuote(node) {
+			text = strings.ReplaceAll(text, "\n", "\n> ")
+		}
+		fmt.Fprint(w, text)
+	case blackfriday.Softbreak, blackfriday.Hardbreak:
+		fmt.Fprint(w, "\n")
+	case blackfriday.Emph:
+		r.style(w, entering, color.Italic)
+	case blackfriday.Strong:
+		r.style(w, entering, color.Bold)
+	case blackfriday.Del:
+		r.style(w, entering, color.CrossedOut)
+	case blackfriday.Link:
+		r.style(w, entering, color.Underline, color.FgBlue)
+		if !entering && len(node.Destination) > 0 && node.Destination[0] != '#' {
+			fmt.Fprintf(w, " (%s)", node.Destination)
+		}
+	case blackfriday.Image:
+		if entering {
+			fmt.Fprint(w, "[image: ")
+			return blackfriday.GoToNext
+		}
+		fmt.Fprint(w, "]")
+	case blackfriday.HorizontalRule:
+		fmt.Fprint(w, strings.Repeat("-", 40)+"\n\n")
+	case blackfriday.TableCell:
+		if entering && node.IsHeader {
+			r.push(w, color.Bold)
+		} else if !entering {
+			if node.IsHeader {
+				r.pop(w)
+			}
+			if node.Next != nil {
+				fmt.Fprint(w, " | ")
+			}
+		}
+	case blackfriday.TableRow:
+		if !entering {
+			fmt.Fprint(w, "\n")
+		}
+	case blackfriday.Table:
+		if !entering {
+			fmt.Fprint(w, "\n")
+		}
+	case blackfriday.HTMLBlock, blackfriday.HTMLSpan:
+		return blackfriday.SkipChildren
+	}
+	return blackfriday.GoToNext
+}
+
+// RenderHeader implements blackfriday.Renderer
+func (r *markdownRenderer) RenderHeader(io.Writer, *blackfriday.Node) {}
+
+// RenderFooter implements blackfriday.Renderer
+func (r *markdownRenderer) RenderFooter(io.Writer, *blackfriday.Node) {}
+
+func (r *markdownRenderer) style(w io.Writer, entering bool, attrs ...color.Attribute) {
+	if entering {
+		r.push(w, attrs...)
+		return
+	}
+	r.pop(w)
+}
+
+// push starts a style, which is kept until the matching pop even if nested styles are used inside of it
+func (r *markdownRenderer) push(w io.Writer, attrs ...color.Attribute) {
+	r.styles = append(r.styles, attrs)
+	writeStyle(w, attrs)
+}
+
+// pop ends the last style and restores the ones around it
+func (r *markdownRenderer) pop(w io.Writer) {
+	if len(r.styles) == 0 {
+		return
+	}
+	r.styles = r.styles[:len(r.styles)-1]
+	writeStyle(w, []color.Attribute{color.Reset})
+	for _, attrs := range r.styles {
+		writeStyle(w, attrs)
+	}
+}
+
+func writeStyle(w io.Writer, attrs []color.Attribute) {
+	if color.NoColor {
+		return
+	}
+	codes := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		codes = append(codes, strconv.Itoa(int(attr)))
+	}
+	fmt.Fprintf(w, "\x1b[%sm", strings.Join(codes, ";"))
+}
+
+// itemMarker returns the bullet of a list item, or its number in ordered lists
+func itemMarker(node *blackfriday.Node) string {
+	if node.Parent == nil || node.Parent.ListFlags&blackfriday.ListTypeOrdered == 0 {
+		return "- "
+	}
+	n := 1
+	for prev := node.Prev; prev != nil; prev = prev.Prev {
+		n++
+	}
+	return strconv.Itoa(n) + ". "
+}
+
+func inBlockQuote(node *blackfriday.Node) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if p.Type == blackfriday.BlockQuote {
+			return true
+		}
+	}
+	return false
+}
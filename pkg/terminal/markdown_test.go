@@ -0,0 +1,58 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"github.com/fatih/color"
+	"github.com/tj/assert"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	src := "# Akamai CLI for DNS\n\n" +
+		"[![Build](https://example.com/badge.svg)](https://example.com/build)\n\n" +
+		"Manage **zones** and `records`,\nsee [docs](https://techdocs.akamai.com) or [usage](#usage).\n\n" +
+		"## Usage\n\n" +
+		"```sh\nakamai dns list-zones\nakamai dns retrieve-zone example.com\n```\n\n" +
+		"- install\n- configure\n  1. credentials\n  2. section\n\n" +
+		"> Note: requires\n> API access\n\n" +
+		"| Command | Description |\n|---|---|\n| list-zones | Lists zones |\n\n" +
+		"<p align=\"center\"><img src=\"logo.png\"></p>\n\n" +
+		"---\n"
+
+	t.Run("plain text", func(t *testing.T) {
+		noColor := color.NoColor
+		color.NoColor = true
+		defer func() { color.NoColor = noColor }()
+
+		assert.Equal(t, "Akamai CLI for DNS\n\n"+
+			"[image: Build] (https://example.com/build)\n\n"+
+			"Manage zones and records,\nsee docs (https://techdocs.akamai.com) or usage.\n\n"+
+			"Usage\n\n"+
+			"    akamai dns list-zones\n    akamai dns retrieve-zone example.com\n\n"+
+			"- install\n- configure\n  1. credentials\n  2. section\n\n"+
+			"> Note: requires\n> API access\n\n"+
+			"Command | Description\nlist-zones | Lists zones\n\n"+
+			"----------------------------------------\n", RenderMarkdown([]byte(src)))
+	})
+
+	t.Run("nested styles", func(t *testing.T) {
+		noColor := color.NoColor
+		color.NoColor = false
+		defer func() { color.NoColor = noColor }()
+
+		assert.Equal(t, "\x1b[1;36mUse \x1b[3mnew\x1b[0m\x1b[1;36m flags\x1b[0m\n", RenderMarkdown([]byte("## Use *new* flags\n")))
+	})
+}
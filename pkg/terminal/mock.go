@@ -37,12 +37,27 @@ func (m *Mock) WriteErrorf(f string, args ...interface{}) {
 	_ = m.Called(f, args)
 }
 
+// Ask mock implementation
+func (m *Mock) Ask(q Question) (string, error) {
+	args := m.Called(q)
+	return args.String(0), args.Error(1)
+}
+
 // Prompt mock implementation
 func (m *Mock) Prompt(p string, options ...string) (string, error) {
 	args := m.Called(p, options)
 	return args.String(0), args.Error(1)
 }
 
+// MultiSelect mock implementation
+func (m *Mock) MultiSelect(p string, options ...string) ([]string, error) {
+	args := m.Called(p, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 // Confirm mock implementation
 func (m *Mock) Confirm(p string, d bool) (bool, error) {
 	args := m.Called(p, d)
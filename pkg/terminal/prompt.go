@@ -0,0 +1,180 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/mattn/go-isatty"
+)
+
+type (
+	// Question describes a single value asked from the user
+	Question struct {
+		Message string
+		// Default is the answer used when the user enters nothing, and the only answer when the terminal is not interactive
+		Default string
+		// Options turn the question into a selection list
+		Options []string
+		// Masked hides the typed answer, e.g. for secrets
+		Masked bool
+		// Validate checks the answer, the question is asked again while it returns an error
+		Validate func(answer string) error
+		// Help is displayed when the user types "?"
+		Help string
+	}
+)
+
+// ErrNonInteractive is returned when a required answer is not given on a non-interactive terminal and there is no default
+var ErrNonInteractive = errors.New("no answer given and no default value")
+
+// Ask asks the question and returns a valid answer
+// If input does not come from a terminal, e.g. in scripts, the answer is read from a single input line without prompting.
+// An empty line or the end of input selects the default value, and an invalid answer is an error instead of asking again.
+func (t *DefaultTerminal) Ask(q Question) (string, error) {
+	if !t.interactive() {
+		answer, err := readLine(t.in)
+		if err != nil {
+			return "", err
+		}
+		return q.fallback(answer)
+	}
+
+	var prompt survey.Prompt
+	switch {
+	case len(q.Options) > 0 && accessible:
+		// a selection list is redrawn on each key press, so in accessibility mode the options are listed once and typed
+		message := fmt.Sprintf("%s (%s):", strings.TrimSuffix(strings.TrimSpace(q.Message), ":"), strings.Join(q.Options, ", "))
+		prompt = &survey.Input{Message: message, Default: q.Default, Help: q.Help}
+	case len(q.Options) > 0:
+		sel := &survey.Select{Message: q.Message, Options: q.Options, Help: q.Help}
+		for _, option := range q.Options {
+			if option == q.Default {
+				sel.Default = q.Default
+			}
+		}
+		prompt = sel
+	case q.Masked:
+		message := q.Message
+		if q.Default != "" {
+			message += " (leave empty to keep the current value)"
+		}
+		prompt = &survey.Password{Message: message, Help: q.Help}
+	default:
+		prompt = &survey.Input{Message: q.Message, Default: q.Default, Help: q.Help}
+	}
+
+	var answer string
+	err := survey.AskOne(prompt, &answer, survey.WithStdio(t.in, t.out, t.err), survey.WithValidator(q.validator()))
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		answer = q.Default
+	}
+	return answer, nil
+}
+
+// fallback validates an answer given without prompting, using the default value if it is empty
+func (q Question) fallback(answer string) (string, error) {
+	if answer == "" {
+		answer = q.Default
+	}
+	if answer == "" && q.Validate != nil {
+		if err := q.Validate(answer); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrNonInteractive, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(q.Message), ":")))
+		}
+	}
+	if len(q.Options) > 0 && !containsString(q.Options, answer) {
+		return "", fmt.Errorf("invalid answer %q, expected one of: %s", answer, strings.Join(q.Options, ", "))
+	}
+	if q.Validate != nil {
+		if err := q.Validate(answer); err != nil {
+			return "", err
+		}
+	}
+	return answer, nil
+}
+
+// readLine reads a single line without buffering, so that following questions read the following lines
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(line)), nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validator adapts the validation function to survey, empty answers are replaced by the default value
+func (q Question) validator() survey.Validator {
+	return func(ans interface{}) error {
+		var answer string
+		switch v := ans.(type) {
+		case string:
+			answer = v
+		case core.OptionAnswer:
+			answer = v.Value
+		}
+		if answer == "" {
+			answer = q.Default
+		}
+		if len(q.Options) > 0 && !containsString(q.Options, answer) {
+			return fmt.Errorf("expected one of: %s", strings.Join(q.Options, ", "))
+		}
+		if q.Validate == nil {
+			return nil
+		}
+		return q.Validate(answer)
+	}
+}
+
+func (t *DefaultTerminal) interactive() bool {
+	return isatty.IsTerminal(t.in.Fd()) || isatty.IsCygwinTerminal(t.in.Fd())
+}
+
+// Required is a validation function rejecting empty answers
+func Required(answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return errors.New("a value is required")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tj/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAsk(t *testing.T) {
+	tests := map[string]struct {
+		input     string
+		question  Question
+		expected  string
+		withError error
+	}{
+		"answer given": {
+			input:    "Tom\n",
+			question: Question{Message: "What is your name", Validate: Required},
+			expected: "Tom",
+		},
+		"empty answer uses default": {
+			input:    "\n",
+			question: Question{Message: "Which color", Default: "red", Options: []string{"red", "blue"}},
+			expected: "red",
+		},
+		"end of input uses default": {
+			question: Question{Message: "Password", Default: "secret", Masked: true},
+			expected: "secret",
+		},
+		"required answer missing": {
+			question:  Question{Message: "What is your name:", Validate: Required},
+			withError: ErrNonInteractive,
+		},
+		"answer not in options": {
+			input:    "green\n",
+			question: Question{Message: "Which color", Options: []string{"red", "blue"}},
+		},
+		"invalid answer": {
+			input:     "13\n",
+			question:  Question{Message: "Lucky number", Validate: func(string) error { return errUnlucky }},
+			withError: errUnlucky,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, err := ioutil.TempFile("", "ask")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, os.Remove(in.Name())) // clean up
+			}()
+			_, err = in.WriteString(test.input)
+			require.NoError(t, err)
+			_, err = in.Seek(0, 0)
+			require.NoError(t, err)
+
+			term := New(DiscardWriter(), in, DiscardWriter())
+			answer, err := term.Ask(test.question)
+			if test.expected == "" {
+				require.Error(t, err)
+				if test.withError != nil {
+					assert.True(t, errors.Is(err, test.withError), "unexpected error: %s", err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, answer)
+		})
+	}
+}
+
+func TestAskReadsOneLinePerQuestion(t *testing.T) {
+	in, err := ioutil.TempFile("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.Remove(in.Name())) // clean up
+	}()
+	_, err = in.WriteString("first\r\nsecond\n")
+	require.NoError(t, err)
+	_, err = in.Seek(0, 0)
+	require.NoError(t, err)
+
+	term := New(DiscardWriter(), in, DiscardWriter())
+	for _, expected := range []string{"first", "second"} {
+		answer, err := term.Ask(Question{Message: "Next"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, answer)
+	}
+}
+
+var errUnlucky = errors.New("unlucky number")
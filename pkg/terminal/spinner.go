@@ -5,6 +5,7 @@ import (
 	spnr "github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"io"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -23,8 +24,11 @@ type (
 
 	// DefaultSpinner defines a simple status spinner
 	DefaultSpinner struct {
-		spinner *spnr.Spinner
-		prefix  string
+		spinner   *spnr.Spinner
+		prefix    string
+		verbosity Verbosity
+		// step is the number of the last step started in accessibility mode
+		step int
 	}
 )
 
@@ -36,39 +40,98 @@ var (
 	SpinnerStatusFail   = SpinnerStatus(fmt.Sprintf("... [%s]\n", color.RedString("FAIL")))
 )
 
+// accessibleResults spell out the results of spinner statuses in accessibility mode
+var accessibleResults = map[string]string{
+	"OK":   "done",
+	"WARN": "warning",
+	"FAIL": "failed",
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
 // StandardSpinner returns a default spinner for Akamai CLI
 func StandardSpinner() *DefaultSpinner {
 	return &DefaultSpinner{spinner: spnr.New(spnr.CharSets[33], 500*time.Millisecond)}
 }
 
 // Start starts the spinner using the provided string as the prefix
+// Nothing is shown in quiet mode, and in verbose mode the prefix is written as a status line instead of animating it,
+// as the spinner would mix with the output streamed by the step. In accessibility mode the status line is numbered.
 func (s *DefaultSpinner) Start(f string, args ...interface{}) {
 	s.prefix = fmt.Sprintf(f, args...)
+	switch {
+	case s.verbosity <= VerbosityQuiet:
+		return
+	case accessible:
+		s.step++
+		fmt.Fprintf(s.spinner.Writer, "Step %d: %s\n", s.step, s.prefix)
+		return
+	case s.verbosity >= VerbosityVerbose:
+		fmt.Fprintln(s.spinner.Writer, s.prefix)
+		return
+	}
 	s.spinner.Prefix = s.prefix + " "
 	s.spinner.Start()
 }
 
 // Stop stops the spinner and updates the final status message
 func (s *DefaultSpinner) Stop(status SpinnerStatus) {
+	s.stop(status, "")
+}
+
+// stop stops the spinner with given status, in accessibility mode the status line of the step ends with result,
+// or the text of the status if result is empty
+func (s *DefaultSpinner) stop(status SpinnerStatus, result string) {
+	switch {
+	case s.verbosity <= VerbosityQuiet:
+		return
+	case accessible:
+		if result == "" {
+			result = strings.Trim(ansiEscape.ReplaceAllString(string(status), ""), ". []\n")
+			if word, ok := accessibleResults[result]; ok {
+				result = word
+			}
+		}
+		fmt.Fprintf(s.spinner.Writer, "Step %d: %s\n", s.step, result)
+		return
+	case s.verbosity >= VerbosityVerbose:
+		fmt.Fprint(s.spinner.Writer, s.prefix+" "+string(status))
+		return
+	}
 	s.spinner.Suffix = ""
 	s.spinner.FinalMSG = s.prefix + " " + string(status)
 	s.spinner.Stop()
 }
 
 // Write implements the io.Writer interface and updates the suffix of the spinner
+// In verbose mode the progress is written as is, and it is discarded in quiet and accessibility modes,
+// where repeated updates, such as percentages, would be read out one by one.
 func (s *DefaultSpinner) Write(v []byte) (n int, err error) {
+	switch {
+	case s.verbosity <= VerbosityQuiet:
+		return len(v), nil
+	case s.verbosity >= VerbosityVerbose:
+		return s.spinner.Writer.Write(v)
+	case accessible:
+		return len(v), nil
+	}
 	s.spinner.Suffix = " " + strings.TrimSpace(string(v))
 	return len(v), nil
 }
 
+// SetVerbosity changes how the spinner reports progress
+func (s *DefaultSpinner) SetVerbosity(v Verbosity) {
+	s.verbosity = v
+}
+
 // OK stops the spinner with ok status
 func (s *DefaultSpinner) OK() {
 	s.Stop(SpinnerStatusOK)
 }
 
-// WarnOK stops the spinner with WarnOK status
+// WarnOK stops the spinner with WarnOK status, which tells it from OK by color only outside of accessibility mode
 func (s *DefaultSpinner) WarnOK() {
-	s.Stop(SpinnerStatusWarnOK)
+	s.stop(SpinnerStatusWarnOK, "done, with warnings")
 }
 
 // Warn stops the spinner with Warn status
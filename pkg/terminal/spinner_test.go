@@ -113,3 +113,57 @@ func TestSpinnerWrite(t *testing.T) {
 	assert.Equal(t, 4, l)
 	assert.Equal(t, " test", s.spinner.Suffix)
 }
+
+func TestSpinnerVerbosity(t *testing.T) {
+	tests := map[string]struct {
+		verbosity Verbosity
+		expected  string
+	}{
+		"quiet": {
+			verbosity: VerbosityQuiet,
+			expected:  "",
+		},
+		"verbose": {
+			verbosity: VerbosityVerbose,
+			expected:  "spinner test\ncloning...\nspinner test ... [OK]\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wr := bytes.Buffer{}
+			s := DefaultSpinner{
+				spinner: spnr.New(spnr.CharSets[26], 1*time.Minute, spnr.WithWriter(&wr)),
+			}
+			s.SetVerbosity(test.verbosity)
+			s.Start("spinner %s", "test")
+			l, err := s.Write([]byte("cloning...\n"))
+			assert.NoError(t, err)
+			assert.Equal(t, 11, l)
+			s.OK()
+			assert.Equal(t, test.expected, wr.String())
+			assert.False(t, s.spinner.Active())
+		})
+	}
+}
+
+func TestSpinnerAccessible(t *testing.T) {
+	SetAccessible(true)
+	defer SetAccessible(false)
+	wr := bytes.Buffer{}
+	s := DefaultSpinner{
+		spinner: spnr.New(spnr.CharSets[26], 1*time.Minute, spnr.WithWriter(&wr)),
+	}
+	s.Start("Fetching %s...", "cli-dns")
+	l, err := s.Write([]byte("50%"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, l)
+	s.OK()
+	s.Start("Installing dependencies...")
+	s.WarnOK()
+	s.Start("Downloading binary...")
+	s.Fail()
+	assert.Equal(t, "Step 1: Fetching cli-dns...\nStep 1: done\nStep 2: Installing dependencies...\nStep 2: done, with warnings\n"+
+		"Step 3: Downloading binary...\nStep 3: failed\n", wr.String())
+	assert.False(t, s.spinner.Active())
+}
@@ -0,0 +1,47 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"fmt"
+)
+
+// statusTerminal is a terminal writing everything to the error stream of the wrapped terminal
+type statusTerminal struct {
+	Terminal
+}
+
+// StatusTerminal returns a terminal writing all output of t to its error stream, so that status messages
+// of a command do not mix with a machine-readable result written to standard output with t itself
+func StatusTerminal(t Terminal) Terminal {
+	return &statusTerminal{Terminal: t}
+}
+
+func (t *statusTerminal) Write(v []byte) (int, error) {
+	t.Terminal.WriteErrorf("%s", string(v))
+	return len(v), nil
+}
+
+// Printf writes a formatted message to the error stream
+func (t *statusTerminal) Printf(f string, args ...interface{}) {
+	t.Terminal.WriteErrorf(f, args...)
+}
+
+// Writeln writes a line to the error stream
+func (t *statusTerminal) Writeln(args ...interface{}) (int, error) {
+	line := fmt.Sprintln(args...)
+	t.Terminal.WriteErrorf("%s", line)
+	return len(line), nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestStatusTerminal(t *testing.T) {
+	out, err := ioutil.TempFile("", "status-terminal")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, out.Close())
+		require.NoError(t, os.Remove(out.Name()))
+	}()
+	errOut := &bytes.Buffer{}
+	term := New(out, nil, errOut)
+
+	status := StatusTerminal(term)
+	status.Printf("Installing %s...\n", "cli-dns")
+	_, err = status.Writeln("100% done")
+	require.NoError(t, err)
+	_, err = status.Write([]byte("raw\n"))
+	require.NoError(t, err)
+	_, err = term.Writeln(`{"status":"installed"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Installing cli-dns...\n100% done\nraw\n", errOut.String())
+	content, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "{\"status\":\"installed\"}\n", string(content))
+}
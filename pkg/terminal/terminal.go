@@ -27,7 +27,6 @@ import (
 	"github.com/akamai/cli/pkg/version"
 	"github.com/fatih/color"
 
-	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -54,7 +53,9 @@ type (
 
 	// Prompter contains methods enabling user input
 	Prompter interface {
+		Ask(q Question) (string, error)
 		Prompt(p string, options ...string) (string, error)
+		MultiSelect(p string, options ...string) ([]string, error)
 		Confirm(p string, d bool) (bool, error)
 	}
 
@@ -92,14 +93,19 @@ type (
 
 var terminalContext contextType = "terminal"
 
-// Color returns a colorable terminal
+// Color returns a colorable terminal.
+// Color is decided separately for stdout and stderr, so that redirected output is written as plain text
+// while the other stream, if attached to a terminal, stays colored.
 func Color() *DefaultTerminal {
+	// colored strings are built before it is known which stream they are written to,
+	// and the escape sequences are removed by the streams which should not be colored
+	color.NoColor = !ColorEnabled(os.Stdout.Fd()) && !ColorEnabled(os.Stderr.Fd())
 	wr := &colorWriter{
-		Writer: colorable.NewColorableStdout(),
+		Writer: colorStream(os.Stdout),
 		fd:     os.Stdout.Fd(),
 	}
 
-	return New(wr, os.Stdin, colorable.NewColorableStderr())
+	return New(wr, os.Stdin, colorStream(os.Stderr))
 }
 
 // New returns a new terminal with the specifed streams
@@ -149,29 +155,22 @@ func (t *DefaultTerminal) Error() io.Writer {
 
 // Prompt prompts the use for an open or multiple choice anwswer
 func (t *DefaultTerminal) Prompt(p string, options ...string) (string, error) {
-	q := survey.Question{
-		Name:     "q",
-		Prompt:   &survey.Input{Message: p},
-		Validate: survey.Required,
-	}
+	return t.Ask(Question{Message: p, Options: options, Validate: Required})
+}
 
-	if len(options) > 0 {
-		q.Prompt = &survey.Select{
-			Message: p,
-			Options: options,
-		}
+// MultiSelect prompts the user to choose any number of the provided options
+func (t *DefaultTerminal) MultiSelect(p string, options ...string) ([]string, error) {
+	answers := make([]string, 0)
+	q := &survey.MultiSelect{
+		Message: p,
+		Options: options,
 	}
 
-	answers := struct {
-		Q string
-	}{}
-
-	err := survey.Ask([]*survey.Question{&q}, &answers, survey.WithStdio(t.in, t.out, t.err))
-	if err != nil {
-		return "", err
+	if err := survey.AskOne(q, &answers, survey.WithStdio(t.in, t.out, t.err)); err != nil {
+		return nil, err
 	}
 
-	return answers.Q, nil
+	return answers, nil
 }
 
 // Confirm asks the user for a Y/n response, with a default
@@ -193,6 +192,11 @@ func (t *DefaultTerminal) IsTTY() bool {
 	return isatty.IsTerminal(t.out.Fd()) || isatty.IsCygwinTerminal(t.out.Fd())
 }
 
+// SetVerbosity changes the verbosity of the terminal spinner
+func (t *DefaultTerminal) SetVerbosity(v Verbosity) {
+	t.spnr.SetVerbosity(v)
+}
+
 // Spinner returns the terminal spinner
 func (t *DefaultTerminal) Spinner() Spinner {
 	return t.spnr
@@ -229,12 +233,17 @@ func (w *colorWriter) Fd() uintptr {
 // ShowBanner displays welcome banner
 func ShowBanner(ctx context.Context) {
 	term := Get(ctx)
+	title := "Welcome to Akamai CLI v" + version.Version
+	if accessible {
+		// the padding of the colored banner would only be read out as blanks
+		term.Writeln(title)
+		return
+	}
 
 	term.Writeln()
 	bg := color.New(color.BgMagenta)
 	term.Printf(bg.Sprintf(strings.Repeat(" ", 60) + "\n"))
 	fg := bg.Add(color.FgWhite)
-	title := "Welcome to Akamai CLI v" + version.Version
 	ws := strings.Repeat(" ", 16)
 	term.Printf(fg.Sprintf(ws + title + ws + "\n"))
 	term.Printf(bg.Sprintf(strings.Repeat(" ", 60) + "\n"))
@@ -0,0 +1,72 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Verbosity is the amount of output Akamai CLI writes while running commands
+type Verbosity int
+
+// Verbosity levels, selected with -q, -v and -vv flags
+const (
+	// VerbosityQuiet hides spinners and status lines, only results and errors are written
+	VerbosityQuiet Verbosity = iota - 1
+	// VerbosityNormal shows a spinner with a status line for each step
+	VerbosityNormal
+	// VerbosityVerbose shows status lines and streams the output of git and package managers instead of hiding it behind a spinner
+	VerbosityVerbose
+	// VerbosityDebug is VerbosityVerbose with debug logs
+	VerbosityDebug
+)
+
+var verbosityContext contextType = "verbosity"
+
+// WithVerbosity returns a context carrying given verbosity level
+func WithVerbosity(ctx context.Context, v Verbosity) context.Context {
+	return context.WithValue(ctx, verbosityContext, v)
+}
+
+// GetVerbosity returns the verbosity level set in the context, VerbosityNormal if none was set
+func GetVerbosity(ctx context.Context) Verbosity {
+	if v, ok := ctx.Value(verbosityContext).(Verbosity); ok {
+		return v
+	}
+	return VerbosityNormal
+}
+
+// LogLevel returns the log level matching the verbosity level
+func (v Verbosity) LogLevel() string {
+	switch {
+	case v >= VerbosityDebug:
+		return "debug"
+	case v == VerbosityVerbose:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// OutputWriter returns the writer the output of external tools, such as git or package managers, is streamed to.
+// It is nil unless the verbosity level is at least VerbosityVerbose, in which case the output is written to standard error.
+func OutputWriter(ctx context.Context) io.Writer {
+	if GetVerbosity(ctx) < VerbosityVerbose {
+		return nil
+	}
+	return os.Stderr
+}
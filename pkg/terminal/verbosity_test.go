@@ -0,0 +1,63 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestVerbosity(t *testing.T) {
+	tests := map[string]struct {
+		verbosity     Verbosity
+		expectedLevel string
+		withWriter    bool
+	}{
+		"quiet": {
+			verbosity:     VerbosityQuiet,
+			expectedLevel: "error",
+		},
+		"normal": {
+			verbosity:     VerbosityNormal,
+			expectedLevel: "error",
+		},
+		"verbose": {
+			verbosity:     VerbosityVerbose,
+			expectedLevel: "info",
+			withWriter:    true,
+		},
+		"debug": {
+			verbosity:     VerbosityDebug,
+			expectedLevel: "debug",
+			withWriter:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := WithVerbosity(context.Background(), test.verbosity)
+			assert.Equal(t, test.verbosity, GetVerbosity(ctx))
+			assert.Equal(t, test.expectedLevel, test.verbosity.LogLevel())
+			if test.withWriter {
+				assert.Equal(t, os.Stderr, OutputWriter(ctx))
+				return
+			}
+			assert.Nil(t, OutputWriter(ctx))
+		})
+	}
+	assert.Equal(t, VerbosityNormal, GetVerbosity(context.Background()))
+}
@@ -0,0 +1,104 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Width returns the number of columns of the terminal Akamai CLI writes to.
+// COLUMNS environment variable takes precedence over the detected size.
+// If the width cannot be determined (e.g. output is redirected to a file), 0 is returned and the output should be neither wrapped nor truncated.
+// In accessibility mode the width is at most 80 columns.
+func Width() int {
+	width := terminalWidth()
+	if accessible && width > accessibleWidth {
+		return accessibleWidth
+	}
+	return width
+}
+
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	fd := os.Stdout.Fd()
+	if !isTerminal(fd) {
+		return 0
+	}
+	width, err := size(fd)
+	if err != nil || width <= 0 {
+		return 0
+	}
+	return width
+}
+
+func isTerminal(fd uintptr) bool {
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// Wrap breaks text into lines no longer than width, counting indent, which is prepended to every line except the first one.
+// Existing line breaks are kept. Text is returned unchanged if width is not positive.
+func Wrap(text string, width, indent int) string {
+	if width <= 0 || width <= indent {
+		return text
+	}
+	prefix := strings.Repeat(" ", indent)
+	limit := width - indent
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		var wrapped strings.Builder
+		wrapped.WriteString(words[0])
+		lineLen := utf8.RuneCountInString(words[0])
+		for _, word := range words[1:] {
+			wordLen := utf8.RuneCountInString(word)
+			if lineLen+1+wordLen > limit {
+				wrapped.WriteString("\n" + prefix + word)
+				lineLen = wordLen
+				continue
+			}
+			wrapped.WriteString(" " + word)
+			lineLen += 1 + wordLen
+		}
+		lines[i] = wrapped.String()
+	}
+	return strings.Join(lines, "\n"+prefix)
+}
+
+// Truncate shortens text to a single line of at most width characters, marking the cut with "...".
+// Text is returned unchanged if width is not positive.
+func Truncate(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
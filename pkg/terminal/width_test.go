@@ -0,0 +1,127 @@
+// Copyright 2020. Akamai Technologies, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tj/assert"
+)
+
+func TestWidth(t *testing.T) {
+	columns, ok := os.LookupEnv("COLUMNS")
+	defer func() {
+		if ok {
+			require.NoError(t, os.Setenv("COLUMNS", columns))
+		} else {
+			require.NoError(t, os.Unsetenv("COLUMNS"))
+		}
+	}()
+
+	require.NoError(t, os.Setenv("COLUMNS", "120"))
+	assert.Equal(t, 120, Width())
+
+	SetAccessible(true)
+	assert.Equal(t, 80, Width(), "accessibility mode keeps lines within 80 columns")
+	require.NoError(t, os.Setenv("COLUMNS", "60"))
+	assert.Equal(t, 60, Width())
+	SetAccessible(false)
+
+	require.NoError(t, os.Setenv("COLUMNS", "abc"))
+	if fd := os.Stdout.Fd(); !isTerminal(fd) {
+		assert.Equal(t, 0, Width())
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		width    int
+		indent   int
+		expected string
+	}{
+		"fits in one line": {
+			text:     "short text",
+			width:    20,
+			expected: "short text",
+		},
+		"wrap with indent": {
+			text:     "this text is too long to fit",
+			width:    16,
+			indent:   4,
+			expected: "this text is\n    too long to\n    fit",
+		},
+		"keep line breaks": {
+			text:     "first line\nsecond line",
+			width:    80,
+			indent:   2,
+			expected: "first line\n  second line",
+		},
+		"word longer than width": {
+			text:     "a verylongword b",
+			width:    5,
+			expected: "a\nverylongword\nb",
+		},
+		"unknown width": {
+			text:     "this text is too long to fit",
+			expected: "this text is too long to fit",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Wrap(test.text, test.width, test.indent))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		width    int
+		expected string
+	}{
+		"fits": {
+			text:     "short text",
+			width:    10,
+			expected: "short text",
+		},
+		"truncated": {
+			text:     "this text is too long",
+			width:    10,
+			expected: "this te...",
+		},
+		"multiple lines joined": {
+			text:     "first\nsecond",
+			width:    20,
+			expected: "first second",
+		},
+		"narrow": {
+			text:     "abcdef",
+			width:    2,
+			expected: "ab",
+		},
+		"unknown width": {
+			text:     "this text is too long",
+			expected: "this text is too long",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Truncate(test.text, test.width))
+		})
+	}
+}
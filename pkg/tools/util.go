@@ -15,6 +15,7 @@
 package tools
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -55,8 +56,21 @@ func GetAkamaiCliSrcPath() (string, error) {
 	return filepath.Join(cliHome, "src"), nil
 }
 
+// gitHosts maps the host prefixes accepted in repository shorthands (e.g. "gitlab:team/cli-foo") to host names
+var gitHosts = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"bitbucket": "bitbucket.org",
+}
+
 // Githubize ..
 func Githubize(repo string) string {
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(repo, scheme) && !strings.HasSuffix(repo, ".git") {
+			return scheme + trimWebPath(strings.TrimPrefix(repo, scheme))
+		}
+	}
+
 	if strings.HasPrefix(repo, "http") || strings.HasPrefix(repo, "ssh") || strings.HasSuffix(repo, ".git") {
 		return strings.TrimPrefix(repo, "ssh://")
 	}
@@ -65,6 +79,22 @@ func Githubize(repo string) string {
 		return repo
 	}
 
+	// scp-like syntax, e.g. git@bitbucket.org:team/cli-foo
+	if strings.Contains(repo, "@") && strings.Contains(repo, ":") {
+		return repo
+	}
+
+	if i := strings.Index(repo, ":"); i > 0 {
+		if host, ok := gitHosts[strings.ToLower(repo[:i])]; ok {
+			return "https://" + trimWebPath(host+"/"+repo[i+1:]) + ".git"
+		}
+	}
+
+	// Shorthand with a host name, e.g. gitlab.example.com/team/cli-foo
+	if i := strings.Index(repo, "/"); i > 0 && strings.Contains(repo[:i], ".") {
+		return "https://" + trimWebPath(repo) + ".git"
+	}
+
 	if !strings.Contains(repo, "/") {
 		repo = "akamai/cli-" + strings.TrimPrefix(repo, "cli-")
 	}
@@ -76,3 +106,27 @@ func Githubize(repo string) string {
 
 	return "https://github.com/" + repo + ".git"
 }
+
+// trimWebPath strips the web UI part of a repository address given as host/owner/repo,
+// e.g. GitLab "/-/tree/main" or Bitbucket and Gitea "/src/master"
+func trimWebPath(repo string) string {
+	repo = strings.TrimSuffix(repo, "/")
+	if i := strings.Index(repo, "/-/"); i > 0 {
+		repo = repo[:i]
+	}
+	if parts := strings.Split(repo, "/"); len(parts) > 3 && parts[3] == "src" {
+		repo = strings.Join(parts[:3], "/")
+	}
+	return repo
+}
+
+// ParseSwitch parses on/off style values, also accepting true/false, yes/no and 1/0
+func ParseSwitch(val string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "on", "true", "yes", "1":
+		return true, nil
+	case "off", "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q, expected on or off", val)
+}
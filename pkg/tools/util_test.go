@@ -61,6 +61,14 @@ func TestGithubize(t *testing.T) {
 		{"file:///local/repo/path", "file:///local/repo/path"},
 		{"file:///local/repo/path.git", "file:///local/repo/path.git"},
 		{"ssh://example.org:/repo/path", "example.org:/repo/path"},
+		{"https://github.com/akamai/cli-property", "https://github.com/akamai/cli-property"},
+		{"gitlab.example.com/team/cli-foo", "https://gitlab.example.com/team/cli-foo.git"},
+		{"gitlab.example.com/group/team/cli-foo/-/tree/main", "https://gitlab.example.com/group/team/cli-foo.git"},
+		{"gitlab:team/cli-foo", "https://gitlab.com/team/cli-foo.git"},
+		{"bitbucket:team/cli-foo", "https://bitbucket.org/team/cli-foo.git"},
+		{"https://bitbucket.org/team/cli-foo/src/master/", "https://bitbucket.org/team/cli-foo"},
+		{"gitea.example.com/team/cli-foo/src/branch/main", "https://gitea.example.com/team/cli-foo.git"},
+		{"git@bitbucket.org:team/cli-foo", "git@bitbucket.org:team/cli-foo"},
 	}
 
 	for _, tt := range githubizeTests {
@@ -69,3 +77,26 @@ func TestGithubize(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSwitch(t *testing.T) {
+	switchTests := []struct {
+		value     string
+		result    bool
+		withError bool
+	}{
+		{"on", true, false},
+		{"Yes", true, false},
+		{"true", true, false},
+		{"off", false, false},
+		{" no ", false, false},
+		{"0", false, false},
+		{"maybe", false, true},
+	}
+
+	for _, tt := range switchTests {
+		result, err := ParseSwitch(tt.value)
+		if (err != nil) != tt.withError || result != tt.result {
+			t.Errorf("ParseSwitch(%s) => %t, %v, wanted: %t, error: %t", tt.value, result, err, tt.result, tt.withError)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package warnings writes the notices Akamai CLI would otherwise repeat on every run, such as pending updates or
+// the use of deprecated flags, at most once per time window on a machine. Scripts running Akamai CLI hundreds of
+// times then get each notice once, instead of a copy per run.
+package warnings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli/pkg/tools"
+)
+
+const (
+	// IntervalKey is the cli setting holding the window within which a warning is not repeated, a duration
+	// such as 12h. 0 repeats warnings on every run.
+	IntervalKey = "warnings-interval"
+
+	// DefaultInterval is the window used when cli.warnings-interval is not set
+	DefaultInterval = 24 * time.Hour
+
+	stateFile = "warnings.json"
+)
+
+// shownWarning records when a warning was last written, and its message, so that a changed message is
+// written again within the window
+type shownWarning struct {
+	Digest string    `json:"digest"`
+	Time   time.Time `json:"time"`
+}
+
+// Warn writes the message in yellow to the error stream of the terminal, unless the warning of given key was
+// written with the same message within the window set in cli.warnings-interval. The message is logged in any
+// case. It returns true if the message was written.
+func Warn(ctx context.Context, key, msg string) bool {
+	logger := log.FromContext(ctx)
+	logger.Warn(msg)
+	interval := Interval(ctx)
+	if interval == 0 {
+		writeWarning(ctx, msg)
+		return true
+	}
+
+	path, err := statePath()
+	if err != nil {
+		logger.Debugf("Unable to deduplicate warnings: %s", err)
+		writeWarning(ctx, msg)
+		return true
+	}
+	state := readState(path)
+	digest := messageDigest(msg)
+	now := time.Now().UTC()
+	if last, ok := state[key]; ok && last.Digest == digest && now.Sub(last.Time) < interval {
+		logger.Debugf("Warning %s already shown at %s", key, last.Time.Format(time.RFC3339))
+		return false
+	}
+	writeWarning(ctx, msg)
+	state[key] = shownWarning{Digest: digest, Time: now}
+	for k, shown := range state {
+		if now.Sub(shown.Time) >= interval {
+			delete(state, k)
+		}
+	}
+	if err := writeState(path, state); err != nil {
+		logger.Debugf("Unable to record shown warning: %s", err)
+	}
+	return true
+}
+
+// Interval returns the window within which warnings are not repeated, zero if they are written on every run
+func Interval(ctx context.Context) time.Duration {
+	val, ok := config.Get(ctx).GetValue("cli", IntervalKey)
+	val = strings.TrimSpace(val)
+	if !ok || val == "" {
+		return DefaultInterval
+	}
+	interval, err := time.ParseDuration(val)
+	if err != nil || interval < 0 {
+		log.FromContext(ctx).Warnf("Invalid value of cli.%s: %s, expected a duration such as 12h", IntervalKey, val)
+		return DefaultInterval
+	}
+	return interval
+}
+
+func writeWarning(ctx context.Context, msg string) {
+	fmt.Fprintln(terminal.Get(ctx).Error(), color.YellowString(msg))
+}
+
+func messageDigest(msg string) string {
+	sum := sha256.Sum256([]byte(msg))
+	return hex.EncodeToString(sum[:8])
+}
+
+func statePath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, stateFile), nil
+}
+
+// readState returns the warnings shown within the window, a missing or invalid file is an empty state
+func readState(path string) map[string]shownWarning {
+	state := make(map[string]shownWarning)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return make(map[string]shownWarning)
+	}
+	return state
+}
+
+// writeState replaces the state file atomically, so that concurrent runs never read a partial file
+func writeState(path string, state map[string]shownWarning) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), stateFile+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,109 @@
+package warnings
+
+import (
+	"bytes"
+	"context"
+	"github.com/akamai/cli/pkg/config"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWarn(t *testing.T) {
+	tests := map[string]struct {
+		interval string
+		state    string
+		key      string
+		msg      string
+		shown    bool
+	}{
+		"first warning": {
+			key:   "package-updates",
+			msg:   "2 packages have updates",
+			shown: true,
+		},
+		"shown within the window": {
+			state: `{"package-updates": {"digest": "{{digest}}", "time": "{{hour ago}}"}}`,
+			key:   "package-updates",
+			msg:   "2 packages have updates",
+		},
+		"message changed": {
+			state: `{"package-updates": {"digest": "{{digest}}", "time": "{{hour ago}}"}}`,
+			key:   "package-updates",
+			msg:   "3 packages have updates",
+			shown: true,
+		},
+		"window elapsed": {
+			interval: "30m",
+			state:    `{"package-updates": {"digest": "{{digest}}", "time": "{{hour ago}}"}}`,
+			key:      "package-updates",
+			msg:      "2 packages have updates",
+			shown:    true,
+		},
+		"deduplication disabled": {
+			interval: "0",
+			state:    `{"package-updates": {"digest": "{{digest}}", "time": "{{hour ago}}"}}`,
+			key:      "package-updates",
+			msg:      "2 packages have updates",
+			shown:    true,
+		},
+		"other warning shown": {
+			state: `{"github-token-expiry": {"digest": "{{digest}}", "time": "{{hour ago}}"}}`,
+			key:   "package-updates",
+			msg:   "2 packages have updates",
+			shown: true,
+		},
+		"invalid state": {
+			state: `{`,
+			key:   "package-updates",
+			msg:   "2 packages have updates",
+			shown: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome, err := ioutil.TempDir("", "cli-warnings")
+			require.NoError(t, err)
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+				require.NoError(t, os.RemoveAll(cliHome))
+			}()
+			path := filepath.Join(cliHome, ".akamai-cli", stateFile)
+			if test.state != "" {
+				state := bytes.ReplaceAll([]byte(test.state), []byte("{{digest}}"), []byte(messageDigest("2 packages have updates")))
+				state = bytes.ReplaceAll(state, []byte("{{hour ago}}"), []byte(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)))
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+				require.NoError(t, ioutil.WriteFile(path, state, 0600))
+			}
+			cfg, term := &config.Mock{}, &terminal.Mock{}
+			cfg.On("GetValue", "cli", IntervalKey).Return(test.interval, test.interval != "")
+			out := &bytes.Buffer{}
+			term.On("Error").Return(out).Maybe()
+			ctx := terminal.Context(config.Context(context.Background(), cfg), term)
+
+			assert.Equal(t, test.shown, Warn(ctx, test.key, test.msg))
+			if !test.shown {
+				assert.Empty(t, out.String())
+				return
+			}
+			assert.Equal(t, color.YellowString(test.msg)+"\n", out.String())
+			if test.interval == "0" {
+				return
+			}
+			assert.False(t, Warn(ctx, test.key, test.msg), "the warning is not repeated")
+			state := readState(path)
+			assert.Equal(t, messageDigest(test.msg), state[test.key].Digest)
+			if name == "window elapsed" {
+				assert.Len(t, state, 1, "expired warnings are removed")
+			}
+		})
+	}
+}